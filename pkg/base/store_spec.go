@@ -48,6 +48,12 @@ type StoreSpec struct {
 	SizePercent float64
 	InMemory    bool
 	Attributes  roachpb.Attributes
+	// EncryptionKey is the path of the store key with which newly written
+	// files are encrypted.
+	EncryptionKey string
+	// OldEncryptionKeys are the paths of previous store keys, needed to
+	// read files written before the store key was rotated.
+	OldEncryptionKeys []string
 }
 
 // String returns a fully parsable version of the store spec.
@@ -75,6 +81,12 @@ func (ss StoreSpec) String() string {
 		}
 		fmt.Fprintf(&buffer, ",")
 	}
+	if len(ss.EncryptionKey) > 0 {
+		fmt.Fprintf(&buffer, "key=%s,", ss.EncryptionKey)
+	}
+	if len(ss.OldEncryptionKeys) > 0 {
+		fmt.Fprintf(&buffer, "old-keys=%s,", strings.Join(ss.OldEncryptionKeys, ":"))
+	}
 	// Trim the extra comma from the end if it exists.
 	if l := buffer.Len(); l > 0 {
 		buffer.Truncate(l - 1)
@@ -84,7 +96,7 @@ func (ss StoreSpec) String() string {
 
 // newStoreSpec parses the string passed into a --store flag and returns a
 // StoreSpec if it is correctly parsed.
-// There are four possible fields that can be passed in, comma separated:
+// There are six possible fields that can be passed in, comma separated:
 // - path=xxx The directory in which to the rocks db instance should be
 //   located, required unless using a in memory storage.
 // - type=mem This specifies that the store is an in memory storage instead of
@@ -98,6 +110,11 @@ func (ss StoreSpec) String() string {
 //   - 20%             -> 20% of the available space
 //   - 0.2             -> 20% of the available space
 // - attrs=xxx:yyy:zzz A colon separated list of optional attributes.
+// - key=xxx The path of a file containing the store key (16, 24 or 32 random
+//   bytes) with which to encrypt newly written files.
+// - old-keys=xxx:yyy A colon separated list of the paths of previous store
+//   keys, required to read files which were written before the store key
+//   was rotated.
 // Note that commas are forbidden within any field name or value.
 func newStoreSpec(value string) (StoreSpec, error) {
 	if len(value) == 0 {
@@ -191,6 +208,15 @@ func newStoreSpec(value string) (StoreSpec, error) {
 				ss.Attributes.Attrs = append(ss.Attributes.Attrs, attribute)
 			}
 			sort.Strings(ss.Attributes.Attrs)
+		case "key":
+			ss.EncryptionKey = value
+		case "old-keys":
+			for _, path := range strings.Split(value, ":") {
+				if len(path) == 0 {
+					return StoreSpec{}, fmt.Errorf("empty path in old-keys")
+				}
+				ss.OldEncryptionKeys = append(ss.OldEncryptionKeys, path)
+			}
 		case "type":
 			if value == "mem" {
 				ss.InMemory = true
@@ -209,6 +235,9 @@ func newStoreSpec(value string) (StoreSpec, error) {
 		if ss.SizePercent == 0 && ss.SizeInBytes == 0 {
 			return StoreSpec{}, fmt.Errorf("size must be specified for an in memory store")
 		}
+		if ss.EncryptionKey != "" || len(ss.OldEncryptionKeys) > 0 {
+			return StoreSpec{}, fmt.Errorf("encryption keys specified for in memory store")
+		}
 	} else if ss.Path == "" {
 		return StoreSpec{}, fmt.Errorf("no path specified")
 	}
@@ -73,6 +73,10 @@ type TestServerArgs struct {
 
 	// If set, the recording of events to the event log tables is disabled.
 	DisableEventLog bool
+
+	// If set, in-memory stores use the pure-Go in-memory engine instead of
+	// RocksDB.
+	UseGoInMemEngine bool
 }
 
 // TestClusterArgs contains the parameters one can set when creating a test
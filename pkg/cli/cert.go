@@ -17,7 +17,12 @@
 package cli
 
 import (
+	"os"
+	"strings"
+	"time"
+
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -105,15 +110,62 @@ func runCreateClientCert(cmd *cobra.Command, args []string) error {
 	)
 }
 
+// A listCertsCmd command lists the certificates found in a directory.
+var listCertsCmd = &cobra.Command{
+	Use:   "list <directory>",
+	Short: "list certs in a directory",
+	Long: `
+Lists the certificates found in the .crt files of the given directory,
+along with their usage and expiration time.
+`,
+	SilenceUsage: true,
+	RunE:         maybeDecorateGRPCError(runListCerts),
+}
+
+var listCertsColumnHeaders = []string{
+	"filename",
+	"usage",
+	"common_name",
+	"hosts",
+	"expiration",
+}
+
+// runListCerts prints a table describing the certificates of a directory.
+func runListCerts(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return usageAndError(cmd)
+	}
+	infos, err := security.ListCertificates(args[0])
+	if err != nil {
+		return errors.Wrap(err, "failed to list certificates")
+	}
+	rows := make([][]string, len(infos))
+	for i, info := range infos {
+		expiration := info.NotAfter.Format(time.RFC3339)
+		if timeutil.Now().After(info.NotAfter) {
+			expiration += " (expired)"
+		}
+		rows[i] = []string{
+			info.Filename,
+			info.Usage,
+			info.CommonName,
+			strings.Join(info.Hosts, ","),
+			expiration,
+		}
+	}
+	return printQueryOutput(os.Stdout, listCertsColumnHeaders, rows, "", cliCtx.tableDisplayFormat)
+}
+
 var certCmds = []*cobra.Command{
 	createCACertCmd,
 	createNodeCertCmd,
 	createClientCertCmd,
+	listCertsCmd,
 }
 
 var certCmd = &cobra.Command{
 	Use:   "cert",
-	Short: "create ca, node, and client certs",
+	Short: "create and list ca, node, and client certs",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return cmd.Usage()
 	},
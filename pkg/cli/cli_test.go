@@ -841,6 +841,82 @@ func Example_sql_escape() {
 	// (1 row)
 }
 
+func Example_sql_format() {
+	c, err := newCLITest(nil, false)
+	if err != nil {
+		panic(err)
+	}
+	defer c.stop(true)
+
+	c.RunWithArgs([]string{"sql", "-e", "create database t; create table t.f (x int, y string);"})
+	c.RunWithArgs([]string{"sql", "-e", "insert into t.f values (1, 'a,b'), (2, NULL), (3, e'c\\nd')"})
+	for _, format := range []string{"csv", "json", "html", "records", "sql", "raw"} {
+		c.RunWithArgs([]string{"sql", "--format=" + format, "-e", "select * from t.f order by x"})
+	}
+
+	// Output:
+	// sql -e create database t; create table t.f (x int, y string);
+	// CREATE TABLE
+	// sql -e insert into t.f values (1, 'a,b'), (2, NULL), (3, e'c\nd')
+	// INSERT 3
+	// sql --format=csv -e select * from t.f order by x
+	// x,y
+	// 1,"a,b"
+	// 2,
+	// 3,"c
+	// d"
+	// sql --format=json -e select * from t.f order by x
+	// [
+	//   {"x": 1, "y": "a,b"},
+	//   {"x": 2, "y": null},
+	//   {"x": 3, "y": "c\nd"}
+	// ]
+	// sql --format=html -e select * from t.f order by x
+	// <table>
+	// <thead><tr><th>x</th><th>y</th></tr></thead>
+	// <tbody>
+	// <tr><td>1</td><td>a,b</td></tr>
+	// <tr><td>2</td><td><i>NULL</i></td></tr>
+	// <tr><td>3</td><td>c<br/>d</td></tr>
+	// </tbody>
+	// <tfoot><tr><td colspan=2>3 rows</td></tr></tfoot></table>
+	// sql --format=records -e select * from t.f order by x
+	// -[ RECORD 1 ]
+	// x | 1
+	// y | a,b
+	// -[ RECORD 2 ]
+	// x | 2
+	// y | NULL
+	// -[ RECORD 3 ]
+	// x | 3
+	// y | c␤
+	//   | d
+	// (3 rows)
+	// sql --format=sql -e select * from t.f order by x
+	// INSERT INTO results(x, y) VALUES (1, 'a,b');
+	// INSERT INTO results(x, y) VALUES (2, NULL);
+	// INSERT INTO results(x, y) VALUES (3, e'c\nd');
+	// -- 3 rows
+	// sql --format=raw -e select * from t.f order by x
+	// # 2 columns
+	// # row 1
+	// ## 1
+	// 1
+	// ## 3
+	// a,b
+	// # row 2
+	// ## 1
+	// 2
+	// ## NULL
+	// # row 3
+	// ## 1
+	// 3
+	// ## 3
+	// c
+	// d
+	// # 3 rows
+}
+
 func Example_user() {
 	c, err := newCLITest(nil, false)
 	if err != nil {
@@ -939,7 +1015,7 @@ Usage:
 
 Available Commands:
   start          start a node
-  cert           create ca, node, and client certs
+  cert           create and list ca, node, and client certs
   freeze-cluster freeze the cluster in preparation for an update
   quit           drain and shutdown node
 
@@ -145,11 +145,20 @@ with a non-zero status code and further statements are not executed. The
 results of each SQL statement are printed on the standard output.`,
 	}
 
+	TableDisplayFormat = FlagInfo{
+		Name: "format",
+		Description: `
+Selects how to display table rows in results. Possible values: tsv,
+csv, json, html, records, sql, raw, pretty. If left unspecified,
+defaults to pretty when the output is a terminal and tsv otherwise.`,
+	}
+
 	Pretty = FlagInfo{
 		Name: "pretty",
 		Description: `
 Causes table rows to be formatted as tables using ASCII art.
-When not specified, table rows are printed as tab-separated values (TSV).`,
+When not specified, table rows are printed as tab-separated values (TSV).
+Deprecated: use --format=pretty instead.`,
 	}
 
 	Join = FlagInfo{
@@ -243,6 +252,15 @@ server to listen on an external address in insecure mode.`,
 		Description: `Prompt for the new user's password.`,
 	}
 
+	AuthMethods = FlagInfo{
+		Name: "auth-methods",
+		Description: `
+Comma-separated list of the authentication methods the user may use to log in
+with a password, in order of preference: scram-sha-256, md5 and password. When
+empty, md5 and password are allowed; scram-sha-256 has to be enabled
+explicitly, since not all drivers support it.`,
+	}
+
 	CACert = FlagInfo{
 		Name:        "ca-cert",
 		EnvVar:      "COCKROACH_CA_CERT",
@@ -324,6 +342,19 @@ memory that the store may consume, for example:
   --store=type=mem,size=20GiB
   --store=type=mem,size=90%
 
+</PRE>
+The files of an on-disk store can be encrypted at rest by setting the "key"
+field to the path of a file containing a store key of 16, 24 or 32 random
+bytes (for AES-128, AES-192 or AES-256). Each file is encrypted with its own
+data key, which is in turn encrypted with the store key. To rotate the store
+key, set "key" to the new key and list the previous keys, separated by colons,
+in the "old-keys" field. Only newly written files use the new key, so old keys
+must be provided until "cockroach debug encryption-status" shows no files
+using them, for example:
+<PRE>
+
+  --store=path=/mnt/ssd01,key=/keys/store2.key,old-keys=/keys/store1.key
+
 </PRE>
 Commas are forbidden in all values, since they are used to separate fields.
 Also, if you use equal signs in the file path to a store, you must use the
@@ -371,6 +402,20 @@ is the prefix for range local keys.`}
 		Description: `Print key and value sizes along with their associated key.`,
 	}
 
+	EncryptionKey = FlagInfo{
+		Name: "encryption-key",
+		Description: `
+The path of the store key of an encrypted store, used to read the store and to
+encrypt any files written by the command.`,
+	}
+
+	OldEncryptionKeys = FlagInfo{
+		Name: "old-encryption-keys",
+		Description: `
+A colon separated list of the paths of previous store keys of an encrypted
+store, used to read files written before the store key was rotated.`,
+	}
+
 	RaftTickInterval = FlagInfo{
 		Name: "raft-tick-interval",
 		Description: `
@@ -378,11 +423,24 @@ The resolution of the Raft timer; other raft timeouts are
 defined in terms of multiples of this value.`,
 	}
 
+	MaxOffset = FlagInfo{
+		Name: "max-offset",
+		Description: `
+The maximum allowed offset between the clocks of any two nodes of the cluster.
+It must be the same on all the nodes: a node refuses to communicate with nodes
+configured with a different value. Changing it requires restarting every node.`,
+	}
+
 	UndoFreezeCluster = FlagInfo{
 		Name:        "undo",
 		Description: `Attempt to undo an earlier attempt to freeze the cluster.`,
 	}
 
+	DecommissionWait = FlagInfo{
+		Name:        "wait",
+		Description: `Wait until all replicas have been moved off the decommissioning nodes.`,
+	}
+
 	Replicated = FlagInfo{
 		Name:        "replicated",
 		Description: "Restrict scan to replicated data.",
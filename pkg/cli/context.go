@@ -47,13 +47,12 @@ type cliContext struct {
 	// Embed the base context.
 	*base.Config
 
-	// prettyFmt indicates whether tables should be pretty-formatted in
-	// the output during non-interactive execution.
-	prettyFmt bool
+	// tableDisplayFormat indicates how to format result tables.
+	tableDisplayFormat tableDisplayFormat
 }
 
 func (ctx *cliContext) InitCLIDefaults() {
-	ctx.prettyFmt = false
+	ctx.tableDisplayFormat = tableDisplayTSV
 }
 
 type sqlContext struct {
@@ -149,4 +148,7 @@ type debugContext struct {
 	values           bool
 	sizes            bool
 	replicated       bool
+
+	encryptionKey     string
+	oldEncryptionKeys string
 }
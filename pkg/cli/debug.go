@@ -18,10 +18,18 @@
 package cli
 
 import (
+	"bufio"
 	"bytes"
+	"container/heap"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"golang.org/x/net/context"
 
@@ -32,10 +40,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/raftlog"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/coreos/etcd/raft/raftpb"
@@ -71,12 +83,24 @@ func openStore(cmd *cobra.Command, dir string, stopper *stop.Stopper) (*engine.R
 	if err != nil {
 		return nil, err
 	}
-	db, err := engine.NewRocksDB(
+	var encryption *engine.EncryptionKeys
+	if debugCtx.encryptionKey != "" || debugCtx.oldEncryptionKeys != "" {
+		var oldKeys []string
+		if debugCtx.oldEncryptionKeys != "" {
+			oldKeys = strings.Split(debugCtx.oldEncryptionKeys, ":")
+		}
+		encryption, err = engine.LoadEncryptionKeys(debugCtx.encryptionKey, oldKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+	db, err := engine.NewEncryptedRocksDB(
 		roachpb.Attributes{},
 		dir,
 		cache,
 		0,
 		maxOpenFiles,
+		encryption,
 	)
 	if err != nil {
 		return nil, err
@@ -478,6 +502,33 @@ func runDebugRaftLog(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	// The store may keep its raft log in files instead of the engine.
+	logDir := filepath.Join(db.AuxiliaryDir(), raftlog.DirName)
+	if exists, err := raftlog.Exists(logDir); err != nil {
+		return err
+	} else if exists {
+		raftLog, err := raftlog.Open(logDir, 0)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = raftLog.Close() }()
+		lo, hi, ok := raftLog.Bounds(rangeID)
+		if !ok {
+			return nil
+		}
+		return raftLog.Iterate(rangeID, lo, hi+1, func(index uint64, data []byte) (bool, error) {
+			meta := enginepb.MVCCMetadata{RawBytes: roachpb.MakeValueFromBytes(data).RawBytes}
+			value, err := protoutil.Marshal(&meta)
+			if err != nil {
+				return false, err
+			}
+			return printRaftLogEntry(engine.MVCCKeyValue{
+				Key:   engine.MakeMVCCMetadataKey(keys.RaftLogKey(rangeID, index)),
+				Value: value,
+			})
+		})
+	}
+
 	start := engine.MakeMVCCMetadataKey(keys.RaftLogPrefix(rangeID))
 	end := engine.MakeMVCCMetadataKey(keys.RaftLogPrefix(rangeID).PrefixEnd())
 
@@ -555,7 +606,7 @@ func runDebugGCCmd(cmd *cobra.Command, args []string) error {
 		snap := db.NewSnapshot()
 		defer snap.Close()
 		_, info, err := storage.RunGC(context.Background(), &desc, snap, hlc.Timestamp{WallTime: timeutil.Now().UnixNano()},
-			config.GCPolicy{TTLSeconds: 24 * 60 * 60 /* 1 day */}, func(_ hlc.Timestamp, _ *roachpb.Transaction, _ roachpb.PushTxnType) {
+			config.GCPolicy{TTLSeconds: 24 * 60 * 60 /* 1 day */}, hlc.ZeroTimestamp, func(_ hlc.Timestamp, _ *roachpb.Transaction, _ roachpb.PushTxnType) {
 			}, func(_ []roachpb.Intent, _, _ bool) error { return nil })
 		if err != nil {
 			return err
@@ -681,6 +732,65 @@ Output environment variables that influence configuration.
 	},
 }
 
+var debugEncryptionStatusCmd = &cobra.Command{
+	Use:   "encryption-status [directory]",
+	Short: "show the encryption status of the files in a store",
+	Long: `
+Lists the files in a store along with the ID of the store key each of them is
+encrypted with, followed by a summary for each key. Files written before
+encryption was enabled are listed as plaintext. An old store key can be
+dropped from the "old-keys" field of --store once no files use it.
+`,
+	RunE: maybeDecorateGRPCError(runDebugEncryptionStatus),
+}
+
+func runDebugEncryptionStatus(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return errors.New("one argument is required")
+	}
+
+	files, err := engine.GetEncryptionStatus(args[0])
+	if err != nil {
+		return err
+	}
+
+	type keyUsage struct {
+		files int
+		bytes int64
+	}
+	var keyIDs []string
+	usage := make(map[string]*keyUsage)
+
+	tw := tabwriter.NewWriter(os.Stdout, 2, 1, 2, ' ', 0)
+	fmt.Fprintln(tw, "file\tsize\tkey")
+	for _, f := range files {
+		keyID := f.KeyID
+		if keyID == "" {
+			keyID = "plaintext"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Name, humanizeutil.IBytes(f.Size), keyID)
+		u, ok := usage[keyID]
+		if !ok {
+			u = &keyUsage{}
+			usage[keyID] = u
+			keyIDs = append(keyIDs, keyID)
+		}
+		u.files++
+		u.bytes += f.Size
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	sort.Strings(keyIDs)
+	for _, keyID := range keyIDs {
+		u := usage[keyID]
+		fmt.Printf("%s: %d files, %s\n", keyID, u.files, humanizeutil.IBytes(u.bytes))
+	}
+	return nil
+}
+
 var debugCompactCmd = &cobra.Command{
 	Use:   "compact [directory]",
 	Short: "compact the sstables in a store",
@@ -750,6 +860,113 @@ func runDebugSSTables(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+var debugMergeLogsCmd = &cobra.Command{
+	Use:   "merge-logs <file> [<file>...]",
+	Short: "merge log files by timestamp",
+	Long: `
+Merge the entries of the given log files, typically retrieved from several
+nodes, into a single stream ordered by timestamp. Each entry is printed in the
+text format, prefixed by the name of the file it comes from. Files in the text
+and JSON formats are supported.
+`,
+	RunE: runDebugMergeLogs,
+}
+
+func runDebugMergeLogs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("at least one log file is required")
+	}
+	return mergeLogs(os.Stdout, args)
+}
+
+// logFileReader is the next entry of a log file being merged.
+type logFileReader struct {
+	name    string
+	decoder *log.EntryDecoder
+	entry   log.Entry
+}
+
+// next decodes the next entry of the file. It returns false at the end of the
+// file.
+func (r *logFileReader) next() (bool, error) {
+	if err := r.decoder.Decode(&r.entry); err != nil {
+		if err == io.EOF {
+			return false, nil
+		}
+		return false, fmt.Errorf("%s: %s", r.name, err)
+	}
+	return true, nil
+}
+
+// logFileHeap is a min-heap of log files ordered by the timestamp of their
+// next entry. Files whose next entries have the same timestamp are ordered by
+// name so that the output is deterministic.
+type logFileHeap []*logFileReader
+
+func (h logFileHeap) Len() int      { return len(h) }
+func (h logFileHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h logFileHeap) Less(i, j int) bool {
+	if h[i].entry.Time != h[j].entry.Time {
+		return h[i].entry.Time < h[j].entry.Time
+	}
+	return h[i].name < h[j].name
+}
+
+func (h *logFileHeap) Push(x interface{}) {
+	*h = append(*h, x.(*logFileReader))
+}
+
+func (h *logFileHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// mergeLogs writes the entries of the given log files to w, interleaved by
+// timestamp. Each entry is prefixed by the name of its file.
+func mergeLogs(w io.Writer, paths []string) error {
+	var h logFileHeap
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r := &logFileReader{name: path, decoder: log.NewEntryDecoder(f)}
+		ok, err := r.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			h = append(h, r)
+		}
+	}
+	heap.Init(&h)
+
+	bw := bufio.NewWriter(w)
+	for h.Len() > 0 {
+		r := h[0]
+		if _, err := fmt.Fprintf(bw, "%s> ", r.name); err != nil {
+			return err
+		}
+		if err := log.FormatEntry(r.entry, bw); err != nil {
+			return err
+		}
+		ok, err := r.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+	return bw.Flush()
+}
+
 func init() {
 	debugCmd.AddCommand(debugCmds...)
 }
@@ -763,6 +980,8 @@ var debugCmds = []*cobra.Command{
 	debugCheckStoreCmd,
 	debugCompactCmd,
 	debugSSTablesCmd,
+	debugEncryptionStatusCmd,
+	debugMergeLogsCmd,
 	kvCmd,
 	rangeCmd,
 	debugEnvCmd,
@@ -0,0 +1,114 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+func TestMergeLogs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, err := ioutil.TempDir("", "TestMergeLogs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	t1 := time.Date(2016, 12, 1, 10, 0, 0, 1000, time.UTC)
+	t2 := t1.Add(time.Second)
+	t3 := t2.Add(time.Second)
+	t4 := t3.Add(time.Second)
+	entry := func(t time.Time, sev log.Severity, line int64, msg string) log.Entry {
+		return log.Entry{
+			Severity:  sev,
+			Time:      t.UnixNano(),
+			Goroutine: 1,
+			File:      "server/server.go",
+			Line:      line,
+			Message:   msg,
+		}
+	}
+	e1 := entry(t1, log.Severity_INFO, 1, "[n1] first")
+	e2 := entry(t2, log.Severity_WARNING, 2, "[n2] second")
+	e3 := entry(t3, log.Severity_INFO, 3, "[n1] third")
+	e4 := entry(t4, log.Severity_ERROR, 4, "[n2] fourth")
+
+	// The first node logs in the text format.
+	var text bytes.Buffer
+	for _, e := range []log.Entry{e1, e3} {
+		if err := log.FormatEntry(e, &text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	node1 := filepath.Join(dir, "node1.log")
+	if err := ioutil.WriteFile(node1, text.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second node logs in the JSON format.
+	var json bytes.Buffer
+	for _, e := range []struct {
+		t        time.Time
+		severity string
+		line     int
+		msg      string
+	}{
+		{t2, "WARNING", 2, "second"},
+		{t4, "ERROR", 4, "fourth"},
+	} {
+		fmt.Fprintf(&json, `{"severity":%q,"time":%q,"goroutine":1,"file":"server/server.go",`+
+			`"line":%d,"channel":"default","tags":{"n":"2"},"message":%q}`+"\n",
+			e.severity, e.t.Format(time.RFC3339Nano), e.line, e.msg)
+	}
+	node2 := filepath.Join(dir, "node2.log")
+	if err := ioutil.WriteFile(node2, json.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected bytes.Buffer
+	for _, e := range []struct {
+		file  string
+		entry log.Entry
+	}{
+		{node1, e1}, {node2, e2}, {node1, e3}, {node2, e4},
+	} {
+		fmt.Fprintf(&expected, "%s> ", e.file)
+		if err := log.FormatEntry(e.entry, &expected); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := mergeLogs(&out, []string{node1, node2}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected.String() {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected.String(), out.String())
+	}
+}
@@ -46,6 +46,7 @@ var httpHost, httpPort, connDBName, zoneConfig string
 var zoneDisableReplication bool
 var startBackground bool
 var undoFreezeCluster bool
+var decommissionWait bool
 
 var serverCfg = server.MakeConfig()
 var baseCfg = serverCfg.Config
@@ -276,6 +277,7 @@ func init() {
 
 		varFlag(f, &serverCfg.Stores, cliflags.Store)
 		durationFlag(f, &serverCfg.RaftTickInterval, cliflags.RaftTickInterval, base.DefaultRaftTickInterval)
+		durationFlag(f, &serverCfg.MaxOffset, cliflags.MaxOffset, serverCfg.MaxOffset)
 		boolFlag(f, &startBackground, cliflags.Background, false)
 
 		// Usage for the unix socket is odd as we use a real file, whereas
@@ -317,6 +319,7 @@ func init() {
 	}
 
 	boolFlag(setUserCmd.Flags(), &password, cliflags.Password, false)
+	stringFlag(setUserCmd.Flags(), &authMethods, cliflags.AuthMethods, "")
 
 	clientCmds := []*cobra.Command{
 		sqlShellCmd, quitCmd, freezeClusterCmd, dumpCmd, /* startCmd is covered above */
@@ -342,8 +345,15 @@ func init() {
 
 		// By default, client commands print their output as
 		// pretty-formatted tables on terminals, and TSV when redirected
-		// to a file. The user can override with --pretty.
-		boolFlag(f, &cliCtx.prettyFmt, cliflags.Pretty, isInteractive)
+		// to a file. The user can override with --format.
+		cliCtx.tableDisplayFormat = tableDisplayTSV
+		if isInteractive {
+			cliCtx.tableDisplayFormat = tableDisplayPretty
+		}
+		varFlag(f, &cliCtx.tableDisplayFormat, cliflags.TableDisplayFormat)
+		varFlag(f, prettyValue{format: &cliCtx.tableDisplayFormat}, cliflags.Pretty)
+		// Allow '--pretty'
+		f.Lookup(cliflags.Pretty.Name).NoOptDefVal = "true"
 	}
 
 	zf := setZoneCmd.Flags()
@@ -354,6 +364,8 @@ func init() {
 
 	boolFlag(freezeClusterCmd.PersistentFlags(), &undoFreezeCluster, cliflags.UndoFreezeCluster, false)
 
+	boolFlag(decommissionNodeCmd.Flags(), &decommissionWait, cliflags.DecommissionWait, false)
+
 	// Commands that need the cockroach port.
 	simpleCmds := []*cobra.Command{quitCmd, freezeClusterCmd}
 	simpleCmds = append(simpleCmds, kvCmds...)
@@ -393,6 +405,16 @@ func init() {
 
 		f = debugRangeDataCmd.Flags()
 		boolFlag(f, &debugCtx.replicated, cliflags.Replicated, false)
+
+		// Commands which open a store.
+		for _, cmd := range []*cobra.Command{
+			debugKeysCmd, debugRangeDataCmd, debugRangeDescriptorsCmd, debugRaftLogCmd,
+			debugGCCmd, debugCheckStoreCmd, debugCompactCmd, debugSSTablesCmd,
+		} {
+			f := cmd.Flags()
+			stringFlag(f, &debugCtx.encryptionKey, cliflags.EncryptionKey, "")
+			stringFlag(f, &debugCtx.oldEncryptionKeys, cliflags.OldEncryptionKeys, "")
+		}
 	}
 
 	boolFlag(versionCmd.Flags(), &versionIncludesDeps, cliflags.Deps, false)
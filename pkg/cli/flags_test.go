@@ -108,6 +108,28 @@ func TestRaftTickIntervalFlagValue(t *testing.T) {
 	}
 }
 
+func TestMaxOffsetFlagValue(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	f := startCmd.Flags()
+	testData := []struct {
+		args     []string
+		expected time.Duration
+	}{
+		{nil, 250 * time.Millisecond},
+		{[]string{"--max-offset", "500ms"}, 500 * time.Millisecond},
+	}
+
+	for i, td := range testData {
+		if err := f.Parse(td.args); err != nil {
+			t.Fatal(err)
+		}
+		if td.expected != serverCfg.MaxOffset {
+			t.Errorf("%d. MaxOffset expected %d, but got %d", i, td.expected, serverCfg.MaxOffset)
+		}
+	}
+}
+
 func TestHttpHostFlagValue(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
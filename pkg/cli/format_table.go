@@ -0,0 +1,569 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util"
+)
+
+// tableDisplayFormat identifies the format in which result rows are
+// displayed by the client commands.
+type tableDisplayFormat int
+
+const (
+	// tableDisplayTSV prints the row count, then the column names and
+	// the rows as tab-separated values.
+	tableDisplayTSV tableDisplayFormat = iota
+	// tableDisplayCSV prints the column names and the rows as
+	// comma-separated values, quoted as per RFC 4180.
+	tableDisplayCSV
+	// tableDisplayJSON prints the rows as a JSON array of objects.
+	tableDisplayJSON
+	// tableDisplayHTML prints the rows as an HTML table.
+	tableDisplayHTML
+	// tableDisplayRecords prints every row as a vertical record, one
+	// line per column.
+	tableDisplayRecords
+	// tableDisplaySQL prints the rows as INSERT statements.
+	tableDisplaySQL
+	// tableDisplayRaw prints every value unescaped, preceded by its
+	// length, for consumption by programs.
+	tableDisplayRaw
+	// tableDisplayPretty prints the rows as a table using ASCII art.
+	tableDisplayPretty
+)
+
+var tableDisplayFormatNames = [...]string{
+	tableDisplayTSV:     "tsv",
+	tableDisplayCSV:     "csv",
+	tableDisplayJSON:    "json",
+	tableDisplayHTML:    "html",
+	tableDisplayRecords: "records",
+	tableDisplaySQL:     "sql",
+	tableDisplayRaw:     "raw",
+	tableDisplayPretty:  "pretty",
+}
+
+// String implements the pflag.Value interface.
+func (f *tableDisplayFormat) String() string {
+	return tableDisplayFormatNames[*f]
+}
+
+// Type implements the pflag.Value interface.
+func (f *tableDisplayFormat) Type() string {
+	return "string"
+}
+
+// Set implements the pflag.Value interface.
+func (f *tableDisplayFormat) Set(s string) error {
+	for i, name := range tableDisplayFormatNames {
+		if strings.EqualFold(s, name) {
+			*f = tableDisplayFormat(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid table display format: %s (possible values: %s)",
+		s, strings.Join(tableDisplayFormatNames[:], ", "))
+}
+
+// prettyValue implements the deprecated --pretty flag, which selects
+// between the pretty and the TSV display formats.
+type prettyValue struct {
+	format *tableDisplayFormat
+}
+
+func (p prettyValue) IsBoolFlag() bool {
+	return true
+}
+
+func (p prettyValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	if v {
+		*p.format = tableDisplayPretty
+	} else {
+		*p.format = tableDisplayTSV
+	}
+	return nil
+}
+
+func (p prettyValue) Type() string {
+	return "bool"
+}
+
+func (p prettyValue) String() string {
+	return fmt.Sprint(*p.format == tableDisplayPretty)
+}
+
+// rowIter iterates over the rows of a result. next returns io.EOF after
+// the last row.
+type rowIter interface {
+	next() ([]driver.Value, error)
+}
+
+// sqlRowsIter streams the rows of a SQL result.
+type sqlRowsIter struct {
+	rows *sqlRows
+	cols int
+}
+
+func (it sqlRowsIter) next() ([]driver.Value, error) {
+	vals := make([]driver.Value, it.cols)
+	if err := it.rows.Next(vals); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// stringRowsIter iterates over rows which were already converted to
+// strings.
+type stringRowsIter struct {
+	rows [][]string
+}
+
+func (it *stringRowsIter) next() ([]driver.Value, error) {
+	if len(it.rows) == 0 {
+		return nil, io.EOF
+	}
+	row := make([]driver.Value, len(it.rows[0]))
+	for i, s := range it.rows[0] {
+		row[i] = s
+	}
+	it.rows = it.rows[1:]
+	return row, nil
+}
+
+// rowReporter renders a result in one of the table display formats.
+// Except for the pretty and TSV formats, which need to see all the rows
+// before printing any of them, the rows are written as they are
+// received so that large results aren't buffered.
+type rowReporter interface {
+	// describe is called with the result columns before the first row.
+	describe(w io.Writer, cols []string) error
+	// iter is called for every row.
+	iter(w io.Writer, rowIdx int, row []driver.Value) error
+	// done is called after the last row with the number of rows.
+	done(w io.Writer, numRows int) error
+}
+
+func makeRowReporter(format tableDisplayFormat) rowReporter {
+	switch format {
+	case tableDisplayCSV:
+		return &csvReporter{}
+	case tableDisplayJSON:
+		return &jsonReporter{}
+	case tableDisplayHTML:
+		return &htmlReporter{}
+	case tableDisplayRecords:
+		return &recordsReporter{}
+	case tableDisplaySQL:
+		return &sqlReporter{}
+	case tableDisplayRaw:
+		return &rawReporter{}
+	case tableDisplayPretty:
+		return &prettyReporter{}
+	default:
+		return &tsvReporter{}
+	}
+}
+
+// render writes the rows produced by iter to w using the reporter.
+func render(w io.Writer, r rowReporter, cols []string, iter rowIter) error {
+	if err := r.describe(w, cols); err != nil {
+		return err
+	}
+	numRows := 0
+	for {
+		row, err := iter.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.iter(w, numRows, row); err != nil {
+			return err
+		}
+		numRows++
+	}
+	return r.done(w, numRows)
+}
+
+// rawVal formats a value without any escaping.
+func rawVal(val driver.Value) string {
+	switch t := val.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	}
+	return fmt.Sprint(val)
+}
+
+// finiteNumber returns the literal of a value which is an integer, a
+// finite float or a boolean, and whether the value is one of these.
+func finiteNumber(val driver.Value) (string, bool) {
+	switch t := val.(type) {
+	case int64:
+		return strconv.FormatInt(t, 10), true
+	case float64:
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(t, 'g', -1, 64), true
+	case bool:
+		return strconv.FormatBool(t), true
+	}
+	return "", false
+}
+
+type prettyReporter struct {
+	table *tablewriter.Table
+}
+
+func (p *prettyReporter) describe(w io.Writer, cols []string) error {
+	p.table = tablewriter.NewWriter(w)
+	p.table.SetAutoFormatHeaders(false)
+	p.table.SetAutoWrapText(false)
+	header := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = formatVal(c, true, false)
+	}
+	p.table.SetHeader(header)
+	return nil
+}
+
+func (p *prettyReporter) iter(_ io.Writer, _ int, row []driver.Value) error {
+	strs := make([]string, len(row))
+	for i, v := range row {
+		strs[i] = expandTabsAndNewLines(formatVal(v, true, true))
+	}
+	p.table.Append(strs)
+	return nil
+}
+
+func (p *prettyReporter) done(w io.Writer, numRows int) error {
+	p.table.Render()
+	fmt.Fprintf(w, "(%d row%s)\n", numRows, util.Pluralize(int64(numRows)))
+	return nil
+}
+
+type tsvReporter struct {
+	cols []string
+	rows [][]string
+}
+
+func (p *tsvReporter) describe(_ io.Writer, cols []string) error {
+	p.cols = make([]string, len(cols))
+	for i, c := range cols {
+		p.cols[i] = formatVal(c, false, false)
+	}
+	return nil
+}
+
+func (p *tsvReporter) iter(_ io.Writer, _ int, row []driver.Value) error {
+	strs := make([]string, len(row))
+	for i, v := range row {
+		strs[i] = formatVal(v, false, false)
+	}
+	p.rows = append(p.rows, strs)
+	return nil
+}
+
+func (p *tsvReporter) done(w io.Writer, numRows int) error {
+	// Inform the user about how much data to expect.
+	fmt.Fprintf(w, "%d row%s\n", numRows, util.Pluralize(int64(numRows)))
+	fmt.Fprintln(w, strings.Join(p.cols, "\t"))
+	for _, row := range p.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return nil
+}
+
+type csvReporter struct{}
+
+// csvField quotes a field if it is empty or contains characters that are
+// special to CSV. NULL is the only value printed as an unquoted empty
+// field, like PostgreSQL's COPY does.
+func csvField(val driver.Value) string {
+	if val == nil {
+		return ""
+	}
+	s := rawVal(val)
+	if s != "" && !strings.ContainsAny(s, ",\"\r\n") &&
+		s[0] != ' ' && s[0] != '\t' && s[len(s)-1] != ' ' && s[len(s)-1] != '\t' {
+		return s
+	}
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
+
+func (p *csvReporter) writeRow(w io.Writer, row []driver.Value) error {
+	var buf bytes.Buffer
+	for i, v := range row {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(csvField(v))
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *csvReporter) describe(w io.Writer, cols []string) error {
+	header := make([]driver.Value, len(cols))
+	for i, c := range cols {
+		header[i] = c
+	}
+	return p.writeRow(w, header)
+}
+
+func (p *csvReporter) iter(w io.Writer, _ int, row []driver.Value) error {
+	return p.writeRow(w, row)
+}
+
+func (p *csvReporter) done(io.Writer, int) error {
+	return nil
+}
+
+type jsonReporter struct {
+	keys []string
+}
+
+func jsonVal(val driver.Value) (string, error) {
+	if val == nil {
+		return "null", nil
+	}
+	if s, ok := finiteNumber(val); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(rawVal(val))
+	return string(b), err
+}
+
+func (p *jsonReporter) describe(w io.Writer, cols []string) error {
+	p.keys = make([]string, len(cols))
+	for i, c := range cols {
+		b, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		p.keys[i] = string(b)
+	}
+	_, err := io.WriteString(w, "[")
+	return err
+}
+
+func (p *jsonReporter) iter(w io.Writer, rowIdx int, row []driver.Value) error {
+	var buf bytes.Buffer
+	if rowIdx > 0 {
+		buf.WriteByte(',')
+	}
+	buf.WriteString("\n  {")
+	for i, v := range row {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		s, err := jsonVal(v)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&buf, "%s: %s", p.keys[i], s)
+	}
+	buf.WriteByte('}')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *jsonReporter) done(w io.Writer, numRows int) error {
+	if numRows > 0 {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, "]")
+	return nil
+}
+
+type htmlReporter struct {
+	numCols int
+}
+
+func htmlVal(val driver.Value) string {
+	if val == nil {
+		return "<i>NULL</i>"
+	}
+	return strings.Replace(html.EscapeString(rawVal(val)), "\n", "<br/>", -1)
+}
+
+func (p *htmlReporter) describe(w io.Writer, cols []string) error {
+	p.numCols = len(cols)
+	var buf bytes.Buffer
+	buf.WriteString("<table>\n<thead><tr>")
+	for _, c := range cols {
+		fmt.Fprintf(&buf, "<th>%s</th>", html.EscapeString(c))
+	}
+	buf.WriteString("</tr></thead>\n<tbody>\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *htmlReporter) iter(w io.Writer, _ int, row []driver.Value) error {
+	var buf bytes.Buffer
+	buf.WriteString("<tr>")
+	for _, v := range row {
+		fmt.Fprintf(&buf, "<td>%s</td>", htmlVal(v))
+	}
+	buf.WriteString("</tr>\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *htmlReporter) done(w io.Writer, numRows int) error {
+	fmt.Fprintf(w, "</tbody>\n<tfoot><tr><td colspan=%d>%d row%s</td></tr></tfoot></table>\n",
+		p.numCols, numRows, util.Pluralize(int64(numRows)))
+	return nil
+}
+
+type recordsReporter struct {
+	cols  []string
+	width int
+}
+
+func (p *recordsReporter) describe(_ io.Writer, cols []string) error {
+	p.cols = make([]string, len(cols))
+	p.width = 0
+	for i, c := range cols {
+		p.cols[i] = formatVal(c, true, false)
+		if n := utf8.RuneCountInString(p.cols[i]); n > p.width {
+			p.width = n
+		}
+	}
+	return nil
+}
+
+func (p *recordsReporter) iter(w io.Writer, rowIdx int, row []driver.Value) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "-[ RECORD %d ]\n", rowIdx+1)
+	for i, v := range row {
+		name := p.cols[i]
+		for _, line := range strings.Split(expandTabsAndNewLines(formatVal(v, true, true)), "\n") {
+			buf.WriteString(name)
+			buf.WriteString(strings.Repeat(" ", p.width-utf8.RuneCountInString(name)))
+			buf.WriteString(" |")
+			if line != "" {
+				buf.WriteByte(' ')
+				buf.WriteString(line)
+			}
+			buf.WriteByte('\n')
+			name = ""
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *recordsReporter) done(w io.Writer, numRows int) error {
+	fmt.Fprintf(w, "(%d row%s)\n", numRows, util.Pluralize(int64(numRows)))
+	return nil
+}
+
+// sqlReporter prints the rows as INSERT statements into a table called
+// "results".
+type sqlReporter struct {
+	insert string
+}
+
+func sqlVal(val driver.Value) string {
+	if val == nil {
+		return "NULL"
+	}
+	if s, ok := finiteNumber(val); ok {
+		return s
+	}
+	if b, ok := val.([]byte); ok {
+		return parser.AsString(parser.NewDBytes(parser.DBytes(b)))
+	}
+	return parser.AsString(parser.NewDString(rawVal(val)))
+}
+
+func (p *sqlReporter) describe(_ io.Writer, cols []string) error {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = parser.AsString(parser.Name(c))
+	}
+	p.insert = fmt.Sprintf("INSERT INTO results(%s) VALUES (", strings.Join(names, ", "))
+	return nil
+}
+
+func (p *sqlReporter) iter(w io.Writer, _ int, row []driver.Value) error {
+	vals := make([]string, len(row))
+	for i, v := range row {
+		vals[i] = sqlVal(v)
+	}
+	_, err := fmt.Fprintf(w, "%s%s);\n", p.insert, strings.Join(vals, ", "))
+	return err
+}
+
+func (p *sqlReporter) done(w io.Writer, numRows int) error {
+	fmt.Fprintf(w, "-- %d row%s\n", numRows, util.Pluralize(int64(numRows)))
+	return nil
+}
+
+// rawReporter prints every value preceded by a line containing its
+// length in bytes, so that values containing newlines can be told
+// apart.
+type rawReporter struct{}
+
+func (p *rawReporter) describe(w io.Writer, cols []string) error {
+	fmt.Fprintf(w, "# %d column%s\n", len(cols), util.Pluralize(int64(len(cols))))
+	return nil
+}
+
+func (p *rawReporter) iter(w io.Writer, rowIdx int, row []driver.Value) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# row %d\n", rowIdx+1)
+	for _, v := range row {
+		if v == nil {
+			buf.WriteString("## NULL\n")
+			continue
+		}
+		s := rawVal(v)
+		fmt.Fprintf(&buf, "## %d\n%s\n", len(s), s)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (p *rawReporter) done(w io.Writer, numRows int) error {
+	fmt.Fprintf(w, "# %d row%s\n", numRows, util.Pluralize(int64(numRows)))
+	return nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"io"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+type valueRowsIter struct {
+	rows [][]driver.Value
+}
+
+func (it *valueRowsIter) next() ([]driver.Value, error) {
+	if len(it.rows) == 0 {
+		return nil, io.EOF
+	}
+	row := it.rows[0]
+	it.rows = it.rows[1:]
+	return row, nil
+}
+
+func TestRenderTableDisplayFormats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	cols := []string{"a", "b c"}
+	rows := [][]driver.Value{
+		{int64(1), "x,\"y\""},
+		{nil, "line1\nline2"},
+		{float64(1.5), ""},
+	}
+
+	testCases := []struct {
+		format   tableDisplayFormat
+		expected string
+	}{
+		{tableDisplayTSV, "3 rows\n" +
+			"a\tb c\n" +
+			"1\t\"x,\\\"y\\\"\"\n" +
+			"NULL\t\"line1\\nline2\"\n" +
+			"1.5\t\n"},
+		{tableDisplayCSV, `a,b c
+1,"x,""y"""
+,"line1
+line2"
+1.5,""
+`},
+		{tableDisplayJSON, `[
+  {"a": 1, "b c": "x,\"y\""},
+  {"a": null, "b c": "line1\nline2"},
+  {"a": 1.5, "b c": ""}
+]
+`},
+		{tableDisplayHTML, `<table>
+<thead><tr><th>a</th><th>b c</th></tr></thead>
+<tbody>
+<tr><td>1</td><td>x,&#34;y&#34;</td></tr>
+<tr><td><i>NULL</i></td><td>line1<br/>line2</td></tr>
+<tr><td>1.5</td><td></td></tr>
+</tbody>
+<tfoot><tr><td colspan=2>3 rows</td></tr></tfoot></table>
+`},
+		{tableDisplayRecords, `-[ RECORD 1 ]
+a   | 1
+b c | x,"y"
+-[ RECORD 2 ]
+a   | NULL
+b c | line1␤
+    | line2
+-[ RECORD 3 ]
+a   | 1.5
+b c |
+(3 rows)
+`},
+		{tableDisplaySQL, `INSERT INTO results(a, "b c") VALUES (1, 'x,"y"');
+INSERT INTO results(a, "b c") VALUES (NULL, e'line1\nline2');
+INSERT INTO results(a, "b c") VALUES (1.5, '');
+-- 3 rows
+`},
+		{tableDisplayRaw, `# 2 columns
+# row 1
+## 1
+1
+## 5
+x,"y"
+# row 2
+## NULL
+## 11
+line1
+line2
+# row 3
+## 3
+1.5
+## 0
+
+# 3 rows
+`},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		if err := render(&buf, makeRowReporter(tc.format), cols,
+			&valueRowsIter{rows: rows}); err != nil {
+			t.Fatal(err)
+		}
+		if a, e := buf.String(), tc.expected; a != e {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", &tc.format, e, a)
+		}
+	}
+}
+
+func TestTableDisplayFormatFlag(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var f tableDisplayFormat
+	for i, name := range tableDisplayFormatNames {
+		if err := f.Set(name); err != nil {
+			t.Fatal(err)
+		}
+		if f != tableDisplayFormat(i) || f.String() != name {
+			t.Errorf("expected %s, but got %s", name, &f)
+		}
+	}
+	if err := f.Set("CSV"); err != nil || f != tableDisplayCSV {
+		t.Errorf("expected csv, but got %s (err: %v)", &f, err)
+	}
+	if err := f.Set("xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+
+	p := prettyValue{format: &f}
+	if err := p.Set("true"); err != nil || f != tableDisplayPretty {
+		t.Errorf("expected pretty, but got %s (err: %v)", &f, err)
+	}
+	if err := p.Set("false"); err != nil || f != tableDisplayTSV {
+		t.Errorf("expected tsv, but got %s (err: %v)", &f, err)
+	}
+}
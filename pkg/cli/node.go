@@ -24,7 +24,9 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/server/status"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -72,8 +74,7 @@ func runLsNodes(cmd *cobra.Command, args []string) error {
 		})
 	}
 
-	printQueryOutput(os.Stdout, lsNodesColumnHeaders, rows, "", cliCtx.prettyFmt)
-	return nil
+	return printQueryOutput(os.Stdout, lsNodesColumnHeaders, rows, "", cliCtx.tableDisplayFormat)
 }
 
 var nodesColumnHeaders = []string{
@@ -140,8 +141,8 @@ func runStatusNode(cmd *cobra.Command, args []string) error {
 		return errors.Errorf("expected no arguments or a single node ID")
 	}
 
-	printQueryOutput(os.Stdout, nodesColumnHeaders, nodeStatusesToRows(nodeStatuses), "", cliCtx.prettyFmt)
-	return nil
+	return printQueryOutput(os.Stdout, nodesColumnHeaders,
+		nodeStatusesToRows(nodeStatuses), "", cliCtx.tableDisplayFormat)
 }
 
 // nodeStatusesToRows converts NodeStatuses to SQL-like result rows, so that we can pretty-print
@@ -183,16 +184,165 @@ func nodeStatusesToRows(statuses []status.NodeStatus) [][]string {
 	return rows
 }
 
+var decommissionStatusColumnHeaders = []string{
+	"id",
+	"is_live",
+	"replicas",
+	"decommissioning",
+}
+
+var decommissionNodeCmd = &cobra.Command{
+	Use:   "decommission <node id 1> [<node id 2> ...]",
+	Short: "decommissions the node(s)",
+	Long: `
+	Marks the nodes with the supplied IDs as decommissioning. This causes
+	leases and replicas to be moved off the nodes. The remaining replica count
+	of each node is displayed; with --wait, the command waits until all
+	replicas have been moved off the nodes.
+	`,
+	SilenceUsage: true,
+	RunE:         maybeDecorateGRPCError(runDecommissionNode),
+}
+
+func runDecommissionNode(cmd *cobra.Command, args []string) error {
+	nodeIDs, err := parseNodeIDs(args)
+	if err != nil {
+		return err
+	}
+	if len(nodeIDs) == 0 {
+		return usageAndError(cmd)
+	}
+
+	c, stopper, err := getAdminClient()
+	if err != nil {
+		return err
+	}
+	defer stopper.Stop()
+
+	ctx := stopperContext(stopper)
+	resp, err := c.Decommission(ctx, &serverpb.DecommissionRequest{
+		NodeIDs:         nodeIDs,
+		Decommissioning: true,
+	})
+	if err != nil {
+		return err
+	}
+	if decommissionWait {
+		if resp, err = waitForDecommission(ctx, c, nodeIDs); err != nil {
+			return err
+		}
+	}
+	return printQueryOutput(os.Stdout, decommissionStatusColumnHeaders,
+		decommissionStatusesToRows(resp.Status), "", cliCtx.tableDisplayFormat)
+}
+
+// waitForDecommission polls the decommissioning status of the supplied
+// nodes until no replicas remain on any of them.
+func waitForDecommission(
+	ctx context.Context, c serverpb.AdminClient, nodeIDs []roachpb.NodeID,
+) (*serverpb.DecommissionStatusResponse, error) {
+	for {
+		resp, err := c.DecommissionStatus(ctx, &serverpb.DecommissionStatusRequest{
+			NodeIDs: nodeIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var replicaCount int64
+		for _, status := range resp.Status {
+			replicaCount += status.ReplicaCount
+		}
+		if replicaCount == 0 {
+			return resp, nil
+		}
+		fmt.Fprintf(os.Stderr, "waiting for %d replica(s) to be moved off the node(s)\n", replicaCount)
+		select {
+		case <-time.After(5 * time.Second):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+var recommissionNodeCmd = &cobra.Command{
+	Use:   "recommission <node id 1> [<node id 2> ...]",
+	Short: "recommissions the node(s)",
+	Long: `
+	Clears the decommissioning flag of the nodes with the supplied IDs, allowing
+	replicas and leases to be placed on them again.
+	`,
+	SilenceUsage: true,
+	RunE:         maybeDecorateGRPCError(runRecommissionNode),
+}
+
+func runRecommissionNode(cmd *cobra.Command, args []string) error {
+	nodeIDs, err := parseNodeIDs(args)
+	if err != nil {
+		return err
+	}
+	if len(nodeIDs) == 0 {
+		return usageAndError(cmd)
+	}
+
+	c, stopper, err := getAdminClient()
+	if err != nil {
+		return err
+	}
+	defer stopper.Stop()
+
+	resp, err := c.Decommission(stopperContext(stopper), &serverpb.DecommissionRequest{
+		NodeIDs:         nodeIDs,
+		Decommissioning: false,
+	})
+	if err != nil {
+		return err
+	}
+	return printQueryOutput(os.Stdout, decommissionStatusColumnHeaders,
+		decommissionStatusesToRows(resp.Status), "", cliCtx.tableDisplayFormat)
+}
+
+// parseNodeIDs converts the supplied command line arguments into node IDs.
+func parseNodeIDs(args []string) ([]roachpb.NodeID, error) {
+	nodeIDs := make([]roachpb.NodeID, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse %s as node ID", arg)
+		}
+		nodeIDs = append(nodeIDs, roachpb.NodeID(id))
+	}
+	return nodeIDs, nil
+}
+
+// decommissionStatusesToRows converts decommissioning statuses to SQL-like
+// result rows, so that we can pretty-print them.
+func decommissionStatusesToRows(
+	statuses []serverpb.DecommissionStatusResponse_Status,
+) [][]string {
+	var rows [][]string
+	for _, status := range statuses {
+		rows = append(rows, []string{
+			strconv.FormatInt(int64(status.NodeID), 10),
+			strconv.FormatBool(status.IsLive),
+			strconv.FormatInt(status.ReplicaCount, 10),
+			strconv.FormatBool(status.Decommissioning),
+		})
+	}
+	return rows
+}
+
 // Sub-commands for node command.
 var nodeCmds = []*cobra.Command{
 	lsNodesCmd,
 	statusNodeCmd,
+	decommissionNodeCmd,
+	recommissionNodeCmd,
 }
 
 var nodeCmd = &cobra.Command{
 	Use:   "node [command]",
-	Short: "list nodes and show their status",
-	Long:  "List nodes and show their status.",
+	Short: "list, inspect or decommission nodes",
+	Long:  "List, inspect or decommission nodes.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return cmd.Usage()
 	},
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/url"
 	"os"
 	"os/exec"
@@ -34,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/spf13/cobra"
 )
 
@@ -77,6 +79,8 @@ type cliState struct {
 	fullPrompt string
 	// The prompt on a continuation line in a multi-line entry.
 	continuePrompt string
+	// Determines whether to print the execution time of statements.
+	showTimes bool
 
 	// State
 	//
@@ -114,6 +118,10 @@ type cliState struct {
 	// new lines of input.
 	syntax parser.Syntax
 
+	// completer completes the input in the interactive shell. Its current
+	// database is updated along with the syntax.
+	completer *sqlCompleter
+
 	// exitErr defines the error to report to the user upon termination.
 	// This can carry over from one line of input to another. For
 	// example in the interactive shell, a statement causing a SQL
@@ -121,6 +129,18 @@ type cliState struct {
 	// by Ctrl+D, causes the shell to terminate with an error --
 	// reporting the status of the last valid SQL statement executed.
 	exitErr error
+
+	// output is the file query results are written to, set with \o. The
+	// results go to the standard output when it is nil.
+	output *os.File
+
+	// lastStatement is the SQL text most recently sent to the server,
+	// which \e edits when there is no input so far.
+	lastStatement string
+
+	// history is the command-line history, including the entries loaded
+	// from the history file, which \s searches.
+	history []string
 }
 
 // cliStateEnum drives the CLI state machine in runInteractive().
@@ -173,10 +193,25 @@ Type: \q to exit (Ctrl+C/Ctrl+D also supported)
   \! CMD            run an external command and print its results on standard output.
   \| CMD            run an external command and run its output as SQL statements.
   \set [NAME]       set a client-side flag or (without argument) print the current settings.
+  \set display_format FORMAT
+                    set the format of result tables (tsv, csv, json, html, records, sql, raw, pretty).
   \unset NAME       unset a flag.
   \show             during a multi-line statement or transaction, show the SQL entered so far.
+  \l                list the databases.
+  \dt [DB]          list the tables of the current database or of DB.
+  \d [TABLE]        list the tables of the current database, or describe the columns of TABLE.
+  \du               list the users.
+  \di [TABLE]       list the indexes of the current database or of TABLE.
+  \timing           toggle the display of the execution time of statements.
+  \i FILE           run the SQL statements in FILE.
+  \o [FILE]         send query results to FILE or (without argument) back to the standard output.
+  \e                edit the current input, or the last statement, with $EDITOR and run it.
+  \s [PATTERN]      print the command-line history, or the entries containing PATTERN.
   \? or "help"      print this help.
 
+Press Tab to complete keywords and database, table and column names,
+and Ctrl+R to search the command-line history.
+
 More documentation about our SQL dialect is available online:
 http://www.cockroachlabs.com/docs/
 
@@ -189,6 +224,7 @@ func (c *cliState) addHistory(line string) {
 	if !isInteractive {
 		return
 	}
+	c.history = append(c.history, line)
 
 	// ins.SaveHistory will push command into memory and try to
 	// persist to disk (if ins's config.HistoryFile is set).  err can
@@ -224,10 +260,28 @@ func (c *cliState) invalidOptionChange(nextState cliStateEnum, opt string) cliSt
 
 // handleSet supports the \set client-side command.
 func (c *cliState) handleSet(args []string, nextState, errState cliStateEnum) cliStateEnum {
-	if len(args) != 1 {
+	if len(args) == 1 {
+		// Also accept "\set NAME=VALUE".
+		args = strings.SplitN(args[0], "=", 2)
+	}
+	if len(args) == 0 || len(args) > 2 {
 		return c.invalidSyntax(errState, `\set %s. Try \? for help.`, strings.Join(args, " "))
 	}
 	opt := strings.ToLower(args[0])
+	if opt == `display_format` {
+		if len(args) != 2 {
+			return c.invalidSyntax(errState, `\set %s requires a value. Try \? for help.`, opt)
+		}
+		if err := cliCtx.tableDisplayFormat.Set(args[1]); err != nil {
+			fmt.Fprintln(osStderr, err)
+			c.exitErr = err
+			return errState
+		}
+		return nextState
+	}
+	if len(args) != 1 {
+		return c.invalidSyntax(errState, `\set %s. Try \? for help.`, strings.Join(args, " "))
+	}
 	switch opt {
 	case `errexit`:
 		c.errExit = true
@@ -266,6 +320,11 @@ func (c *cliState) handleUnset(args []string, nextState, errState cliStateEnum)
 			return c.invalidOptionChange(errState, opt)
 		}
 		c.normalizeHistory = false
+	case `display_format`:
+		cliCtx.tableDisplayFormat = tableDisplayTSV
+		if isInteractive {
+			cliCtx.tableDisplayFormat = tableDisplayPretty
+		}
 	default:
 		return c.invalidOption(errState, opt)
 	}
@@ -346,6 +405,174 @@ func (c *cliState) pipeSyscmd(line string, nextState, errState cliStateEnum) cli
 	return nextState
 }
 
+// describeQuery returns the query run by one of the \l and \d family of
+// client-side commands with the given arguments.
+func describeQuery(cmd string, args []string) (string, error) {
+	if len(args) > 1 || (len(args) > 0 && (cmd == `\l` || cmd == `\du`)) {
+		return "", errors.New("too many arguments")
+	}
+	switch cmd {
+	case `\l`:
+		return `SHOW DATABASES`, nil
+	case `\du`:
+		return `SHOW USERS`, nil
+	case `\dt`:
+		if len(args) == 0 {
+			return `SHOW TABLES`, nil
+		}
+		return `SHOW TABLES FROM ` + parser.AsString(parser.Name(args[0])), nil
+	case `\d`, `\di`:
+		if len(args) == 0 {
+			if cmd == `\d` {
+				return `SHOW TABLES`, nil
+			}
+			return `SELECT table_name, index_name, non_unique, seq_in_index, column_name, direction, storing
+  FROM information_schema.statistics WHERE table_schema = current_schema()
+  ORDER BY table_name, index_name, seq_in_index`, nil
+		}
+		tn, err := parser.ParseTableNameTraditional(args[0])
+		if err != nil {
+			return "", err
+		}
+		if cmd == `\d` {
+			return `SHOW COLUMNS FROM ` + tn.String(), nil
+		}
+		return `SHOW INDEXES FROM ` + tn.String(), nil
+	}
+	return "", fmt.Errorf("unknown command: %s", cmd)
+}
+
+// runMetaQuery runs the query of a client-side command and prints its
+// results.
+func (c *cliState) runMetaQuery(query string, nextState, errState cliStateEnum) cliStateEnum {
+	if err := runQueryAndFormatResults(c.conn, c.outputWriter(), makeQuery(query),
+		cliCtx.tableDisplayFormat); err != nil {
+		fmt.Fprintln(osStderr, err)
+		c.exitErr = err
+		return errState
+	}
+	return nextState
+}
+
+// outputWriter returns where query results are written.
+func (c *cliState) outputWriter() io.Writer {
+	if c.output != nil {
+		return c.output
+	}
+	return os.Stdout
+}
+
+// setOutput supports the \o client-side command, which sends query
+// results to a file, or back to the standard output without argument.
+func (c *cliState) setOutput(args []string, nextState, errState cliStateEnum) cliStateEnum {
+	if len(args) > 1 {
+		fmt.Fprintf(osStderr, "Usage:\n  \\o [file]\n")
+		c.exitErr = errInvalidSyntax
+		return errState
+	}
+	if c.output != nil {
+		if err := c.output.Close(); err != nil {
+			fmt.Fprintf(osStderr, "cannot close %s: %s\n", c.output.Name(), err)
+		}
+		c.output = nil
+	}
+	if len(args) == 0 {
+		return nextState
+	}
+	f, err := os.OpenFile(args[0], os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		fmt.Fprintln(osStderr, err)
+		c.exitErr = err
+		return errState
+	}
+	c.output = f
+	return nextState
+}
+
+// includeFile supports the \i client-side command, which reads SQL
+// statements from a file and runs them as if they had been entered.
+func (c *cliState) includeFile(args []string, nextState, errState cliStateEnum) cliStateEnum {
+	if len(args) != 1 {
+		fmt.Fprintf(osStderr, "Usage:\n  \\i [file]\n")
+		c.exitErr = errInvalidSyntax
+		return errState
+	}
+	contents, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		fmt.Fprintln(osStderr, err)
+		c.exitErr = err
+		return errState
+	}
+	c.lastInputLine = string(contents)
+	return nextState
+}
+
+// editStatement supports the \e client-side command, which opens the
+// input entered so far, or the last statement if there is none, in the
+// user's editor and then processes the edited text as input, replacing
+// the previous input.
+func (c *cliState) editStatement(nextState, errState cliStateEnum) cliStateEnum {
+	if !isInteractive {
+		fmt.Fprintln(osStderr, `\e is only supported in interactive sessions`)
+		c.exitErr = errInvalidSyntax
+		return errState
+	}
+	text := strings.Join(c.partialLines, "\n")
+	if text == "" {
+		text = c.lastStatement
+	}
+
+	f, err := ioutil.TempFile("", "cockroach-sql")
+	if err != nil {
+		fmt.Fprintln(osStderr, err)
+		c.exitErr = err
+		return errState
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	_, err = f.WriteString(text + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		editor := os.Getenv("VISUAL")
+		if editor == "" {
+			editor = os.Getenv("EDITOR")
+		}
+		if editor == "" {
+			editor = "vi"
+		}
+		args := strings.Fields(editor)
+		cmd := exec.Command(args[0], append(args[1:], f.Name())...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		err = cmd.Run()
+	}
+	var contents []byte
+	if err == nil {
+		contents, err = ioutil.ReadFile(f.Name())
+	}
+	if err != nil {
+		fmt.Fprintf(osStderr, "cannot edit input: %s\n", err)
+		c.exitErr = err
+		return errState
+	}
+
+	c.partialLines = c.partialLines[:0]
+	c.partialStmtsLen = 0
+	c.lastInputLine = strings.TrimSpace(string(contents))
+	return nextState
+}
+
+// printHistory supports the \s client-side command, which prints the
+// command-line history entries containing the pattern, ignoring case.
+func (c *cliState) printHistory(pattern string) {
+	pattern = strings.ToLower(pattern)
+	for i, line := range c.history {
+		if strings.Contains(strings.ToLower(line), pattern) {
+			fmt.Printf("%5d  %s\n", i+1, line)
+		}
+	}
+}
+
 // preparePrompts computes a full and short prompt for the interactive
 // CLI.
 func preparePrompts(dbURL string) (fullPrompt string, continuePrompt string) {
@@ -412,8 +639,16 @@ func (c *cliState) doStart(nextState cliStateEnum) cliStateEnum {
 			cfg.HistoryFile = histFile
 			cfg.HistorySearchFold = true
 			c.ins.SetConfig(cfg)
+			if contents, err := ioutil.ReadFile(histFile); err == nil {
+				c.history = strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+			}
 		}
 
+		c.completer = newSQLCompleter(c.conn.url)
+		cfg := c.ins.Config.Clone()
+		cfg.AutoComplete = c.completer
+		c.ins.SetConfig(cfg)
+
 		// The user only gets to see the info screen on interactive session.
 		fmt.Print(infoMessage)
 
@@ -445,6 +680,14 @@ func getSyntax(conn *sqlConn) (parser.Syntax, error) {
 	return 0, fmt.Errorf("unknown syntax: %s", rows[0][0])
 }
 
+func getDatabase(conn *sqlConn) (string, error) {
+	_, rows, _, err := runQuery(conn, makeQuery("SHOW DATABASE"), false)
+	if err != nil {
+		return "", err
+	}
+	return rows[0][0], nil
+}
+
 func (c *cliState) doQuerySyntax(nextState cliStateEnum) cliStateEnum {
 	if !c.querySyntax {
 		return nextState
@@ -457,6 +700,13 @@ func (c *cliState) doQuerySyntax(nextState cliStateEnum) cliStateEnum {
 	} else {
 		c.syntax = newSyntax
 	}
+	if c.completer != nil {
+		// SET may also have changed the current database, which the
+		// completer needs since it doesn't run in the session.
+		if database, err := getDatabase(c.conn); err == nil {
+			c.completer.database = database
+		}
+	}
 	return nextState
 }
 
@@ -606,6 +856,33 @@ func (c *cliState) doHandleCliCmd(loopState, nextState cliStateEnum) cliStateEnu
 	case `\|`:
 		return c.pipeSyscmd(c.lastInputLine, nextState, errState)
 
+	case `\l`, `\dt`, `\d`, `\du`, `\di`:
+		query, err := describeQuery(cmd[0], cmd[1:])
+		if err != nil {
+			return c.invalidSyntax(errState, `%s: %v. Try \? for help.`, c.lastInputLine, err)
+		}
+		return c.runMetaQuery(query, loopState, errState)
+
+	case `\timing`:
+		c.showTimes = !c.showTimes
+		if c.showTimes {
+			fmt.Println("Timing is on.")
+		} else {
+			fmt.Println("Timing is off.")
+		}
+
+	case `\i`:
+		return c.includeFile(cmd[1:], nextState, errState)
+
+	case `\o`:
+		return c.setOutput(cmd[1:], loopState, errState)
+
+	case `\e`:
+		return c.editStatement(nextState, errState)
+
+	case `\s`:
+		c.printHistory(strings.TrimSpace(c.lastInputLine[len(cmd[0]):]))
+
 	default:
 		if strings.HasPrefix(cmd[0], `\d`) {
 			// Unrecognized command for now, but we want to be helpful.
@@ -736,7 +1013,13 @@ func (c *cliState) doCheckStatement(startState, contState, execState cliStateEnu
 }
 
 func (c *cliState) doRunStatement(nextState cliStateEnum) cliStateEnum {
-	c.exitErr = runQueryAndFormatResults(c.conn, os.Stdout, makeQuery(c.concatLines), cliCtx.prettyFmt)
+	c.lastStatement = c.concatLines
+	start := timeutil.Now()
+	c.exitErr = runQueryAndFormatResults(c.conn, c.outputWriter(), makeQuery(c.concatLines),
+		cliCtx.tableDisplayFormat)
+	if c.showTimes {
+		fmt.Printf("Time: %s\n", timeutil.Since(start))
+	}
 	if c.exitErr != nil {
 		fmt.Fprintln(osStderr, c.exitErr)
 		if c.errExit {
@@ -777,6 +1060,16 @@ func runInteractive(conn *sqlConn, config *readline.Config) (exitErr error) {
 				return c.exitErr
 			}
 			defer func() { _ = c.ins.Close() }()
+			defer func() {
+				if c.completer != nil {
+					c.completer.Close()
+				}
+			}()
+			defer func() {
+				if c.output != nil {
+					_ = c.output.Close()
+				}
+			}()
 
 			state = c.doStart(cliQuerySyntax)
 
@@ -822,9 +1115,9 @@ func runInteractive(conn *sqlConn, config *readline.Config) (exitErr error) {
 
 // runOneStatement executes one statement and terminates
 // on error.
-func runStatements(conn *sqlConn, stmts []string, pretty bool) error {
+func runStatements(conn *sqlConn, stmts []string, displayFormat tableDisplayFormat) error {
 	for _, stmt := range stmts {
-		if err := runQueryAndFormatResults(conn, os.Stdout, makeQuery(stmt), pretty); err != nil {
+		if err := runQueryAndFormatResults(conn, os.Stdout, makeQuery(stmt), displayFormat); err != nil {
 			return err
 		}
 	}
@@ -850,7 +1143,7 @@ func runTerm(cmd *cobra.Command, args []string) error {
 
 	if len(sqlCtx.execStmts) > 0 {
 		// Single-line sql; run as simple as possible, without noise on stdout.
-		return runStatements(conn, sqlCtx.execStmts, cliCtx.prettyFmt)
+		return runStatements(conn, sqlCtx.execStmts, cliCtx.tableDisplayFormat)
 	}
 	// Use the same as the default global readline config.
 	conf := readline.Config{
@@ -0,0 +1,151 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"database/sql/driver"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+)
+
+const (
+	// completeNamesQuery retrieves the names of the databases and of the
+	// tables and columns of the database $1.
+	completeNamesQuery = `
+SELECT schema_name FROM information_schema.schemata
+UNION SELECT table_name FROM information_schema.tables WHERE table_schema = $1
+UNION SELECT column_name FROM information_schema.columns WHERE table_schema = $1`
+
+	// completeQualifiedNamesQuery retrieves the names which can follow
+	// the qualifier $1: the tables of the database $1 and the columns of
+	// the table $1 in the database $2.
+	completeQualifiedNamesQuery = `
+SELECT table_name FROM information_schema.tables WHERE table_schema = $1
+UNION SELECT column_name FROM information_schema.columns
+  WHERE table_schema = $2 AND table_name = $1`
+)
+
+// sqlCompleter implements readline.AutoCompleter for the interactive
+// shell. It completes SQL keywords and the names of the databases,
+// tables and columns, which it looks up on the server every time so
+// that they are never stale.
+//
+// The names are looked up on a connection of the completer's own, so that
+// completion doesn't run statements in the user's session, where they
+// could e.g. become part of, or fail, an open transaction. The session's
+// current database is tracked by the shell instead.
+type sqlCompleter struct {
+	conn *sqlConn
+	// database is the current database of the user's session.
+	database string
+}
+
+func newSQLCompleter(url string) *sqlCompleter {
+	return &sqlCompleter{conn: makeSQLConn(url)}
+}
+
+// Close closes the completer's connection.
+func (sc *sqlCompleter) Close() {
+	sc.conn.Close()
+}
+
+// Do implements the readline.AutoCompleter interface. It returns the
+// suffixes completing the word before the cursor, and the length of that
+// word in runes.
+func (sc *sqlCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	prefix := completionPrefix(line[:pos])
+	if i := strings.LastIndexByte(prefix, '.'); i >= 0 {
+		word := prefix[i+1:]
+		return completions(nil, sc.names(completeQualifiedNamesQuery, prefix[:i], sc.database), word),
+			utf8.RuneCountInString(word)
+	}
+	if prefix == "" {
+		// Don't list every keyword.
+		return nil, 0
+	}
+	var keywords []string
+	if !strings.HasPrefix(strings.TrimSpace(string(line)), `\`) {
+		// Client-side commands take names, not SQL.
+		keywords = parser.Keywords()
+	}
+	return completions(keywords, sc.names(completeNamesQuery, sc.database), prefix),
+		utf8.RuneCountInString(prefix)
+}
+
+// names runs a query returning names, ignoring errors: completion is
+// best-effort and must not disturb the user's input.
+func (sc *sqlCompleter) names(query string, args ...driver.Value) []string {
+	_, rows, _, err := runQuery(sc.conn, makeQuery(query, args...), true)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, len(rows))
+	for i, row := range rows {
+		names[i] = row[0]
+	}
+	return names
+}
+
+// completionPrefix returns the (possibly qualified) name at the end of
+// the line.
+func completionPrefix(line []rune) string {
+	i := len(line)
+	for i > 0 {
+		r := line[i-1]
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$' && r != '.' {
+			break
+		}
+		i--
+	}
+	return string(line[i:])
+}
+
+// completions returns the sorted suffixes of the keywords and names
+// starting with word, ignoring case. Keywords are suggested in lower case
+// if word contains lower case letters.
+func completions(keywords, names []string, word string) [][]rune {
+	lower := strings.ToUpper(word) != word
+	seen := make(map[string]struct{})
+	var matches []string
+	add := func(candidate string) {
+		if len(candidate) < len(word) || !strings.EqualFold(candidate[:len(word)], word) {
+			return
+		}
+		if _, ok := seen[candidate]; ok {
+			return
+		}
+		seen[candidate] = struct{}{}
+		matches = append(matches, candidate)
+	}
+	for _, k := range keywords {
+		if lower {
+			k = strings.ToLower(k)
+		}
+		add(k)
+	}
+	for _, n := range names {
+		add(n)
+	}
+	sort.Strings(matches)
+	result := make([][]rune, len(matches))
+	for i, m := range matches {
+		result[i] = []rune(m[len(word):])
+	}
+	return result
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestCompletionPrefix(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		line     string
+		expected string
+	}{
+		{``, ``},
+		{`SEL`, `SEL`},
+		{`SELECT * FROM `, ``},
+		{`SELECT * FROM db.t`, `db.t`},
+		{`SELECT a,b`, `b`},
+		{`SELECT (κό`, `κό`},
+		{`\d sys`, `sys`},
+	}
+	for _, tc := range testCases {
+		if prefix := completionPrefix([]rune(tc.line)); prefix != tc.expected {
+			t.Errorf("%q: expected %q, but got %q", tc.line, tc.expected, prefix)
+		}
+	}
+}
+
+func TestCompletions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	keywords := []string{"SELECT", "SET", "SHOW", "TABLE"}
+	names := []string{"system", "t", "table", "users"}
+	testCases := []struct {
+		word     string
+		expected []string
+	}{
+		{`SE`, []string{"LECT", "T"}},
+		{`se`, []string{"lect", "t"}},
+		{`Sh`, []string{"ow"}},
+		{`sy`, []string{"stem"}},
+		{`ta`, []string{"ble"}},
+		{`TA`, []string{"BLE", "ble"}},
+		{`t`, []string{"", "able"}},
+		{`x`, []string{}},
+	}
+	for _, tc := range testCases {
+		result := []string{}
+		for _, r := range completions(keywords, names, tc.word) {
+			result = append(result, string(r))
+		}
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("%q: expected %q, but got %q", tc.word, tc.expected, result)
+		}
+	}
+}
+
+// TestSQLCompleterConnection verifies that names are completed on a
+// connection of the completer's own, so that completion works, and
+// doesn't interfere, while the user's transaction is aborted.
+func TestSQLCompleterConnection(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{Insecure: true})
+	defer s.Stopper().Stop()
+
+	pgurl, err := s.(*server.TestServer).Cfg.PGURL(url.User(security.RootUser))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := makeSQLConn(pgurl.String())
+	defer conn.Close()
+	sc := newSQLCompleter(conn.url)
+	defer sc.Close()
+	sc.database = "system"
+
+	if _, _, _, err := runQuery(conn, makeQuery(`BEGIN`), false); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := runQuery(conn, makeQuery(`SELECT * FROM system.nonexistent`), false); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// The name of the system.descriptor table and column are completed.
+	line := []rune(`SELECT * FROM descr`)
+	result, n := sc.Do(line, len(line))
+	if n != 5 || len(result) != 1 || string(result[0]) != "iptor" {
+		t.Fatalf("expected the completion of descriptor, but got %q (%d)", result, n)
+	}
+
+	// The user's transaction is still aborted, and can be rolled back.
+	if _, _, _, err := runQuery(conn, makeQuery(`SELECT 1`), false); err == nil {
+		t.Fatal("expected the transaction to still be aborted")
+	}
+	if _, _, _, err := runQuery(conn, makeQuery(`ROLLBACK`), false); err != nil {
+		t.Fatal(err)
+	}
+}
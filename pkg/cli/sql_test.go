@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 )
@@ -111,7 +112,7 @@ SET
 	}
 
 	// Some other tests (TestDumpRow) mess with this, so make sure it's set.
-	cliCtx.prettyFmt = true
+	cliCtx.tableDisplayFormat = tableDisplayPretty
 
 	for _, test := range tests {
 		conf.Stdin = strings.NewReader(test.in)
@@ -191,3 +192,44 @@ func TestIsEndOfStatement(t *testing.T) {
 		}
 	}
 }
+
+func TestDescribeQuery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tests := []struct {
+		cmd    string
+		args   []string
+		expect string
+		err    string
+	}{
+		{`\l`, nil, `SHOW DATABASES`, ``},
+		{`\l`, []string{"x"}, ``, `too many arguments`},
+		{`\du`, nil, `SHOW USERS`, ``},
+		{`\dt`, nil, `SHOW TABLES`, ``},
+		{`\dt`, []string{"system"}, `SHOW TABLES FROM system`, ``},
+		{`\dt`, []string{"my db"}, `SHOW TABLES FROM "my db"`, ``},
+		{`\d`, nil, `SHOW TABLES`, ``},
+		{`\d`, []string{"system.users"}, `SHOW COLUMNS FROM system.users`, ``},
+		{`\d`, []string{"t;"}, ``, `syntax error`},
+		{`\d`, []string{"a", "b"}, ``, `too many arguments`},
+		{`\di`, []string{"t"}, `SHOW INDEXES FROM t`, ``},
+	}
+	for _, test := range tests {
+		query, err := describeQuery(test.cmd, test.args)
+		if test.err == "" && err != nil {
+			t.Errorf("%s %s: unexpected error: %v", test.cmd, test.args, err)
+			continue
+		} else if test.err != "" && !testutils.IsError(err, test.err) {
+			t.Errorf("%s %s: expected error %q, got %v", test.cmd, test.args, test.err, err)
+			continue
+		}
+		if query != test.expect {
+			t.Errorf("%s %s: expected %q, got %q", test.cmd, test.args, test.expect, query)
+		}
+	}
+	if query, err := describeQuery(`\di`, nil); err != nil ||
+		!strings.Contains(query, "information_schema.statistics") {
+		t.Errorf(`\di: expected a query on information_schema.statistics, got %q (err: %v)`,
+			query, err)
+	}
+}
@@ -29,10 +29,7 @@ import (
 
 	"golang.org/x/net/context"
 
-	"github.com/olekukonko/tablewriter"
-
 	"github.com/cockroachdb/cockroach/pkg/security"
-	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/pq"
 )
@@ -251,21 +248,46 @@ func runQuery(conn *sqlConn, fn queryFunc, pretty bool) ([]string, [][]string, s
 }
 
 // runQueryAndFormatResults takes a 'query' with optional 'parameters'.
-// It runs the sql query and writes output to 'w'.
-func runQueryAndFormatResults(conn *sqlConn, w io.Writer, fn queryFunc, pretty bool) error {
+// It runs the sql query and writes output to 'w' in the given display
+// format.
+func runQueryAndFormatResults(
+	conn *sqlConn, w io.Writer, fn queryFunc, displayFormat tableDisplayFormat,
+) error {
 	for {
-		cols, allRows, result, err := runQuery(conn, fn, pretty)
+		rows, err := fn(conn)
 		if err != nil {
 			if err == pq.ErrNoMoreResults {
 				return nil
 			}
 			return err
 		}
-		printQueryOutput(w, cols, allRows, result, pretty)
+		if err := printQueryRows(w, rows, displayFormat); err != nil {
+			return err
+		}
 		fn = nextResult
 	}
 }
 
+// printQueryRows writes the rows of a single result to 'w' as they are
+// received, or the statement tag if the statement did not return rows.
+// 'rows' is closed before returning.
+func printQueryRows(w io.Writer, rows *sqlRows, displayFormat tableDisplayFormat) error {
+	defer func() { _ = rows.Close() }()
+	cols := rows.Columns()
+	if len(cols) == 0 {
+		for {
+			if err := rows.Next(nil); err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(w, statementTag(rows))
+		return nil
+	}
+	return render(w, makeRowReporter(displayFormat), cols, sqlRowsIter{rows: rows, cols: len(cols)})
+}
+
 // sqlRowsToStrings turns 'rows' into a list of rows, each of which
 // is a  list of column values.
 // 'rows' should be closed by the caller.
@@ -301,18 +323,23 @@ func sqlRowsToStrings(rows *sqlRows, pretty bool) ([]string, [][]string, string,
 		allRows = append(allRows, rowStrings)
 	}
 
-	result := rows.Result()
+	return cols, allRows, statementTag(rows), nil
+}
+
+// statementTag returns the tag describing the statement which produced
+// 'rows', including the number of affected rows when applicable. It must
+// be called after all the rows were read.
+func statementTag(rows *sqlRows) string {
 	tag := rows.Tag()
 	switch tag {
 	case "":
 		tag = "OK"
 	case "DELETE", "INSERT", "UPDATE":
-		if n, err := result.RowsAffected(); err == nil {
+		if n, err := rows.Result().RowsAffected(); err == nil {
 			tag = fmt.Sprintf("%s %d", tag, n)
 		}
 	}
-
-	return cols, allRows, tag, nil
+	return tag
 }
 
 // expandTabsAndNewLines ensures that multi-line row strings that may
@@ -331,45 +358,17 @@ func expandTabsAndNewLines(s string) string {
 }
 
 // printQueryOutput takes a list of column names and a list of row contents
-// writes a pretty table to 'w', or "OK" if empty.
-func printQueryOutput(w io.Writer, cols []string, allRows [][]string, tag string, pretty bool) {
+// and writes them to 'w' in the given display format, or the tag if there
+// are no columns.
+func printQueryOutput(
+	w io.Writer, cols []string, allRows [][]string, tag string, displayFormat tableDisplayFormat,
+) error {
 	if len(cols) == 0 {
 		// This operation did not return rows, just show the tag.
 		fmt.Fprintln(w, tag)
-		return
-	}
-
-	if pretty {
-		// Initialize tablewriter and set column names as the header row.
-		table := tablewriter.NewWriter(w)
-		table.SetAutoFormatHeaders(false)
-		table.SetAutoWrapText(false)
-		table.SetHeader(cols)
-		for _, row := range allRows {
-			for i, r := range row {
-				row[i] = expandTabsAndNewLines(r)
-			}
-			table.Append(row)
-		}
-		table.Render()
-		nRows := len(allRows)
-		fmt.Fprintf(w, "(%d row%s)\n", nRows, util.Pluralize(int64(nRows)))
-	} else {
-		if len(cols) == 0 {
-			// No result selected, inform the user.
-			fmt.Fprintln(w, tag)
-		} else {
-			// Some results selected, inform the user about how much data to expect.
-			fmt.Fprintf(w, "%d row%s\n", len(allRows),
-				util.Pluralize(int64(len(allRows))))
-
-			// Then print the results themselves.
-			fmt.Fprintln(w, strings.Join(cols, "\t"))
-			for _, row := range allRows {
-				fmt.Fprintln(w, strings.Join(row, "\t"))
-			}
-		}
+		return nil
 	}
+	return render(w, makeRowReporter(displayFormat), cols, &stringRowsIter{rows: allRows})
 }
 
 func isNotPrintableASCII(r rune) bool { return r < 0x20 || r > 0x7e || r == '"' || r == '\\' }
@@ -321,6 +321,15 @@ func runStart(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to start Cockroach server: %s", err)
 	}
 
+	// Reload the certificates on SIGHUP, without restarting the node.
+	if !serverCfg.Insecure {
+		certMgr, err := serverCfg.GetCertificateManager()
+		if err != nil {
+			return err
+		}
+		certMgr.RegisterSignalHandler(stopper.ShouldQuiesce())
+	}
+
 	if err := s.Start(startCtx); err != nil {
 		return fmt.Errorf("cockroach server exited with error: %s", err)
 	}
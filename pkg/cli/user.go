@@ -17,6 +17,7 @@
 package cli
 
 import (
+	"database/sql/driver"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -25,6 +26,7 @@ import (
 )
 
 var password bool
+var authMethods string
 
 // A getUserCmd command displays the config for the specified username.
 var getUserCmd = &cobra.Command{
@@ -47,7 +49,7 @@ func runGetUser(cmd *cobra.Command, args []string) error {
 	}
 	defer conn.Close()
 	return runQueryAndFormatResults(conn, os.Stdout,
-		makeQuery(`SELECT * FROM system.users WHERE username=$1`, args[0]), cliCtx.prettyFmt)
+		makeQuery(`SELECT username, hashedPassword FROM system.users WHERE username=$1 AND NOT "isRole"`, args[0]), cliCtx.tableDisplayFormat)
 }
 
 // A lsUsersCmd command displays a list of users.
@@ -71,7 +73,7 @@ func runLsUsers(cmd *cobra.Command, args []string) error {
 	}
 	defer conn.Close()
 	return runQueryAndFormatResults(conn, os.Stdout,
-		makeQuery(`SELECT username FROM system.users`), cliCtx.prettyFmt)
+		makeQuery(`SELECT username FROM system.users WHERE NOT "isRole"`), cliCtx.tableDisplayFormat)
 }
 
 // A rmUserCmd command removes the user for the specified username.
@@ -95,7 +97,7 @@ func runRmUser(cmd *cobra.Command, args []string) error {
 	}
 	defer conn.Close()
 	return runQueryAndFormatResults(conn, os.Stdout,
-		makeQuery(`DELETE FROM system.users WHERE username=$1`, args[0]), cliCtx.prettyFmt)
+		makeQuery(`DELETE FROM system.users WHERE username=$1 AND NOT "isRole"`, args[0]), cliCtx.tableDisplayFormat)
 }
 
 // A setUserCmd command creates a new or updates an existing user.
@@ -110,8 +112,8 @@ for the password.
 	RunE:         maybeDecorateGRPCError(runSetUser),
 }
 
-// runSetUser prompts for a password, then inserts the user and hash
-// into the system.users table.
+// runSetUser prompts for a password, then inserts the user and the hashes of
+// their password into the system.users table.
 // TODO(marc): once we have more fields in the user, we will need
 // to allow changing just some of them (eg: change email, but leave password).
 func runSetUser(cmd *cobra.Command, args []string) error {
@@ -119,17 +121,24 @@ func runSetUser(cmd *cobra.Command, args []string) error {
 		return usageAndError(cmd)
 	}
 	var err error
-	var hashed []byte
+	var pwd string
 	if password {
-		hashed, err = security.PromptForPasswordAndHash()
+		pwd, err = security.PromptForPassword()
 		if err != nil {
 			return err
 		}
-	} else {
-		hashed, err = security.HashPassword("")
-		if err != nil {
+	}
+	hashes, err := security.HashUserPassword(args[0], pwd)
+	if err != nil {
+		return err
+	}
+	// An empty list of methods is stored as NULL, which allows the defaults.
+	var methods driver.Value
+	if authMethods != "" {
+		if _, err := security.ParseAuthMethods(authMethods); err != nil {
 			return err
 		}
+		methods = authMethods
 	}
 
 	conn, err := getPasswordAndMakeSQLClient()
@@ -140,7 +149,20 @@ func runSetUser(cmd *cobra.Command, args []string) error {
 	// TODO(asubiotto): Implement appropriate server-side authorization rules
 	// for users to be able to change their own passwords.
 	return runQueryAndFormatResults(conn, os.Stdout,
-		makeQuery(`UPSERT INTO system.users VALUES ($1, $2)`, args[0], hashed), cliCtx.prettyFmt)
+		makeQuery(`UPSERT INTO system.users `+
+			`(username, hashedPassword, "md5Password", "scramVerifier", "authMethods") `+
+			`VALUES ($1, $2, $3, $4, $5)`,
+			args[0], hashes.Hashed, credentialValue(hashes.MD5), credentialValue(hashes.SCRAM), methods),
+		cliCtx.tableDisplayFormat)
+}
+
+// credentialValue returns the query parameter for one of the password hashes
+// stored in system.users, which is NULL if the user doesn't have it.
+func credentialValue(credential []byte) driver.Value {
+	if len(credential) == 0 {
+		return nil
+	}
+	return credential
 }
 
 var userCmds = []*cobra.Command{
@@ -137,6 +137,40 @@ func queryDescriptors(conn *sqlConn) (map[sqlbase.ID]*sqlbase.Descriptor, error)
 	return descs, nil
 }
 
+func queryIndexID(conn *sqlConn, tableID sqlbase.ID, name string) (uint32, error) {
+	rows, err := makeQuery(
+		`SELECT descriptor FROM system.descriptor WHERE id = $1`, tableID)(conn)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	vals := make([]driver.Value, 1)
+	if err := rows.Next(vals); err != nil {
+		return 0, err
+	}
+	desc := &sqlbase.Descriptor{}
+	if err := unmarshalProto(vals[0], desc); err != nil {
+		return 0, err
+	}
+	tableDesc := desc.GetTable()
+	if tableDesc == nil {
+		return 0, fmt.Errorf("%d is not a table", tableID)
+	}
+	normName := parser.Name(name).Normalize()
+	if parser.ReNormalizeName(tableDesc.PrimaryIndex.Name) == normName {
+		return uint32(tableDesc.PrimaryIndex.ID), nil
+	}
+	status, i, err := tableDesc.FindIndexByNormalizedName(normName)
+	if err != nil {
+		return 0, err
+	}
+	if status != sqlbase.DescriptorActive {
+		return 0, fmt.Errorf("index %q is not public", name)
+	}
+	return uint32(tableDesc.Indexes[i].ID), nil
+}
+
 func queryNamespace(conn *sqlConn, parentID sqlbase.ID, name string) (sqlbase.ID, error) {
 	rows, err := makeQuery(
 		`SELECT id FROM system.namespace WHERE parentID = $1 AND name = $2`,
@@ -176,37 +210,49 @@ func queryDescriptorIDPath(conn *sqlConn, names []string) ([]sqlbase.ID, error)
 	return path, nil
 }
 
-func parseZoneName(s string) ([]string, error) {
+// parseZoneName parses the name of a database, a table or an index of a
+// table, returning the database and table names and the index name, if any.
+func parseZoneName(s string) ([]string, string, error) {
 	if strings.ToLower(s) == ".default" {
-		return nil, nil
+		return nil, "", nil
+	}
+	var index string
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		s, index = s[:i], s[i+1:]
+		if index == "" {
+			return nil, "", fmt.Errorf("malformed name: %s@", s)
+		}
 	}
 	// TODO(knz): we are passing a name that might not be escaped correctly.
 	// See #8389.
 	tn, err := parser.ParseTableNameTraditional(s)
 	if err != nil {
-		return nil, fmt.Errorf("malformed name: %s", s)
+		return nil, "", fmt.Errorf("malformed name: %s", s)
 	}
 	// This is a bit of a hack: "." is not a valid database name.
 	// We use this to detect when a database name was not specified, in
 	// which case we interpret the table name as a database name below.
 	if err := tn.QualifyWithDatabase("."); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	var names []string
 	if n := tn.Database(); n != "." {
 		names = append(names, n)
 	}
 	names = append(names, tn.Table())
-	return names, nil
+	if index != "" && len(names) != 2 {
+		return nil, "", fmt.Errorf("index zone configs require a table name: %s@%s", s, index)
+	}
+	return names, index, nil
 }
 
 // A getZoneCmd command displays a zone config.
 var getZoneCmd = &cobra.Command{
-	Use:   "get [options] <database[.table]>",
+	Use:   "get [options] <database[.table[@index]]>",
 	Short: "fetches and displays the zone config",
 	Long: `
-Fetches and displays the zone configuration for the specified database or
-table.
+Fetches and displays the zone configuration for the specified database, table
+or index.
 `,
 	SilenceUsage: true,
 	RunE:         maybeDecorateGRPCError(runGetZone),
@@ -219,7 +265,7 @@ func runGetZone(cmd *cobra.Command, args []string) error {
 		return usageAndError(cmd)
 	}
 
-	names, err := parseZoneName(args[0])
+	names, index, err := parseZoneName(args[0])
 	if err != nil {
 		return err
 	}
@@ -239,6 +285,26 @@ func runGetZone(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if index != "" {
+		indexID, err := queryIndexID(conn, path[len(path)-1], index)
+		if err != nil {
+			return err
+		}
+		zone, found, err := queryZone(conn, path[len(path)-1])
+		if err != nil {
+			return err
+		}
+		if indexZone, ok := zone.GetIndexZone(indexID); found && ok {
+			fmt.Printf("%s@%s\n", strings.Join(names, "."), index)
+			res, err := yaml.Marshal(indexZone)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(res))
+			return nil
+		}
+	}
+
 	id, zone, err := queryZonePath(conn, path)
 	if err != nil {
 		return err
@@ -324,6 +390,15 @@ func runLsZones(cmd *cobra.Command, args []string) error {
 		}
 		name += parser.Name(desc.GetName()).String()
 		output = append(output, name)
+		if tableDesc := desc.GetTable(); tableDesc != nil {
+			for _, indexZone := range zones[id].IndexZones {
+				idx, err := tableDesc.FindIndexByID(sqlbase.IndexID(indexZone.IndexID))
+				if err != nil {
+					continue
+				}
+				output = append(output, name+"@"+parser.Name(idx.Name).String())
+			}
+		}
 	}
 
 	sort.Strings(output)
@@ -339,10 +414,12 @@ func runLsZones(cmd *cobra.Command, args []string) error {
 
 // A rmZoneCmd command removes a zone config.
 var rmZoneCmd = &cobra.Command{
-	Use:   "rm [options] <database[.table]>",
+	Use:   "rm [options] <database[.table[@index]]>",
 	Short: "remove a zone config",
 	Long: `
-Remove an existing zone config for the specified database or table.
+Remove an existing zone config for the specified database, table or index.
+Removing the zone config of a table also removes the zone configs of its
+indexes.
 `,
 	SilenceUsage: true,
 	RunE:         maybeDecorateGRPCError(runRmZone),
@@ -353,7 +430,7 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 		return usageAndError(cmd)
 	}
 
-	names, err := parseZoneName(args[0])
+	names, index, err := parseZoneName(args[0])
 	if err != nil {
 		return err
 	}
@@ -381,8 +458,33 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unable to remove %s", args[0])
 	}
 
+	if index != "" {
+		indexID, err := queryIndexID(conn, id, index)
+		if err != nil {
+			return err
+		}
+		zone, found, err := queryZone(conn, id)
+		if err != nil {
+			return err
+		}
+		if !found || !zone.DeleteIndexZone(indexID) {
+			fmt.Printf("%s not found\n", args[0])
+			return nil
+		}
+		buf, err := protoutil.Marshal(&zone)
+		if err != nil {
+			return err
+		}
+		if err := runQueryAndFormatResults(conn, os.Stdout,
+			makeQuery(`UPSERT INTO system.zones (id, config) VALUES ($1, $2)`, id, buf),
+			cliCtx.tableDisplayFormat); err != nil {
+			return err
+		}
+		return conn.Exec(`COMMIT`, nil)
+	}
+
 	if err := runQueryAndFormatResults(conn, os.Stdout,
-		makeQuery(`DELETE FROM system.zones WHERE id=$1`, id), cliCtx.prettyFmt); err != nil {
+		makeQuery(`DELETE FROM system.zones WHERE id=$1`, id), cliCtx.tableDisplayFormat); err != nil {
 		return err
 	}
 	return conn.Exec(`COMMIT`, nil)
@@ -390,11 +492,11 @@ func runRmZone(cmd *cobra.Command, args []string) error {
 
 // A setZoneCmd command creates a new or updates an existing zone config.
 var setZoneCmd = &cobra.Command{
-	Use:   "set [options] <database[.table]> <zone-config>",
+	Use:   "set [options] <database[.table[@index]]> <zone-config>",
 	Short: "create or update zone config for object ID",
 	Long: `
-Create or update the zone config for the specified database or table to the
-specified zone-config.
+Create or update the zone config for the specified database, table or index to
+the specified zone-config.
 
 The zone config format has the following YAML schema:
 
@@ -411,8 +513,14 @@ num_replicas: 3
 constraints: [ssd, -mem]
 EOF
 
+To set the zone config for the secondary index idx of the table db.t, run:
+$ cockroach zone set db.t@idx -f - << EOF
+constraints: [ssd]
+EOF
+
 Note that the specified zone config is merged with the existing zone config for
-the database or table.
+the database, table or index. The zone config of an index is stored as part of
+the zone config of its table, which is created if the table doesn't have one.
 `,
 	SilenceUsage: true,
 	RunE:         maybeDecorateGRPCError(runSetZone),
@@ -450,7 +558,7 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 	}
 	defer conn.Close()
 
-	names, err := parseZoneName(args[0])
+	names, index, err := parseZoneName(args[0])
 	if err != nil {
 		return err
 	}
@@ -468,10 +576,29 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	_, zone, err := queryZonePath(conn, path)
+	id := path[len(path)-1]
+	zoneID, zone, err := queryZonePath(conn, path)
 	if err != nil {
 		return err
 	}
+	if zoneID != id {
+		// The index zone configs of a table aren't inherited.
+		zone.IndexZones = nil
+	}
+	var indexID uint32
+	tableZone := zone
+	if index != "" {
+		if indexID, err = queryIndexID(conn, id, index); err != nil {
+			return err
+		}
+		// An index without its own zone config uses the zone config of its
+		// table.
+		if indexZone, ok := tableZone.GetIndexZone(indexID); ok {
+			zone = indexZone
+		} else {
+			zone.IndexZones = nil
+		}
+	}
 	// Convert it to proto and marshal it again to put into the table. This is a
 	// bit more tedious than taking protos directly, but yaml is a more widely
 	// understood format.
@@ -485,16 +612,20 @@ func runSetZone(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("unable to parse zoneConfig file: %s", err)
 	}
 
-	if err := zone.Validate(); err != nil {
+	if index != "" {
+		tableZone.SetIndexZone(indexID, zone)
+	} else {
+		tableZone = zone
+	}
+	if err := tableZone.Validate(); err != nil {
 		return err
 	}
 
-	buf, err := protoutil.Marshal(&zone)
+	buf, err := protoutil.Marshal(&tableZone)
 	if err != nil {
 		return fmt.Errorf("unable to parse zone config file %q: %s", args[1], err)
 	}
 
-	id := path[len(path)-1]
 	_, _, _, err = runQuery(conn, makeQuery(
 		`UPSERT INTO system.zones (id, config) VALUES ($1, $2)`,
 		id, buf), false)
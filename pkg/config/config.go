@@ -171,9 +171,57 @@ func (z ZoneConfig) Validate() error {
 		return fmt.Errorf("RangeMinBytes %d is greater than or equal to RangeMaxBytes %d",
 			z.RangeMinBytes, z.RangeMaxBytes)
 	}
+	for _, indexZone := range z.IndexZones {
+		if len(indexZone.Config.IndexZones) > 0 {
+			return fmt.Errorf("zone config for index %d cannot contain index zone configs",
+				indexZone.IndexID)
+		}
+		if err := indexZone.Config.Validate(); err != nil {
+			return fmt.Errorf("zone config for index %d: %s", indexZone.IndexID, err)
+		}
+	}
 	return nil
 }
 
+// GetIndexZone returns the zone config of the index with the given ID, if
+// the index has its own zone config.
+func (z ZoneConfig) GetIndexZone(indexID uint32) (ZoneConfig, bool) {
+	for _, indexZone := range z.IndexZones {
+		if indexZone.IndexID == indexID {
+			return indexZone.Config, true
+		}
+	}
+	return ZoneConfig{}, false
+}
+
+// SetIndexZone sets the zone config of the index with the given ID,
+// replacing its existing zone config if any. The index zones are kept sorted
+// by index ID.
+func (z *ZoneConfig) SetIndexZone(indexID uint32, zone ZoneConfig) {
+	i := sort.Search(len(z.IndexZones), func(i int) bool {
+		return z.IndexZones[i].IndexID >= indexID
+	})
+	if i < len(z.IndexZones) && z.IndexZones[i].IndexID == indexID {
+		z.IndexZones[i].Config = zone
+		return
+	}
+	z.IndexZones = append(z.IndexZones, IndexZoneConfig{})
+	copy(z.IndexZones[i+1:], z.IndexZones[i:])
+	z.IndexZones[i] = IndexZoneConfig{IndexID: indexID, Config: zone}
+}
+
+// DeleteIndexZone removes the zone config of the index with the given ID,
+// returning whether the index had its own zone config.
+func (z *ZoneConfig) DeleteIndexZone(indexID uint32) bool {
+	for i, indexZone := range z.IndexZones {
+		if indexZone.IndexID == indexID {
+			z.IndexZones = append(z.IndexZones[:i], z.IndexZones[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 // ObjectIDForKey returns the object ID (table or database) for 'key',
 // or (_, false) if not within the structured key space.
 func ObjectIDForKey(key roachpb.RKey) (uint32, bool) {
@@ -189,6 +237,20 @@ func ObjectIDForKey(key roachpb.RKey) (uint32, bool) {
 	return uint32(id64), err == nil
 }
 
+// indexIDForKey returns the index ID for 'key', or (_, false) if 'key' is not
+// within the span of an index of a table.
+func indexIDForKey(key roachpb.RKey) (uint32, bool) {
+	if _, ok := ObjectIDForKey(key); !ok {
+		return 0, false
+	}
+	remaining, _, err := encoding.DecodeUvarintAscending(key)
+	if err != nil || encoding.PeekType(remaining) != encoding.Int {
+		return 0, false
+	}
+	_, indexID, err := encoding.DecodeUvarintAscending(remaining)
+	return uint32(indexID), err == nil
+}
+
 // Hash returns a SHA1 hash of the SystemConfig contents.
 func (s SystemConfig) Hash() []byte {
 	sha := sha1.New()
@@ -344,7 +406,19 @@ func (s SystemConfig) GetZoneConfigForKey(key roachpb.RKey) (ZoneConfig, error)
 		// For now, only user databases and tables get custom zone configs.
 		objectID = keys.RootNamespaceID
 	}
-	return s.getZoneConfigForID(objectID)
+	zone, err := s.getZoneConfigForID(objectID)
+	if err != nil || len(zone.IndexZones) == 0 {
+		return zone, err
+	}
+	// The table has indexes with their own zone configs. Since the spans of
+	// these indexes are split off from the rest of the table, the whole range
+	// belongs to the index containing 'key'.
+	if indexID, ok := indexIDForKey(key); ok {
+		if indexZone, ok := zone.GetIndexZone(indexID); ok {
+			return indexZone, nil
+		}
+	}
+	return zone, nil
 }
 
 // getZoneConfigForID looks up the zone config for the object (table or database)
@@ -359,9 +433,57 @@ func (s SystemConfig) getZoneConfigForID(id uint32) (ZoneConfig, error) {
 	return DefaultZoneConfig(), nil
 }
 
+// indexSplitKeys returns, for each table with indexes that have their own
+// zone configs, the sorted keys at which the spans of these indexes need to be
+// split off from the rest of the table.
+func (s SystemConfig) indexSplitKeys() map[uint32][]roachpb.RKey {
+	zonesPrefix := roachpb.Key(keys.MakeTablePrefix(keys.ZonesTableID))
+	i := sort.Search(len(s.Values), func(i int) bool {
+		return bytes.Compare(s.Values[i].Key, zonesPrefix) >= 0
+	})
+	var splitKeys map[uint32][]roachpb.RKey
+	for ; i < len(s.Values) && bytes.HasPrefix(s.Values[i].Key, zonesPrefix); i++ {
+		kv := &s.Values[i]
+		// ZonesTable.PrimaryIndex.ID
+		remaining, _, err := encoding.DecodeUvarintAscending(kv.Key[len(zonesPrefix):])
+		if err != nil {
+			continue
+		}
+		// Zone ID.
+		_, id, err := encoding.DecodeUvarintAscending(remaining)
+		if err != nil {
+			continue
+		}
+		zone, err := MigrateZoneConfig(&kv.Value)
+		if err != nil {
+			log.Errorf(context.TODO(), "unable to unmarshal zone config %d: %s", id, err)
+			continue
+		}
+		if len(zone.IndexZones) == 0 {
+			continue
+		}
+		if splitKeys == nil {
+			splitKeys = make(map[uint32][]roachpb.RKey)
+		}
+		tableKeys := splitKeys[uint32(id)]
+		for _, indexZone := range zone.IndexZones {
+			indexStart := roachpb.RKey(encoding.EncodeUvarintAscending(
+				keys.MakeTablePrefix(uint32(id)), uint64(indexZone.IndexID)))
+			// The end of an index may be the start of the next one.
+			if n := len(tableKeys); n == 0 || !indexStart.Equal(tableKeys[n-1]) {
+				tableKeys = append(tableKeys, indexStart)
+			}
+			tableKeys = append(tableKeys, indexStart.PrefixEnd())
+		}
+		splitKeys[uint32(id)] = tableKeys
+	}
+	return splitKeys
+}
+
 // ComputeSplitKeys takes a start and end key and returns an array of keys
 // at which to split the span [start, end).
-// The only required splits are at each user table prefix.
+// The only required splits are at each user table prefix, as well as at the
+// start and end of each index with its own zone config.
 func (s SystemConfig) ComputeSplitKeys(startKey, endKey roachpb.RKey) []roachpb.RKey {
 	tableStart := roachpb.RKey(keys.SystemConfigTableDataMax)
 	if !tableStart.Less(endKey) {
@@ -369,7 +491,28 @@ func (s SystemConfig) ComputeSplitKeys(startKey, endKey roachpb.RKey) []roachpb.
 		return nil
 	}
 
+	indexSplitKeys := s.indexSplitKeys()
+	// appendIndexSplitKeys adds the split keys of the indexes of the given table
+	// which fall within the span to splitKeys.
+	var splitKeys []roachpb.RKey
+	appendIndexSplitKeys := func(id uint32) {
+		for _, key := range indexSplitKeys[id] {
+			if !startKey.Less(key) {
+				continue
+			}
+			if !key.Less(endKey) {
+				break
+			}
+			splitKeys = append(splitKeys, key)
+		}
+	}
+
 	startID, ok := ObjectIDForKey(startKey)
+	if ok && startID > keys.MaxSystemConfigDescID {
+		// The start key may fall within a table whose remaining indexes need
+		// to be split off.
+		appendIndexSplitKeys(startID)
+	}
 	if !ok || startID <= keys.MaxSystemConfigDescID {
 		// The start key is either:
 		// - not part of the structured data span
@@ -387,7 +530,6 @@ func (s SystemConfig) ComputeSplitKeys(startKey, endKey roachpb.RKey) []roachpb.
 	// that there are two disjoint sets of sequential keys: non-system reserved
 	// tables have sequential IDs, as do user tables, but the two ranges contain a
 	// gap.
-	var splitKeys []roachpb.RKey
 	var key roachpb.RKey
 
 	// appendSplitKeys generates all possible split keys between the given range
@@ -405,6 +547,7 @@ func (s SystemConfig) ComputeSplitKeys(startKey, endKey roachpb.RKey) []roachpb.
 				break
 			}
 			splitKeys = append(splitKeys, key)
+			appendIndexSplitKeys(id)
 		}
 	}
 
@@ -173,7 +173,7 @@ func TestGetLargestID(t *testing.T) {
 		}, 12, 0, ""},
 
 		// Real SQL layout.
-		{sqlbase.MakeMetadataSchema().GetInitialValues(), keys.MaxSystemConfigDescID + 4, 0, ""},
+		{sqlbase.MakeMetadataSchema().GetInitialValues(), keys.MaxSystemConfigDescID + 7, 0, ""},
 
 		// Test non-zero max.
 		{[]roachpb.KeyValue{
@@ -340,6 +340,30 @@ func TestZoneConfigValidate(t *testing.T) {
 			},
 			"is greater than or equal to RangeMaxBytes",
 		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   1,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				IndexZones: []config.IndexZoneConfig{
+					{IndexID: 2, Config: config.ZoneConfig{NumReplicas: 2}},
+				},
+			},
+			"zone config for index 2: at least 3 replicas are required",
+		},
+		{
+			config.ZoneConfig{
+				NumReplicas:   1,
+				RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+				IndexZones: []config.IndexZoneConfig{
+					{IndexID: 2, Config: config.ZoneConfig{
+						NumReplicas:   1,
+						RangeMaxBytes: config.DefaultZoneConfig().RangeMaxBytes,
+						IndexZones:    []config.IndexZoneConfig{{IndexID: 3}},
+					}},
+				},
+			},
+			"zone config for index 2 cannot contain index zone configs",
+		},
 	}
 	for i, c := range testCases {
 		err := c.cfg.Validate()
@@ -218,6 +218,12 @@ var (
 	// TimeseriesPrefix is the key prefix for all timeseries data.
 	TimeseriesPrefix = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("tsd")))
 
+	// MigrationPrefix specifies the key prefix under which the completed
+	// migrations of the system tables are recorded.
+	MigrationPrefix = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("system-version/")))
+	// MigrationKeyMax is the maximum value for any migration key.
+	MigrationKeyMax = MigrationPrefix.PrefixEnd()
+
 	// UpdateCheckPrefix is the key prefix for all update check times.
 	UpdateCheckPrefix  = roachpb.Key(makeKey(SystemPrefix, roachpb.RKey("update-")))
 	UpdateCheckCluster = roachpb.Key(makeKey(UpdateCheckPrefix, roachpb.RKey("cluster")))
@@ -261,17 +267,22 @@ const (
 	// SystemDatabaseID and following are the database/table IDs for objects
 	// in the system span.
 	// NOTE: IDs must be <= MaxSystemConfigDescID.
-	SystemDatabaseID  = 1
-	NamespaceTableID  = 2
-	DescriptorTableID = 3
-	UsersTableID      = 4
-	ZonesTableID      = 5
+	SystemDatabaseID   = 1
+	NamespaceTableID   = 2
+	DescriptorTableID  = 3
+	UsersTableID       = 4
+	ZonesTableID       = 5
+	SettingsTableID    = 6
+	RoleMembersTableID = 7
 
 	// Reserved IDs for other system tables. If you're adding a new system table,
 	// it probably belongs here.
 	// NOTE: IDs must be <= MaxReservedDescID.
-	LeaseTableID      = 11
-	EventLogTableID   = 12
-	RangeEventTableID = 13
-	UITableID         = 14
+	LeaseTableID              = 11
+	EventLogTableID           = 12
+	RangeEventTableID         = 13
+	UITableID                 = 14
+	ProtectedTimestampTableID = 15
+	WebSessionsTableID        = 16
+	ChangeFeedsTableID        = 17
 )
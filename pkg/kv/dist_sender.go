@@ -100,6 +100,9 @@ type DistSender struct {
 	sendNextTimeout  time.Duration
 	asyncSenderSem   chan struct{}
 	asyncSenderCount int32
+	// closedTimestampInterval, if set, determines which reads may be
+	// served by followers; see DistSenderConfig.ClosedTimestampInterval.
+	closedTimestampInterval func() time.Duration
 }
 
 var _ client.Sender = &DistSender{}
@@ -134,6 +137,11 @@ type DistSenderConfig struct {
 	// splitting batches into multiple requests when they span ranges.
 	// TODO(spencer): This is per-process. We should add a per-batch limit.
 	SenderConcurrency int32
+	// ClosedTimestampInterval, if set, returns the trailing interval at
+	// which lease holders close timestamps. Consistent reads older than
+	// this interval are sent to the nearest replica instead of the lease
+	// holder. If nil or if it returns zero, follower reads are disabled.
+	ClosedTimestampInterval func() time.Duration
 }
 
 // NewDistSender returns a batch.Sender instance which connects to the
@@ -189,6 +197,7 @@ func NewDistSender(cfg DistSenderConfig, g *gossip.Gossip) *DistSender {
 	} else {
 		ds.sendNextTimeout = defaultSendNextTimeout
 	}
+	ds.closedTimestampInterval = cfg.ClosedTimestampInterval
 	if cfg.SenderConcurrency != 0 {
 		ds.asyncSenderSem = make(chan struct{}, cfg.SenderConcurrency)
 	} else {
@@ -420,8 +429,9 @@ func (ds *DistSender) sendSingleRange(
 	ds.optimizeReplicaOrder(replicas)
 
 	// If this request needs to go to a lease holder and we know who that is, move
-	// it to the front.
-	if !(ba.IsReadOnly() && ba.ReadConsistency == roachpb.INCONSISTENT) {
+	// it to the front. Reads which don't need to go to the lease holder are
+	// sent to the nearest replica.
+	if !(ba.IsReadOnly() && (ba.ReadConsistency == roachpb.INCONSISTENT || ds.canSendToFollower(ba))) {
 		if leaseHolder, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
 			if i := replicas.FindReplica(leaseHolder.StoreID); i >= 0 {
 				replicas.MoveToFront(i)
@@ -448,6 +458,29 @@ func (ds *DistSender) sendSingleRange(
 	return br, pErr
 }
 
+// canSendToFollower returns whether the supplied read-only batch reads
+// at a timestamp old enough for it to have been closed by the range's
+// lease holder, in which case any replica can serve it. A replica which
+// hasn't caught up on the closed timestamp yet redirects the request to
+// the lease holder with a NotLeaseHolderError.
+func (ds *DistSender) canSendToFollower(ba roachpb.BatchRequest) bool {
+	if ds.closedTimestampInterval == nil {
+		return false
+	}
+	interval := ds.closedTimestampInterval()
+	if interval <= 0 || ba.ReadConsistency != roachpb.CONSISTENT || ba.IsNonKV() {
+		return false
+	}
+	ts := ba.Timestamp
+	if ba.Txn != nil {
+		ts.Forward(ba.Txn.MaxTimestamp)
+	}
+	if ts == hlc.ZeroTimestamp {
+		return false
+	}
+	return ts.Less(ds.clock.Now().Add(-interval.Nanoseconds(), 0))
+}
+
 // initAndVerifyBatch initializes timestamp-related information and
 // verifies batch constraints before splitting.
 func (ds *DistSender) initAndVerifyBatch(
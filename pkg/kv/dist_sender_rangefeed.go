@@ -0,0 +1,258 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+)
+
+// rangeFeedGroup tracks the partial RangeFeeds established on behalf of
+// a single DistSender.RangeFeed call. The first error encountered by any
+// of them is recorded and cancels the others.
+type rangeFeedGroup struct {
+	wg      sync.WaitGroup
+	cancel  func()
+	errCh   chan error
+	eventCh chan<- *roachpb.RangeFeedEvent
+}
+
+func (g *rangeFeedGroup) fail(err error) {
+	select {
+	case g.errCh <- err:
+	default:
+	}
+	g.cancel()
+}
+
+// RangeFeed divides a RangeFeed request on range boundaries and
+// establishes a RangeFeed to each of the individual ranges, streaming
+// back the events on the provided channel. Checkpoints are sent for the
+// part of the span served by a single range. Feeds on ranges which split
+// or whose lease moves are transparently re-established starting at the
+// last resolved timestamp, so a value may be sent more than once.
+//
+// RangeFeed blocks until the context is canceled or an unrecoverable
+// error occurs, which is returned.
+func (ds *DistSender) RangeFeed(
+	ctx context.Context, args *roachpb.RangeFeedRequest, eventCh chan<- *roachpb.RangeFeedEvent,
+) error {
+	ctx = ds.AnnotateCtx(ctx)
+	startKey, err := keys.Addr(args.Span.Key)
+	if err != nil {
+		return err
+	}
+	endKey, err := keys.Addr(args.Span.EndKey)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	g := &rangeFeedGroup{
+		cancel:  cancel,
+		errCh:   make(chan error, 1),
+		eventCh: eventCh,
+	}
+	ds.divideAndSendRangeFeedToRanges(ctx, g, roachpb.RSpan{Key: startKey, EndKey: endKey}, args.Timestamp)
+	g.wg.Wait()
+
+	select {
+	case err := <-g.errCh:
+		return err
+	default:
+		return ctx.Err()
+	}
+}
+
+// divideAndSendRangeFeedToRanges starts a partial RangeFeed for each of
+// the ranges overlapping the span.
+func (ds *DistSender) divideAndSendRangeFeedToRanges(
+	ctx context.Context, g *rangeFeedGroup, rs roachpb.RSpan, ts hlc.Timestamp,
+) {
+	ri := NewRangeIterator(ds, false /* reverse */)
+	for ri.Seek(ctx, rs.Key); ri.Valid(); ri.Next(ctx) {
+		desc := ri.Desc()
+		partialRS, err := rs.Intersect(desc)
+		if err != nil {
+			g.fail(err)
+			return
+		}
+		token := ri.Token()
+		g.wg.Add(1)
+		go func() {
+			defer g.wg.Done()
+			if err := ds.partialRangeFeed(ctx, g, partialRS, ts, desc, token); err != nil {
+				g.fail(err)
+			}
+		}()
+		if !ri.NeedAnother(rs) {
+			break
+		}
+	}
+	if pErr := ri.Error(); pErr != nil {
+		g.fail(pErr.GoError())
+	}
+}
+
+// partialRangeFeed establishes a RangeFeed to the range specified by
+// desc, which must contain the span. It re-establishes the feed on
+// errors which indicate the range's replicas or boundaries have
+// changed, and returns any other error.
+func (ds *DistSender) partialRangeFeed(
+	ctx context.Context,
+	g *rangeFeedGroup,
+	rs roachpb.RSpan,
+	ts hlc.Timestamp,
+	desc *roachpb.RangeDescriptor,
+	token *EvictionToken,
+) error {
+	span := roachpb.Span{Key: rs.Key.AsRawKey(), EndKey: rs.EndKey.AsRawKey()}
+	for r := retry.StartWithCtx(ctx, ds.rpcRetryOptions); r.Next(); {
+		if desc == nil {
+			var err error
+			desc, token, err = ds.getDescriptor(ctx, rs.Key, token, false /* useReverseScan */)
+			if err != nil {
+				log.VEventf(ctx, 1, "range descriptor re-lookup failed: %s", err)
+				continue
+			}
+		}
+
+		args := roachpb.RangeFeedRequest{Span: span}
+		args.Timestamp = ts
+		args.RangeID = desc.RangeID
+		pErr := ds.singleRangeFeed(ctx, &args, desc, g.eventCh)
+		ts.Forward(args.Timestamp)
+
+		switch pErr.GetDetail().(type) {
+		case *roachpb.SendError, *roachpb.RangeNotFoundError:
+			// None of the replicas could serve the feed; the cached
+			// descriptor is likely stale.
+			if err := token.Evict(ctx); err != nil {
+				return err
+			}
+			desc = nil
+		case *roachpb.RangeKeyMismatchError:
+			// The range split or merged. Divide the remainder of the span
+			// on the new range boundaries.
+			if err := token.Evict(ctx); err != nil {
+				return err
+			}
+			ds.divideAndSendRangeFeedToRanges(ctx, g, rs, ts)
+			return nil
+		default:
+			return pErr.GoError()
+		}
+	}
+	return ctx.Err()
+}
+
+// singleRangeFeed establishes a RangeFeed to the replicas of the range
+// in turn, starting with the cached lease holder, and forwards its
+// events to eventCh. It returns once the feed fails on all replicas or
+// fails with an error which isn't specific to the replica serving it.
+// args.Timestamp is forwarded to the most recent checkpoint.
+func (ds *DistSender) singleRangeFeed(
+	ctx context.Context,
+	args *roachpb.RangeFeedRequest,
+	desc *roachpb.RangeDescriptor,
+	eventCh chan<- *roachpb.RangeFeedEvent,
+) *roachpb.Error {
+	replicas := newReplicaSlice(ds.gossip, desc)
+	ds.optimizeReplicaOrder(replicas)
+	if leaseHolder, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
+		if i := replicas.FindReplica(leaseHolder.StoreID); i >= 0 {
+			replicas.MoveToFront(i)
+		}
+	}
+
+	for len(replicas) > 0 {
+		replica := replicas[0]
+		replicas = replicas[1:]
+		args.Replica = replica.ReplicaDescriptor
+
+		conn, err := ds.rpcContext.GRPCDial(replica.NodeDesc.Address.String())
+		if err != nil {
+			log.VEventf(ctx, 1, "unable to dial n%d: %s", replica.NodeID, err)
+			continue
+		}
+		stream, err := roachpb.NewInternalClient(conn).RangeFeed(ctx, args)
+		if err != nil {
+			log.VEventf(ctx, 1, "unable to establish rangefeed to n%d: %s", replica.NodeID, err)
+			continue
+		}
+		pErr := ds.forwardRangeFeedEvents(ctx, args, stream, eventCh)
+		if pErr == nil {
+			continue
+		}
+		switch tErr := pErr.GetDetail().(type) {
+		case *roachpb.RangeNotFoundError, *roachpb.StoreNotFoundError, *roachpb.NodeUnavailableError:
+		case *roachpb.NotLeaseHolderError:
+			if tErr.LeaseHolder != nil {
+				ds.updateLeaseHolderCache(ctx, desc.RangeID, *tErr.LeaseHolder)
+				if i := replicas.FindReplica(tErr.LeaseHolder.StoreID); i >= 0 {
+					replicas.MoveToFront(i)
+				}
+			}
+		default:
+			return pErr
+		}
+	}
+	return roachpb.NewError(roachpb.NewSendError("rangefeed failed on all replicas"))
+}
+
+// forwardRangeFeedEvents forwards the values and checkpoints received
+// on stream to eventCh until the stream ends. It returns the error
+// which ended the stream on the server, or nil if the stream was
+// broken without one.
+func (ds *DistSender) forwardRangeFeedEvents(
+	ctx context.Context,
+	args *roachpb.RangeFeedRequest,
+	stream roachpb.Internal_RangeFeedClient,
+	eventCh chan<- *roachpb.RangeFeedEvent,
+) *roachpb.Error {
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return roachpb.NewError(ctx.Err())
+			}
+			log.VEventf(ctx, 1, "rangefeed stream failed: %s", err)
+			return nil
+		}
+		switch {
+		case event.Error != nil:
+			pErr := event.Error.Error
+			return &pErr
+		case event.Checkpoint != nil:
+			args.Timestamp.Forward(event.Checkpoint.ResolvedTS)
+		case event.Val == nil:
+			return roachpb.NewError(errors.Errorf("unexpected rangefeed event: %v", event))
+		}
+		select {
+		case eventCh <- event:
+		case <-ctx.Done():
+			return roachpb.NewError(ctx.Err())
+		}
+	}
+}
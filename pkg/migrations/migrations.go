@@ -0,0 +1,283 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package migrations brings the system tables of clusters bootstrapped by
+// older versions up to date with the schema new clusters are bootstrapped
+// with (see sqlbase.MakeMetadataSchema).
+//
+// Each migration is run once per cluster, when a node first starts with a
+// version which knows about it, and is then recorded under
+// keys.MigrationPrefix so that it isn't run again. Since several nodes may
+// start at the same time, and a node may die after running a migration but
+// before recording it, migrations must be idempotent.
+package migrations
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// backwardCompatibleMigrations is the list of migrations, in the order in
+// which they are run. Migrations may be appended to the list, but never
+// removed or renamed, as they are recorded by name.
+var backwardCompatibleMigrations = []migrationDescriptor{
+	{
+		name:   "create system.protected_ts table",
+		workFn: createProtectedTimestampTable,
+	},
+	{
+		name:   "create system.settings table",
+		workFn: createSettingsTable,
+	},
+	{
+		name:   "create system.web_sessions table",
+		workFn: createWebSessionsTable,
+	},
+	{
+		name:   "create system.role_members table",
+		workFn: createRoleMembersTable,
+	},
+	{
+		name:   "add system.users isRole column",
+		workFn: addUsersIsRoleColumn,
+	},
+	{
+		name:   "add system.users password hash and authentication method columns",
+		workFn: addUsersAuthColumns,
+	},
+	{
+		name:   "create system.changefeeds table",
+		workFn: createChangeFeedsTable,
+	},
+}
+
+// migrationDescriptor describes a single migration.
+type migrationDescriptor struct {
+	// name identifies the migration, and must never change.
+	name string
+	// workFn performs the migration. It must be idempotent.
+	workFn func(context.Context, *client.DB) error
+}
+
+// Manager runs the migrations which haven't been run yet on the cluster.
+type Manager struct {
+	db *client.DB
+}
+
+// NewManager creates a Manager.
+func NewManager(db *client.DB) *Manager {
+	return &Manager{db: db}
+}
+
+// EnsureMigrations runs, in order, all the migrations which haven't been
+// recorded as completed yet, and records them. It returns after the first
+// failure, which leaves the remaining migrations to a later attempt.
+func (m *Manager) EnsureMigrations(ctx context.Context) error {
+	kvs, err := m.db.Scan(ctx, keys.MigrationPrefix, keys.MigrationKeyMax, 0)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the completed migrations")
+	}
+	completed := make(map[string]struct{}, len(kvs))
+	for _, kv := range kvs {
+		completed[string(kv.Key)] = struct{}{}
+	}
+
+	for _, migration := range backwardCompatibleMigrations {
+		key := migrationKey(migration)
+		if _, ok := completed[string(key)]; ok {
+			continue
+		}
+		log.Infof(ctx, "running migration %q", migration.name)
+		if err := migration.workFn(ctx, m.db); err != nil {
+			return errors.Wrapf(err, "failed to run migration %q", migration.name)
+		}
+		if err := m.db.Put(ctx, key, timeutil.Now().String()); err != nil {
+			return errors.Wrapf(err, "failed to record migration %q", migration.name)
+		}
+	}
+	return nil
+}
+
+func migrationKey(migration migrationDescriptor) roachpb.Key {
+	key := make(roachpb.Key, 0, len(keys.MigrationPrefix)+len(migration.name))
+	key = append(key, keys.MigrationPrefix...)
+	return append(key, migration.name...)
+}
+
+func createProtectedTimestampTable(ctx context.Context, db *client.DB) error {
+	return createSystemTable(ctx, db, sqlbase.ProtectedTimestampTable)
+}
+
+func createSettingsTable(ctx context.Context, db *client.DB) error {
+	return createSystemTable(ctx, db, sqlbase.SettingsTable)
+}
+
+func createWebSessionsTable(ctx context.Context, db *client.DB) error {
+	return createSystemTable(ctx, db, sqlbase.WebSessionsTable)
+}
+
+func createRoleMembersTable(ctx context.Context, db *client.DB) error {
+	return createSystemTable(ctx, db, sqlbase.RoleMembersTable)
+}
+
+func createChangeFeedsTable(ctx context.Context, db *client.DB) error {
+	return createSystemTable(ctx, db, sqlbase.ChangeFeedsTable)
+}
+
+func addUsersIsRoleColumn(ctx context.Context, db *client.DB) error {
+	// isRole is NOT NULL: the existing users, which can't be roles, must
+	// read as false rather than NULL.
+	return addSystemColumns(ctx, db, sqlbase.UsersTable, systemColumn{name: "isRole", value: parser.DBoolFalse})
+}
+
+func addUsersAuthColumns(ctx context.Context, db *client.DB) error {
+	// The existing users only have a bcrypt hash, and are allowed the
+	// default authentication methods.
+	return addSystemColumns(ctx, db, sqlbase.UsersTable,
+		systemColumn{name: "md5Password", value: parser.DNull},
+		systemColumn{name: "scramVerifier", value: parser.DNull},
+		systemColumn{name: "authMethods", value: parser.DNull},
+	)
+}
+
+// createSystemTable writes the namespace entry and descriptor of a system
+// table, unless the table already exists.
+func createSystemTable(ctx context.Context, db *client.DB, desc sqlbase.TableDescriptor) error {
+	nameKey := sqlbase.MakeNameMetadataKey(desc.ParentID, desc.Name)
+	return db.Txn(ctx, func(txn *client.Txn) error {
+		kv, err := txn.Get(nameKey)
+		if err != nil {
+			return err
+		}
+		if kv.Exists() {
+			return nil
+		}
+		// The descriptors are part of the system config, which must be
+		// gossiped again for the new table to be visible.
+		txn.SetSystemConfigTrigger()
+		b := txn.NewBatch()
+		b.Put(nameKey, desc.ID)
+		b.Put(sqlbase.MakeDescMetadataKey(desc.ID), sqlbase.WrapDescriptor(&desc))
+		return txn.Run(b)
+	})
+}
+
+// systemColumn is a column added to a system table by addSystemColumns,
+// along with the value of the existing rows, which may be DNull.
+type systemColumn struct {
+	name  string
+	value parser.Datum
+}
+
+// addSystemColumns adds the given columns of desc, along with their
+// families, to the stored descriptor of a system table, unless it already
+// has them, and backfills the existing rows. Each column must be the only
+// one of its family.
+func addSystemColumns(
+	ctx context.Context, db *client.DB, desc sqlbase.TableDescriptor, columns ...systemColumn,
+) error {
+	descKey := sqlbase.MakeDescMetadataKey(desc.ID)
+	return db.Txn(ctx, func(txn *client.Txn) error {
+		stored := &sqlbase.Descriptor{}
+		if err := txn.GetProto(descKey, stored); err != nil {
+			return err
+		}
+		table := stored.GetTable()
+		if table == nil {
+			return errors.Errorf("descriptor %d is not a table", desc.ID)
+		}
+
+		var families []sqlbase.FamilyID
+		var values []roachpb.Value
+		for _, column := range columns {
+			if _, err := table.FindActiveColumnByName(parser.Name(column.name)); err == nil {
+				continue
+			}
+			col, err := desc.FindActiveColumnByName(parser.Name(column.name))
+			if err != nil {
+				return err
+			}
+			var family *sqlbase.ColumnFamilyDescriptor
+			for i := range desc.Families {
+				if f := &desc.Families[i]; len(f.ColumnIDs) == 1 && f.ColumnIDs[0] == col.ID {
+					family = f
+				}
+			}
+			if family == nil {
+				return errors.Errorf("column %s of %s is not alone in its family", column.name, desc.Name)
+			}
+			value, err := sqlbase.MarshalColumnValue(col, column.value)
+			if err != nil {
+				return err
+			}
+			table.Columns = append(table.Columns, col)
+			table.Families = append(table.Families, *family)
+			if table.NextColumnID <= col.ID {
+				table.NextColumnID = col.ID + 1
+			}
+			if table.NextFamilyID <= family.ID {
+				table.NextFamilyID = family.ID + 1
+			}
+			families = append(families, family.ID)
+			values = append(values, value)
+		}
+		if len(families) == 0 {
+			return nil
+		}
+		table.Version++
+		if err := table.ValidateTable(); err != nil {
+			return err
+		}
+
+		// Rows are read in the same transaction as the descriptor is
+		// written, so that rows inserted with the old descriptor can't be
+		// missed.
+		prefix := roachpb.Key(sqlbase.MakeIndexKeyPrefix(table, table.PrimaryIndex.ID))
+		kvs, err := txn.Scan(prefix, prefix.PrefixEnd(), 0)
+		if err != nil {
+			return err
+		}
+		txn.SetSystemConfigTrigger()
+		b := txn.NewBatch()
+		b.Put(descKey, stored)
+		var lastRow roachpb.Key
+		for _, kv := range kvs {
+			row, err := keys.EnsureSafeSplitKey(kv.Key)
+			if err != nil {
+				return err
+			}
+			if row.Equal(lastRow) {
+				continue
+			}
+			lastRow = row
+			for i := range families {
+				// NULL values are not written (see rowInserter).
+				if values[i].RawBytes == nil {
+					continue
+				}
+				row = row[:len(row):len(row)]
+				b.Put(keys.MakeFamilyKey(row, uint32(families[i])), &values[i])
+			}
+		}
+		return txn.Run(b)
+	})
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package migrations_test
+
+import (
+	gosql "database/sql"
+	"fmt"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/migrations"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// dropSystemTable removes the namespace entry and descriptor of a system
+// table, as if the cluster had been bootstrapped by a version without it.
+func dropSystemTable(t *testing.T, kvDB *client.DB, desc sqlbase.TableDescriptor) {
+	if err := kvDB.Txn(context.Background(), func(txn *client.Txn) error {
+		txn.SetSystemConfigTrigger()
+		b := txn.NewBatch()
+		b.Del(sqlbase.MakeNameMetadataKey(desc.ParentID, desc.Name))
+		b.Del(sqlbase.MakeDescMetadataKey(desc.ID))
+		return txn.Run(b)
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// dropSystemColumns removes columns, along with their families, from the
+// stored descriptor of a system table, as if the cluster had been
+// bootstrapped by a version without them.
+func dropSystemColumns(
+	t *testing.T, kvDB *client.DB, desc sqlbase.TableDescriptor, names ...string,
+) {
+	dropped := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		dropped[name] = struct{}{}
+	}
+	var columns []sqlbase.ColumnDescriptor
+	for _, col := range desc.Columns {
+		if _, ok := dropped[col.Name]; !ok {
+			columns = append(columns, col)
+		}
+	}
+	var families []sqlbase.ColumnFamilyDescriptor
+	for _, family := range desc.Families {
+		if _, ok := dropped[family.ColumnNames[0]]; !ok || len(family.ColumnNames) > 1 {
+			families = append(families, family)
+		}
+	}
+	desc.Columns, desc.Families = columns, families
+	if err := kvDB.Txn(context.Background(), func(txn *client.Txn) error {
+		txn.SetSystemConfigTrigger()
+		return txn.Put(sqlbase.MakeDescMetadataKey(desc.ID), sqlbase.WrapDescriptor(&desc))
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// forgetMigrations removes the records of the completed migrations.
+func forgetMigrations(t *testing.T, kvDB *client.DB) {
+	if err := kvDB.DelRange(context.Background(), keys.MigrationPrefix, keys.MigrationKeyMax); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnsureMigrations(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop()
+	ctx := context.Background()
+
+	// The server ran the migrations when it started, and recorded them.
+	kvs, err := kvDB.Scan(ctx, keys.MigrationPrefix, keys.MigrationKeyMax, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) == 0 {
+		t.Fatal("expected the migrations to be recorded")
+	}
+
+	const query = `SELECT COUNT(*) FROM system.protected_ts`
+	dropSystemTable(t, kvDB, sqlbase.ProtectedTimestampTable)
+	if _, err := sqlDB.Exec(query); !testutils.IsError(err, "does not exist") {
+		t.Fatalf("expected the table to be missing, got %v", err)
+	}
+
+	// Recorded migrations are not run again.
+	mgr := migrations.NewManager(kvDB)
+	if err := mgr.EnsureMigrations(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(query); !testutils.IsError(err, "does not exist") {
+		t.Fatalf("expected the table to be missing, got %v", err)
+	}
+
+	// Once forgotten, they are, and running them again is harmless.
+	for i := 0; i < 2; i++ {
+		forgetMigrations(t, kvDB)
+		if err := mgr.EnsureMigrations(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sqlDB.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestCreateSystemTables verifies that the migrations create the system
+// tables which are missing on clusters bootstrapped by older versions.
+func TestCreateSystemTables(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop()
+	ctx := context.Background()
+
+	tables := []sqlbase.TableDescriptor{
+		sqlbase.ProtectedTimestampTable,
+		sqlbase.SettingsTable,
+		sqlbase.WebSessionsTable,
+		sqlbase.RoleMembersTable,
+		sqlbase.ChangeFeedsTable,
+	}
+	for _, desc := range tables {
+		dropSystemTable(t, kvDB, desc)
+	}
+	forgetMigrations(t, kvDB)
+	if err := migrations.NewManager(kvDB).EnsureMigrations(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, desc := range tables {
+		if _, err := sqlDB.Exec(fmt.Sprintf(`SELECT COUNT(*) FROM system.%s`, desc.Name)); err != nil {
+			t.Fatalf("%s: %s", desc.Name, err)
+		}
+	}
+}
+
+// TestAddSystemColumns verifies that the migrations add the columns which
+// are missing from the system tables of clusters bootstrapped by older
+// versions, and backfill the existing rows.
+func TestAddSystemColumns(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop()
+	ctx := context.Background()
+
+	if _, err := sqlDB.Exec(`CREATE USER olduser`); err != nil {
+		t.Fatal(err)
+	}
+	dropSystemColumns(t, kvDB, sqlbase.UsersTable,
+		"isRole", "md5Password", "scramVerifier", "authMethods")
+	// Older versions didn't write the value of isRole.
+	row := encoding.EncodeStringAscending(
+		sqlbase.MakeIndexKeyPrefix(&sqlbase.UsersTable, sqlbase.UsersTable.PrimaryIndex.ID), "olduser")
+	if err := kvDB.Del(ctx, keys.MakeFamilyKey(row, 3)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Running the migrations twice is harmless.
+	for i := 0; i < 2; i++ {
+		forgetMigrations(t, kvDB)
+		if err := migrations.NewManager(kvDB).EnsureMigrations(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var username string
+	var authMethods gosql.NullString
+	if err := sqlDB.QueryRow(
+		`SELECT username, "authMethods" FROM system.users WHERE NOT "isRole"`,
+	).Scan(&username, &authMethods); err != nil {
+		t.Fatal(err)
+	} else if username != "olduser" || authMethods.Valid {
+		t.Fatalf("expected olduser without authentication methods, got %s %v", username, authMethods)
+	}
+	if _, err := sqlDB.Exec(`CREATE ROLE newrole`); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -35,6 +35,13 @@ func (n NodeID) String() string {
 	return strconv.FormatInt(int64(n), 10)
 }
 
+// NodeIDSlice implements sort.Interface.
+type NodeIDSlice []NodeID
+
+func (s NodeIDSlice) Len() int           { return len(s) }
+func (s NodeIDSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s NodeIDSlice) Less(i, j int) bool { return s[i] < s[j] }
+
 // StoreID is a custom type for a cockroach store ID.
 type StoreID int32
 
@@ -18,6 +18,7 @@ package rpc
 
 import (
 	"math"
+	"net"
 	"sync"
 	"time"
 
@@ -68,7 +69,10 @@ func NewServer(ctx *Context) *grpc.Server {
 		if err != nil {
 			panic(err)
 		}
-		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		opts = append(opts, grpc.Creds(tlsCredentials{
+			TransportCredentials: credentials.NewTLS(tlsConfig),
+			ctx:                  ctx,
+		}))
 	}
 	s := grpc.NewServer(opts...)
 	RegisterHeartbeatServer(s, &HeartbeatService{
@@ -85,6 +89,35 @@ type connMeta struct {
 	healthy bool
 }
 
+// tlsCredentials runs the TLS handshake of each connection with the latest
+// TLS configs, so that connections opened or reopened after the
+// certificates are reloaded use, and verify their peer against, the new
+// ones.
+type tlsCredentials struct {
+	credentials.TransportCredentials
+	ctx *Context
+}
+
+// ClientHandshake implements credentials.TransportCredentials.
+func (c tlsCredentials) ClientHandshake(
+	ctx context.Context, addr string, rawConn net.Conn,
+) (net.Conn, credentials.AuthInfo, error) {
+	tlsConfig, err := c.ctx.GetClientTLSConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	return credentials.NewTLS(tlsConfig).ClientHandshake(ctx, addr, rawConn)
+}
+
+// ServerHandshake implements credentials.TransportCredentials.
+func (c tlsCredentials) ServerHandshake(rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	tlsConfig, err := c.ctx.GetServerTLSConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	return credentials.NewTLS(tlsConfig).ServerHandshake(rawConn)
+}
+
 // Context contains the fields required by the rpc framework.
 type Context struct {
 	*base.Config
@@ -209,7 +242,10 @@ func (ctx *Context) GRPCDial(target string, opts ...grpc.DialOption) (*grpc.Clie
 				meta.err = err
 				return
 			}
-			dialOpt = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+			dialOpt = grpc.WithTransportCredentials(tlsCredentials{
+				TransportCredentials: credentials.NewTLS(tlsConfig),
+				ctx:                  ctx,
+			})
 		}
 
 		dialOpts := make([]grpc.DialOption, 0, 2+len(opts))
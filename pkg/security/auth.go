@@ -120,8 +120,11 @@ func UserAuthCertHook(insecureMode bool, tlsState *tls.ConnectionState) (UserAut
 }
 
 // UserAuthPasswordHook builds an authentication hook based on the security
-// mode, password, and its potentially matching hash.
-func UserAuthPasswordHook(insecureMode bool, password string, hashedPassword []byte) UserAuthHook {
+// mode, the client's response to a password authentication request and the
+// credentials stored for the requested user.
+func UserAuthPasswordHook(
+	insecureMode bool, response PasswordResponse, creds *UserCredentials,
+) UserAuthHook {
 	return func(requestedUser string, clientConnection bool) error {
 		if len(requestedUser) == 0 {
 			return errors.New("user is missing")
@@ -139,8 +142,12 @@ func UserAuthPasswordHook(insecureMode bool, password string, hashedPassword []b
 			return errors.Errorf("user %s must authenticate using a client certificate ", RootUser)
 		}
 
-		// If the requested user has an empty password, disallow authentication.
-		if len(password) == 0 || compareHashAndPassword(hashedPassword, password) != nil {
+		if !creds.allows(response.Method()) {
+			return errors.Errorf("authentication method %s is not allowed for user %s",
+				response.Method(), requestedUser)
+		}
+
+		if response.verify(creds) != nil {
 			return errors.New("invalid password")
 		}
 
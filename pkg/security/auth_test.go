@@ -17,14 +17,18 @@
 package security_test
 
 import (
+	"crypto/md5"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/gogo/protobuf/proto"
 )
@@ -130,3 +134,119 @@ func TestAuthenticationHook(t *testing.T) {
 		}
 	}
 }
+
+func TestUserAuthPasswordHook(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	hashes, err := security.HashUserPassword("foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := &security.UserCredentials{
+		HashedPassword: hashes.Hashed,
+		MD5Password:    hashes.MD5,
+		SCRAMVerifier:  hashes.SCRAM,
+	}
+	scramOnly := *creds
+	scramOnly.Methods = []security.AuthMethod{security.AuthSCRAMSHA256}
+
+	// md5Response computes the response of a client to an md5 authentication
+	// request with the given salt.
+	md5Response := func(password string, salt []byte) security.MD5Response {
+		inner := md5.Sum([]byte(password + "foo"))
+		outer := md5.Sum(append([]byte(hex.EncodeToString(inner[:])), salt...))
+		return security.MD5Response{Salt: salt, Response: "md5" + hex.EncodeToString(outer[:])}
+	}
+	salt := []byte{1, 2, 3, 4}
+
+	testCases := []struct {
+		insecure    bool
+		user        string
+		response    security.PasswordResponse
+		creds       *security.UserCredentials
+		expectedErr string
+	}{
+		{false, "foo", security.CleartextPassword("bar"), creds, ""},
+		{false, "foo", security.CleartextPassword("baz"), creds, "invalid password"},
+		{false, "foo", security.CleartextPassword(""), creds, "invalid password"},
+		{false, "foo", md5Response("bar", salt), creds, ""},
+		{false, "foo", md5Response("baz", salt), creds, "invalid password"},
+		{false, "foo", security.MD5Response{Salt: salt, Response: string(hashes.MD5)}, creds,
+			"invalid password"},
+		{false, "foo", security.CleartextPassword("bar"), &scramOnly,
+			"authentication method password is not allowed for user foo"},
+		{false, "foo", md5Response("bar", salt), &scramOnly,
+			"authentication method md5 is not allowed for user foo"},
+		{false, "", security.CleartextPassword("bar"), creds, "user is missing"},
+		{false, security.RootUser, security.CleartextPassword("bar"), creds,
+			"user root must authenticate using a client certificate"},
+		{true, "foo", security.CleartextPassword("baz"), creds, ""},
+	}
+	for i, tc := range testCases {
+		hook := security.UserAuthPasswordHook(tc.insecure, tc.response, tc.creds)
+		if err := hook(tc.user, true /* public */); !testutils.IsError(err, tc.expectedErr) {
+			t.Errorf("%d: expected error %q, got %v", i, tc.expectedErr, err)
+		}
+		if err := hook(tc.user, false /* not public */); err == nil {
+			t.Errorf("%d: expected password authentication to fail for non-client connections", i)
+		}
+	}
+}
+
+func TestPreferredAuthMethod(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	hashes, err := security.HashUserPassword("foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	emptyHashes, err := security.HashUserPassword("foo", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if emptyHashes.MD5 != nil || emptyHashes.SCRAM != nil {
+		t.Fatalf("expected an empty password to only be hashed with bcrypt, got %+v", emptyHashes)
+	}
+
+	testCases := []struct {
+		hashes   security.UserPasswordHashes
+		methods  string
+		expected security.AuthMethod
+	}{
+		{hashes, "", security.AuthMD5},
+		{hashes, "scram-sha-256,md5", security.AuthSCRAMSHA256},
+		{hashes, "md5, password", security.AuthMD5},
+		{hashes, "PASSWORD,scram-sha-256", security.AuthPassword},
+		{emptyHashes, "", security.AuthPassword},
+		{emptyHashes, "scram-sha-256,md5", ""},
+	}
+	for i, tc := range testCases {
+		creds := &security.UserCredentials{
+			HashedPassword: tc.hashes.Hashed,
+			MD5Password:    tc.hashes.MD5,
+			SCRAMVerifier:  tc.hashes.SCRAM,
+		}
+		if tc.methods != "" {
+			if creds.Methods, err = security.ParseAuthMethods(tc.methods); err != nil {
+				t.Fatal(err)
+			}
+		}
+		method, ok := creds.PreferredMethod()
+		if method != tc.expected || ok != (tc.expected != "") {
+			t.Errorf("%d: expected method %q, got %q (%t)", i, tc.expected, method, ok)
+		}
+	}
+
+	if _, err := security.ParseAuthMethods("md5,kerberos"); !testutils.IsError(
+		err, `unknown authentication method "kerberos"`,
+	) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	methods, err := security.ParseAuthMethods("md5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []security.AuthMethod{security.AuthMD5}; !reflect.DeepEqual(methods, expected) {
+		t.Errorf("expected %v, got %v", expected, methods)
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+var (
+	metaCAExpiration = metric.Metadata{
+		Name: "security.certificate.expiration.ca",
+		Help: "Expiration time of the CA certificate, in seconds since the Unix epoch",
+	}
+	metaNodeExpiration = metric.Metadata{
+		Name: "security.certificate.expiration.node",
+		Help: "Expiration time of the node certificate, in seconds since the Unix epoch",
+	}
+	metaClientExpiration = metric.Metadata{
+		Name: "security.certificate.expiration.client",
+		Help: "Expiration time of the client certificate, in seconds since the Unix epoch",
+	}
+)
+
+// CertificateMetrics holds the expiration times of the loaded certificates.
+// Node certificates are also client certificates: nodes present them to
+// each other.
+type CertificateMetrics struct {
+	CAExpiration     *metric.Gauge
+	NodeExpiration   *metric.Gauge
+	ClientExpiration *metric.Gauge
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (CertificateMetrics) MetricStruct() {}
+
+// CertificateManager holds the CA certificate and the certificate and key of
+// a node or client, and the TLS configs built from them. The certificates
+// can be reloaded from disk while the process is running; if reloading
+// fails, the previously loaded certificates remain in use.
+//
+// TLS configs returned before a reload keep trusting the CA certificate they
+// were built with, but servers using them present the latest certificate.
+// Long-lived users should get the latest config for each connection, e.g.
+// with NewListener.
+type CertificateManager struct {
+	caCertPath, certPath, keyPath string
+
+	metrics CertificateMetrics
+
+	mu struct {
+		syncutil.RWMutex
+		cert         *tls.Certificate
+		serverConfig *tls.Config
+		clientConfig *tls.Config
+	}
+}
+
+// NewCertificateManager creates a CertificateManager and loads the
+// certificates at the given paths.
+func NewCertificateManager(sslCA, sslCert, sslCertKey string) (*CertificateManager, error) {
+	cm := &CertificateManager{
+		caCertPath: sslCA,
+		certPath:   sslCert,
+		keyPath:    sslCertKey,
+		metrics: CertificateMetrics{
+			CAExpiration:     metric.NewGauge(metaCAExpiration),
+			NodeExpiration:   metric.NewGauge(metaNodeExpiration),
+			ClientExpiration: metric.NewGauge(metaClientExpiration),
+		},
+	}
+	if err := cm.LoadCertificates(); err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
+
+// Metrics returns the metrics of the manager.
+func (cm *CertificateManager) Metrics() CertificateMetrics {
+	return cm.metrics
+}
+
+// LoadCertificates (re)loads the certificates from disk. On error, the
+// previously loaded certificates are kept.
+func (cm *CertificateManager) LoadCertificates() error {
+	certPEM, err := readFileFn(cm.certPath)
+	if err != nil {
+		return err
+	}
+	keyPEM, err := readFileFn(cm.keyPath)
+	if err != nil {
+		return err
+	}
+	caPEM, err := readFileFn(cm.caCertPath)
+	if err != nil {
+		return err
+	}
+
+	serverConfig, err := newServerTLSConfig(certPEM, keyPEM, caPEM)
+	if err != nil {
+		return err
+	}
+	clientConfig, err := newClientTLSConfig(certPEM, keyPEM, caPEM)
+	if err != nil {
+		return err
+	}
+	cert := serverConfig.Certificates[0]
+	x509Cert, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return errors.Wrapf(err, "error parsing certificate %s", cm.certPath)
+	}
+	block, _ := pem.Decode(caPEM)
+	if block == nil {
+		return errors.Errorf("no PEM data found in CA certificate %s", cm.caCertPath)
+	}
+	caCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errors.Wrapf(err, "error parsing CA certificate %s", cm.caCertPath)
+	}
+
+	// Servers present the latest certificate, even if they were started with
+	// a config returned before a reload.
+	serverConfig.Certificates = nil
+	serverConfig.GetCertificate = cm.getCertificate
+	// The configs returned after a reload are used as is by NewListener, and
+	// must allow HTTP/2 like the ones configured by http2.ConfigureServer.
+	serverConfig.NextProtos = []string{"h2", "http/1.1"}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.mu.cert = &cert
+	cm.mu.serverConfig = serverConfig
+	cm.mu.clientConfig = clientConfig
+	cm.metrics.CAExpiration.Update(caCert.NotAfter.Unix())
+	for _, usage := range x509Cert.ExtKeyUsage {
+		switch usage {
+		case x509.ExtKeyUsageServerAuth:
+			cm.metrics.NodeExpiration.Update(x509Cert.NotAfter.Unix())
+		case x509.ExtKeyUsageClientAuth:
+			cm.metrics.ClientExpiration.Update(x509Cert.NotAfter.Unix())
+		}
+	}
+	return nil
+}
+
+func (cm *CertificateManager) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.mu.cert, nil
+}
+
+// ServerTLSConfig returns a server TLS config built from the latest
+// certificates.
+func (cm *CertificateManager) ServerTLSConfig() *tls.Config {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.mu.serverConfig
+}
+
+// ClientTLSConfig returns a client TLS config built from the latest
+// certificates.
+func (cm *CertificateManager) ClientTLSConfig() *tls.Config {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	return cm.mu.clientConfig
+}
+
+// NewListener returns a listener which runs the TLS handshake of each
+// connection accepted by inner with the latest server TLS config. Unlike
+// with tls.NewListener, clients are verified against the latest CA
+// certificate.
+func (cm *CertificateManager) NewListener(inner net.Listener) net.Listener {
+	return &tlsListener{Listener: inner, cm: cm}
+}
+
+type tlsListener struct {
+	net.Listener
+	cm *CertificateManager
+}
+
+// Accept implements net.Listener.
+func (l *tlsListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return tls.Server(conn, l.cm.ServerTLSConfig()), nil
+}
+
+// RegisterSignalHandler reloads the certificates every time the process
+// receives SIGHUP, until stopper is closed.
+func (cm *CertificateManager) RegisterSignalHandler(stopper <-chan struct{}) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(ch)
+		ctx := context.TODO()
+		for {
+			select {
+			case <-stopper:
+				return
+			case <-ch:
+				log.Infof(ctx, "received SIGHUP, reloading certificates")
+				if err := cm.LoadCertificates(); err != nil {
+					log.Warningf(ctx, "could not reload certificates, keeping the current ones: %s", err)
+				} else {
+					log.Infof(ctx, "successfully reloaded certificates")
+				}
+			}
+		}
+	}()
+}
+
+// CertificateInfo describes a certificate file.
+type CertificateInfo struct {
+	Filename string
+	// Usage is "CA", "node" or "client".
+	Usage      string
+	CommonName string
+	Hosts      []string
+	NotBefore  time.Time
+	NotAfter   time.Time
+}
+
+// ListCertificates returns the certificates found in the .crt files of the
+// given directory, sorted by filename.
+func ListCertificates(dir string) ([]CertificateInfo, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var infos []CertificateInfo
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ".crt" {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		certPEM, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		block, _ := pem.Decode(certPEM)
+		if block == nil {
+			return nil, errors.Errorf("no PEM data found in %s", path)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing %s", path)
+		}
+		info := CertificateInfo{
+			Filename:   f.Name(),
+			Usage:      "client",
+			CommonName: cert.Subject.CommonName,
+			Hosts:      cert.DNSNames,
+			NotBefore:  cert.NotBefore,
+			NotAfter:   cert.NotAfter,
+		}
+		for _, ip := range cert.IPAddresses {
+			info.Hosts = append(info.Hosts, ip.String())
+		}
+		if cert.IsCA {
+			info.Usage = "CA"
+		} else {
+			for _, usage := range cert.ExtKeyUsage {
+				if usage == x509.ExtKeyUsageServerAuth {
+					info.Usage = "node"
+				}
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
@@ -0,0 +1,256 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security_test
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func createCACert(t *testing.T, certsDir string) {
+	for _, name := range []string{security.EmbeddedCACert, security.EmbeddedCAKey} {
+		if err := os.Remove(filepath.Join(certsDir, name)); err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+	}
+	if err := security.RunCreateCACert(
+		filepath.Join(certsDir, security.EmbeddedCACert),
+		filepath.Join(certsDir, security.EmbeddedCAKey),
+		512); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func createNodeCert(t *testing.T, certsDir string) {
+	for _, name := range []string{security.EmbeddedNodeCert, security.EmbeddedNodeKey} {
+		if err := os.Remove(filepath.Join(certsDir, name)); err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+	}
+	if err := security.RunCreateNodeCert(
+		filepath.Join(certsDir, security.EmbeddedCACert),
+		filepath.Join(certsDir, security.EmbeddedCAKey),
+		filepath.Join(certsDir, security.EmbeddedNodeCert),
+		filepath.Join(certsDir, security.EmbeddedNodeKey),
+		512, []string{"localhost"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCertificateManagerReload(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// Do not mock cert access for this test.
+	security.ResetReadFileFn()
+	defer ResetTest()
+	certsDir := util.CreateTempDir(t, "certs_test")
+	defer util.CleanupDir(certsDir)
+
+	createCACert(t, certsDir)
+	createNodeCert(t, certsDir)
+
+	cm, err := security.NewCertificateManager(
+		filepath.Join(certsDir, security.EmbeddedCACert),
+		filepath.Join(certsDir, security.EmbeddedNodeCert),
+		filepath.Join(certsDir, security.EmbeddedNodeKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The expiration metrics match the certificates on disk.
+	infos, err := security.ListCertificates(certsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 certificates, got %+v", infos)
+	}
+	if e, a := infos[0].NotAfter.Unix(), cm.Metrics().CAExpiration.Value(); e != a {
+		t.Errorf("expected CA expiration %d, got %d", e, a)
+	}
+	if e, a := infos[1].NotAfter.Unix(), cm.Metrics().NodeExpiration.Value(); e != a {
+		t.Errorf("expected node expiration %d, got %d", e, a)
+	}
+	// The node certificate is also used as a client certificate.
+	if e, a := infos[1].NotAfter.Unix(), cm.Metrics().ClientExpiration.Value(); e != a {
+		t.Errorf("expected client expiration %d, got %d", e, a)
+	}
+
+	serverConfig := cm.ServerTLSConfig()
+	cert, err := serverConfig.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	origCert := cert.Certificate[0]
+
+	// A failed reload keeps the current certificates.
+	if err := ioutil.WriteFile(
+		filepath.Join(certsDir, security.EmbeddedNodeKey), []byte("garbage"), 0600,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.LoadCertificates(); err == nil {
+		t.Fatal("expected reloading an invalid key to fail")
+	}
+	if cert, err = serverConfig.GetCertificate(nil); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(cert.Certificate[0], origCert) {
+		t.Error("expected the original certificate to be kept after a failed reload")
+	}
+	if cm.ServerTLSConfig() != serverConfig {
+		t.Error("expected the original server TLS config to be kept after a failed reload")
+	}
+
+	// A successful reload changes the certificate presented by configs
+	// returned before the reload.
+	createNodeCert(t, certsDir)
+	if err := cm.LoadCertificates(); err != nil {
+		t.Fatal(err)
+	}
+	if cert, err = serverConfig.GetCertificate(nil); err != nil {
+		t.Fatal(err)
+	} else if bytes.Equal(cert.Certificate[0], origCert) {
+		t.Error("expected the new certificate to be presented after a reload")
+	}
+	clientCerts := cm.ClientTLSConfig().Certificates
+	if len(clientCerts) != 1 || !bytes.Equal(clientCerts[0].Certificate[0], cert.Certificate[0]) {
+		t.Error("expected the client TLS config to use the new certificate")
+	}
+}
+
+func TestCertificateManagerNewListener(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// Do not mock cert access for this test.
+	security.ResetReadFileFn()
+	defer ResetTest()
+	certsDir := util.CreateTempDir(t, "certs_test")
+	defer util.CleanupDir(certsDir)
+
+	createCACert(t, certsDir)
+	createNodeCert(t, certsDir)
+	cm, err := security.NewCertificateManager(
+		filepath.Join(certsDir, security.EmbeddedCACert),
+		filepath.Join(certsDir, security.EmbeddedNodeCert),
+		filepath.Join(certsDir, security.EmbeddedNodeKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := cm.NewListener(inner)
+	defer ln.Close()
+	_, port, err := net.SplitHostPort(ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// handshake connects to the listener with the latest client TLS config,
+	// and returns the errors of both sides of the handshake.
+	handshake := func() (serverErr error, clientErr error) {
+		errCh := make(chan error, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				errCh <- err
+				return
+			}
+			defer conn.Close()
+			errCh <- conn.(*tls.Conn).Handshake()
+		}()
+		conn, clientErr := tls.Dial("tcp", net.JoinHostPort("localhost", port), cm.ClientTLSConfig())
+		if clientErr == nil {
+			_ = conn.Close()
+		}
+		return <-errCh, clientErr
+	}
+	if serverErr, clientErr := handshake(); serverErr != nil || clientErr != nil {
+		t.Fatalf("server: %v, client: %v", serverErr, clientErr)
+	}
+
+	// Once the CA and node certificates are replaced and reloaded, the
+	// listener accepts clients with a certificate signed by the new CA.
+	createCACert(t, certsDir)
+	createNodeCert(t, certsDir)
+	if err := cm.LoadCertificates(); err != nil {
+		t.Fatal(err)
+	}
+	if serverErr, clientErr := handshake(); serverErr != nil || clientErr != nil {
+		t.Fatalf("server: %v, client: %v", serverErr, clientErr)
+	}
+}
+
+func TestListCertificates(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// Do not mock cert access for this test.
+	security.ResetReadFileFn()
+	defer ResetTest()
+	certsDir := util.CreateTempDir(t, "certs_test")
+	defer util.CleanupDir(certsDir)
+
+	if err := security.RunCreateCACert(
+		filepath.Join(certsDir, security.EmbeddedCACert),
+		filepath.Join(certsDir, security.EmbeddedCAKey),
+		512); err != nil {
+		t.Fatal(err)
+	}
+	createNodeCert(t, certsDir)
+	if err := security.RunCreateClientCert(
+		filepath.Join(certsDir, security.EmbeddedCACert),
+		filepath.Join(certsDir, security.EmbeddedCAKey),
+		filepath.Join(certsDir, security.EmbeddedRootCert),
+		filepath.Join(certsDir, security.EmbeddedRootKey),
+		512, security.RootUser); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := security.ListCertificates(certsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type summary struct {
+		filename, usage, commonName string
+		hosts                       []string
+	}
+	var summaries []summary
+	for _, info := range infos {
+		if !info.NotBefore.Before(info.NotAfter) {
+			t.Errorf("%s: invalid validity period %s - %s", info.Filename, info.NotBefore, info.NotAfter)
+		}
+		summaries = append(summaries, summary{info.Filename, info.Usage, info.CommonName, info.Hosts})
+	}
+	expected := []summary{
+		{security.EmbeddedCACert, "CA", "Cockroach CA", nil},
+		{security.EmbeddedNodeCert, "node", "node", []string{"localhost"}},
+		{security.EmbeddedRootCert, "client", security.RootUser, nil},
+	}
+	if !reflect.DeepEqual(summaries, expected) {
+		t.Errorf("expected %+v, got %+v", expected, summaries)
+	}
+
+	if _, err := security.ListCertificates(filepath.Join(certsDir, "missing")); err == nil {
+		t.Error("expected listing a missing directory to fail")
+	}
+}
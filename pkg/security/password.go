@@ -18,8 +18,12 @@ package security
 
 import (
 	"bytes"
+	"crypto/md5"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
+	"strings"
 	"syscall"
 
 	"github.com/pkg/errors"
@@ -44,6 +48,179 @@ func HashPassword(password string) ([]byte, error) {
 	return bcrypt.GenerateFromPassword(h.Sum([]byte(password)), bcryptCost)
 }
 
+// HashPasswordMD5 returns the md5 password hash which postgres clients
+// compute for the given user: "md5" followed by the hex encoded md5 hash of
+// the password and the username.
+func HashPasswordMD5(username, password string) []byte {
+	return saltMD5([]byte(password), []byte(username))
+}
+
+const md5Prefix = "md5"
+
+func saltMD5(secret, salt []byte) []byte {
+	h := md5.New()
+	_, _ = h.Write(secret)
+	_, _ = h.Write(salt)
+	return []byte(md5Prefix + hex.EncodeToString(h.Sum(nil)))
+}
+
+// UserPasswordHashes holds a user's password in each of the forms stored in
+// system.users.
+type UserPasswordHashes struct {
+	Hashed []byte
+	MD5    []byte
+	SCRAM  []byte
+}
+
+// HashUserPassword hashes the password of the given user for each
+// authentication method. Empty passwords can't be used to authenticate, so
+// they are only hashed with bcrypt.
+func HashUserPassword(username, password string) (UserPasswordHashes, error) {
+	var hashes UserPasswordHashes
+	var err error
+	if hashes.Hashed, err = HashPassword(password); err != nil {
+		return UserPasswordHashes{}, err
+	}
+	if len(password) == 0 {
+		return hashes, nil
+	}
+	hashes.MD5 = HashPasswordMD5(username, password)
+	if hashes.SCRAM, err = HashPasswordSCRAM(password); err != nil {
+		return UserPasswordHashes{}, err
+	}
+	return hashes, nil
+}
+
+// AuthMethod identifies a way for a client to prove that it knows a user's
+// password.
+type AuthMethod string
+
+const (
+	// AuthPassword has the client send its password in cleartext.
+	AuthPassword AuthMethod = "password"
+	// AuthMD5 has the client send a salted md5 hash of its password. It only
+	// exists for legacy drivers: the stored hash is enough to authenticate as
+	// the user.
+	AuthMD5 AuthMethod = "md5"
+	// AuthSCRAMSHA256 runs a SCRAM-SHA-256 exchange, during which neither the
+	// password nor anything equivalent to it is sent to the server.
+	AuthSCRAMSHA256 AuthMethod = "scram-sha-256"
+)
+
+// DefaultAuthMethods are the authentication methods allowed for users which
+// don't specify their own, in order of preference. SCRAM-SHA-256 has to be
+// enabled per user, since many drivers, including lib/pq which the command
+// line tools use, don't support it yet.
+var DefaultAuthMethods = []AuthMethod{AuthMD5, AuthPassword}
+
+// ParseAuthMethods parses a comma-separated list of authentication methods.
+func ParseAuthMethods(s string) ([]AuthMethod, error) {
+	var methods []AuthMethod
+	for _, m := range strings.Split(s, ",") {
+		method := AuthMethod(strings.ToLower(strings.TrimSpace(m)))
+		switch method {
+		case AuthPassword, AuthMD5, AuthSCRAMSHA256:
+		default:
+			return nil, errors.Errorf("unknown authentication method %q", m)
+		}
+		methods = append(methods, method)
+	}
+	return methods, nil
+}
+
+// UserCredentials are the password credentials stored for a user.
+type UserCredentials struct {
+	// HashedPassword is the bcrypt hash of the password.
+	HashedPassword []byte
+	// MD5Password and SCRAMVerifier are only set for users with a non-empty
+	// password.
+	MD5Password   []byte
+	SCRAMVerifier []byte
+	// Methods are the authentication methods the user may use, in order of
+	// preference. DefaultAuthMethods are used if it is empty.
+	Methods []AuthMethod
+}
+
+func (c *UserCredentials) methods() []AuthMethod {
+	if len(c.Methods) == 0 {
+		return DefaultAuthMethods
+	}
+	return c.Methods
+}
+
+func (c *UserCredentials) allows(method AuthMethod) bool {
+	for _, m := range c.methods() {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// PreferredMethod returns the first of the user's authentication methods for
+// which credentials are stored, or false if there is none. Cleartext
+// passwords are checked against the bcrypt hash, which every user has.
+func (c *UserCredentials) PreferredMethod() (AuthMethod, bool) {
+	for _, m := range c.methods() {
+		switch m {
+		case AuthSCRAMSHA256:
+			if len(c.SCRAMVerifier) > 0 {
+				return m, true
+			}
+		case AuthMD5:
+			if len(c.MD5Password) > 0 {
+				return m, true
+			}
+		case AuthPassword:
+			return m, true
+		}
+	}
+	return "", false
+}
+
+// PasswordResponse is a client's response to a password authentication
+// request. UserAuthPasswordHook verifies it against the user's credentials.
+type PasswordResponse interface {
+	// Method returns the authentication method the response was made with.
+	Method() AuthMethod
+	verify(creds *UserCredentials) error
+}
+
+// CleartextPassword is a password sent in cleartext by the client.
+type CleartextPassword string
+
+// Method implements PasswordResponse.
+func (CleartextPassword) Method() AuthMethod { return AuthPassword }
+
+func (p CleartextPassword) verify(creds *UserCredentials) error {
+	// Users with an empty password are not allowed to authenticate.
+	if len(p) == 0 {
+		return errors.New("empty password")
+	}
+	return compareHashAndPassword(creds.HashedPassword, string(p))
+}
+
+// MD5Response is a client's response to an md5 authentication request: the
+// salted md5 hash of the user's md5 password hash, in the same format.
+type MD5Response struct {
+	Salt     []byte
+	Response string
+}
+
+// Method implements PasswordResponse.
+func (MD5Response) Method() AuthMethod { return AuthMD5 }
+
+func (r MD5Response) verify(creds *UserCredentials) error {
+	if !bytes.HasPrefix(creds.MD5Password, []byte(md5Prefix)) {
+		return errors.New("no md5 password hash")
+	}
+	expected := saltMD5(creds.MD5Password[len(md5Prefix):], r.Salt)
+	if subtle.ConstantTimeCompare(expected, []byte(r.Response)) != 1 {
+		return errors.New("md5 password hash mismatch")
+	}
+	return nil
+}
+
 // PromptForPassword prompts for a password twice, returning the read string if
 // they match, or an error.
 func PromptForPassword() (string, error) {
@@ -65,13 +242,3 @@ func PromptForPassword() (string, error) {
 
 	return string(one), nil
 }
-
-// PromptForPasswordAndHash prompts for a password twice and returns the bcrypt
-// hash.
-func PromptForPasswordAndHash() ([]byte, error) {
-	password, err := PromptForPassword()
-	if err != nil {
-		return nil, err
-	}
-	return HashPassword(password)
-}
@@ -0,0 +1,282 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// SCRAMSHA256Mechanism is the SASL name of the SCRAM-SHA-256 mechanism.
+const SCRAMSHA256Mechanism = "SCRAM-SHA-256"
+
+const (
+	scramIterations = 4096
+	scramSaltSize   = 16
+	scramNonceSize  = 18
+)
+
+// scramNonce generates the server's part of the nonce of an exchange. It is
+// overridden in tests.
+var scramNonce = func() (string, error) {
+	nonce := make([]byte, scramNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(nonce), nil
+}
+
+// scramVerifier holds what the server needs to know about a password to run
+// a SCRAM exchange. It is stored in the same format as in postgres:
+//   SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>
+// where the salt and keys are base64 encoded.
+type scramVerifier struct {
+	iterations int
+	salt       []byte
+	storedKey  []byte
+	serverKey  []byte
+}
+
+func scramHMAC(key []byte, msg string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(msg))
+	return h.Sum(nil)
+}
+
+func makeSCRAMVerifier(password string, salt []byte, iterations int) scramVerifier {
+	saltedPassword := pbkdf2.Key([]byte(password), salt, iterations, sha256.Size, sha256.New)
+	storedKey := sha256.Sum256(scramHMAC(saltedPassword, "Client Key"))
+	return scramVerifier{
+		iterations: iterations,
+		salt:       salt,
+		storedKey:  storedKey[:],
+		serverKey:  scramHMAC(saltedPassword, "Server Key"),
+	}
+}
+
+func (v scramVerifier) encode() []byte {
+	enc := base64.StdEncoding.EncodeToString
+	return []byte(fmt.Sprintf("%s$%d:%s$%s:%s",
+		SCRAMSHA256Mechanism, v.iterations, enc(v.salt), enc(v.storedKey), enc(v.serverKey)))
+}
+
+func decodeSCRAMVerifier(b []byte) (scramVerifier, error) {
+	var v scramVerifier
+	malformed := errors.New("malformed SCRAM verifier")
+	parts := strings.Split(string(b), "$")
+	if len(parts) != 3 || parts[0] != SCRAMSHA256Mechanism {
+		return scramVerifier{}, malformed
+	}
+	params := strings.Split(parts[1], ":")
+	keys := strings.Split(parts[2], ":")
+	if len(params) != 2 || len(keys) != 2 {
+		return scramVerifier{}, malformed
+	}
+	var err error
+	if v.iterations, err = strconv.Atoi(params[0]); err != nil || v.iterations <= 0 {
+		return scramVerifier{}, malformed
+	}
+	dsts := []*[]byte{&v.salt, &v.storedKey, &v.serverKey}
+	for i, src := range []string{params[1], keys[0], keys[1]} {
+		if *dsts[i], err = base64.StdEncoding.DecodeString(src); err != nil {
+			return scramVerifier{}, malformed
+		}
+	}
+	return v, nil
+}
+
+// HashPasswordSCRAM returns the SCRAM-SHA-256 verifier of the password, with
+// a random salt.
+func HashPasswordSCRAM(password string) ([]byte, error) {
+	salt := make([]byte, scramSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return makeSCRAMVerifier(password, salt, scramIterations).encode(), nil
+}
+
+// SCRAMExchange is the server side of a SCRAM-SHA-256 exchange (RFC 5802,
+// RFC 7677):
+//
+//   client-first-message ->
+//                        <- server-first-message
+//   client-final-message ->
+//                        <- server-final-message
+//
+// Once the client-final-message has been read, the exchange is the
+// PasswordResponse of the client, whose verification checks the client's
+// proof. The server-final-message proves to the client that the server knows
+// the verifier; it must only be sent once the client has been authenticated.
+//
+// Like in postgres, the username in the client-first-message is ignored in
+// favor of the one from the startup message. Channel binding and SASLprep
+// normalization of passwords are not supported.
+type SCRAMExchange struct {
+	verifier scramVerifier
+
+	gs2Header               string
+	nonce                   string
+	clientFirstBare         string
+	serverFirst             string
+	clientFinalWithoutProof string
+	proof                   []byte
+}
+
+var _ PasswordResponse = &SCRAMExchange{}
+
+// NewSCRAMExchange starts an exchange for a user with the given SCRAM
+// verifier.
+func NewSCRAMExchange(verifier []byte) (*SCRAMExchange, error) {
+	v, err := decodeSCRAMVerifier(verifier)
+	if err != nil {
+		return nil, err
+	}
+	return &SCRAMExchange{verifier: v}, nil
+}
+
+// splitSCRAMAttributes returns the values of the leading attributes of a SCRAM
+// message, checking that they have the given names. Trailing attributes are
+// ignored.
+func splitSCRAMAttributes(msg string, names ...byte) ([]string, error) {
+	attrs := strings.Split(msg, ",")
+	if len(attrs) < len(names) {
+		return nil, errors.Errorf("malformed SCRAM message %q", msg)
+	}
+	values := make([]string, len(names))
+	for i, name := range names {
+		attr := attrs[i]
+		if len(attr) < 2 || attr[0] != name || attr[1] != '=' {
+			return nil, errors.Errorf("malformed SCRAM message %q", msg)
+		}
+		values[i] = attr[2:]
+	}
+	return values, nil
+}
+
+// ServerFirst reads the client-first-message and returns the
+// server-first-message.
+func (s *SCRAMExchange) ServerFirst(clientFirst []byte) ([]byte, error) {
+	// The message starts with the gs2 header: the channel binding flag and an
+	// optional authorization identity.
+	parts := strings.SplitN(string(clientFirst), ",", 3)
+	if len(parts) != 3 {
+		return nil, errors.Errorf("malformed SCRAM message %q", clientFirst)
+	}
+	switch {
+	case parts[0] == "n", parts[0] == "y":
+	case strings.HasPrefix(parts[0], "p="):
+		return nil, errors.New("SCRAM channel binding is not supported")
+	default:
+		return nil, errors.Errorf("malformed SCRAM message %q", clientFirst)
+	}
+	if parts[1] != "" {
+		return nil, errors.New("SCRAM authorization identities are not supported")
+	}
+	s.gs2Header = parts[0] + "," + parts[1] + ","
+	s.clientFirstBare = parts[2]
+
+	values, err := splitSCRAMAttributes(s.clientFirstBare, 'n', 'r')
+	if err != nil {
+		return nil, err
+	}
+	clientNonce := values[1]
+	if clientNonce == "" {
+		return nil, errors.New("empty SCRAM client nonce")
+	}
+	serverNonce, err := scramNonce()
+	if err != nil {
+		return nil, err
+	}
+	s.nonce = clientNonce + serverNonce
+	s.serverFirst = fmt.Sprintf("r=%s,s=%s,i=%d",
+		s.nonce, base64.StdEncoding.EncodeToString(s.verifier.salt), s.verifier.iterations)
+	return []byte(s.serverFirst), nil
+}
+
+// ReadClientFinal reads the client-final-message, which holds the client's
+// proof that it knows the password.
+func (s *SCRAMExchange) ReadClientFinal(clientFinal []byte) error {
+	if s.serverFirst == "" {
+		return errors.New("SCRAM client-final-message sent before the server-first-message")
+	}
+	msg := string(clientFinal)
+	// The proof is always the last attribute.
+	i := strings.LastIndex(msg, ",p=")
+	if i < 0 {
+		return errors.Errorf("malformed SCRAM message %q", msg)
+	}
+	proof, err := base64.StdEncoding.DecodeString(msg[i+len(",p="):])
+	if err != nil {
+		return errors.Errorf("malformed SCRAM message %q", msg)
+	}
+	values, err := splitSCRAMAttributes(msg[:i], 'c', 'r')
+	if err != nil {
+		return err
+	}
+	if binding, err := base64.StdEncoding.DecodeString(values[0]); err != nil ||
+		string(binding) != s.gs2Header {
+		return errors.New("SCRAM channel binding mismatch")
+	}
+	if values[1] != s.nonce {
+		return errors.New("SCRAM nonce mismatch")
+	}
+	s.clientFinalWithoutProof = msg[:i]
+	s.proof = proof
+	return nil
+}
+
+func (s *SCRAMExchange) authMessage() string {
+	return s.clientFirstBare + "," + s.serverFirst + "," + s.clientFinalWithoutProof
+}
+
+// ServerFinal returns the server-final-message.
+func (s *SCRAMExchange) ServerFinal() []byte {
+	signature := scramHMAC(s.verifier.serverKey, s.authMessage())
+	return []byte("v=" + base64.StdEncoding.EncodeToString(signature))
+}
+
+// Method implements PasswordResponse.
+func (s *SCRAMExchange) Method() AuthMethod { return AuthSCRAMSHA256 }
+
+// verify checks the client's proof against the verifier the exchange was
+// started with, which came from the same credentials.
+func (s *SCRAMExchange) verify(_ *UserCredentials) error {
+	if s.proof == nil {
+		return errors.New("SCRAM exchange is incomplete")
+	}
+	if len(s.proof) != sha256.Size {
+		return errors.New("SCRAM proof mismatch")
+	}
+	// The proof is the client key XORed with the client signature. Recover
+	// the client key and check that it hashes to the stored key.
+	clientKey := scramHMAC(s.verifier.storedKey, s.authMessage())
+	for i := range clientKey {
+		clientKey[i] ^= s.proof[i]
+	}
+	storedKey := sha256.Sum256(clientKey)
+	if subtle.ConstantTimeCompare(storedKey[:], s.verifier.storedKey) != 1 {
+		return errors.New("SCRAM proof mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// isError checks that err contains substr, or that it is nil if substr is
+// empty. testutils.IsError can't be used here since testutils imports this
+// package.
+func isError(err error, substr string) bool {
+	if substr == "" {
+		return err == nil
+	}
+	return err != nil && strings.Contains(err.Error(), substr)
+}
+
+// TestSCRAMExchange runs the SCRAM-SHA-256 example exchange from RFC 7677.
+func TestSCRAMExchange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer func(f func() (string, error)) { scramNonce = f }(scramNonce)
+	scramNonce = func() (string, error) { return "%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0", nil }
+
+	salt, err := base64.StdEncoding.DecodeString("W22ZaJ0SNY7soEsUEjb6gQ==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := &UserCredentials{SCRAMVerifier: makeSCRAMVerifier("pencil", salt, 4096).encode()}
+
+	const (
+		clientFirst = "n,,n=user,r=rOprNGfwEbeRWgbNEkqO"
+		serverFirst = "r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0," +
+			"s=W22ZaJ0SNY7soEsUEjb6gQ==,i=4096"
+		clientFinalWithoutProof = "c=biws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0"
+		proof                   = "dHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="
+		badProof                = "AHzbZapWIk4jUhN+Ute9ytag9zjfMHgsqmmiz7AndVQ="
+		serverFinal             = "v=6rriTRBi23WpRR/wtup+mMhUZUn/dB5nLTJRsjl95G4="
+	)
+
+	testCases := []struct {
+		clientFinal string
+		expectedErr string
+	}{
+		{clientFinalWithoutProof + ",p=" + proof, ""},
+		{clientFinalWithoutProof + ",p=" + badProof, "SCRAM proof mismatch"},
+		{"c=biws,r=rOprNGfwEbeRWgbNEkqO,p=" + proof, "SCRAM nonce mismatch"},
+		{"c=eSws,r=rOprNGfwEbeRWgbNEkqO%hvYDpWUa2RaTCAfuxFIlj)hNlF$k0,p=" + proof,
+			"SCRAM channel binding mismatch"},
+		{clientFinalWithoutProof, "malformed SCRAM message"},
+	}
+	for i, tc := range testCases {
+		s, err := NewSCRAMExchange(creds.SCRAMVerifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.verify(creds); err == nil {
+			t.Fatalf("%d: expected an incomplete exchange to fail verification", i)
+		}
+		msg, err := s.ServerFirst([]byte(clientFirst))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(msg) != serverFirst {
+			t.Fatalf("%d: expected server-first-message %q, got %q", i, serverFirst, msg)
+		}
+		err = s.ReadClientFinal([]byte(tc.clientFinal))
+		if err == nil {
+			err = s.verify(creds)
+		}
+		if tc.expectedErr == "" {
+			if err != nil {
+				t.Fatalf("%d: %v", i, err)
+			}
+			if msg := s.ServerFinal(); string(msg) != serverFinal {
+				t.Errorf("%d: expected server-final-message %q, got %q", i, serverFinal, msg)
+			}
+		} else if !isError(err, tc.expectedErr) {
+			t.Errorf("%d: expected error %q, got %v", i, tc.expectedErr, err)
+		}
+	}
+}
+
+func TestSCRAMClientFirst(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	verifier, err := HashPasswordSCRAM("pencil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		clientFirst string
+		expectedErr string
+	}{
+		{"n,,n=,r=abc", ""},
+		{"y,,n=user,r=abc", ""},
+		{"p=tls-unique,,n=user,r=abc", "channel binding is not supported"},
+		{"n,a=admin,n=user,r=abc", "authorization identities are not supported"},
+		{"n,,n=user,r=", "empty SCRAM client nonce"},
+		{"n,,m=ext,n=user,r=abc", "malformed SCRAM message"},
+		{"n,,r=abc", "malformed SCRAM message"},
+		{"x,,n=user,r=abc", "malformed SCRAM message"},
+	}
+	for i, tc := range testCases {
+		s, err := NewSCRAMExchange(verifier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = s.ServerFirst([]byte(tc.clientFirst))
+		if !isError(err, tc.expectedErr) {
+			t.Errorf("%d: expected error %q, got %v", i, tc.expectedErr, err)
+		}
+	}
+}
+
+func TestSCRAMVerifierEncoding(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	verifier, err := HashPasswordSCRAM("pencil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := decodeSCRAMVerifier(verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoded := v.encode(); string(encoded) != string(verifier) {
+		t.Errorf("expected %s to round trip, got %s", verifier, encoded)
+	}
+	for _, s := range []string{
+		"",
+		"md5abc",
+		"SCRAM-SHA-256$4096:c2FsdA==",
+		"SCRAM-SHA-256$x:c2FsdA==$a2V5:a2V5",
+		"SCRAM-SHA-256$4096:c2FsdA==$!!!:a2V5",
+	} {
+		if _, err := decodeSCRAMVerifier([]byte(s)); err == nil {
+			t.Errorf("expected %q to fail to decode", s)
+		}
+	}
+}
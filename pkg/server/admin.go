@@ -21,16 +21,19 @@ package server
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/gogo/protobuf/proto"
 	gwruntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/build"
@@ -110,13 +113,47 @@ func (s *adminServer) RegisterGateway(
 	return serverpb.RegisterAdminHandler(ctx, mux, conn)
 }
 
-// getUserProto will return the authenticated user. For now, this is just a stub until we
-// figure out our authentication mechanism.
-//
-// TODO(cdo): Make this work when we have an authentication scheme for the
-// API.
-func (s *adminServer) getUser(_ proto.Message) string {
-	return security.RootUser
+// getUser returns the user who made the RPC. gRPC requests are made by the
+// user of their client certificate, except for those made by the gRPC
+// gateway, which are made by the node on behalf of the user authenticated
+// by the authenticationMux (see webUserMetadataKey). The node user itself,
+// which is also used by the command line tools, acts as root, as do the
+// requests of insecure clusters and of the server itself.
+func getUser(ctx context.Context) (string, error) {
+	user := security.RootUser
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			certUser, err := security.GetCertificateUser(&tlsInfo.State)
+			if err != nil {
+				return "", grpc.Errorf(codes.Unauthenticated, "%s", err)
+			}
+			user = certUser
+		}
+	}
+	if user == security.NodeUser {
+		user = security.RootUser
+		if md, ok := metadata.FromContext(ctx); ok {
+			if webUsers := md[webUserMetadataKey]; len(webUsers) > 0 {
+				user = webUsers[0]
+			}
+		}
+	}
+	return user, nil
+}
+
+// requireAdminUser returns an error unless the RPC was made by an admin
+// user. It guards the RPCs which change the state of the cluster or expose
+// cluster-wide information, as well as the status RPCs exposing the node's
+// logs and goroutine stacks (see adminOnlyStatusPaths).
+func requireAdminUser(ctx context.Context, op string) error {
+	user, err := getUser(ctx)
+	if err != nil {
+		return err
+	}
+	if !isAdminUser(user) {
+		return grpc.Errorf(codes.PermissionDenied, "user %s is not allowed to %s", user, op)
+	}
+	return nil
 }
 
 // serverError logs the provided error and returns an error that should be returned by
@@ -183,7 +220,11 @@ func (s *adminServer) NewSessionForRPC(ctx context.Context, args sql.SessionArgs
 func (s *adminServer) Databases(
 	ctx context.Context, req *serverpb.DatabasesRequest,
 ) (*serverpb.DatabasesResponse, error) {
-	args := sql.SessionArgs{User: s.getUser(req)}
+	user, err := getUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args := sql.SessionArgs{User: user}
 	session := s.NewSessionForRPC(ctx, args)
 	defer session.Finish(s.server.sqlExecutor)
 	r := s.server.sqlExecutor.ExecuteStatements(session, "SHOW DATABASES;", nil)
@@ -210,7 +251,11 @@ func (s *adminServer) Databases(
 func (s *adminServer) DatabaseDetails(
 	ctx context.Context, req *serverpb.DatabaseDetailsRequest,
 ) (*serverpb.DatabaseDetailsResponse, error) {
-	args := sql.SessionArgs{User: s.getUser(req)}
+	user, err := getUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args := sql.SessionArgs{User: user}
 	session := s.NewSessionForRPC(ctx, args)
 	defer session.Finish(s.server.sqlExecutor)
 
@@ -305,7 +350,11 @@ func (s *adminServer) DatabaseDetails(
 func (s *adminServer) TableDetails(
 	ctx context.Context, req *serverpb.TableDetailsRequest,
 ) (*serverpb.TableDetailsResponse, error) {
-	args := sql.SessionArgs{User: s.getUser(req)}
+	user, err := getUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args := sql.SessionArgs{User: user}
 	session := s.NewSessionForRPC(ctx, args)
 	defer session.Finish(s.server.sqlExecutor)
 
@@ -453,7 +502,7 @@ func (s *adminServer) TableDetails(
 			if err := s.server.db.Txn(ctx, func(txn *client.Txn) error {
 				var err error
 				tableSpan, err = iexecutor.GetTableSpan(
-					s.getUser(req), txn, req.Database, req.Table,
+					user, txn, req.Database, req.Table,
 				)
 				return err
 			}); err != nil {
@@ -476,15 +525,19 @@ func (s *adminServer) TableDetails(
 			resp.RangeCount = rangeCount
 		}
 
-		// Query the descriptor ID and zone configuration for this table.
+		// Query the descriptor ID and zone configuration for this table. The
+		// queries above have checked that the user has access to the table,
+		// but not necessarily to system.namespace and system.zones.
 		{
-			path, err := s.queryDescriptorIDPath(session, []string{req.Database, req.Table})
+			rootSession := s.NewSessionForRPC(ctx, sql.SessionArgs{User: security.RootUser})
+			defer rootSession.Finish(s.server.sqlExecutor)
+			path, err := s.queryDescriptorIDPath(rootSession, []string{req.Database, req.Table})
 			if err != nil {
 				return nil, s.serverError(err)
 			}
 			resp.DescriptorID = int64(path[2])
 
-			id, zone, zoneExists, err := s.queryZonePath(session, path)
+			id, zone, zoneExists, err := s.queryZonePath(rootSession, path)
 			if err != nil {
 				return nil, s.serverError(err)
 			}
@@ -513,12 +566,17 @@ func (s *adminServer) TableDetails(
 func (s *adminServer) TableStats(
 	ctx context.Context, req *serverpb.TableStatsRequest,
 ) (*serverpb.TableStatsResponse, error) {
+	user, err := getUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get table span.
 	var tableSpan roachpb.Span
 	iexecutor := sql.InternalExecutor{LeaseManager: s.server.leaseMgr}
 	if err := s.server.db.Txn(ctx, func(txn *client.Txn) error {
 		var err error
-		tableSpan, err = iexecutor.GetTableSpan(s.getUser(req), txn, req.Database, req.Table)
+		tableSpan, err = iexecutor.GetTableSpan(user, txn, req.Database, req.Table)
 		return err
 	}); err != nil {
 		return nil, s.serverError(err)
@@ -640,10 +698,13 @@ func (s *adminServer) TableStats(
 func (s *adminServer) Users(
 	ctx context.Context, req *serverpb.UsersRequest,
 ) (*serverpb.UsersResponse, error) {
-	args := sql.SessionArgs{User: s.getUser(req)}
+	if err := requireAdminUser(ctx, "list the users"); err != nil {
+		return nil, err
+	}
+	args := sql.SessionArgs{User: security.RootUser}
 	session := s.NewSessionForRPC(ctx, args)
 	defer session.Finish(s.server.sqlExecutor)
-	query := "SELECT username FROM system.users"
+	query := `SELECT username FROM system.users WHERE NOT "isRole"`
 	r := s.server.sqlExecutor.ExecuteStatements(session, query, nil)
 	defer r.Close()
 	if err := s.checkQueryResults(r.ResultList, 1); err != nil {
@@ -666,7 +727,10 @@ func (s *adminServer) Users(
 func (s *adminServer) Events(
 	ctx context.Context, req *serverpb.EventsRequest,
 ) (*serverpb.EventsResponse, error) {
-	args := sql.SessionArgs{User: s.getUser(req)}
+	if err := requireAdminUser(ctx, "read the event log"); err != nil {
+		return nil, err
+	}
+	args := sql.SessionArgs{User: security.RootUser}
 	session := s.NewSessionForRPC(ctx, args)
 	defer session.Finish(s.server.sqlExecutor)
 
@@ -727,7 +791,7 @@ func (s *adminServer) Events(
 // getUIData returns the values and timestamps for the given UI keys. Keys
 // that are not found will not be returned.
 func (s *adminServer) getUIData(
-	session *sql.Session, user string, keys []string,
+	session *sql.Session, keys []string,
 ) (*serverpb.GetUIDataResponse, error) {
 	if len(keys) == 0 {
 		return &serverpb.GetUIDataResponse{}, nil
@@ -786,7 +850,10 @@ func (s *adminServer) SetUIData(
 		return nil, grpc.Errorf(codes.InvalidArgument, "KeyValues cannot be empty")
 	}
 
-	args := sql.SessionArgs{User: s.getUser(req)}
+	if err := requireAdminUser(ctx, "change the UI data"); err != nil {
+		return nil, err
+	}
+	args := sql.SessionArgs{User: security.RootUser}
 	session := s.NewSessionForRPC(ctx, args)
 	defer session.Finish(s.server.sqlExecutor)
 
@@ -800,7 +867,7 @@ func (s *adminServer) SetUIData(
 		}
 
 		// See if the key already exists.
-		resp, err := s.getUIData(session, s.getUser(req), []string{key})
+		resp, err := s.getUIData(session, []string{key})
 		if err != nil {
 			return nil, s.serverError(err)
 		}
@@ -848,7 +915,9 @@ func (s *adminServer) SetUIData(
 func (s *adminServer) GetUIData(
 	ctx context.Context, req *serverpb.GetUIDataRequest,
 ) (*serverpb.GetUIDataResponse, error) {
-	args := sql.SessionArgs{User: s.getUser(req)}
+	// The UI data is shared by all the users of the admin UI, but only root
+	// has access to system.ui.
+	args := sql.SessionArgs{User: security.RootUser}
 	session := s.NewSessionForRPC(ctx, args)
 	defer session.Finish(s.server.sqlExecutor)
 
@@ -856,7 +925,7 @@ func (s *adminServer) GetUIData(
 		return nil, grpc.Errorf(codes.InvalidArgument, "keys cannot be empty")
 	}
 
-	resp, err := s.getUIData(session, s.getUser(req), req.Keys)
+	resp, err := s.getUIData(session, req.Keys)
 	if err != nil {
 		return nil, s.serverError(err)
 	}
@@ -882,6 +951,9 @@ func (s *adminServer) Health(
 }
 
 func (s *adminServer) Drain(req *serverpb.DrainRequest, stream serverpb.Admin_DrainServer) error {
+	if err := requireAdminUser(stream.Context(), "drain the node"); err != nil {
+		return err
+	}
 	on := make([]serverpb.DrainMode, len(req.On))
 	for i := range req.On {
 		on[i] = serverpb.DrainMode(req.On[i])
@@ -925,6 +997,81 @@ func (s *adminServer) Drain(req *serverpb.DrainRequest, stream serverpb.Admin_Dr
 	}
 }
 
+// DecommissionStatus returns the decommissioning status of the specified
+// nodes, or of all nodes known to node liveness if none are specified.
+func (s *adminServer) DecommissionStatus(
+	ctx context.Context, req *serverpb.DecommissionStatusRequest,
+) (*serverpb.DecommissionStatusResponse, error) {
+	if err := requireAdminUser(ctx, "read the decommissioning status"); err != nil {
+		return nil, err
+	}
+	nodeIDs := req.NodeIDs
+	if len(nodeIDs) == 0 {
+		for _, liveness := range s.server.nodeLiveness.GetLivenesses() {
+			nodeIDs = append(nodeIDs, liveness.NodeID)
+		}
+		sort.Sort(roachpb.NodeIDSlice(nodeIDs))
+	}
+
+	// Count the replicas on each node by scanning over all meta2 range
+	// descriptors.
+	replicaCounts := make(map[roachpb.NodeID]int64)
+	rangeDescKVs, err := s.server.db.Scan(ctx, keys.Meta2Prefix, keys.MetaMax, 0)
+	if err != nil {
+		return nil, s.serverError(err)
+	}
+	for _, kv := range rangeDescKVs {
+		var rng roachpb.RangeDescriptor
+		if err := kv.Value.GetProto(&rng); err != nil {
+			return nil, s.serverError(err)
+		}
+		for _, repl := range rng.Replicas {
+			replicaCounts[repl.NodeID]++
+		}
+	}
+
+	var res serverpb.DecommissionStatusResponse
+	for _, nodeID := range nodeIDs {
+		liveness, err := s.server.nodeLiveness.GetLiveness(nodeID)
+		if err != nil {
+			return nil, grpc.Errorf(codes.NotFound, "node %d: %s", nodeID, err)
+		}
+		isLive, err := s.server.nodeLiveness.IsLive(nodeID)
+		if err != nil {
+			return nil, s.serverError(err)
+		}
+		res.Status = append(res.Status, serverpb.DecommissionStatusResponse_Status{
+			NodeID:          nodeID,
+			IsLive:          isLive,
+			ReplicaCount:    replicaCounts[nodeID],
+			Decommissioning: liveness.Decommissioning,
+		})
+	}
+	return &res, nil
+}
+
+// Decommission sets the decommissioning flag on the specified nodes and
+// returns their decommissioning status.
+func (s *adminServer) Decommission(
+	ctx context.Context, req *serverpb.DecommissionRequest,
+) (*serverpb.DecommissionStatusResponse, error) {
+	if err := requireAdminUser(ctx, "decommission nodes"); err != nil {
+		return nil, err
+	}
+	if len(req.NodeIDs) == 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument, "no node ID specified")
+	}
+	for _, nodeID := range req.NodeIDs {
+		if err := s.server.nodeLiveness.SetDecommissioning(ctx, nodeID, req.Decommissioning); err != nil {
+			if err == storage.ErrNoLivenessRecord {
+				return nil, grpc.Errorf(codes.NotFound, "node %d: %s", nodeID, err)
+			}
+			return nil, s.serverError(err)
+		}
+	}
+	return s.DecommissionStatus(ctx, &serverpb.DecommissionStatusRequest{NodeIDs: req.NodeIDs})
+}
+
 // waitForStoreFrozen polls the given stores until they all report having no
 // unfrozen Replicas (or an error or timeout occurs).
 func (s *adminServer) waitForStoreFrozen(
@@ -1073,6 +1220,9 @@ func (s *adminServer) waitForStoreFrozen(
 func (s *adminServer) ClusterFreeze(
 	req *serverpb.ClusterFreezeRequest, stream serverpb.Admin_ClusterFreezeServer,
 ) error {
+	if err := requireAdminUser(stream.Context(), "freeze the cluster"); err != nil {
+		return err
+	}
 	var totalAffected int64
 	stores := make(map[roachpb.StoreID]roachpb.NodeID)
 	process := func(from, to roachpb.Key) (roachpb.Key, error) {
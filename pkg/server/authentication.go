@@ -0,0 +1,415 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/httputil"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+const (
+	// authPrefix is the prefix of the login and logout endpoints of the admin
+	// UI.
+	authPrefix = "/_auth/v1/"
+	loginPath  = authPrefix + "login"
+	logoutPath = authPrefix + "logout"
+
+	// sessionCookieName is the name of the cookie which identifies a web
+	// session. Its value is the session ID and the session secret, separated
+	// by a colon.
+	sessionCookieName = "session"
+
+	// sessionSecretSize is the number of random bytes in a session secret.
+	sessionSecretSize = 16
+
+	// webUserMetadataKey is the gRPC metadata key under which the gRPC
+	// gateway passes the user authenticated by the authenticationMux on to
+	// the RPCs. The gateway turns the HTTP headers prefixed with
+	// "Grpc-Metadata-" into metadata.
+	webUserMetadataKey = "webuser"
+	webUserHeader      = "Grpc-Metadata-" + webUserMetadataKey
+)
+
+// webSessionTimeout is the lifetime of a newly created web session.
+var webSessionTimeout = settings.RegisterPositiveDurationSetting(
+	"server.web_session_timeout",
+	"the duration that a newly created web session will be valid",
+	7*24*time.Hour,
+)
+
+var errUnauthenticated = errors.New("the request is not authenticated")
+
+// loginRequest is the JSON body expected by the login endpoint.
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// loginResponse is the JSON body returned by a successful login.
+type loginResponse struct {
+	Username string `json:"username"`
+}
+
+// authenticationServer serves the login and logout endpoints and checks the
+// credentials of the requests to the other HTTP endpoints.
+//
+// A request is authenticated either by a client certificate, as for the
+// other interfaces of a node, or by the cookie of a web session created by a
+// login with the password of a user in system.users. Web sessions are stored
+// in system.web_sessions, which holds a hash of the session secret rather
+// than the secret itself.
+type authenticationServer struct {
+	server *Server
+}
+
+func newAuthenticationServer(s *Server) *authenticationServer {
+	return &authenticationServer{server: s}
+}
+
+// isAdminUser returns whether the given user has access to the endpoints
+// restricted to administrators, which expose logs, stacks and debugging
+// tools.
+func isAdminUser(username string) bool {
+	return username == security.RootUser || username == security.NodeUser
+}
+
+// handleLogin checks the username and password of a POST request against
+// system.users and, when they match, creates a web session and sets its
+// cookie.
+func (s *authenticationServer) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "login requires a POST request", http.StatusMethodNotAllowed)
+		return
+	}
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "malformed login request", http.StatusBadRequest)
+		return
+	}
+	ctx := s.server.AnnotateCtx(context.Background())
+	username := parser.Name(req.Username).Normalize()
+
+	if err := s.verifyPassword(ctx, username, req.Password); err != nil {
+		log.Infof(ctx, "web login as %s failed: %s", username, err)
+		// Don't reveal whether the user exists or the password is wrong.
+		http.Error(w, "invalid username or password", http.StatusUnauthorized)
+		return
+	}
+
+	cookie, err := s.newSession(ctx, username)
+	if err != nil {
+		log.Errorf(ctx, "unable to create web session for %s: %s", username, err)
+		http.Error(w, "unable to create session", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, cookie)
+	writeJSONResponse(w, loginResponse{Username: username})
+}
+
+// handleLogout revokes the web session of the request and clears its cookie.
+// The session is only revoked if the cookie holds its secret, so that the
+// sessions of other users can't be revoked by guessing their IDs.
+func (s *authenticationServer) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "logout requires a POST request", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := s.server.AnnotateCtx(context.Background())
+	if c, err := r.Cookie(sessionCookieName); err == nil {
+		if id, secret, err := decodeSessionCookie(c.Value); err == nil {
+			if err := s.revokeSession(ctx, id, secret); err != nil {
+				log.Errorf(ctx, "unable to revoke web session %d: %s", id, err)
+				http.Error(w, "unable to revoke session", http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   !s.server.cfg.Insecure,
+	})
+	writeJSONResponse(w, struct{}{})
+}
+
+// verifyPassword checks the password of the given user against its hash in
+// system.users. This counts as cleartext password authentication, which the
+// user must allow.
+func (s *authenticationServer) verifyPassword(
+	ctx context.Context, username, password string,
+) error {
+	creds, err := sql.GetUserCredentials(
+		ctx, s.server.sqlExecutor, &s.server.adminMemMetrics, username,
+	)
+	if err != nil {
+		return err
+	}
+	hook := security.UserAuthPasswordHook(
+		s.server.cfg.Insecure, security.CleartextPassword(password), creds,
+	)
+	return hook(username, true /* public */)
+}
+
+// newSession creates a web session for the given user in system.web_sessions
+// and returns the cookie which identifies it.
+func (s *authenticationServer) newSession(
+	ctx context.Context, username string,
+) (*http.Cookie, error) {
+	secret := make([]byte, sessionSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	hashedSecret := sha256.Sum256(secret)
+	expiresAt := timeutil.Now().Add(webSessionTimeout.Get())
+
+	const query = `INSERT INTO system.web_sessions (hashedSecret, username, expiresAt) ` +
+		`VALUES ($1, $2, $3) RETURNING id`
+	params := parser.NewPlaceholderInfo()
+	params.SetValue(`1`, parser.NewDBytes(parser.DBytes(hashedSecret[:])))
+	params.SetValue(`2`, parser.NewDString(username))
+	params.SetValue(`3`, parser.MakeDTimestamp(expiresAt, time.Microsecond))
+
+	var id int64
+	if err := s.queryRow(ctx, query, params, func(scanner resultScanner, row parser.DTuple) error {
+		return scanner.ScanIndex(row, 0, &id)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    encodeSessionCookie(id, secret),
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   !s.server.cfg.Insecure,
+	}, nil
+}
+
+// revokeSession marks the given web session as revoked, provided that it has
+// the given secret. A session with another secret is left untouched.
+func (s *authenticationServer) revokeSession(
+	ctx context.Context, id int64, secret []byte,
+) error {
+	hashedSecret := sha256.Sum256(secret)
+	const query = `UPDATE system.web_sessions SET revokedAt = now() ` +
+		`WHERE id = $1 AND hashedSecret = $2 AND revokedAt IS NULL`
+	params := parser.NewPlaceholderInfo()
+	params.SetValue(`1`, parser.NewDInt(parser.DInt(id)))
+	params.SetValue(`2`, parser.NewDBytes(parser.DBytes(hashedSecret[:])))
+	return s.queryRow(ctx, query, params, nil)
+}
+
+// verifySession returns the user of the web session identified by the given
+// cookie value, provided that the session exists, has the given secret and
+// has neither expired nor been revoked.
+func (s *authenticationServer) verifySession(
+	ctx context.Context, cookieValue string,
+) (string, error) {
+	id, secret, err := decodeSessionCookie(cookieValue)
+	if err != nil {
+		return "", err
+	}
+
+	const query = `SELECT hashedSecret, username, expiresAt, revokedAt ` +
+		`FROM system.web_sessions WHERE id = $1`
+	params := parser.NewPlaceholderInfo()
+	params.SetValue(`1`, parser.NewDInt(parser.DInt(id)))
+
+	var found bool
+	var username string
+	var hashedSecret []byte
+	var expiresAt time.Time
+	var revoked bool
+	if err := s.queryRow(ctx, query, params, func(scanner resultScanner, row parser.DTuple) error {
+		found = true
+		if err := scanner.ScanIndex(row, 0, &hashedSecret); err != nil {
+			return err
+		}
+		if err := scanner.ScanIndex(row, 1, &username); err != nil {
+			return err
+		}
+		if err := scanner.ScanIndex(row, 2, &expiresAt); err != nil {
+			return err
+		}
+		revoked = row[3] != parser.DNull
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	if !found {
+		return "", errors.Errorf("web session %d does not exist", id)
+	}
+	actual := sha256.Sum256(secret)
+	if subtle.ConstantTimeCompare(actual[:], hashedSecret) != 1 {
+		return "", errors.Errorf("invalid secret for web session %d", id)
+	}
+	if revoked {
+		return "", errors.Errorf("web session %d has been revoked", id)
+	}
+	if !timeutil.Now().Before(expiresAt) {
+		return "", errors.Errorf("web session %d has expired", id)
+	}
+	return username, nil
+}
+
+// queryRow runs the given statement as root and calls fn with the first row
+// of its results, if there is one.
+func (s *authenticationServer) queryRow(
+	ctx context.Context,
+	query string,
+	params *parser.PlaceholderInfo,
+	fn func(scanner resultScanner, row parser.DTuple) error,
+) error {
+	session := s.server.admin.NewSessionForRPC(ctx, sql.SessionArgs{User: security.RootUser})
+	defer session.Finish(s.server.sqlExecutor)
+	r := s.server.sqlExecutor.ExecuteStatements(session, query, params)
+	defer r.Close()
+	if err := s.server.admin.checkQueryResults(r.ResultList, 1); err != nil {
+		return err
+	}
+	result := r.ResultList[0]
+	if fn == nil || result.Rows == nil || result.Rows.Len() == 0 {
+		return nil
+	}
+	return fn(makeResultScanner(result.Columns), result.Rows.At(0))
+}
+
+// getRequestUser returns the user on behalf of whom the given request is
+// made. In insecure mode, all requests are made by root.
+func (s *authenticationServer) getRequestUser(r *http.Request) (string, error) {
+	if s.server.cfg.Insecure {
+		return security.RootUser, nil
+	}
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		username, err := security.GetCertificateUser(r.TLS)
+		if err != nil {
+			return "", err
+		}
+		return parser.Name(username).Normalize(), nil
+	}
+	c, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return "", errUnauthenticated
+	}
+	return s.verifySession(s.server.AnnotateCtx(context.Background()), c.Value)
+}
+
+// authenticationMux is an http.Handler which passes the requests made by
+// authenticated users on to an inner handler.
+type authenticationMux struct {
+	server *authenticationServer
+	inner  http.Handler
+	// adminOnly restricts the inner handler to admin users.
+	adminOnly bool
+}
+
+func newAuthenticationMux(s *authenticationServer, inner http.Handler) *authenticationMux {
+	return &authenticationMux{server: s, inner: inner}
+}
+
+func newAdminAuthenticationMux(s *authenticationServer, inner http.Handler) *authenticationMux {
+	return &authenticationMux{server: s, inner: inner, adminOnly: true}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (am *authenticationMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Header.Del(webUserHeader)
+	username, err := am.server.getRequestUser(r)
+	if err != nil {
+		if log.V(1) {
+			log.Infof(am.server.server.AnnotateCtx(context.Background()),
+				"unauthenticated request to %s: %s", r.URL.Path, err)
+		}
+		http.Error(w, errUnauthenticated.Error(), http.StatusUnauthorized)
+		return
+	}
+	if am.adminOnly && !isAdminUser(username) {
+		http.Error(w, "user "+username+" is not allowed to access "+r.URL.Path,
+			http.StatusForbidden)
+		return
+	}
+	r.Header.Set(webUserHeader, username)
+	am.inner.ServeHTTP(w, r)
+}
+
+// unauthenticatedHandler wraps the handlers of the endpoints which are open
+// to unauthenticated requests, making sure that they can't pass a user on to
+// the RPCs they call.
+func unauthenticatedHandler(inner http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del(webUserHeader)
+		inner.ServeHTTP(w, r)
+	})
+}
+
+// encodeSessionCookie returns the value of the cookie identifying the given
+// web session.
+func encodeSessionCookie(id int64, secret []byte) string {
+	return strconv.FormatInt(id, 10) + ":" + base64.RawURLEncoding.EncodeToString(secret)
+}
+
+// decodeSessionCookie is the inverse of encodeSessionCookie.
+func decodeSessionCookie(value string) (int64, []byte, error) {
+	i := strings.IndexByte(value, ':')
+	if i < 0 {
+		return 0, nil, errors.New("malformed session cookie")
+	}
+	id, err := strconv.ParseInt(value[:i], 10, 64)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "malformed session cookie")
+	}
+	secret, err := base64.RawURLEncoding.DecodeString(value[i+1:])
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "malformed session cookie")
+	}
+	return id, secret, nil
+}
+
+// writeJSONResponse writes the given value as the JSON body of a successful
+// response.
+func writeJSONResponse(w http.ResponseWriter, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(httputil.ContentTypeHeader, httputil.JSONContentType)
+	if _, err := w.Write(body); err != nil {
+		log.Error(context.TODO(), err)
+	}
+}
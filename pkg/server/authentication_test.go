@@ -22,11 +22,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
+	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/ts"
@@ -117,32 +120,61 @@ func TestSSLEnforcement(t *testing.T) {
 		{"GET", "/index.html", nil, noCertsContext, true, http.StatusOK},
 		{"GET", "/index.html", nil, insecureContext, true, http.StatusPermanentRedirect},
 
-		// /_admin/: server.adminServer: no auth.
+		// /_admin/v1/health: server.adminServer: no auth.
 		{"GET", adminPrefix + "health", nil, rootCertsContext, true, http.StatusOK},
 		{"GET", adminPrefix + "health", nil, nodeCertsContext, true, http.StatusOK},
 		{"GET", adminPrefix + "health", nil, testCertsContext, true, http.StatusOK},
 		{"GET", adminPrefix + "health", nil, noCertsContext, true, http.StatusOK},
 		{"GET", adminPrefix + "health", nil, insecureContext, true, http.StatusPermanentRedirect},
 
-		// /debug/: server.adminServer: no auth.
+		// /_admin/: server.adminServer: any authenticated user.
+		{"GET", adminPrefix + "databases", nil, rootCertsContext, true, http.StatusOK},
+		{"GET", adminPrefix + "databases", nil, nodeCertsContext, true, http.StatusOK},
+		{"GET", adminPrefix + "databases", nil, testCertsContext, true, http.StatusOK},
+		{"GET", adminPrefix + "databases", nil, noCertsContext, true, http.StatusUnauthorized},
+		{"GET", adminPrefix + "databases", nil, insecureContext, true, http.StatusPermanentRedirect},
+
+		// /_admin/v1/events and /_admin/v1/decommission: server.adminServer:
+		// cluster-wide, admin users only.
+		{"GET", adminPrefix + "events", nil, rootCertsContext, true, http.StatusOK},
+		{"GET", adminPrefix + "events", nil, nodeCertsContext, true, http.StatusOK},
+		{"GET", adminPrefix + "events", nil, testCertsContext, true, http.StatusForbidden},
+		{"GET", adminPrefix + "events", nil, noCertsContext, true, http.StatusUnauthorized},
+		{"GET", adminPrefix + "decommission", nil, rootCertsContext, true, http.StatusOK},
+		{"GET", adminPrefix + "decommission", nil, testCertsContext, true, http.StatusForbidden},
+		{"POST", adminPrefix + "decommission", &serverpb.DecommissionRequest{NodeIDs: []roachpb.NodeID{1}},
+			testCertsContext, true, http.StatusForbidden},
+
+		// /debug/: server.adminServer: admin users only.
 		{"GET", debugEndpoint + "vars", nil, rootCertsContext, true, http.StatusOK},
 		{"GET", debugEndpoint + "vars", nil, nodeCertsContext, true, http.StatusOK},
-		{"GET", debugEndpoint + "vars", nil, testCertsContext, true, http.StatusOK},
-		{"GET", debugEndpoint + "vars", nil, noCertsContext, true, http.StatusOK},
+		{"GET", debugEndpoint + "vars", nil, testCertsContext, true, http.StatusForbidden},
+		{"GET", debugEndpoint + "vars", nil, noCertsContext, true, http.StatusUnauthorized},
 		{"GET", debugEndpoint + "vars", nil, insecureContext, true, http.StatusPermanentRedirect},
 
-		// /_status/nodes: server.statusServer: no auth.
+		// /_status/nodes: server.statusServer: any authenticated user.
 		{"GET", statusPrefix + "nodes", nil, rootCertsContext, true, http.StatusOK},
 		{"GET", statusPrefix + "nodes", nil, nodeCertsContext, true, http.StatusOK},
 		{"GET", statusPrefix + "nodes", nil, testCertsContext, true, http.StatusOK},
-		{"GET", statusPrefix + "nodes", nil, noCertsContext, true, http.StatusOK},
+		{"GET", statusPrefix + "nodes", nil, noCertsContext, true, http.StatusUnauthorized},
 		{"GET", statusPrefix + "nodes", nil, insecureContext, true, http.StatusPermanentRedirect},
 
-		// /ts/: ts.Server: no auth.
+		// /_status/logfiles/: server.statusServer: admin users only.
+		{"GET", statusPrefix + "logfiles/local", nil, rootCertsContext, true, http.StatusOK},
+		{"GET", statusPrefix + "logfiles/local", nil, nodeCertsContext, true, http.StatusOK},
+		{"GET", statusPrefix + "logfiles/local", nil, testCertsContext, true, http.StatusForbidden},
+		{"GET", statusPrefix + "logfiles/local", nil, noCertsContext, true, http.StatusUnauthorized},
+		{"GET", statusPrefix + "logfiles/local", nil, insecureContext, true, http.StatusPermanentRedirect},
+
+		// /_status/vars: server.statusServer: no auth.
+		{"GET", statusVars, nil, noCertsContext, true, http.StatusOK},
+		{"GET", statusVars, nil, insecureContext, true, http.StatusPermanentRedirect},
+
+		// /ts/: ts.Server: any authenticated user.
 		{"GET", ts.URLPrefix, nil, rootCertsContext, true, http.StatusNotFound},
 		{"GET", ts.URLPrefix, nil, nodeCertsContext, true, http.StatusNotFound},
 		{"GET", ts.URLPrefix, nil, testCertsContext, true, http.StatusNotFound},
-		{"GET", ts.URLPrefix, nil, noCertsContext, true, http.StatusNotFound},
+		{"GET", ts.URLPrefix, nil, noCertsContext, true, http.StatusUnauthorized},
 		{"GET", ts.URLPrefix, nil, insecureContext, true, http.StatusPermanentRedirect},
 	}
 
@@ -168,3 +200,117 @@ func TestSSLEnforcement(t *testing.T) {
 		}
 	}
 }
+
+// Verify that web sessions created by a login authenticate requests until
+// they are revoked by a logout.
+func TestWebSessionLogin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop()
+
+	if _, err := db.Exec(`CREATE USER foo WITH PASSWORD 'bar'`); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := insecureCtx{}.GetHTTPClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Jar, err = cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	urlPrefix := "https://" + s.(*TestServer).Cfg.HTTPAddr
+
+	post := func(path, body string) *http.Response {
+		resp, err := client.Post(urlPrefix+path, httputil.JSONContentType, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	expectCode := func(req *http.Request, expected int) {
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Errorf("%s: expected status code %d, got %d", req.URL.Path, expected, resp.StatusCode)
+		}
+	}
+	get := func(path string) *http.Request {
+		req, err := http.NewRequest("GET", urlPrefix+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	if resp := post(loginPath, `{"username": "foo", "password": "wrong"}`); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected login with a wrong password to fail, got status code %d", resp.StatusCode)
+	}
+	if resp := post(loginPath, `{"username": "nobody", "password": "bar"}`); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected login as an unknown user to fail, got status code %d", resp.StatusCode)
+	}
+	expectCode(get(statusPrefix+"nodes"), http.StatusUnauthorized)
+
+	resp := post(loginPath, `{"username": "foo", "password": "bar"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected login to succeed, got status code %d", resp.StatusCode)
+	}
+	var cookie *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == sessionCookieName {
+			cookie = c
+		}
+	}
+	if cookie == nil {
+		t.Fatal("expected the login to set a session cookie")
+	}
+
+	expectCode(get(statusPrefix+"nodes"), http.StatusOK)
+	expectCode(get(adminPrefix+"databases"), http.StatusOK)
+	// Non-admin users can't access logs and debugging tools.
+	expectCode(get(statusPrefix+"logfiles/local"), http.StatusForbidden)
+	expectCode(get(debugEndpoint+"vars"), http.StatusForbidden)
+	// The RPCs are made on behalf of the logged in user, who can't claim to
+	// be another one.
+	expectCode(get(adminPrefix+"events"), http.StatusForbidden)
+	req := get(adminPrefix + "events")
+	req.Header.Set(webUserHeader, security.RootUser)
+	expectCode(req, http.StatusForbidden)
+
+	// A cookie with the wrong secret is rejected.
+	id, _, err := decodeSessionCookie(cookie.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Jar = nil
+	req = get(statusPrefix + "nodes")
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: encodeSessionCookie(id, []byte("wrong"))})
+	expectCode(req, http.StatusUnauthorized)
+
+	// A logout with the wrong secret doesn't revoke the session.
+	req, err = http.NewRequest("POST", urlPrefix+logoutPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: encodeSessionCookie(id, []byte("wrong"))})
+	expectCode(req, http.StatusOK)
+	req = get(statusPrefix + "nodes")
+	req.AddCookie(cookie)
+	expectCode(req, http.StatusOK)
+
+	// Once revoked, the session is no longer valid.
+	req, err = http.NewRequest("POST", urlPrefix+logoutPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(cookie)
+	expectCode(req, http.StatusOK)
+	req = get(statusPrefix + "nodes")
+	req.AddCookie(cookie)
+	expectCode(req, http.StatusUnauthorized)
+}
@@ -45,17 +45,13 @@ import (
 
 // Context defaults.
 const (
-	defaultCGroupMemPath            = "/sys/fs/cgroup/memory/memory.limit_in_bytes"
-	defaultMaxOffset                = 250 * time.Millisecond
-	defaultCacheSize                = 512 << 20 // 512 MB
-	defaultSQLMemoryPoolSize        = 512 << 20 // 512 MB
-	defaultScanInterval             = 10 * time.Minute
-	defaultConsistencyCheckInterval = 24 * time.Hour
-	defaultScanMaxIdleTime          = 200 * time.Millisecond
-	defaultMetricsSampleInterval    = 10 * time.Second
-	defaultTimeUntilStoreDead       = 5 * time.Minute
-	defaultStorePath                = "cockroach-data"
-	defaultEventLogEnabled          = true
+	defaultCGroupMemPath         = "/sys/fs/cgroup/memory/memory.limit_in_bytes"
+	defaultMaxOffset             = 250 * time.Millisecond
+	defaultCacheSize             = 512 << 20 // 512 MB
+	defaultSQLMemoryPoolSize     = 512 << 20 // 512 MB
+	defaultMetricsSampleInterval = 10 * time.Second
+	defaultStorePath             = "cockroach-data"
+	defaultEventLogEnabled       = true
 
 	minimumNetworkFileDescriptors     = 256
 	recommendedNetworkFileDescriptors = 5000
@@ -114,8 +110,9 @@ type Config struct {
 	// Environment Variable: COCKROACH_LINEARIZABLE
 	Linearizable bool
 
-	// Maximum clock offset for the cluster.
-	// Environment Variable: COCKROACH_MAX_OFFSET
+	// Maximum clock offset for the cluster. It must be identical on all the
+	// nodes of the cluster and can only be changed by restarting them all, so
+	// it isn't a cluster setting.
 	MaxOffset time.Duration
 
 	// RaftTickInterval is the resolution of the Raft timer.
@@ -127,24 +124,27 @@ type Config struct {
 	RaftElectionTimeoutTicks int
 
 	// MetricsSamplePeriod determines the time between records of
-	// server internal metrics.
+	// server internal metrics. It stays an environment variable rather than
+	// a cluster setting because it sizes the windows of the histograms and
+	// the periods of the sampling loops created when the server starts,
+	// neither of which can be changed while it runs.
 	// Environment Variable: COCKROACH_METRICS_SAMPLE_INTERVAL
 	MetricsSampleInterval time.Duration
 
 	// ScanInterval determines a duration during which each range should be
-	// visited approximately once by the range scanner. Set to 0 to disable.
-	// Environment Variable: COCKROACH_SCAN_INTERVAL
+	// visited approximately once by the range scanner. If zero, the
+	// server.scanner.interval cluster setting is used.
 	ScanInterval time.Duration
 
 	// ScanMaxIdleTime is the maximum time the scanner will be idle between ranges.
 	// If enabled (> 0), the scanner may complete in less than ScanInterval for small
-	// stores.
-	// Environment Variable: COCKROACH_SCAN_MAX_IDLE_TIME
+	// stores. If both it and ScanInterval are zero, the
+	// server.scanner.max_idle_time cluster setting is used.
 	ScanMaxIdleTime time.Duration
 
-	// ConsistencyCheckInterval determines the time between range consistency checks.
-	// Set to 0 to disable.
-	// Environment Variable: COCKROACH_CONSISTENCY_CHECK_INTERVAL
+	// ConsistencyCheckInterval determines the time between range consistency
+	// checks. If zero, the server.consistency_check.interval cluster setting
+	// is used.
 	ConsistencyCheckInterval time.Duration
 
 	// ConsistencyCheckPanicOnFailure causes the node to panic when it detects a
@@ -152,8 +152,8 @@ type Config struct {
 	ConsistencyCheckPanicOnFailure bool
 
 	// TimeUntilStoreDead is the time after which if there is no new gossiped
-	// information about a store, it is considered dead.
-	// Environment Variable: COCKROACH_TIME_UNTIL_STORE_DEAD
+	// information about a store, it is considered dead. If zero, the
+	// server.time_until_store_dead cluster setting is used.
 	TimeUntilStoreDead time.Duration
 
 	// TestingKnobs is used for internal test controls only.
@@ -171,6 +171,10 @@ type Config struct {
 	// actions.
 	EventLogEnabled bool
 
+	// UseGoInMemEngine makes in-memory stores use the pure-Go in-memory
+	// engine instead of RocksDB. It is only meant for tests.
+	UseGoInMemEngine bool
+
 	enginesCreated bool
 }
 
@@ -334,16 +338,12 @@ func SetOpenFileLimitForOneStore() (int, error) {
 // MakeConfig returns a Context with default values.
 func MakeConfig() Config {
 	cfg := Config{
-		Config:                   new(base.Config),
-		MaxOffset:                defaultMaxOffset,
-		CacheSize:                defaultCacheSize,
-		SQLMemoryPoolSize:        defaultSQLMemoryPoolSize,
-		ScanInterval:             defaultScanInterval,
-		ScanMaxIdleTime:          defaultScanMaxIdleTime,
-		ConsistencyCheckInterval: defaultConsistencyCheckInterval,
-		MetricsSampleInterval:    defaultMetricsSampleInterval,
-		TimeUntilStoreDead:       defaultTimeUntilStoreDead,
-		EventLogEnabled:          defaultEventLogEnabled,
+		Config:                new(base.Config),
+		MaxOffset:             defaultMaxOffset,
+		CacheSize:             defaultCacheSize,
+		SQLMemoryPoolSize:     defaultSQLMemoryPoolSize,
+		MetricsSampleInterval: defaultMetricsSampleInterval,
+		EventLogEnabled:       defaultEventLogEnabled,
 		Stores: base.StoreSpecList{
 			Specs: []base.StoreSpec{{Path: defaultStorePath}},
 		},
@@ -357,6 +357,7 @@ type Engines []engine.Engine
 
 // Close closes all the Engines.
 // This method has a pointer receiver so that the following pattern works:
+//
 //	func f() {
 //		engines := Engines(engineSlice)
 //		defer engines.Close()  // make sure the engines are Closed if this
@@ -411,7 +412,11 @@ func (cfg *Config) CreateEngines() (Engines, error) {
 				return Engines{}, errors.Errorf("%f%% of memory is only %s bytes, which is below the minimum requirement of %s",
 					spec.SizePercent, humanizeutil.IBytes(sizeInBytes), humanizeutil.IBytes(base.MinimumStoreSize))
 			}
-			engines = append(engines, engine.NewInMem(spec.Attributes, sizeInBytes))
+			if cfg.UseGoInMemEngine {
+				engines = append(engines, engine.NewGoInMem(spec.Attributes, sizeInBytes))
+			} else {
+				engines = append(engines, engine.NewInMem(spec.Attributes, sizeInBytes))
+			}
 		} else {
 			if spec.SizePercent > 0 {
 				fileSystemUsage := gosigar.FileSystemUsage{}
@@ -425,12 +430,21 @@ func (cfg *Config) CreateEngines() (Engines, error) {
 					spec.SizePercent, spec.Path, humanizeutil.IBytes(sizeInBytes), humanizeutil.IBytes(base.MinimumStoreSize))
 			}
 
-			eng, err := engine.NewRocksDB(
+			var encryption *engine.EncryptionKeys
+			if spec.EncryptionKey != "" || len(spec.OldEncryptionKeys) > 0 {
+				var err error
+				encryption, err = engine.LoadEncryptionKeys(spec.EncryptionKey, spec.OldEncryptionKeys)
+				if err != nil {
+					return Engines{}, err
+				}
+			}
+			eng, err := engine.NewEncryptedRocksDB(
 				spec.Attributes,
 				spec.Path,
 				cache,
 				sizeInBytes,
 				openFileLimitPerStore,
+				encryption,
 			)
 			if err != nil {
 				return Engines{}, err
@@ -476,12 +490,7 @@ func (cfg *Config) readEnvironmentVariables() {
 	// cockroach-linearizable
 	cfg.Linearizable = envutil.EnvOrDefaultBool("COCKROACH_LINEARIZABLE", cfg.Linearizable)
 	cfg.ConsistencyCheckPanicOnFailure = envutil.EnvOrDefaultBool("COCKROACH_CONSISTENCY_CHECK_PANIC_ON_FAILURE", cfg.ConsistencyCheckPanicOnFailure)
-	cfg.MaxOffset = envutil.EnvOrDefaultDuration("COCKROACH_MAX_OFFSET", cfg.MaxOffset)
 	cfg.MetricsSampleInterval = envutil.EnvOrDefaultDuration("COCKROACH_METRICS_SAMPLE_INTERVAL", cfg.MetricsSampleInterval)
-	cfg.ScanInterval = envutil.EnvOrDefaultDuration("COCKROACH_SCAN_INTERVAL", cfg.ScanInterval)
-	cfg.ScanMaxIdleTime = envutil.EnvOrDefaultDuration("COCKROACH_SCAN_MAX_IDLE_TIME", cfg.ScanMaxIdleTime)
-	cfg.TimeUntilStoreDead = envutil.EnvOrDefaultDuration("COCKROACH_TIME_UNTIL_STORE_DEAD", cfg.TimeUntilStoreDead)
-	cfg.ConsistencyCheckInterval = envutil.EnvOrDefaultDuration("COCKROACH_CONSISTENCY_CHECK_INTERVAL", cfg.ConsistencyCheckInterval)
 }
 
 // parseGossipBootstrapResolvers parses list of gossip bootstrap resolvers.
@@ -90,30 +90,12 @@ func TestReadEnvironmentVariables(t *testing.T) {
 		if err := os.Unsetenv("COCKROACH_LINEARIZABLE"); err != nil {
 			t.Fatal(err)
 		}
-		if err := os.Unsetenv("COCKROACH_MAX_OFFSET"); err != nil {
-			t.Fatal(err)
-		}
 		if err := os.Unsetenv("COCKROACH_METRICS_SAMPLE_INTERVAL"); err != nil {
 			t.Fatal(err)
 		}
-		if err := os.Unsetenv("COCKROACH_SCAN_INTERVAL"); err != nil {
-			t.Fatal(err)
-		}
-		if err := os.Unsetenv("COCKROACH_SCAN_MAX_IDLE_TIME"); err != nil {
-			t.Fatal(err)
-		}
-		if err := os.Unsetenv("COCKROACH_CONSISTENCY_CHECK_INTERVAL"); err != nil {
-			t.Fatal(err)
-		}
 		if err := os.Unsetenv("COCKROACH_CONSISTENCY_CHECK_PANIC_ON_FAILURE"); err != nil {
 			t.Fatal(err)
 		}
-		if err := os.Unsetenv("COCKROACH_TIME_UNTIL_STORE_DEAD"); err != nil {
-			t.Fatal(err)
-		}
-		if err := os.Unsetenv("COCKROACH_CONSISTENCY_CHECK_INTERVAL"); err != nil {
-			t.Fatal(err)
-		}
 		if err := os.Unsetenv("COCKROACH_RESERVATIONS_ENABLED"); err != nil {
 			t.Fatal(err)
 		}
@@ -137,38 +119,14 @@ func TestReadEnvironmentVariables(t *testing.T) {
 		t.Fatal(err)
 	}
 	cfgExpected.Linearizable = true
-	if err := os.Setenv("COCKROACH_MAX_OFFSET", "1s"); err != nil {
-		t.Fatal(err)
-	}
-	cfgExpected.MaxOffset = time.Second
 	if err := os.Setenv("COCKROACH_METRICS_SAMPLE_INTERVAL", "1h10m"); err != nil {
 		t.Fatal(err)
 	}
 	cfgExpected.MetricsSampleInterval = time.Hour + time.Minute*10
-	if err := os.Setenv("COCKROACH_SCAN_INTERVAL", "48h"); err != nil {
-		t.Fatal(err)
-	}
-	cfgExpected.ScanInterval = time.Hour * 48
-	if err := os.Setenv("COCKROACH_SCAN_MAX_IDLE_TIME", "100ns"); err != nil {
-		t.Fatal(err)
-	}
-	cfgExpected.ScanMaxIdleTime = time.Nanosecond * 100
-	if err := os.Setenv("COCKROACH_CONSISTENCY_CHECK_INTERVAL", "48h"); err != nil {
-		t.Fatal(err)
-	}
-	cfgExpected.ConsistencyCheckInterval = time.Hour * 48
 	if err := os.Setenv("COCKROACH_CONSISTENCY_CHECK_PANIC_ON_FAILURE", "true"); err != nil {
 		t.Fatal(err)
 	}
 	cfgExpected.ConsistencyCheckPanicOnFailure = true
-	if err := os.Setenv("COCKROACH_TIME_UNTIL_STORE_DEAD", "10ms"); err != nil {
-		t.Fatal(err)
-	}
-	cfgExpected.TimeUntilStoreDead = time.Millisecond * 10
-	if err := os.Setenv("COCKROACH_CONSISTENCY_CHECK_INTERVAL", "10ms"); err != nil {
-		t.Fatal(err)
-	}
-	cfgExpected.ConsistencyCheckInterval = time.Millisecond * 10
 	if err := os.Setenv("COCKROACH_RESERVATIONS_ENABLED", "false"); err != nil {
 		t.Fatal(err)
 	}
@@ -187,30 +145,12 @@ func TestReadEnvironmentVariables(t *testing.T) {
 	if err := os.Setenv("COCKROACH_LINEARIZABLE", "abcd"); err != nil {
 		t.Fatal(err)
 	}
-	if err := os.Setenv("COCKROACH_MAX_OFFSET", "abcd"); err != nil {
-		t.Fatal(err)
-	}
 	if err := os.Setenv("COCKROACH_METRICS_SAMPLE_INTERVAL", "abcd"); err != nil {
 		t.Fatal(err)
 	}
-	if err := os.Setenv("COCKROACH_SCAN_INTERVAL", "abcd"); err != nil {
-		t.Fatal(err)
-	}
-	if err := os.Setenv("COCKROACH_SCAN_MAX_IDLE_TIME", "abcd"); err != nil {
-		t.Fatal(err)
-	}
-	if err := os.Setenv("COCKROACH_CONSISTENCY_CHECK_INTERVAL", "abcd"); err != nil {
-		t.Fatal(err)
-	}
 	if err := os.Setenv("COCKROACH_CONSISTENCY_CHECK_PANIC_ON_FAILURE", "abcd"); err != nil {
 		t.Fatal(err)
 	}
-	if err := os.Setenv("COCKROACH_TIME_UNTIL_STORE_DEAD", "abcd"); err != nil {
-		t.Fatal(err)
-	}
-	if err := os.Setenv("COCKROACH_CONSISTENCY_CHECK_INTERVAL", "abcd"); err != nil {
-		t.Fatal(err)
-	}
 	if err := os.Setenv("COCKROACH_RESERVATIONS_ENABLED", "abcd"); err != nil {
 		t.Fatal(err)
 	}
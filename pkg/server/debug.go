@@ -51,9 +51,9 @@ func init() {
 	// Tweak the authentication logic for the tracing endpoint. By default it's
 	// open for localhost only, but with Docker we want to get there from
 	// anywhere. We maintain the default behavior of only allowing access to
-	// sensitive logs from localhost.
-	//
-	// TODO(mberhault): properly secure this once we require client certs.
+	// sensitive logs from localhost. Access to all the debug endpoints is
+	// restricted to admin users by the authenticationMux they are served
+	// through.
 	origAuthRequest := trace.AuthRequest
 	trace.AuthRequest = func(req *http.Request) (bool, bool) {
 		_, sensitive := origAuthRequest(req)
@@ -868,3 +868,27 @@ func (n *Node) Batch(
 	}
 	return br, nil
 }
+
+// RangeFeed implements the roachpb.InternalServer interface.
+func (n *Node) RangeFeed(
+	args *roachpb.RangeFeedRequest, stream roachpb.Internal_RangeFeedServer,
+) error {
+	var pErr *roachpb.Error
+	if err := n.stopper.RunTaskWithErr(func() error {
+		pErr = n.stores.RangeFeed(args, stream)
+		return nil
+	}); err != nil {
+		pErr = roachpb.NewError(err)
+	}
+	if pErr == nil {
+		return nil
+	}
+	// As with Batch, errors are returned as part of the stream so that
+	// their structure is preserved.
+	if err := stream.Send(&roachpb.RangeFeedEvent{
+		Error: &roachpb.RangeFeedError{Error: *pErr},
+	}); err != nil {
+		return err
+	}
+	return nil
+}
@@ -19,7 +19,6 @@ package server
 
 import (
 	"compress/gzip"
-	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
@@ -42,6 +41,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/migrations"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
@@ -99,6 +99,7 @@ type Server struct {
 	recorder           *status.MetricsRecorder
 	runtime            status.RuntimeStatSampler
 	admin              *adminServer
+	authentication     *authenticationServer
 	status             *statusServer
 	tsDB               *ts.DB
 	tsServer           ts.Server
@@ -106,6 +107,7 @@ type Server struct {
 	stopper            *stop.Stopper
 	sqlExecutor        *sql.Executor
 	leaseMgr           *sql.LeaseManager
+	protectedTS        *sql.ProtectedTimestamps
 	engines            Engines
 	internalMemMetrics sql.MemoryMetrics
 	adminMemMetrics    sql.MemoryMetrics
@@ -200,6 +202,8 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		Clock:           s.clock,
 		RPCContext:      s.rpcContext,
 		RPCRetryOptions: &retryOpts,
+
+		ClosedTimestampInterval: storage.ClosedTimestampInterval.Get,
 	}
 	s.distSender = kv.NewDistSender(distSenderCfg, s.gossip)
 
@@ -243,6 +247,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	s.leaseMgr = sql.NewLeaseManager(&s.nodeIDContainer, *s.db, s.clock, lmKnobs,
 		s.stopper, &s.internalMemMetrics)
 	s.leaseMgr.RefreshLeases(s.stopper, s.db, s.gossip)
+	s.protectedTS = sql.NewProtectedTimestamps(*s.db, s.clock, s.leaseMgr)
 
 	// Set up the DistSQL server
 	distSQLCfg := distsql.ServerConfig{
@@ -267,6 +272,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		LeaseManager:          s.leaseMgr,
 		Clock:                 s.clock,
 		DistSQLSrv:            s.distSQLServer,
+		RangeFeeder:           s.distSender,
 		MetricsSampleInterval: s.cfg.MetricsSampleInterval,
 	}
 	if s.cfg.TestingKnobs.SQLExecutor != nil {
@@ -316,6 +322,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		RangeLeaseActiveDuration:  active,
 		RangeLeaseRenewalDuration: renewal,
 		TimeSeriesDataStore:       s.tsDB,
+		ProtectedTimestamps:       s.protectedTS,
 	}
 	if s.cfg.TestingKnobs.Store != nil {
 		storeCfg.TestingKnobs = *s.cfg.TestingKnobs.Store.(*storage.StoreTestingKnobs)
@@ -327,12 +334,21 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	s.runtime = status.MakeRuntimeStatSampler(s.clock)
 	s.registry.AddMetricStruct(s.runtime)
 
+	if !s.cfg.Insecure {
+		certMgr, err := s.cfg.GetCertificateManager()
+		if err != nil {
+			return nil, err
+		}
+		s.registry.AddMetricStruct(certMgr.Metrics())
+	}
+
 	s.node = NewNode(storeCfg, s.recorder, s.registry, s.stopper, txnMetrics, sql.MakeEventLogger(s.leaseMgr))
 	roachpb.RegisterInternalServer(s.grpc, s.node)
 	storage.RegisterConsistencyServer(s.grpc, s.node.storesServer)
 	storage.RegisterFreezeServer(s.grpc, s.node.storesServer)
 
 	s.admin = newAdminServer(s)
+	s.authentication = newAuthenticationServer(s)
 	s.status = newStatusServer(
 		s.cfg.AmbientCtx, s.db, s.gossip, s.recorder, s.rpcContext, s.node.stores,
 	)
@@ -477,7 +493,13 @@ func (s *Server) Start(ctx context.Context) error {
 			netutil.FatalIfUnexpected(plainRedirectServer.Serve(clearL))
 		})
 
-		httpLn = tls.NewListener(tlsL, tlsConfig)
+		// Handshake with the latest certificates, which may have been
+		// reloaded since the server started.
+		certMgr, err := s.cfg.GetCertificateManager()
+		if err != nil {
+			return err
+		}
+		httpLn = certMgr.NewListener(tlsL)
 	}
 
 	s.stopper.RunWorker(func() {
@@ -539,9 +561,9 @@ func (s *Server) Start(ctx context.Context) error {
 	// Enable the debug endpoints first to provide an earlier window
 	// into what's going on with the node in advance of exporting node
 	// functionality.
-	// TODO(marc): when cookie-based authentication exists,
-	// apply it for all web endpoints.
-	s.mux.HandleFunc(debugEndpoint, http.HandlerFunc(handleDebug))
+	s.mux.Handle(debugEndpoint, newAdminAuthenticationMux(
+		s.authentication, http.HandlerFunc(handleDebug),
+	))
 
 	s.gossip.Start(unresolvedAdvertAddr)
 	log.Event(ctx, "started gossip")
@@ -587,6 +609,9 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}
 
+	// Apply the cluster settings as soon as the system config is gossiped.
+	s.refreshSettings()
+
 	// Now that we have a monotonic HLC wrt previous incarnations of the process,
 	// init all the replicas.
 	err = s.node.start(
@@ -624,6 +649,7 @@ func (s *Server) Start(ctx context.Context) error {
 		testingKnobs = s.cfg.TestingKnobs.SQLSchemaChanger.(*sql.SchemaChangerTestingKnobs)
 	}
 	sql.NewSchemaChangeManager(testingKnobs, *s.db, s.gossip, s.leaseMgr).Start(s.stopper)
+	s.protectedTS.Start(s.stopper)
 
 	s.distSQLServer.Start()
 
@@ -640,6 +666,20 @@ func (s *Server) Start(ctx context.Context) error {
 
 	log.Event(ctx, "accepting connections")
 
+	// Bring the system tables of clusters bootstrapped by older versions up
+	// to date. This needs a quorum of the system ranges, so it must wait for
+	// this node to accept connections from the other nodes.
+	if err := migrations.NewManager(s.db).EnsureMigrations(ctx); err != nil {
+		return err
+	}
+	log.Event(ctx, "ran migrations")
+
+	// Resume the changefeeds this node ran before it restarted, now that
+	// system.changefeeds is known to exist.
+	if err := s.sqlExecutor.ResumeChangeFeeds(ctx); err != nil {
+		log.Warningf(ctx, "unable to resume changefeeds: %s", err)
+	}
+
 	// Initialize grpc-gateway mux and context.
 	jsonpb := &protoutil.JSONPb{
 		EnumsAsInts:  true,
@@ -693,12 +733,23 @@ func (s *Server) Start(ctx context.Context) error {
 		uiFileServer.ServeHTTP(w, r)
 	}))
 
-	// TODO(marc): when cookie-based authentication exists,
-	// apply it for all web endpoints.
-	s.mux.Handle(adminPrefix, gwMux)
-	s.mux.Handle(ts.URLPrefix, gwMux)
-	s.mux.Handle(statusPrefix, gwMux)
-	s.mux.Handle("/health", gwMux)
+	s.mux.HandleFunc(loginPath, s.authentication.handleLogin)
+	s.mux.HandleFunc(logoutPath, s.authentication.handleLogout)
+
+	// All the API endpoints require an authenticated user, and the ones
+	// exposing logs and stacks require an admin user.
+	authenticatedGWMux := newAuthenticationMux(s.authentication, gwMux)
+	adminGWMux := newAdminAuthenticationMux(s.authentication, gwMux)
+	s.mux.Handle(adminPrefix, authenticatedGWMux)
+	s.mux.Handle(ts.URLPrefix, authenticatedGWMux)
+	s.mux.Handle(statusPrefix, authenticatedGWMux)
+	for _, path := range adminOnlyStatusPaths {
+		s.mux.Handle(path, adminGWMux)
+	}
+	// The health checks and the prometheus metrics are left open for load
+	// balancers and monitoring systems.
+	s.mux.Handle(adminPrefix+"health", unauthenticatedHandler(gwMux))
+	s.mux.Handle("/health", unauthenticatedHandler(gwMux))
 	s.mux.Handle(statusVars, http.HandlerFunc(s.status.handleVars))
 	log.Event(ctx, "added http endpoints")
 
@@ -39,6 +39,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -47,6 +48,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/gogo/protobuf/jsonpb"
 )
@@ -76,6 +78,50 @@ func TestHealth(t *testing.T) {
 	}
 }
 
+// TestGoInMemEngine verifies that a server can run on GoInMem engines,
+// including splitting ranges and serving SQL.
+func TestGoInMemEngine(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, sqlDB, kvDB := serverutils.StartServer(t, base.TestServerArgs{UseGoInMemEngine: true})
+	defer s.Stopper().Stop()
+
+	for _, e := range s.(*TestServer).Engines() {
+		if _, ok := e.(*engine.GoInMem); !ok {
+			t.Fatalf("expected a GoInMem engine, got %T", e)
+		}
+	}
+
+	if err := kvDB.AdminSplit(context.TODO(), "m"); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"a", "z"} {
+		if err := kvDB.Put(context.TODO(), k, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rows, err := kvDB.Scan(context.TODO(), "a", "zz", 0); err != nil {
+		t.Fatal(err)
+	} else if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.kv (k INT PRIMARY KEY, v INT);
+INSERT INTO t.kv VALUES (1, 1), (2, 2);
+UPDATE t.kv SET v = v + 1 WHERE k = 2;
+`); err != nil {
+		t.Fatal(err)
+	}
+	var sum int
+	if err := sqlDB.QueryRow(`SELECT SUM(v) FROM t.kv`).Scan(&sum); err != nil {
+		t.Fatal(err)
+	}
+	if sum != 4 {
+		t.Fatalf("expected a sum of 4, got %d", sum)
+	}
+}
+
 // TestServerStartClock tests that a server's clock is not pushed out of thin
 // air. This used to happen - the simple act of starting was causing a server's
 // clock to be pushed because we were introducing bogus future timestamps into
@@ -118,6 +164,38 @@ func TestServerStartClock(t *testing.T) {
 	}
 }
 
+// TestServerRestartMaxOffset verifies that a node restarted with a
+// non-default maximum clock offset uses it, including to wait for HLC
+// monotonicity before serving anything.
+func TestServerRestartMaxOffset(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t, 0)
+	defer cleanup()
+
+	const maxOffset = time.Second
+	params := base.TestServerArgs{
+		StoreSpecs: []base.StoreSpec{{Path: dir}},
+		Knobs: base.TestingKnobs{
+			Store: &storage.StoreTestingKnobs{
+				MaxOffset: maxOffset,
+			},
+		},
+	}
+	s, _, _ := serverutils.StartServer(t, params)
+	s.Stopper().Stop()
+
+	start := timeutil.Now()
+	s, _, _ = serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+	if mo := s.Clock().MaxOffset(); mo != maxOffset {
+		t.Fatalf("expected max offset %s, got %s", maxOffset, mo)
+	}
+	if elapsed := timeutil.Since(start); elapsed < maxOffset {
+		t.Fatalf("restarted server only waited %s for HLC monotonicity", elapsed)
+	}
+}
+
 // TestPlainHTTPServer verifies that we can serve plain http and talk to it.
 // This is controlled by -cert=""
 func TestPlainHTTPServer(t *testing.T) {
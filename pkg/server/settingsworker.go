@@ -0,0 +1,131 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// refreshSettings starts a worker which applies the contents of the
+// system.settings table to the registered cluster settings every time a new
+// system config is gossiped, and has the stores record the settings they
+// only use when they're started.
+func (s *Server) refreshSettings() {
+	ctx := s.AnnotateCtx(context.Background())
+	gossipUpdateC := s.gossip.RegisterSystemConfigChannel()
+	s.stopper.RunWorker(func() {
+		for {
+			select {
+			case <-gossipUpdateC:
+				cfg, _ := s.gossip.GetSystemConfig()
+				applySettings(ctx, cfg)
+				if err := s.node.stores.VisitStores(func(store *storage.Store) error {
+					return store.RecordClusterSettings()
+				}); err != nil {
+					log.Warningf(ctx, "unable to record cluster settings: %s", err)
+				}
+			case <-s.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// applySettings updates the cluster settings from the rows of
+// system.settings found in the given system config. Rows which can't be
+// decoded are logged and skipped, leaving their settings at the default
+// value.
+func applySettings(ctx context.Context, cfg config.SystemConfig) {
+	prefix := roachpb.Key(keys.MakeTablePrefix(keys.SettingsTableID))
+	var a sqlbase.DatumAlloc
+	u := settings.MakeUpdater()
+	defer u.Done()
+	for _, kv := range cfg.Values {
+		if !bytes.HasPrefix(kv.Key, prefix) {
+			continue
+		}
+		name, value, valType, err := decodeSettingKV(&a, kv)
+		if err == nil {
+			err = u.Set(name, value, valType)
+		}
+		if err != nil {
+			log.Warningf(ctx, "unable to apply setting from %s: %s", kv.Key, err)
+		}
+	}
+}
+
+// decodeSettingKV decodes a row of system.settings, whose columns are all
+// stored in a single family.
+func decodeSettingKV(
+	a *sqlbase.DatumAlloc, kv roachpb.KeyValue,
+) (name, value, valType string, err error) {
+	tbl := &sqlbase.SettingsTable
+
+	vals := make([]parser.Datum, 1)
+	_, ok, err := sqlbase.DecodeIndexKey(a, tbl, tbl.PrimaryIndex.ID,
+		[]parser.Type{parser.TypeString}, vals, nil, kv.Key)
+	if err != nil {
+		return "", "", "", err
+	}
+	if !ok {
+		return "", "", "", errors.Errorf("not a key of %s", tbl.Name)
+	}
+	name = string(*vals[0].(*parser.DString))
+
+	tuple, err := kv.Value.GetTuple()
+	if err != nil {
+		return "", "", "", err
+	}
+	var lastColID sqlbase.ColumnID
+	for len(tuple) > 0 {
+		_, _, colIDDiff, _, err := encoding.DecodeValueTag(tuple)
+		if err != nil {
+			return "", "", "", err
+		}
+		colID := lastColID + sqlbase.ColumnID(colIDDiff)
+		lastColID = colID
+		col, err := tbl.FindColumnByID(colID)
+		if err != nil {
+			return "", "", "", err
+		}
+		var d parser.Datum
+		d, tuple, err = sqlbase.DecodeTableValue(a, col.Type.Kind.ToDatumType(), tuple)
+		if err != nil {
+			return "", "", "", err
+		}
+		switch col.Name {
+		case "value":
+			value = string(*d.(*parser.DString))
+		case "valueType":
+			if d != parser.DNull {
+				valType = string(*d.(*parser.DString))
+			}
+		}
+	}
+	return name, value, valType, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+var (
+	testIntSetting      = settings.RegisterIntSetting("server.test.int", "for testing", 1)
+	testStringSetting   = settings.RegisterStringSetting("server.test.string", "for testing", "<default>")
+	testDurationSetting = settings.RegisterDurationSetting("server.test.duration", "for testing", time.Second)
+)
+
+func TestSettingsRefresh(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, rawDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop()
+	db := sqlutils.MakeSQLRunner(t, rawDB)
+
+	changes := make(chan struct{}, 10)
+	testIntSetting.OnChange(func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	})
+
+	db.Exec(`SET CLUSTER SETTING server.test.int = 5`)
+	db.Exec(`SET CLUSTER SETTING server.test.string = 'foo'`)
+	db.Exec(`SET CLUSTER SETTING server.test.duration = '1m'`)
+	util.SucceedsSoon(t, func() error {
+		if v := testIntSetting.Get(); v != 5 {
+			return errors.Errorf("expected 5, got %d", v)
+		}
+		if v := testStringSetting.Get(); v != "foo" {
+			return errors.Errorf("expected foo, got %s", v)
+		}
+		if v := testDurationSetting.Get(); v != time.Minute {
+			return errors.Errorf("expected 1m, got %s", v)
+		}
+		return nil
+	})
+	select {
+	case <-changes:
+	default:
+		t.Fatal("expected the change callback to run")
+	}
+
+	var v string
+	db.QueryRow(`SHOW CLUSTER SETTING server.test.int`).Scan(&v)
+	if v != "5" {
+		t.Errorf("expected 5, got %s", v)
+	}
+
+	// Values of the setting's own type are accepted as well as strings.
+	db.Exec(`SET CLUSTER SETTING server.test.duration = INTERVAL '2h'`)
+	// Resetting a setting removes its row, reverting it to its default.
+	db.Exec(`SET CLUSTER SETTING server.test.int = DEFAULT`)
+	util.SucceedsSoon(t, func() error {
+		if v := testIntSetting.Get(); v != 1 {
+			return errors.Errorf("expected 1, got %d", v)
+		}
+		if v := testDurationSetting.Get(); v != 2*time.Hour {
+			return errors.Errorf("expected 2h, got %s", v)
+		}
+		return nil
+	})
+
+	if _, err := rawDB.Exec(`SET CLUSTER SETTING server.test.missing = 1`); !testutils.IsError(
+		err, `unknown cluster setting "server.test.missing"`,
+	) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := rawDB.Exec(`SET CLUSTER SETTING server.test.int = 'abc'`); !testutils.IsError(
+		err, `invalid syntax`,
+	) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := rawDB.Exec(`SET CLUSTER SETTING server.test.int = true`); !testutils.IsError(
+		err, `does not match the setting type`,
+	) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -64,6 +64,14 @@ const (
 	statusVars = statusPrefix + "vars"
 )
 
+// adminOnlyStatusPaths are the prefixes of the status endpoints which are
+// restricted to admin users, as logs and stacks may contain sensitive data.
+var adminOnlyStatusPaths = []string{
+	statusPrefix + "logfiles/",
+	statusPrefix + "logs/",
+	statusPrefix + "stacks/",
+}
+
 // Pattern for local used when determining the node ID.
 var localRE = regexp.MustCompile(`(?i)local`)
 
@@ -196,6 +204,9 @@ func (s *statusServer) Details(
 func (s *statusServer) LogFilesList(
 	ctx context.Context, req *serverpb.LogFilesListRequest,
 ) (*serverpb.LogFilesListResponse, error) {
+	if err := requireAdminUser(ctx, "list the log files"); err != nil {
+		return nil, err
+	}
 	ctx = s.AnnotateCtx(ctx)
 	nodeID, local, err := s.parseNodeID(req.NodeId)
 	if err != nil {
@@ -220,6 +231,9 @@ func (s *statusServer) LogFilesList(
 func (s *statusServer) LogFile(
 	ctx context.Context, req *serverpb.LogFileRequest,
 ) (*serverpb.LogEntriesResponse, error) {
+	if err := requireAdminUser(ctx, "read the logs"); err != nil {
+		return nil, err
+	}
 	ctx = s.AnnotateCtx(ctx)
 	nodeID, local, err := s.parseNodeID(req.NodeId)
 	if err != nil {
@@ -285,8 +299,11 @@ func parseInt64WithDefault(s string, defaultValue int64) (int64, error) {
 // * "level" query parameter filters the log entries to be those of the
 //   corresponding severity level or worse. Defaults to "info".
 func (s *statusServer) Logs(
-	_ context.Context, req *serverpb.LogsRequest,
+	ctx context.Context, req *serverpb.LogsRequest,
 ) (*serverpb.LogEntriesResponse, error) {
+	if err := requireAdminUser(ctx, "read the logs"); err != nil {
+		return nil, err
+	}
 	log.Flush()
 
 	var sev log.Severity
@@ -346,6 +363,9 @@ func (s *statusServer) Logs(
 func (s *statusServer) Stacks(
 	ctx context.Context, req *serverpb.StacksRequest,
 ) (*serverpb.JSONResponse, error) {
+	if err := requireAdminUser(ctx, "read the stacks"); err != nil {
+		return nil, err
+	}
 	ctx = s.AnnotateCtx(ctx)
 	nodeID, local, err := s.parseNodeID(req.NodeId)
 	if err != nil {
@@ -32,6 +32,8 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/build"
@@ -39,8 +41,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/server/status"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/ts"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -537,3 +541,63 @@ func TestSpanStatsGRPCResponse(t *testing.T) {
 		t.Errorf("expected %d ranges, found %d", e, a)
 	}
 }
+
+// TestStatusLogsAndStacksRequireAdmin verifies that the RPCs exposing the
+// logs and the goroutine stacks are denied to non-admin users, as their
+// HTTP endpoints are (see adminOnlyStatusPaths).
+func TestStatusLogsAndStacksRequireAdmin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ts := startServer(t)
+	defer ts.Stopper().Stop()
+
+	rpcStopper := stop.NewStopper()
+	defer rpcStopper.Stop()
+	for _, tc := range []struct {
+		user    string
+		allowed bool
+	}{
+		{security.RootUser, true},
+		{security.NodeUser, true},
+		{TestUser, false},
+	} {
+		t.Run(tc.user, func(t *testing.T) {
+			var cfg *base.Config
+			if tc.user == security.NodeUser {
+				cfg = testutils.NewNodeTestBaseContext()
+			} else {
+				cfg = testutils.NewTestBaseContext(tc.user)
+			}
+			rpcContext := rpc.NewContext(log.AmbientContext{}, cfg, ts.Clock(), rpcStopper)
+			conn, err := rpcContext.GRPCDial(ts.ServingAddr())
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := serverpb.NewStatusClient(conn)
+			ctx := context.Background()
+
+			for name, call := range map[string]func() error{
+				"LogFilesList": func() error {
+					_, err := client.LogFilesList(ctx, &serverpb.LogFilesListRequest{NodeId: "local"})
+					return err
+				},
+				"LogFile": func() error {
+					_, err := client.LogFile(ctx, &serverpb.LogFileRequest{NodeId: "local", File: "missing"})
+					return err
+				},
+				"Logs": func() error {
+					_, err := client.Logs(ctx, &serverpb.LogsRequest{NodeId: "local"})
+					return err
+				},
+				"Stacks": func() error {
+					_, err := client.Stacks(ctx, &serverpb.StacksRequest{NodeId: "local"})
+					return err
+				},
+			} {
+				err := call()
+				if denied := grpc.Code(err) == codes.PermissionDenied; denied == tc.allowed {
+					t.Errorf("%s: expected allowed=%t, got %v", name, tc.allowed, err)
+				}
+			}
+		})
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/ts"
@@ -123,6 +124,7 @@ func makeTestConfigFromParams(params base.TestServerArgs) Config {
 	if params.DisableEventLog {
 		cfg.EventLogEnabled = false
 	}
+	cfg.UseGoInMemEngine = params.UseGoInMemEngine
 	cfg.JoinList = []string{params.JoinAddr}
 	if cfg.Insecure {
 		// Whenever we can (i.e. in insecure mode), use IsolatedTestAddr
@@ -404,6 +406,11 @@ func (ts *TestServer) GetNode() *Node {
 	return ts.node
 }
 
+// Executor exposes the Server's SQL Executor.
+func (ts *TestServer) Executor() *sql.Executor {
+	return ts.sqlExecutor
+}
+
 // DistSender exposes the Server's DistSender.
 func (ts *TestServer) DistSender() *kv.DistSender {
 	return ts.distSender
@@ -0,0 +1,95 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package settings
+
+import (
+	"strconv"
+	"sync/atomic"
+)
+
+// BoolSetting is a setting of type "b".
+type BoolSetting struct {
+	common
+	defaultValue bool
+	v            int32
+}
+
+var _ Setting = &BoolSetting{}
+
+// RegisterBoolSetting defines a new setting of type bool.
+func RegisterBoolSetting(key, desc string, defaultValue bool) *BoolSetting {
+	s := &BoolSetting{defaultValue: defaultValue}
+	s.setValue(defaultValue)
+	register(key, desc, s, &s.common)
+	return s
+}
+
+// Get returns the current value of the setting.
+func (b *BoolSetting) Get() bool {
+	return atomic.LoadInt32(&b.v) != 0
+}
+
+// Typ implements the Setting interface.
+func (*BoolSetting) Typ() string {
+	return "b"
+}
+
+// String implements the Setting interface.
+func (b *BoolSetting) String() string {
+	return EncodeBool(b.Get())
+}
+
+// Validate implements the Setting interface.
+func (*BoolSetting) Validate(raw string) (string, error) {
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return "", err
+	}
+	return EncodeBool(v), nil
+}
+
+func (b *BoolSetting) set(raw string) error {
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return err
+	}
+	b.setValue(v)
+	return nil
+}
+
+func (b *BoolSetting) setToDefault() {
+	b.setValue(b.defaultValue)
+}
+
+func (b *BoolSetting) setValue(v bool) {
+	var i int32
+	if v {
+		i = 1
+	}
+	if atomic.SwapInt32(&b.v, i) != i {
+		b.changed()
+	}
+}
+
+// TestingSetBool replaces the setting pointed to by s with an unregistered
+// setting holding v, which Updaters leave alone, and returns a function which
+// restores the original setting.
+func TestingSetBool(s **BoolSetting, v bool) func() {
+	saved := *s
+	tmp := &BoolSetting{}
+	tmp.setValue(v)
+	*s = tmp
+	return func() { *s = saved }
+}
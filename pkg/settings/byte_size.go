@@ -0,0 +1,148 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package settings
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
+)
+
+// ByteSizeSetting is a setting of type "z", holding a number of bytes. Its
+// values may be given using any of the suffixes accepted by
+// humanizeutil.ParseBytes, e.g. "64 MiB", but are stored as a plain number
+// of bytes since the human-readable form is rounded.
+type ByteSizeSetting struct {
+	common
+	defaultValue int64
+	validateFn   func(int64) error
+	v            int64
+}
+
+var _ Setting = &ByteSizeSetting{}
+
+// RegisterByteSizeSetting defines a new setting of type byte size.
+func RegisterByteSizeSetting(key, desc string, defaultValue int64) *ByteSizeSetting {
+	s := &ByteSizeSetting{defaultValue: defaultValue}
+	s.setValue(defaultValue)
+	register(key, desc, s, &s.common)
+	return s
+}
+
+// RegisterValidatedByteSizeSetting defines a new setting of type byte size,
+// whose values are checked by validateFn.
+func RegisterValidatedByteSizeSetting(
+	key, desc string, defaultValue int64, validateFn func(int64) error,
+) *ByteSizeSetting {
+	if err := validateFn(defaultValue); err != nil {
+		panic(fmt.Sprintf("invalid default value for %s: %v", key, err))
+	}
+	s := &ByteSizeSetting{defaultValue: defaultValue, validateFn: validateFn}
+	s.setValue(defaultValue)
+	register(key, desc, s, &s.common)
+	return s
+}
+
+// RegisterNonNegativeByteSizeSetting defines a new setting of type byte size
+// which can't be set to a negative value.
+func RegisterNonNegativeByteSizeSetting(key, desc string, defaultValue int64) *ByteSizeSetting {
+	return RegisterValidatedByteSizeSetting(key, desc, defaultValue, func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot be set to a negative value: %d", v)
+		}
+		return nil
+	})
+}
+
+// RegisterPositiveByteSizeSetting defines a new setting of type byte size
+// which can only be set to a positive value.
+func RegisterPositiveByteSizeSetting(key, desc string, defaultValue int64) *ByteSizeSetting {
+	return RegisterValidatedByteSizeSetting(key, desc, defaultValue, func(v int64) error {
+		if v <= 0 {
+			return errors.Errorf("cannot be set to a non-positive value: %d", v)
+		}
+		return nil
+	})
+}
+
+// Get returns the current value of the setting.
+func (b *ByteSizeSetting) Get() int64 {
+	return atomic.LoadInt64(&b.v)
+}
+
+// Typ implements the Setting interface.
+func (*ByteSizeSetting) Typ() string {
+	return "z"
+}
+
+// String implements the Setting interface.
+func (b *ByteSizeSetting) String() string {
+	return humanizeutil.IBytes(b.Get())
+}
+
+// Validate implements the Setting interface.
+func (b *ByteSizeSetting) Validate(raw string) (string, error) {
+	v, err := b.parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return EncodeInt(v), nil
+}
+
+func (b *ByteSizeSetting) parse(raw string) (int64, error) {
+	v, err := humanizeutil.ParseBytes(raw)
+	if err != nil {
+		return 0, err
+	}
+	if b.validateFn != nil {
+		if err := b.validateFn(v); err != nil {
+			return 0, err
+		}
+	}
+	return v, nil
+}
+
+func (b *ByteSizeSetting) set(raw string) error {
+	v, err := b.parse(raw)
+	if err != nil {
+		return err
+	}
+	b.setValue(v)
+	return nil
+}
+
+func (b *ByteSizeSetting) setToDefault() {
+	b.setValue(b.defaultValue)
+}
+
+func (b *ByteSizeSetting) setValue(v int64) {
+	if atomic.SwapInt64(&b.v, v) != v {
+		b.changed()
+	}
+}
+
+// TestingSetByteSize replaces the setting pointed to by s with an unregistered
+// setting holding v, which Updaters leave alone, and returns a function which
+// restores the original setting.
+func TestingSetByteSize(s **ByteSizeSetting, v int64) func() {
+	saved := *s
+	tmp := &ByteSizeSetting{}
+	tmp.setValue(v)
+	*s = tmp
+	return func() { *s = saved }
+}
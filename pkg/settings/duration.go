@@ -0,0 +1,144 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package settings
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DurationSetting is a setting of type "d".
+type DurationSetting struct {
+	common
+	defaultValue time.Duration
+	validateFn   func(time.Duration) error
+	v            int64
+}
+
+var _ Setting = &DurationSetting{}
+
+// RegisterDurationSetting defines a new setting of type duration.
+func RegisterDurationSetting(key, desc string, defaultValue time.Duration) *DurationSetting {
+	s := &DurationSetting{defaultValue: defaultValue}
+	s.setValue(defaultValue)
+	register(key, desc, s, &s.common)
+	return s
+}
+
+// RegisterValidatedDurationSetting defines a new setting of type duration,
+// whose values are checked by validateFn.
+func RegisterValidatedDurationSetting(
+	key, desc string, defaultValue time.Duration, validateFn func(time.Duration) error,
+) *DurationSetting {
+	if err := validateFn(defaultValue); err != nil {
+		panic(fmt.Sprintf("invalid default value for %s: %v", key, err))
+	}
+	s := &DurationSetting{defaultValue: defaultValue, validateFn: validateFn}
+	s.setValue(defaultValue)
+	register(key, desc, s, &s.common)
+	return s
+}
+
+// RegisterNonNegativeDurationSetting defines a new setting of type duration
+// which can't be set to a negative value.
+func RegisterNonNegativeDurationSetting(key, desc string, defaultValue time.Duration) *DurationSetting {
+	return RegisterValidatedDurationSetting(key, desc, defaultValue, func(v time.Duration) error {
+		if v < 0 {
+			return errors.Errorf("cannot be set to a negative value: %s", v)
+		}
+		return nil
+	})
+}
+
+// RegisterPositiveDurationSetting defines a new setting of type duration
+// which can only be set to a positive value.
+func RegisterPositiveDurationSetting(key, desc string, defaultValue time.Duration) *DurationSetting {
+	return RegisterValidatedDurationSetting(key, desc, defaultValue, func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("cannot be set to a non-positive value: %s", v)
+		}
+		return nil
+	})
+}
+
+// Get returns the current value of the setting.
+func (d *DurationSetting) Get() time.Duration {
+	return time.Duration(atomic.LoadInt64(&d.v))
+}
+
+// Typ implements the Setting interface.
+func (*DurationSetting) Typ() string {
+	return "d"
+}
+
+// String implements the Setting interface.
+func (d *DurationSetting) String() string {
+	return EncodeDuration(d.Get())
+}
+
+// Validate implements the Setting interface.
+func (d *DurationSetting) Validate(raw string) (string, error) {
+	v, err := d.parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return EncodeDuration(v), nil
+}
+
+func (d *DurationSetting) parse(raw string) (time.Duration, error) {
+	v, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, err
+	}
+	if d.validateFn != nil {
+		if err := d.validateFn(v); err != nil {
+			return 0, err
+		}
+	}
+	return v, nil
+}
+
+func (d *DurationSetting) set(raw string) error {
+	v, err := d.parse(raw)
+	if err != nil {
+		return err
+	}
+	d.setValue(v)
+	return nil
+}
+
+func (d *DurationSetting) setToDefault() {
+	d.setValue(d.defaultValue)
+}
+
+func (d *DurationSetting) setValue(v time.Duration) {
+	if time.Duration(atomic.SwapInt64(&d.v, int64(v))) != v {
+		d.changed()
+	}
+}
+
+// TestingSetDuration replaces the setting pointed to by s with an unregistered
+// setting holding v, which Updaters leave alone, and returns a function which
+// restores the original setting.
+func TestingSetDuration(s **DurationSetting, v time.Duration) func() {
+	saved := *s
+	tmp := &DurationSetting{}
+	tmp.setValue(v)
+	*s = tmp
+	return func() { *s = saved }
+}
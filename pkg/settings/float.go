@@ -0,0 +1,135 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package settings
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// FloatSetting is a setting of type "f".
+type FloatSetting struct {
+	common
+	defaultValue float64
+	validateFn   func(float64) error
+	v            uint64
+}
+
+var _ Setting = &FloatSetting{}
+
+// RegisterFloatSetting defines a new setting of type float.
+func RegisterFloatSetting(key, desc string, defaultValue float64) *FloatSetting {
+	s := &FloatSetting{defaultValue: defaultValue}
+	s.setValue(defaultValue)
+	register(key, desc, s, &s.common)
+	return s
+}
+
+// RegisterValidatedFloatSetting defines a new setting of type float,
+// whose values are checked by validateFn.
+func RegisterValidatedFloatSetting(
+	key, desc string, defaultValue float64, validateFn func(float64) error,
+) *FloatSetting {
+	if err := validateFn(defaultValue); err != nil {
+		panic(fmt.Sprintf("invalid default value for %s: %v", key, err))
+	}
+	s := &FloatSetting{defaultValue: defaultValue, validateFn: validateFn}
+	s.setValue(defaultValue)
+	register(key, desc, s, &s.common)
+	return s
+}
+
+// RegisterNonNegativeFloatSetting defines a new setting of type float
+// which can't be set to a negative value.
+func RegisterNonNegativeFloatSetting(key, desc string, defaultValue float64) *FloatSetting {
+	return RegisterValidatedFloatSetting(key, desc, defaultValue, func(v float64) error {
+		if v < 0 {
+			return errors.Errorf("cannot be set to a negative value: %g", v)
+		}
+		return nil
+	})
+}
+
+// Get returns the current value of the setting.
+func (f *FloatSetting) Get() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&f.v))
+}
+
+// Typ implements the Setting interface.
+func (*FloatSetting) Typ() string {
+	return "f"
+}
+
+// String implements the Setting interface.
+func (f *FloatSetting) String() string {
+	return EncodeFloat(f.Get())
+}
+
+// Validate implements the Setting interface.
+func (f *FloatSetting) Validate(raw string) (string, error) {
+	v, err := f.parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return EncodeFloat(v), nil
+}
+
+func (f *FloatSetting) parse(raw string) (float64, error) {
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, err
+	}
+	if f.validateFn != nil {
+		if err := f.validateFn(v); err != nil {
+			return 0, err
+		}
+	}
+	return v, nil
+}
+
+func (f *FloatSetting) set(raw string) error {
+	v, err := f.parse(raw)
+	if err != nil {
+		return err
+	}
+	f.setValue(v)
+	return nil
+}
+
+func (f *FloatSetting) setToDefault() {
+	f.setValue(f.defaultValue)
+}
+
+func (f *FloatSetting) setValue(v float64) {
+	bits := math.Float64bits(v)
+	if atomic.SwapUint64(&f.v, bits) != bits {
+		f.changed()
+	}
+}
+
+// TestingSetFloat replaces the setting pointed to by s with an unregistered
+// setting holding v, which Updaters leave alone, and returns a function which
+// restores the original setting.
+func TestingSetFloat(s **FloatSetting, v float64) func() {
+	saved := *s
+	tmp := &FloatSetting{}
+	tmp.setValue(v)
+	*s = tmp
+	return func() { *s = saved }
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package settings
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// IntSetting is a setting of type "i".
+type IntSetting struct {
+	common
+	defaultValue int64
+	validateFn   func(int64) error
+	v            int64
+}
+
+var _ Setting = &IntSetting{}
+
+// RegisterIntSetting defines a new setting of type int.
+func RegisterIntSetting(key, desc string, defaultValue int64) *IntSetting {
+	s := &IntSetting{defaultValue: defaultValue}
+	s.setValue(defaultValue)
+	register(key, desc, s, &s.common)
+	return s
+}
+
+// RegisterValidatedIntSetting defines a new setting of type int,
+// whose values are checked by validateFn.
+func RegisterValidatedIntSetting(
+	key, desc string, defaultValue int64, validateFn func(int64) error,
+) *IntSetting {
+	if err := validateFn(defaultValue); err != nil {
+		panic(fmt.Sprintf("invalid default value for %s: %v", key, err))
+	}
+	s := &IntSetting{defaultValue: defaultValue, validateFn: validateFn}
+	s.setValue(defaultValue)
+	register(key, desc, s, &s.common)
+	return s
+}
+
+// RegisterNonNegativeIntSetting defines a new setting of type int
+// which can't be set to a negative value.
+func RegisterNonNegativeIntSetting(key, desc string, defaultValue int64) *IntSetting {
+	return RegisterValidatedIntSetting(key, desc, defaultValue, func(v int64) error {
+		if v < 0 {
+			return errors.Errorf("cannot be set to a negative value: %d", v)
+		}
+		return nil
+	})
+}
+
+// RegisterPositiveIntSetting defines a new setting of type int
+// which can only be set to a positive value.
+func RegisterPositiveIntSetting(key, desc string, defaultValue int64) *IntSetting {
+	return RegisterValidatedIntSetting(key, desc, defaultValue, func(v int64) error {
+		if v <= 0 {
+			return errors.Errorf("cannot be set to a non-positive value: %d", v)
+		}
+		return nil
+	})
+}
+
+// Get returns the current value of the setting.
+func (i *IntSetting) Get() int64 {
+	return atomic.LoadInt64(&i.v)
+}
+
+// Typ implements the Setting interface.
+func (*IntSetting) Typ() string {
+	return "i"
+}
+
+// String implements the Setting interface.
+func (i *IntSetting) String() string {
+	return EncodeInt(i.Get())
+}
+
+// Validate implements the Setting interface.
+func (i *IntSetting) Validate(raw string) (string, error) {
+	v, err := i.parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return EncodeInt(v), nil
+}
+
+func (i *IntSetting) parse(raw string) (int64, error) {
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if i.validateFn != nil {
+		if err := i.validateFn(v); err != nil {
+			return 0, err
+		}
+	}
+	return v, nil
+}
+
+func (i *IntSetting) set(raw string) error {
+	v, err := i.parse(raw)
+	if err != nil {
+		return err
+	}
+	i.setValue(v)
+	return nil
+}
+
+func (i *IntSetting) setToDefault() {
+	i.setValue(i.defaultValue)
+}
+
+func (i *IntSetting) setValue(v int64) {
+	if atomic.SwapInt64(&i.v, v) != v {
+		i.changed()
+	}
+}
+
+// TestingSetInt replaces the setting pointed to by s with an unregistered
+// setting holding v, which Updaters leave alone, and returns a function which
+// restores the original setting.
+func TestingSetInt(s **IntSetting, v int64) func() {
+	saved := *s
+	tmp := &IntSetting{}
+	tmp.setValue(v)
+	*s = tmp
+	return func() { *s = saved }
+}
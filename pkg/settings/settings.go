@@ -0,0 +1,139 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package settings implements the registry of cluster settings: tunables
+// whose values are stored in the system.settings table and gossiped to
+// every node, so that they can be changed at runtime without restarting
+// the cluster.
+//
+// A setting is registered at package initialization by the package using
+// it, e.g.
+//
+//	var enabled = settings.RegisterBoolSetting(
+//	    "kv.foo.enabled", "set to true to enable foo", false)
+//
+// and its current value is read with enabled.Get(). Values are updated
+// through an Updater whenever a new version of the settings table is
+// received.
+package settings
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Setting is the interface implemented by all the typed settings.
+type Setting interface {
+	// Typ returns the short name of the type of the setting. It is stored
+	// alongside the encoded value of the setting in system.settings.
+	Typ() string
+	// String returns the current value of the setting, in a form accepted
+	// by Validate.
+	String() string
+	// Description returns a human-readable description of the setting.
+	Description() string
+	// Validate returns an error if the raw encoding isn't a valid value for
+	// the setting, and otherwise its canonical encoding.
+	Validate(raw string) (string, error)
+	// OnChange registers a function which is called whenever the value of
+	// the setting changes.
+	OnChange(fn func())
+
+	set(raw string) error
+	setToDefault()
+}
+
+// common holds the fields shared by all the typed settings.
+type common struct {
+	description string
+
+	mu struct {
+		syncutil.Mutex
+		onChange []func()
+	}
+}
+
+// Description implements the Setting interface.
+func (c *common) Description() string {
+	return c.description
+}
+
+// OnChange implements the Setting interface.
+func (c *common) OnChange(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.onChange = append(c.mu.onChange, fn)
+}
+
+// changed runs the change callbacks of the setting.
+func (c *common) changed() {
+	c.mu.Lock()
+	fns := c.mu.onChange
+	c.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+var registry = map[string]Setting{}
+
+// register adds a setting to the registry. It panics if a setting with the
+// same key was already registered, which can only be a programming error.
+func register(key, desc string, s Setting, c *common) {
+	if _, ok := registry[key]; ok {
+		panic(fmt.Sprintf("setting already defined: %s", key))
+	}
+	c.description = desc
+	registry[key] = s
+}
+
+// Lookup returns the setting registered under the given key.
+func Lookup(key string) (Setting, bool) {
+	s, ok := registry[key]
+	return s, ok
+}
+
+// Keys returns the sorted keys of all the registered settings.
+func Keys() []string {
+	keys := make([]string, 0, len(registry))
+	for k := range registry {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// EncodeBool encodes a bool as stored in system.settings.
+func EncodeBool(b bool) string {
+	return strconv.FormatBool(b)
+}
+
+// EncodeInt encodes an int as stored in system.settings.
+func EncodeInt(i int64) string {
+	return strconv.FormatInt(i, 10)
+}
+
+// EncodeFloat encodes a float as stored in system.settings.
+func EncodeFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// EncodeDuration encodes a duration as stored in system.settings.
+func EncodeDuration(d time.Duration) string {
+	return d.String()
+}
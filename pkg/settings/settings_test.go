@@ -0,0 +1,245 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package settings
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+var (
+	boolA     = RegisterBoolSetting("test.bool.a", "desc", true)
+	intA      = RegisterIntSetting("test.int.a", "desc", 1)
+	floatA    = RegisterFloatSetting("test.float.a", "desc", 1.5)
+	durationA = RegisterDurationSetting("test.duration.a", "desc", time.Minute)
+	stringA   = RegisterStringSetting("test.string.a", "desc", "foo")
+	byteSizeA = RegisterByteSizeSetting("test.bytesize.a", "desc", 1<<20)
+	durationP = RegisterPositiveDurationSetting("test.duration.positive", "desc", time.Second)
+	intNN     = RegisterNonNegativeIntSetting("test.int.nonnegative", "desc", 0)
+	floatNN   = RegisterNonNegativeFloatSetting("test.float.nonnegative", "desc", 0)
+	byteSizeP = RegisterPositiveByteSizeSetting("test.bytesize.positive", "desc", 1)
+	stringVal = RegisterValidatedStringSetting("test.string.validated", "desc", "",
+		func(s string) error {
+			if strings.ContainsRune(s, ' ') {
+				return errors.New("spaces are not allowed")
+			}
+			return nil
+		})
+)
+
+func TestSettingsUpdate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	changes := 0
+	intA.OnChange(func() { changes++ })
+
+	u := MakeUpdater()
+	for _, kv := range []struct{ key, value, typ string }{
+		{"test.bool.a", "false", "b"},
+		{"test.int.a", "5", "i"},
+		{"test.float.a", "0.25", "f"},
+		{"test.duration.a", "2h", "d"},
+		{"test.string.a", "bar", "s"},
+		{"test.bytesize.a", "2 MiB", "z"},
+		{"test.unknown", "1", "i"},
+	} {
+		if err := u.Set(kv.key, kv.value, kv.typ); err != nil {
+			t.Fatal(err)
+		}
+	}
+	u.Done()
+
+	if v := boolA.Get(); v {
+		t.Errorf("expected false, got %t", v)
+	}
+	if v := intA.Get(); v != 5 {
+		t.Errorf("expected 5, got %d", v)
+	}
+	if v := floatA.Get(); v != 0.25 {
+		t.Errorf("expected 0.25, got %f", v)
+	}
+	if v := durationA.Get(); v != 2*time.Hour {
+		t.Errorf("expected 2h, got %s", v)
+	}
+	if v := stringA.Get(); v != "bar" {
+		t.Errorf("expected bar, got %s", v)
+	}
+	if v := byteSizeA.Get(); v != 2<<20 {
+		t.Errorf("expected %d, got %d", 2<<20, v)
+	}
+	if changes != 1 {
+		t.Errorf("expected 1 change, got %d", changes)
+	}
+
+	// Settings which aren't present in the update revert to their defaults.
+	u = MakeUpdater()
+	if err := u.Set("test.int.a", "5", "i"); err != nil {
+		t.Fatal(err)
+	}
+	u.Done()
+	if v := intA.Get(); v != 5 {
+		t.Errorf("expected 5, got %d", v)
+	}
+	if v := stringA.Get(); v != "foo" {
+		t.Errorf("expected foo, got %s", v)
+	}
+	if changes != 1 {
+		t.Errorf("expected 1 change, got %d", changes)
+	}
+	u = MakeUpdater()
+	u.Done()
+	if v := intA.Get(); v != 1 {
+		t.Errorf("expected 1, got %d", v)
+	}
+	if changes != 2 {
+		t.Errorf("expected 2 changes, got %d", changes)
+	}
+}
+
+func TestSettingsUpdateErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	u := MakeUpdater()
+	defer u.Done()
+	if err := u.Set("test.int.a", "5", "b"); !testutils.IsError(err, `setting "test.int.a" is of type "i", not "b"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := u.Set("test.int.a", "five", "i"); !testutils.IsError(err, `setting "test.int.a": .*invalid syntax`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := u.Set("test.string.validated", "a b", "s"); !testutils.IsError(err, `setting "test.string.validated": spaces are not allowed`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := u.Set("test.duration.positive", "0s", "d"); !testutils.IsError(err, `setting "test.duration.positive": cannot be set to a non-positive value`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestSettingsUpdateInvalid verifies that a setting whose stored value is
+// rejected by its validation keeps its default value.
+func TestSettingsUpdateInvalid(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	u := MakeUpdater()
+	if err := u.Set("test.duration.positive", "2s", "d"); err != nil {
+		t.Fatal(err)
+	}
+	u.Done()
+	if v := durationP.Get(); v != 2*time.Second {
+		t.Fatalf("expected 2s, got %s", v)
+	}
+	u = MakeUpdater()
+	if err := u.Set("test.duration.positive", "-1s", "d"); err == nil {
+		t.Fatal("expected an error")
+	}
+	u.Done()
+	if v := durationP.Get(); v != time.Second {
+		t.Fatalf("expected the default of 1s, got %s", v)
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	testCases := []struct {
+		key, raw, expected, err string
+	}{
+		{"test.bool.a", "TRUE", "true", ""},
+		{"test.bool.a", "yes", "", "invalid syntax"},
+		{"test.int.a", "007", "7", ""},
+		{"test.float.a", "1e2", "100", ""},
+		{"test.duration.a", "90s", "1m30s", ""},
+		{"test.duration.a", "90", "", "missing unit"},
+		{"test.string.a", "anything", "anything", ""},
+		{"test.string.validated", "a_b", "a_b", ""},
+		{"test.string.validated", "a b", "", "spaces are not allowed"},
+		{"test.bytesize.a", "64 MiB", "67108864", ""},
+		{"test.bytesize.a", "1kb", "1000", ""},
+		{"test.duration.positive", "1ms", "1ms", ""},
+		{"test.duration.positive", "0s", "", "non-positive"},
+		{"test.int.nonnegative", "0", "0", ""},
+		{"test.int.nonnegative", "-1", "", "negative"},
+		{"test.float.nonnegative", "0.5", "0.5", ""},
+		{"test.float.nonnegative", "-0.5", "", "negative"},
+		{"test.bytesize.positive", "1 KiB", "1024", ""},
+		{"test.bytesize.positive", "0", "", "non-positive"},
+	}
+	for _, tc := range testCases {
+		s, ok := Lookup(tc.key)
+		if !ok {
+			t.Fatalf("setting %s not found", tc.key)
+		}
+		encoded, err := s.Validate(tc.raw)
+		if !testutils.IsError(err, tc.err) {
+			t.Errorf("%s=%s: expected error %q, got %v", tc.key, tc.raw, tc.err, err)
+		} else if encoded != tc.expected {
+			t.Errorf("%s=%s: expected %q, got %q", tc.key, tc.raw, tc.expected, encoded)
+		}
+	}
+}
+
+func TestTestingSet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer TestingSetString(&stringA, "bar")()
+	if v := stringA.Get(); v != "bar" {
+		t.Errorf("expected bar, got %s", v)
+	}
+	reset := TestingSetBool(&boolA, false)
+	if boolA.Get() {
+		t.Error("expected false")
+	}
+	// The overridden value isn't reset by updates.
+	u := MakeUpdater()
+	u.Done()
+	if boolA.Get() {
+		t.Error("expected false")
+	}
+	reset()
+	if !boolA.Get() {
+		t.Error("expected true")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	expected := []string{
+		"test.bool.a", "test.bytesize.a", "test.bytesize.positive",
+		"test.duration.a", "test.duration.positive", "test.float.a",
+		"test.float.nonnegative", "test.int.a", "test.int.nonnegative",
+		"test.string.a", "test.string.validated",
+	}
+	if keys := Keys(); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected a panic when registering a duplicate setting")
+		}
+	}()
+	RegisterBoolSetting("test.bool.a", "desc", false)
+}
+
+func TestInvalidDefault(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected a panic when registering an invalid default value")
+		}
+	}()
+	RegisterPositiveIntSetting("test.int.invalid", "desc", 0)
+}
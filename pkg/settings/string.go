@@ -0,0 +1,109 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package settings
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// StringSetting is a setting of type "s".
+type StringSetting struct {
+	common
+	defaultValue string
+	validateFn   func(string) error
+	v            atomic.Value
+}
+
+var _ Setting = &StringSetting{}
+
+// RegisterStringSetting defines a new setting of type string.
+func RegisterStringSetting(key, desc string, defaultValue string) *StringSetting {
+	s := &StringSetting{defaultValue: defaultValue}
+	s.v.Store(defaultValue)
+	register(key, desc, s, &s.common)
+	return s
+}
+
+// RegisterValidatedStringSetting defines a new setting of type string, whose
+// values are checked by validateFn.
+func RegisterValidatedStringSetting(
+	key, desc string, defaultValue string, validateFn func(string) error,
+) *StringSetting {
+	if err := validateFn(defaultValue); err != nil {
+		panic(fmt.Sprintf("invalid default value for %s: %v", key, err))
+	}
+	s := &StringSetting{defaultValue: defaultValue, validateFn: validateFn}
+	s.v.Store(defaultValue)
+	register(key, desc, s, &s.common)
+	return s
+}
+
+// Get returns the current value of the setting.
+func (s *StringSetting) Get() string {
+	return s.v.Load().(string)
+}
+
+// Typ implements the Setting interface.
+func (*StringSetting) Typ() string {
+	return "s"
+}
+
+// String implements the Setting interface.
+func (s *StringSetting) String() string {
+	return s.Get()
+}
+
+// Validate implements the Setting interface.
+func (s *StringSetting) Validate(raw string) (string, error) {
+	if s.validateFn != nil {
+		if err := s.validateFn(raw); err != nil {
+			return "", err
+		}
+	}
+	return raw, nil
+}
+
+func (s *StringSetting) set(raw string) error {
+	if _, err := s.Validate(raw); err != nil {
+		return err
+	}
+	s.setValue(raw)
+	return nil
+}
+
+func (s *StringSetting) setToDefault() {
+	s.setValue(s.defaultValue)
+}
+
+func (s *StringSetting) setValue(v string) {
+	// Stores of the same setting are serialized by the Updater, so the
+	// comparison doesn't race with another store.
+	if s.Get() != v {
+		s.v.Store(v)
+		s.changed()
+	}
+}
+
+// TestingSetString replaces the setting pointed to by s with an unregistered
+// setting holding v, which Updaters leave alone, and returns a function which
+// restores the original setting.
+func TestingSetString(s **StringSetting, v string) func() {
+	saved := *s
+	tmp := &StringSetting{}
+	tmp.v.Store(v)
+	*s = tmp
+	return func() { *s = saved }
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package settings
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// updateMu serializes Updaters, so that the settings of concurrent updates
+// aren't interleaved.
+var updateMu syncutil.Mutex
+
+// An Updater applies the contents of a version of the settings table to the
+// registered settings: each row is passed to Set, and Done then resets the
+// settings which didn't have a row to their default values.
+type Updater struct {
+	seen map[string]struct{}
+}
+
+// MakeUpdater returns a new Updater. Done must be called on it.
+func MakeUpdater() Updater {
+	updateMu.Lock()
+	return Updater{seen: make(map[string]struct{})}
+}
+
+// Set sets the setting with the given key to the encoded value, after
+// checking that the setting has the given type and that the value passes
+// the setting's validation. Unknown keys are ignored: they may have been set
+// by a node running a newer version, or belong to a setting which has since
+// been removed. A setting whose value is rejected is reset to its default
+// by Done.
+func (u Updater) Set(key, rawValue, valType string) error {
+	s, ok := registry[key]
+	if !ok {
+		return nil
+	}
+	if typ := s.Typ(); valType != typ {
+		return errors.Errorf("setting %q is of type %q, not %q", key, typ, valType)
+	}
+	if err := s.set(rawValue); err != nil {
+		return errors.Wrapf(err, "setting %q", key)
+	}
+	u.seen[key] = struct{}{}
+	return nil
+}
+
+// Done resets the settings which weren't Set to their default values and
+// releases the Updater.
+func (u Updater) Done() {
+	defer updateMu.Unlock()
+	for key, s := range registry {
+		if _, ok := u.seen[key]; !ok {
+			s.setToDefault()
+		}
+	}
+}
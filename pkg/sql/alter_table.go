@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -312,6 +313,20 @@ func (n *alterTableNode) Start() error {
 				return errors.Errorf("validating %s constraint %q unsupported", constraint.Kind, t.Constraint)
 			}
 
+		case *parser.AlterTableSetAudit:
+			if n.p.session.User != security.RootUser {
+				return fmt.Errorf("only %s is allowed to change the audit mode of a table",
+					security.RootUser)
+			}
+			mode := sqlbase.TableDescriptor_DISABLED
+			if t.Mode == parser.AuditModeReadWrite {
+				mode = sqlbase.TableDescriptor_READWRITE
+			}
+			if n.tableDesc.AuditMode != mode {
+				n.tableDesc.AuditMode = mode
+				descriptorChanged = true
+			}
+
 		case parser.ColumnMutationCmd:
 			// Column mutations
 			status, i, err := n.tableDesc.FindColumnByName(t.GetColumn())
@@ -0,0 +1,154 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	gosql "database/sql"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// readAuditLog returns the messages written to the SQL audit log.
+func readAuditLog(t *testing.T) []string {
+	log.Flush()
+	files, err := log.ListLogFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for _, file := range files {
+		if !strings.HasSuffix(file.Details.Program, "-sql-audit") {
+			continue
+		}
+		reader, err := log.GetLogReader(file.Name, true /* restricted */)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoder := log.NewEntryDecoder(reader)
+		for {
+			var entry log.Entry
+			if err := decoder.Decode(&entry); err != nil {
+				if err == io.EOF {
+					break
+				}
+				t.Fatal(err)
+			}
+			// Skip the entries written when the file was created.
+			if strings.Contains(entry.Message, " table=") {
+				messages = append(messages, entry.Message)
+			}
+		}
+		if err := reader.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return messages
+}
+
+func TestAuditLog(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, err := ioutil.TempDir("", "TestAuditLog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := log.EnableLogFileOutput(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		log.DisableLogFileOutput()
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop()
+
+	if _, err := db.Exec(`
+CREATE DATABASE d;
+CREATE TABLE d.audited (k INT PRIMARY KEY, v STRING);
+CREATE TABLE d.unaudited (k INT PRIMARY KEY);
+ALTER TABLE d.audited EXPERIMENTAL_AUDIT SET READ WRITE;
+CREATE USER testuser;
+`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO d.audited VALUES ($1, $2)`, 1, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`SELECT * FROM d.audited, d.unaudited`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`SELECT * FROM d.unaudited`); err != nil {
+		t.Fatal(err)
+	}
+
+	pgURL, cleanupFn := sqlutils.PGUrl(
+		t, s.ServingAddr(), "TestAuditLog", url.User(server.TestUser))
+	defer cleanupFn()
+	testUserDB, err := gosql.Open("postgres", pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testUserDB.Close()
+	if _, err := testUserDB.Exec(`DELETE FROM d.audited`); !testutils.IsError(
+		err, "user testuser does not have DELETE privilege",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Accesses are no longer audited once auditing is turned off.
+	if _, err := db.Exec(`ALTER TABLE d.audited EXPERIMENTAL_AUDIT SET OFF`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`SELECT * FROM d.audited`); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*regexp.Regexp{
+		regexp.MustCompile(`user=root client=\S+ table="audited"\[\d+\] access=WRITE ` +
+			`stmt="INSERT INTO d.audited VALUES \(\$1, \$2\)" placeholders={\$1:1, \$2:'a'} ` +
+			`rows=1 status=OK$`),
+		regexp.MustCompile(`user=root client=\S+ table="audited"\[\d+\] access=READ ` +
+			`stmt="SELECT \* FROM d.audited, d.unaudited" placeholders={} rows=0 status=OK$`),
+		regexp.MustCompile(`user=testuser client=\S+ table="audited"\[\d+\] access=WRITE ` +
+			`stmt="DELETE FROM d.audited" placeholders={} rows=0 ` +
+			`status=ERROR error="user testuser does not have DELETE privilege on table audited"$`),
+	}
+	messages := readAuditLog(t)
+	if len(messages) != len(expected) {
+		t.Fatalf("expected %d audit log entries, got:\n%s",
+			len(expected), strings.Join(messages, "\n"))
+	}
+	for i, re := range expected {
+		if !re.MatchString(messages[i]) {
+			t.Errorf("%d: expected audit log entry matching %s, got %q", i, re, messages[i])
+		}
+	}
+}
@@ -442,6 +442,7 @@ func (sc *SchemaChanger) truncateAndBackfillColumnsChunk(
 
 		oldValues := make(parser.DTuple, len(ru.fetchCols))
 		writeBatch := txn.NewBatch()
+		writeBatch.Header.Background = true
 		rowLength := 0
 		var lastRowSeen parser.DTuple
 		i := int64(0)
@@ -665,6 +666,7 @@ func (sc *SchemaChanger) backfillIndexesChunk(
 			return err
 		}
 		b := &client.Batch{}
+		b.Header.Background = true
 		numRows := int64(0)
 		for ; numRows < chunkSize; numRows++ {
 			if next, err := rows.Next(); !next {
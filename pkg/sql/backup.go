@@ -270,7 +270,14 @@ func Ingest(
 		return err
 	}
 
-	b := txn.NewBatch()
+	newBatch := func() *client.Batch {
+		b := txn.NewBatch()
+		// The ingested data is bulk written in the background, behind
+		// foreground traffic.
+		b.Header.Background = true
+		return b
+	}
+	b := newBatch()
 	var v roachpb.Value
 	count := 0
 	ingestFunc := func(kv engine.MVCCKeyValue) (bool, error) {
@@ -285,7 +292,7 @@ func Ingest(
 			if err := txn.Run(b); err != nil {
 				return true, err
 			}
-			b = txn.NewBatch()
+			b = newBatch()
 			count = 0
 		}
 		return false, nil
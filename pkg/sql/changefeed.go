@@ -0,0 +1,756 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// RangeFeeder establishes a RangeFeed over a span of the key space,
+// streaming its events until the context is canceled or an error
+// occurs. It is implemented by *kv.DistSender.
+type RangeFeeder interface {
+	RangeFeed(context.Context, *roachpb.RangeFeedRequest, chan<- *roachpb.RangeFeedEvent) error
+}
+
+// changeFeedEventBufferSize is the number of RangeFeed events buffered
+// between the RangeFeed and the CHANGEFEED processing them.
+const changeFeedEventBufferSize = 128
+
+// changeFeedProgressInterval is the minimum interval at which a CHANGEFEED
+// records the timestamp up to which it has emitted all changes in
+// system.changefeeds.
+var changeFeedProgressInterval = 5 * time.Second
+
+// ChangeFeed starts a feed of the changes to the rows of a table, which
+// are written as JSON to a sink. The feed runs in the background on the
+// gateway node until it's canceled or fails. It is recorded in
+// system.changefeeds along with its progress, so that it is resumed when
+// the node restarts (see Executor.ResumeChangeFeeds).
+// Privileges: root user, since sinks are written to by the node itself.
+func (p *planner) ChangeFeed(n *parser.ChangeFeed) (planNode, error) {
+	if p.session.User != security.RootUser {
+		return nil, fmt.Errorf("only %s is allowed to create changefeeds", security.RootUser)
+	}
+	tn, err := n.Table.NormalizeWithDatabaseName(p.session.Database)
+	if err != nil {
+		return nil, err
+	}
+	tableDesc, err := p.mustGetTableDesc(tn)
+	if err != nil {
+		return nil, err
+	}
+	if !tableDesc.IsTable() {
+		return nil, errors.Errorf("%q is not a table", tn.String())
+	}
+	if tableDesc.IsInterleaved() || len(tableDesc.PrimaryIndex.InterleavedBy) > 0 {
+		return nil, errors.Errorf("changefeeds are not supported on interleaved table %q", tn.String())
+	}
+	sink, err := makeChangeFeedSink(n.Sink)
+	if err != nil {
+		return nil, err
+	}
+	return &changeFeedNode{p: p, tableDesc: tableDesc, sinkURI: n.Sink, sink: sink}, nil
+}
+
+type changeFeedNode struct {
+	p         *planner
+	tableDesc *sqlbase.TableDescriptor
+	sinkURI   string
+	sink      changeFeedSink
+}
+
+func (n *changeFeedNode) expandPlan() error {
+	return nil
+}
+
+func (n *changeFeedNode) Start() error {
+	cfg := n.p.execCfg
+	if cfg.RangeFeeder == nil {
+		return errors.New("changefeeds are not supported by this executor")
+	}
+	start := n.p.txn.Proto.OrigTimestamp
+	const insertFeed = `INSERT INTO system.changefeeds ` +
+		`(nodeID, tableID, sink, username, wallTime, logical) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, createdAt`
+	values, err := n.p.queryRow(insertFeed, int64(cfg.NodeID.Get()), int64(n.tableDesc.ID),
+		n.sinkURI, n.p.session.User, start.WallTime, int64(start.Logical))
+	if err != nil {
+		return err
+	}
+	return cfg.startChangeFeed(changeFeedInfo{
+		id:      int64(*values[0].(*parser.DInt)),
+		table:   n.tableDesc.Name,
+		sink:    n.sinkURI,
+		user:    n.p.session.User,
+		started: values[1].(*parser.DTimestamp).Time,
+	}, n.tableDesc, n.sink, start)
+}
+
+// startChangeFeed runs the changefeed described by info in the background,
+// emitting the changes to the table made after start to the sink.
+func (cfg *ExecutorConfig) startChangeFeed(
+	info changeFeedInfo, tableDesc *sqlbase.TableDescriptor, sink changeFeedSink, start hlc.Timestamp,
+) error {
+	cf, err := makeChangeFeed(cfg.DB, cfg.RangeFeeder, tableDesc, sink, start)
+	if err != nil {
+		return err
+	}
+	cf.id = info.id
+	cf.memMetrics = cfg.LeaseManager.memMetrics
+	// The feed outlives the session, so it doesn't inherit its context.
+	ctx := cfg.stopper.WithCancel(cfg.AmbientCtx.AnnotateCtx(context.Background()))
+	ctx, cancel := context.WithCancel(ctx)
+	info.cancel = cancel
+	cfg.changeFeeds.register(&info)
+	if err := cfg.stopper.RunAsyncTask(ctx, func(ctx context.Context) {
+		defer cfg.changeFeeds.unregister(&info)
+		cf.run(ctx)
+	}); err != nil {
+		cfg.changeFeeds.unregister(&info)
+		cancel()
+		return err
+	}
+	return nil
+}
+
+// ResumeChangeFeeds restarts the changefeeds recorded in system.changefeeds
+// as run by this node, each from the timestamp up to which it had emitted
+// all changes, so that feeds survive the restart of their node. Changes
+// emitted after the feed last recorded its progress are emitted again,
+// while versions garbage collected while the node was down are missed. It
+// is called once the node has started and the system tables have been
+// migrated. Feeds which can't be resumed are logged and left alone.
+func (e *Executor) ResumeChangeFeeds(ctx context.Context) error {
+	cfg := &e.cfg
+	if cfg.RangeFeeder == nil {
+		return nil
+	}
+	type changeFeedRecord struct {
+		info    changeFeedInfo
+		tableID sqlbase.ID
+		start   hlc.Timestamp
+	}
+	var records []changeFeedRecord
+	if err := cfg.DB.Txn(ctx, func(txn *client.Txn) error {
+		records = nil
+		p := makeInternalPlanner("resume-changefeeds", txn, security.RootUser, cfg.LeaseManager.memMetrics)
+		defer finishInternalPlanner(p)
+		const nodeFeeds = `SELECT id, tableID, sink, username, createdAt, wallTime, logical ` +
+			`FROM system.changefeeds WHERE nodeID = $1`
+		plan, err := p.query(nodeFeeds, int64(cfg.NodeID.Get()))
+		if err != nil {
+			return err
+		}
+		defer plan.Close()
+		if err := plan.Start(); err != nil {
+			return err
+		}
+		for {
+			next, err := plan.Next()
+			if err != nil {
+				return err
+			}
+			if !next {
+				return nil
+			}
+			row := plan.Values()
+			records = append(records, changeFeedRecord{
+				info: changeFeedInfo{
+					id:      int64(*row[0].(*parser.DInt)),
+					sink:    string(*row[2].(*parser.DString)),
+					user:    string(*row[3].(*parser.DString)),
+					started: row[4].(*parser.DTimestamp).Time,
+				},
+				tableID: sqlbase.ID(*row[1].(*parser.DInt)),
+				start: hlc.Timestamp{
+					WallTime: int64(*row[5].(*parser.DInt)),
+					Logical:  int32(*row[6].(*parser.DInt)),
+				},
+			})
+		}
+	}); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		var tableDesc *sqlbase.TableDescriptor
+		err := cfg.DB.Txn(ctx, func(txn *client.Txn) error {
+			var err error
+			tableDesc, err = sqlbase.GetTableDescFromID(txn, record.tableID)
+			return err
+		})
+		if err == nil && tableDesc.Dropped() {
+			err = errors.Errorf("table %q was dropped", tableDesc.Name)
+		}
+		var sink changeFeedSink
+		if err == nil {
+			sink, err = makeChangeFeedSink(record.info.sink)
+		}
+		if err == nil {
+			record.info.table = tableDesc.Name
+			err = cfg.startChangeFeed(record.info, tableDesc, sink, record.start)
+		}
+		if err != nil {
+			log.Errorf(ctx, "unable to resume changefeed %d: %s", record.info.id, err)
+			continue
+		}
+		log.Infof(ctx, "resumed changefeed %d for table %q at %s", record.info.id, tableDesc.Name, record.start)
+	}
+	return nil
+}
+
+func (n *changeFeedNode) Next() (bool, error)                 { return false, nil }
+func (n *changeFeedNode) Close()                              {}
+func (n *changeFeedNode) Columns() ResultColumns              { return make(ResultColumns, 0) }
+func (n *changeFeedNode) Ordering() orderingInfo              { return orderingInfo{} }
+func (n *changeFeedNode) Values() parser.DTuple               { return parser.DTuple{} }
+func (n *changeFeedNode) DebugValues() debugValues            { return debugValues{} }
+func (n *changeFeedNode) ExplainTypes(_ func(string, string)) {}
+func (n *changeFeedNode) SetLimitHint(_ int64, _ bool)        {}
+func (n *changeFeedNode) MarkDebug(mode explainMode)          {}
+func (n *changeFeedNode) ExplainPlan(v bool) (string, string, []planNode) {
+	return "changefeed", n.tableDesc.Name, nil
+}
+
+// ShowChangeFeeds lists the changefeeds running on this node.
+// Privileges: root user.
+func (p *planner) ShowChangeFeeds(n *parser.ShowChangeFeeds) (planNode, error) {
+	if p.session.User != security.RootUser {
+		return nil, fmt.Errorf("only %s is allowed to SHOW CHANGEFEEDS", security.RootUser)
+	}
+	columns := ResultColumns{
+		{Name: "id", Typ: parser.TypeInt},
+		{Name: "table", Typ: parser.TypeString},
+		{Name: "sink", Typ: parser.TypeString},
+		{Name: "user", Typ: parser.TypeString},
+		{Name: "started", Typ: parser.TypeTimestamp},
+	}
+	return &delayedNode{
+		p:       p,
+		name:    n.String(),
+		columns: columns,
+		constructor: func(p *planner) (planNode, error) {
+			v := p.newContainerValuesNode(columns, 0)
+			for _, info := range p.execCfg.changeFeeds.list() {
+				if err := v.rows.AddRow(parser.DTuple{
+					parser.NewDInt(parser.DInt(info.id)),
+					parser.NewDString(info.table),
+					parser.NewDString(info.sink),
+					parser.NewDString(info.user),
+					parser.MakeDTimestamp(info.started, time.Microsecond),
+				}); err != nil {
+					v.rows.Close()
+					return nil, err
+				}
+			}
+			return v, nil
+		},
+	}, nil
+}
+
+// CancelChangeFeed stops a changefeed running on this node.
+// Privileges: root user.
+func (p *planner) CancelChangeFeed(n *parser.CancelChangeFeed) (planNode, error) {
+	if p.session.User != security.RootUser {
+		return nil, fmt.Errorf("only %s is allowed to CANCEL CHANGEFEED", security.RootUser)
+	}
+	if !p.execCfg.changeFeeds.cancel(n.ID) {
+		return nil, errors.Errorf("changefeed %d is not running on this node", n.ID)
+	}
+	if _, err := p.exec(`DELETE FROM system.changefeeds WHERE id = $1`, n.ID); err != nil {
+		return nil, err
+	}
+	return &emptyNode{}, nil
+}
+
+// changeFeedInfo describes a running changefeed.
+type changeFeedInfo struct {
+	id      int64
+	table   string
+	sink    string
+	user    string
+	started time.Time
+	cancel  func()
+}
+
+// changeFeedRegistry tracks the changefeeds running on a node, so that
+// they can be listed and canceled. Changefeeds run on the node which
+// created them, and are identified by the ID of their record in
+// system.changefeeds.
+type changeFeedRegistry struct {
+	mu    syncutil.Mutex
+	feeds map[int64]*changeFeedInfo
+}
+
+func (r *changeFeedRegistry) register(info *changeFeedInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.feeds == nil {
+		r.feeds = make(map[int64]*changeFeedInfo)
+	}
+	r.feeds[info.id] = info
+}
+
+// unregister removes the changefeed, unless it was canceled and another
+// feed with the same ID was registered since, which happens when a feed
+// is resumed.
+func (r *changeFeedRegistry) unregister(info *changeFeedInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.feeds[info.id] == info {
+		delete(r.feeds, info.id)
+	}
+}
+
+// cancel cancels the changefeed with the given ID, returning false if it
+// isn't running.
+func (r *changeFeedRegistry) cancel(id int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info, ok := r.feeds[id]
+	if ok {
+		info.cancel()
+		delete(r.feeds, id)
+	}
+	return ok
+}
+
+// list returns the running changefeeds, ordered by ID.
+func (r *changeFeedRegistry) list() []changeFeedInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	infos := make([]changeFeedInfo, 0, len(r.feeds))
+	for _, info := range r.feeds {
+		infos = append(infos, *info)
+	}
+	sort.Sort(changeFeedInfosByID(infos))
+	return infos
+}
+
+type changeFeedInfosByID []changeFeedInfo
+
+func (s changeFeedInfosByID) Len() int           { return len(s) }
+func (s changeFeedInfosByID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s changeFeedInfosByID) Less(i, j int) bool { return s[i].id < s[j].id }
+
+// changeFeedMessage is the JSON message emitted for each change to a
+// row. Value is nil if the row was deleted.
+type changeFeedMessage struct {
+	Table   string                 `json:"table"`
+	Key     []interface{}          `json:"key"`
+	Value   map[string]interface{} `json:"value"`
+	Updated string                 `json:"updated"`
+}
+
+// changeFeedResolvedMessage is the JSON message emitted once all the
+// changes to a table at or below a timestamp have been emitted.
+type changeFeedResolvedMessage struct {
+	Resolved string `json:"resolved"`
+}
+
+// changeFeedRowUpdate identifies a change to a row, by the row's key and
+// the timestamp it was changed at.
+type changeFeedRowUpdate struct {
+	key string
+	ts  hlc.Timestamp
+}
+
+type changeFeedRowUpdatesByTimestamp []changeFeedRowUpdate
+
+func (u changeFeedRowUpdatesByTimestamp) Len() int      { return len(u) }
+func (u changeFeedRowUpdatesByTimestamp) Swap(i, j int) { u[i], u[j] = u[j], u[i] }
+func (u changeFeedRowUpdatesByTimestamp) Less(i, j int) bool {
+	if u[i].ts != u[j].ts {
+		return u[i].ts.Less(u[j].ts)
+	}
+	return u[i].key < u[j].key
+}
+
+// changeFeed turns the RangeFeed over the primary index of a table into
+// a stream of row changes. The RangeFeed delivers the writes to each
+// range in timestamp order, but not across ranges, so the changes are
+// buffered until the whole table has been resolved past them. They are
+// then emitted in timestamp order, each with the row as of the
+// timestamp it was changed at, followed by a resolved message.
+//
+// The table's schema is captured when the feed starts; schema changes
+// made afterwards are not reflected in the emitted rows.
+//
+// Every changeFeedProgressInterval, the resolved timestamp is recorded in
+// the feed's record in system.changefeeds once its changes have been
+// written to the sink, which is where the feed resumes after a restart.
+type changeFeed struct {
+	id         int64
+	memMetrics *MemoryMetrics
+	// persistedAt is when the progress of the feed was last recorded.
+	persistedAt time.Time
+
+	db        *client.DB
+	feeder    RangeFeeder
+	tableDesc *sqlbase.TableDescriptor
+	sink      changeFeedSink
+	span      roachpb.Span
+	start     hlc.Timestamp
+
+	frontier spanFrontier
+	// pending contains the row changes above the frontier.
+	pending map[changeFeedRowUpdate]struct{}
+
+	rf        sqlbase.RowFetcher
+	colIdxMap map[sqlbase.ColumnID]int
+	alloc     sqlbase.DatumAlloc
+}
+
+func makeChangeFeed(
+	db *client.DB,
+	feeder RangeFeeder,
+	tableDesc *sqlbase.TableDescriptor,
+	sink changeFeedSink,
+	start hlc.Timestamp,
+) (*changeFeed, error) {
+	prefix := roachpb.Key(sqlbase.MakeIndexKeyPrefix(tableDesc, tableDesc.PrimaryIndex.ID))
+	span := roachpb.Span{Key: prefix, EndKey: prefix.PrefixEnd()}
+	cf := &changeFeed{
+		db:        db,
+		feeder:    feeder,
+		tableDesc: tableDesc,
+		sink:      sink,
+		span:      span,
+		start:     start,
+		frontier:  makeSpanFrontier(span, start),
+		pending:   make(map[changeFeedRowUpdate]struct{}),
+		colIdxMap: colIDtoRowIndexFromCols(tableDesc.Columns),
+	}
+	valNeededForCol := make([]bool, len(tableDesc.Columns))
+	for i := range valNeededForCol {
+		valNeededForCol[i] = true
+	}
+	if err := cf.rf.Init(
+		tableDesc, cf.colIdxMap, &tableDesc.PrimaryIndex, false /* reverse */, false, /* isSecondaryIndex */
+		tableDesc.Columns, valNeededForCol,
+	); err != nil {
+		return nil, err
+	}
+	return cf, nil
+}
+
+func (cf *changeFeed) run(ctx context.Context) {
+	defer func() {
+		if err := cf.sink.Close(); err != nil {
+			log.Warningf(ctx, "unable to close changefeed sink: %s", err)
+		}
+	}()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// A feed which fails is not resumed; one canceled by CANCEL CHANGEFEED
+	// has its record removed by the statement, and one stopped by the node
+	// shutting down keeps it.
+	fail := func(err error) {
+		log.Errorf(ctx, "changefeed for table %q failed: %s", cf.tableDesc.Name, err)
+		if err := cf.removeRecord(ctx); err != nil {
+			log.Warningf(ctx, "unable to remove changefeed record: %s", err)
+		}
+	}
+
+	eventCh := make(chan *roachpb.RangeFeedEvent, changeFeedEventBufferSize)
+	errCh := make(chan error, 1)
+	go func() {
+		args := roachpb.RangeFeedRequest{Span: cf.span}
+		args.Timestamp = cf.start
+		errCh <- cf.feeder.RangeFeed(ctx, &args, eventCh)
+	}()
+
+	log.Infof(ctx, "changefeed for table %q started at %s", cf.tableDesc.Name, cf.start)
+	for {
+		select {
+		case event := <-eventCh:
+			if err := cf.processEvent(ctx, event); err != nil {
+				fail(err)
+				return
+			}
+		case err := <-errCh:
+			if ctx.Err() == nil {
+				fail(err)
+			}
+			return
+		}
+	}
+}
+
+func (cf *changeFeed) processEvent(ctx context.Context, event *roachpb.RangeFeedEvent) error {
+	switch {
+	case event.Val != nil:
+		ts := event.Val.Value.Timestamp
+		if !cf.frontier.Frontier().Less(ts) {
+			// The change was already emitted.
+			return nil
+		}
+		rowKey, err := keys.EnsureSafeSplitKey(event.Val.Key)
+		if err != nil {
+			return err
+		}
+		cf.pending[changeFeedRowUpdate{key: string(rowKey), ts: ts}] = struct{}{}
+	case event.Checkpoint != nil:
+		prev := cf.frontier.Frontier()
+		if resolved := cf.frontier.Forward(
+			event.Checkpoint.Span, event.Checkpoint.ResolvedTS,
+		); prev.Less(resolved) {
+			return cf.emit(ctx, resolved)
+		}
+	}
+	return nil
+}
+
+// emit writes the pending changes at or below the resolved timestamp to
+// the sink, followed by a resolved message.
+func (cf *changeFeed) emit(ctx context.Context, resolved hlc.Timestamp) error {
+	var updates []changeFeedRowUpdate
+	for u := range cf.pending {
+		if !resolved.Less(u.ts) {
+			updates = append(updates, u)
+			delete(cf.pending, u)
+		}
+	}
+	sort.Sort(changeFeedRowUpdatesByTimestamp(updates))
+
+	msgs := make([][]byte, 0, len(updates)+1)
+	for len(updates) > 0 {
+		ts := updates[0].ts
+		n := 1
+		for n < len(updates) && updates[n].ts == ts {
+			n++
+		}
+		// Read all the rows changed at the same timestamp in one
+		// transaction fixed at that timestamp.
+		var batch [][]byte
+		if err := cf.db.Txn(ctx, func(txn *client.Txn) error {
+			setTxnTimestamps(txn, ts)
+			batch = batch[:0]
+			for _, u := range updates[:n] {
+				msg, err := cf.encodeRow(txn, roachpb.Key(u.key), ts)
+				if err != nil {
+					return err
+				}
+				batch = append(batch, msg)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		msgs = append(msgs, batch...)
+		updates = updates[n:]
+	}
+
+	msg, err := json.Marshal(changeFeedResolvedMessage{Resolved: resolved.String()})
+	if err != nil {
+		return err
+	}
+	if err := cf.sink.Emit(ctx, append(msgs, msg)); err != nil {
+		return err
+	}
+	if timeutil.Since(cf.persistedAt) >= changeFeedProgressInterval {
+		if err := cf.persistProgress(ctx, resolved); err != nil {
+			log.Warningf(ctx, "unable to record changefeed progress: %s", err)
+		} else {
+			cf.persistedAt = timeutil.Now()
+		}
+	}
+	return nil
+}
+
+// persistProgress records in the feed's record that all the changes at or
+// below resolved have been emitted.
+func (cf *changeFeed) persistProgress(ctx context.Context, resolved hlc.Timestamp) error {
+	return cf.db.Txn(ctx, func(txn *client.Txn) error {
+		p := makeInternalPlanner("changefeed-progress", txn, security.RootUser, cf.memMetrics)
+		defer finishInternalPlanner(p)
+		const updateFeed = `UPDATE system.changefeeds SET wallTime = $1, logical = $2 WHERE id = $3`
+		_, err := p.exec(updateFeed, resolved.WallTime, int64(resolved.Logical), cf.id)
+		return err
+	})
+}
+
+// removeRecord removes the feed's record, so that it isn't resumed.
+func (cf *changeFeed) removeRecord(ctx context.Context) error {
+	return cf.db.Txn(ctx, func(txn *client.Txn) error {
+		p := makeInternalPlanner("changefeed-remove", txn, security.RootUser, cf.memMetrics)
+		defer finishInternalPlanner(p)
+		_, err := p.exec(`DELETE FROM system.changefeeds WHERE id = $1`, cf.id)
+		return err
+	})
+}
+
+// encodeRow returns the message for the change to the row at rowKey
+// at timestamp ts, reading the row in txn.
+func (cf *changeFeed) encodeRow(txn *client.Txn, rowKey roachpb.Key, ts hlc.Timestamp) ([]byte, error) {
+	span := roachpb.Span{Key: rowKey, EndKey: rowKey.PrefixEnd()}
+	if err := cf.rf.StartScan(txn, roachpb.Spans{span}, false /* limitBatches */, 0); err != nil {
+		return nil, err
+	}
+	row, err := cf.rf.NextRow()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := changeFeedMessage{Table: cf.tableDesc.Name, Updated: ts.String()}
+	index := &cf.tableDesc.PrimaryIndex
+	if row == nil {
+		// The row was deleted, so its primary key can only be recovered
+		// from its key.
+		if msg.Key, err = cf.decodePrimaryKey(rowKey); err != nil {
+			return nil, err
+		}
+	} else {
+		msg.Key = make([]interface{}, len(index.ColumnIDs))
+		for i, id := range index.ColumnIDs {
+			msg.Key[i] = datumToJSON(row[cf.colIdxMap[id]])
+		}
+		msg.Value = make(map[string]interface{}, len(cf.tableDesc.Columns))
+		for i, col := range cf.tableDesc.Columns {
+			msg.Value[col.Name] = datumToJSON(row[i])
+		}
+	}
+	return json.Marshal(msg)
+}
+
+func (cf *changeFeed) decodePrimaryKey(rowKey roachpb.Key) ([]interface{}, error) {
+	index := &cf.tableDesc.PrimaryIndex
+	colIDs, dirs := index.FullColumnIDs()
+	types, err := sqlbase.MakeKeyVals(cf.tableDesc, colIDs)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]parser.Datum, len(types))
+	if _, ok, err := sqlbase.DecodeIndexKey(
+		&cf.alloc, cf.tableDesc, index.ID, types, vals, dirs, rowKey,
+	); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.Errorf("key %s does not belong to table %q", rowKey, cf.tableDesc.Name)
+	}
+	key := make([]interface{}, len(vals))
+	for i, val := range vals {
+		key[i] = datumToJSON(val)
+	}
+	return key, nil
+}
+
+// datumToJSON returns the value d is encoded as in JSON messages.
+func datumToJSON(d parser.Datum) interface{} {
+	switch t := d.(type) {
+	case nil:
+		return nil
+	case *parser.DBool:
+		return bool(*t)
+	case *parser.DInt:
+		return int64(*t)
+	case *parser.DFloat:
+		return float64(*t)
+	case *parser.DDecimal:
+		return json.Number(t.Dec.String())
+	case *parser.DString:
+		return string(*t)
+	case *parser.DBytes:
+		return []byte(*t)
+	}
+	if d == parser.DNull {
+		return nil
+	}
+	return d.String()
+}
+
+// spanFrontier tracks the timestamp up to which a span has been
+// resolved, when parts of the span are resolved independently.
+type spanFrontier struct {
+	// entries partition the span, in key order.
+	entries []spanFrontierEntry
+}
+
+type spanFrontierEntry struct {
+	span roachpb.Span
+	ts   hlc.Timestamp
+}
+
+func makeSpanFrontier(span roachpb.Span, ts hlc.Timestamp) spanFrontier {
+	return spanFrontier{entries: []spanFrontierEntry{{span: span, ts: ts}}}
+}
+
+// Frontier returns the timestamp the whole span has been resolved to.
+func (f *spanFrontier) Frontier() hlc.Timestamp {
+	ts := f.entries[0].ts
+	for _, e := range f.entries[1:] {
+		if e.ts.Less(ts) {
+			ts = e.ts
+		}
+	}
+	return ts
+}
+
+// Forward marks the part of the tracked span overlapping span as
+// resolved to ts and returns the new frontier.
+func (f *spanFrontier) Forward(span roachpb.Span, ts hlc.Timestamp) hlc.Timestamp {
+	entries := make([]spanFrontierEntry, 0, len(f.entries)+2)
+	add := func(e spanFrontierEntry) {
+		// Coalesce adjacent entries with equal timestamps.
+		if n := len(entries); n > 0 && entries[n-1].ts == e.ts &&
+			entries[n-1].span.EndKey.Equal(e.span.Key) {
+			entries[n-1].span.EndKey = e.span.EndKey
+			return
+		}
+		entries = append(entries, e)
+	}
+	for _, e := range f.entries {
+		if !e.span.Overlaps(span) {
+			add(e)
+			continue
+		}
+		inner := e
+		if bytes.Compare(e.span.Key, span.Key) < 0 {
+			add(spanFrontierEntry{span: roachpb.Span{Key: e.span.Key, EndKey: span.Key}, ts: e.ts})
+			inner.span.Key = span.Key
+		}
+		var outer *spanFrontierEntry
+		if bytes.Compare(span.EndKey, e.span.EndKey) < 0 {
+			outer = &spanFrontierEntry{span: roachpb.Span{Key: span.EndKey, EndKey: e.span.EndKey}, ts: e.ts}
+			inner.span.EndKey = span.EndKey
+		}
+		inner.ts.Forward(ts)
+		add(inner)
+		if outer != nil {
+			add(*outer)
+		}
+	}
+	f.entries = entries
+	return f.Frontier()
+}
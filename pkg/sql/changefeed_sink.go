@@ -0,0 +1,133 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// changeFeedSink is the destination of the messages emitted by a
+// CHANGEFEED. Each message is a single line of JSON.
+type changeFeedSink interface {
+	// Emit writes a batch of messages to the sink.
+	Emit(ctx context.Context, msgs [][]byte) error
+	Close() error
+}
+
+// changeFeedHTTPTimeout bounds each request made to an HTTP sink, so that
+// an unresponsive endpoint fails the feed instead of stalling it.
+const changeFeedHTTPTimeout = time.Minute
+
+// makeChangeFeedSink returns the sink described by uri, which is either
+// a local file (file:///path/to/file) which messages are appended to or
+// an HTTP endpoint (http://host/path) which batches of messages are
+// POSTed to. Sinks are written to by the node running the feed, with its
+// privileges, which is why only the root user may create changefeeds.
+func makeChangeFeedSink(uri string) (changeFeedSink, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid changefeed sink %q", uri)
+	}
+	switch u.Scheme {
+	case "file":
+		if u.Host != "" {
+			return nil, errors.Errorf("file sink %q must not specify a host", uri)
+		}
+		if u.Path == "" {
+			return nil, errors.Errorf("file sink %q must specify a path", uri)
+		}
+		return &fileChangeFeedSink{path: u.Path}, nil
+	case "http", "https":
+		return &httpChangeFeedSink{url: u.String(), client: &http.Client{Timeout: changeFeedHTTPTimeout}}, nil
+	default:
+		return nil, errors.Errorf("unsupported changefeed sink scheme %q", u.Scheme)
+	}
+}
+
+// fileChangeFeedSink appends messages to a file on the local filesystem
+// of the node running the feed.
+type fileChangeFeedSink struct {
+	path string
+	f    *os.File
+}
+
+func (s *fileChangeFeedSink) Emit(_ context.Context, msgs [][]byte) error {
+	if s.f == nil {
+		f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		s.f = f
+	}
+	if _, err := s.f.Write(joinChangeFeedMessages(msgs)); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+func (s *fileChangeFeedSink) Close() error {
+	if s.f == nil {
+		return nil
+	}
+	return s.f.Close()
+}
+
+// httpChangeFeedSink POSTs each batch of messages, as newline-delimited
+// JSON, to an HTTP endpoint.
+type httpChangeFeedSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *httpChangeFeedSink) Emit(ctx context.Context, msgs [][]byte) error {
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(joinChangeFeedMessages(msgs)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("changefeed sink %s returned %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+func (*httpChangeFeedSink) Close() error {
+	return nil
+}
+
+func joinChangeFeedMessages(msgs [][]byte) []byte {
+	var buf bytes.Buffer
+	for _, msg := range msgs {
+		buf.Write(msg)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,243 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"bufio"
+	gosql "database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestChangeFeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanupFn := testutils.TempDir(t, 0)
+	defer cleanupFn()
+	sinkPath := filepath.Join(dir, "feed.json")
+
+	params, _ := createTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+
+	r := sqlutils.MakeSQLRunner(t, db)
+	r.Exec(`CREATE DATABASE d`)
+	r.Exec(`CREATE TABLE d.t (k INT PRIMARY KEY, v STRING)`)
+	r.Exec(fmt.Sprintf(`CHANGEFEED FOR TABLE d.t INTO 'file://%s'`, sinkPath))
+
+	r.Exec(`INSERT INTO d.t VALUES (1, 'a'), (2, 'b')`)
+	// The feed is re-established on both sides of the split.
+	r.Exec(`ALTER TABLE d.t SPLIT AT (2)`)
+	r.Exec(`UPDATE d.t SET v = 'c' WHERE k = 1`)
+	r.Exec(`DELETE FROM d.t WHERE k = 2`)
+
+	expected := []string{
+		`[1] map[k:1 v:a]`,
+		`[2] map[k:2 v:b]`,
+		`[1] map[k:1 v:c]`,
+		`[2] map[]`,
+	}
+	util.SucceedsSoon(t, func() error {
+		f, err := os.Open(sinkPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		var changes []string
+		for scanner := bufio.NewScanner(f); scanner.Scan(); {
+			var msg struct {
+				Table    string
+				Key      []interface{}
+				Value    map[string]interface{}
+				Resolved string
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+				// The sink may be in the middle of writing a message.
+				return err
+			}
+			if msg.Resolved != "" {
+				continue
+			}
+			if msg.Table != "t" {
+				t.Fatalf("unexpected table %q", msg.Table)
+			}
+			changes = append(changes, fmt.Sprint(msg.Key, " ", msg.Value))
+		}
+		if !reflect.DeepEqual(expected, changes) {
+			return errors.Errorf("expected changes %v, got %v", expected, changes)
+		}
+		return nil
+	})
+
+	// The feed can be listed and canceled.
+	var id int64
+	var table, sink, user string
+	var started time.Time
+	r.QueryRow(`SHOW CHANGEFEEDS`).Scan(&id, &table, &sink, &user, &started)
+	if table != "t" || sink != "file://"+sinkPath {
+		t.Fatalf("unexpected changefeed on table %q with sink %q", table, sink)
+	}
+	r.Exec(fmt.Sprintf(`CANCEL CHANGEFEED %d`, id))
+	rows := r.Query(`SHOW CHANGEFEEDS`)
+	if rows.Next() {
+		t.Fatal("expected no changefeeds after canceling the feed")
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(fmt.Sprintf(`CANCEL CHANGEFEED %d`, id)); !testutils.IsError(
+		err, "is not running",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestChangeFeedResume verifies that a changefeed records its progress,
+// and is resumed from it when its node restarts.
+func TestChangeFeedResume(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer sql.TestingSetChangeFeedProgressInterval(0)()
+
+	dir, cleanupFn := testutils.TempDir(t, 0)
+	defer cleanupFn()
+	sinkPath := filepath.Join(dir, "feed.json")
+
+	params, _ := createTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+
+	r := sqlutils.MakeSQLRunner(t, db)
+	r.Exec(`CREATE DATABASE d`)
+	r.Exec(`CREATE TABLE d.t (k INT PRIMARY KEY, v STRING)`)
+	r.Exec(fmt.Sprintf(`CHANGEFEED FOR TABLE d.t INTO 'file://%s'`, sinkPath))
+	var id, startWallTime int64
+	r.QueryRow(`SELECT id, wallTime FROM system.changefeeds`).Scan(&id, &startWallTime)
+
+	// waitForChange waits until the sink contains the given change.
+	waitForChange := func(expected string) {
+		util.SucceedsSoon(t, func() error {
+			f, err := os.Open(sinkPath)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			for scanner := bufio.NewScanner(f); scanner.Scan(); {
+				var msg struct {
+					Key   []interface{}
+					Value map[string]interface{}
+				}
+				if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+					return err
+				}
+				if fmt.Sprint(msg.Key, " ", msg.Value) == expected {
+					return nil
+				}
+			}
+			return errors.Errorf("change %s not emitted yet", expected)
+		})
+	}
+
+	r.Exec(`INSERT INTO d.t VALUES (1, 'a')`)
+	waitForChange(`[1] map[k:1 v:a]`)
+	util.SucceedsSoon(t, func() error {
+		var wallTime int64
+		r.QueryRow(`SELECT wallTime FROM system.changefeeds WHERE id = $1`, id).Scan(&wallTime)
+		if wallTime <= startWallTime {
+			return errors.Errorf("progress of changefeed %d not recorded yet", id)
+		}
+		return nil
+	})
+
+	// Stop the feed like the node shutting down would, and resume it like
+	// the node restarting would. The change made in the meantime is
+	// emitted by the resumed feed, which keeps its ID.
+	exec := s.(*server.TestServer).Executor()
+	exec.TestingStopChangeFeeds()
+	r.Exec(`INSERT INTO d.t VALUES (2, 'b')`)
+	if err := exec.ResumeChangeFeeds(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	waitForChange(`[2] map[k:2 v:b]`)
+	var resumedID int64
+	var table, sink, user string
+	var started time.Time
+	r.QueryRow(`SHOW CHANGEFEEDS`).Scan(&resumedID, &table, &sink, &user, &started)
+	if resumedID != id || table != "t" {
+		t.Fatalf("expected changefeed %d on table t, got %d on table %q", id, resumedID, table)
+	}
+
+	// Canceling the feed removes its record.
+	r.Exec(fmt.Sprintf(`CANCEL CHANGEFEED %d`, id))
+	var count int
+	r.QueryRow(`SELECT count(*) FROM system.changefeeds`).Scan(&count)
+	if count != 0 {
+		t.Fatalf("expected no changefeed records after canceling the feed, got %d", count)
+	}
+}
+
+// TestChangeFeedPrivileges verifies that only the root user may create,
+// list and cancel changefeeds, since their sinks are written to by the
+// node itself.
+func TestChangeFeedPrivileges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := createTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+
+	r := sqlutils.MakeSQLRunner(t, db)
+	r.Exec(`CREATE DATABASE d`)
+	r.Exec(`CREATE TABLE d.t (k INT PRIMARY KEY, v STRING)`)
+	r.Exec(`CREATE USER testuser`)
+	r.Exec(`GRANT SELECT ON TABLE d.t TO testuser`)
+
+	pgURL, cleanupFn := sqlutils.PGUrl(
+		t, s.ServingAddr(), "TestChangeFeedPrivileges", url.User(server.TestUser))
+	defer cleanupFn()
+	testUserDB, err := gosql.Open("postgres", pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testUserDB.Close()
+
+	for _, stmt := range []string{
+		`CHANGEFEED FOR TABLE d.t INTO 'file:///etc/passwd'`,
+		`CHANGEFEED FOR TABLE d.t INTO 'http://127.0.0.1:8080/_admin/v1/drain'`,
+		`SHOW CHANGEFEEDS`,
+		`CANCEL CHANGEFEED 1`,
+	} {
+		if _, err := testUserDB.Exec(stmt); !testutils.IsError(err, "only root is allowed") {
+			t.Errorf("%s: unexpected error: %v", stmt, err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+)
+
+// SetClusterSetting sets a cluster setting. The new value is written to
+// system.settings and is applied on every node once the system config is
+// gossiped.
+// Privileges: root user.
+func (p *planner) SetClusterSetting(n *parser.SetClusterSetting) (planNode, error) {
+	if p.session.User != security.RootUser {
+		return nil, fmt.Errorf("only %s is allowed to SET CLUSTER SETTING", security.RootUser)
+	}
+
+	name, err := clusterSettingName(n.Name)
+	if err != nil {
+		return nil, err
+	}
+	setting, ok := settings.Lookup(name)
+	if !ok {
+		return nil, errors.Errorf("unknown cluster setting %q", name)
+	}
+
+	if n.Value == nil {
+		// Removing the row resets the setting to its default on every node.
+		if _, err := p.exec(`DELETE FROM system.settings WHERE name = $1`, name); err != nil {
+			return nil, err
+		}
+		return &emptyNode{}, nil
+	}
+
+	raw, err := p.clusterSettingValue(setting, n.Value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cluster setting %q", name)
+	}
+	encoded, err := setting.Validate(raw)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cluster setting %q", name)
+	}
+	if _, err := p.exec(
+		`UPSERT INTO system.settings (name, value, "lastUpdated", "valueType") VALUES ($1, $2, NOW(), $3)`,
+		name, encoded, setting.Typ(),
+	); err != nil {
+		return nil, err
+	}
+	return &emptyNode{}, nil
+}
+
+// clusterSettingName returns the dotted name of a cluster setting. Unlike
+// VarName.String(), the parts are not quoted, so that setting names with parts
+// which happen to be keywords are still found.
+func clusterSettingName(n parser.VarName) (string, error) {
+	u, ok := n.(parser.UnresolvedName)
+	if !ok {
+		return "", errors.Errorf("invalid cluster setting name: %s", n)
+	}
+	parts := make([]string, len(u))
+	for i, part := range u {
+		name, ok := part.(parser.Name)
+		if !ok {
+			return "", errors.Errorf("invalid cluster setting name: %s", n)
+		}
+		parts[i] = name.Normalize()
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// clusterSettingValue evaluates expr and returns its value in the raw
+// string form accepted by the setting's Validate method. A string value is
+// accepted for any type of setting and is left to Validate to parse.
+func (p *planner) clusterSettingValue(setting settings.Setting, expr parser.Expr) (string, error) {
+	var desired parser.Type
+	switch setting.Typ() {
+	case "b":
+		desired = parser.TypeBool
+	case "i", "z":
+		desired = parser.TypeInt
+	case "f":
+		desired = parser.TypeFloat
+	case "d":
+		desired = parser.TypeInterval
+	default:
+		desired = parser.TypeString
+	}
+	typedExpr, err := parser.TypeCheck(expr, nil, desired)
+	if err != nil {
+		return "", err
+	}
+	d, err := typedExpr.Eval(&p.evalCtx)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := d.(type) {
+	case *parser.DString:
+		return string(*v), nil
+	case *parser.DBool:
+		if setting.Typ() == "b" {
+			return settings.EncodeBool(bool(*v)), nil
+		}
+	case *parser.DInt:
+		switch setting.Typ() {
+		case "i", "z":
+			return settings.EncodeInt(int64(*v)), nil
+		case "f":
+			return settings.EncodeFloat(float64(*v)), nil
+		}
+	case *parser.DFloat:
+		if setting.Typ() == "f" {
+			return settings.EncodeFloat(float64(*v)), nil
+		}
+	case *parser.DDecimal:
+		if setting.Typ() == "f" {
+			return v.Dec.String(), nil
+		}
+	case *parser.DInterval:
+		if setting.Typ() == "d" {
+			if v.Months != 0 {
+				return "", errors.Errorf("interval %s has a month component", v)
+			}
+			return settings.EncodeDuration(
+				time.Duration(v.Days)*24*time.Hour + time.Duration(v.Nanos)), nil
+		}
+	}
+	return "", errors.Errorf("value %s of type %s does not match the setting type %q",
+		d, d.ResolvedType(), setting.Typ())
+}
+
+// ShowClusterSetting shows the current value of one or all cluster settings
+// on this node.
+// Privileges: None.
+func (p *planner) ShowClusterSetting(n *parser.ShowClusterSetting) (planNode, error) {
+	var name string
+	var columns ResultColumns
+	if n.Name == nil {
+		columns = ResultColumns{
+			{Name: "name", Typ: parser.TypeString},
+			{Name: "current_value", Typ: parser.TypeString},
+			{Name: "type", Typ: parser.TypeString},
+			{Name: "description", Typ: parser.TypeString},
+		}
+	} else {
+		var err error
+		name, err = clusterSettingName(n.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := settings.Lookup(name); !ok {
+			return nil, errors.Errorf("unknown cluster setting %q", name)
+		}
+		columns = ResultColumns{{Name: name, Typ: parser.TypeString}}
+	}
+
+	return &delayedNode{
+		p:       p,
+		name:    n.String(),
+		columns: columns,
+		constructor: func(p *planner) (planNode, error) {
+			v := p.newContainerValuesNode(columns, 0)
+
+			var rows []parser.DTuple
+			if name == "" {
+				for _, k := range settings.Keys() {
+					s, _ := settings.Lookup(k)
+					rows = append(rows, parser.DTuple{
+						parser.NewDString(k),
+						parser.NewDString(s.String()),
+						parser.NewDString(s.Typ()),
+						parser.NewDString(s.Description()),
+					})
+				}
+			} else {
+				// The setting is guaranteed to exist thanks to the check above.
+				s, _ := settings.Lookup(name)
+				rows = append(rows, parser.DTuple{parser.NewDString(s.String())})
+			}
+			for _, row := range rows {
+				if err := v.rows.AddRow(row); err != nil {
+					v.rows.Close()
+					return nil, err
+				}
+			}
+			return v, nil
+		},
+	}, nil
+}
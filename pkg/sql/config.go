@@ -18,6 +18,7 @@ package sql
 
 import (
 	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
@@ -61,6 +62,24 @@ func GetZoneConfig(cfg config.SystemConfig, id uint32) (config.ZoneConfig, bool,
 	return config.ZoneConfig{}, false, nil
 }
 
+// getTableZoneConfig returns the zone config of the table as part of the
+// provided transaction. Like GetZoneConfig, it falls back to the zone config
+// of the table's database and then to the default zone config.
+func getTableZoneConfig(
+	txn *client.Txn, tableDesc *sqlbase.TableDescriptor,
+) (config.ZoneConfig, error) {
+	for _, id := range []sqlbase.ID{tableDesc.ID, tableDesc.ParentID, keys.RootNamespaceID} {
+		kv, err := txn.Get(sqlbase.MakeZoneKey(id))
+		if err != nil {
+			return config.ZoneConfig{}, err
+		}
+		if kv.Value != nil {
+			return config.MigrateZoneConfig(kv.Value)
+		}
+	}
+	return config.DefaultZoneConfig(), nil
+}
+
 // GetTableDesc returns the table descriptor for the table with 'id'.
 // Returns nil if the descriptor is not present, or is present but is not a
 // table.
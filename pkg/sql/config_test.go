@@ -17,6 +17,7 @@
 package sql_test
 
 import (
+	"reflect"
 	"testing"
 
 	"golang.org/x/net/context"
@@ -29,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/gogo/protobuf/proto"
@@ -189,7 +191,7 @@ func TestGetZoneConfig(t *testing.T) {
 	//   tb1: true
 	//   tb2: false
 	// db1: false
-	//   tb1: true
+	//   tb1: true, with a custom zone config for index 2
 	//   tb2: false
 	db1Cfg := config.ZoneConfig{
 		NumReplicas: 1,
@@ -203,6 +205,11 @@ func TestGetZoneConfig(t *testing.T) {
 		NumReplicas: 1,
 		Constraints: config.Constraints{Constraints: []config.Constraint{{Value: "db2.tb1"}}},
 	}
+	tb21IdxCfg := config.ZoneConfig{
+		NumReplicas: 1,
+		Constraints: config.Constraints{Constraints: []config.Constraint{{Value: "db2.tb1@idx"}}},
+	}
+	tb21Cfg.SetIndexZone(2, tb21IdxCfg)
 	for objID, objZone := range map[uint32]config.ZoneConfig{
 		db1:  db1Cfg,
 		tb11: tb11Cfg,
@@ -233,6 +240,9 @@ func TestGetZoneConfig(t *testing.T) {
 			{keys.MakeTablePrefix(tb11), tb11Cfg},
 			{keys.MakeTablePrefix(tb12), db1Cfg},
 			{keys.MakeTablePrefix(tb21), tb21Cfg},
+			{makeIndexKey(tb21, 1), tb21Cfg},
+			{makeIndexKey(tb21, 2), tb21IdxCfg},
+			{makeIndexKey(tb21, 3), tb21Cfg},
 			{keys.MakeTablePrefix(tb22), defaultZoneConfig},
 		}
 
@@ -246,5 +256,17 @@ func TestGetZoneConfig(t *testing.T) {
 				t.Errorf("#%d: bad zone config.\nexpected: %+v\ngot: %+v", tcNum, tc.zoneCfg, zoneCfg)
 			}
 		}
+
+		// The span of the index with a custom zone config is split off from
+		// the rest of its table.
+		splits := cfg.ComputeSplitKeys(keys.MakeTablePrefix(tb21), keys.MakeTablePrefix(tb22))
+		expected := []roachpb.RKey{makeIndexKey(tb21, 2), makeIndexKey(tb21, 3)}
+		if !reflect.DeepEqual(splits, expected) {
+			t.Errorf("expected splits %v, got %v", expected, splits)
+		}
 	}
 }
+
+func makeIndexKey(tableID uint32, indexID uint64) roachpb.RKey {
+	return encoding.EncodeUvarintAscending(keys.MakeTablePrefix(tableID), indexID)
+}
@@ -269,20 +269,23 @@ func (n *createUserNode) expandPlan() error {
 }
 
 func (n *createUserNode) Start() error {
-	hashedPassword, err := security.HashPassword(n.password)
+	normalizedUsername := n.n.Name.Normalize()
+
+	hashes, err := security.HashUserPassword(normalizedUsername, n.password)
 	if err != nil {
 		return err
 	}
 
-	normalizedUsername := n.n.Name.Normalize()
-
 	internalExecutor := InternalExecutor{LeaseManager: n.p.leaseMgr}
 	rowsAffected, err := internalExecutor.ExecuteStatementInTransaction(
 		"create-user",
 		n.p.txn,
-		"INSERT INTO system.users VALUES ($1, $2);",
+		`INSERT INTO system.users (username, hashedPassword, "md5Password", "scramVerifier") `+
+			`VALUES ($1, $2, $3, $4);`,
 		normalizedUsername,
-		hashedPassword,
+		hashes.Hashed,
+		nullIfEmpty(hashes.MD5),
+		nullIfEmpty(hashes.SCRAM),
 	)
 	if err != nil {
 		if _, ok := err.(*sqlbase.ErrUniquenessConstraintViolation); ok {
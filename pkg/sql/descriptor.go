@@ -73,24 +73,60 @@ var _ DescriptorAccessor = &planner{}
 func (p *planner) checkPrivilege(
 	descriptor sqlbase.DescriptorProto, privilege privilege.Kind,
 ) error {
-	if descriptor.GetPrivileges().CheckPrivilege(p.session.User, privilege) {
+	privs := descriptor.GetPrivileges()
+	if privs.CheckPrivilege(p.session.User, privilege) {
 		return nil
 	}
+	// Privileges granted to the roles of the user are inherited.
+	roles, err := p.getRoles()
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if privs.CheckPrivilege(role, privilege) {
+			return nil
+		}
+	}
 	return fmt.Errorf("user %s does not have %s privilege on %s %s",
 		p.session.User, privilege, descriptor.TypeName(), descriptor.GetName())
 }
 
 // anyPrivilege implements the DescriptorAccessor interface.
 func (p *planner) anyPrivilege(descriptor sqlbase.DescriptorProto) error {
-	if userCanSeeDescriptor(descriptor, p.session.User) {
+	users, err := p.getUserAndRoles()
+	if err != nil {
+		return err
+	}
+	if userCanSeeDescriptor(descriptor, users) {
 		return nil
 	}
 	return fmt.Errorf("user %s has no privileges on %s %s",
 		p.session.User, descriptor.TypeName(), descriptor.GetName())
 }
 
-func userCanSeeDescriptor(descriptor sqlbase.DescriptorProto, user string) bool {
-	return descriptor.GetPrivileges().AnyPrivilege(user) || isVirtualDescriptor(descriptor)
+// getUserAndRoles returns the current user followed by the roles it is a
+// member of.
+func (p *planner) getUserAndRoles() ([]string, error) {
+	roles, err := p.getRoles()
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{p.session.User}, roles...), nil
+}
+
+// userCanSeeDescriptor returns true if any of users, typically a user and its
+// roles, has a privilege on the descriptor.
+func userCanSeeDescriptor(descriptor sqlbase.DescriptorProto, users []string) bool {
+	if isVirtualDescriptor(descriptor) {
+		return true
+	}
+	privs := descriptor.GetPrivileges()
+	for _, user := range users {
+		if privs.AnyPrivilege(user) {
+			return true
+		}
+	}
+	return false
 }
 
 type descriptorAlreadyExistsErr struct {
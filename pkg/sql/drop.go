@@ -18,6 +18,7 @@ package sql
 
 import (
 	"fmt"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/pkg/errors"
 )
 
@@ -828,6 +830,7 @@ func (p *planner) initiateDropTable(tableDesc *sqlbase.TableDescriptor) error {
 		return err
 	}
 	tableDesc.State = sqlbase.TableDescriptor_DROP
+	tableDesc.DropTime = timeutil.Now().UnixNano()
 	if err := p.writeTableDesc(tableDesc); err != nil {
 		return err
 	}
@@ -948,12 +951,12 @@ func (p *planner) dropViewImpl(
 
 // truncateAndDropTable batches all the commands required for truncating and
 // deleting the table descriptor. It is called from a mutation, async wrt the
-// DROP statement. Before this method is called, the table has already been
-// marked for deletion and has been purged from the descriptor cache on all
-// nodes. No node is reading/writing data on the table at this stage,
-// therefore the entire table can be deleted with no concern for conflicts (we
-// can even eliminate the need to use a transaction for each chunk at a later
-// stage if it proves inefficient).
+// DROP statement, once the GC TTL of the table has passed. Before this method
+// is called, the table has already been marked for deletion and has been
+// purged from the descriptor cache on all nodes. No node is reading/writing
+// data on the table at this stage, therefore the entire table can be deleted
+// with no concern for conflicts (we can even eliminate the need to use a
+// transaction for each chunk at a later stage if it proves inefficient).
 func truncateAndDropTable(
 	ctx context.Context, tableDesc *sqlbase.TableDescriptor, db *client.DB,
 ) error {
@@ -963,18 +966,47 @@ func truncateAndDropTable(
 
 	// Finished deleting all the table data, now delete the table meta data.
 	return db.Txn(ctx, func(txn *client.Txn) error {
-		zoneKey, nameKey, descKey := getKeysForTableDescriptor(tableDesc)
+		zoneKey, _, descKey := getKeysForTableDescriptor(tableDesc)
+		txn.SetSystemConfigTrigger()
+		// The name of the table has usually been released already.
+		if err := releaseTableName(txn, tableDesc); err != nil {
+			return err
+		}
 		// Delete table descriptor
 		b := &client.Batch{}
 		b.Del(descKey)
-		b.Del(nameKey)
 		// Delete the zone config entry for this table.
 		b.Del(zoneKey)
-		txn.SetSystemConfigTrigger()
 		return txn.Run(b)
 	})
 }
 
+// releaseTableName deletes the namespace entry of the dropped table as part
+// of the provided transaction, unless the name has already been reused by
+// another table.
+func releaseTableName(txn *client.Txn, tableDesc *sqlbase.TableDescriptor) error {
+	_, nameKey, _ := getKeysForTableDescriptor(tableDesc)
+	gr, err := txn.Get(nameKey)
+	if err != nil {
+		return err
+	}
+	if !gr.Exists() || sqlbase.ID(gr.ValueInt()) != tableDesc.ID {
+		return nil
+	}
+	return txn.Del(nameKey)
+}
+
+// dropDeadline returns the time after which the data of the dropped table can
+// be deleted, i.e. once the GC TTL of the table's zone has passed since the
+// table was dropped. A GC TTL <= 0 allows the data to be deleted right away.
+func dropDeadline(txn *client.Txn, tableDesc *sqlbase.TableDescriptor) (time.Time, error) {
+	zone, err := getTableZoneConfig(txn, tableDesc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, tableDesc.DropTime).Add(time.Duration(zone.GC.TTLSeconds) * time.Second), nil
+}
+
 // removeMatchingReferences removes all refs from the provided slice that
 // match the provided ID, returning the modified slice.
 func removeMatchingReferences(
@@ -84,15 +84,10 @@ INSERT INTO t.kv VALUES ('c', 'e'), ('a', 'c'), ('b', 'd');
 	tbDesc := desc.GetTable()
 
 	// Add a zone config for both the table and database.
-	cfg := config.DefaultZoneConfig()
-	buf, err := protoutil.Marshal(&cfg)
-	if err != nil {
-		t.Fatal(err)
-	}
-	if _, err := sqlDB.Exec(`INSERT INTO system.zones VALUES ($1, $2)`, tbDesc.ID, buf); err != nil {
+	if _, err := addImmediateGCZoneConfig(sqlDB, tbDesc.ID); err != nil {
 		t.Fatal(err)
 	}
-	if _, err := sqlDB.Exec(`INSERT INTO system.zones VALUES ($1, $2)`, dbDesc.ID, buf); err != nil {
+	if _, err := addImmediateGCZoneConfig(sqlDB, dbDesc.ID); err != nil {
 		t.Fatal(err)
 	}
 
@@ -165,6 +160,19 @@ INSERT INTO t.kv VALUES ('c', 'e'), ('a', 'c'), ('b', 'd');
 	}
 }
 
+// addImmediateGCZoneConfig adds a zone config with a GC TTL of 0 for the
+// given ID, so that the data of a dropped table is deleted right away.
+func addImmediateGCZoneConfig(sqlDB *gosql.DB, id sqlbase.ID) (config.ZoneConfig, error) {
+	cfg := config.DefaultZoneConfig()
+	cfg.GC.TTLSeconds = 0
+	buf, err := protoutil.Marshal(&cfg)
+	if err != nil {
+		return cfg, err
+	}
+	_, err = sqlDB.Exec(`UPSERT INTO system.zones VALUES ($1, $2)`, id, buf)
+	return cfg, err
+}
+
 func checkKeyCount(t *testing.T, kvDB *client.DB, prefix roachpb.Key, numKeys int) {
 	if kvs, err := kvDB.Scan(context.TODO(), prefix, prefix.PrefixEnd(), 0); err != nil {
 		t.Fatal(err)
@@ -332,12 +340,7 @@ func TestDropTable(t *testing.T) {
 	descKey := sqlbase.MakeDescMetadataKey(sqlbase.ID(gr.ValueInt()))
 
 	// Add a zone config for the table.
-	cfg := config.DefaultZoneConfig()
-	buf, err := protoutil.Marshal(&cfg)
-	if err != nil {
-		t.Fatal(err)
-	}
-	if _, err := sqlDB.Exec(`INSERT INTO system.zones VALUES ($1, $2)`, tableDesc.ID, buf); err != nil {
+	if _, err := addImmediateGCZoneConfig(sqlDB, tableDesc.ID); err != nil {
 		t.Fatal(err)
 	}
 
@@ -394,6 +397,10 @@ func TestDropTableInterleaved(t *testing.T) {
 
 	tableDesc := sqlbase.GetTableDescriptor(kvDB, "t", "kv")
 	tablePrefix := roachpb.Key(keys.MakeTablePrefix(uint32(tableDesc.ID)))
+	intlvDesc := sqlbase.GetTableDescriptor(kvDB, "t", "intlv")
+	if _, err := addImmediateGCZoneConfig(sqlDB, intlvDesc.ID); err != nil {
+		t.Fatal(err)
+	}
 
 	checkKeyCount(t, kvDB, tablePrefix, 3*numRows)
 	if _, err := sqlDB.Exec(`DROP TABLE t.intlv`); err != nil {
@@ -410,6 +417,69 @@ func TestDropTableInterleaved(t *testing.T) {
 	}
 }
 
+// TestUndropTable tests that the data of a dropped table is kept until the GC
+// TTL of the table has passed, and that the table can be restored until then.
+func TestUndropTable(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	// Disable the async schema changer so that the GC TTL of the dropped table
+	// can be changed without the table being truncated.
+	params.Knobs.SQLSchemaChanger = &sql.SchemaChangerTestingKnobs{
+		AsyncExecNotification: asyncSchemaChangerDisabled,
+	}
+	s, sqlDB, kvDB := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+
+	numRows := 10
+	createKVTable(t, sqlDB, numRows)
+
+	tableDesc := sqlbase.GetTableDescriptor(kvDB, "t", "kv")
+	tablePrefix := roachpb.Key(keys.MakeTablePrefix(uint32(tableDesc.ID)))
+
+	checkKeyCount(t, kvDB, tablePrefix, 3*numRows)
+	if _, err := sqlDB.Exec(`DROP TABLE t.kv`); err != nil {
+		t.Fatal(err)
+	}
+	// The data is kept, but the name of the table can be reused right away.
+	checkKeyCount(t, kvDB, tablePrefix, 3*numRows)
+	if _, err := sqlDB.Exec(`SELECT * FROM t.kv`); !testutils.IsError(err, `table "t.kv" does not exist`) {
+		t.Fatalf("different error than expected: %v", err)
+	}
+	if _, err := sqlDB.Exec(`CREATE TABLE t.kv (a INT PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`UNDROP TABLE t.kv`); !testutils.IsError(err, `relation "kv" already exists`) {
+		t.Fatalf("different error than expected: %v", err)
+	}
+	if _, err := sqlDB.Exec(`ALTER TABLE t.kv RENAME TO t.kv2`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sqlDB.Exec(`UNDROP TABLE t.kv`); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := sqlDB.QueryRow(`SELECT COUNT(*) FROM t.kv@foo`).Scan(&count); err != nil {
+		t.Fatal(err)
+	} else if count != numRows {
+		t.Fatalf("expected %d rows, but got %d", numRows, count)
+	}
+	if _, err := sqlDB.Exec(`UNDROP TABLE t.kv2`); !testutils.IsError(err, `no dropped table "t.kv2"`) {
+		t.Fatalf("different error than expected: %v", err)
+	}
+
+	// Once the GC TTL has passed, the table can't be restored anymore.
+	if _, err := sqlDB.Exec(`DROP TABLE t.kv`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := addImmediateGCZoneConfig(sqlDB, tableDesc.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`UNDROP TABLE t.kv`); !testutils.IsError(err, `GC TTL of dropped table "t.kv" has expired`) {
+		t.Fatalf("different error than expected: %v", err)
+	}
+}
+
 func TestDropTableInTxn(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	params, _ := createTestServerParams()
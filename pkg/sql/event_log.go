@@ -42,6 +42,8 @@ const (
 	EventLogCreateTable EventLogType = "create_table"
 	// EventLogDropTable is recorded when a table is dropped.
 	EventLogDropTable EventLogType = "drop_table"
+	// EventLogUndropTable is recorded when a dropped table is restored.
+	EventLogUndropTable EventLogType = "undrop_table"
 	// EventLogAlterTable is recorded when a table is altered.
 	EventLogAlterTable EventLogType = "alter_table"
 
@@ -67,6 +69,11 @@ const (
 	// EventLogNodeRestart is recorded when an existing node rejoins the cluster
 	// after being offline.
 	EventLogNodeRestart EventLogType = "node_restart"
+
+	// EventLogRemoveInconsistentReplica is recorded when a replica which
+	// failed a consistency check is removed from its range. The event is
+	// recorded by the storage package.
+	EventLogRemoveInconsistentReplica EventLogType = "remove_inconsistent_replica"
 )
 
 // An EventLogger exposes methods used to record events to the event table.
@@ -186,6 +186,7 @@ type Executor struct {
 	// System Config and mutex.
 	systemConfig   config.SystemConfig
 	databaseCache  *databaseCache
+	roleCache      *roleCache
 	systemConfigMu syncutil.RWMutex
 	// This uses systemConfigMu in RLocker mode to not block
 	// execution of statements. So don't go on changing state after you've
@@ -205,11 +206,19 @@ type ExecutorConfig struct {
 	LeaseManager *LeaseManager
 	Clock        *hlc.Clock
 	DistSQLSrv   *distsql.ServerImpl
+	// RangeFeeder establishes the RangeFeeds backing CHANGEFEEDs.
+	RangeFeeder RangeFeeder
 
 	TestingKnobs              *ExecutorTestingKnobs
 	SchemaChangerTestingKnobs *SchemaChangerTestingKnobs
 	// MetricsSampleInterval is (server.Context).MetricsSampleInterval.
 	MetricsSampleInterval time.Duration
+
+	// stopper is the Stopper passed to NewExecutor. It runs the tasks
+	// which outlive the statements that start them, like CHANGEFEEDs.
+	stopper *stop.Stopper
+	// changeFeeds tracks the CHANGEFEEDs running on this node.
+	changeFeeds *changeFeedRegistry
 }
 
 var _ base.ModuleTestingKnobs = &ExecutorTestingKnobs{}
@@ -256,6 +265,8 @@ type ExecutorTestingKnobs struct {
 func NewExecutor(
 	cfg ExecutorConfig, stopper *stop.Stopper, startupMemMetrics *MemoryMetrics,
 ) *Executor {
+	cfg.stopper = stopper
+	cfg.changeFeeds = &changeFeedRegistry{}
 	exec := &Executor{
 		cfg:     cfg,
 		reCache: parser.NewRegexpCache(512),
@@ -318,19 +329,21 @@ func (e *Executor) updateSystemConfig(cfg config.SystemConfig) {
 	e.systemConfigMu.Lock()
 	defer e.systemConfigMu.Unlock()
 	e.systemConfig = cfg
-	// The database cache gets reset whenever the system config changes.
+	// The database and role caches get reset whenever the system config
+	// changes.
 	e.databaseCache = &databaseCache{
 		databases: map[string]sqlbase.ID{},
 	}
+	e.roleCache = newRoleCache()
 	e.systemConfigCond.Broadcast()
 }
 
 // getSystemConfig returns a copy of the latest system config.
-func (e *Executor) getSystemConfig() (config.SystemConfig, *databaseCache) {
+func (e *Executor) getSystemConfig() (config.SystemConfig, *databaseCache, *roleCache) {
 	e.systemConfigMu.RLock()
 	defer e.systemConfigMu.RUnlock()
-	cfg, cache := e.systemConfig, e.databaseCache
-	return cfg, cache
+	cfg, cache, roles := e.systemConfig, e.databaseCache, e.roleCache
+	return cfg, cache, roles
 }
 
 // Prepare returns the result types of the given statement. pinfo may
@@ -1042,13 +1055,17 @@ func (e *Executor) execStmtInOpenTxn(
 	}
 
 	autoCommit := implicitTxn && !e.cfg.TestingKnobs.DisableAutoCommit
+	planMaker.auditEvents = nil
 	result, err := e.execStmt(stmt, planMaker, autoCommit)
+	if len(planMaker.auditEvents) > 0 {
+		planMaker.logAuditEvents(stmt, result, err)
+	}
 	if err != nil {
 		if result.Rows != nil {
 			result.Rows.Close()
 			result.Rows = nil
 		}
-		if traceSQL {
+		if traceSQL() {
 			log.ErrEventf(txnState.txn.Context, "ERROR: %v", err)
 		}
 		log.ErrEventf(session.context, "ERROR: %v", err)
@@ -1063,7 +1080,7 @@ func (e *Executor) execStmtInOpenTxn(
 	case parser.Rows:
 		tResult.count = result.Rows.Len()
 	}
-	if traceSQL {
+	if traceSQL() {
 		log.Eventf(txnState.txn.Context, "%s done", tResult)
 	}
 	log.Eventf(session.context, "%s done", tResult)
@@ -19,6 +19,7 @@ package sql
 import (
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
@@ -98,3 +99,30 @@ func (m *LeaseManager) ExpireLeases(clock *hlc.Clock) {
 	}
 	m.tableNames.mu.Unlock()
 }
+
+// TestingSetProtectedTimestampPollInterval sets the interval at which the
+// protected timestamp records are read, and returns a function which
+// restores it.
+func TestingSetProtectedTimestampPollInterval(d time.Duration) func() {
+	return settings.TestingSetDuration(&protectedTimestampPollInterval, d)
+}
+
+// TestingSetChangeFeedProgressInterval sets the minimum interval at which
+// changefeeds record their progress, returning a function which restores
+// it.
+func TestingSetChangeFeedProgressInterval(d time.Duration) func() {
+	prev := changeFeedProgressInterval
+	changeFeedProgressInterval = d
+	return func() {
+		changeFeedProgressInterval = prev
+	}
+}
+
+// TestingStopChangeFeeds stops the changefeeds running on the executor's
+// node like the node shutting down would, leaving their records in
+// system.changefeeds.
+func (e *Executor) TestingStopChangeFeeds() {
+	for _, info := range e.cfg.changeFeeds.list() {
+		e.cfg.changeFeeds.cancel(info.id)
+	}
+}
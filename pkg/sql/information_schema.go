@@ -533,9 +533,13 @@ func forEachDatabaseDesc(p *planner, fn func(*sqlbase.DatabaseDescriptor) error)
 		dbDescs = append(dbDescs, schema.desc)
 	}
 
+	users, err := p.getUserAndRoles()
+	if err != nil {
+		return err
+	}
 	sort.Sort(sortedDBDescs(dbDescs))
 	for _, db := range dbDescs {
-		if userCanSeeDatabase(db, p.session.User) {
+		if userCanSeeDatabase(db, users) {
 			if err := fn(db); err != nil {
 				return err
 			}
@@ -643,6 +647,10 @@ func forEachTableDescWithTableLookup(
 		dbNames = append(dbNames, dbName)
 	}
 	sort.Strings(dbNames)
+	users, err := p.getUserAndRoles()
+	if err != nil {
+		return err
+	}
 	for _, dbName := range dbNames {
 		db := databases[dbName]
 		dbTableNames := make([]string, 0, len(db.tables))
@@ -652,7 +660,7 @@ func forEachTableDescWithTableLookup(
 		sort.Strings(dbTableNames)
 		for _, tableName := range dbTableNames {
 			tableDesc := db.tables[tableName]
-			if userCanSeeTable(tableDesc, p.session.User) {
+			if userCanSeeTable(tableDesc, users) {
 				if err := fn(db.desc, tableDesc, tableLookup); err != nil {
 					return err
 				}
@@ -711,8 +719,8 @@ func forEachColumnInIndex(
 	return nil
 }
 
-func forEachUser(p *planner, fn func(username string) error) error {
-	query := `SELECT username FROM system.users`
+func forEachUser(p *planner, fn func(username string, isRole bool) error) error {
+	query := `SELECT username, "isRole" FROM system.users`
 	plan, err := p.query(query)
 	if err != nil {
 		return nil
@@ -724,7 +732,7 @@ func forEachUser(p *planner, fn func(username string) error) error {
 
 	// TODO(cuongdo/asubiotto): Get rid of root user special-casing if/when a row
 	// for "root" exists in system.user.
-	if err := fn(security.RootUser); err != nil {
+	if err := fn(security.RootUser, false); err != nil {
 		return err
 	}
 
@@ -738,17 +746,18 @@ func forEachUser(p *planner, fn func(username string) error) error {
 		}
 		row := plan.Values()
 		username := row[0].(*parser.DString)
-		if err := fn(string(*username)); err != nil {
+		isRole := row[1].(*parser.DBool)
+		if err := fn(string(*username), bool(*isRole)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func userCanSeeDatabase(db *sqlbase.DatabaseDescriptor, user string) bool {
-	return userCanSeeDescriptor(db, user)
+func userCanSeeDatabase(db *sqlbase.DatabaseDescriptor, users []string) bool {
+	return userCanSeeDescriptor(db, users)
 }
 
-func userCanSeeTable(table *sqlbase.TableDescriptor, user string) bool {
-	return userCanSeeDescriptor(table, user) && table.State == sqlbase.TableDescriptor_PUBLIC
+func userCanSeeTable(table *sqlbase.TableDescriptor, users []string) bool {
+	return userCanSeeDescriptor(table, users) && table.State == sqlbase.TableDescriptor_PUBLIC
 }
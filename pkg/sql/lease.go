@@ -772,6 +772,10 @@ func (t *tableState) purgeOldLeases(
 	db *client.DB, deleted bool, minVersion sqlbase.DescriptorVersion, store LeaseStore,
 ) error {
 	t.mu.Lock()
+	if !deleted {
+		// The table may have been brought back using UNDROP TABLE.
+		t.deleted = false
+	}
 	empty := len(t.active.data) == 0
 	t.mu.Unlock()
 	if empty {
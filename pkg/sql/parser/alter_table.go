@@ -65,6 +65,7 @@ func (*AlterTableAddConstraint) alterTableCmd()      {}
 func (*AlterTableDropColumn) alterTableCmd()         {}
 func (*AlterTableDropConstraint) alterTableCmd()     {}
 func (*AlterTableDropNotNull) alterTableCmd()        {}
+func (*AlterTableSetAudit) alterTableCmd()           {}
 func (*AlterTableSetDefault) alterTableCmd()         {}
 func (*AlterTableValidateConstraint) alterTableCmd() {}
 
@@ -73,6 +74,7 @@ var _ AlterTableCmd = &AlterTableAddConstraint{}
 var _ AlterTableCmd = &AlterTableDropColumn{}
 var _ AlterTableCmd = &AlterTableDropConstraint{}
 var _ AlterTableCmd = &AlterTableDropNotNull{}
+var _ AlterTableCmd = &AlterTableSetAudit{}
 var _ AlterTableCmd = &AlterTableSetDefault{}
 var _ AlterTableCmd = &AlterTableValidateConstraint{}
 
@@ -229,3 +231,36 @@ func (node *AlterTableDropNotNull) Format(buf *bytes.Buffer, f FmtFlags) {
 	FormatNode(buf, f, node.Column)
 	buf.WriteString(" DROP NOT NULL")
 }
+
+// AuditMode represents the audit setting of a table.
+type AuditMode int
+
+const (
+	// AuditModeDisable turns off auditing.
+	AuditModeDisable AuditMode = iota
+	// AuditModeReadWrite enables auditing of reads and writes.
+	AuditModeReadWrite
+)
+
+// String implements the fmt.Stringer interface.
+func (m AuditMode) String() string {
+	switch m {
+	case AuditModeDisable:
+		return "OFF"
+	case AuditModeReadWrite:
+		return "READ WRITE"
+	default:
+		return fmt.Sprintf("AuditMode(%d)", int(m))
+	}
+}
+
+// AlterTableSetAudit represents an EXPERIMENTAL_AUDIT SET command.
+type AlterTableSetAudit struct {
+	Mode AuditMode
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableSetAudit) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("EXPERIMENTAL_AUDIT SET ")
+	buf.WriteString(node.Mode.String())
+}
@@ -1264,6 +1264,36 @@ var Builtins = map[string][]Builtin{
 		},
 	},
 
+	"current_user": {
+		Builtin{
+			Types:      ArgTypes{},
+			ReturnType: TypeString,
+			category:   categorySystemInfo,
+			fn: func(ctx *EvalContext, args DTuple) (Datum, error) {
+				if len(ctx.User) == 0 {
+					return DNull, nil
+				}
+				return NewDString(ctx.User), nil
+			},
+		},
+	},
+
+	// Roles are not assumed by a session, so the session user is always the
+	// current user.
+	"session_user": {
+		Builtin{
+			Types:      ArgTypes{},
+			ReturnType: TypeString,
+			category:   categorySystemInfo,
+			fn: func(ctx *EvalContext, args DTuple) (Datum, error) {
+				if len(ctx.User) == 0 {
+					return DNull, nil
+				}
+				return NewDString(ctx.User), nil
+			},
+		},
+	},
+
 	// For now, schemas are the same as databases. So, current_schemas returns the
 	// current database (if one has been set by the user) and, if the passed in
 	// parameter is true, the session's database search path.
@@ -0,0 +1,53 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+
+package parser
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ChangeFeed represents a CHANGEFEED statement.
+type ChangeFeed struct {
+	Table NormalizableTableName
+	Sink  string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ChangeFeed) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CHANGEFEED FOR TABLE ")
+	FormatNode(buf, f, node.Table)
+	buf.WriteString(" INTO ")
+	encodeSQLString(buf, node.Sink)
+}
+
+// ShowChangeFeeds represents a SHOW CHANGEFEEDS statement.
+type ShowChangeFeeds struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowChangeFeeds) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW CHANGEFEEDS")
+}
+
+// CancelChangeFeed represents a CANCEL CHANGEFEED statement.
+type CancelChangeFeed struct {
+	ID int64
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CancelChangeFeed) Format(buf *bytes.Buffer, f FmtFlags) {
+	fmt.Fprintf(buf, "CANCEL CHANGEFEED %d", node.ID)
+}
@@ -616,6 +616,17 @@ func (node *CreateTable) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// CreateRole represents a CREATE ROLE statement.
+type CreateRole struct {
+	Name Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateRole) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("CREATE ROLE ")
+	FormatNode(buf, f, node.Name)
+}
+
 // CreateUser represents a CREATE USER statement.
 type CreateUser struct {
 	Name     Name
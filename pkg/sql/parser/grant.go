@@ -61,3 +61,17 @@ func (node *Grant) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString(" TO ")
 	FormatNode(buf, f, node.Grantees)
 }
+
+// GrantRole represents a GRANT <role> statement.
+type GrantRole struct {
+	Roles   NameList
+	Members NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GrantRole) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("GRANT ")
+	FormatNode(buf, f, node.Roles)
+	buf.WriteString(" TO ")
+	FormatNode(buf, f, node.Members)
+}
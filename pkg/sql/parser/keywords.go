@@ -3,263 +3,274 @@
 package parser
 
 var keywords = map[string]int{
-	"ACTION":            ACTION,
-	"ADD":               ADD,
-	"ALL":               ALL,
-	"ALTER":             ALTER,
-	"ANALYSE":           ANALYSE,
-	"ANALYZE":           ANALYZE,
-	"AND":               AND,
-	"ANNOTATE_TYPE":     ANNOTATE_TYPE,
-	"ANY":               ANY,
-	"ARRAY":             ARRAY,
-	"AS":                AS,
-	"ASC":               ASC,
-	"ASYMMETRIC":        ASYMMETRIC,
-	"AT":                AT,
-	"BEGIN":             BEGIN,
-	"BETWEEN":           BETWEEN,
-	"BIGINT":            BIGINT,
-	"BIGSERIAL":         BIGSERIAL,
-	"BIT":               BIT,
-	"BLOB":              BLOB,
-	"BOOL":              BOOL,
-	"BOOLEAN":           BOOLEAN,
-	"BOTH":              BOTH,
-	"BY":                BY,
-	"BYTEA":             BYTEA,
-	"BYTES":             BYTES,
-	"CASCADE":           CASCADE,
-	"CASE":              CASE,
-	"CAST":              CAST,
-	"CHAR":              CHAR,
-	"CHARACTER":         CHARACTER,
-	"CHARACTERISTICS":   CHARACTERISTICS,
-	"CHECK":             CHECK,
-	"COALESCE":          COALESCE,
-	"COLLATE":           COLLATE,
-	"COLLATION":         COLLATION,
-	"COLUMN":            COLUMN,
-	"COLUMNS":           COLUMNS,
-	"COMMIT":            COMMIT,
-	"COMMITTED":         COMMITTED,
-	"CONFLICT":          CONFLICT,
-	"CONSTRAINT":        CONSTRAINT,
-	"CONSTRAINTS":       CONSTRAINTS,
-	"COPY":              COPY,
-	"COVERING":          COVERING,
-	"CREATE":            CREATE,
-	"CROSS":             CROSS,
-	"CUBE":              CUBE,
-	"CURRENT":           CURRENT,
-	"CURRENT_CATALOG":   CURRENT_CATALOG,
-	"CURRENT_DATE":      CURRENT_DATE,
-	"CURRENT_ROLE":      CURRENT_ROLE,
-	"CURRENT_TIME":      CURRENT_TIME,
-	"CURRENT_TIMESTAMP": CURRENT_TIMESTAMP,
-	"CURRENT_USER":      CURRENT_USER,
-	"CYCLE":             CYCLE,
-	"DATA":              DATA,
-	"DATABASE":          DATABASE,
-	"DATABASES":         DATABASES,
-	"DATE":              DATE,
-	"DAY":               DAY,
-	"DEALLOCATE":        DEALLOCATE,
-	"DEC":               DEC,
-	"DECIMAL":           DECIMAL,
-	"DEFAULT":           DEFAULT,
-	"DEFERRABLE":        DEFERRABLE,
-	"DELETE":            DELETE,
-	"DESC":              DESC,
-	"DISTINCT":          DISTINCT,
-	"DO":                DO,
-	"DOUBLE":            DOUBLE,
-	"DROP":              DROP,
-	"ELSE":              ELSE,
-	"ENCODING":          ENCODING,
-	"END":               END,
-	"EXCEPT":            EXCEPT,
-	"EXECUTE":           EXECUTE,
-	"EXISTS":            EXISTS,
-	"EXPLAIN":           EXPLAIN,
-	"EXTRACT":           EXTRACT,
-	"EXTRACT_DURATION":  EXTRACT_DURATION,
-	"FALSE":             FALSE,
-	"FAMILY":            FAMILY,
-	"FETCH":             FETCH,
-	"FILTER":            FILTER,
-	"FIRST":             FIRST,
-	"FLOAT":             FLOAT,
-	"FOLLOWING":         FOLLOWING,
-	"FOR":               FOR,
-	"FORCE_INDEX":       FORCE_INDEX,
-	"FOREIGN":           FOREIGN,
-	"FROM":              FROM,
-	"FULL":              FULL,
-	"GRANT":             GRANT,
-	"GRANTS":            GRANTS,
-	"GREATEST":          GREATEST,
-	"GROUP":             GROUP,
-	"GROUPING":          GROUPING,
-	"HAVING":            HAVING,
-	"HELP":              HELP,
-	"HIGH":              HIGH,
-	"HOUR":              HOUR,
-	"IF":                IF,
-	"IFNULL":            IFNULL,
-	"ILIKE":             ILIKE,
-	"IN":                IN,
-	"INDEX":             INDEX,
-	"INDEXES":           INDEXES,
-	"INITIALLY":         INITIALLY,
-	"INNER":             INNER,
-	"INSERT":            INSERT,
-	"INT":               INT,
-	"INT64":             INT64,
-	"INT8":              INT8,
-	"INTEGER":           INTEGER,
-	"INTERLEAVE":        INTERLEAVE,
-	"INTERSECT":         INTERSECT,
-	"INTERVAL":          INTERVAL,
-	"INTO":              INTO,
-	"IS":                IS,
-	"ISOLATION":         ISOLATION,
-	"JOIN":              JOIN,
-	"KEY":               KEY,
-	"KEYS":              KEYS,
-	"LATERAL":           LATERAL,
-	"LEADING":           LEADING,
-	"LEAST":             LEAST,
-	"LEFT":              LEFT,
-	"LEVEL":             LEVEL,
-	"LIKE":              LIKE,
-	"LIMIT":             LIMIT,
-	"LOCAL":             LOCAL,
-	"LOCALTIME":         LOCALTIME,
-	"LOCALTIMESTAMP":    LOCALTIMESTAMP,
-	"LOW":               LOW,
-	"MATCH":             MATCH,
-	"MINUTE":            MINUTE,
-	"MONTH":             MONTH,
-	"NAME":              NAME,
-	"NAMES":             NAMES,
-	"NATURAL":           NATURAL,
-	"NEXT":              NEXT,
-	"NO":                NO,
-	"NORMAL":            NORMAL,
-	"NOT":               NOT,
-	"NOTHING":           NOTHING,
-	"NO_INDEX_JOIN":     NO_INDEX_JOIN,
-	"NULL":              NULL,
-	"NULLIF":            NULLIF,
-	"NULLS":             NULLS,
-	"NUMERIC":           NUMERIC,
-	"OF":                OF,
-	"OFF":               OFF,
-	"OFFSET":            OFFSET,
-	"ON":                ON,
-	"ONLY":              ONLY,
-	"OR":                OR,
-	"ORDER":             ORDER,
-	"ORDINALITY":        ORDINALITY,
-	"OUT":               OUT,
-	"OUTER":             OUTER,
-	"OVER":              OVER,
-	"OVERLAPS":          OVERLAPS,
-	"OVERLAY":           OVERLAY,
-	"PARENT":            PARENT,
-	"PARTIAL":           PARTIAL,
-	"PARTITION":         PARTITION,
-	"PASSWORD":          PASSWORD,
-	"PLACING":           PLACING,
-	"POSITION":          POSITION,
-	"PRECEDING":         PRECEDING,
-	"PRECISION":         PRECISION,
-	"PREPARE":           PREPARE,
-	"PRIMARY":           PRIMARY,
-	"PRIORITY":          PRIORITY,
-	"RANGE":             RANGE,
-	"READ":              READ,
-	"REAL":              REAL,
-	"RECURSIVE":         RECURSIVE,
-	"REF":               REF,
-	"REFERENCES":        REFERENCES,
-	"RELEASE":           RELEASE,
-	"RENAME":            RENAME,
-	"REPEATABLE":        REPEATABLE,
-	"RESTRICT":          RESTRICT,
-	"RETURNING":         RETURNING,
-	"REVOKE":            REVOKE,
-	"RIGHT":             RIGHT,
-	"ROLLBACK":          ROLLBACK,
-	"ROLLUP":            ROLLUP,
-	"ROW":               ROW,
-	"ROWS":              ROWS,
-	"SAVEPOINT":         SAVEPOINT,
-	"SEARCH":            SEARCH,
-	"SECOND":            SECOND,
-	"SELECT":            SELECT,
-	"SERIAL":            SERIAL,
-	"SERIALIZABLE":      SERIALIZABLE,
-	"SESSION":           SESSION,
-	"SESSION_USER":      SESSION_USER,
-	"SET":               SET,
-	"SHOW":              SHOW,
-	"SIMILAR":           SIMILAR,
-	"SIMPLE":            SIMPLE,
-	"SMALLINT":          SMALLINT,
-	"SMALLSERIAL":       SMALLSERIAL,
-	"SNAPSHOT":          SNAPSHOT,
-	"SOME":              SOME,
-	"SPLIT":             SPLIT,
-	"SQL":               SQL,
-	"START":             START,
-	"STDIN":             STDIN,
-	"STORING":           STORING,
-	"STRICT":            STRICT,
-	"STRING":            STRING,
-	"SUBSTRING":         SUBSTRING,
-	"SYMMETRIC":         SYMMETRIC,
-	"SYSTEM":            SYSTEM,
-	"TABLE":             TABLE,
-	"TABLES":            TABLES,
-	"TEXT":              TEXT,
-	"THEN":              THEN,
-	"TIME":              TIME,
-	"TIMESTAMP":         TIMESTAMP,
-	"TIMESTAMPTZ":       TIMESTAMPTZ,
-	"TO":                TO,
-	"TRAILING":          TRAILING,
-	"TRANSACTION":       TRANSACTION,
-	"TREAT":             TREAT,
-	"TRIM":              TRIM,
-	"TRUE":              TRUE,
-	"TRUNCATE":          TRUNCATE,
-	"TYPE":              TYPE,
-	"UNBOUNDED":         UNBOUNDED,
-	"UNCOMMITTED":       UNCOMMITTED,
-	"UNION":             UNION,
-	"UNIQUE":            UNIQUE,
-	"UNKNOWN":           UNKNOWN,
-	"UPDATE":            UPDATE,
-	"UPSERT":            UPSERT,
-	"USER":              USER,
-	"USERS":             USERS,
-	"USING":             USING,
-	"VALID":             VALID,
-	"VALIDATE":          VALIDATE,
-	"VALUE":             VALUE,
-	"VALUES":            VALUES,
-	"VARCHAR":           VARCHAR,
-	"VARIADIC":          VARIADIC,
-	"VARYING":           VARYING,
-	"VIEW":              VIEW,
-	"WHEN":              WHEN,
-	"WHERE":             WHERE,
-	"WINDOW":            WINDOW,
-	"WITH":              WITH,
-	"WITHIN":            WITHIN,
-	"WITHOUT":           WITHOUT,
-	"YEAR":              YEAR,
-	"ZONE":              ZONE,
+	"ACTION":             ACTION,
+	"ADD":                ADD,
+	"ALL":                ALL,
+	"ALTER":              ALTER,
+	"ANALYSE":            ANALYSE,
+	"ANALYZE":            ANALYZE,
+	"AND":                AND,
+	"ANNOTATE_TYPE":      ANNOTATE_TYPE,
+	"ANY":                ANY,
+	"ARRAY":              ARRAY,
+	"AS":                 AS,
+	"ASC":                ASC,
+	"ASYMMETRIC":         ASYMMETRIC,
+	"AT":                 AT,
+	"BEGIN":              BEGIN,
+	"BETWEEN":            BETWEEN,
+	"BIGINT":             BIGINT,
+	"BIGSERIAL":          BIGSERIAL,
+	"BIT":                BIT,
+	"BLOB":               BLOB,
+	"BOOL":               BOOL,
+	"BOOLEAN":            BOOLEAN,
+	"BOTH":               BOTH,
+	"BY":                 BY,
+	"BYTEA":              BYTEA,
+	"BYTES":              BYTES,
+	"CANCEL":             CANCEL,
+	"CASCADE":            CASCADE,
+	"CASE":               CASE,
+	"CAST":               CAST,
+	"CHANGEFEED":         CHANGEFEED,
+	"CHANGEFEEDS":        CHANGEFEEDS,
+	"CHAR":               CHAR,
+	"CHARACTER":          CHARACTER,
+	"CHARACTERISTICS":    CHARACTERISTICS,
+	"CHECK":              CHECK,
+	"CLUSTER":            CLUSTER,
+	"COALESCE":           COALESCE,
+	"COLLATE":            COLLATE,
+	"COLLATION":          COLLATION,
+	"COLUMN":             COLUMN,
+	"COLUMNS":            COLUMNS,
+	"COMMIT":             COMMIT,
+	"COMMITTED":          COMMITTED,
+	"CONFLICT":           CONFLICT,
+	"CONSTRAINT":         CONSTRAINT,
+	"CONSTRAINTS":        CONSTRAINTS,
+	"COPY":               COPY,
+	"COVERING":           COVERING,
+	"CREATE":             CREATE,
+	"CROSS":              CROSS,
+	"CUBE":               CUBE,
+	"CURRENT":            CURRENT,
+	"CURRENT_CATALOG":    CURRENT_CATALOG,
+	"CURRENT_DATE":       CURRENT_DATE,
+	"CURRENT_ROLE":       CURRENT_ROLE,
+	"CURRENT_TIME":       CURRENT_TIME,
+	"CURRENT_TIMESTAMP":  CURRENT_TIMESTAMP,
+	"CURRENT_USER":       CURRENT_USER,
+	"CYCLE":              CYCLE,
+	"DATA":               DATA,
+	"DATABASE":           DATABASE,
+	"DATABASES":          DATABASES,
+	"DATE":               DATE,
+	"DAY":                DAY,
+	"DEALLOCATE":         DEALLOCATE,
+	"DEC":                DEC,
+	"DECIMAL":            DECIMAL,
+	"DEFAULT":            DEFAULT,
+	"DEFERRABLE":         DEFERRABLE,
+	"DELETE":             DELETE,
+	"DESC":               DESC,
+	"DISTINCT":           DISTINCT,
+	"DO":                 DO,
+	"DOUBLE":             DOUBLE,
+	"DROP":               DROP,
+	"ELSE":               ELSE,
+	"ENCODING":           ENCODING,
+	"END":                END,
+	"EXCEPT":             EXCEPT,
+	"EXECUTE":            EXECUTE,
+	"EXISTS":             EXISTS,
+	"EXPERIMENTAL_AUDIT": EXPERIMENTAL_AUDIT,
+	"EXPLAIN":            EXPLAIN,
+	"EXTRACT":            EXTRACT,
+	"EXTRACT_DURATION":   EXTRACT_DURATION,
+	"FALSE":              FALSE,
+	"FAMILY":             FAMILY,
+	"FETCH":              FETCH,
+	"FILTER":             FILTER,
+	"FIRST":              FIRST,
+	"FLOAT":              FLOAT,
+	"FOLLOWING":          FOLLOWING,
+	"FOR":                FOR,
+	"FORCE_INDEX":        FORCE_INDEX,
+	"FOREIGN":            FOREIGN,
+	"FROM":               FROM,
+	"FULL":               FULL,
+	"GRANT":              GRANT,
+	"GRANTS":             GRANTS,
+	"GREATEST":           GREATEST,
+	"GROUP":              GROUP,
+	"GROUPING":           GROUPING,
+	"HAVING":             HAVING,
+	"HELP":               HELP,
+	"HIGH":               HIGH,
+	"HOUR":               HOUR,
+	"IF":                 IF,
+	"IFNULL":             IFNULL,
+	"ILIKE":              ILIKE,
+	"IN":                 IN,
+	"INDEX":              INDEX,
+	"INDEXES":            INDEXES,
+	"INITIALLY":          INITIALLY,
+	"INNER":              INNER,
+	"INSERT":             INSERT,
+	"INT":                INT,
+	"INT64":              INT64,
+	"INT8":               INT8,
+	"INTEGER":            INTEGER,
+	"INTERLEAVE":         INTERLEAVE,
+	"INTERSECT":          INTERSECT,
+	"INTERVAL":           INTERVAL,
+	"INTO":               INTO,
+	"IS":                 IS,
+	"ISOLATION":          ISOLATION,
+	"JOIN":               JOIN,
+	"KEY":                KEY,
+	"KEYS":               KEYS,
+	"LATERAL":            LATERAL,
+	"LEADING":            LEADING,
+	"LEAST":              LEAST,
+	"LEFT":               LEFT,
+	"LEVEL":              LEVEL,
+	"LIKE":               LIKE,
+	"LIMIT":              LIMIT,
+	"LOCAL":              LOCAL,
+	"LOCALTIME":          LOCALTIME,
+	"LOCALTIMESTAMP":     LOCALTIMESTAMP,
+	"LOW":                LOW,
+	"MATCH":              MATCH,
+	"MINUTE":             MINUTE,
+	"MONTH":              MONTH,
+	"NAME":               NAME,
+	"NAMES":              NAMES,
+	"NATURAL":            NATURAL,
+	"NEXT":               NEXT,
+	"NO":                 NO,
+	"NORMAL":             NORMAL,
+	"NOT":                NOT,
+	"NOTHING":            NOTHING,
+	"NO_INDEX_JOIN":      NO_INDEX_JOIN,
+	"NULL":               NULL,
+	"NULLIF":             NULLIF,
+	"NULLS":              NULLS,
+	"NUMERIC":            NUMERIC,
+	"OF":                 OF,
+	"OFF":                OFF,
+	"OFFSET":             OFFSET,
+	"ON":                 ON,
+	"ONLY":               ONLY,
+	"OR":                 OR,
+	"ORDER":              ORDER,
+	"ORDINALITY":         ORDINALITY,
+	"OUT":                OUT,
+	"OUTER":              OUTER,
+	"OVER":               OVER,
+	"OVERLAPS":           OVERLAPS,
+	"OVERLAY":            OVERLAY,
+	"PARENT":             PARENT,
+	"PARTIAL":            PARTIAL,
+	"PARTITION":          PARTITION,
+	"PASSWORD":           PASSWORD,
+	"PLACING":            PLACING,
+	"POSITION":           POSITION,
+	"PRECEDING":          PRECEDING,
+	"PRECISION":          PRECISION,
+	"PREPARE":            PREPARE,
+	"PRIMARY":            PRIMARY,
+	"PRIORITY":           PRIORITY,
+	"RANGE":              RANGE,
+	"READ":               READ,
+	"REAL":               REAL,
+	"RECURSIVE":          RECURSIVE,
+	"REF":                REF,
+	"REFERENCES":         REFERENCES,
+	"RELEASE":            RELEASE,
+	"RENAME":             RENAME,
+	"REPEATABLE":         REPEATABLE,
+	"RESTRICT":           RESTRICT,
+	"RETURNING":          RETURNING,
+	"REVOKE":             REVOKE,
+	"RIGHT":              RIGHT,
+	"ROLE":               ROLE,
+	"ROLES":              ROLES,
+	"ROLLBACK":           ROLLBACK,
+	"ROLLUP":             ROLLUP,
+	"ROW":                ROW,
+	"ROWS":               ROWS,
+	"SAVEPOINT":          SAVEPOINT,
+	"SEARCH":             SEARCH,
+	"SECOND":             SECOND,
+	"SELECT":             SELECT,
+	"SERIAL":             SERIAL,
+	"SERIALIZABLE":       SERIALIZABLE,
+	"SESSION":            SESSION,
+	"SESSION_USER":       SESSION_USER,
+	"SET":                SET,
+	"SETTING":            SETTING,
+	"SETTINGS":           SETTINGS,
+	"SHOW":               SHOW,
+	"SIMILAR":            SIMILAR,
+	"SIMPLE":             SIMPLE,
+	"SMALLINT":           SMALLINT,
+	"SMALLSERIAL":        SMALLSERIAL,
+	"SNAPSHOT":           SNAPSHOT,
+	"SOME":               SOME,
+	"SPLIT":              SPLIT,
+	"SQL":                SQL,
+	"START":              START,
+	"STDIN":              STDIN,
+	"STORING":            STORING,
+	"STRICT":             STRICT,
+	"STRING":             STRING,
+	"SUBSTRING":          SUBSTRING,
+	"SYMMETRIC":          SYMMETRIC,
+	"SYSTEM":             SYSTEM,
+	"TABLE":              TABLE,
+	"TABLES":             TABLES,
+	"TEXT":               TEXT,
+	"THEN":               THEN,
+	"TIME":               TIME,
+	"TIMESTAMP":          TIMESTAMP,
+	"TIMESTAMPTZ":        TIMESTAMPTZ,
+	"TO":                 TO,
+	"TRAILING":           TRAILING,
+	"TRANSACTION":        TRANSACTION,
+	"TREAT":              TREAT,
+	"TRIM":               TRIM,
+	"TRUE":               TRUE,
+	"TRUNCATE":           TRUNCATE,
+	"TYPE":               TYPE,
+	"UNBOUNDED":          UNBOUNDED,
+	"UNCOMMITTED":        UNCOMMITTED,
+	"UNDROP":             UNDROP,
+	"UNION":              UNION,
+	"UNIQUE":             UNIQUE,
+	"UNKNOWN":            UNKNOWN,
+	"UPDATE":             UPDATE,
+	"UPSERT":             UPSERT,
+	"USER":               USER,
+	"USERS":              USERS,
+	"USING":              USING,
+	"VALID":              VALID,
+	"VALIDATE":           VALIDATE,
+	"VALUE":              VALUE,
+	"VALUES":             VALUES,
+	"VARCHAR":            VARCHAR,
+	"VARIADIC":           VARIADIC,
+	"VARYING":            VARYING,
+	"VIEW":               VIEW,
+	"WHEN":               WHEN,
+	"WHERE":              WHERE,
+	"WINDOW":             WINDOW,
+	"WITH":               WITH,
+	"WITHIN":             WITHIN,
+	"WITHOUT":            WITHOUT,
+	"WRITE":              WRITE,
+	"YEAR":               YEAR,
+	"ZONE":               ZONE,
 }
@@ -49,6 +49,7 @@ func TestParse(t *testing.T) {
 		{"SAVEPOINT foo"},
 
 		{`CREATE DATABASE a`},
+		{`CREATE ROLE a`},
 		{`CREATE DATABASE a ENCODING='UTF8'`},
 		{`CREATE DATABASE IF NOT EXISTS a`},
 		{`CREATE DATABASE IF NOT EXISTS a ENCODING='UTF8'`},
@@ -153,6 +154,9 @@ func TestParse(t *testing.T) {
 
 		{`DROP DATABASE a`},
 		{`DROP DATABASE IF EXISTS a`},
+		{`DROP ROLE a`},
+		{`DROP ROLE a, b`},
+		{`DROP ROLE IF EXISTS a`},
 		{`DROP TABLE a`},
 		{`DROP TABLE a.b`},
 		{`DROP TABLE a, b`},
@@ -165,6 +169,8 @@ func TestParse(t *testing.T) {
 		{`DROP TABLE a.b CASCADE`},
 		{`DROP TABLE a, b CASCADE`},
 		{`DROP TABLE IF EXISTS a CASCADE`},
+		{`UNDROP TABLE a`},
+		{`UNDROP TABLE a.b`},
 		{`DROP INDEX a.b@c`},
 		{`DROP INDEX a`},
 		{`DROP INDEX a.b`},
@@ -205,6 +211,9 @@ func TestParse(t *testing.T) {
 		{`SHOW CONSTRAINTS FROM a.b.c`},
 		{`SHOW TABLES FROM a; SHOW COLUMNS FROM b`},
 		{`SHOW USERS`},
+		{`SHOW ROLES`},
+		{`SHOW ALL CLUSTER SETTINGS`},
+		{`SHOW CLUSTER SETTING a.b`},
 
 		// Tables are the default, but can also be specified with
 		// GRANT x ON TABLE y. However, the stringer does not output TABLE.
@@ -214,6 +223,9 @@ func TestParse(t *testing.T) {
 		{`SHOW GRANTS ON DATABASE foo, bar`},
 		{`SHOW GRANTS ON DATABASE foo FOR bar`},
 		{`SHOW GRANTS FOR bar, baz`},
+		{`SHOW GRANTS ON ROLE foo`},
+		{`SHOW GRANTS ON ROLE foo, bar FOR baz`},
+		{`SHOW GRANTS ON role`},
 
 		{`SHOW TRANSACTION ISOLATION LEVEL`},
 		{`SHOW TRANSACTION PRIORITY`},
@@ -242,6 +254,8 @@ func TestParse(t *testing.T) {
 		{`GRANT SELECT, INSERT ON DATABASE bar TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO "test-user"`},
+		{`GRANT foo TO bar`},
+		{`GRANT foo, bar TO baz, "test-user"`},
 
 		// Tables are the default, but can also be specified with
 		// REVOKE x ON TABLE y. However, the stringer does not output TABLE.
@@ -251,6 +265,8 @@ func TestParse(t *testing.T) {
 		{`REVOKE ALL ON DATABASE foo FROM root, test`},
 		{`REVOKE SELECT, INSERT ON DATABASE bar FROM foo, bar, baz`},
 		{`REVOKE SELECT, INSERT ON DATABASE db1, db2 FROM foo, bar, baz`},
+		{`REVOKE foo FROM bar`},
+		{`REVOKE foo, bar FROM baz, "test-user"`},
 
 		{`INSERT INTO a VALUES (1)`},
 		{`INSERT INTO a.b VALUES (1)`},
@@ -501,6 +517,10 @@ func TestParse(t *testing.T) {
 		{`SET TIME ZONE -7.3`},
 		{`SET TIME ZONE DEFAULT`},
 		{`SET TIME ZONE LOCAL`},
+		{`SET CLUSTER SETTING a = 3`},
+		{`SET CLUSTER SETTING a.b.c = 'foo'`},
+		{`SET CLUSTER SETTING a = true`},
+		{`SET CLUSTER SETTING a = DEFAULT`},
 
 		{`SELECT * FROM (VALUES (1, 2)) AS foo`},
 		{`SELECT * FROM (VALUES (1, 2)) AS foo (a, b)`},
@@ -562,6 +582,8 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a DROP CONSTRAINT b CASCADE`},
 		{`ALTER TABLE a DROP CONSTRAINT IF EXISTS b RESTRICT`},
 		{`ALTER TABLE a VALIDATE CONSTRAINT a`},
+		{`ALTER TABLE a EXPERIMENTAL_AUDIT SET READ WRITE`},
+		{`ALTER TABLE a EXPERIMENTAL_AUDIT SET OFF`},
 
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT 42`},
 		{`ALTER TABLE a ALTER COLUMN b SET DEFAULT NULL`},
@@ -572,6 +594,11 @@ func TestParse(t *testing.T) {
 		{`COPY t FROM STDIN`},
 		{`COPY t (a, b, c) FROM STDIN`},
 
+		{`CHANGEFEED FOR TABLE t INTO 'file:///tmp/t.json'`},
+		{`CHANGEFEED FOR TABLE d.t INTO 'http://localhost:8000/feed'`},
+		{`SHOW CHANGEFEEDS`},
+		{`CANCEL CHANGEFEED 1`},
+
 		{`ALTER TABLE a SPLIT AT (1)`},
 		{`ALTER TABLE d.a SPLIT AT ('b', 2)`},
 		{`ALTER INDEX a@i SPLIT AT (1)`},
@@ -677,6 +704,17 @@ func TestParse2(t *testing.T) {
 			`SELECT "CURRENT_TIMESTAMP"()`},
 		{`SELECT CURRENT_DATE`,
 			`SELECT "CURRENT_DATE"()`},
+		{`SELECT CURRENT_USER`,
+			`SELECT "current_user"()`},
+		{`SELECT CURRENT_ROLE`,
+			`SELECT "current_user"()`},
+		{`SELECT SESSION_USER`,
+			`SELECT "session_user"()`},
+		{`SELECT USER`,
+			`SELECT "current_user"()`},
+		// Privileges are also parsed as role names.
+		{`GRANT select, insert ON foo TO bar`,
+			`GRANT SELECT, INSERT ON foo TO bar`},
 		{`SELECT POSITION(a IN b)`,
 			`SELECT STRPOS(b, a)`},
 		{`SELECT TRIM(BOTH a FROM b)`,
@@ -723,6 +761,10 @@ func TestParse2(t *testing.T) {
 			`SET TIME ZONE 'Europe/Rome'`},
 		{`SET TIME ZONE INTERVAL '-7h'`,
 			`SET TIME ZONE INTERVAL '-7h0m0s'`},
+		{`SET CLUSTER SETTING a TO 'b'`,
+			`SET CLUSTER SETTING a = 'b'`},
+		{`SET CLUSTER SETTING a TO DEFAULT`,
+			`SET CLUSTER SETTING a = DEFAULT`},
 		// Special substring syntax
 		{`SELECT SUBSTRING('RoacH' from 2 for 3)`,
 			`SELECT SUBSTRING('RoacH', 2, 3)`},
@@ -862,6 +904,10 @@ b = 2
 		{`SET TIME ZONE INTERVAL 'foobar'`, `could not parse 'foobar' as type interval: time: invalid duration foobar at or near "EOF"
 SET TIME ZONE INTERVAL 'foobar'
                                ^
+`},
+		{`GRANT foo ON bar TO baz`, `not a valid privilege: "foo" at or near "ON"
+GRANT foo ON bar TO baz
+          ^
 `},
 		{`SELECT 1 /* hello`, `unterminated comment
 SELECT 1 /* hello
@@ -45,3 +45,17 @@ func (node *Revoke) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString(" FROM ")
 	FormatNode(buf, f, node.Grantees)
 }
+
+// RevokeRole represents a REVOKE <role> statement.
+type RevokeRole struct {
+	Roles   NameList
+	Members NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RevokeRole) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("REVOKE ")
+	FormatNode(buf, f, node.Roles)
+	buf.WriteString(" FROM ")
+	FormatNode(buf, f, node.Members)
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"go/constant"
 	"go/token"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -557,6 +558,17 @@ func (s *Scanner) scanIdent(lval *sqlSymType, ch int) {
 	lval.id = IDENT
 }
 
+// Keywords returns the SQL keywords recognized by the scanner, in upper
+// case and sorted.
+func Keywords() []string {
+	result := make([]string, 0, len(keywords))
+	for k := range keywords {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
 func (s *Scanner) scanNumber(lval *sqlSymType, ch int) {
 	start := s.pos - 1
 	isHex := false
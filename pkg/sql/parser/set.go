@@ -44,6 +44,25 @@ func (node *Set) Format(buf *bytes.Buffer, f FmtFlags) {
 	}
 }
 
+// SetClusterSetting represents a SET CLUSTER SETTING statement. A nil
+// Value resets the setting to its default.
+type SetClusterSetting struct {
+	Name  VarName
+	Value Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *SetClusterSetting) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SET CLUSTER SETTING ")
+	FormatNode(buf, f, node.Name)
+	buf.WriteString(" = ")
+	if node.Value == nil {
+		buf.WriteString("DEFAULT")
+	} else {
+		FormatNode(buf, f, node.Value)
+	}
+}
+
 // SetTransaction represents a SET TRANSACTION statement.
 type SetTransaction struct {
 	Isolation    IsolationLevel
@@ -35,6 +35,22 @@ func (node *Show) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString(node.Name)
 }
 
+// ShowClusterSetting represents a SHOW CLUSTER SETTING statement, or a
+// SHOW ALL CLUSTER SETTINGS statement if Name is nil.
+type ShowClusterSetting struct {
+	Name VarName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowClusterSetting) Format(buf *bytes.Buffer, f FmtFlags) {
+	if node.Name == nil {
+		buf.WriteString("SHOW ALL CLUSTER SETTINGS")
+		return
+	}
+	buf.WriteString("SHOW CLUSTER SETTING ")
+	FormatNode(buf, f, node.Name)
+}
+
 // ShowColumns represents a SHOW COLUMNS statement.
 type ShowColumns struct {
 	Table NormalizableTableName
@@ -145,6 +161,31 @@ func (node *ShowUsers) Format(buf *bytes.Buffer, f FmtFlags) {
 	buf.WriteString("SHOW USERS")
 }
 
+// ShowRoles represents a SHOW ROLES statement.
+type ShowRoles struct {
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowRoles) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW ROLES")
+}
+
+// ShowRoleGrants represents a SHOW GRANTS ON ROLE statement.
+type ShowRoleGrants struct {
+	Roles    NameList
+	Grantees NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowRoleGrants) Format(buf *bytes.Buffer, f FmtFlags) {
+	buf.WriteString("SHOW GRANTS ON ROLE ")
+	FormatNode(buf, f, node.Roles)
+	if node.Grantees != nil {
+		buf.WriteString(" FOR ")
+		FormatNode(buf, f, node.Grantees)
+	}
+}
+
 // Help represents a HELP statement.
 type Help struct {
 	Name Name
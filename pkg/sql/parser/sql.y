@@ -240,9 +240,6 @@ func (u *sqlSymUnion) targetList() TargetList {
 func (u *sqlSymUnion) targetListPtr() *TargetList {
     return u.val.(*TargetList)
 }
-func (u *sqlSymUnion) privilegeType() privilege.Kind {
-    return u.val.(privilege.Kind)
-}
 func (u *sqlSymUnion) privilegeList() privilege.List {
     return u.val.(privilege.List)
 }
@@ -288,6 +285,9 @@ func (u *sqlSymUnion) dropBehavior() DropBehavior {
 func (u *sqlSymUnion) validationBehavior() ValidationBehavior {
     return u.val.(ValidationBehavior)
 }
+func (u *sqlSymUnion) auditMode() AuditMode {
+    return u.val.(AuditMode)
+}
 func (u *sqlSymUnion) interleave() *InterleaveDef {
     return u.val.(*InterleaveDef)
 }
@@ -313,12 +313,14 @@ func (u *sqlSymUnion) window() Window {
 %type <Statement> stmt
 
 %type <Statement> alter_table_stmt
+%type <Statement> changefeed_stmt
 %type <Statement> copy_from_stmt
 %type <Statement> create_stmt
 %type <Statement> create_database_stmt
 %type <Statement> create_index_stmt
 %type <Statement> create_table_stmt
 %type <Statement> create_table_as_stmt
+%type <Statement> create_role_stmt
 %type <Statement> create_user_stmt
 %type <Statement> create_view_stmt
 %type <Statement> delete_stmt
@@ -342,6 +344,7 @@ func (u *sqlSymUnion) window() Window {
 %type <Statement> split_stmt
 %type <Statement> transaction_stmt
 %type <Statement> truncate_stmt
+%type <Statement> undrop_stmt
 %type <Statement> update_stmt
 
 %type <*Select> select_no_parens
@@ -360,6 +363,7 @@ func (u *sqlSymUnion) window() Window {
 %type <DropBehavior> opt_interleave_drop_behavior
 
 %type <ValidationBehavior> opt_validate_behavior
+%type <AuditMode> audit_mode
 
 %type <*StrVal> opt_encoding_clause
 
@@ -533,8 +537,9 @@ func (u *sqlSymUnion) window() Window {
 %type <TargetList>    privilege_target
 %type <*TargetList> on_privilege_target_clause
 %type <NameList>       grantee_list for_grantee_clause
-%type <privilege.List> privileges privilege_list
-%type <privilege.Kind> privilege
+%type <privilege.List> privileges
+%type <NameList> privilege_list
+%type <str> privilege
 
 // Non-keyword token types. These are hard-wired into the "flex" lexer. They
 // must be listed first so that their numeric codes do not depend on the set of
@@ -564,8 +569,8 @@ func (u *sqlSymUnion) window() Window {
 %token <str>   BEGIN BETWEEN BIGINT BIGSERIAL BIT
 %token <str>   BLOB BOOL BOOLEAN BOTH BY BYTEA BYTES
 
-%token <str>   CASCADE CASE CAST CHAR
-%token <str>   CHARACTER CHARACTERISTICS CHECK
+%token <str>   CANCEL CASCADE CASE CAST CHANGEFEED CHANGEFEEDS CHAR
+%token <str>   CHARACTER CHARACTERISTICS CHECK CLUSTER
 %token <str>   COALESCE COLLATE COLLATION COLUMN COLUMNS COMMIT
 %token <str>   COMMITTED CONCAT CONFLICT CONSTRAINT CONSTRAINTS
 %token <str>   COPY COVERING CREATE
@@ -578,7 +583,7 @@ func (u *sqlSymUnion) window() Window {
 %token <str>   DISTINCT DO DOUBLE DROP
 
 %token <str>   ELSE ENCODING END ESCAPE EXCEPT
-%token <str>   EXISTS EXECUTE EXPLAIN EXTRACT EXTRACT_DURATION
+%token <str>   EXISTS EXECUTE EXPERIMENTAL_AUDIT EXPLAIN EXTRACT EXTRACT_DURATION
 
 %token <str>   FALSE FAMILY FETCH FILTER FIRST FLOAT FLOORDIV FOLLOWING FOR
 %token <str>   FORCE_INDEX FOREIGN FROM FULL
@@ -614,11 +619,11 @@ func (u *sqlSymUnion) window() Window {
 
 %token <str>   RANGE READ REAL RECURSIVE REF REFERENCES
 %token <str>   RENAME REPEATABLE
-%token <str>   RELEASE RESTRICT RETURNING REVOKE RIGHT ROLLBACK ROLLUP
+%token <str>   RELEASE RESTRICT RETURNING REVOKE RIGHT ROLE ROLES ROLLBACK ROLLUP
 %token <str>   ROW ROWS RSHIFT
 
 %token <str>   SAVEPOINT SEARCH SECOND SELECT
-%token <str>   SERIAL SERIALIZABLE SESSION SESSION_USER SET SHOW
+%token <str>   SERIAL SERIALIZABLE SESSION SESSION_USER SET SETTING SETTINGS SHOW
 %token <str>   SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 %token <str>   START STDIN STRICT STRING STORING SUBSTRING
 %token <str>   SYMMETRIC SYSTEM
@@ -627,12 +632,12 @@ func (u *sqlSymUnion) window() Window {
 %token <str>   TIME TIMESTAMP TIMESTAMPTZ TO TRAILING TRANSACTION TREAT TRIM TRUE
 %token <str>   TRUNCATE TYPE
 
-%token <str>   UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN
+%token <str>   UNBOUNDED UNCOMMITTED UNDROP UNION UNIQUE UNKNOWN
 %token <str>   UPDATE UPSERT USER USERS USING
 
 %token <str>   VALID VALIDATE VALUE VALUES VARCHAR VARIADIC VIEW VARYING
 
-%token <str>   WHEN WHERE WINDOW WITH WITHIN WITHOUT
+%token <str>   WHEN WHERE WINDOW WITH WITHIN WITHOUT WRITE
 
 %token <str>   YEAR
 
@@ -736,6 +741,7 @@ stmt_list:
 
 stmt:
   alter_table_stmt
+| changefeed_stmt
 | copy_from_stmt
 | create_stmt
 | delete_stmt
@@ -760,6 +766,7 @@ stmt:
 | transaction_stmt
 | release_stmt
 | truncate_stmt
+| undrop_stmt
 | update_stmt
 | /* EMPTY */
   {
@@ -877,6 +884,21 @@ alter_table_cmd:
       DropBehavior: $4.dropBehavior(),
     }
   }
+  // ALTER TABLE <name> EXPERIMENTAL_AUDIT SET <mode>
+| EXPERIMENTAL_AUDIT SET audit_mode
+  {
+    $$.val = &AlterTableSetAudit{Mode: $3.auditMode()}
+  }
+
+audit_mode:
+  READ WRITE
+  {
+    $$.val = AuditModeReadWrite
+  }
+| OFF
+  {
+    $$.val = AuditModeDisable
+  }
 
 alter_column_default:
   SET DEFAULT a_expr
@@ -920,6 +942,23 @@ alter_using:
   USING a_expr { return unimplemented(sqllex) }
 | /* EMPTY */ {}
 
+// CHANGEFEED FOR TABLE relname INTO 'sink'
+// CANCEL CHANGEFEED id
+changefeed_stmt:
+  CHANGEFEED FOR TABLE qualified_name INTO SCONST
+  {
+    $$.val = &ChangeFeed{Table: $4.normalizableTableName(), Sink: $6}
+  }
+| CANCEL CHANGEFEED ICONST
+  {
+    id, err := $3.numVal().asInt64()
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = &CancelChangeFeed{ID: id}
+  }
+
 copy_from_stmt:
   COPY qualified_name FROM STDIN
   {
@@ -940,6 +979,7 @@ create_stmt:
 | create_index_stmt
 | create_table_stmt
 | create_table_as_stmt
+| create_role_stmt
 | create_user_stmt
 | create_view_stmt
 
@@ -992,6 +1032,14 @@ drop_stmt:
   {
     $$.val = &DropView{Names: $5.tableNameReferences(), IfExists: true, DropBehavior: $6.dropBehavior()}
   }
+| DROP ROLE name_list
+  {
+    $$.val = &DropRole{Names: $3.nameList(), IfExists: false}
+  }
+| DROP ROLE IF EXISTS name_list
+  {
+    $$.val = &DropRole{Names: $5.nameList(), IfExists: true}
+  }
 
 table_name_list:
   any_name
@@ -1139,18 +1187,28 @@ deallocate_stmt:
   }
 
 // GRANT privileges ON privilege_target TO grantee_list
+// GRANT role_list TO grantee_list
 grant_stmt:
   GRANT privileges ON privilege_target TO grantee_list
   {
     $$.val = &Grant{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| GRANT privilege_list TO grantee_list
+  {
+    $$.val = &GrantRole{Roles: $2.nameList(), Members: $4.nameList()}
+  }
 
 // REVOKE privileges ON privilege_target FROM grantee_list
+// REVOKE role_list FROM grantee_list
 revoke_stmt:
   REVOKE privileges ON privilege_target FROM grantee_list
   {
     $$.val = &Revoke{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| REVOKE privilege_list FROM grantee_list
+  {
+    $$.val = &RevokeRole{Roles: $2.nameList(), Members: $4.nameList()}
+  }
 
 
 privilege_target:
@@ -1173,48 +1231,36 @@ privileges:
   {
     $$.val = privilege.List{privilege.ALL}
   }
-  | privilege_list { }
+  | privilege_list
+  {
+    privList, err := privilege.ListFromStrings($1.nameList().ToStrings())
+    if err != nil {
+      sqllex.Error(err.Error())
+      return 1
+    }
+    $$.val = privList
+  }
 
+// A privilege_list is also used as a list of role names in GRANT and
+// REVOKE, so its elements are only checked to be valid privileges once
+// it is known to be used as such.
 privilege_list:
   privilege
   {
-    $$.val = privilege.List{$1.privilegeType()}
+    $$.val = NameList{Name($1)}
   }
   | privilege_list ',' privilege
   {
-    $$.val = append($1.privilegeList(), $3.privilegeType())
+    $$.val = append($1.nameList(), Name($3))
   }
 
-// This list must match the list of privileges in sql/privilege/privilege.go.
+// Privileges which are reserved keywords are listed explicitly; the others,
+// like DROP, INSERT, DELETE and UPDATE, are matched by name.
 privilege:
-  CREATE
-  {
-    $$.val = privilege.CREATE
-  }
-| DROP
-  {
-    $$.val = privilege.DROP
-  }
+  name
+| CREATE
 | GRANT
-  {
-    $$.val = privilege.GRANT
-  }
 | SELECT
-  {
-    $$.val = privilege.SELECT
-  }
-| INSERT
-  {
-    $$.val = privilege.INSERT
-  }
-| DELETE
-  {
-    $$.val = privilege.DELETE
-  }
-| UPDATE
-  {
-    $$.val = privilege.UPDATE
-  }
 
 // TODO(marc): this should not be 'name', but should instead be a
 // type just for usernames.
@@ -1229,6 +1275,7 @@ grantee_list:
   }
 
 // SET name TO 'var_value'
+// SET CLUSTER SETTING name = var_value
 // SET TIME ZONE 'var_value'
 set_stmt:
   SET set_rest
@@ -1244,6 +1291,14 @@ set_stmt:
   {
     $$.val = $3.stmt()
   }
+| SET CLUSTER SETTING var_name to_or_eq var_value
+  {
+    $$.val = &SetClusterSetting{Name: $4.unresolvedName(), Value: $6.expr()}
+  }
+| SET CLUSTER SETTING var_name to_or_eq DEFAULT
+  {
+    $$.val = &SetClusterSetting{Name: $4.unresolvedName()}
+  }
 | set_exprs_internal { /* SKIP DOC */ }
 
 set_exprs_internal:
@@ -1280,6 +1335,10 @@ transaction_mode_list:
   }
 
 
+to_or_eq:
+  '='
+| TO
+
 transaction_user_priority:
   PRIORITY user_priority
   {
@@ -1447,6 +1506,18 @@ show_stmt:
   {
     $$.val = &Show{Name: $2}
   }
+| SHOW ALL CLUSTER SETTINGS
+  {
+    $$.val = &ShowClusterSetting{}
+  }
+| SHOW CHANGEFEEDS
+  {
+    $$.val = &ShowChangeFeeds{}
+  }
+| SHOW CLUSTER SETTING var_name
+  {
+    $$.val = &ShowClusterSetting{Name: $4.unresolvedName()}
+  }
 | SHOW DATABASE
   {
     $$.val = &Show{Name: $2}
@@ -1463,6 +1534,10 @@ show_stmt:
   {
     $$.val = &ShowGrants{Targets: $3.targetListPtr(), Grantees: $4.nameList()}
   }
+| SHOW GRANTS ON ROLE name_list for_grantee_clause
+  {
+    $$.val = &ShowRoleGrants{Roles: $5.nameList(), Grantees: $6.nameList()}
+  }
 | SHOW INDEX FROM var_name
   {
     $$.val = &ShowIndex{Table: $4.normalizableTableName()}
@@ -1515,6 +1590,10 @@ show_stmt:
   {
     $$.val = &ShowUsers{}
   }
+| SHOW ROLES
+  {
+    $$.val = &ShowRoles{}
+  }
 
 help_stmt:
   HELP unrestricted_name
@@ -1888,6 +1967,20 @@ truncate_stmt:
     $$.val = &Truncate{Tables: $3.tableNameReferences(), DropBehavior: $4.dropBehavior()}
   }
 
+// UNDROP TABLE relname
+undrop_stmt:
+  UNDROP TABLE qualified_name
+  {
+    $$.val = &UndropTable{Name: $3.normalizableTableName()}
+  }
+
+// CREATE ROLE
+create_role_stmt:
+  CREATE ROLE name
+  {
+    $$.val = &CreateRole{Name: Name($3)}
+  }
+
 // CREATE USER
 create_user_stmt:
   CREATE USER name opt_with opt_password
@@ -3786,10 +3879,22 @@ func_expr_common_subexpr:
   {
     $$.val = &FuncExpr{Name: WrapQualifiedFunctionName($1)}
   }
-| CURRENT_ROLE { return unimplemented(sqllex) }
-| CURRENT_USER { return unimplemented(sqllex) }
-| SESSION_USER { return unimplemented(sqllex) }
-| USER { return unimplemented(sqllex) }
+| CURRENT_ROLE
+  {
+    $$.val = &FuncExpr{Name: WrapQualifiedFunctionName("current_user")}
+  }
+| CURRENT_USER
+  {
+    $$.val = &FuncExpr{Name: WrapQualifiedFunctionName("current_user")}
+  }
+| SESSION_USER
+  {
+    $$.val = &FuncExpr{Name: WrapQualifiedFunctionName("session_user")}
+  }
+| USER
+  {
+    $$.val = &FuncExpr{Name: WrapQualifiedFunctionName("current_user")}
+  }
 | CAST '(' a_expr AS typename ')'
   {
     $$.val = &CastExpr{Expr: $3.expr(), Type: $5.colType(), syntaxMode: castExplicit}
@@ -4614,7 +4719,11 @@ unreserved_keyword:
 | BEGIN
 | BLOB
 | BY
+| CANCEL
 | CASCADE
+| CHANGEFEED
+| CHANGEFEEDS
+| CLUSTER
 | COLUMNS
 | COMMIT
 | COMMITTED
@@ -4635,6 +4744,7 @@ unreserved_keyword:
 | DROP
 | ENCODING
 | EXECUTE
+| EXPERIMENTAL_AUDIT
 | EXPLAIN
 | FILTER
 | FIRST
@@ -4684,6 +4794,8 @@ unreserved_keyword:
 | REPEATABLE
 | RESTRICT
 | REVOKE
+| ROLE
+| ROLES
 | ROLLBACK
 | ROLLUP
 | ROWS
@@ -4693,6 +4805,8 @@ unreserved_keyword:
 | SERIALIZABLE
 | SESSION
 | SET
+| SETTING
+| SETTINGS
 | SHOW
 | SIMPLE
 | SNAPSHOT
@@ -4710,6 +4824,7 @@ unreserved_keyword:
 | TYPE
 | UNBOUNDED
 | UNCOMMITTED
+| UNDROP
 | UNKNOWN
 | UPDATE
 | UPSERT
@@ -4720,6 +4835,7 @@ unreserved_keyword:
 | VARYING
 | WITHIN
 | WITHOUT
+| WRITE
 | YEAR
 | ZONE
 
@@ -86,6 +86,18 @@ func (*BeginTransaction) StatementType() StatementType { return Ack }
 // StatementTag returns a short string identifying the type of statement.
 func (*BeginTransaction) StatementTag() string { return "BEGIN" }
 
+// StatementType implements the Statement interface.
+func (*CancelChangeFeed) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CancelChangeFeed) StatementTag() string { return "CANCEL CHANGEFEED" }
+
+// StatementType implements the Statement interface.
+func (*ChangeFeed) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ChangeFeed) StatementTag() string { return "CHANGEFEED" }
+
 // StatementType implements the Statement interface.
 func (*CommitTransaction) StatementType() StatementType { return Ack }
 
@@ -116,6 +128,12 @@ func (*CreateTable) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*CreateTable) StatementTag() string { return "CREATE TABLE" }
 
+// StatementType implements the Statement interface.
+func (*CreateRole) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateRole) StatementTag() string { return "CREATE ROLE" }
+
 // StatementType implements the Statement interface.
 func (*CreateUser) StatementType() StatementType { return Ack }
 
@@ -158,6 +176,12 @@ func (*DropIndex) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*DropIndex) StatementTag() string { return "DROP INDEX" }
 
+// StatementType implements the Statement interface.
+func (*DropRole) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropRole) StatementTag() string { return "DROP ROLE" }
+
 // StatementType implements the Statement interface.
 func (*DropTable) StatementType() StatementType { return DDL }
 
@@ -188,6 +212,12 @@ func (*Grant) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*Grant) StatementTag() string { return "GRANT" }
 
+// StatementType implements the Statement interface.
+func (*GrantRole) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*GrantRole) StatementTag() string { return "GRANT" }
+
 // StatementType implements the Statement interface.
 func (n *Insert) StatementType() StatementType { return n.Returning.StatementType() }
 
@@ -247,6 +277,12 @@ func (*Revoke) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*Revoke) StatementTag() string { return "REVOKE" }
 
+// StatementType implements the Statement interface.
+func (*RevokeRole) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*RevokeRole) StatementTag() string { return "REVOKE" }
+
 // StatementType implements the Statement interface.
 func (*RollbackToSavepoint) StatementType() StatementType { return Ack }
 
@@ -283,6 +319,12 @@ func (*Set) StatementType() StatementType { return Ack }
 // StatementTag returns a short string identifying the type of statement.
 func (*Set) StatementTag() string { return "SET" }
 
+// StatementType implements the Statement interface.
+func (*SetClusterSetting) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*SetClusterSetting) StatementTag() string { return "SET CLUSTER SETTING" }
+
 // StatementType implements the Statement interface.
 func (*SetTransaction) StatementType() StatementType { return Ack }
 
@@ -307,6 +349,18 @@ func (*Show) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*Show) StatementTag() string { return "SHOW" }
 
+// StatementType implements the Statement interface.
+func (*ShowChangeFeeds) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowChangeFeeds) StatementTag() string { return "SHOW CHANGEFEEDS" }
+
+// StatementType implements the Statement interface.
+func (*ShowClusterSetting) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowClusterSetting) StatementTag() string { return "SHOW" }
+
 // StatementType implements the Statement interface.
 func (*ShowColumns) StatementType() StatementType { return Rows }
 
@@ -337,12 +391,24 @@ func (*ShowGrants) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*ShowGrants) StatementTag() string { return "SHOW GRANTS" }
 
+// StatementType implements the Statement interface.
+func (*ShowRoleGrants) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowRoleGrants) StatementTag() string { return "SHOW GRANTS" }
+
 // StatementType implements the Statement interface.
 func (*ShowIndex) StatementType() StatementType { return Rows }
 
 // StatementTag returns a short string identifying the type of statement.
 func (*ShowIndex) StatementTag() string { return "SHOW INDEX" }
 
+// StatementType implements the Statement interface.
+func (*ShowRoles) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowRoles) StatementTag() string { return "SHOW ROLES" }
+
 // StatementType implements the Statement interface.
 func (*ShowUsers) StatementType() StatementType { return Rows }
 
@@ -379,6 +445,12 @@ func (*Truncate) StatementType() StatementType { return Ack }
 // StatementTag returns a short string identifying the type of statement.
 func (*Truncate) StatementTag() string { return "TRUNCATE" }
 
+// StatementType implements the Statement interface.
+func (*UndropTable) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*UndropTable) StatementTag() string { return "UNDROP TABLE" }
+
 // StatementType implements the Statement interface.
 func (n *Update) StatementType() StatementType { return n.Returning.StatementType() }
 
@@ -406,10 +478,13 @@ func (n *AlterTableDropConstraint) String() string { return AsString(n) }
 func (n *AlterTableDropNotNull) String() string    { return AsString(n) }
 func (n *AlterTableSetDefault) String() string     { return AsString(n) }
 func (n *BeginTransaction) String() string         { return AsString(n) }
+func (n *CancelChangeFeed) String() string         { return AsString(n) }
+func (n *ChangeFeed) String() string               { return AsString(n) }
 func (n *CommitTransaction) String() string        { return AsString(n) }
 func (n *CopyFrom) String() string                 { return AsString(n) }
 func (n *CreateDatabase) String() string           { return AsString(n) }
 func (n *CreateIndex) String() string              { return AsString(n) }
+func (n *CreateRole) String() string               { return AsString(n) }
 func (n *CreateTable) String() string              { return AsString(n) }
 func (n *CreateUser) String() string               { return AsString(n) }
 func (n *CreateView) String() string               { return AsString(n) }
@@ -417,11 +492,13 @@ func (n *Deallocate) String() string               { return AsString(n) }
 func (n *Delete) String() string                   { return AsString(n) }
 func (n *DropDatabase) String() string             { return AsString(n) }
 func (n *DropIndex) String() string                { return AsString(n) }
+func (n *DropRole) String() string                 { return AsString(n) }
 func (n *DropTable) String() string                { return AsString(n) }
 func (n *DropView) String() string                 { return AsString(n) }
 func (n *Execute) String() string                  { return AsString(n) }
 func (n *Explain) String() string                  { return AsString(n) }
 func (n *Grant) String() string                    { return AsString(n) }
+func (n *GrantRole) String() string                { return AsString(n) }
 func (n *Help) String() string                     { return AsString(n) }
 func (n *Insert) String() string                   { return AsString(n) }
 func (n *ParenSelect) String() string              { return AsString(n) }
@@ -432,22 +509,28 @@ func (n *RenameDatabase) String() string           { return AsString(n) }
 func (n *RenameIndex) String() string              { return AsString(n) }
 func (n *RenameTable) String() string              { return AsString(n) }
 func (n *Revoke) String() string                   { return AsString(n) }
+func (n *RevokeRole) String() string               { return AsString(n) }
 func (n *RollbackToSavepoint) String() string      { return AsString(n) }
 func (n *RollbackTransaction) String() string      { return AsString(n) }
 func (n *Savepoint) String() string                { return AsString(n) }
 func (n *Select) String() string                   { return AsString(n) }
 func (n *SelectClause) String() string             { return AsString(n) }
 func (n *Set) String() string                      { return AsString(n) }
+func (n *SetClusterSetting) String() string        { return AsString(n) }
 func (n *SetDefaultIsolation) String() string      { return AsString(n) }
 func (n *SetTimeZone) String() string              { return AsString(n) }
 func (n *SetTransaction) String() string           { return AsString(n) }
 func (n *Show) String() string                     { return AsString(n) }
+func (n *ShowChangeFeeds) String() string          { return AsString(n) }
+func (n *ShowClusterSetting) String() string       { return AsString(n) }
 func (n *ShowColumns) String() string              { return AsString(n) }
 func (n *ShowCreateTable) String() string          { return AsString(n) }
 func (n *ShowCreateView) String() string           { return AsString(n) }
 func (n *ShowDatabases) String() string            { return AsString(n) }
 func (n *ShowGrants) String() string               { return AsString(n) }
 func (n *ShowIndex) String() string                { return AsString(n) }
+func (n *ShowRoleGrants) String() string           { return AsString(n) }
+func (n *ShowRoles) String() string                { return AsString(n) }
 func (n *ShowConstraints) String() string          { return AsString(n) }
 func (n *ShowTables) String() string               { return AsString(n) }
 func (n *ShowUsers) String() string                { return AsString(n) }
@@ -455,5 +538,6 @@ func (n *Split) String() string                    { return AsString(n) }
 func (l StatementList) String() string             { return AsString(l) }
 func (n *Truncate) String() string                 { return AsString(n) }
 func (n *UnionClause) String() string              { return AsString(n) }
+func (n *UndropTable) String() string              { return AsString(n) }
 func (n *Update) String() string                   { return AsString(n) }
 func (n *ValuesClause) String() string             { return AsString(n) }
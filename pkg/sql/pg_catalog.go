@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"hash"
 	"hash/fnv"
+	"net"
 	"reflect"
 	"strconv"
 	"strings"
@@ -29,6 +30,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/lib/pq/oid"
 	"github.com/pkg/errors"
@@ -59,6 +61,7 @@ var pgCatalog = virtualSchema{
 		pgCatalogDatabaseTable,
 		pgCatalogDependTable,
 		pgCatalogDescriptionTable,
+		pgCatalogHbaFileRulesTable,
 		pgCatalogIndexesTable,
 		pgCatalogNamespaceTable,
 		pgCatalogProcTable,
@@ -687,6 +690,65 @@ CREATE TABLE pg_catalog.pg_description (
 	},
 }
 
+// See: https://www.postgresql.org/docs/10/static/view-pg-hba-file-rules.html.
+var pgCatalogHbaFileRulesTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE pg_catalog.pg_hba_file_rules (
+	line_number INT,
+	type STRING,
+	database STRING,
+	user_name STRING,
+	address STRING,
+	netmask STRING,
+	auth_method STRING,
+	options STRING,
+	error STRING
+);
+`,
+	populate: func(p *planner, addRow func(...parser.Datum) error) error {
+		// The authentication configuration is only visible to root, as
+		// pg_hba_file_rules is only visible to superusers in Postgres.
+		if p.session.User != security.RootUser {
+			return errors.Errorf("only %s is allowed to read pg_catalog.pg_hba_file_rules", security.RootUser)
+		}
+		// Only valid configurations can be set, so the error column is
+		// always NULL.
+		for _, r := range hba.Current().Rules {
+			address, netmask := parser.Datum(parser.DNull), parser.Datum(parser.DNull)
+			if r.ConnType != hba.ConnLocal {
+				address = parser.NewDString("all")
+				if r.Address != nil {
+					address = parser.NewDString(r.Address.IP.String())
+					netmask = parser.NewDString(net.IP(r.Address.Mask).String())
+				}
+			}
+			if err := addRow(
+				parser.NewDInt(parser.DInt(r.Line)),    // line_number
+				parser.NewDString(r.ConnType.String()), // type
+				hbaNamesToDatum(r.Databases),           // database
+				hbaNamesToDatum(r.Users),               // user_name
+				address,                                // address
+				netmask,                                // netmask
+				parser.NewDString(string(r.Method)),    // auth_method
+				parser.DNull,                           // options
+				parser.DNull,                           // error
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// hbaNamesToDatum formats the databases or users of a host-based
+// authentication rule as an array, where nil stands for "all".
+func hbaNamesToDatum(names []string) parser.Datum {
+	if names == nil {
+		names = []string{"all"}
+	}
+	return parser.NewDString("{" + strings.Join(names, ",") + "}")
+}
+
 // See: https://www.postgresql.org/docs/9.6/static/view-pg-indexes.html.
 var pgCatalogIndexesTable = virtualSchemaTable{
 	schema: `
@@ -983,17 +1045,20 @@ CREATE TABLE pg_catalog.pg_roles (
 		// include sensitive information such as password hashes.
 		h := makeOidHasher()
 		return forEachUser(p,
-			func(username string) error {
+			func(username string, isRole bool) error {
 				isRoot := parser.DBool(username == security.RootUser)
+				// Members of roles always inherit their privileges, and roles
+				// can't log in.
+				canLogin := parser.DBool(!isRole)
 				return addRow(
 					h.UserOid(username),           // oid
 					parser.NewDString(username),   // rolname
 					parser.MakeDBool(isRoot),      // rolsuper
-					parser.MakeDBool(false),       // rolinherit
+					parser.MakeDBool(true),        // rolinherit
 					parser.MakeDBool(isRoot),      // rolcreaterole
 					parser.MakeDBool(isRoot),      // rolcreatedb
 					parser.MakeDBool(false),       // rolcatupdate
-					parser.MakeDBool(true),        // rolcanlogin
+					parser.MakeDBool(canLogin),    // rolcanlogin
 					negOneVal,                     // rolconnlimit
 					parser.NewDString("********"), // rolpassword
 					parser.DNull,                  // rolvaliduntil
@@ -0,0 +1,322 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package hba implements host-based authentication: rules in the style of
+// postgres' pg_hba.conf which decide how SQL clients must authenticate,
+// depending on the user, the database, the client address and whether the
+// connection uses TLS.
+//
+// The rules are read from the server.host_based_authentication.configuration
+// cluster setting, so that they can be changed without restarting the
+// cluster. They are evaluated every time a client connects.
+package hba
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+)
+
+// ConnType is the type of a client connection. A rule matches the
+// connection types whose bits it has set.
+type ConnType int
+
+const (
+	// ConnLocal is a connection over a unix socket.
+	ConnLocal ConnType = 1 << iota
+	// ConnHostSSL is a TCP connection which uses TLS.
+	ConnHostSSL
+	// ConnHostNoSSL is a TCP connection which doesn't use TLS.
+	ConnHostNoSSL
+
+	// ConnHost matches all TCP connections.
+	ConnHost = ConnHostSSL | ConnHostNoSSL
+)
+
+var connTypeNames = map[ConnType]string{
+	ConnLocal:     "local",
+	ConnHost:      "host",
+	ConnHostSSL:   "hostssl",
+	ConnHostNoSSL: "hostnossl",
+}
+
+func (t ConnType) String() string {
+	if name, ok := connTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("ConnType(%d)", int(t))
+}
+
+// Method is the way in which the clients matching a rule must authenticate.
+type Method string
+
+const (
+	// MethodTrust lets clients in without authenticating them.
+	MethodTrust Method = "trust"
+	// MethodReject rejects clients.
+	MethodReject Method = "reject"
+	// MethodCert requires a client certificate.
+	MethodCert Method = "cert"
+	// MethodPassword requires a password, exchanged with one of the user's
+	// authentication methods. It is the only method which allows connections
+	// without TLS in secure mode.
+	MethodPassword Method = "password"
+	// MethodCertPassword requires a client certificate if the client sent
+	// one, and a password otherwise.
+	MethodCertPassword Method = "cert-password"
+)
+
+var methods = map[Method]struct{}{
+	MethodTrust:        {},
+	MethodReject:       {},
+	MethodCert:         {},
+	MethodPassword:     {},
+	MethodCertPassword: {},
+}
+
+// Rule is a line of the configuration.
+type Rule struct {
+	// Line is the line number of the rule in the configuration.
+	Line     int
+	ConnType ConnType
+	// Databases and Users hold normalized names. They are nil if the rule
+	// matches all databases or users.
+	Databases []string
+	Users     []string
+	// Address is nil if the rule matches all addresses, and for local
+	// connections.
+	Address *net.IPNet
+	Method  Method
+}
+
+// String formats the rule as a line of the configuration.
+func (r Rule) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s %s", r.ConnType, formatNames(r.Databases), formatNames(r.Users))
+	if r.ConnType != ConnLocal {
+		if r.Address == nil {
+			buf.WriteString(" all")
+		} else {
+			fmt.Fprintf(&buf, " %s", r.Address)
+		}
+	}
+	fmt.Fprintf(&buf, " %s", r.Method)
+	return buf.String()
+}
+
+func formatNames(names []string) string {
+	if names == nil {
+		return "all"
+	}
+	return strings.Join(names, ",")
+}
+
+func matchName(names []string, name string) bool {
+	if names == nil {
+		return true
+	}
+	name = parser.Name(name).Normalize()
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns whether the rule applies to the given connection.
+func (r Rule) matches(connType ConnType, database, user string, addr net.IP) bool {
+	if r.ConnType&connType == 0 {
+		return false
+	}
+	if !matchName(r.Databases, database) || !matchName(r.Users, user) {
+		return false
+	}
+	return r.Address == nil || (addr != nil && r.Address.Contains(addr))
+}
+
+// Config is a parsed configuration.
+type Config struct {
+	Rules []Rule
+}
+
+// Lookup returns the first rule which applies to the given connection. addr
+// is nil for local connections. Clients for which there is no rule must be
+// rejected.
+func (c *Config) Lookup(connType ConnType, database, user string, addr net.IP) (Rule, bool) {
+	for _, r := range c.Rules {
+		if r.matches(connType, database, user, addr) {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// rootRule is implicitly the first rule of every configuration, so that a
+// mistake in the configuration can't lock root out of the cluster: root can
+// always authenticate with a certificate.
+var rootRule = Rule{
+	ConnType: ConnHostSSL,
+	Users:    []string{security.RootUser},
+	Method:   MethodCert,
+}
+
+// Parse parses a configuration. Each line holds a rule, in one of the forms
+//
+//   local     DATABASE USER METHOD
+//   host      DATABASE USER ADDRESS METHOD
+//   hostssl   DATABASE USER ADDRESS METHOD
+//   hostnossl DATABASE USER ADDRESS METHOD
+//
+// where DATABASE and USER are either "all" or comma-separated lists of names,
+// and ADDRESS is either "all", an IP address or a CIDR. Empty lines and
+// comments, which start with '#', are ignored. The configuration starts with
+// an implicit rule on line 0, which lets root authenticate with a certificate.
+func Parse(s string) (*Config, error) {
+	conf := &Config{Rules: []Rule{rootRule}}
+	for i, line := range strings.Split(s, "\n") {
+		if j := strings.IndexByte(line, '#'); j >= 0 {
+			line = line[:j]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		r, err := parseRule(fields)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", i+1)
+		}
+		r.Line = i + 1
+		conf.Rules = append(conf.Rules, r)
+	}
+	return conf, nil
+}
+
+func parseRule(fields []string) (Rule, error) {
+	var r Rule
+	for t, name := range connTypeNames {
+		if fields[0] == name {
+			r.ConnType = t
+		}
+	}
+	expected := 5
+	switch r.ConnType {
+	case 0:
+		return Rule{}, errors.Errorf("unknown connection type %q", fields[0])
+	case ConnLocal:
+		expected = 4
+	}
+	if len(fields) != expected {
+		return Rule{}, errors.Errorf("expected %d fields for a %s rule, found %d",
+			expected, r.ConnType, len(fields))
+	}
+
+	r.Databases = parseNames(fields[1])
+	r.Users = parseNames(fields[2])
+	if r.ConnType != ConnLocal {
+		var err error
+		if r.Address, err = parseAddress(fields[3]); err != nil {
+			return Rule{}, err
+		}
+	}
+	r.Method = Method(fields[len(fields)-1])
+	if _, ok := methods[r.Method]; !ok {
+		return Rule{}, errors.Errorf("unknown authentication method %q", r.Method)
+	}
+	return r, nil
+}
+
+func parseNames(field string) []string {
+	if field == "all" {
+		return nil
+	}
+	names := strings.Split(field, ",")
+	for i := range names {
+		names[i] = parser.Name(names[i]).Normalize()
+	}
+	return names
+}
+
+func parseAddress(field string) (*net.IPNet, error) {
+	if field == "all" {
+		return nil, nil
+	}
+	if strings.IndexByte(field, '/') >= 0 {
+		_, ipNet, err := net.ParseCIDR(field)
+		return ipNet, err
+	}
+	ip := net.ParseIP(field)
+	if ip == nil {
+		return nil, errors.Errorf("invalid address %q", field)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// DefaultConfig is the configuration used when the cluster setting is
+// empty: clients authenticate with a certificate if they send one, and with
+// a password otherwise.
+const DefaultConfig = `
+# TYPE  DATABASE USER ADDRESS METHOD
+host    all      all  all     cert-password
+local   all      all          cert-password
+`
+
+var configSetting = settings.RegisterValidatedStringSetting(
+	"server.host_based_authentication.configuration",
+	"host-based authentication rules for SQL clients, in the format of pg_hba.conf "+
+		"(the default rules are used if empty)",
+	"",
+	func(s string) error {
+		_, err := Parse(s)
+		return err
+	},
+)
+
+// current holds the parsed value of configSetting.
+var current atomic.Value
+
+func init() {
+	configSetting.OnChange(reload)
+	reload()
+}
+
+func reload() {
+	s := configSetting.Get()
+	if strings.TrimSpace(s) == "" {
+		s = DefaultConfig
+	}
+	conf, err := Parse(s)
+	if err != nil {
+		// Values are validated before being set, so this can't happen.
+		return
+	}
+	current.Store(conf)
+}
+
+// Current returns the configuration in effect.
+func Current() *Config {
+	return current.Load().(*Config)
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package hba
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	conf, err := Parse(`
+# Comments and empty lines are ignored.
+
+hostssl   all        Admin,ops  all              cert # trailing comment
+hostnossl app        all        10.0.0.0/8       password
+host      all        all        192.168.1.1      cert-password
+host      all        all        ::1              trust
+local     all        all                         reject
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"hostssl all root all cert",
+		"hostssl all admin,ops all cert",
+		"hostnossl app all 10.0.0.0/8 password",
+		"host all all 192.168.1.1/32 cert-password",
+		"host all all ::1/128 trust",
+		"local all all reject",
+	}
+	if len(conf.Rules) != len(expected) {
+		t.Fatalf("expected %d rules, got %d: %v", len(expected), len(conf.Rules), conf.Rules)
+	}
+	for i, r := range conf.Rules {
+		if s := r.String(); s != expected[i] {
+			t.Errorf("%d: expected %q, got %q", i, expected[i], s)
+		}
+	}
+	if lines := []int{conf.Rules[0].Line, conf.Rules[1].Line, conf.Rules[5].Line}; lines[0] != 0 ||
+		lines[1] != 4 || lines[2] != 8 {
+		t.Errorf("unexpected line numbers %v", lines)
+	}
+
+	for _, tc := range []struct {
+		conf        string
+		expectedErr string
+	}{
+		{"hostx all all all trust", `line 1: unknown connection type "hostx"`},
+		{"\nhost all all trust", "line 2: expected 5 fields for a host rule, found 4"},
+		{"local all all all trust", "line 1: expected 4 fields for a local rule, found 5"},
+		{"host all all 10.0.0.0/33 trust", "invalid CIDR address"},
+		{"host all all localhost trust", `invalid address "localhost"`},
+		{"host all all all md4", `unknown authentication method "md4"`},
+	} {
+		if _, err := Parse(tc.conf); !testutils.IsError(err, tc.expectedErr) {
+			t.Errorf("%q: expected error %q, got %v", tc.conf, tc.expectedErr, err)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	conf, err := Parse(`
+hostssl   all all    all        reject
+hostnossl app all    10.0.0.0/8 password
+hostnossl all bob    all        password
+local     all all               trust
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vpc := net.ParseIP("10.1.2.3")
+	outside := net.ParseIP("8.8.8.8")
+	testCases := []struct {
+		connType ConnType
+		database string
+		user     string
+		addr     net.IP
+		line     int
+	}{
+		// Root can always use a certificate, despite the reject rule.
+		{ConnHostSSL, "app", "root", outside, 0},
+		{ConnHostSSL, "app", "alice", vpc, 2},
+		{ConnHostNoSSL, "app", "alice", vpc, 3},
+		{ConnHostNoSSL, "APP", "alice", vpc, 3},
+		{ConnHostNoSSL, "app", "Bob", outside, 4},
+		{ConnHostNoSSL, "app", "alice", outside, -1},
+		{ConnHostNoSSL, "other", "alice", vpc, -1},
+		{ConnHostNoSSL, "app", "root", outside, -1},
+		{ConnLocal, "app", "root", nil, 5},
+	}
+	for i, tc := range testCases {
+		r, ok := conf.Lookup(tc.connType, tc.database, tc.user, tc.addr)
+		if !ok {
+			if tc.line != -1 {
+				t.Errorf("%d: expected rule on line %d to match, got none", i, tc.line)
+			}
+			continue
+		}
+		if r.Line != tc.line {
+			t.Errorf("%d: expected rule on line %d to match, got %d (%s)", i, tc.line, r.Line, r)
+		}
+	}
+}
+
+func TestDefaultConfig(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, connType := range []ConnType{ConnLocal, ConnHostSSL, ConnHostNoSSL} {
+		r, ok := Current().Lookup(connType, "", "alice", net.ParseIP("8.8.8.8"))
+		if !ok || r.Method != MethodCertPassword {
+			t.Errorf("%s: expected the cert-password rule to match, got %s (%t)", connType, r, ok)
+		}
+	}
+}
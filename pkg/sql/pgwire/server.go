@@ -232,7 +232,6 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 	if err != nil {
 		return err
 	}
-	errSSLRequired := false
 	if version == versionSSL {
 		if len(buf.msg) > 0 {
 			return errors.Errorf("unexpected data after SSLRequest: %q", buf.msg)
@@ -262,8 +261,6 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 		if err != nil {
 			return err
 		}
-	} else if !s.cfg.Insecure {
-		errSSLRequired = true
 	}
 
 	if version == version30 {
@@ -277,9 +274,6 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 			return v3conn.sendInternalError(err.Error())
 		}
 
-		if errSSLRequired {
-			return v3conn.sendInternalError(ErrSSLRequired)
-		}
 		if draining {
 			// TODO(tschottdorf): Likely not handled gracefully by clients.
 			// See #6295.
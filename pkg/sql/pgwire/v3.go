@@ -19,6 +19,7 @@ package pgwire
 
 import (
 	"bufio"
+	"crypto/rand"
 	"crypto/tls"
 	"fmt"
 	"net"
@@ -32,6 +33,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/mon"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -100,6 +102,10 @@ const (
 const (
 	authOK                int32 = 0
 	authCleartextPassword int32 = 3
+	authMD5Password       int32 = 5
+	authSASL              int32 = 10
+	authSASLContinue      int32 = 11
+	authSASLFinal         int32 = 12
 )
 
 // preparedStatementMeta is pgwire-specific metadata which is attached to each
@@ -208,47 +214,111 @@ var statusReportParams = map[string]string{
 // name, if different from the one given initially. Note: at this
 // point the sql.Session does not exist yet! If need exists to access the
 // database to look up authentication data, use the internal executor.
+//
+// How the client must authenticate is decided by the first host-based
+// authentication rule which applies to the connection.
 func (c *v3Conn) handleAuthentication(ctx context.Context, insecure bool) error {
-	if tlsConn, ok := c.conn.(*tls.Conn); ok {
-		var authenticationHook security.UserAuthHook
-
-		// Check that the requested user exists and retrieve the hashed
-		// password in case password authentication is needed.
-		hashedPassword, err := sql.GetUserHashedPassword(
-			ctx, c.executor, c.metrics.internalMemMetrics, c.sessionArgs.User,
-		)
+	tlsConn, isTLS := c.conn.(*tls.Conn)
+	connType, addr := hba.ConnLocal, net.IP(nil)
+	if tcpAddr, ok := c.conn.RemoteAddr().(*net.TCPAddr); ok {
+		connType, addr = hba.ConnHostNoSSL, tcpAddr.IP
+		if isTLS {
+			connType = hba.ConnHostSSL
+		}
+	}
+	rule, ok := hba.Current().Lookup(connType, c.sessionArgs.Database, c.sessionArgs.User, addr)
+	if !ok {
+		return c.sendInternalError(fmt.Sprintf(
+			"no host-based authentication rule for user %s from %s",
+			c.sessionArgs.User, c.conn.RemoteAddr()))
+	}
+	switch {
+	case rule.Method == hba.MethodReject:
+		return c.sendInternalError(fmt.Sprintf(
+			"connection rejected by host-based authentication rule on line %d", rule.Line))
+	case rule.Method == hba.MethodTrust, insecure:
+		// In insecure mode, there is nothing to verify users against.
+		return c.sendAuthOK()
+	case !isTLS && rule.Method != hba.MethodPassword:
+		// Client certificates can only be sent over TLS.
+		return c.sendInternalError(ErrSSLRequired)
+	}
+
+	var authenticationHook security.UserAuthHook
+
+	// Check that the requested user exists and retrieve their
+	// credentials in case password authentication is needed.
+	creds, err := sql.GetUserCredentials(
+		ctx, c.executor, c.metrics.internalMemMetrics, c.sessionArgs.User,
+	)
+	if err != nil {
+		return c.sendInternalError(err.Error())
+	}
+
+	var tlsState tls.ConnectionState
+	if isTLS {
+		tlsState = tlsConn.ConnectionState()
+	}
+	// If no certificates are provided, cert-password defaults to password
+	// authentication.
+	var scram *security.SCRAMExchange
+	if rule.Method == hba.MethodPassword ||
+		(rule.Method == hba.MethodCertPassword && len(tlsState.PeerCertificates) == 0) {
+		var response security.PasswordResponse
+		method, ok := creds.PreferredMethod()
+		if !ok {
+			return c.sendInternalError(fmt.Sprintf(
+				"no credentials for an allowed authentication method of user %s",
+				c.sessionArgs.User))
+		}
+		switch method {
+		case security.AuthSCRAMSHA256:
+			scram, err = c.runSCRAMExchange(creds.SCRAMVerifier)
+			response = scram
+		case security.AuthMD5:
+			response, err = c.sendAuthMD5Request()
+		default:
+			var password string
+			password, err = c.sendAuthPasswordRequest()
+			response = security.CleartextPassword(password)
+		}
 		if err != nil {
 			return c.sendInternalError(err.Error())
 		}
-
-		tlsState := tlsConn.ConnectionState()
-		// If no certificates are provided, default to password
-		// authentication.
-		if len(tlsState.PeerCertificates) == 0 {
-			password, err := c.sendAuthPasswordRequest()
-			if err != nil {
-				return c.sendInternalError(err.Error())
-			}
-			authenticationHook = security.UserAuthPasswordHook(
-				insecure, password, hashedPassword,
-			)
-		} else {
-			// Normalize the username contained in the certificate.
+		authenticationHook = security.UserAuthPasswordHook(insecure, response, creds)
+	} else {
+		// Normalize the username contained in the certificate.
+		if len(tlsState.PeerCertificates) > 0 {
 			tlsState.PeerCertificates[0].Subject.CommonName = parser.Name(
 				tlsState.PeerCertificates[0].Subject.CommonName,
 			).Normalize()
-			var err error
-			authenticationHook, err = security.UserAuthCertHook(insecure, &tlsState)
-			if err != nil {
-				return c.sendInternalError(err.Error())
-			}
 		}
-
-		if err := authenticationHook(c.sessionArgs.User, true /* public */); err != nil {
+		var err error
+		authenticationHook, err = security.UserAuthCertHook(insecure, &tlsState)
+		if err != nil {
 			return c.sendInternalError(err.Error())
 		}
 	}
 
+	if err := authenticationHook(c.sessionArgs.User, true /* public */); err != nil {
+		return c.sendInternalError(err.Error())
+	}
+
+	// The client has been authenticated; complete the SCRAM exchange by
+	// proving to it that we know its verifier.
+	if scram != nil {
+		c.writeBuf.initMsg(serverMsgAuth)
+		c.writeBuf.putInt32(authSASLFinal)
+		c.writeBuf.write(scram.ServerFinal())
+		if err := c.writeBuf.finishMsg(c.wr); err != nil {
+			return err
+		}
+	}
+	return c.sendAuthOK()
+}
+
+// sendAuthOK tells the client that it has been authenticated.
+func (c *v3Conn) sendAuthOK() error {
 	c.writeBuf.initMsg(serverMsgAuth)
 	c.writeBuf.putInt32(authOK)
 	return c.writeBuf.finishMsg(c.wr)
@@ -386,31 +456,111 @@ func (c *v3Conn) serve(ctx context.Context, reserved mon.BoundAccount) error {
 	}
 }
 
-// sendAuthPasswordRequest requests a cleartext password from the client and
-// returns it.
-func (c *v3Conn) sendAuthPasswordRequest() (string, error) {
+// sendAuthRequest sends an authentication request of the given type,
+// followed by data, and reads the client's response into c.readBuf.
+func (c *v3Conn) sendAuthRequest(authType int32, data []byte) error {
 	c.writeBuf.initMsg(serverMsgAuth)
-	c.writeBuf.putInt32(authCleartextPassword)
+	c.writeBuf.putInt32(authType)
+	c.writeBuf.write(data)
 	if err := c.writeBuf.finishMsg(c.wr); err != nil {
-		return "", err
+		return err
 	}
 	if err := c.wr.Flush(); err != nil {
-		return "", err
+		return err
 	}
 
 	typ, n, err := c.readBuf.readTypedMsg(c.rd)
 	c.metrics.BytesInCount.Inc(int64(n))
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	if typ != clientMsgPassword {
-		return "", errors.Errorf("invalid response to authentication request: %s", typ)
+		return errors.Errorf("invalid response to authentication request: %s", typ)
 	}
+	return nil
+}
 
+// sendAuthPasswordRequest requests a cleartext password from the client and
+// returns it.
+func (c *v3Conn) sendAuthPasswordRequest() (string, error) {
+	if err := c.sendAuthRequest(authCleartextPassword, nil); err != nil {
+		return "", err
+	}
 	return c.readBuf.getString()
 }
 
+// sendAuthMD5Request requests an md5 password hash, salted with a random
+// salt, from the client and returns its response.
+func (c *v3Conn) sendAuthMD5Request() (security.MD5Response, error) {
+	salt := make([]byte, 4)
+	if _, err := rand.Read(salt); err != nil {
+		return security.MD5Response{}, err
+	}
+	if err := c.sendAuthRequest(authMD5Password, salt); err != nil {
+		return security.MD5Response{}, err
+	}
+	response, err := c.readBuf.getString()
+	if err != nil {
+		return security.MD5Response{}, err
+	}
+	return security.MD5Response{Salt: salt, Response: response}, nil
+}
+
+// runSCRAMExchange runs a SCRAM-SHA-256 exchange with the client, up to the
+// client's proof. The server-final-message must only be sent once the client
+// has been authenticated.
+func (c *v3Conn) runSCRAMExchange(verifier []byte) (*security.SCRAMExchange, error) {
+	scram, err := security.NewSCRAMExchange(verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	// The request lists the supported SASL mechanisms, terminated by an empty
+	// one.
+	mechanisms := []byte(security.SCRAMSHA256Mechanism + "\x00\x00")
+	if err := c.sendAuthRequest(authSASL, mechanisms); err != nil {
+		return nil, err
+	}
+	// The client's initial response names the mechanism it chose, followed by
+	// the length-prefixed client-first-message.
+	mechanism, err := c.readBuf.getString()
+	if err != nil {
+		return nil, err
+	}
+	if mechanism != security.SCRAMSHA256Mechanism {
+		return nil, errors.Errorf("unsupported SASL mechanism %q", mechanism)
+	}
+	size, err := c.readBuf.getUint32()
+	if err != nil {
+		return nil, err
+	}
+	if int32(size) < 0 {
+		return nil, errors.New("missing SCRAM client-first-message")
+	}
+	clientFirst, err := c.readBuf.getBytes(int(size))
+	if err != nil {
+		return nil, err
+	}
+	serverFirst, err := scram.ServerFirst(clientFirst)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.sendAuthRequest(authSASLContinue, serverFirst); err != nil {
+		return nil, err
+	}
+	// The client-final-message makes up the whole response.
+	clientFinal, err := c.readBuf.getBytes(len(c.readBuf.msg))
+	if err != nil {
+		return nil, err
+	}
+	if err := scram.ReadClientFinal(clientFinal); err != nil {
+		return nil, err
+	}
+	return scram, nil
+}
+
 func (c *v3Conn) handleSimpleQuery(ctx context.Context, buf *readBuffer) error {
 	query, err := buf.getString()
 	if err != nil {
@@ -17,18 +17,29 @@
 package sql_test
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
 	gosql "database/sql"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"golang.org/x/crypto/pbkdf2"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
@@ -424,7 +435,11 @@ func TestPGPreparedQuery(t *testing.T) {
 		"SHOW COLUMNS FROM system.users": {
 			baseTest.
 				Results("username", "STRING", false, gosql.NullBool{}).
-				Results("hashedPassword", "BYTES", true, gosql.NullBool{}),
+				Results("hashedPassword", "BYTES", true, gosql.NullBool{}).
+				Results("isRole", "BOOL", false, gosql.NullBool{Valid: true}).
+				Results("md5Password", "BYTES", true, gosql.NullBool{}).
+				Results("scramVerifier", "BYTES", true, gosql.NullBool{}).
+				Results("authMethods", "STRING", true, gosql.NullBool{}),
 		},
 		"SHOW DATABASES": {
 			baseTest.Results("information_schema").Results("pg_catalog").Results("d").Results("system"),
@@ -1298,6 +1313,7 @@ func TestPGWireAuth(t *testing.T) {
 	defer s.Stopper().Stop()
 	{
 		unicodeUser := "♫"
+		scramUser := "scram"
 
 		t.Run("RootUserAuth", func(t *testing.T) {
 			// Authenticate as root with certificate and expect success.
@@ -1324,6 +1340,16 @@ func TestPGWireAuth(t *testing.T) {
 			if _, err := db.Exec(fmt.Sprintf("CREATE USER %s WITH PASSWORD '蟑♫螂';", unicodeUser)); err != nil {
 				t.Fatal(err)
 			}
+
+			// SCRAM has to be enabled explicitly.
+			if _, err := db.Exec(fmt.Sprintf("CREATE USER %s WITH PASSWORD 'pencil';", scramUser)); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := db.Exec(
+				`UPDATE system.users SET "authMethods" = 'scram-sha-256' WHERE username = $1`, scramUser,
+			); err != nil {
+				t.Fatal(err)
+			}
 		})
 		t.Run("UnicodeUserAuth", func(t *testing.T) {
 			// Try to perform authentication with unicodeUser and no password.
@@ -1347,6 +1373,34 @@ func TestPGWireAuth(t *testing.T) {
 			if err := trivialQuery(unicodeUserPgUrl); err != nil {
 				t.Fatal(err)
 			}
+
+			// Switch the user to cleartext passwords.
+			rootPgUrl, cleanupFn := sqlutils.PGUrl(
+				t, s.ServingAddr(), "TestPGWireAuth", url.User(security.RootUser))
+			defer cleanupFn()
+			db, err := gosql.Open("postgres", rootPgUrl.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if _, err := db.Exec(
+				`UPDATE system.users SET "authMethods" = 'password' WHERE username = $1`, unicodeUser,
+			); err != nil {
+				t.Fatal(err)
+			}
+			if err := trivialQuery(unicodeUserPgUrl); err != nil {
+				t.Fatal(err)
+			}
+		})
+		t.Run("SCRAMUserAuth", func(t *testing.T) {
+			if err := scramLogin(s.ServingAddr(), scramUser, "pencil"); err != nil {
+				t.Fatal(err)
+			}
+			if err := scramLogin(s.ServingAddr(), scramUser, "pen"); !testutils.IsError(
+				err, "invalid password",
+			) {
+				t.Fatalf("unexpected error: %v", err)
+			}
 		})
 	}
 
@@ -1376,4 +1430,199 @@ func TestPGWireAuth(t *testing.T) {
 			t.Fatalf("unexpected error: %v", err)
 		}
 	})
+
+	t.Run("HostBasedAuth", func(t *testing.T) {
+		rootPgUrl, cleanupFn := sqlutils.PGUrl(
+			t, s.ServingAddr(), "TestPGWireAuth", url.User(security.RootUser))
+		defer cleanupFn()
+		db, err := gosql.Open("postgres", rootPgUrl.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		// Reject all clients. Root can still connect with its certificate.
+		if _, err := db.Exec(
+			`SET CLUSTER SETTING server.host_based_authentication.configuration = 'host all all all reject'`,
+		); err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if _, err := db.Exec(
+				`SET CLUSTER SETTING server.host_based_authentication.configuration = DEFAULT`,
+			); err != nil {
+				t.Fatal(err)
+			}
+		}()
+
+		testUserPgUrl, cleanupFn := sqlutils.PGUrl(
+			t, s.ServingAddr(), "TestPGWireAuth", url.User(server.TestUser))
+		defer cleanupFn()
+		util.SucceedsSoon(t, func() error {
+			if err := trivialQuery(testUserPgUrl); !testutils.IsError(
+				err, "connection rejected by host-based authentication rule on line 1",
+			) {
+				return errors.Errorf("unexpected error: %v", err)
+			}
+			return nil
+		})
+		if err := trivialQuery(rootPgUrl); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// scramLogin logs in as the given user with a SCRAM-SHA-256 exchange. It
+// speaks the protocol directly, since lib/pq doesn't support SCRAM.
+func scramLogin(addr, user, password string) error {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	writeMsg := func(w io.Writer, typ byte, body []byte) error {
+		var buf bytes.Buffer
+		if typ != 0 {
+			buf.WriteByte(typ)
+		}
+		if err := binary.Write(&buf, binary.BigEndian, int32(len(body)+4)); err != nil {
+			return err
+		}
+		buf.Write(body)
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	// Negotiate TLS, without a client certificate.
+	if err := writeMsg(conn, 0, []byte{0x04, 0xd2, 0x16, 0x2f}); err != nil {
+		return err
+	}
+	var sslResponse [1]byte
+	if _, err := io.ReadFull(conn, sslResponse[:]); err != nil {
+		return err
+	}
+	if sslResponse[0] != 'S' {
+		return errors.Errorf("unexpected response to SSLRequest: %q", sslResponse[0])
+	}
+	tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true})
+	rd := bufio.NewReader(tlsConn)
+
+	// readAuthMsg reads an authentication message, returning its type and
+	// data, or the error sent by the server.
+	readAuthMsg := func() (int32, []byte, error) {
+		typ, err := rd.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		var size int32
+		if err := binary.Read(rd, binary.BigEndian, &size); err != nil {
+			return 0, nil, err
+		}
+		body := make([]byte, size-4)
+		if _, err := io.ReadFull(rd, body); err != nil {
+			return 0, nil, err
+		}
+		switch typ {
+		case 'R':
+			return int32(binary.BigEndian.Uint32(body)), body[4:], nil
+		case 'E':
+			// Return the message field of the error.
+			for _, field := range bytes.Split(body, []byte{0}) {
+				if len(field) > 0 && field[0] == 'M' {
+					return 0, nil, errors.New(string(field[1:]))
+				}
+			}
+			return 0, nil, errors.Errorf("malformed error: %q", body)
+		default:
+			return 0, nil, errors.Errorf("unexpected message type %q", typ)
+		}
+	}
+	expectAuthMsg := func(expected int32) ([]byte, error) {
+		typ, data, err := readAuthMsg()
+		if err != nil {
+			return nil, err
+		}
+		if typ != expected {
+			return nil, errors.Errorf("expected authentication message %d, got %d", expected, typ)
+		}
+		return data, nil
+	}
+
+	// The startup message: the protocol version, followed by the parameters.
+	startup := []byte{0, 3, 0, 0}
+	startup = append(startup, "user\x00"+user+"\x00\x00"...)
+	if err := writeMsg(tlsConn, 0, startup); err != nil {
+		return err
+	}
+	if _, err := expectAuthMsg(10 /* AuthenticationSASL */); err != nil {
+		return err
+	}
+
+	const clientNonce = "rOprNGfwEbeRWgbNEkqO"
+	clientFirstBare := "n=,r=" + clientNonce
+	initialResponse := []byte(security.SCRAMSHA256Mechanism + "\x00")
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, uint32(len("n,,"+clientFirstBare)))
+	initialResponse = append(initialResponse, size...)
+	initialResponse = append(initialResponse, "n,,"+clientFirstBare...)
+	if err := writeMsg(tlsConn, 'p', initialResponse); err != nil {
+		return err
+	}
+	serverFirst, err := expectAuthMsg(11 /* AuthenticationSASLContinue */)
+	if err != nil {
+		return err
+	}
+
+	var nonce, salt string
+	var iterations int
+	for _, attr := range strings.Split(string(serverFirst), ",") {
+		switch attr[:2] {
+		case "r=":
+			nonce = attr[2:]
+		case "s=":
+			salt = attr[2:]
+		case "i=":
+			if iterations, err = strconv.Atoi(attr[2:]); err != nil {
+				return err
+			}
+		}
+	}
+	if !strings.HasPrefix(nonce, clientNonce) {
+		return errors.Errorf("unexpected server nonce %q", nonce)
+	}
+	rawSalt, err := base64.StdEncoding.DecodeString(salt)
+	if err != nil {
+		return err
+	}
+
+	mac := func(key []byte, msg string) []byte {
+		h := hmac.New(sha256.New, key)
+		_, _ = h.Write([]byte(msg))
+		return h.Sum(nil)
+	}
+	saltedPassword := pbkdf2.Key([]byte(password), rawSalt, iterations, sha256.Size, sha256.New)
+	clientKey := mac(saltedPassword, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	clientFinalWithoutProof := "c=biws,r=" + nonce
+	authMessage := clientFirstBare + "," + string(serverFirst) + "," + clientFinalWithoutProof
+	proof := mac(storedKey[:], authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	clientFinal := clientFinalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof)
+	if err := writeMsg(tlsConn, 'p', []byte(clientFinal)); err != nil {
+		return err
+	}
+
+	serverFinal, err := expectAuthMsg(12 /* AuthenticationSASLFinal */)
+	if err != nil {
+		return err
+	}
+	serverSignature := mac(mac(saltedPassword, "Server Key"), authMessage)
+	if expected := "v=" + base64.StdEncoding.EncodeToString(serverSignature); string(serverFinal) != expected {
+		return errors.Errorf("expected server-final-message %q, got %q", expected, serverFinal)
+	}
+	_, err = expectAuthMsg(0 /* AuthenticationOk */)
+	return err
 }
@@ -250,6 +250,10 @@ func (p *planner) newPlan(
 		return p.AlterTable(n)
 	case *parser.BeginTransaction:
 		return p.BeginTransaction(n)
+	case *parser.CancelChangeFeed:
+		return p.CancelChangeFeed(n)
+	case *parser.ChangeFeed:
+		return p.ChangeFeed(n)
 	case CopyDataBlock:
 		return p.CopyData(n, autoCommit)
 	case *parser.CopyFrom:
@@ -258,6 +262,8 @@ func (p *planner) newPlan(
 		return p.CreateDatabase(n)
 	case *parser.CreateIndex:
 		return p.CreateIndex(n)
+	case *parser.CreateRole:
+		return p.CreateRole(n)
 	case *parser.CreateTable:
 		return p.CreateTable(n)
 	case *parser.CreateUser:
@@ -270,6 +276,8 @@ func (p *planner) newPlan(
 		return p.DropDatabase(n)
 	case *parser.DropIndex:
 		return p.DropIndex(n)
+	case *parser.DropRole:
+		return p.DropRole(n)
 	case *parser.DropTable:
 		return p.DropTable(n)
 	case *parser.DropView:
@@ -278,6 +286,8 @@ func (p *planner) newPlan(
 		return p.Explain(n, autoCommit)
 	case *parser.Grant:
 		return p.Grant(n)
+	case *parser.GrantRole:
+		return p.GrantRole(n)
 	case *parser.Help:
 		return p.Help(n)
 	case *parser.Insert:
@@ -294,12 +304,16 @@ func (p *planner) newPlan(
 		return p.RenameTable(n)
 	case *parser.Revoke:
 		return p.Revoke(n)
+	case *parser.RevokeRole:
+		return p.RevokeRole(n)
 	case *parser.Select:
 		return p.Select(n, desiredTypes, autoCommit)
 	case *parser.SelectClause:
 		return p.SelectClause(n, nil, nil, desiredTypes, publicColumns)
 	case *parser.Set:
 		return p.Set(n)
+	case *parser.SetClusterSetting:
+		return p.SetClusterSetting(n)
 	case *parser.SetTimeZone:
 		return p.SetTimeZone(n)
 	case *parser.SetTransaction:
@@ -308,6 +322,10 @@ func (p *planner) newPlan(
 		return p.SetDefaultIsolation(n)
 	case *parser.Show:
 		return p.Show(n)
+	case *parser.ShowChangeFeeds:
+		return p.ShowChangeFeeds(n)
+	case *parser.ShowClusterSetting:
+		return p.ShowClusterSetting(n)
 	case *parser.ShowColumns:
 		return p.ShowColumns(n)
 	case *parser.ShowConstraints:
@@ -322,6 +340,10 @@ func (p *planner) newPlan(
 		return p.ShowGrants(n)
 	case *parser.ShowIndex:
 		return p.ShowIndex(n)
+	case *parser.ShowRoleGrants:
+		return p.ShowRoleGrants(n)
+	case *parser.ShowRoles:
+		return p.ShowRoles(n)
 	case *parser.ShowTables:
 		return p.ShowTables(n)
 	case *parser.ShowUsers:
@@ -330,6 +352,8 @@ func (p *planner) newPlan(
 		return p.Split(n)
 	case *parser.Truncate:
 		return p.Truncate(n)
+	case *parser.UndropTable:
+		return p.UndropTable(n)
 	case *parser.UnionClause:
 		return p.UnionClause(n, desiredTypes, autoCommit)
 	case *parser.Update:
@@ -355,6 +379,10 @@ func (p *planner) prepare(stmt parser.Statement) (planNode, error) {
 		return p.SelectClause(n, nil, nil, nil, publicColumns)
 	case *parser.Show:
 		return p.Show(n)
+	case *parser.ShowChangeFeeds:
+		return p.ShowChangeFeeds(n)
+	case *parser.ShowClusterSetting:
+		return p.ShowClusterSetting(n)
 	case *parser.ShowCreateTable:
 		return p.ShowCreateTable(n)
 	case *parser.ShowCreateView:
@@ -369,6 +397,10 @@ func (p *planner) prepare(stmt parser.Statement) (planNode, error) {
 		return p.ShowIndex(n)
 	case *parser.ShowConstraints:
 		return p.ShowConstraints(n)
+	case *parser.ShowRoleGrants:
+		return p.ShowRoleGrants(n)
+	case *parser.ShowRoles:
+		return p.ShowRoles(n)
 	case *parser.ShowTables:
 		return p.ShowTables(n)
 	case *parser.ShowUsers:
@@ -51,6 +51,8 @@ type planner struct {
 	// database descriptors.
 	systemConfig  config.SystemConfig
 	databaseCache *databaseCache
+	// roleCache caches the role memberships found in systemConfig.
+	roleCache *roleCache
 
 	testingVerifyMetadataFn func(config.SystemConfig) error
 	verifyFnCheckedOnce     bool
@@ -70,6 +72,11 @@ type planner struct {
 	// If set, contains the in progress COPY FROM columns.
 	copyFrom *copyNode
 
+	// auditEvents are the accesses to audited tables by the current
+	// statement. They are written to the audit log once the statement has
+	// been executed.
+	auditEvents []auditEvent
+
 	// Avoid allocations by embedding commonly used visitors.
 	subqueryVisitor             subqueryVisitor
 	subqueryPlanVisitor         subqueryPlanVisitor
@@ -244,14 +251,16 @@ func finishInternalPlanner(p *planner) {
 func (p *planner) resetForBatch(e *Executor) {
 	// Update the systemConfig to a more recent copy, so that we can use tables
 	// that we created in previus batches of the same transaction.
-	cfg, cache := e.getSystemConfig()
+	cfg, cache, roles := e.getSystemConfig()
 	p.systemConfig = cfg
 	p.databaseCache = cache
+	p.roleCache = roles
 	p.session.TxnState.schemaChangers.curGroupNum++
 	p.resetContexts()
 	p.evalCtx.NodeID = e.cfg.NodeID.Get()
 	p.evalCtx.ReCache = e.reCache
 	p.evalCtx.Database = p.session.Database
+	p.evalCtx.User = p.session.User
 	p.evalCtx.SearchPath = p.session.SearchPath
 }
 
@@ -20,6 +20,8 @@ import (
 	"bytes"
 	"sort"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
 //go:generate stringer -type=Kind
@@ -58,6 +60,18 @@ var ByValue = [...]Kind{
 	ALL, CREATE, DROP, GRANT, SELECT, INSERT, DELETE, UPDATE,
 }
 
+// ByName is a map of string -> kind value.
+var ByName = map[string]Kind{
+	"ALL":    ALL,
+	"CREATE": CREATE,
+	"DROP":   DROP,
+	"GRANT":  GRANT,
+	"SELECT": SELECT,
+	"INSERT": INSERT,
+	"DELETE": DELETE,
+	"UPDATE": UPDATE,
+}
+
 // List is a list of privileges.
 type List []Kind
 
@@ -138,3 +152,17 @@ func ListFromBitField(m uint32) List {
 	}
 	return ret
 }
+
+// ListFromStrings takes a list of privilege names and returns a list of
+// privileges. The names are matched case-insensitively.
+func ListFromStrings(strs []string) (List, error) {
+	ret := make(List, len(strs))
+	for i, s := range strs {
+		k, ok := ByName[strings.ToUpper(s)]
+		if !ok {
+			return nil, errors.Errorf("not a valid privilege: %q", s)
+		}
+		ret[i] = k
+	}
+	return ret, nil
+}
@@ -62,3 +62,17 @@ func TestPrivilegeDecode(t *testing.T) {
 		}
 	}
 }
+
+func TestPrivilegeListFromStrings(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	pl, err := privilege.ListFromStrings([]string{"select", "INSERT", "Drop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := pl.String(); s != "SELECT, INSERT, DROP" {
+		t.Errorf("unexpected privileges: %s", s)
+	}
+	if _, err := privilege.ListFromStrings([]string{"select", "foo"}); err == nil {
+		t.Error("expected an error for an unknown privilege")
+	}
+}
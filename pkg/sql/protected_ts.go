@@ -0,0 +1,379 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// protectedTimestampExpirationInterval is the interval at which expired
+// records are removed from the protected timestamp table.
+var protectedTimestampExpirationInterval = settings.RegisterPositiveDurationSetting(
+	"kv.protectedts.expiration_interval",
+	"the interval at which expired records are removed from system.protected_ts",
+	time.Minute,
+)
+
+// protectedTimestampPollInterval is the interval at which each node reads
+// the protected timestamp table into its cache.
+var protectedTimestampPollInterval = settings.RegisterPositiveDurationSetting(
+	"kv.protectedts.poll_interval",
+	"the interval at which each node reads the records of system.protected_ts",
+	30*time.Second,
+)
+
+// protectedTimestampMaxStaleness returns how old the cache of the protected
+// timestamp table may be when it's used to decide what the GC queue may
+// collect. It leaves room for one failed read of the table.
+func protectedTimestampMaxStaleness() time.Duration {
+	return 2 * protectedTimestampPollInterval.Get()
+}
+
+// protectedTimestampRecord is a record of the protected timestamp table, as
+// cached by ProtectedTimestamps.
+type protectedTimestampRecord struct {
+	span       roachpb.Span
+	ts         hlc.Timestamp
+	expiration time.Time
+}
+
+// ProtectedTimestamps manages the records of the system.protected_ts table.
+// A job which reads the MVCC versions of a span at a timestamp which may
+// fall behind the span's GC TTL while it runs (e.g. a backup) protects the
+// timestamp by writing a record, and removes the record when it's done. The
+// GC queue doesn't garbage collect versions visible at the minimum protected
+// timestamp of a range.
+//
+// Each record has an expiration which its owner must keep extending using
+// Heartbeat while it runs. The records of owners which died are ignored once
+// they expire, and are eventually removed.
+//
+// The GC queue doesn't read the table for every range it processes: each
+// node caches the records, reading the whole table every poll interval, and
+// refuses to GC a range using a cache which is too stale. A record only
+// protects its span once Verify has returned, which waits until every cache
+// is guaranteed to contain the record.
+type ProtectedTimestamps struct {
+	InternalExecutor
+	db    client.DB
+	clock *hlc.Clock
+
+	mu struct {
+		syncutil.Mutex
+		// records are the records read at readAt, the physical time at which
+		// the last successful read of the table started.
+		records []protectedTimestampRecord
+		readAt  time.Time
+	}
+}
+
+// NewProtectedTimestamps creates a ProtectedTimestamps. A LeaseManager is
+// required in order to correctly execute SQL statements.
+func NewProtectedTimestamps(
+	db client.DB, clock *hlc.Clock, leaseMgr *LeaseManager,
+) *ProtectedTimestamps {
+	return &ProtectedTimestamps{
+		InternalExecutor: InternalExecutor{LeaseManager: leaseMgr},
+		db:               db,
+		clock:            clock,
+	}
+}
+
+// Protect writes a record protecting the versions of the span visible at
+// the given timestamp on behalf of owner, as part of the provided
+// transaction, and returns the record's ID. The record expires at the given
+// expiration unless it's extended. Once the transaction has committed, the
+// caller must call Verify before relying on the protection.
+func (pts *ProtectedTimestamps) Protect(
+	txn *client.Txn, span roachpb.Span, ts hlc.Timestamp, owner string, expiration time.Time,
+) (int64, error) {
+	if len(span.EndKey) == 0 || span.Key.Compare(span.EndKey) >= 0 {
+		return 0, errors.Errorf("invalid span %s", span)
+	}
+	if ts == hlc.ZeroTimestamp {
+		return 0, errors.New("cannot protect the zero timestamp")
+	}
+	p := makeInternalPlanner("protect-timestamp", txn, security.RootUser, pts.LeaseManager.memMetrics)
+	defer finishInternalPlanner(p)
+	const insertRecord = `INSERT INTO system.protected_ts ` +
+		`(startKey, endKey, wallTime, logical, owner, expiration) ` +
+		`VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	values, err := p.queryRow(insertRecord, []byte(span.Key), []byte(span.EndKey),
+		ts.WallTime, int64(ts.Logical), owner, expiration)
+	if err != nil {
+		return 0, err
+	}
+	return int64(*values[0].(*parser.DInt)), nil
+}
+
+// Verify waits until the record protecting the span at the given timestamp,
+// which must have been committed before Verify is called, is visible to the
+// GC queue of every node, and then checks that no range of the span was
+// garbage collected above the timestamp in the meantime. An error means the
+// versions visible at the timestamp may already have been removed.
+//
+// Verify doesn't use a transaction: after the wait, the cache of every node
+// was read after the record was committed, so that any GC of the span which
+// happens later keeps the versions visible at the timestamp. Any GC which
+// happened earlier has raised the range's GC threshold, which fails the
+// reads at the timestamp which Verify sends to every range of the span.
+func (pts *ProtectedTimestamps) Verify(
+	ctx context.Context, span roachpb.Span, ts hlc.Timestamp,
+) error {
+	wait := protectedTimestampMaxStaleness() + pts.clock.MaxOffset()
+	select {
+	case <-time.After(wait):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	startKey, err := keys.Addr(span.Key)
+	if err != nil {
+		return err
+	}
+	endKey, err := keys.Addr(span.EndKey)
+	if err != nil {
+		return err
+	}
+	// Range descriptors are stored in meta2 at their end key, so the
+	// descriptors of the span start after the meta2 key of its start key
+	// and end with the first one whose end key isn't below the span's.
+	var descs []roachpb.RangeDescriptor
+	for metaKey := keys.RangeMetaKey(startKey).Next(); ; {
+		const batchSize = 100
+		descKVs, err := pts.db.Scan(ctx, metaKey, keys.Meta2KeyMax, batchSize)
+		if err != nil {
+			return err
+		}
+		done := len(descKVs) < batchSize
+		for _, kv := range descKVs {
+			var desc roachpb.RangeDescriptor
+			if err := kv.ValueProto(&desc); err != nil {
+				return err
+			}
+			descs = append(descs, desc)
+			if !desc.EndKey.Less(endKey) {
+				done = true
+				break
+			}
+		}
+		if done {
+			break
+		}
+		metaKey = descKVs[len(descKVs)-1].Key.Next()
+	}
+	return pts.db.Txn(ctx, func(txn *client.Txn) error {
+		setTxnTimestamps(txn, ts)
+		b := txn.NewBatch()
+		for _, desc := range descs {
+			key := desc.StartKey.AsRawKey()
+			if key.Compare(span.Key) < 0 {
+				key = span.Key
+			}
+			// Reading any key of the range checks its GC threshold.
+			b.Get(key)
+		}
+		if err := txn.Run(b); err != nil {
+			return errors.Wrapf(err, "unable to verify the protection of %s at %s", span, ts)
+		}
+		return nil
+	})
+}
+
+// Heartbeat extends the expiration of the record with the given ID as part
+// of the provided transaction. It returns an error if the record doesn't
+// exist, e.g. because it expired and was removed.
+func (pts *ProtectedTimestamps) Heartbeat(
+	txn *client.Txn, id int64, expiration time.Time,
+) error {
+	p := makeInternalPlanner("protect-timestamp-heartbeat", txn, security.RootUser, pts.LeaseManager.memMetrics)
+	defer finishInternalPlanner(p)
+	const updateRecord = `UPDATE system.protected_ts SET expiration = $2 WHERE id = $1`
+	count, err := p.exec(updateRecord, id, expiration)
+	if err != nil {
+		return err
+	}
+	if count != 1 {
+		return errors.Errorf("protected timestamp record %d not found", id)
+	}
+	return nil
+}
+
+// Release removes the record with the given ID as part of the provided
+// transaction.
+func (pts *ProtectedTimestamps) Release(txn *client.Txn, id int64) error {
+	p := makeInternalPlanner("protect-timestamp-release", txn, security.RootUser, pts.LeaseManager.memMetrics)
+	defer finishInternalPlanner(p)
+	const deleteRecord = `DELETE FROM system.protected_ts WHERE id = $1`
+	_, err := p.exec(deleteRecord, id)
+	return err
+}
+
+// MinProtectedTimestamp implements the storage.ProtectedTimestampReader
+// interface. It returns an error if the records haven't been read recently
+// enough for the result to be trusted.
+func (pts *ProtectedTimestamps) MinProtectedTimestamp(
+	ctx context.Context, span roachpb.Span,
+) (hlc.Timestamp, error) {
+	pts.mu.Lock()
+	defer pts.mu.Unlock()
+	if pts.mu.readAt.IsZero() {
+		return hlc.ZeroTimestamp, errors.New("protected timestamps haven't been read yet")
+	}
+	now := time.Unix(0, pts.clock.PhysicalNow())
+	if age := now.Sub(pts.mu.readAt); age > protectedTimestampMaxStaleness() {
+		return hlc.ZeroTimestamp, errors.Errorf("protected timestamps were last read %s ago", age)
+	}
+	var ts hlc.Timestamp
+	for _, r := range pts.mu.records {
+		if !r.expiration.After(now) || span.Key.Compare(r.span.EndKey) >= 0 ||
+			r.span.Key.Compare(span.EndKey) >= 0 {
+			continue
+		}
+		if ts == hlc.ZeroTimestamp || r.ts.Less(ts) {
+			ts = r.ts
+		}
+	}
+	return ts, nil
+}
+
+// Refresh reads all the records of the protected timestamp table into the
+// cache used by MinProtectedTimestamp.
+func (pts *ProtectedTimestamps) Refresh(ctx context.Context) error {
+	readAt := time.Unix(0, pts.clock.PhysicalNow())
+	var records []protectedTimestampRecord
+	if err := pts.db.Txn(ctx, func(txn *client.Txn) error {
+		records = nil
+		p := makeInternalPlanner("protected-timestamp-refresh", txn, security.RootUser, pts.LeaseManager.memMetrics)
+		defer finishInternalPlanner(p)
+		const allRecords = `SELECT startKey, endKey, wallTime, logical, expiration ` +
+			`FROM system.protected_ts`
+		plan, err := p.query(allRecords)
+		if isUndefinedTableError(err) {
+			// Clusters bootstrapped by older versions don't have the table
+			// until its migration has run, and nothing can be protected
+			// until then.
+			return nil
+		} else if err != nil {
+			return err
+		}
+		defer plan.Close()
+		if err := plan.Start(); err != nil {
+			return err
+		}
+		for {
+			next, err := plan.Next()
+			if err != nil {
+				return err
+			}
+			if !next {
+				return nil
+			}
+			row := plan.Values()
+			records = append(records, protectedTimestampRecord{
+				span: roachpb.Span{
+					Key:    roachpb.Key(*row[0].(*parser.DBytes)),
+					EndKey: roachpb.Key(*row[1].(*parser.DBytes)),
+				},
+				ts: hlc.Timestamp{
+					WallTime: int64(*row[2].(*parser.DInt)),
+					Logical:  int32(*row[3].(*parser.DInt)),
+				},
+				expiration: row[4].(*parser.DTimestamp).Time,
+			})
+		}
+	}); err != nil {
+		return err
+	}
+	pts.mu.Lock()
+	defer pts.mu.Unlock()
+	pts.mu.records = records
+	pts.mu.readAt = readAt
+	return nil
+}
+
+// RemoveExpired removes the records which have expired, returning the
+// number of removed records.
+func (pts *ProtectedTimestamps) RemoveExpired(ctx context.Context) (int, error) {
+	var count int
+	err := pts.db.Txn(ctx, func(txn *client.Txn) error {
+		p := makeInternalPlanner("protected-timestamp-expire", txn, security.RootUser, pts.LeaseManager.memMetrics)
+		defer finishInternalPlanner(p)
+		const deleteExpired = `DELETE FROM system.protected_ts WHERE expiration <= $1`
+		var err error
+		count, err = p.exec(deleteExpired, pts.now())
+		if isUndefinedTableError(err) {
+			return nil
+		}
+		return err
+	})
+	return count, err
+}
+
+func isUndefinedTableError(err error) bool {
+	_, ok := errors.Cause(err).(*sqlbase.ErrUndefinedTable)
+	return ok
+}
+
+// Start periodically reads the records into the cache and removes expired
+// records until the stopper is stopped.
+func (pts *ProtectedTimestamps) Start(stopper *stop.Stopper) {
+	stopper.RunWorker(func() {
+		ctx := context.TODO()
+		var timer timeutil.Timer
+		defer timer.Stop()
+		var lastExpiration time.Time
+		for {
+			if err := pts.Refresh(ctx); err != nil {
+				log.Warningf(ctx, "unable to read protected timestamp records: %s", err)
+			}
+			if timeutil.Since(lastExpiration) >= protectedTimestampExpirationInterval.Get() {
+				lastExpiration = timeutil.Now()
+				if count, err := pts.RemoveExpired(ctx); err != nil {
+					log.Warningf(ctx, "unable to remove expired protected timestamp records: %s", err)
+				} else if count > 0 {
+					log.Infof(ctx, "removed %d expired protected timestamp records", count)
+				}
+			}
+			timer.Reset(protectedTimestampPollInterval.Get())
+			select {
+			case <-timer.C:
+				timer.Read = true
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+func (pts *ProtectedTimestamps) now() time.Time {
+	return time.Unix(0, pts.clock.Now().WallTime)
+}
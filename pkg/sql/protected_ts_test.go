@@ -0,0 +1,197 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	csql "github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestProtectedTimestamps verifies that the minimum protected timestamp of
+// a span only considers the unexpired records overlapping the span, and
+// that records can be released and expired.
+func TestProtectedTimestamps(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := createTestServerParams()
+	s, _, kvDB := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+	ctx := context.Background()
+
+	pts := csql.NewProtectedTimestamps(*kvDB, s.Clock(), s.LeaseManager().(*csql.LeaseManager))
+
+	// Nothing can be trusted to be unprotected until the records are read.
+	span := roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")}
+	if _, err := pts.MinProtectedTimestamp(ctx, span); !testutils.IsError(err, "haven't been read") {
+		t.Fatalf("expected error before the records are read, got %v", err)
+	}
+
+	now := s.Clock().Now()
+	later := time.Unix(0, now.WallTime).Add(time.Hour)
+	earlier := time.Unix(0, now.WallTime).Add(-time.Hour)
+	protect := func(start, end string, ts hlc.Timestamp, expiration time.Time) int64 {
+		var id int64
+		if err := kvDB.Txn(ctx, func(txn *client.Txn) error {
+			var err error
+			span := roachpb.Span{Key: roachpb.Key(start), EndKey: roachpb.Key(end)}
+			id, err = pts.Protect(txn, span, ts, "test", expiration)
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	ts1 := now
+	ts2 := now.Add(-1, 0)
+	ts3 := now.Add(-2, 0)
+	protect("b", "d", ts1, later)
+	id2 := protect("a", "c", ts2, later)
+	id3 := protect("b", "c", ts3, earlier)
+
+	testCases := []struct {
+		start, end string
+		expected   hlc.Timestamp
+	}{
+		{"a", "b", ts2},
+		{"b", "c", ts2},
+		{"c", "d", ts1},
+		{"a", "z", ts2},
+		{"d", "z", hlc.ZeroTimestamp},
+	}
+	verify := func() {
+		if err := pts.Refresh(ctx); err != nil {
+			t.Fatal(err)
+		}
+		for i, c := range testCases {
+			span := roachpb.Span{Key: roachpb.Key(c.start), EndKey: roachpb.Key(c.end)}
+			ts, err := pts.MinProtectedTimestamp(ctx, span)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ts != c.expected {
+				t.Errorf("%d: expected %s for %s, got %s", i, c.expected, span, ts)
+			}
+		}
+	}
+	verify()
+
+	// Releasing a record stops it from protecting its span.
+	if err := kvDB.Txn(ctx, func(txn *client.Txn) error {
+		return pts.Release(txn, id2)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	testCases[0].expected = hlc.ZeroTimestamp
+	testCases[1].expected = ts1
+	testCases[3].expected = ts1
+	verify()
+
+	// Only the expired record is removed, after which it can't be extended.
+	if count, err := pts.RemoveExpired(ctx); err != nil {
+		t.Fatal(err)
+	} else if count != 1 {
+		t.Fatalf("expected 1 expired record to be removed, got %d", count)
+	}
+	verify()
+	if err := kvDB.Txn(ctx, func(txn *client.Txn) error {
+		return pts.Heartbeat(txn, id3, later)
+	}); !testutils.IsError(err, "not found") {
+		t.Fatalf("expected heartbeat of removed record to fail, got %v", err)
+	}
+
+	// Clusters bootstrapped by older versions don't have the table until its
+	// migration has run, in which case nothing is protected.
+	if err := kvDB.Txn(ctx, func(txn *client.Txn) error {
+		desc := &sqlbase.ProtectedTimestampTable
+		txn.SetSystemConfigTrigger()
+		b := txn.NewBatch()
+		b.Del(sqlbase.MakeNameMetadataKey(desc.ParentID, desc.Name))
+		b.Del(sqlbase.MakeDescMetadataKey(desc.ID))
+		return txn.Run(b)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i := range testCases {
+		testCases[i].expected = hlc.ZeroTimestamp
+	}
+	verify()
+	if _, err := pts.RemoveExpired(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestProtectedTimestampsVerify verifies that a protection is only reported
+// as verified if no range of its span was garbage collected above the
+// protected timestamp, and that stale records aren't used.
+func TestProtectedTimestampsVerify(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer csql.TestingSetProtectedTimestampPollInterval(time.Millisecond)()
+	params, _ := createTestServerParams()
+	s, _, kvDB := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop()
+	ctx := context.Background()
+
+	pts := csql.NewProtectedTimestamps(*kvDB, s.Clock(), s.LeaseManager().(*csql.LeaseManager))
+
+	// Once the records haven't been read for a while, they can't be used.
+	span := roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")}
+	if err := pts.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if _, err := pts.MinProtectedTimestamp(ctx, span); !testutils.IsError(err, "last read") {
+		t.Fatalf("expected error for stale records, got %v", err)
+	}
+
+	now := s.Clock().Now()
+	expiration := time.Unix(0, now.WallTime).Add(time.Hour)
+	protect := func(span roachpb.Span, ts hlc.Timestamp) {
+		if err := kvDB.Txn(ctx, func(txn *client.Txn) error {
+			_, err := pts.Protect(txn, span, ts, "test", expiration)
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A timestamp which was garbage collected before its record was written
+	// isn't protected.
+	b := &client.Batch{}
+	b.AddRawRequest(&roachpb.GCRequest{Span: span, Threshold: now})
+	if err := kvDB.Run(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	protect(span, now.Prev())
+	if err := pts.Verify(ctx, span, now.Prev()); !testutils.IsError(err, "GC threshold") {
+		t.Fatalf("expected verification to fail, got %v", err)
+	}
+
+	// A timestamp above the GC threshold of the span's ranges is.
+	later := s.Clock().Now()
+	protect(span, later)
+	if err := pts.Verify(ctx, span, later); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,460 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// A role is a row of system.users with isRole set. Roles can't log in, but
+// privileges can be granted to them like to any user, and every member of a
+// role, whether a user or another role, inherits the privileges of the role.
+// Memberships are stored in system.role_members, which is part of the system
+// config so that privilege checks can resolve them without a KV lookup.
+
+// CreateRole creates a role.
+// Privileges: INSERT on system.users.
+//   notes: postgres requires the CREATEROLE attribute.
+func (p *planner) CreateRole(n *parser.CreateRole) (planNode, error) {
+	name := n.Name.Normalize()
+	if name == "" {
+		return nil, errors.New("no role name specified")
+	}
+	if name == security.RootUser || name == security.NodeUser {
+		return nil, errors.Errorf("role name %s is reserved", name)
+	}
+	if err := p.checkSystemTablePrivilege("users", privilege.INSERT); err != nil {
+		return nil, err
+	}
+
+	if _, err := p.exec(
+		`INSERT INTO system.users (username, "isRole") VALUES ($1, true)`, name,
+	); err != nil {
+		if _, ok := err.(*sqlbase.ErrUniquenessConstraintViolation); ok {
+			err = errors.Errorf("a user or role named %s already exists", name)
+		}
+		return nil, err
+	}
+	return &emptyNode{}, nil
+}
+
+// DropRole drops roles. A role can only be dropped once all the privileges
+// granted to it have been revoked.
+// Privileges: DELETE on system.users and system.role_members.
+//   notes: postgres requires the CREATEROLE attribute.
+func (p *planner) DropRole(n *parser.DropRole) (planNode, error) {
+	if err := p.checkSystemTablePrivilege("users", privilege.DELETE); err != nil {
+		return nil, err
+	}
+	if err := p.checkSystemTablePrivilege("role_members", privilege.DELETE); err != nil {
+		return nil, err
+	}
+
+	var descs []sqlbase.DescriptorProto
+	for _, roleName := range n.Names {
+		name := roleName.Normalize()
+		if err := p.checkRoleExists(name); err != nil {
+			if n.IfExists {
+				if _, ok := err.(*roleDoesNotExistError); ok {
+					continue
+				}
+			}
+			return nil, err
+		}
+
+		if descs == nil {
+			var err error
+			if descs, err = p.getAllDescriptors(); err != nil {
+				return nil, err
+			}
+		}
+		for _, desc := range descs {
+			if desc.GetPrivileges().AnyPrivilege(name) {
+				return nil, errors.Errorf("role %s cannot be dropped because it has privileges on %s %s",
+					name, desc.TypeName(), desc.GetName())
+			}
+		}
+
+		if _, err := p.exec(`DELETE FROM system.users WHERE username = $1`, name); err != nil {
+			return nil, err
+		}
+		if _, err := p.exec(
+			`DELETE FROM system.role_members WHERE role = $1 OR member = $1`, name,
+		); err != nil {
+			return nil, err
+		}
+	}
+	return &emptyNode{}, nil
+}
+
+// GrantRole makes users or roles members of roles.
+// Privileges: INSERT on system.role_members.
+//   notes: postgres requires the ADMIN OPTION on the role.
+func (p *planner) GrantRole(n *parser.GrantRole) (planNode, error) {
+	if err := p.checkSystemTablePrivilege("role_members", privilege.INSERT); err != nil {
+		return nil, err
+	}
+	roles, members, err := p.resolveRoleGrant(n.Roles, n.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the memberships from the table rather than from the gossiped
+	// system config so that the cycle check sees the effect of earlier
+	// statements in the same transaction.
+	memberOf, err := p.loadRoleMemberships()
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		for _, member := range members {
+			if role == member {
+				return nil, errors.Errorf("%s cannot be a member of itself", role)
+			}
+			// Making member a member of role creates a cycle iff role already
+			// inherits from member.
+			for _, r := range memberOf.resolve(role) {
+				if r == member {
+					return nil, errors.Errorf(
+						"making %s a member of %s would create a cycle", member, role)
+				}
+			}
+			memberOf.add(role, member)
+			if _, err := p.exec(
+				`UPSERT INTO system.role_members (role, member) VALUES ($1, $2)`, role, member,
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &emptyNode{}, nil
+}
+
+// RevokeRole removes users or roles from roles.
+// Privileges: DELETE on system.role_members.
+//   notes: postgres requires the ADMIN OPTION on the role.
+func (p *planner) RevokeRole(n *parser.RevokeRole) (planNode, error) {
+	if err := p.checkSystemTablePrivilege("role_members", privilege.DELETE); err != nil {
+		return nil, err
+	}
+	roles, members, err := p.resolveRoleGrant(n.Roles, n.Members)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		for _, member := range members {
+			if _, err := p.exec(
+				`DELETE FROM system.role_members WHERE role = $1 AND member = $2`, role, member,
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &emptyNode{}, nil
+}
+
+// ShowRoles returns all the roles.
+// Privileges: SELECT on system.users.
+func (p *planner) ShowRoles(n *parser.ShowRoles) (planNode, error) {
+	stmt, err := parser.ParseOneTraditional(
+		`SELECT username AS role FROM system.users WHERE "isRole" ORDER BY 1`)
+	if err != nil {
+		return nil, err
+	}
+	return p.newPlan(stmt, nil, true)
+}
+
+// ShowRoleGrants returns the members of the given roles, optionally limited
+// to the given grantees.
+// Privileges: SELECT on system.role_members.
+func (p *planner) ShowRoleGrants(n *parser.ShowRoleGrants) (planNode, error) {
+	if err := p.checkSystemTablePrivilege("role_members", privilege.SELECT); err != nil {
+		return nil, err
+	}
+	roles := make([]string, len(n.Roles))
+	for i, r := range n.Roles {
+		roles[i] = r.Normalize()
+	}
+	var grantees map[string]struct{}
+	if n.Grantees != nil {
+		grantees = make(map[string]struct{}, len(n.Grantees))
+		for _, g := range n.Grantees {
+			grantees[g.Normalize()] = struct{}{}
+		}
+	}
+
+	columns := ResultColumns{
+		{Name: "role", Typ: parser.TypeString},
+		{Name: "member", Typ: parser.TypeString},
+	}
+	return &delayedNode{
+		p:       p,
+		name:    n.String(),
+		columns: columns,
+		constructor: func(p *planner) (planNode, error) {
+			for _, role := range roles {
+				if err := p.checkRoleExists(role); err != nil {
+					return nil, err
+				}
+			}
+			memberOf, err := p.loadRoleMemberships()
+			if err != nil {
+				return nil, err
+			}
+			v := p.newContainerValuesNode(columns, 0)
+			for _, role := range roles {
+				for _, member := range memberOf.members(role) {
+					if grantees != nil {
+						if _, ok := grantees[member]; !ok {
+							continue
+						}
+					}
+					if err := v.rows.AddRow(parser.DTuple{
+						parser.NewDString(role),
+						parser.NewDString(member),
+					}); err != nil {
+						v.rows.Close()
+						return nil, err
+					}
+				}
+			}
+			return v, nil
+		},
+	}, nil
+}
+
+// checkSystemTablePrivilege checks that the current user has the given
+// privilege on the named system table.
+func (p *planner) checkSystemTablePrivilege(table string, privilege privilege.Kind) error {
+	tDesc, err := p.getTableDesc(&parser.TableName{
+		DatabaseName: "system", TableName: parser.Name(table)})
+	if err != nil {
+		return err
+	}
+	return p.checkPrivilege(tDesc, privilege)
+}
+
+type roleDoesNotExistError struct {
+	name string
+}
+
+func (e *roleDoesNotExistError) Error() string {
+	return "role " + e.name + " does not exist"
+}
+
+// checkRoleExists returns an error if name is not a role.
+func (p *planner) checkRoleExists(name string) error {
+	values, err := p.queryRow(`SELECT "isRole" FROM system.users WHERE username = $1`, name)
+	if err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return &roleDoesNotExistError{name: name}
+	}
+	if !bool(*values[0].(*parser.DBool)) {
+		return errors.Errorf("%s is a user, not a role", name)
+	}
+	return nil
+}
+
+// resolveRoleGrant normalizes the roles and members of a GRANT or REVOKE of
+// roles, checking that the roles are roles and that the members exist.
+func (p *planner) resolveRoleGrant(
+	roleNames, memberNames parser.NameList,
+) (roles []string, members []string, err error) {
+	for _, r := range roleNames {
+		role := r.Normalize()
+		if err := p.checkRoleExists(role); err != nil {
+			return nil, nil, err
+		}
+		roles = append(roles, role)
+	}
+	for _, m := range memberNames {
+		member := m.Normalize()
+		// The root user is not in system.users.
+		if member != security.RootUser {
+			values, err := p.queryRow(`SELECT username FROM system.users WHERE username = $1`, member)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(values) == 0 {
+				return nil, nil, errors.Errorf("user or role %s does not exist", member)
+			}
+		}
+		members = append(members, member)
+	}
+	return roles, members, nil
+}
+
+// loadRoleMemberships reads system.role_members.
+func (p *planner) loadRoleMemberships() (roleMemberships, error) {
+	plan, err := p.query(`SELECT role, member FROM system.role_members`)
+	if err != nil {
+		return nil, err
+	}
+	defer plan.Close()
+	if err := plan.Start(); err != nil {
+		return nil, err
+	}
+	memberOf := roleMemberships{}
+	for {
+		next, err := plan.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !next {
+			break
+		}
+		row := plan.Values()
+		memberOf.add(string(*row[0].(*parser.DString)), string(*row[1].(*parser.DString)))
+	}
+	return memberOf, nil
+}
+
+// roleMemberships maps each user or role to the roles it is a direct member
+// of.
+type roleMemberships map[string][]string
+
+func (m roleMemberships) add(role, member string) {
+	for _, r := range m[member] {
+		if r == role {
+			return
+		}
+	}
+	m[member] = append(m[member], role)
+}
+
+// members returns the direct members of role, sorted.
+func (m roleMemberships) members(role string) []string {
+	var members []string
+	for member, roles := range m {
+		for _, r := range roles {
+			if r == role {
+				members = append(members, member)
+				break
+			}
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// resolve returns the roles that member is a direct or indirect member of,
+// sorted.
+func (m roleMemberships) resolve(member string) []string {
+	seen := map[string]struct{}{}
+	queue := m[member]
+	var roles []string
+	for len(queue) > 0 {
+		role := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[role]; ok {
+			continue
+		}
+		seen[role] = struct{}{}
+		roles = append(roles, role)
+		queue = append(queue, m[role]...)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// roleCache caches the roles users are transitively members of. It is
+// populated from the rows of system.role_members in the system config as
+// users are looked up and, like the databaseCache, a new cache is created
+// whenever the system config changes.
+type roleCache struct {
+	mu syncutil.Mutex
+	// memberOf holds the decoded memberships; it is nil until the first
+	// lookup.
+	memberOf roleMemberships
+	roles    map[string][]string
+}
+
+func newRoleCache() *roleCache {
+	return &roleCache{roles: map[string][]string{}}
+}
+
+// getRoles returns the roles user is transitively a member of according to
+// cfg, which must be the system config the cache was created for. A nil cache
+// decodes the memberships from cfg on every call.
+func (c *roleCache) getRoles(cfg config.SystemConfig, user string) ([]string, error) {
+	if c == nil {
+		memberOf, err := decodeRoleMemberships(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return memberOf.resolve(user), nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if roles, ok := c.roles[user]; ok {
+		return roles, nil
+	}
+	if c.memberOf == nil {
+		memberOf, err := decodeRoleMemberships(cfg)
+		if err != nil {
+			return nil, err
+		}
+		c.memberOf = memberOf
+	}
+	roles := c.memberOf.resolve(user)
+	c.roles[user] = roles
+	return roles, nil
+}
+
+// decodeRoleMemberships decodes the rows of system.role_members found in the
+// system config. All the columns of the table are part of its primary key, so
+// every row is a single key.
+func decodeRoleMemberships(cfg config.SystemConfig) (roleMemberships, error) {
+	tbl := &sqlbase.RoleMembersTable
+	prefix := roachpb.Key(keys.MakeTablePrefix(uint32(tbl.ID)))
+	var a sqlbase.DatumAlloc
+	vals := make([]parser.Datum, 2)
+	memberOf := roleMemberships{}
+	for _, kv := range cfg.Values {
+		if !bytes.HasPrefix(kv.Key, prefix) {
+			continue
+		}
+		_, ok, err := sqlbase.DecodeIndexKey(&a, tbl, tbl.PrimaryIndex.ID,
+			[]parser.Type{parser.TypeString, parser.TypeString}, vals, nil, kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, errors.Errorf("%s is not a key of %s", kv.Key, tbl.Name)
+		}
+		memberOf.add(string(*vals[0].(*parser.DString)), string(*vals[1].(*parser.DString)))
+	}
+	return memberOf, nil
+}
+
+// getRoles returns the roles the current user is transitively a member of.
+func (p *planner) getRoles() ([]string, error) {
+	return p.roleCache.getRoles(p.systemConfig, p.session.User)
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestRoleMembershipsResolve(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	m := roleMemberships{}
+	m.add("readers", "writers")
+	m.add("writers", "admins")
+	m.add("readers", "admins")
+	m.add("auditors", "alice")
+	m.add("admins", "alice")
+	// Adding a membership twice is a no-op.
+	m.add("admins", "alice")
+
+	testCases := []struct {
+		member string
+		roles  []string
+	}{
+		{"alice", []string{"admins", "auditors", "readers", "writers"}},
+		{"admins", []string{"readers", "writers"}},
+		{"writers", []string{"readers"}},
+		{"readers", nil},
+		{"bob", nil},
+	}
+	for _, tc := range testCases {
+		if roles := m.resolve(tc.member); !reflect.DeepEqual(roles, tc.roles) {
+			t.Errorf("%s: expected roles %v, got %v", tc.member, tc.roles, roles)
+		}
+	}
+
+	if members := m.members("readers"); !reflect.DeepEqual(members, []string{"admins", "writers"}) {
+		t.Errorf("unexpected members of readers: %v", members)
+	}
+}
+
+func TestRoleCache(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tbl := &sqlbase.RoleMembersTable
+	makeKV := func(role, member string) roachpb.KeyValue {
+		key := sqlbase.MakeIndexKeyPrefix(tbl, tbl.PrimaryIndex.ID)
+		key = encoding.EncodeStringAscending(key, role)
+		key = encoding.EncodeStringAscending(key, member)
+		return roachpb.KeyValue{Key: keys.MakeFamilyKey(key, 0)}
+	}
+	cfg := config.SystemConfig{Values: []roachpb.KeyValue{
+		makeKV("readers", "writers"),
+		makeKV("writers", "alice"),
+	}}
+
+	for _, c := range []*roleCache{nil, newRoleCache()} {
+		// Look the user up twice to hit the cache.
+		for i := 0; i < 2; i++ {
+			roles, err := c.getRoles(cfg, "alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := []string{"readers", "writers"}; !reflect.DeepEqual(roles, expected) {
+				t.Errorf("expected roles %v, got %v", expected, roles)
+			}
+		}
+	}
+}
@@ -268,6 +268,7 @@ func (n *scanNode) initTable(
 ) error {
 	n.desc = *desc
 
+	p.maybeAudit(desc, privilege.SELECT)
 	if !p.skipSelectPrivilegeChecks {
 		if err := p.checkPrivilege(&n.desc, privilege.SELECT); err != nil {
 			return err
@@ -24,6 +24,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
@@ -73,6 +74,33 @@ func (sc *SchemaChanger) truncateAndDropTable(
 	return truncateAndDropTable(ctx, tableDesc, &sc.db)
 }
 
+// releaseDroppedTableName deletes the namespace entry of the dropped table,
+// unless the name has already been reused. It returns whether the GC TTL of
+// the table has expired, in which case the table can be truncated.
+func (sc *SchemaChanger) releaseDroppedTableName() (bool, error) {
+	var expired bool
+	err := sc.db.Txn(context.TODO(), func(txn *client.Txn) error {
+		txn.SetSystemConfigTrigger()
+		tableDesc, err := sqlbase.GetTableDescFromID(txn, sc.tableID)
+		if err != nil {
+			return err
+		}
+		if !tableDesc.Dropped() {
+			return errors.Errorf("table %d is no longer being dropped", sc.tableID)
+		}
+		if err := releaseTableName(txn, tableDesc); err != nil {
+			return err
+		}
+		deadline, err := dropDeadline(txn, tableDesc)
+		if err != nil {
+			return err
+		}
+		expired = !timeutil.Now().Before(deadline)
+		return nil
+	})
+	return expired, err
+}
+
 // NewSchemaChangerForTesting only for tests.
 func NewSchemaChangerForTesting(
 	tableID sqlbase.ID,
@@ -235,6 +263,17 @@ func (sc SchemaChanger) exec() error {
 			return err
 		}
 
+		// Release the name of the table so that it can be reused right away.
+		// The table's data is kept until the GC TTL of its zone has passed,
+		// so that it can be brought back using UNDROP TABLE until then.
+		expired, err := sc.releaseDroppedTableName()
+		if err != nil {
+			return err
+		}
+		if !expired {
+			return nil
+		}
+
 		// Truncate the table and delete the descriptor.
 		if err := sc.truncateAndDropTable(context.TODO(), &lease, table); err != nil {
 			return err
@@ -736,6 +775,29 @@ func (s *SchemaChangeManager) newTimer() *time.Timer {
 	return &time.Timer{}
 }
 
+// droppedTableDeadline returns the time after which the data of the dropped
+// table can be deleted according to the system config, if the name of the
+// table has already been released.
+func droppedTableDeadline(
+	cfg config.SystemConfig, table *sqlbase.TableDescriptor,
+) (time.Time, bool) {
+	if !table.Dropped() {
+		return time.Time{}, false
+	}
+	nameKey := sqlbase.MakeNameMetadataKey(table.ParentID, table.Name)
+	if nameVal := cfg.GetValue(nameKey); nameVal != nil {
+		if id, err := nameVal.GetInt(); err != nil || sqlbase.ID(id) == table.ID {
+			return time.Time{}, false
+		}
+	}
+	zone, err := cfg.GetZoneConfigForKey(keys.MakeTablePrefix(uint32(table.ID)))
+	if err != nil {
+		log.Warningf(context.TODO(), "unable to get zone config of dropped table %d: %s", table.ID, err)
+		return time.Time{}, false
+	}
+	return time.Unix(0, table.DropTime).Add(time.Duration(zone.GC.TTLSeconds) * time.Second), true
+}
+
 // Start starts a goroutine that runs outstanding schema changes
 // for tables received in the latest system configuration via gossip.
 func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
@@ -810,11 +872,20 @@ func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 								schemaChanger.mutationID = table.Mutations[0].MutationID
 							}
 							schemaChanger.execAfter = execAfter
+							// A dropped table whose name has already been
+							// released only needs to be processed once its GC
+							// TTL has passed.
+							deadline, ok := droppedTableDeadline(cfg, table)
+							waitForTTL := ok && deadline.After(execAfter)
+							if waitForTTL {
+								schemaChanger.execAfter = deadline
+							}
 							// Keep track of this schema change.
 							// Remove from oldSchemaChangers map.
 							delete(oldSchemaChangers, table.ID)
 							if sc, ok := s.schemaChangers[table.ID]; ok {
-								if sc.mutationID == schemaChanger.mutationID {
+								if sc.mutationID == schemaChanger.mutationID &&
+									!(waitForTTL && deadline.After(sc.execAfter)) {
 									// Ignore duplicate.
 									continue
 								}
@@ -858,7 +929,10 @@ func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 						}
 						// Advance the execAfter time so that this schema
 						// changer doesn't get called again for a while.
-						sc.execAfter = timeutil.Now().Add(delay)
+						if _, ok := s.schemaChangers[tableID]; ok {
+							sc.execAfter = timeutil.Now().Add(delay)
+							s.schemaChangers[tableID] = sc
+						}
 					}
 					// Only attempt to run one schema changer.
 					break
@@ -695,6 +695,9 @@ CREATE UNIQUE INDEX vidx ON t.test (v);
 	// completed.
 	time.Sleep(10 * time.Millisecond)
 
+	if _, err := addImmediateGCZoneConfig(sqlDB, tableDesc.ID); err != nil {
+		t.Fatal(err)
+	}
 	if _, err := sqlDB.Exec("DROP TABLE t.test"); err != nil {
 		t.Fatal(err)
 	}
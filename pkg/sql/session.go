@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/mon"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -39,14 +40,22 @@ import (
 	"github.com/pkg/errors"
 )
 
-// COCKROACH_TRACE_SQL=duration can be used to log SQL transactions that take
-// longer than duration to complete. For example, COCKROACH_TRACE_SQL=1s will
-// log the trace for any transaction that takes 1s or longer. To log traces for
-// all transactions use COCKROACH_TRACE_SQL=1ns. Note that any positive
-// duration will enable tracing and will slow down all execution because traces
-// are gathered for all transactions even if they are not output.
-var traceSQLDuration = envutil.EnvOrDefaultDuration("COCKROACH_TRACE_SQL", 0)
-var traceSQL = traceSQLDuration > 0
+// traceTxnThreshold can be used to log SQL transactions that take longer than
+// the threshold to complete. For example, a threshold of 1s will log the trace
+// for any transaction that takes 1s or longer. To log traces for all
+// transactions use 1ns. Note that any positive duration will enable tracing
+// and will slow down all execution because traces are gathered for all
+// transactions even if they are not output.
+var traceTxnThreshold = settings.RegisterNonNegativeDurationSetting(
+	"sql.trace.txn.enable_threshold",
+	"duration beyond which all transactions are traced (set to 0 to disable)",
+	0,
+)
+
+// traceSQL returns whether SQL transactions are currently being traced.
+func traceSQL() bool {
+	return traceTxnThreshold.Get() > 0
+}
 
 // COCKROACH_TRACE_7881 can be used to trace all SQL transactions, in the hope
 // that we'll catch #7881 and dump the current trace for debugging.
@@ -70,6 +79,10 @@ type Session struct {
 	Syntax      int32
 	DistSQLMode distSQLExecMode
 
+	// clientAddr is the address of the client, or "<admin>" for sessions
+	// not created by SQL clients.
+	clientAddr string
+
 	virtualSchemas virtualSchemaHolder
 
 	// Info about the open transaction (if any).
@@ -127,24 +140,25 @@ func NewSession(
 		virtualSchemas: e.virtualSchemas,
 		memMetrics:     memMetrics,
 	}
-	cfg, cache := e.getSystemConfig()
+	cfg, cache, roles := e.getSystemConfig()
 	s.planner = planner{
 		leaseMgr:      e.cfg.LeaseManager,
 		systemConfig:  cfg,
 		databaseCache: cache,
+		roleCache:     roles,
 		session:       s,
 		execCfg:       &e.cfg,
 	}
 	s.PreparedStatements = makePreparedStatements(s)
 	s.PreparedPortals = makePreparedPortals(s)
 
+	s.clientAddr = "<admin>"
+	if remote != nil {
+		s.clientAddr = remote.String()
+	}
 	if opentracing.SpanFromContext(ctx) == nil {
-		remoteStr := "<admin>"
-		if remote != nil {
-			remoteStr = remote.String()
-		}
 		// Set up an EventLog for session events.
-		ctx = log.WithEventLog(ctx, fmt.Sprintf("sql [%s]", args.User), remoteStr)
+		ctx = log.WithEventLog(ctx, fmt.Sprintf("sql [%s]", args.User), s.clientAddr)
 		s.finishEventLog = true
 	}
 	s.context, s.cancel = context.WithCancel(ctx)
@@ -257,8 +271,8 @@ type txnState struct {
 	schemaChangers schemaChangerCollection
 
 	sp opentracing.Span
-	// When COCKROACH_TRACE_SQL is enabled, CollectedSpans accumulates spans as
-	// they're closed. All the spans pertain to the current txn.
+	// When sql.trace.txn.enable_threshold is set, CollectedSpans accumulates
+	// spans as they're closed. All the spans pertain to the current txn.
 	CollectedSpans []basictracer.RawSpan
 
 	// The timestamp to report for current_timestamp(), now() etc.
@@ -297,7 +311,7 @@ func (ts *txnState) resetForNewSQLTxn(e *Executor, s *Session) {
 	// TODO(andrei): figure out how to close these spans on server shutdown?
 	ctx := s.context
 	var sp opentracing.Span
-	if traceSQL {
+	if traceSQL() {
 		var err error
 		sp, err = tracing.JoinOrNewSnowball("coordinator", nil, func(sp basictracer.RawSpan) {
 			ts.CollectedSpans = append(ts.CollectedSpans, sp)
@@ -379,7 +393,7 @@ func (ts *txnState) finishSQLTxn(sessionCtx context.Context) {
 	sampledFor7881 := (ts.sp.BaggageItem(keyFor7881Sample) != "")
 	ts.sp.Finish()
 	ts.sp = nil
-	if (traceSQL && timeutil.Since(ts.sqlTimestamp) >= traceSQLDuration) ||
+	if threshold := traceTxnThreshold.Get(); (threshold > 0 && timeutil.Since(ts.sqlTimestamp) >= threshold) ||
 		(traceSQLFor7881 && sampledFor7881) {
 		dump := tracing.FormatRawSpans(ts.CollectedSpans)
 		if len(dump) > 0 {
@@ -695,7 +695,7 @@ func (p *planner) ShowTables(n *parser.ShowTables) (planNode, error) {
 // ShowUsers returns all the users.
 // Privileges: SELECT on system.users.
 func (p *planner) ShowUsers(n *parser.ShowUsers) (planNode, error) {
-	stmt, err := parser.ParseOneTraditional(`SELECT username FROM system.users`)
+	stmt, err := parser.ParseOneTraditional(`SELECT username FROM system.users WHERE NOT "isRole"`)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +52,11 @@ CREATE TABLE system.descriptor (
 	UsersTableSchema = `
 CREATE TABLE system.users (
   username       STRING PRIMARY KEY,
-  hashedPassword BYTES
+  hashedPassword BYTES,
+  isRole         BOOL NOT NULL DEFAULT false,
+  md5Password    BYTES,
+  scramVerifier  BYTES,
+  authMethods    STRING
 );`
 
 	// ZonesTableSchema is checked in TestSystemTables.
@@ -62,6 +66,27 @@ CREATE TABLE system.zones (
   id     INT PRIMARY KEY,
   config BYTES
 );`
+
+	// SettingsTableSchema is checked in TestSystemTables. It holds the
+	// cluster settings which were changed from their default values. The
+	// values are encoded as strings, with valueType naming their type.
+	SettingsTableSchema = `
+CREATE TABLE system.settings (
+  name        STRING    NOT NULL PRIMARY KEY,
+  value       STRING    NOT NULL,
+  lastUpdated TIMESTAMP NOT NULL DEFAULT now(),
+  valueType   STRING,
+  FAMILY (name, value, lastUpdated, valueType)
+);`
+
+	// RoleMembersTableSchema is checked in TestSystemTables. Each row grants
+	// role to member, which is either a user or another role.
+	RoleMembersTableSchema = `
+CREATE TABLE system.role_members (
+  role   STRING NOT NULL,
+  member STRING NOT NULL,
+  PRIMARY KEY (role, member)
+);`
 )
 
 // These system tables are not part of the system config.
@@ -111,6 +136,54 @@ CREATE TABLE system.ui (
 	value       BYTES,
 	lastUpdated TIMESTAMP NOT NULL
 );`
+
+	// ProtectedTimestampTableSchema is checked in TestSystemTables. Each row
+	// protects the MVCC versions of a key span which are visible at the
+	// timestamp given by (wallTime, logical) from garbage collection, until
+	// the record is removed or it expires.
+	ProtectedTimestampTableSchema = `
+CREATE TABLE system.protected_ts (
+  id          INT       DEFAULT unique_rowid(),
+  startKey    BYTES     NOT NULL,
+  endKey      BYTES     NOT NULL,
+  wallTime    INT       NOT NULL,
+  logical     INT       NOT NULL,
+  owner       STRING    NOT NULL,
+  expiration  TIMESTAMP NOT NULL,
+  PRIMARY KEY (id)
+);`
+
+	// WebSessionsTableSchema is checked in TestSystemTables. Each row is a
+	// session of the admin UI, created by a successful login and identified
+	// by the session cookie, which holds the session ID and a secret whose
+	// hash is stored here.
+	WebSessionsTableSchema = `
+CREATE TABLE system.web_sessions (
+  id           INT       DEFAULT unique_rowid(),
+  hashedSecret BYTES     NOT NULL,
+  username     STRING    NOT NULL,
+  createdAt    TIMESTAMP NOT NULL DEFAULT now(),
+  expiresAt    TIMESTAMP NOT NULL,
+  revokedAt    TIMESTAMP,
+  PRIMARY KEY (id)
+);`
+
+	// ChangeFeedsTableSchema is checked in TestSystemTables. Each row is a
+	// CHANGEFEED, run by the node nodeID, which is resumed when the node
+	// restarts. (wallTime, logical) is the timestamp up to which the feed
+	// has emitted all the changes to its table.
+	ChangeFeedsTableSchema = `
+CREATE TABLE system.changefeeds (
+  id         INT       DEFAULT unique_rowid(),
+  nodeID     INT       NOT NULL,
+  tableID    INT       NOT NULL,
+  sink       STRING    NOT NULL,
+  username   STRING    NOT NULL,
+  createdAt  TIMESTAMP NOT NULL DEFAULT now(),
+  wallTime   INT       NOT NULL,
+  logical    INT       NOT NULL,
+  PRIMARY KEY (id)
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -126,6 +199,7 @@ func pk(name string) IndexDescriptor {
 
 // Helpers used to make some of the TableDescriptor literals below more concise.
 var (
+	colTypeBool      = ColumnType{Kind: ColumnType_BOOL}
 	colTypeInt       = ColumnType{Kind: ColumnType_INT}
 	colTypeString    = ColumnType{Kind: ColumnType_STRING}
 	colTypeBytes     = ColumnType{Kind: ColumnType_BYTES}
@@ -202,6 +276,8 @@ var (
 		NextMutationID: 1,
 	}
 
+	falseString = "false"
+
 	// UsersTable is the descriptor for the users table.
 	UsersTable = TableDescriptor{
 		Name:     "users",
@@ -211,14 +287,22 @@ var (
 		Columns: []ColumnDescriptor{
 			{Name: "username", ID: 1, Type: colTypeString},
 			{Name: "hashedPassword", ID: 2, Type: colTypeBytes, Nullable: true},
+			{Name: "isRole", ID: 3, Type: colTypeBool, DefaultExpr: &falseString},
+			{Name: "md5Password", ID: 4, Type: colTypeBytes, Nullable: true},
+			{Name: "scramVerifier", ID: 5, Type: colTypeBytes, Nullable: true},
+			{Name: "authMethods", ID: 6, Type: colTypeString, Nullable: true},
 		},
-		NextColumnID: 3,
+		NextColumnID: 7,
 		Families: []ColumnFamilyDescriptor{
 			{Name: "primary", ID: 0, ColumnNames: []string{"username"}, ColumnIDs: singleID1},
 			{Name: "fam_2_hashedPassword", ID: 2, ColumnNames: []string{"hashedPassword"}, ColumnIDs: []ColumnID{2}, DefaultColumnID: 2},
+			{Name: "fam_3_isRole", ID: 3, ColumnNames: []string{"isRole"}, ColumnIDs: []ColumnID{3}, DefaultColumnID: 3},
+			{Name: "fam_4_md5Password", ID: 4, ColumnNames: []string{"md5Password"}, ColumnIDs: []ColumnID{4}, DefaultColumnID: 4},
+			{Name: "fam_5_scramVerifier", ID: 5, ColumnNames: []string{"scramVerifier"}, ColumnIDs: []ColumnID{5}, DefaultColumnID: 5},
+			{Name: "fam_6_authMethods", ID: 6, ColumnNames: []string{"authMethods"}, ColumnIDs: []ColumnID{6}, DefaultColumnID: 6},
 		},
 		PrimaryIndex:   pk("username"),
-		NextFamilyID:   3,
+		NextFamilyID:   7,
 		NextIndexID:    2,
 		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemConfigAllowedPrivileges[4]),
 		FormatVersion:  InterleavedFormatVersion,
@@ -248,16 +332,78 @@ var (
 		NextMutationID: 1,
 	}
 
+	nowString = "now()"
+
+	// SettingsTable is the descriptor for the settings table.
+	SettingsTable = TableDescriptor{
+		Name:     "settings",
+		ID:       keys.SettingsTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "name", ID: 1, Type: colTypeString},
+			{Name: "value", ID: 2, Type: colTypeString},
+			{Name: "lastUpdated", ID: 3, Type: colTypeTimestamp, DefaultExpr: &nowString},
+			{Name: "valueType", ID: 4, Type: colTypeString, Nullable: true},
+		},
+		NextColumnID: 5,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "fam_0_name_value_lastUpdated_valueType",
+				ID:          0,
+				ColumnNames: []string{"name", "value", "lastUpdated", "valueType"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("name"),
+		NextIndexID:    2,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemConfigAllowedPrivileges[keys.SettingsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// RoleMembersTable is the descriptor for the role_members table.
+	RoleMembersTable = TableDescriptor{
+		Name:     "role_members",
+		ID:       keys.RoleMembersTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "role", ID: 1, Type: colTypeString},
+			{Name: "member", ID: 2, Type: colTypeString},
+		},
+		NextColumnID: 3,
+		Families: []ColumnFamilyDescriptor{
+			{Name: "primary", ID: 0, ColumnNames: []string{"role", "member"}, ColumnIDs: []ColumnID{1, 2}},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"role", "member"},
+			ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC},
+			ColumnIDs:        []ColumnID{1, 2},
+		},
+		NextIndexID:    2,
+		Privileges:     NewPrivilegeDescriptor(security.RootUser, SystemConfigAllowedPrivileges[keys.RoleMembersTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
 	// SystemConfigAllowedPrivileges describes the privileges allowed for each
 	// system config object. No user may have more than those privileges, and
 	// the root user must have exactly those privileges. CREATE|DROP|ALL
 	// should always be denied.
 	SystemConfigAllowedPrivileges = map[ID]privilege.List{
-		keys.SystemDatabaseID:  privilege.ReadData,
-		keys.NamespaceTableID:  privilege.ReadData,
-		keys.DescriptorTableID: privilege.ReadData,
-		keys.UsersTableID:      privilege.ReadWriteData,
-		keys.ZonesTableID:      privilege.ReadWriteData,
+		keys.SystemDatabaseID:   privilege.ReadData,
+		keys.NamespaceTableID:   privilege.ReadData,
+		keys.DescriptorTableID:  privilege.ReadData,
+		keys.UsersTableID:       privilege.ReadWriteData,
+		keys.ZonesTableID:       privilege.ReadWriteData,
+		keys.SettingsTableID:    privilege.ReadWriteData,
+		keys.RoleMembersTableID: privilege.ReadWriteData,
 	}
 )
 
@@ -402,6 +548,106 @@ var (
 		FormatVersion:  InterleavedFormatVersion,
 		NextMutationID: 1,
 	}
+
+	// ProtectedTimestampTable is the descriptor for the protected timestamp
+	// table.
+	ProtectedTimestampTable = TableDescriptor{
+		Name:     "protected_ts",
+		ID:       keys.ProtectedTimestampTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "id", ID: 1, Type: colTypeInt, DefaultExpr: &uniqueRowIDString},
+			{Name: "startKey", ID: 2, Type: colTypeBytes},
+			{Name: "endKey", ID: 3, Type: colTypeBytes},
+			{Name: "wallTime", ID: 4, Type: colTypeInt},
+			{Name: "logical", ID: 5, Type: colTypeInt},
+			{Name: "owner", ID: 6, Type: colTypeString},
+			{Name: "expiration", ID: 7, Type: colTypeTimestamp},
+		},
+		NextColumnID: 8,
+		Families: []ColumnFamilyDescriptor{
+			{Name: "primary", ID: 0, ColumnNames: []string{"id"}, ColumnIDs: singleID1},
+			{Name: "fam_2_startKey", ID: 2, ColumnNames: []string{"startKey"}, ColumnIDs: []ColumnID{2}, DefaultColumnID: 2},
+			{Name: "fam_3_endKey", ID: 3, ColumnNames: []string{"endKey"}, ColumnIDs: []ColumnID{3}, DefaultColumnID: 3},
+			{Name: "fam_4_wallTime", ID: 4, ColumnNames: []string{"wallTime"}, ColumnIDs: []ColumnID{4}, DefaultColumnID: 4},
+			{Name: "fam_5_logical", ID: 5, ColumnNames: []string{"logical"}, ColumnIDs: []ColumnID{5}, DefaultColumnID: 5},
+			{Name: "fam_6_owner", ID: 6, ColumnNames: []string{"owner"}, ColumnIDs: []ColumnID{6}, DefaultColumnID: 6},
+			{Name: "fam_7_expiration", ID: 7, ColumnNames: []string{"expiration"}, ColumnIDs: []ColumnID{7}, DefaultColumnID: 7},
+		},
+		NextFamilyID:   8,
+		PrimaryIndex:   pk("id"),
+		NextIndexID:    2,
+		Privileges:     NewDefaultPrivilegeDescriptor(),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// WebSessionsTable is the descriptor for the web sessions table.
+	WebSessionsTable = TableDescriptor{
+		Name:     "web_sessions",
+		ID:       keys.WebSessionsTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "id", ID: 1, Type: colTypeInt, DefaultExpr: &uniqueRowIDString},
+			{Name: "hashedSecret", ID: 2, Type: colTypeBytes},
+			{Name: "username", ID: 3, Type: colTypeString},
+			{Name: "createdAt", ID: 4, Type: colTypeTimestamp, DefaultExpr: &nowString},
+			{Name: "expiresAt", ID: 5, Type: colTypeTimestamp},
+			{Name: "revokedAt", ID: 6, Type: colTypeTimestamp, Nullable: true},
+		},
+		NextColumnID: 7,
+		Families: []ColumnFamilyDescriptor{
+			{Name: "primary", ID: 0, ColumnNames: []string{"id"}, ColumnIDs: singleID1},
+			{Name: "fam_2_hashedSecret", ID: 2, ColumnNames: []string{"hashedSecret"}, ColumnIDs: []ColumnID{2}, DefaultColumnID: 2},
+			{Name: "fam_3_username", ID: 3, ColumnNames: []string{"username"}, ColumnIDs: []ColumnID{3}, DefaultColumnID: 3},
+			{Name: "fam_4_createdAt", ID: 4, ColumnNames: []string{"createdAt"}, ColumnIDs: []ColumnID{4}, DefaultColumnID: 4},
+			{Name: "fam_5_expiresAt", ID: 5, ColumnNames: []string{"expiresAt"}, ColumnIDs: []ColumnID{5}, DefaultColumnID: 5},
+			{Name: "fam_6_revokedAt", ID: 6, ColumnNames: []string{"revokedAt"}, ColumnIDs: []ColumnID{6}, DefaultColumnID: 6},
+		},
+		NextFamilyID:   7,
+		PrimaryIndex:   pk("id"),
+		NextIndexID:    2,
+		Privileges:     NewDefaultPrivilegeDescriptor(),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// ChangeFeedsTable is the descriptor for the changefeeds table.
+	ChangeFeedsTable = TableDescriptor{
+		Name:     "changefeeds",
+		ID:       keys.ChangeFeedsTableID,
+		ParentID: 1,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "id", ID: 1, Type: colTypeInt, DefaultExpr: &uniqueRowIDString},
+			{Name: "nodeID", ID: 2, Type: colTypeInt},
+			{Name: "tableID", ID: 3, Type: colTypeInt},
+			{Name: "sink", ID: 4, Type: colTypeString},
+			{Name: "username", ID: 5, Type: colTypeString},
+			{Name: "createdAt", ID: 6, Type: colTypeTimestamp, DefaultExpr: &nowString},
+			{Name: "wallTime", ID: 7, Type: colTypeInt},
+			{Name: "logical", ID: 8, Type: colTypeInt},
+		},
+		NextColumnID: 9,
+		Families: []ColumnFamilyDescriptor{
+			{Name: "primary", ID: 0, ColumnNames: []string{"id"}, ColumnIDs: singleID1},
+			{Name: "fam_2_nodeID", ID: 2, ColumnNames: []string{"nodeID"}, ColumnIDs: []ColumnID{2}, DefaultColumnID: 2},
+			{Name: "fam_3_tableID", ID: 3, ColumnNames: []string{"tableID"}, ColumnIDs: []ColumnID{3}, DefaultColumnID: 3},
+			{Name: "fam_4_sink", ID: 4, ColumnNames: []string{"sink"}, ColumnIDs: []ColumnID{4}, DefaultColumnID: 4},
+			{Name: "fam_5_username", ID: 5, ColumnNames: []string{"username"}, ColumnIDs: []ColumnID{5}, DefaultColumnID: 5},
+			{Name: "fam_6_createdAt", ID: 6, ColumnNames: []string{"createdAt"}, ColumnIDs: []ColumnID{6}, DefaultColumnID: 6},
+			{Name: "fam_7_wallTime", ID: 7, ColumnNames: []string{"wallTime"}, ColumnIDs: []ColumnID{7}, DefaultColumnID: 7},
+			{Name: "fam_8_logical", ID: 8, ColumnNames: []string{"logical"}, ColumnIDs: []ColumnID{8}, DefaultColumnID: 8},
+		},
+		NextFamilyID:   9,
+		PrimaryIndex:   pk("id"),
+		NextIndexID:    2,
+		Privileges:     NewDefaultPrivilegeDescriptor(),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create the key/value pairs for the default zone config entry.
@@ -432,12 +678,17 @@ func addSystemDatabaseToSchema(target *MetadataSchema) {
 	target.AddConfigDescriptor(keys.SystemDatabaseID, &DescriptorTable)
 	target.AddConfigDescriptor(keys.SystemDatabaseID, &UsersTable)
 	target.AddConfigDescriptor(keys.SystemDatabaseID, &ZonesTable)
+	target.AddConfigDescriptor(keys.SystemDatabaseID, &SettingsTable)
+	target.AddConfigDescriptor(keys.SystemDatabaseID, &RoleMembersTable)
 
 	// Add all the other system tables.
 	target.AddDescriptor(keys.SystemDatabaseID, &LeaseTable)
 	target.AddDescriptor(keys.SystemDatabaseID, &EventLogTable)
 	target.AddDescriptor(keys.SystemDatabaseID, &RangeEventTable)
 	target.AddDescriptor(keys.SystemDatabaseID, &UITable)
+	target.AddDescriptor(keys.SystemDatabaseID, &ProtectedTimestampTable)
+	target.AddDescriptor(keys.SystemDatabaseID, &WebSessionsTable)
+	target.AddDescriptor(keys.SystemDatabaseID, &ChangeFeedsTable)
 
 	target.otherKV = append(target.otherKV, createDefaultZoneConfig()...)
 }
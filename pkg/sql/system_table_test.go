@@ -32,7 +32,7 @@ import (
 func TestInitialKeys(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
-	const nonSystemDesc = 4
+	const nonSystemDesc = 7
 	const keysPerDesc = 2
 	const nonDescKeys = 2
 
@@ -109,6 +109,8 @@ func TestSystemTableLiterals(t *testing.T) {
 		{keys.DescriptorTableID, sqlbase.DescriptorTableSchema, sqlbase.DescriptorTable},
 		{keys.UsersTableID, sqlbase.UsersTableSchema, sqlbase.UsersTable},
 		{keys.ZonesTableID, sqlbase.ZonesTableSchema, sqlbase.ZonesTable},
+		{keys.SettingsTableID, sqlbase.SettingsTableSchema, sqlbase.SettingsTable},
+		{keys.RoleMembersTableID, sqlbase.RoleMembersTableSchema, sqlbase.RoleMembersTable},
 	} {
 		gen, err := sql.CreateTestTableDescriptor(
 			keys.SystemDatabaseID,
@@ -131,6 +133,9 @@ func TestSystemTableLiterals(t *testing.T) {
 		{keys.EventLogTableID, sqlbase.EventLogTableSchema, sqlbase.EventLogTable},
 		{keys.RangeEventTableID, sqlbase.RangeEventTableSchema, sqlbase.RangeEventTable},
 		{keys.UITableID, sqlbase.UITableSchema, sqlbase.UITable},
+		{keys.ProtectedTimestampTableID, sqlbase.ProtectedTimestampTableSchema, sqlbase.ProtectedTimestampTable},
+		{keys.WebSessionsTableID, sqlbase.WebSessionsTableSchema, sqlbase.WebSessionsTable},
+		{keys.ChangeFeedsTableID, sqlbase.ChangeFeedsTableSchema, sqlbase.ChangeFeedsTable},
 	} {
 		gen, err := sql.CreateTestTableDescriptor(
 			keys.SystemDatabaseID, test.id, test.schema, sqlbase.NewDefaultPrivilegeDescriptor(),
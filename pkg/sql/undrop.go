@@ -0,0 +1,191 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// UndropTable brings back the most recently dropped table with the given
+// name, as long as the GC TTL of the table hasn't passed since it was
+// dropped and its name hasn't been reused. Foreign keys referencing the
+// table and views depending on it were removed by the DROP and aren't
+// restored.
+// Privileges: CREATE on database.
+func (p *planner) UndropTable(n *parser.UndropTable) (planNode, error) {
+	tn, err := n.Name.NormalizeTableName()
+	if err != nil {
+		return nil, err
+	}
+	if err := tn.QualifyWithDatabase(p.session.Database); err != nil {
+		return nil, err
+	}
+	dbDesc, err := p.mustGetDatabaseDesc(tn.Database())
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkPrivilege(dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	tableDesc, err := p.getDroppedTableDesc(dbDesc.ID, tn.Table())
+	if err != nil {
+		return nil, err
+	}
+	if tableDesc == nil {
+		return nil, fmt.Errorf("no dropped table %q", tn)
+	}
+	if tableDesc.Lease != nil &&
+		time.Unix(0, tableDesc.Lease.ExpirationTime).After(timeutil.Now()) {
+		return nil, fmt.Errorf("table %q is being dropped, try again later", tn)
+	}
+	deadline, err := dropDeadline(p.txn, tableDesc)
+	if err != nil {
+		return nil, err
+	}
+	if !timeutil.Now().Before(deadline) {
+		return nil, fmt.Errorf("the GC TTL of dropped table %q has expired", tn)
+	}
+
+	// The data of an interleaved table lives in the key space of its parent, and
+	// its interleave relationships have been removed when it was dropped.
+	for _, idx := range tableDesc.AllNonDropIndexes() {
+		if len(idx.Interleave.Ancestors) > 0 || len(idx.InterleavedBy) > 0 {
+			return nil, util.UnimplementedWithIssueErrorf(
+				8036, "cannot undrop interleaved table %q", tn)
+		}
+	}
+
+	_, nameKey, _ := getKeysForTableDescriptor(tableDesc)
+	gr, err := p.txn.Get(nameKey)
+	if err != nil {
+		return nil, err
+	}
+	if gr.Exists() && sqlbase.ID(gr.ValueInt()) != tableDesc.ID {
+		return nil, sqlbase.NewRelationAlreadyExistsError(tn.Table())
+	}
+
+	// Restore the back references of the foreign keys of the table. The foreign
+	// keys referencing the table and the views depending on it have been
+	// removed when it was dropped.
+	tableDesc.PrimaryIndex.ReferencedBy = nil
+	for i := range tableDesc.Indexes {
+		tableDesc.Indexes[i].ReferencedBy = nil
+	}
+	for i := range tableDesc.Indexes {
+		if err := p.restoreFKBackReference(tableDesc, &tableDesc.Indexes[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.restoreFKBackReference(tableDesc, &tableDesc.PrimaryIndex); err != nil {
+		return nil, err
+	}
+	tableDesc.DependedOnBy = nil
+
+	tableDesc.State = sqlbase.TableDescriptor_PUBLIC
+	tableDesc.DropTime = 0
+	if err := p.saveNonmutationAndNotify(tableDesc); err != nil {
+		return nil, err
+	}
+	if err := p.txn.Put(nameKey, tableDesc.ID); err != nil {
+		return nil, err
+	}
+
+	// Log an Undrop Table event. This is an auditable log event and is
+	// recorded in the same transaction as the table descriptor update.
+	if err := MakeEventLogger(p.leaseMgr).InsertEventRecord(p.txn,
+		EventLogUndropTable,
+		int32(tableDesc.ID),
+		int32(p.evalCtx.NodeID),
+		struct {
+			TableName string
+			Statement string
+			User      string
+		}{tn.String(), n.String(), p.session.User},
+	); err != nil {
+		return nil, err
+	}
+	return &emptyNode{}, nil
+}
+
+// getDroppedTableDesc returns the descriptor of the most recently dropped
+// table with the given name in the given database, or nil if there is none.
+// Dropped tables are only found until they're truncated.
+func (p *planner) getDroppedTableDesc(
+	parentID sqlbase.ID, name string,
+) (*sqlbase.TableDescriptor, error) {
+	descs, err := p.getAllDescriptors()
+	if err != nil {
+		return nil, err
+	}
+	var dropped *sqlbase.TableDescriptor
+	for _, desc := range descs {
+		table, ok := desc.(*sqlbase.TableDescriptor)
+		if !ok || !table.Dropped() || !table.IsTable() {
+			continue
+		}
+		if table.ParentID != parentID || table.Name != name {
+			continue
+		}
+		if dropped == nil || table.DropTime > dropped.DropTime {
+			dropped = table
+		}
+	}
+	return dropped, nil
+}
+
+// restoreFKBackReference adds back the reference from the index targeted by
+// the foreign key of idx, which was removed when the table was dropped. The
+// foreign key is removed instead if the targeted table was dropped as well.
+func (p *planner) restoreFKBackReference(
+	tableDesc *sqlbase.TableDescriptor, idx *sqlbase.IndexDescriptor,
+) error {
+	if !idx.ForeignKey.IsSet() {
+		return nil
+	}
+	if idx.ForeignKey.Table == tableDesc.ID {
+		targetIdx, err := tableDesc.FindIndexByID(idx.ForeignKey.Index)
+		if err != nil {
+			return err
+		}
+		targetIdx.ReferencedBy = append(targetIdx.ReferencedBy,
+			sqlbase.ForeignKeyReference{Table: tableDesc.ID, Index: idx.ID})
+		return nil
+	}
+	t, err := sqlbase.GetTableDescFromID(p.txn, idx.ForeignKey.Table)
+	if err == sqlbase.ErrDescriptorNotFound || (err == nil && t.Dropped()) {
+		idx.ForeignKey = sqlbase.ForeignKeyReference{}
+		return nil
+	}
+	if err != nil {
+		return errors.Errorf("error resolving referenced table ID %d: %v", idx.ForeignKey.Table, err)
+	}
+	targetIdx, err := t.FindIndexByID(idx.ForeignKey.Index)
+	if err != nil {
+		return err
+	}
+	targetIdx.ReferencedBy = append(targetIdx.ReferencedBy,
+		sqlbase.ForeignKeyReference{Table: tableDesc.ID, Index: idx.ID})
+	return p.saveNonmutationAndNotify(t)
+}
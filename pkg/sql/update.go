@@ -52,6 +52,7 @@ func (p *planner) makeEditNode(
 			errors.Errorf("cannot run %s on view %q - views are not updateable", priv, tn)
 	}
 
+	p.maybeAudit(tableDesc, priv)
 	if err := p.checkPrivilege(tableDesc, priv); err != nil {
 		return editNodeBase{}, err
 	}
@@ -25,35 +25,54 @@ import (
 	"github.com/pkg/errors"
 )
 
-// GetUserHashedPassword returns the hashedPassword for the given username if
-// found in system.users.
-func GetUserHashedPassword(
+// GetUserCredentials returns the password credentials of the given username
+// if found in system.users.
+func GetUserCredentials(
 	ctx context.Context, executor *Executor, metrics *MemoryMetrics, username string,
-) ([]byte, error) {
+) (*security.UserCredentials, error) {
 	normalizedUsername := parser.Name(username).Normalize()
 	// The root user is not in system.users.
 	if normalizedUsername == security.RootUser {
-		return nil, nil
+		return &security.UserCredentials{}, nil
 	}
 
-	var hashedPassword []byte
+	creds := &security.UserCredentials{}
 	if err := executor.cfg.DB.Txn(ctx, func(txn *client.Txn) error {
 		p := makeInternalPlanner("get-pwd", txn, security.RootUser, metrics)
 		defer finishInternalPlanner(p)
-		const getHashedPassword = `SELECT hashedPassword FROM system.users ` +
-			`WHERE username=$1`
-		values, err := p.queryRow(getHashedPassword, normalizedUsername)
+		const getCredentials = `SELECT hashedPassword, "md5Password", "scramVerifier", ` +
+			`"authMethods" FROM system.users WHERE username=$1 AND NOT "isRole"`
+		values, err := p.queryRow(getCredentials, normalizedUsername)
 		if err != nil {
 			return errors.Errorf("error looking up user %s", normalizedUsername)
 		}
 		if len(values) == 0 {
 			return errors.Errorf("user %s does not exist", normalizedUsername)
 		}
-		hashedPassword = []byte(*(values[0].(*parser.DBytes)))
+		for i, dst := range []*[]byte{&creds.HashedPassword, &creds.MD5Password, &creds.SCRAMVerifier} {
+			if values[i] != parser.DNull {
+				*dst = []byte(*(values[i].(*parser.DBytes)))
+			}
+		}
+		if values[3] != parser.DNull {
+			creds.Methods, err = security.ParseAuthMethods(string(*(values[3].(*parser.DString))))
+			if err != nil {
+				return errors.Wrapf(err, "invalid authentication methods for user %s", normalizedUsername)
+			}
+		}
 		return nil
 	}); err != nil {
 		return nil, err
 	}
 
-	return hashedPassword, nil
+	return creds, nil
+}
+
+// nullIfEmpty returns a placeholder argument for a credential in
+// system.users, which is NULL if the user doesn't have it.
+func nullIfEmpty(credential []byte) interface{} {
+	if len(credential) == 0 {
+		return nil
+	}
+	return credential
 }
@@ -27,8 +27,8 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util"
-	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/pkg/errors"
@@ -42,9 +42,11 @@ const (
 	maxFractionUsedThreshold = 0.95
 
 	// priorities for various repair operations.
-	addMissingReplicaPriority  float64 = 10000
-	removeDeadReplicaPriority  float64 = 1000
-	removeExtraReplicaPriority float64 = 100
+	addMissingReplicaPriority             float64 = 10000
+	addDecommissioningReplacementPriority float64 = 5000
+	removeDeadReplicaPriority             float64 = 1000
+	removeDecommissioningReplicaPriority  float64 = 200
+	removeExtraReplicaPriority            float64 = 100
 )
 
 // AllocatorAction enumerates the various replication adjustments that may be
@@ -58,13 +60,15 @@ const (
 	AllocatorRemove
 	AllocatorAdd
 	AllocatorRemoveDead
+	AllocatorRemoveDecommissioning
 )
 
 var allocatorActionNames = map[AllocatorAction]string{
-	AllocatorNoop:       "noop",
-	AllocatorRemove:     "remove",
-	AllocatorAdd:        "add",
-	AllocatorRemoveDead: "remove dead",
+	AllocatorNoop:                  "noop",
+	AllocatorRemove:                "remove",
+	AllocatorAdd:                   "add",
+	AllocatorRemoveDead:            "remove dead",
+	AllocatorRemoveDecommissioning: "remove decommissioning",
 }
 
 func (a AllocatorAction) String() string {
@@ -181,6 +185,13 @@ func (a *Allocator) ComputeAction(
 		neededQuorum := computeQuorum(need)
 		return AllocatorAdd, addMissingReplicaPriority + float64(neededQuorum-have)
 	}
+	decommissioningReplicas := a.storePool.decommissioningReplicas(desc.Replicas)
+	if have == need && len(decommissioningReplicas) > 0 {
+		// The range has replicas on decommissioning nodes. Add a replacement
+		// replica first; the decommissioning replica is removed once the range
+		// is over-replicated.
+		return AllocatorAdd, addDecommissioningReplacementPriority
+	}
 	deadReplicas := a.storePool.deadReplicas(desc.RangeID, desc.Replicas)
 	if len(deadReplicas) > 0 {
 		// The range has dead replicas, which should be removed immediately.
@@ -189,6 +200,11 @@ func (a *Allocator) ComputeAction(
 		liveReplicas := len(desc.Replicas) - len(deadReplicas)
 		return AllocatorRemoveDead, removeDeadReplicaPriority + float64(quorum-liveReplicas)
 	}
+	if len(decommissioningReplicas) > 0 {
+		// The range has a replica on a decommissioning node which should be
+		// removed in preference to any other replica.
+		return AllocatorRemoveDecommissioning, removeDecommissioningReplicaPriority
+	}
 	if have > need {
 		// Range is over-replicated, and should remove a replica.
 		// Ranges with an even number of replicas get extra priority because
@@ -507,11 +523,19 @@ func (a *Allocator) TransferLeaseTarget(
 		return roachpb.ReplicaDescriptor{}
 	}
 
+	decommissioning := make(nodeIDSet)
+	for _, repl := range a.storePool.decommissioningReplicas(existing) {
+		decommissioning[repl.NodeID] = struct{}{}
+	}
+
 	candidates := make([]roachpb.ReplicaDescriptor, 0, len(existing)-1)
 	for _, repl := range existing {
 		if leaseStoreID == repl.StoreID {
 			continue
 		}
+		if _, ok := decommissioning[repl.NodeID]; ok {
+			continue
+		}
 		storeDesc, ok := a.storePool.getStoreDescriptor(repl.StoreID)
 		if !ok {
 			continue
@@ -552,15 +576,19 @@ func (a *Allocator) ShouldTransferLease(
 
 // EnableLeaseRebalancing controls whether lease rebalancing is enabled or
 // not. Exported for testing.
-var EnableLeaseRebalancing = envutil.EnvOrDefaultBool("COCKROACH_ENABLE_LEASE_REBALANCING", false)
+var EnableLeaseRebalancing = settings.RegisterBoolSetting(
+	"kv.allocator.lease_rebalancing.enabled",
+	"set to true to enable rebalancing of range leases across stores",
+	false,
+)
 
 func shouldTransferLease(sl StoreList, source roachpb.StoreDescriptor) bool {
-	if !EnableLeaseRebalancing {
+	if !EnableLeaseRebalancing.Get() {
 		return false
 	}
 	// Allow lease transfer if we're above the overfull threshold, which is
 	// mean*(1+rebalanceThreshold).
-	overfullLeaseThreshold := int32(math.Ceil(sl.candidateLeases.mean * (1 + rebalanceThreshold)))
+	overfullLeaseThreshold := int32(math.Ceil(sl.candidateLeases.mean * (1 + rebalanceThreshold.Get())))
 	minOverfullThreshold := int32(math.Ceil(sl.candidateLeases.mean + 5))
 	if overfullLeaseThreshold < minOverfullThreshold {
 		overfullLeaseThreshold = minOverfullThreshold
@@ -596,7 +624,11 @@ func (a Allocator) improve(sl StoreList, excluded nodeIDSet) *roachpb.StoreDescr
 
 // rebalanceThreshold is the minimum ratio of a store's range surplus to the
 // mean range count that permits rebalances away from that store.
-var rebalanceThreshold = envutil.EnvOrDefaultFloat("COCKROACH_REBALANCE_THRESHOLD", 0.05)
+var rebalanceThreshold = settings.RegisterNonNegativeFloatSetting(
+	"kv.allocator.range_rebalance_threshold",
+	"minimum fraction away from the mean a store's range count can be before it is considered overfull or underfull",
+	0.05,
+)
 
 // shouldRebalance returns whether the specified store is a candidate for
 // having a replica removed from it given the candidate store list.
@@ -608,7 +640,7 @@ func (a Allocator) shouldRebalance(store roachpb.StoreDescriptor, sl StoreList)
 
 	// Rebalance if we're above the rebalance target, which is
 	// mean*(1+rebalanceThreshold).
-	target := int32(math.Ceil(sl.candidateCount.mean * (1 + rebalanceThreshold)))
+	target := int32(math.Ceil(sl.candidateCount.mean * (1 + rebalanceThreshold.Get())))
 	rangeCountAboveTarget := store.Capacity.RangeCount > target
 
 	// Rebalance if the candidate store has a range count above the mean, and
@@ -616,7 +648,7 @@ func (a Allocator) shouldRebalance(store roachpb.StoreDescriptor, sl StoreList)
 	// than mean*(1-rebalanceThreshold).
 	var rebalanceToUnderfullStore bool
 	if float64(store.Capacity.RangeCount) > sl.candidateCount.mean {
-		underfullThreshold := int32(math.Floor(sl.candidateCount.mean * (1 - rebalanceThreshold)))
+		underfullThreshold := int32(math.Floor(sl.candidateCount.mean * (1 - rebalanceThreshold.Get())))
 		for _, desc := range sl.stores {
 			if desc.Capacity.RangeCount < underfullThreshold {
 				rebalanceToUnderfullStore = true
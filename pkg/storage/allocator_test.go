@@ -36,6 +36,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/testutils/gossiputil"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -756,7 +757,7 @@ func TestAllocatorRebalanceThrashing(t *testing.T) {
 		for i := range stores {
 			stores[i].rangeCount = mean
 		}
-		surplus := int32(math.Ceil(float64(mean)*rebalanceThreshold + 1))
+		surplus := int32(math.Ceil(float64(mean)*rebalanceThreshold.Get() + 1))
 		stores[0].rangeCount += surplus
 		stores[0].shouldRebalanceFrom = true
 		for i := 1; i < len(stores); i++ {
@@ -775,7 +776,7 @@ func TestAllocatorRebalanceThrashing(t *testing.T) {
 		// Subtract enough ranges from the first store to make it a suitable
 		// rebalance target. To maintain the specified mean, we then add that delta
 		// back to the rest of the replicas.
-		deficit := int32(math.Ceil(float64(mean)*rebalanceThreshold + 1))
+		deficit := int32(math.Ceil(float64(mean)*rebalanceThreshold.Get() + 1))
 		stores[0].rangeCount -= deficit
 		for i := 1; i < len(stores); i++ {
 			stores[i].rangeCount += int32(math.Ceil(float64(deficit) / float64(len(stores)-1)))
@@ -944,10 +945,7 @@ func TestAllocatorTransferLeaseTarget(t *testing.T) {
 	defer stopper.Stop()
 
 	// TODO(peter): Remove when lease rebalancing is the default.
-	defer func(v bool) {
-		EnableLeaseRebalancing = v
-	}(EnableLeaseRebalancing)
-	EnableLeaseRebalancing = true
+	defer settings.TestingSetBool(&EnableLeaseRebalancing, true)()
 
 	// 3 stores where the lease count for each store is equal to 10x the store
 	// ID.
@@ -1007,10 +1005,7 @@ func TestAllocatorShouldTransferLease(t *testing.T) {
 	defer stopper.Stop()
 
 	// TODO(peter): Remove when lease rebalancing is the default.
-	defer func(v bool) {
-		EnableLeaseRebalancing = v
-	}(EnableLeaseRebalancing)
-	EnableLeaseRebalancing = true
+	defer settings.TestingSetBool(&EnableLeaseRebalancing, true)()
 
 	// 3 stores where the lease count for each store is equal to 10x the store
 	// ID.
@@ -1556,6 +1551,66 @@ func TestAllocatorComputeActionNoStorePool(t *testing.T) {
 	})
 }
 
+// TestAllocatorComputeActionDecommission verifies that ranges with replicas
+// on decommissioning nodes first add a replacement replica and then remove
+// the decommissioning replica.
+func TestAllocatorComputeActionDecommission(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	zone := config.ZoneConfig{
+		NumReplicas:   3,
+		RangeMinBytes: 0,
+		RangeMaxBytes: 64000,
+	}
+	threeReplicas := roachpb.RangeDescriptor{
+		Replicas: []roachpb.ReplicaDescriptor{
+			{StoreID: 1, NodeID: 1, ReplicaID: 1},
+			{StoreID: 2, NodeID: 2, ReplicaID: 2},
+			{StoreID: 3, NodeID: 3, ReplicaID: 3},
+		},
+	}
+	fourReplicas := roachpb.RangeDescriptor{
+		Replicas: append(threeReplicas.Replicas[:3:3],
+			roachpb.ReplicaDescriptor{StoreID: 4, NodeID: 4, ReplicaID: 4}),
+	}
+
+	testCases := []struct {
+		desc           roachpb.RangeDescriptor
+		expectedAction AllocatorAction
+	}{
+		{threeReplicas, AllocatorAdd},
+		{fourReplicas, AllocatorRemoveDecommissioning},
+	}
+
+	runToggleRuleSolver(t, func(useRuleSolver bool, t *testing.T) {
+		stopper, _, sp, a, _ := createTestAllocator(
+			/* deterministic */ false,
+			/* useRuleSolver */ useRuleSolver,
+		)
+		defer stopper.Stop()
+
+		mockStorePool(sp, []roachpb.StoreID{1, 2, 3, 4}, nil, nil)
+		sp.mu.Lock()
+		sp.mu.decommissioningNodes[3] = struct{}{}
+		sp.mu.Unlock()
+
+		for i, tcase := range testCases {
+			action, _ := a.ComputeAction(zone, &tcase.desc)
+			if tcase.expectedAction != action {
+				t.Errorf("%d: expected action %s, got action %s", i, tcase.expectedAction, action)
+			}
+		}
+
+		// Stores on decommissioning nodes are not allocation targets.
+		sl, _, _ := sp.getStoreList(0)
+		for _, s := range sl.stores {
+			if s.Node.NodeID == 3 {
+				t.Errorf("expected store list to exclude decommissioning node 3, got %+v", sl.stores)
+			}
+		}
+	})
+}
+
 // TestAllocatorError ensures that the correctly formatted error message is
 // returned from an allocatorError.
 func TestAllocatorError(t *testing.T) {
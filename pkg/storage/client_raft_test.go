@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
@@ -2626,6 +2627,72 @@ func TestCheckInconsistent(t *testing.T) {
 	}
 }
 
+// TestCheckInconsistentRepair verifies that a replica which disagrees with
+// the majority of its range in a consistency check is removed from the range
+// when repairs are enabled, instead of the lease holder panicking.
+func TestCheckInconsistentRepair(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer settings.TestingSetBool(&storage.ConsistencyCheckRepair, true)()
+
+	sc := storage.TestStoreConfig(nil)
+	mtc := &multiTestContext{storeConfig: &sc}
+	sc.TestingKnobs.BadChecksumPanic = func(s roachpb.StoreIdent) {
+		t.Errorf("BadChecksumPanic called despite repair (StoreIdent = %s)", s)
+	}
+
+	const numStores = 3
+	mtc.Start(t, numStores)
+	defer mtc.Stop()
+	// Setup replication of range 1 on store 0 to stores 1 and 2.
+	mtc.replicateRange(1, 1, 2)
+
+	pArgs := putArgs([]byte("a"), []byte("b"))
+	if _, err := client.SendWrapped(context.Background(), rg1(mtc.stores[0]), &pArgs); err != nil {
+		t.Fatal(err)
+	}
+	inconsistentReplica, err := mtc.stores[1].GetReplica(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inconsistentReplicaDesc, err := inconsistentReplica.GetReplicaDescriptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write some arbitrary data only to store 1.
+	var val roachpb.Value
+	val.SetInt(42)
+	if err := engine.MVCCPut(
+		context.Background(), mtc.stores[1].Engine(), nil, []byte("e"), mtc.stores[1].Clock().Now(), val, nil,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	checkArgs := roachpb.CheckConsistencyRequest{
+		Span: roachpb.Span{
+			Key:    []byte("a"),
+			EndKey: []byte("z"),
+		},
+	}
+	if _, err := client.SendWrapped(context.Background(), rg1(mtc.stores[0]), &checkArgs); err != nil {
+		t.Fatal(err)
+	}
+	// The replica on store 1 is removed. The replicate queue may add a new
+	// replica to store 1 afterwards, which has a different replica ID.
+	util.SucceedsSoon(t, func() error {
+		repl, err := mtc.stores[0].GetReplica(1)
+		if err != nil {
+			return err
+		}
+		for _, replica := range repl.Desc().Replicas {
+			if replica == inconsistentReplicaDesc {
+				return errors.Errorf("inconsistent replica %s not removed yet", replica)
+			}
+		}
+		return nil
+	})
+}
+
 func TestTransferRaftLeadership(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -2849,3 +2916,97 @@ func TestRangeQuiescence(t *testing.T) {
 		t.Fatalf("%s should be the leader: %s", rep, state)
 	}
 }
+
+// TestReplicaProposalQuotaSlowFollower verifies that a follower which falls
+// behind holds on to the leader's proposal quota, blocking writes once the
+// quota is exhausted until the follower catches up.
+func TestReplicaProposalQuotaSlowFollower(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	const quota = 1 << 12
+	defer storage.TestingSetProposalQuota(quota)()
+
+	mtc := startMultiTestContext(t, 3)
+	defer mtc.Stop()
+	mtc.replicateRange(1, 1, 2)
+
+	leaderRepl, err := mtc.stores[0].GetReplica(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raftLeader := mtc.getRaftLeader(1); raftLeader != leaderRepl {
+		t.Fatalf("expected the lease holder %s to be the raft leader, got %s", leaderRepl, raftLeader)
+	}
+	util.SucceedsSoon(t, func() error {
+		if n, ok := leaderRepl.ProposalQuota(); !ok || n != quota {
+			return errors.Errorf("expected %d bytes of quota, got %d (pool: %t)", quota, n, ok)
+		}
+		return nil
+	})
+	for _, s := range mtc.stores[1:] {
+		followerRepl, err := s.GetReplica(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, ok := followerRepl.ProposalQuota(); ok {
+			t.Fatalf("expected no quota pool on follower %s, got one with %d bytes", followerRepl, n)
+		}
+	}
+
+	// Stall the follower on store 2. See TestRaftBlockedReplica for why the
+	// lock is acquired on a different goroutine.
+	slowRepl, err := mtc.stores[2].GetReplica(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		slowRepl.RaftLock()
+		wg.Done()
+	}()
+	wg.Wait()
+	unlocked := false
+	defer func() {
+		if !unlocked {
+			slowRepl.RaftUnlock()
+		}
+	}()
+
+	// The first write commits on the leader and the other follower, but
+	// its quota is held for the stalled follower.
+	value := bytes.Repeat([]byte("v"), quota*3/4)
+	pArgs := putArgs(roachpb.Key("a"), value)
+	if _, err := client.SendWrapped(context.Background(), rg1(mtc.stores[0]), &pArgs); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := leaderRepl.ProposalQuota(); n > quota/4 {
+		t.Fatalf("expected the write's quota to be held, but %d bytes are available", n)
+	}
+
+	// The second write blocks waiting for quota.
+	writeDone := make(chan error, 1)
+	go func() {
+		pArgs := putArgs(roachpb.Key("b"), value)
+		_, pErr := client.SendWrapped(context.Background(), rg1(mtc.stores[0]), &pArgs)
+		writeDone <- pErr.GoError()
+	}()
+	select {
+	case err := <-writeDone:
+		t.Fatalf("write proceeded without proposal quota: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Once the follower catches up, the quota is released and the write
+	// goes through.
+	slowRepl.RaftUnlock()
+	unlocked = true
+	if err := <-writeDone; err != nil {
+		t.Fatal(err)
+	}
+	util.SucceedsSoon(t, func() error {
+		if n, _ := leaderRepl.ProposalQuota(); n != quota {
+			return errors.Errorf("expected %d bytes of quota, got %d", quota, n)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+type testRangeFeedSink struct {
+	ctx    context.Context
+	events chan *roachpb.RangeFeedEvent
+}
+
+func (s *testRangeFeedSink) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testRangeFeedSink) Send(event *roachpb.RangeFeedEvent) error {
+	select {
+	case s.events <- event:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+// TestStoreRangeFeed verifies that a RangeFeed emits committed values in
+// timestamp order and doesn't resolve its span past unresolved intents.
+func TestStoreRangeFeed(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// The transaction below stays open while the clock moves past the
+	// closed timestamp interval, which would push its commit.
+	defer settings.TestingSetDuration(&storage.ClosedTimestampInterval, 0)()
+	store, stopper, manual := createTestStore(t)
+	defer stopper.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	args := roachpb.RangeFeedRequest{Span: roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")}}
+	args.RangeID = store.LookupReplica(roachpb.RKey("a"), nil).RangeID
+	args.Timestamp = store.Clock().Now()
+	sink := &testRangeFeedSink{ctx: ctx, events: make(chan *roachpb.RangeFeedEvent, 16)}
+	errCh := make(chan *roachpb.Error, 1)
+	go func() {
+		errCh <- store.RangeFeed(&args, sink)
+	}()
+	defer func() {
+		cancel()
+		if pErr := <-errCh; !testutils.IsPError(pErr, context.Canceled.Error()) {
+			t.Errorf("expected feed to end with %q, got %v", context.Canceled, pErr)
+		}
+	}()
+
+	nextEvent := func() *roachpb.RangeFeedEvent {
+		select {
+		case event := <-sink.events:
+			return event
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for rangefeed event")
+			return nil
+		}
+	}
+
+	manual.Increment(10)
+	txn := client.NewTxn(context.TODO(), *store.DB())
+	if err := txn.Put("a1", "intent"); err != nil {
+		t.Fatal(err)
+	}
+	intentTS := txn.Proto.Timestamp
+	if err := store.DB().Put(context.TODO(), "a2", "committed"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Move the clock well past the writes. The intent holds back the
+	// resolved timestamp, so the value committed above it isn't emitted
+	// until the intent is resolved.
+	manual.Increment(time.Minute.Nanoseconds())
+	event := nextEvent()
+	if event.Checkpoint == nil {
+		t.Fatalf("expected checkpoint, got %v", event)
+	}
+	if !event.Checkpoint.ResolvedTS.Less(intentTS) {
+		t.Fatalf("resolved to %s, past intent at %s", event.Checkpoint.ResolvedTS, intentTS)
+	}
+
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expKey := range []string{"a1", "a2"} {
+		event := nextEvent()
+		if event.Val == nil {
+			t.Fatalf("expected value for %s, got %v", expKey, event)
+		}
+		if key := string(event.Val.Key); key != expKey {
+			t.Fatalf("expected value for %s, got %s", expKey, key)
+		}
+	}
+	if event := nextEvent(); event.Checkpoint == nil || event.Checkpoint.ResolvedTS.Less(intentTS) {
+		t.Fatalf("expected checkpoint past %s, got %v", intentTS, event)
+	}
+
+	// Once the span has been scanned, the feed only looks at the keys
+	// written since. A new write is still emitted.
+	if err := store.DB().Put(context.TODO(), "a3", "later"); err != nil {
+		t.Fatal(err)
+	}
+	manual.Increment(time.Minute.Nanoseconds())
+	for {
+		event := nextEvent()
+		if event.Checkpoint != nil {
+			continue
+		}
+		if event.Val == nil || string(event.Val.Key) != "a3" {
+			t.Fatalf("expected value for a3, got %v", event)
+		}
+		break
+	}
+}
+
+// TestStoreRangeFeedResolvesWhenNeeded verifies that a RangeFeed doesn't
+// resolve its span while it isn't written to until the idle resolve
+// interval has passed, and that resolving the span doesn't push the
+// transactions writing to it.
+func TestStoreRangeFeedResolvesWhenNeeded(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer storage.TestingSetRangeFeedPollInterval(10 * time.Millisecond)()
+	store, stopper, manual := createTestStore(t)
+	defer stopper.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	args := roachpb.RangeFeedRequest{Span: roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")}}
+	args.RangeID = store.LookupReplica(roachpb.RKey("a"), nil).RangeID
+	args.Timestamp = store.Clock().Now()
+	sink := &testRangeFeedSink{ctx: ctx, events: make(chan *roachpb.RangeFeedEvent, 16)}
+	errCh := make(chan *roachpb.Error, 1)
+	go func() {
+		errCh <- store.RangeFeed(&args, sink)
+	}()
+	defer func() {
+		cancel()
+		if pErr := <-errCh; !testutils.IsPError(pErr, context.Canceled.Error()) {
+			t.Errorf("expected feed to end with %q, got %v", context.Canceled, pErr)
+		}
+	}()
+
+	nextCheckpoint := func() *roachpb.RangeFeedCheckpoint {
+		select {
+		case event := <-sink.events:
+			if event.Checkpoint == nil {
+				t.Fatalf("expected checkpoint, got %v", event)
+			}
+			return event.Checkpoint
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for rangefeed event")
+			return nil
+		}
+	}
+
+	// The span is resolved once it has been scanned.
+	manual.Increment(time.Minute.Nanoseconds())
+	nextCheckpoint()
+
+	// It isn't resolved again while it isn't written to, until its
+	// resolved timestamp falls behind by the idle resolve interval.
+	manual.Increment(5 * time.Second.Nanoseconds())
+	select {
+	case event := <-sink.events:
+		t.Fatalf("expected no event for an idle span, got %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+	manual.Increment(10 * time.Second.Nanoseconds())
+	nextCheckpoint()
+
+	// A transaction writing to the span holds back its resolved timestamp,
+	// but isn't pushed when the span is resolved behind it.
+	txn := client.NewTxn(context.TODO(), *store.DB())
+	if err := txn.Put("a1", "intent"); err != nil {
+		t.Fatal(err)
+	}
+	intentTS := txn.Proto.Timestamp
+	manual.Increment(time.Minute.Nanoseconds())
+	for checkpoint := nextCheckpoint(); checkpoint.ResolvedTS != intentTS.Prev(); {
+		checkpoint = nextCheckpoint()
+	}
+	if err := txn.Put("a2", "value"); err != nil {
+		t.Fatal(err)
+	}
+	if txn.Proto.Timestamp != intentTS {
+		t.Fatalf("expected transaction at %s not to be pushed, got %s", intentTS, txn.Proto.Timestamp)
+	}
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
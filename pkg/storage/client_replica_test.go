@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
@@ -1105,3 +1106,77 @@ func TestRangeInfo(t *testing.T) {
 		t.Errorf("on scan reply, expected %+v; got %+v", expRangeInfos, reply.Header().RangeInfos)
 	}
 }
+
+// TestFollowerReadAtClosedTimestamp verifies that a follower serves
+// consistent reads at timestamps which have been closed by the lease
+// holder, while redirecting more recent reads to the lease holder, and
+// that the lease holder doesn't accept writes below its closed
+// timestamp.
+func TestFollowerReadAtClosedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer settings.TestingSetDuration(&storage.ClosedTimestampInterval, 10*time.Millisecond)()
+
+	mtc := &multiTestContext{}
+	mtc.Start(t, 2)
+	defer mtc.Stop()
+	mtc.replicateRange(1, 1)
+
+	key := roachpb.Key("a")
+	incArgs := incrementArgs(key, 5)
+	if _, pErr := client.SendWrapped(context.Background(), rg1(mtc.stores[0]), &incArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	mtc.waitForValues(key, []int64{5, 5})
+	readTS := mtc.clock.Now()
+
+	readFromFollower := func() (*roachpb.GetResponse, *roachpb.Error) {
+		gArgs := getArgs(key)
+		reply, pErr := client.SendWrappedWith(
+			context.Background(), rg1(mtc.stores[1]), roachpb.Header{Timestamp: readTS}, &gArgs,
+		)
+		if pErr != nil {
+			return nil, pErr
+		}
+		return reply.(*roachpb.GetResponse), nil
+	}
+
+	// The read timestamp has not been closed yet, so the follower
+	// redirects the read to the lease holder.
+	if _, pErr := readFromFollower(); !testutils.IsPError(pErr, "not lease holder") {
+		t.Fatalf("expected NotLeaseHolderError, got %v", pErr)
+	}
+
+	// Once time has passed, writes to the range close the read timestamp.
+	mtc.manualClock.Increment(100 * time.Millisecond.Nanoseconds())
+	util.SucceedsSoon(t, func() error {
+		pArgs := putArgs(roachpb.Key("b"), []byte("value"))
+		if _, pErr := client.SendWrapped(context.Background(), rg1(mtc.stores[0]), &pArgs); pErr != nil {
+			return pErr.GoError()
+		}
+		reply, pErr := readFromFollower()
+		if pErr != nil {
+			return pErr.GoError()
+		}
+		if v := mustGetInt(reply.Value); v != 5 {
+			return errors.Errorf("expected 5, got %d", v)
+		}
+		return nil
+	})
+
+	// A write at the closed read timestamp is moved above it and is not
+	// visible to follower reads at the read timestamp.
+	incArgs = incrementArgs(key, 5)
+	if _, pErr := client.SendWrappedWith(
+		context.Background(), rg1(mtc.stores[0]), roachpb.Header{Timestamp: readTS}, &incArgs,
+	); pErr != nil {
+		t.Fatal(pErr)
+	}
+	mtc.waitForValues(key, []int64{10, 10})
+	reply, pErr := readFromFollower()
+	if pErr != nil {
+		t.Fatal(pErr)
+	}
+	if v := mustGetInt(reply.Value); v != 5 {
+		t.Fatalf("expected 5, got %d", v)
+	}
+}
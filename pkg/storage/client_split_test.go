@@ -780,6 +780,86 @@ func TestStoreRangeSplitWithMaxBytesUpdate(t *testing.T) {
 	})
 }
 
+// TestStoreRangeSplitBackpressureWrites verifies that writes to a range
+// which has grown beyond the backpressure multiple of its maximum size
+// block until the range is split, while deletions go through.
+func TestStoreRangeSplitBackpressureWrites(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	const maxBytes = 1 << 16
+	descID := uint32(keys.MaxReservedDescID + 1)
+	tableBoundary := keys.MakeTablePrefix(descID)
+
+	var blockSplits int32
+	storeCfg := storage.TestStoreConfig(nil)
+	storeCfg.TestingKnobs.TestingCommandFilter =
+		func(filterArgs storagebase.FilterArgs) *roachpb.Error {
+			if atomic.LoadInt32(&blockSplits) == 0 {
+				return nil
+			}
+			if et, ok := filterArgs.Req.(*roachpb.EndTransactionRequest); ok {
+				if st := et.InternalCommitTrigger.GetSplitTrigger(); st != nil &&
+					st.LeftDesc.StartKey.Equal(tableBoundary) {
+					return roachpb.NewErrorf("splits of %s are blocked", tableBoundary)
+				}
+			}
+			return nil
+		}
+	store, stopper := createTestStoreWithConfig(t, storeCfg)
+	config.TestingSetupZoneConfigHook(stopper)
+	defer stopper.Stop()
+
+	config.TestingSetZoneConfig(descID, config.ZoneConfig{RangeMaxBytes: maxBytes})
+	if err := store.Gossip().AddInfoProto(gossip.KeySystemConfig, &config.SystemConfig{}, 0); err != nil {
+		t.Fatal(err)
+	}
+	var repl *storage.Replica
+	util.SucceedsSoon(t, func() error {
+		repl = store.LookupReplica(tableBoundary, nil)
+		if !repl.Desc().StartKey.Equal(tableBoundary) || repl.GetMaxBytes() != maxBytes {
+			return errors.Errorf("range %s has not been split off with %d max bytes", repl, maxBytes)
+		}
+		return nil
+	})
+
+	// Grow the range past twice its maximum size while it can't be split,
+	// with backpressure off so that filling it doesn't block.
+	atomic.StoreInt32(&blockSplits, 1)
+	resetMultiplier := storage.TestingSetBackpressureRangeSizeMultiplier(0)
+	fillRange(store, repl.RangeID, tableBoundary, 5*maxBytes/2, t)
+	resetMultiplier()
+
+	key := keys.MakeRowSentinelKey(append(append(roachpb.Key(nil), tableBoundary...), "key"...))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := store.DB().Put(ctx, key, "value"); !testutils.IsError(err, context.DeadlineExceeded.Error()) {
+		t.Fatalf("expected the write to be backpressured until its deadline, got %v", err)
+	}
+
+	// Deletions can only shrink the range, so they aren't backpressured.
+	if err := store.DB().Del(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the range can be split again, backpressured writes go through.
+	writeDone := make(chan error, 1)
+	go func() {
+		writeDone <- store.DB().Put(context.Background(), key, "value")
+	}()
+	select {
+	case err := <-writeDone:
+		t.Fatalf("write to an oversized range proceeded: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	atomic.StoreInt32(&blockSplits, 0)
+	store.ForceSplitScanAndProcess()
+	if err := <-writeDone; err != nil {
+		t.Fatal(err)
+	}
+	if repl := store.LookupReplica(tableBoundary, nil); repl.Desc().EndKey.Equal(roachpb.RKeyMax) {
+		t.Fatalf("expected range %s to have been split", repl)
+	}
+}
+
 // TestStoreRangeSystemSplits verifies that splits are based on the contents of
 // the SystemConfig span.
 func TestStoreRangeSystemSplits(t *testing.T) {
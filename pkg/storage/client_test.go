@@ -608,8 +608,9 @@ func (m *multiTestContext) populateDB(idx int, stopper *stop.Stopper) {
 			multiTestContext: m,
 			ds:               &m.distSenders[idx],
 		},
-		TransportFactory: m.kvTransportFactory,
-		RPCRetryOptions:  &retryOpts,
+		TransportFactory:        m.kvTransportFactory,
+		RPCRetryOptions:         &retryOpts,
+		ClosedTimestampInterval: storage.ClosedTimestampInterval.Get,
 	}, m.gossips[idx])
 	ambient := log.AmbientContext{Tracer: tracing.NewTracer()}
 	sender := kv.NewTxnCoordSender(
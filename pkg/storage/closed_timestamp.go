@@ -0,0 +1,186 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// ClosedTimestampInterval is the trailing interval behind the current
+// time at which range lease holders close timestamps: a lease holder
+// promises not to accept writes at or below its closed timestamp,
+// which allows any replica to serve consistent reads at timestamps
+// below it. Reads older than this interval are routed to the nearest
+// replica instead of the lease holder (see
+// kv.DistSenderConfig.ClosedTimestampInterval). Transactions which write
+// after running for longer than this interval are pushed above the
+// closed timestamp, so it must not be too short. A zero interval
+// disables closed timestamps and follower reads.
+//
+// Closed timestamps are disabled by default: every range closes them,
+// whether or not anyone reads from its followers, so any long-running
+// transaction would pay for them by being pushed and having to refresh
+// or restart.
+var ClosedTimestampInterval = settings.RegisterNonNegativeDurationSetting(
+	"kv.closed_timestamp.interval",
+	"the trailing interval at which lease holders close timestamps, below which "+
+		"followers serve consistent reads; transactions writing after running for "+
+		"longer than it are pushed; 0 disables follower reads",
+	0,
+)
+
+// closedTimestampTracker is used by the lease holder to decide which
+// timestamp it may close, that is, promise not to accept any further
+// writes at or below. The closed timestamp is attached to each Raft
+// command the lease holder proposes; once a command applies, every
+// replica of the range may serve consistent reads at timestamps at or
+// below it (see Replica.canServeFollowerRead).
+//
+// Writes are tracked from the moment their timestamp is determined
+// until they have been assigned a lease index. Since commands apply in
+// lease index order, it is only safe to close a timestamp once every
+// write which may still be assigned a lease index has a timestamp above
+// it. The tracker maintains two generations of writes: those tracked
+// before the last time the closed timestamp advanced (prevCount) and
+// those tracked since (curCount). Writes in the current generation are
+// forced above next, and next is only promoted to closed once the
+// previous generation has drained. The tracker is not safe for
+// concurrent use; it is protected by Replica.mu.
+type closedTimestampTracker struct {
+	// closed is the timestamp below which no tracked or future write
+	// may be proposed.
+	closed hlc.Timestamp
+	// next is the timestamp which will be closed once the writes of the
+	// previous generation have been proposed. Writes tracked in the
+	// current generation are forced above it.
+	next hlc.Timestamp
+	// epoch identifies the current generation.
+	epoch               int64
+	prevCount, curCount int
+}
+
+// track registers a write with the tracker. It returns the timestamp
+// which the write's timestamp must be forwarded above, along with the
+// epoch which must be passed to untrack once the write has been
+// assigned a lease index (or has failed).
+func (t *closedTimestampTracker) track() (hlc.Timestamp, int64) {
+	t.curCount++
+	return t.next, t.epoch
+}
+
+// untrack releases a write previously registered with track.
+func (t *closedTimestampTracker) untrack(epoch int64) {
+	switch epoch {
+	case t.epoch:
+		t.curCount--
+	case t.epoch - 1:
+		t.prevCount--
+	default:
+		panic("untrack called with unknown epoch")
+	}
+}
+
+// close attempts to advance the closed timestamp. If all writes of the
+// previous generation have been untracked, the timestamp writes of the
+// current generation have been forced above is closed, and the target
+// becomes the timestamp the next generation of writes is forced above.
+// Returns the closed timestamp.
+func (t *closedTimestampTracker) close(target hlc.Timestamp) hlc.Timestamp {
+	if t.prevCount == 0 {
+		t.closed = t.next
+		t.next.Forward(target)
+		t.prevCount, t.curCount = t.curCount, 0
+		t.epoch++
+	}
+	return t.closed
+}
+
+// trackClosedTimestamp registers a write with the replica's closed
+// timestamp tracker. It returns the timestamp the write's timestamp
+// must be forwarded above and a function which must be called once the
+// write has been proposed. The returned function may be called more
+// than once.
+//
+// Writes are tracked even while closed timestamps are disabled, so that
+// timestamps closed after they are enabled again don't fall above writes
+// which were in flight.
+func (r *Replica) trackClosedTimestamp() (hlc.Timestamp, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	minTS, epoch := r.mu.closedTimestampTracker.track()
+	// A previous lease holder may have closed a timestamp above the one
+	// tracked by this replica; writes must respect its promise as well.
+	minTS.Forward(r.mu.closedTimestamp)
+	var untracked bool
+	return minTS, func() {
+		if untracked {
+			return
+		}
+		untracked = true
+		r.mu.Lock()
+		r.mu.closedTimestampTracker.untrack(epoch)
+		r.mu.Unlock()
+	}
+}
+
+// closeTimestampLocked returns the closed timestamp to attach to a
+// command which is about to be proposed. Only a replica holding a valid
+// lease closes timestamps; the returned timestamp trails the current
+// time by the ClosedTimestampInterval setting, or less if writes
+// proposed earlier are still in flight. Replica.mu must be held.
+func (r *Replica) closeTimestampLocked() hlc.Timestamp {
+	interval := ClosedTimestampInterval.Get()
+	if interval <= 0 {
+		return hlc.ZeroTimestamp
+	}
+	now := r.store.Clock().Now()
+	if lease := r.mu.state.Lease; lease == nil ||
+		!lease.OwnedBy(r.store.StoreID()) || !lease.Covers(now) {
+		return hlc.ZeroTimestamp
+	}
+	return r.mu.closedTimestampTracker.close(now.Add(-interval.Nanoseconds(), 0))
+}
+
+// canServeFollowerRead returns whether the supplied read-only batch can
+// be served by this replica without holding the range lease. This is
+// the case for consistent reads whose timestamp, including the
+// uncertainty interval of transactional reads, is at or below the
+// closed timestamp of the last command applied on this replica.
+//
+// Note that the closed timestamp only advances when the lease holder
+// proposes commands, so ranges which do not receive writes don't serve
+// follower reads.
+func (r *Replica) canServeFollowerRead(ctx context.Context, ba roachpb.BatchRequest) bool {
+	if ClosedTimestampInterval.Get() <= 0 || ba.ReadConsistency != roachpb.CONSISTENT || ba.IsNonKV() {
+		return false
+	}
+	ts := ba.Timestamp
+	if ba.Txn != nil {
+		ts.Forward(ba.Txn.MaxTimestamp)
+	}
+	r.mu.Lock()
+	closedTS := r.mu.closedTimestamp
+	r.mu.Unlock()
+	if ts == hlc.ZeroTimestamp || closedTS.Less(ts) {
+		return false
+	}
+	log.Eventf(ctx, "serving follower read at %s (closed timestamp %s)", ts, closedTS)
+	return true
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestClosedTimestampTracker verifies that the closed timestamp does not
+// advance past a tracked write until the write has been untracked.
+func TestClosedTimestampTracker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	var tracker closedTimestampTracker
+	ts := func(wallTime int64) hlc.Timestamp {
+		return hlc.Timestamp{WallTime: wallTime}
+	}
+
+	if closed := tracker.close(ts(10)); closed != hlc.ZeroTimestamp {
+		t.Fatalf("expected zero closed timestamp, got %s", closed)
+	}
+
+	// A write tracked now is forced above 10, which is closed as soon as the
+	// previous (empty) generation has drained.
+	minTS, epoch := tracker.track()
+	if minTS != ts(10) {
+		t.Fatalf("expected write to be forced above %s, got %s", ts(10), minTS)
+	}
+	if closed := tracker.close(ts(20)); closed != ts(10) {
+		t.Fatalf("expected closed timestamp %s, got %s", ts(10), closed)
+	}
+
+	// The write may still be proposed at a timestamp below 20, so 20 can't
+	// be closed until it has been untracked.
+	for i := 0; i < 2; i++ {
+		if closed := tracker.close(ts(30)); closed != ts(10) {
+			t.Fatalf("%d: expected closed timestamp %s, got %s", i, ts(10), closed)
+		}
+	}
+	tracker.untrack(epoch)
+	if closed := tracker.close(ts(30)); closed != ts(20) {
+		t.Fatalf("expected closed timestamp %s, got %s", ts(20), closed)
+	}
+
+	// The closed timestamp never regresses.
+	tracker.close(ts(5))
+	if closed := tracker.close(ts(5)); closed != ts(30) {
+		t.Fatalf("expected closed timestamp %s, got %s", ts(30), closed)
+	}
+}
+
+// TestClosedTimestampPushesLongRunningWriters verifies that closed
+// timestamps, which are disabled by default, push the writes of
+// transactions running for longer than the closed timestamp interval.
+func TestClosedTimestampPushesLongRunningWriters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	if interval := ClosedTimestampInterval.Get(); interval != 0 {
+		t.Fatalf("expected closed timestamps to be disabled by default; got %s", interval)
+	}
+
+	testCases := []struct {
+		interval  time.Duration
+		expPushed bool
+	}{
+		{0, false},
+		{100 * time.Millisecond, true},
+	}
+	for _, c := range testCases {
+		t.Run(c.interval.String(), func(t *testing.T) {
+			defer settings.TestingSetDuration(&ClosedTimestampInterval, c.interval)()
+			store, manual, stopper := createTestStore(t)
+			defer stopper.Stop()
+
+			txn := newTransaction("txn", roachpb.Key("a"), 1, enginepb.SERIALIZABLE, store.cfg.Clock)
+			writeIntent(t, store, txn)
+			origTS := txn.Timestamp
+
+			// Outlive the interval, then propose a command so that the lease
+			// holder closes a timestamp.
+			manual.Increment(time.Second.Nanoseconds())
+			pArgs := putArgs(roachpb.Key("x"), []byte("value"))
+			if _, pErr := client.SendWrapped(context.Background(), store.testSender(), &pArgs); pErr != nil {
+				t.Fatal(pErr)
+			}
+
+			pArgs = putArgs(roachpb.Key("b"), []byte("value"))
+			txn.Sequence++
+			resp, pErr := client.SendWrappedWith(
+				context.Background(), store.testSender(), roachpb.Header{Txn: txn}, &pArgs,
+			)
+			if pErr != nil {
+				t.Fatal(pErr)
+			}
+			if pushed := origTS.Less(resp.Header().Txn.Timestamp); pushed != c.expPushed {
+				t.Errorf("expected pushed=%t; got timestamp %s (originally %s)",
+					c.expPushed, resp.Header().Txn.Timestamp, origTS)
+			}
+		})
+	}
+}
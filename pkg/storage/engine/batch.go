@@ -16,13 +16,25 @@
 
 package engine
 
-import "github.com/cockroachdb/cockroach/pkg/util/hlc"
+import (
+	"encoding/binary"
 
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// BatchType represents the type of an entry in an encoded RocksDB batch.
+type BatchType byte
+
+// These values come from rocksdb/db/dbformat.h.
 const (
-	batchTypeDeletion byte = 0x0
-	batchTypeValue         = 0x1
-	batchTypeMerge         = 0x2
+	BatchTypeDeletion BatchType = 0x0
+	BatchTypeValue    BatchType = 0x1
+	BatchTypeMerge    BatchType = 0x2
+)
 
+const (
 	// The batch header is composed of an 8-byte sequence number (all zeroes) and
 	// 4-byte count of the number of entries in the batch.
 	headerSize       int = 12
@@ -51,8 +63,8 @@ const (
 //      data: uint8[len]
 //
 // The rocksDBBatchBuilder code currently only supports kTypeValue
-// (batchTypeValue), kTypeDeletion (batchTypeDeletion)and kTypeMerge
-// (batchTypeMerge) operations. Before a batch is written to the RocksDB
+// (BatchTypeValue), kTypeDeletion (BatchTypeDeletion)and kTypeMerge
+// (BatchTypeMerge) operations. Before a batch is written to the RocksDB
 // write-ahead-log, the sequence number is 0. The "fixed32" format is little
 // endian.
 //
@@ -175,7 +187,7 @@ func (b *rocksDBBatchBuilder) encodeKey(key MVCCKey, extra int) {
 	b.repr[len(b.repr)-1-extra] = byte(timestampLength)
 }
 
-func (b *rocksDBBatchBuilder) encodeKeyValue(key MVCCKey, value []byte, tag byte) {
+func (b *rocksDBBatchBuilder) encodeKeyValue(key MVCCKey, value []byte, tag BatchType) {
 	b.maybeInit()
 	b.count++
 
@@ -184,7 +196,7 @@ func (b *rocksDBBatchBuilder) encodeKeyValue(key MVCCKey, value []byte, tag byte
 
 	pos := len(b.repr)
 	b.encodeKey(key, extra)
-	b.repr[pos] = tag
+	b.repr[pos] = byte(tag)
 
 	pos = len(b.repr) - extra
 	n := putUvarint32(b.repr[pos:], l)
@@ -193,11 +205,11 @@ func (b *rocksDBBatchBuilder) encodeKeyValue(key MVCCKey, value []byte, tag byte
 }
 
 func (b *rocksDBBatchBuilder) Put(key MVCCKey, value []byte) {
-	b.encodeKeyValue(key, value, batchTypeValue)
+	b.encodeKeyValue(key, value, BatchTypeValue)
 }
 
 func (b *rocksDBBatchBuilder) Merge(key MVCCKey, value []byte) {
-	b.encodeKeyValue(key, value, batchTypeMerge)
+	b.encodeKeyValue(key, value, BatchTypeMerge)
 }
 
 func (b *rocksDBBatchBuilder) Clear(key MVCCKey) {
@@ -205,5 +217,124 @@ func (b *rocksDBBatchBuilder) Clear(key MVCCKey) {
 	b.count++
 	pos := len(b.repr)
 	b.encodeKey(key, 0)
-	b.repr[pos] = batchTypeDeletion
+	b.repr[pos] = byte(BatchTypeDeletion)
+}
+
+// decodeMVCCKey decodes a key encoded by rocksDBBatchBuilder.encodeKey.
+func decodeMVCCKey(encodedKey []byte) (MVCCKey, error) {
+	if len(encodedKey) == 0 {
+		return MVCCKey{}, errors.New("empty key")
+	}
+	tsLen := int(encodedKey[len(encodedKey)-1])
+	keyPartEnd := len(encodedKey) - 1 - tsLen
+	if keyPartEnd < 0 {
+		return MVCCKey{}, errors.Errorf("invalid encoded mvcc key: %x", encodedKey)
+	}
+	key := MVCCKey{Key: encodedKey[:keyPartEnd]}
+	switch tsLen {
+	case 0:
+	case 9, 13:
+		ts := encodedKey[keyPartEnd+1 : len(encodedKey)-1]
+		key.Timestamp.WallTime = int64(binary.BigEndian.Uint64(ts[:8]))
+		if tsLen == 13 {
+			key.Timestamp.Logical = int32(binary.BigEndian.Uint32(ts[8:]))
+		}
+	default:
+		return MVCCKey{}, errors.Errorf("invalid encoded mvcc key: %x", encodedKey)
+	}
+	return key, nil
+}
+
+// RocksDBBatchReader is used to iterate the entries in a RocksDB batch
+// representation, such as one returned by Batch.Repr. Entries are read by
+// calling Next until it returns false, after which Error should be checked.
+type RocksDBBatchReader struct {
+	repr []byte
+	// count is the number of entries which remain to be read.
+	count uint32
+	err   error
+
+	// The current entry.
+	typ   BatchType
+	key   MVCCKey
+	value []byte
+}
+
+// NewRocksDBBatchReader creates a RocksDBBatchReader from the given batch
+// representation.
+func NewRocksDBBatchReader(repr []byte) (*RocksDBBatchReader, error) {
+	if len(repr) < headerSize {
+		return nil, errors.Errorf("batch repr too small: %d < %d", len(repr), headerSize)
+	}
+	return &RocksDBBatchReader{
+		repr:  repr[headerSize:],
+		count: binary.LittleEndian.Uint32(repr[8:headerSize]),
+	}, nil
+}
+
+// Next advances to the next entry in the batch, returning false when the
+// batch is exhausted or an error was encountered.
+func (r *RocksDBBatchReader) Next() bool {
+	if r.err != nil || r.count == 0 {
+		return false
+	}
+	if len(r.repr) == 0 {
+		r.err = errors.New("invalid batch repr: fewer entries than its count")
+		return false
+	}
+	r.count--
+	r.typ = BatchType(r.repr[0])
+	r.repr = r.repr[1:]
+	var encodedKey []byte
+	if encodedKey, r.err = r.varstring(); r.err != nil {
+		return false
+	}
+	if r.key, r.err = decodeMVCCKey(encodedKey); r.err != nil {
+		return false
+	}
+	switch r.typ {
+	case BatchTypeDeletion:
+		r.value = nil
+	case BatchTypeValue, BatchTypeMerge:
+		if r.value, r.err = r.varstring(); r.err != nil {
+			return false
+		}
+	default:
+		r.err = errors.Errorf("unexpected batch entry type %d", r.typ)
+		return false
+	}
+	return true
+}
+
+func (r *RocksDBBatchReader) varstring() ([]byte, error) {
+	n, m := binary.Uvarint(r.repr)
+	if m <= 0 || n > uint64(len(r.repr)-m) {
+		return nil, errors.New("invalid batch repr: malformed varstring")
+	}
+	s := r.repr[m : m+int(n)]
+	r.repr = r.repr[m+int(n):]
+	return s, nil
+}
+
+// BatchType returns the type of the current entry.
+func (r *RocksDBBatchReader) BatchType() BatchType {
+	return r.typ
+}
+
+// Key returns the key of the current entry. The key is only valid until
+// the batch representation is modified.
+func (r *RocksDBBatchReader) Key() MVCCKey {
+	return r.key
+}
+
+// Value returns the value of the current entry, which is nil for
+// deletions. The value is only valid until the batch representation is
+// modified.
+func (r *RocksDBBatchReader) Value() []byte {
+	return r.value
+}
+
+// Error returns the error, if any, which the reader encountered.
+func (r *RocksDBBatchReader) Error() error {
+	return r.err
 }
@@ -27,6 +27,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -873,3 +874,70 @@ func TestBatchDistinctPanics(t *testing.T) {
 		}()
 	}
 }
+
+func TestRocksDBBatchReader(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	e := NewInMem(roachpb.Attributes{}, 1<<20)
+	stopper.AddCloser(e)
+
+	batch := e.NewBatch()
+	defer batch.Close()
+
+	type entry struct {
+		typ   BatchType
+		key   MVCCKey
+		value []byte
+	}
+	expected := []entry{
+		{BatchTypeValue, mvccKey("a"), []byte("value")},
+		{BatchTypeDeletion, MVCCKey{roachpb.Key("b"), hlc.Timestamp{WallTime: 1}}, nil},
+		{BatchTypeMerge, MVCCKey{roachpb.Key("c"), hlc.Timestamp{WallTime: 2, Logical: 3}}, appender("bar")},
+	}
+	for _, ent := range expected {
+		var err error
+		switch ent.typ {
+		case BatchTypeValue:
+			err = batch.Put(ent.key, ent.value)
+		case BatchTypeDeletion:
+			err = batch.Clear(ent.key)
+		case BatchTypeMerge:
+			err = batch.Merge(ent.key, ent.value)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := NewRocksDBBatchReader(batch.Repr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []entry
+	for r.Next() {
+		found = append(found, entry{r.BatchType(), r.Key(), r.Value()})
+	}
+	if err := r.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, found) {
+		t.Fatalf("expected %v, but found %v", expected, found)
+	}
+
+	// Truncated representations are rejected.
+	repr := batch.Repr()
+	if _, err := NewRocksDBBatchReader(repr[:headerSize-1]); !testutils.IsError(err, "too small") {
+		t.Fatalf("expected error for truncated header, got %v", err)
+	}
+	r, err = NewRocksDBBatchReader(repr[:len(repr)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for r.Next() {
+	}
+	if err := r.Error(); !testutils.IsError(err, "invalid batch repr") {
+		t.Fatalf("expected error for truncated batch, got %v", err)
+	}
+}
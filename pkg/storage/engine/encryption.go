@@ -0,0 +1,194 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Files written to an encrypted store start with a header of the following
+// layout, which must be kept in sync with DBEncryptedEnv in
+// rocksdb/db.cc:
+//
+//	magic (8 bytes) | store key ID (8 bytes) | IV (16 bytes) |
+//	data key wrapped with the store key (32 bytes)
+//
+// The contents of the file following the header are encrypted with
+// AES-256 in CTR mode using the file's data key and IV. Files without the
+// header are plaintext, which allows encryption to be enabled on an
+// existing store: only files written afterwards are encrypted.
+const (
+	encryptionMagic      = "crdbenc1"
+	encryptionKeyIDSize  = 8
+	encryptionHeaderSize = 64
+)
+
+// EncryptionKey is a store key, used to wrap the keys with which the
+// individual files of a store are encrypted.
+type EncryptionKey struct {
+	// ID identifies the key in the headers of the files it wraps the keys
+	// of. It is derived from the key itself.
+	ID  []byte
+	Key []byte
+}
+
+// IDString returns the printable form of the key's ID.
+func (k EncryptionKey) IDString() string {
+	return hex.EncodeToString(k.ID)
+}
+
+// EncryptionKeys are the store keys an encrypted store is opened with.
+type EncryptionKeys struct {
+	// Active is the key used for files written by the store. If it is
+	// unset, new files are written in plaintext.
+	Active *EncryptionKey
+	// Old are previous store keys, which are only needed to read the files
+	// written before the active key was rotated in. Files encrypted with an
+	// old key are re-encrypted with the active key as they are rewritten by
+	// compactions; see `cockroach debug encryption-status`.
+	Old []EncryptionKey
+}
+
+// LoadEncryptionKey reads a store key from a file containing 16, 24 or 32
+// random bytes, selecting AES-128, AES-192 or AES-256 respectively.
+func LoadEncryptionKey(path string) (EncryptionKey, error) {
+	key, err := ioutil.ReadFile(path)
+	if err != nil {
+		return EncryptionKey{}, errors.Wrap(err, "could not read encryption key")
+	}
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return EncryptionKey{}, errors.Errorf(
+			"encryption key %s must be 16, 24 or 32 bytes long, found %d", path, len(key))
+	}
+	sum := sha256.Sum256(key)
+	return EncryptionKey{ID: sum[:encryptionKeyIDSize], Key: key}, nil
+}
+
+// LoadEncryptionKeys reads the active store key (if activePath is not
+// empty) and the old store keys from the specified files.
+func LoadEncryptionKeys(activePath string, oldPaths []string) (*EncryptionKeys, error) {
+	keys := &EncryptionKeys{}
+	if activePath != "" {
+		key, err := LoadEncryptionKey(activePath)
+		if err != nil {
+			return nil, err
+		}
+		keys.Active = &key
+	}
+	for _, path := range oldPaths {
+		key, err := LoadEncryptionKey(path)
+		if err != nil {
+			return nil, err
+		}
+		keys.Old = append(keys.Old, key)
+	}
+	return keys, nil
+}
+
+// encode serializes the keys for DBOpen. Each key is encoded as a length
+// prefixed ID followed by the length prefixed key, starting with the
+// active key (if any).
+func (k *EncryptionKeys) encode() []byte {
+	if k == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	encodeKey := func(key EncryptionKey) {
+		buf.WriteByte(byte(len(key.ID)))
+		buf.Write(key.ID)
+		buf.WriteByte(byte(len(key.Key)))
+		buf.Write(key.Key)
+	}
+	if k.Active != nil {
+		encodeKey(*k.Active)
+	} else {
+		// An empty active key, which causes new files to be written in
+		// plaintext.
+		buf.Write([]byte{0, 0})
+	}
+	for _, key := range k.Old {
+		encodeKey(key)
+	}
+	return buf.Bytes()
+}
+
+// FileEncryptionStatus describes how a single file of a store is
+// encrypted.
+type FileEncryptionStatus struct {
+	Name string
+	Size int64
+	// KeyID is the printable ID of the store key the file is encrypted
+	// with, or empty if the file is in plaintext.
+	KeyID string
+}
+
+// GetEncryptionStatus returns the encryption status of each of the files
+// in the store directory, sorted by file name. It only inspects the file
+// headers, so it doesn't require the store keys.
+func GetEncryptionStatus(dir string) ([]FileEncryptionStatus, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []FileEncryptionStatus
+	for _, info := range infos {
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		keyID, err := readEncryptionKeyID(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, FileEncryptionStatus{
+			Name:  info.Name(),
+			Size:  info.Size(),
+			KeyID: keyID,
+		})
+	}
+	return files, nil
+}
+
+// readEncryptionKeyID returns the printable ID of the store key the file
+// is encrypted with, or the empty string if it is in plaintext.
+func readEncryptionKeyID(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	header := make([]byte, encryptionHeaderSize)
+	if _, err := io.ReadFull(f, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// Too short to have been encrypted.
+			return "", nil
+		}
+		return "", err
+	}
+	if !bytes.Equal(header[:len(encryptionMagic)], []byte(encryptionMagic)) {
+		return "", nil
+	}
+	id := header[len(encryptionMagic) : len(encryptionMagic)+encryptionKeyIDSize]
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func writeTestEncryptionKey(t *testing.T, dir, name string) string {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, key, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// sstKeyIDs returns the IDs of the keys the store's sstables are encrypted
+// with.
+func sstKeyIDs(t *testing.T, dir string) map[string]struct{} {
+	files, err := GetEncryptionStatus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]struct{})
+	for _, f := range files {
+		if strings.HasSuffix(f.Name, ".sst") {
+			ids[f.KeyID] = struct{}{}
+		}
+	}
+	return ids
+}
+
+// TestRocksDBEncryption verifies that the files of an encrypted store
+// don't contain plaintext data, that the store can't be opened without its
+// keys, and that rotating the store key applies to newly written files.
+func TestRocksDBEncryption(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	keyDir, keyDirCleanup := testutils.TempDir(t, 0)
+	defer keyDirCleanup()
+	dir, dirCleanup := testutils.TempDir(t, 0)
+	defer dirCleanup()
+
+	key1, err := LoadEncryptionKeys(writeTestEncryptionKey(t, keyDir, "1.key"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key2Path := writeTestEncryptionKey(t, keyDir, "2.key")
+
+	open := func(keys *EncryptionKeys) (*RocksDB, error) {
+		return NewEncryptedRocksDB(roachpb.Attributes{}, dir, RocksDBCache{}, 0, DefaultMaxOpenFiles, keys)
+	}
+	secret := []byte("a secret value")
+	put := func(db *RocksDB, k string) {
+		if err := db.Put(mvccKey(k), secret); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get := func(db *RocksDB, k string) {
+		if val, err := db.Get(mvccKey(k)); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(val, secret) {
+			t.Fatalf("expected %q for %s, got %q", secret, k, val)
+		}
+	}
+
+	db, err := open(key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	put(db, "a")
+	db.Close()
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		b, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(b, secret) {
+			t.Fatalf("found plaintext value in %s", f.Name())
+		}
+	}
+	if ids := sstKeyIDs(t, dir); len(ids) != 1 {
+		t.Fatalf("expected sstables to use a single key, found %v", ids)
+	} else if _, ok := ids[key1.Active.IDString()]; !ok {
+		t.Fatalf("expected sstables to use key %s, found %v", key1.Active.IDString(), ids)
+	}
+
+	// Opening the store without the key fails.
+	if _, err := open(nil); !testutils.IsError(err, "unknown store key") {
+		t.Fatalf("expected unknown store key error, got %v", err)
+	}
+
+	// Rotate the key. Existing files remain readable with the old key and
+	// new files use the new key.
+	key2, err := LoadEncryptionKeys(key2Path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key2.Old = []EncryptionKey{*key1.Active}
+	db, err = open(key2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get(db, "a")
+	put(db, "b")
+	db.Close()
+	if ids := sstKeyIDs(t, dir); len(ids) != 2 {
+		t.Fatalf("expected sstables to use both keys, found %v", ids)
+	}
+
+	// Compacting the store rewrites all of the sstables with the new key,
+	// after which the old key is no longer needed.
+	db, err = open(key2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if ids := sstKeyIDs(t, dir); len(ids) != 1 {
+		t.Fatalf("expected sstables to use a single key, found %v", ids)
+	} else if _, ok := ids[key2.Active.IDString()]; !ok {
+		t.Fatalf("expected sstables to use key %s, found %v", key2.Active.IDString(), ids)
+	}
+}
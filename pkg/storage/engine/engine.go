@@ -222,6 +222,19 @@ type Stats struct {
 	Flushes                  int64
 	Compactions              int64
 	TableReadersMemEstimate  int64
+	// L0FileCount is the number of sstables in level 0, which are read by
+	// every read that isn't satisfied by the memtables.
+	L0FileCount int64
+	// ImmutableMemtableCount is the number of memtables waiting to be
+	// flushed.
+	ImmutableMemtableCount int64
+	// PendingCompactionBytesEstimate is the estimated number of bytes which
+	// compactions need to rewrite to bring every level down to its target
+	// size.
+	PendingCompactionBytesEstimate int64
+	// WriteStallMicros is the cumulative time writers have been stalled or
+	// slowed down by RocksDB while flushes and compactions caught up.
+	WriteStallMicros int64
 }
 
 // PutProto sets the given key to the protobuf-serialized byte string
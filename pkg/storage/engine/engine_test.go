@@ -63,6 +63,9 @@ func runWithAllEngines(test func(e Engine, t *testing.T), t *testing.T) {
 	inMem := NewInMem(inMemAttrs, testCacheSize)
 	stopper.AddCloser(inMem)
 	test(inMem, t)
+	goInMem := NewGoInMem(inMemAttrs, testCacheSize)
+	stopper.AddCloser(goInMem)
+	test(goInMem, t)
 }
 
 // TestEngineBatchCommit writes a batch containing 10K rows (all the
@@ -155,7 +158,7 @@ func TestEngineBatchStaleCachedIterator(t *testing.T) {
 
 		// Higher-level failure mode. Mostly for documentation.
 		{
-			batch := eng.NewBatch().(*rocksDBBatch)
+			batch := eng.NewBatch()
 			defer batch.Close()
 
 			key := roachpb.Key("z")
@@ -677,7 +680,7 @@ func TestSnapshotMethods(t *testing.T) {
 		// Verify Attrs.
 		var attrs roachpb.Attributes
 		switch engine.(type) {
-		case InMem:
+		case InMem, *GoInMem:
 			attrs = inMemAttrs
 		}
 		if !reflect.DeepEqual(engine.Attrs(), attrs) {
@@ -0,0 +1,880 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// GoInMem is an in-memory engine implemented in pure Go, for use by tests
+// which want to avoid the cost of creating RocksDB instances. Its data is
+// kept in a memtable ordered like RocksDB orders MVCC keys. Since memtables
+// are persistent, snapshots and iterators capture the engine's root instead
+// of copying its data. Merges are resolved when they're written, using the
+// Go port of the merge operator in merge.go.
+//
+// GoInMem doesn't limit the size of its data, and reports its entire size as
+// available.
+type GoInMem struct {
+	attrs   roachpb.Attributes
+	maxSize int64
+
+	mu struct {
+		syncutil.Mutex
+		root   *memNode
+		closed bool
+	}
+}
+
+var _ Engine = &GoInMem{}
+
+// NewGoInMem allocates and returns a new, opened GoInMem engine.
+func NewGoInMem(attrs roachpb.Attributes, maxSize int64) *GoInMem {
+	return &GoInMem{
+		attrs:   attrs,
+		maxSize: maxSize,
+	}
+}
+
+func (e *GoInMem) root() *memNode {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.mu.root
+}
+
+// update replaces the engine's root with the one returned by f, unless f
+// returns an error.
+func (e *GoInMem) update(f func(root *memNode) (*memNode, error)) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	root, err := f(e.mu.root)
+	if err != nil {
+		return err
+	}
+	e.mu.root = root
+	return nil
+}
+
+// Close releases the engine's data.
+func (e *GoInMem) Close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.mu.root = nil
+	e.mu.closed = true
+}
+
+func (e *GoInMem) closed() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.mu.closed
+}
+
+// Attrs returns the engine's attributes.
+func (e *GoInMem) Attrs() roachpb.Attributes {
+	return e.attrs
+}
+
+// Capacity returns the engine's size as both its capacity and its available
+// space.
+func (e *GoInMem) Capacity() (roachpb.StoreCapacity, error) {
+	return roachpb.StoreCapacity{
+		Capacity:  e.maxSize,
+		Available: e.maxSize,
+	}, nil
+}
+
+// Flush is a noop.
+func (e *GoInMem) Flush() error {
+	return nil
+}
+
+// GetStats returns the engine's stats. Only MemtableTotalSize, the size of
+// the engine's keys and values, is filled in: the rest describe RocksDB's
+// block cache, sstables and compactions, which GoInMem doesn't have, and
+// are always zero.
+func (e *GoInMem) GetStats() (*Stats, error) {
+	return &Stats{
+		MemtableTotalSize: memtableSize(e.root()),
+	}, nil
+}
+
+// memtableSize returns the encoded size of the keys and values in the
+// memtable rooted at n.
+func memtableSize(n *memNode) int64 {
+	if n == nil {
+		return 0
+	}
+	size := int64(n.key.EncodedSize() + len(n.value))
+	return size + memtableSize(n.left) + memtableSize(n.right)
+}
+
+// Put sets the given key to the value provided.
+func (e *GoInMem) Put(key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return e.update(func(root *memNode) (*memNode, error) {
+		return applyToMemtable(root, BatchTypeValue, key, value)
+	})
+}
+
+// Merge merges the value into the existing value of the key.
+func (e *GoInMem) Merge(key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return e.update(func(root *memNode) (*memNode, error) {
+		return applyToMemtable(root, BatchTypeMerge, key, value)
+	})
+}
+
+// Clear removes the item from the engine with the given key.
+func (e *GoInMem) Clear(key MVCCKey) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return e.update(func(root *memNode) (*memNode, error) {
+		return applyToMemtable(root, BatchTypeDeletion, key, nil)
+	})
+}
+
+// ApplyBatchRepr atomically applies a set of batched updates.
+func (e *GoInMem) ApplyBatchRepr(repr []byte) error {
+	r, err := NewRocksDBBatchReader(repr)
+	if err != nil {
+		return err
+	}
+	return e.update(func(root *memNode) (*memNode, error) {
+		for r.Next() {
+			if root, err = applyToMemtable(root, r.BatchType(), r.Key(), r.Value()); err != nil {
+				return nil, err
+			}
+		}
+		return root, r.Error()
+	})
+}
+
+// Get returns the value for the given key.
+func (e *GoInMem) Get(key MVCCKey) ([]byte, error) {
+	if len(key.Key) == 0 {
+		return nil, emptyKeyError()
+	}
+	return memGet(nil, e.root(), key)
+}
+
+// GetProto fetches the value at the specified key and unmarshals it.
+func (e *GoInMem) GetProto(
+	key MVCCKey, msg proto.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	return memGetProto(e, key, msg)
+}
+
+// Iterate iterates from start to end keys, invoking f on each key/value
+// pair. See engine.Iterate for details.
+func (e *GoInMem) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	return memIterate(e, start, end, f)
+}
+
+// NewIterator returns an iterator over the engine's data at the time of the
+// call. The prefix option is ignored.
+func (e *GoInMem) NewIterator(prefix bool) Iterator {
+	return newMemIterator(e, e.root(), nil)
+}
+
+// NewSnapshot returns a snapshot of the engine.
+func (e *GoInMem) NewSnapshot() Reader {
+	return &goInMemSnapshot{
+		parent: e,
+		root:   e.root(),
+	}
+}
+
+// NewBatch returns a new batch wrapping the engine.
+func (e *GoInMem) NewBatch() Batch {
+	b := &goInMemBatch{parent: e}
+	b.distinct.goInMemBatch = b
+	return b
+}
+
+func copyMVCCKey(key MVCCKey) MVCCKey {
+	return MVCCKey{
+		Key:       append(roachpb.Key(nil), key.Key...),
+		Timestamp: key.Timestamp,
+	}
+}
+
+// applyToMemtable returns the engine memtable with a write applied to it.
+// The key and value are copied.
+func applyToMemtable(root *memNode, typ BatchType, key MVCCKey, value []byte) (*memNode, error) {
+	switch typ {
+	case BatchTypeValue:
+		value = append([]byte{}, value...)
+	case BatchTypeDeletion:
+		return root.remove(key), nil
+	case BatchTypeMerge:
+		var existing []byte
+		if n := root.get(key); n != nil {
+			existing = n.value
+		}
+		var err error
+		if value, err = fullMerge(existing, [][]byte{value}); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unexpected batch entry type %d", typ)
+	}
+	return root.insert(newMemNode(copyMVCCKey(key), value, memEntryValue, nil)), nil
+}
+
+// resolve returns the value of the entry's key, given the entry in the layer
+// below it with the same key, if any. It returns false if the key doesn't
+// exist.
+func (n *memNode) resolve(below *memNode) ([]byte, bool, error) {
+	var value []byte
+	var ok bool
+	switch n.kind {
+	case memEntryValue:
+		value, ok = n.value, true
+	case memEntryMerge:
+		if below != nil {
+			value, ok = below.value, true
+		}
+	}
+	if len(n.operands) == 0 {
+		return value, ok, nil
+	}
+	merged, err := fullMerge(value, n.operands)
+	if err != nil {
+		return nil, false, err
+	}
+	return merged, true, nil
+}
+
+// memGet returns a copy of the value of the key in the batch writes layered
+// on top of the engine memtable.
+func memGet(writes, root *memNode, key MVCCKey) ([]byte, error) {
+	n := root.get(key)
+	value, ok := []byte(nil), n != nil
+	if ok {
+		value = n.value
+	}
+	if w := writes.get(key); w != nil {
+		var err error
+		if value, ok, err = w.resolve(n); err != nil {
+			return nil, err
+		}
+	}
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte{}, value...), nil
+}
+
+func memGetProto(
+	r Reader, key MVCCKey, msg proto.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	var value []byte
+	if value, err = r.Get(key); err != nil {
+		return
+	}
+	if len(value) == 0 {
+		if msg != nil {
+			msg.Reset()
+		}
+		return
+	}
+	ok = true
+	if msg != nil {
+		err = proto.Unmarshal(value, msg)
+	}
+	keyBytes = int64(key.EncodedSize())
+	valBytes = int64(len(value))
+	return
+}
+
+func memIterate(r Reader, start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	if !start.Less(end) {
+		return nil
+	}
+	it := r.NewIterator(false)
+	defer it.Close()
+
+	for it.Seek(start); it.Valid(); it.Next() {
+		k := it.Key()
+		if !k.Less(end) {
+			break
+		}
+		if done, err := f(MVCCKeyValue{Key: k, Value: it.Value()}); done || err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+type goInMemSnapshot struct {
+	parent   *GoInMem
+	root     *memNode
+	released bool
+}
+
+func (s *goInMemSnapshot) Close() {
+	s.root = nil
+	s.released = true
+}
+
+func (s *goInMemSnapshot) closed() bool {
+	return s.released || s.parent.closed()
+}
+
+func (s *goInMemSnapshot) Get(key MVCCKey) ([]byte, error) {
+	if len(key.Key) == 0 {
+		return nil, emptyKeyError()
+	}
+	return memGet(nil, s.root, key)
+}
+
+func (s *goInMemSnapshot) GetProto(
+	key MVCCKey, msg proto.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	return memGetProto(s, key, msg)
+}
+
+func (s *goInMemSnapshot) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	return memIterate(s, start, end, f)
+}
+
+func (s *goInMemSnapshot) NewIterator(prefix bool) Iterator {
+	return newMemIterator(s, s.root, nil)
+}
+
+// goInMemBatch buffers its writes in a memtable of deletions, values and
+// merge operands which its reads layer on top of the engine's current data,
+// as well as in the RocksDB batch representation which is applied to the
+// engine on commit.
+type goInMemBatch struct {
+	parent       *GoInMem
+	builder      rocksDBBatchBuilder
+	writes       *memNode
+	distinct     goInMemDistinctBatch
+	distinctOpen bool
+	committed    bool
+}
+
+func (b *goInMemBatch) Close() {
+	b.writes = nil
+}
+
+func (b *goInMemBatch) closed() bool {
+	return b.committed || b.parent.closed()
+}
+
+func (b *goInMemBatch) put(key MVCCKey, value []byte) {
+	b.builder.Put(key, value)
+	b.writes = b.writes.insert(newMemNode(copyMVCCKey(key), append([]byte{}, value...),
+		memEntryValue, nil))
+}
+
+func (b *goInMemBatch) merge(key MVCCKey, value []byte) {
+	b.builder.Merge(key, value)
+	operand := append([]byte(nil), value...)
+	if n := b.writes.get(key); n != nil {
+		operands := append(n.operands[:len(n.operands):len(n.operands)], operand)
+		b.writes = b.writes.insert(newMemNode(n.key, n.value, n.kind, operands))
+		return
+	}
+	b.writes = b.writes.insert(newMemNode(copyMVCCKey(key), nil, memEntryMerge,
+		[][]byte{operand}))
+}
+
+func (b *goInMemBatch) clear(key MVCCKey) {
+	b.builder.Clear(key)
+	b.writes = b.writes.insert(newMemNode(copyMVCCKey(key), nil, memEntryDeletion, nil))
+}
+
+func (b *goInMemBatch) Put(key MVCCKey, value []byte) error {
+	if b.distinctOpen {
+		panic("distinct batch open")
+	}
+	b.put(key, value)
+	return nil
+}
+
+func (b *goInMemBatch) Merge(key MVCCKey, value []byte) error {
+	if b.distinctOpen {
+		panic("distinct batch open")
+	}
+	b.merge(key, value)
+	return nil
+}
+
+func (b *goInMemBatch) Clear(key MVCCKey) error {
+	if b.distinctOpen {
+		panic("distinct batch open")
+	}
+	b.clear(key)
+	return nil
+}
+
+// ApplyBatchRepr atomically applies a set of batched updates to the batch.
+func (b *goInMemBatch) ApplyBatchRepr(repr []byte) error {
+	if b.distinctOpen {
+		panic("distinct batch open")
+	}
+	// Validate the whole representation first so that none of it is applied
+	// if part of it is invalid.
+	r, err := NewRocksDBBatchReader(repr)
+	if err != nil {
+		return err
+	}
+	for r.Next() {
+	}
+	if err := r.Error(); err != nil {
+		return err
+	}
+	r, _ = NewRocksDBBatchReader(repr)
+	for r.Next() {
+		switch r.BatchType() {
+		case BatchTypeValue:
+			b.put(r.Key(), r.Value())
+		case BatchTypeMerge:
+			b.merge(r.Key(), r.Value())
+		case BatchTypeDeletion:
+			b.clear(r.Key())
+		}
+	}
+	return nil
+}
+
+func (b *goInMemBatch) Get(key MVCCKey) ([]byte, error) {
+	if b.distinctOpen {
+		panic("distinct batch open")
+	}
+	if len(key.Key) == 0 {
+		return nil, emptyKeyError()
+	}
+	return memGet(b.writes, b.parent.root(), key)
+}
+
+func (b *goInMemBatch) GetProto(
+	key MVCCKey, msg proto.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	if b.distinctOpen {
+		panic("distinct batch open")
+	}
+	return memGetProto(b, key, msg)
+}
+
+func (b *goInMemBatch) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	if b.distinctOpen {
+		panic("distinct batch open")
+	}
+	return memIterate(b, start, end, f)
+}
+
+// NewIterator returns an iterator over the batch and the engine's data at
+// the time of the call. The iterator sees the writes made to the batch after
+// its creation.
+func (b *goInMemBatch) NewIterator(prefix bool) Iterator {
+	if b.distinctOpen {
+		panic("distinct batch open")
+	}
+	return newMemIterator(b, b.parent.root(), func() *memNode {
+		return b.writes
+	})
+}
+
+func (b *goInMemBatch) Commit() error {
+	if b.committed {
+		panic("this batch was already committed")
+	}
+	b.distinctOpen = false
+	if b.builder.count > 0 {
+		if err := b.parent.ApplyBatchRepr(b.builder.Finish()); err != nil {
+			return err
+		}
+	}
+	b.committed = true
+	b.writes = nil
+	return nil
+}
+
+func (b *goInMemBatch) Repr() []byte {
+	return b.builder.getRepr()
+}
+
+func (b *goInMemBatch) Distinct() ReadWriter {
+	if b.distinctOpen {
+		panic("distinct batch already open")
+	}
+	b.distinctOpen = true
+	b.distinct.view = b.writes
+	return &b.distinct
+}
+
+// goInMemDistinctBatch writes to its parent batch, but only reads the writes
+// made to the parent batch before it was opened.
+type goInMemDistinctBatch struct {
+	*goInMemBatch
+	view *memNode
+}
+
+func (d *goInMemDistinctBatch) Close() {
+	if !d.distinctOpen {
+		panic("distinct batch not open")
+	}
+	d.distinctOpen = false
+	d.view = nil
+}
+
+func (d *goInMemDistinctBatch) Put(key MVCCKey, value []byte) error {
+	d.put(key, value)
+	return nil
+}
+
+func (d *goInMemDistinctBatch) Merge(key MVCCKey, value []byte) error {
+	d.merge(key, value)
+	return nil
+}
+
+func (d *goInMemDistinctBatch) Clear(key MVCCKey) error {
+	d.clear(key)
+	return nil
+}
+
+func (d *goInMemDistinctBatch) Get(key MVCCKey) ([]byte, error) {
+	if len(key.Key) == 0 {
+		return nil, emptyKeyError()
+	}
+	return memGet(d.view, d.parent.root(), key)
+}
+
+func (d *goInMemDistinctBatch) GetProto(
+	key MVCCKey, msg proto.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	return memGetProto(d, key, msg)
+}
+
+func (d *goInMemDistinctBatch) Iterate(
+	start, end MVCCKey, f func(MVCCKeyValue) (bool, error),
+) error {
+	return memIterate(d, start, end, f)
+}
+
+func (d *goInMemDistinctBatch) NewIterator(prefix bool) Iterator {
+	view := d.view
+	return newMemIterator(d, d.parent.root(), func() *memNode {
+		return view
+	})
+}
+
+// memIterator iterates over an engine memtable, and optionally the writes of
+// a batch layered on top of it.
+type memIterator struct {
+	reader Reader
+	root   *memNode
+	// writes, if set, returns the batch writes. It's called whenever the
+	// iterator is positioned so that the iterator sees the writes made to
+	// the batch after its creation.
+	writes func() *memNode
+
+	valid bool
+	key   MVCCKey
+	value []byte
+	err   error
+}
+
+func newMemIterator(reader Reader, root *memNode, writes func() *memNode) *memIterator {
+	return &memIterator{
+		reader: reader,
+		root:   root,
+		writes: writes,
+	}
+}
+
+func (it *memIterator) checkReaderOpen() {
+	if it.reader.closed() {
+		panic("iterator used after backing engine closed")
+	}
+}
+
+func (it *memIterator) currentWrites() *memNode {
+	if it.writes == nil {
+		return nil
+	}
+	return it.writes()
+}
+
+// setPosition positions the iterator at w or n, the entries of the batch
+// writes and the engine memtable nearest to the position being sought in the
+// iteration direction. It returns false along with the key of w if w is
+// nearest and deletes its key, in which case the search continues past the
+// key.
+func (it *memIterator) setPosition(w, n *memNode, forward bool) (MVCCKey, bool) {
+	if n != nil && w != nil {
+		c := compareMVCCKeys(n.key, w.key)
+		if !forward {
+			c = -c
+		}
+		if c < 0 {
+			w = nil
+		} else if c > 0 {
+			n = nil
+		}
+	}
+	if w == nil {
+		it.valid = n != nil
+		if it.valid {
+			it.key, it.value = n.key, n.value
+		}
+		return MVCCKey{}, true
+	}
+	value, ok, err := w.resolve(n)
+	if err != nil {
+		it.valid, it.err = false, err
+		return MVCCKey{}, true
+	}
+	if !ok {
+		return w.key, false
+	}
+	it.valid, it.key, it.value = true, w.key, value
+	return MVCCKey{}, true
+}
+
+// seekGE positions the iterator at the first key greater than (or, if
+// inclusive, equal to) key.
+func (it *memIterator) seekGE(key MVCCKey, inclusive bool) {
+	it.checkReaderOpen()
+	it.err = nil
+	writes := it.currentWrites()
+	for {
+		var done bool
+		w, n := writes.ceil(key, inclusive), it.root.ceil(key, inclusive)
+		if key, done = it.setPosition(w, n, true /* forward */); done {
+			return
+		}
+		inclusive = false
+	}
+}
+
+// seekLE positions the iterator at the last key less than (or, if
+// inclusive, equal to) key, or at the last key if last is true.
+func (it *memIterator) seekLE(key MVCCKey, inclusive, last bool) {
+	it.checkReaderOpen()
+	it.err = nil
+	writes := it.currentWrites()
+	for {
+		var done bool
+		var w, n *memNode
+		if last {
+			w, n = writes.last(), it.root.last()
+		} else {
+			w, n = writes.floor(key, inclusive), it.root.floor(key, inclusive)
+		}
+		if key, done = it.setPosition(w, n, false /* !forward */); done {
+			return
+		}
+		inclusive, last = false, false
+	}
+}
+
+func (it *memIterator) Close() {
+	*it = memIterator{}
+}
+
+func (it *memIterator) Seek(key MVCCKey) {
+	it.seekGE(key, true /* inclusive */)
+}
+
+func (it *memIterator) SeekReverse(key MVCCKey) {
+	it.seekLE(key, true /* inclusive */, len(key.Key) == 0 /* last */)
+}
+
+func (it *memIterator) Valid() bool {
+	return it.valid
+}
+
+func (it *memIterator) Next() {
+	if it.valid {
+		it.seekGE(it.key, false /* !inclusive */)
+	}
+}
+
+func (it *memIterator) Prev() {
+	if it.valid {
+		it.seekLE(it.key, false /* !inclusive */, false /* !last */)
+	}
+}
+
+func (it *memIterator) NextKey() {
+	if it.valid {
+		// All of the versions of the key sort before the metadata key of the
+		// next possible key.
+		it.seekGE(MakeMVCCMetadataKey(it.key.Key.Next()), true /* inclusive */)
+	}
+}
+
+func (it *memIterator) PrevKey() {
+	if it.valid {
+		// The metadata key of the key sorts before all of its versions.
+		it.seekLE(MakeMVCCMetadataKey(it.key.Key), false /* !inclusive */, false /* !last */)
+	}
+}
+
+func (it *memIterator) Key() MVCCKey {
+	return copyMVCCKey(it.key)
+}
+
+func (it *memIterator) Value() []byte {
+	return append([]byte{}, it.value...)
+}
+
+func (it *memIterator) ValueProto(msg proto.Message) error {
+	if len(it.value) == 0 {
+		return nil
+	}
+	return proto.Unmarshal(it.value, msg)
+}
+
+func (it *memIterator) unsafeKey() MVCCKey {
+	return it.key
+}
+
+func (it *memIterator) unsafeValue() []byte {
+	return it.value
+}
+
+func (it *memIterator) Error() error {
+	return it.err
+}
+
+func (it *memIterator) Less(key MVCCKey) bool {
+	return it.key.Less(key)
+}
+
+// ageFactor returns the number of seconds between two times, computed the
+// same way as in enginepb.MVCCStats.AgeTo.
+func ageFactor(fromNanos, toNanos int64) int64 {
+	return toNanos/1e9 - fromNanos/1e9
+}
+
+// ComputeStats scans the underlying data from start to end keys and computes
+// stats counters based on the values. It is a port of MVCCComputeStats in
+// rocksdb/db.cc, which computes the stats of RocksDB iterators.
+func (it *memIterator) ComputeStats(
+	start, end MVCCKey, nowNanos int64,
+) (enginepb.MVCCStats, error) {
+	var ms enginepb.MVCCStats
+	var meta enginepb.MVCCMetadata
+	var prevKey roachpb.Key
+	first := false
+
+	for it.Seek(start); it.Valid() && compareMVCCKeys(it.key, end) < 0; it.Next() {
+		key, value := it.key, it.value
+		isSys := isSysLocal(key.Key)
+		isValue := key.IsValue()
+		implicitMeta := isValue && !bytes.Equal(key.Key, prevKey)
+		prevKey = key.Key
+
+		if implicitMeta {
+			// No MVCCMetadata entry for this series of keys.
+			meta.Reset()
+			meta.KeyBytes = mvccVersionTimestampSize
+			meta.ValBytes = int64(len(value))
+			meta.Deleted = len(value) == 0
+			meta.Timestamp.WallTime = key.Timestamp.WallTime
+		}
+
+		if !isValue || implicitMeta {
+			metaKeySize := int64(len(key.Key)) + 1
+			var metaValSize int64
+			if !implicitMeta {
+				metaValSize = int64(len(value))
+			}
+			totalBytes := metaKeySize + metaValSize
+			first = true
+
+			if !implicitMeta {
+				if err := proto.Unmarshal(value, &meta); err != nil {
+					return ms, errors.Wrap(err, "unable to decode MVCCMetadata")
+				}
+			}
+
+			if isSys {
+				ms.SysBytes += totalBytes
+				ms.SysCount++
+			} else {
+				if !meta.Deleted {
+					ms.LiveBytes += totalBytes
+					ms.LiveCount++
+				} else {
+					ms.GCBytesAge += totalBytes * ageFactor(meta.Timestamp.WallTime, nowNanos)
+				}
+				ms.KeyBytes += metaKeySize
+				ms.ValBytes += metaValSize
+				ms.KeyCount++
+				if meta.RawBytes != nil {
+					ms.ValCount++
+				}
+			}
+			if !implicitMeta {
+				continue
+			}
+		}
+
+		totalBytes := int64(len(value)) + mvccVersionTimestampSize
+		if isSys {
+			ms.SysBytes += totalBytes
+			continue
+		}
+		if first {
+			first = false
+			if !meta.Deleted {
+				ms.LiveBytes += totalBytes
+			} else {
+				ms.GCBytesAge += totalBytes * ageFactor(meta.Timestamp.WallTime, nowNanos)
+			}
+			if meta.Txn != nil {
+				ms.IntentBytes += totalBytes
+				ms.IntentCount++
+				ms.IntentAge += ageFactor(meta.Timestamp.WallTime, nowNanos)
+			}
+			if meta.KeyBytes != mvccVersionTimestampSize {
+				return ms, errors.Errorf("expected mvcc metadata key bytes to equal %d; got %d",
+					mvccVersionTimestampSize, meta.KeyBytes)
+			}
+			if meta.ValBytes != int64(len(value)) {
+				return ms, errors.Errorf("expected mvcc metadata val bytes to equal %d; got %d",
+					len(value), meta.ValBytes)
+			}
+		} else {
+			ms.GCBytesAge += totalBytes * ageFactor(key.Timestamp.WallTime, nowNanos)
+		}
+		ms.KeyBytes += mvccVersionTimestampSize
+		ms.ValBytes += int64(len(value))
+		ms.ValCount++
+	}
+	if err := it.Error(); err != nil {
+		return ms, err
+	}
+	ms.LastUpdateNanos = nowNanos
+	return ms, nil
+}
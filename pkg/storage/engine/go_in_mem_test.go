@@ -0,0 +1,196 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+// TestGoInMemMatchesInMem applies random MVCC operations to a GoInMem and an
+// InMem engine and verifies that the engines' contents, iterators and
+// computed stats agree.
+func TestGoInMemMatchesInMem(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	engines := []Engine{
+		NewInMem(roachpb.Attributes{}, testCacheSize),
+		NewGoInMem(roachpb.Attributes{}, testCacheSize),
+	}
+	for _, e := range engines {
+		stopper.AddCloser(e)
+	}
+
+	rng, seed := randutil.NewPseudoRand()
+	t.Logf("using seed %d", seed)
+	ctx := context.Background()
+	testKeys := []roachpb.Key{
+		keys.StoreIdentKey(), testKey1, testKey2, testKey3, testKey4, testKey5, testKey6,
+	}
+	tsKey := roachpb.Key("/ts")
+	tsValues := []roachpb.Value{tsvalue1, tsvalue2}
+
+	ms := make([]enginepb.MVCCStats, len(engines))
+	for i := 0; i < 1000; i++ {
+		key := testKeys[rng.Intn(len(testKeys))]
+		ts := hlc.Timestamp{WallTime: int64(rng.Intn(10)+1) * 1e9, Logical: int32(rng.Intn(2))}
+		var txn *roachpb.Transaction
+		if rng.Intn(4) == 0 {
+			txnCopy := *txn1
+			txnCopy.Timestamp = ts
+			txn = &txnCopy
+		}
+		value := roachpb.MakeValueFromBytes(randutil.RandBytes(rng, rng.Intn(10)))
+		op := rng.Intn(5)
+
+		var errs []error
+		for j, e := range engines {
+			b := e.NewBatch()
+			var err error
+			switch op {
+			case 0, 1:
+				err = MVCCPut(ctx, b, &ms[j], key, ts, value, txn)
+			case 2:
+				err = MVCCDelete(ctx, b, &ms[j], key, ts, txn)
+			case 3:
+				err = MVCCResolveWriteIntent(ctx, b, &ms[j], roachpb.Intent{
+					Span: roachpb.Span{Key: key}, Txn: txn1.TxnMeta, Status: roachpb.COMMITTED,
+				})
+			default:
+				err = MVCCMerge(ctx, b, &ms[j], tsKey, hlc.ZeroTimestamp,
+					tsValues[rng.Intn(len(tsValues))])
+			}
+			if err == nil {
+				err = b.Commit()
+			}
+			b.Close()
+			errs = append(errs, err)
+		}
+		if (errs[0] == nil) != (errs[1] == nil) {
+			t.Fatalf("%d: op %d on %s: InMem returned %v, but GoInMem returned %v",
+				i, op, key, errs[0], errs[1])
+		}
+	}
+
+	var kvs [][]MVCCKeyValue
+	for _, e := range engines {
+		kv, err := Scan(e, mvccKey(roachpb.KeyMin), mvccKey(roachpb.KeyMax), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kvs = append(kvs, kv)
+	}
+	if !reflect.DeepEqual(kvs[0], kvs[1]) {
+		t.Fatalf("InMem contains %v, but GoInMem contains %v", kvs[0], kvs[1])
+	}
+
+	var iters []Iterator
+	for _, e := range engines {
+		iter := e.NewIterator(false)
+		defer iter.Close()
+		iters = append(iters, iter)
+	}
+	var stats []enginepb.MVCCStats
+	for _, iter := range iters {
+		s, err := iter.ComputeStats(mvccKey(roachpb.KeyMin), mvccKey(roachpb.KeyMax), 20*1e9)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats = append(stats, s)
+	}
+	if !reflect.DeepEqual(stats[0], stats[1]) {
+		t.Fatalf("InMem computed %+v, but GoInMem computed %+v", stats[0], stats[1])
+	}
+
+	for i := 0; i < 1000; i++ {
+		op := rng.Intn(6)
+		key := mvccKey(testKeys[rng.Intn(len(testKeys))])
+		for _, iter := range iters {
+			if op > 1 && !iter.Valid() {
+				op = 0
+			}
+			switch op {
+			case 0:
+				iter.Seek(key)
+			case 1:
+				iter.SeekReverse(key)
+			case 2:
+				iter.Next()
+			case 3:
+				iter.Prev()
+			case 4:
+				iter.NextKey()
+			default:
+				iter.PrevKey()
+			}
+		}
+		if iters[0].Valid() != iters[1].Valid() {
+			t.Fatalf("%d: op %d: InMem iterator valid=%t, but GoInMem iterator valid=%t",
+				i, op, iters[0].Valid(), iters[1].Valid())
+		}
+		if !iters[0].Valid() {
+			continue
+		}
+		if k0, k1 := iters[0].Key(), iters[1].Key(); !k0.Equal(k1) {
+			t.Fatalf("%d: op %d: InMem iterator at %s, but GoInMem iterator at %s", i, op, k0, k1)
+		}
+		if v0, v1 := iters[0].Value(), iters[1].Value(); !bytes.Equal(v0, v1) {
+			t.Fatalf("%d: op %d: InMem iterator value %q, but GoInMem iterator value %q",
+				i, op, v0, v1)
+		}
+	}
+}
+
+func TestGoInMemGetStats(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	e := NewGoInMem(roachpb.Attributes{}, testCacheSize)
+	defer e.Close()
+
+	expSize := int64(0)
+	for _, kv := range []MVCCKeyValue{
+		{Key: mvccKey("a"), Value: []byte("value")},
+		{Key: MVCCKey{Key: roachpb.Key("b"), Timestamp: hlc.Timestamp{WallTime: 1}}, Value: []byte("v")},
+	} {
+		if err := e.Put(kv.Key, kv.Value); err != nil {
+			t.Fatal(err)
+		}
+		expSize += int64(kv.Key.EncodedSize() + len(kv.Value))
+	}
+	// Overwriting a key replaces its value's size.
+	if err := e.Put(mvccKey("a"), []byte("val")); err != nil {
+		t.Fatal(err)
+	}
+	expSize -= 2
+
+	stats, err := e.GetStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := (Stats{MemtableTotalSize: expSize}); *stats != exp {
+		t.Fatalf("expected %+v, got %+v", exp, *stats)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package engine
+
+import "math/rand"
+
+// compareMVCCKeys orders MVCC keys the way the RocksDB comparator in
+// rocksdb/db.cc does: by key, then with the metadata key (which has no
+// timestamp) first, followed by the versions of the key from newest to
+// oldest.
+func compareMVCCKeys(a, b MVCCKey) int {
+	if c := a.Key.Compare(b.Key); c != 0 {
+		return c
+	}
+	switch aIsValue, bIsValue := a.IsValue(), b.IsValue(); {
+	case !aIsValue && !bIsValue:
+		return 0
+	case !aIsValue:
+		return -1
+	case !bIsValue:
+		return 1
+	}
+	switch {
+	case a.Timestamp == b.Timestamp:
+		return 0
+	case b.Timestamp.Less(a.Timestamp):
+		return -1
+	default:
+		return 1
+	}
+}
+
+// memEntryKind describes how a memtable entry combines with the value of
+// its key in the layer below it.
+type memEntryKind byte
+
+const (
+	// memEntryValue replaces the value below with its value and operands.
+	memEntryValue memEntryKind = iota
+	// memEntryDeletion deletes the value below. Its operands, if any, are
+	// merged into a missing value.
+	memEntryDeletion
+	// memEntryMerge merges its operands into the value below.
+	memEntryMerge
+)
+
+// A memNode is an entry of a memtable, a persistent treap ordered by MVCC
+// key. Nodes are immutable once they're part of a tree: modifications copy
+// the path to the modified node, so that a tree's root is a consistent
+// snapshot of its contents which can be read without synchronization. The
+// nil *memNode is the empty tree.
+//
+// Engines only store live values, while batches also store deletions and
+// merge operands, to be applied on top of the engine's values.
+type memNode struct {
+	key      MVCCKey
+	value    []byte
+	kind     memEntryKind
+	operands [][]byte
+
+	priority    uint32
+	left, right *memNode
+}
+
+func newMemNode(key MVCCKey, value []byte, kind memEntryKind, operands [][]byte) *memNode {
+	return &memNode{
+		key:      key,
+		value:    value,
+		kind:     kind,
+		operands: operands,
+		priority: rand.Uint32(),
+	}
+}
+
+// get returns the node with the given key, or nil if there is none.
+func (n *memNode) get(key MVCCKey) *memNode {
+	for n != nil {
+		switch c := compareMVCCKeys(key, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// ceil returns the node with the smallest key which is greater than (or, if
+// inclusive, equal to) the given key.
+func (n *memNode) ceil(key MVCCKey, inclusive bool) *memNode {
+	var result *memNode
+	for n != nil {
+		if c := compareMVCCKeys(n.key, key); c > 0 || (c == 0 && inclusive) {
+			result = n
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return result
+}
+
+// floor returns the node with the largest key which is less than (or, if
+// inclusive, equal to) the given key.
+func (n *memNode) floor(key MVCCKey, inclusive bool) *memNode {
+	var result *memNode
+	for n != nil {
+		if c := compareMVCCKeys(n.key, key); c < 0 || (c == 0 && inclusive) {
+			result = n
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return result
+}
+
+// last returns the node with the largest key.
+func (n *memNode) last() *memNode {
+	if n == nil {
+		return nil
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n
+}
+
+// insert returns a tree containing the tree's nodes and e, which replaces
+// the node with the same key, if any. e must not be part of a tree.
+func (n *memNode) insert(e *memNode) *memNode {
+	if n == nil {
+		return e
+	}
+	if e.priority > n.priority {
+		e.left, e.right = n.split(e.key)
+		return e
+	}
+	cp := *n
+	switch c := compareMVCCKeys(e.key, n.key); {
+	case c < 0:
+		cp.left = n.left.insert(e)
+	case c > 0:
+		cp.right = n.right.insert(e)
+	default:
+		e.priority, e.left, e.right = n.priority, n.left, n.right
+		return e
+	}
+	return &cp
+}
+
+// split returns trees containing the nodes with keys less than and greater
+// than the given key. The node with the key, if any, is dropped.
+func (n *memNode) split(key MVCCKey) (*memNode, *memNode) {
+	if n == nil {
+		return nil, nil
+	}
+	cp := *n
+	switch c := compareMVCCKeys(n.key, key); {
+	case c < 0:
+		var r *memNode
+		cp.right, r = n.right.split(key)
+		return &cp, r
+	case c > 0:
+		var l *memNode
+		l, cp.left = n.left.split(key)
+		return l, &cp
+	default:
+		return n.left, n.right
+	}
+}
+
+// remove returns a tree without the node with the given key.
+func (n *memNode) remove(key MVCCKey) *memNode {
+	if n == nil {
+		return nil
+	}
+	cp := *n
+	switch c := compareMVCCKeys(key, n.key); {
+	case c < 0:
+		if cp.left = n.left.remove(key); cp.left == n.left {
+			return n
+		}
+	case c > 0:
+		if cp.right = n.right.remove(key); cp.right == n.right {
+			return n
+		}
+	default:
+		return joinMemNodes(n.left, n.right)
+	}
+	return &cp
+}
+
+// joinMemNodes returns a tree containing the nodes of l and r. The keys of l
+// must all be less than those of r.
+func joinMemNodes(l, r *memNode) *memNode {
+	if l == nil {
+		return r
+	}
+	if r == nil {
+		return l
+	}
+	if l.priority > r.priority {
+		cp := *l
+		cp.right = joinMemNodes(l.right, r)
+		return &cp
+	}
+	cp := *r
+	cp.left = joinMemNodes(l, r.left)
+	return &cp
+}
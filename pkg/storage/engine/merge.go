@@ -17,7 +17,10 @@
 package engine
 
 import (
+	"sort"
+
 	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
@@ -70,3 +73,169 @@ func MergeInternalTimeSeriesData(
 	}
 	return mergedTS, nil
 }
+
+// The functions below port the merge operator in rocksdb/db.cc to Go for
+// use by engines which aren't backed by RocksDB, such as GoInMem. Changes to
+// the merge operator need to be made to both implementations.
+
+// valueHeaderSize is the size of the checksum and tag which prefix the
+// encoded bytes of a roachpb.Value.
+const valueHeaderSize = 5
+
+// fullMerge merges the operands into the existing value, which is nil if
+// the key doesn't exist. It mirrors DBMergeOperator::FullMerge.
+func fullMerge(existing []byte, operands [][]byte) ([]byte, error) {
+	var meta enginepb.MVCCMetadata
+	if existing != nil {
+		if err := proto.Unmarshal(existing, &meta); err != nil {
+			return nil, errors.Wrap(err, "corrupted existing value")
+		}
+	}
+	for _, operand := range operands {
+		var operandMeta enginepb.MVCCMetadata
+		if err := proto.Unmarshal(operand, &operandMeta); err != nil {
+			return nil, errors.Wrap(err, "corrupted operand value")
+		}
+		if err := mergeValues(&meta, operandMeta, true /* full */); err != nil {
+			return nil, err
+		}
+	}
+	return protoutil.Marshal(&meta)
+}
+
+// mergeValues merges right into left. Time series values are only sorted
+// and consolidated by full merges.
+func mergeValues(left *enginepb.MVCCMetadata, right enginepb.MVCCMetadata, full bool) error {
+	if left.RawBytes == nil {
+		left.RawBytes = append([]byte{}, right.RawBytes...)
+		if right.MergeTimestamp != nil {
+			ts := *right.MergeTimestamp
+			left.MergeTimestamp = &ts
+		}
+		if full && isTimeSeriesData(left.RawBytes) {
+			// Like the C++ implementation, a value which fails to parse is
+			// kept as is.
+			if consolidated, err := consolidateTimeSeriesValue(left.RawBytes); err == nil {
+				left.RawBytes = consolidated
+			}
+		}
+		return nil
+	}
+	if right.RawBytes == nil {
+		return errors.New("inconsistent value types for merge (left = bytes, right = ?)")
+	}
+	leftIsTS, rightIsTS := isTimeSeriesData(left.RawBytes), isTimeSeriesData(right.RawBytes)
+	if leftIsTS || rightIsTS {
+		if !leftIsTS || !rightIsTS {
+			return errors.New("inconsistent value types for merging time series data (type(left) != type(right))")
+		}
+		merged, err := mergeTimeSeriesValues(left.RawBytes, right.RawBytes, full)
+		if err != nil {
+			return err
+		}
+		left.RawBytes = merged
+		return nil
+	}
+	if len(right.RawBytes) > valueHeaderSize {
+		left.RawBytes = append(left.RawBytes, right.RawBytes[valueHeaderSize:]...)
+	}
+	return nil
+}
+
+func isTimeSeriesData(rawBytes []byte) bool {
+	return roachpb.Value{RawBytes: rawBytes}.GetTag() == roachpb.ValueType_TIMESERIES
+}
+
+func serializeTimeSeries(ts roachpb.InternalTimeSeriesData) ([]byte, error) {
+	var val roachpb.Value
+	if err := val.SetProto(&ts); err != nil {
+		return nil, err
+	}
+	return val.RawBytes, nil
+}
+
+type samplesByOffset []roachpb.InternalTimeSeriesSample
+
+func (s samplesByOffset) Len() int           { return len(s) }
+func (s samplesByOffset) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s samplesByOffset) Less(i, j int) bool { return s[i].Offset < s[j].Offset }
+
+// mergeTimeSeriesValues merges two values containing InternalTimeSeriesData
+// messages, which must have the same start timestamp and sample duration. A
+// partial merge concatenates the samples, while a full merge sorts them by
+// offset and keeps only the most recently merged sample at each offset. The
+// samples of left are assumed to be sorted.
+func mergeTimeSeriesValues(left, right []byte, full bool) ([]byte, error) {
+	leftTS, err := roachpb.Value{RawBytes: left}.GetTimeseries()
+	if err != nil {
+		return nil, errors.Wrap(err, "left InternalTimeSeriesData could not be parsed from bytes")
+	}
+	rightTS, err := roachpb.Value{RawBytes: right}.GetTimeseries()
+	if err != nil {
+		return nil, errors.Wrap(err, "right InternalTimeSeriesData could not be parsed from bytes")
+	}
+	if leftTS.StartTimestampNanos != rightTS.StartTimestampNanos {
+		return nil, errors.New("TimeSeries merge failed due to mismatched start timestamps")
+	}
+	if leftTS.SampleDurationNanos != rightTS.SampleDurationNanos {
+		return nil, errors.New("TimeSeries merge failed due to mismatched sample durations")
+	}
+
+	if !full {
+		leftTS.Samples = append(leftTS.Samples, rightTS.Samples...)
+		return serializeTimeSeries(leftTS)
+	}
+
+	newTS := roachpb.InternalTimeSeriesData{
+		StartTimestampNanos: leftTS.StartTimestampNanos,
+		SampleDurationNanos: leftTS.SampleDurationNanos,
+	}
+	sort.Stable(samplesByOffset(rightTS.Samples))
+	l, r := leftTS.Samples, rightTS.Samples
+	for len(l) > 0 || len(r) > 0 {
+		// Select the lowest offset from either side.
+		var offset int32
+		switch {
+		case len(l) == 0:
+			offset = r[0].Offset
+		case len(r) == 0:
+			offset = l[0].Offset
+		case l[0].Offset <= r[0].Offset:
+			offset = l[0].Offset
+		default:
+			offset = r[0].Offset
+		}
+		// Only the most recently merged sample at the offset is kept.
+		var sample roachpb.InternalTimeSeriesSample
+		for ; len(l) > 0 && l[0].Offset == offset; l = l[1:] {
+			sample = l[0]
+		}
+		for ; len(r) > 0 && r[0].Offset == offset; r = r[1:] {
+			sample = r[0]
+		}
+		newTS.Samples = append(newTS.Samples, sample)
+	}
+	return serializeTimeSeries(newTS)
+}
+
+// consolidateTimeSeriesValue sorts the samples of a value containing an
+// InternalTimeSeriesData message by offset, keeping only the last of the
+// samples with the same offset.
+func consolidateTimeSeriesValue(val []byte) ([]byte, error) {
+	ts, err := roachpb.Value{RawBytes: val}.GetTimeseries()
+	if err != nil {
+		return nil, errors.Wrap(err, "InternalTimeSeriesData could not be parsed from bytes")
+	}
+	newTS := roachpb.InternalTimeSeriesData{
+		StartTimestampNanos: ts.StartTimestampNanos,
+		SampleDurationNanos: ts.SampleDurationNanos,
+	}
+	sort.Stable(samplesByOffset(ts.Samples))
+	for i, sample := range ts.Samples {
+		if i+1 < len(ts.Samples) && ts.Samples[i+1].Offset == sample.Offset {
+			continue
+		}
+		newTS.Samples = append(newTS.Samples, sample)
+	}
+	return serializeTimeSeries(newTS)
+}
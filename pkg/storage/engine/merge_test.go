@@ -95,10 +95,21 @@ func timeSeriesAsValue(start int64, duration int64, samples ...tsSample) roachpb
 	return v
 }
 
-// TestGoMerge tests the function goMerge but not the integration with
-// the storage engines. For that, see the engine tests.
+// TestGoMerge tests the function goMerge, as well as fullMerge, the Go port
+// of the merge operator, but not the integration with the storage engines.
+// For that, see the engine tests.
 func TestGoMerge(t *testing.T) {
 	defer leaktest.AfterTest(t)()
+	mergeFuncs := []struct {
+		name  string
+		merge func(existing, update []byte) ([]byte, error)
+	}{
+		{"goMerge", goMerge},
+		{"fullMerge", func(existing, update []byte) ([]byte, error) {
+			return fullMerge(existing, [][]byte{update})
+		}},
+	}
+
 	// Let's start with stuff that should go wrong.
 	badCombinations := []struct {
 		existing, update []byte
@@ -140,9 +151,10 @@ func TestGoMerge(t *testing.T) {
 		},
 	}
 	for i, c := range badCombinations {
-		_, err := goMerge(c.existing, c.update)
-		if err == nil {
-			t.Errorf("goMerge: %d: expected error", i)
+		for _, f := range mergeFuncs {
+			if _, err := f.merge(c.existing, c.update); err == nil {
+				t.Errorf("%s: %d: expected error", f.name, i)
+			}
 		}
 	}
 
@@ -160,20 +172,22 @@ func TestGoMerge(t *testing.T) {
 	}
 
 	for i, c := range testCasesAppender {
-		result, err := goMerge(c.existing, c.update)
-		if err != nil {
-			t.Errorf("goMerge error: %d: %v", i, err)
-			continue
-		}
-		var resultV, expectedV enginepb.MVCCMetadata
-		if err := proto.Unmarshal(result, &resultV); err != nil {
-			t.Fatal(err)
-		}
-		if err := proto.Unmarshal(c.expected, &expectedV); err != nil {
-			t.Fatal(err)
-		}
-		if !reflect.DeepEqual(resultV, expectedV) {
-			t.Errorf("goMerge error: %d: want %+v, got %+v", i, expectedV, resultV)
+		for _, f := range mergeFuncs {
+			result, err := f.merge(c.existing, c.update)
+			if err != nil {
+				t.Errorf("%s error: %d: %v", f.name, i, err)
+				continue
+			}
+			var resultV, expectedV enginepb.MVCCMetadata
+			if err := proto.Unmarshal(result, &resultV); err != nil {
+				t.Fatal(err)
+			}
+			if err := proto.Unmarshal(c.expected, &expectedV); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(resultV, expectedV) {
+				t.Errorf("%s error: %d: want %+v, got %+v", f.name, i, expectedV, resultV)
+			}
 		}
 	}
 
@@ -262,19 +276,25 @@ func TestGoMerge(t *testing.T) {
 		expectedTS := unmarshalTimeSeries(t, c.expected)
 		updateTS := unmarshalTimeSeries(t, c.update)
 
-		// Directly test the C++ implementation of merging using goMerge.  goMerge
-		// operates directly on marshalled bytes.
-		result, err := goMerge(c.existing, c.update)
-		if err != nil {
-			t.Errorf("goMerge error on case %d: %s", i, err.Error())
-			continue
-		}
-		resultTS := unmarshalTimeSeries(t, result)
-		if a, e := resultTS, expectedTS; !reflect.DeepEqual(a, e) {
-			t.Errorf("goMerge returned wrong result on case %d: expected %v, returned %v", i, e, a)
+		// Directly test the C++ implementation of merging using goMerge, and
+		// its Go port using fullMerge. Both operate directly on marshalled
+		// bytes.
+		for _, f := range mergeFuncs {
+			result, err := f.merge(c.existing, c.update)
+			if err != nil {
+				t.Errorf("%s error on case %d: %s", f.name, i, err.Error())
+				continue
+			}
+			resultTS := unmarshalTimeSeries(t, result)
+			if a, e := resultTS, expectedTS; !reflect.DeepEqual(a, e) {
+				t.Errorf("%s returned wrong result on case %d: expected %v, returned %v",
+					f.name, i, e, a)
+			}
 		}
 
 		// Test the MergeInternalTimeSeriesData method separately.
+		var resultTS roachpb.InternalTimeSeriesData
+		var err error
 		if c.existing == nil {
 			resultTS, err = MergeInternalTimeSeriesData(updateTS)
 		} else {
@@ -84,11 +84,102 @@ var (
 )
 
 // createTestEngine returns a new in-memory engine with 1MB of storage
-// capacity.
-func createTestEngine() Engine {
+// capacity. TestMVCCGoInMem replaces it to run the MVCC tests against a
+// GoInMem engine.
+var createTestEngine = func() Engine {
 	return NewInMem(roachpb.Attributes{}, 1<<20)
 }
 
+// mvccEngineTests are the tests which create their engine with
+// createTestEngine and don't depend on RocksDB internals (such as
+// TestMVCCTimeSeriesPartialMerge, which forces compactions). New tests of
+// the MVCC layer belong here too.
+var mvccEngineTests = []struct {
+	name string
+	test func(*testing.T)
+}{
+	{"MVCCEmptyKey", TestMVCCEmptyKey},
+	{"MVCCGetNotExist", TestMVCCGetNotExist},
+	{"MVCCPutWithTxn", TestMVCCPutWithTxn},
+	{"MVCCPutWithoutTxn", TestMVCCPutWithoutTxn},
+	{"MVCCPutOutOfOrder", TestMVCCPutOutOfOrder},
+	{"MVCCIncrement", TestMVCCIncrement},
+	{"MVCCIncrementTxn", TestMVCCIncrementTxn},
+	{"MVCCIncrementOldTimestamp", TestMVCCIncrementOldTimestamp},
+	{"MVCCUpdateExistingKey", TestMVCCUpdateExistingKey},
+	{"MVCCUpdateExistingKeyOldVersion", TestMVCCUpdateExistingKeyOldVersion},
+	{"MVCCUpdateExistingKeyInTxn", TestMVCCUpdateExistingKeyInTxn},
+	{"MVCCUpdateExistingKeyDiffTxn", TestMVCCUpdateExistingKeyDiffTxn},
+	{"MVCCGetNoMoreOldVersion", TestMVCCGetNoMoreOldVersion},
+	{"MVCCGetUncertainty", TestMVCCGetUncertainty},
+	{"MVCCGetAndDelete", TestMVCCGetAndDelete},
+	{"MVCCWriteWithOlderTimestampAfterDeletionOfNonexistentKey", TestMVCCWriteWithOlderTimestampAfterDeletionOfNonexistentKey},
+	{"MVCCInlineWithTxn", TestMVCCInlineWithTxn},
+	{"MVCCDeleteMissingKey", TestMVCCDeleteMissingKey},
+	{"MVCCGetAndDeleteInTxn", TestMVCCGetAndDeleteInTxn},
+	{"MVCCGetWriteIntentError", TestMVCCGetWriteIntentError},
+	{"MVCCScanWriteIntentError", TestMVCCScanWriteIntentError},
+	{"MVCCGetInconsistent", TestMVCCGetInconsistent},
+	{"MVCCGetProtoInconsistent", TestMVCCGetProtoInconsistent},
+	{"MVCCScan", TestMVCCScan},
+	{"MVCCScanMaxNum", TestMVCCScanMaxNum},
+	{"MVCCScanWithKeyPrefix", TestMVCCScanWithKeyPrefix},
+	{"MVCCScanInTxn", TestMVCCScanInTxn},
+	{"MVCCScanInconsistent", TestMVCCScanInconsistent},
+	{"MVCCDeleteRange", TestMVCCDeleteRange},
+	{"MVCCDeleteRangeReturnKeys", TestMVCCDeleteRangeReturnKeys},
+	{"MVCCDeleteRangeFailed", TestMVCCDeleteRangeFailed},
+	{"MVCCDeleteRangeConcurrentTxn", TestMVCCDeleteRangeConcurrentTxn},
+	{"MVCCUncommittedDeleteRangeVisible", TestMVCCUncommittedDeleteRangeVisible},
+	{"MVCCDeleteRangeInline", TestMVCCDeleteRangeInline},
+	{"MVCCConditionalPut", TestMVCCConditionalPut},
+	{"MVCCConditionalPutWithTxn", TestMVCCConditionalPutWithTxn},
+	{"MVCCInitPut", TestMVCCInitPut},
+	{"MVCCInitPutWithTxn", TestMVCCInitPutWithTxn},
+	{"MVCCConditionalPutWriteTooOld", TestMVCCConditionalPutWriteTooOld},
+	{"MVCCIncrementWriteTooOld", TestMVCCIncrementWriteTooOld},
+	{"MVCCReverseScan", TestMVCCReverseScan},
+	{"MVCCResolveTxn", TestMVCCResolveTxn},
+	{"MVCCResolveNewerIntent", TestMVCCResolveNewerIntent},
+	{"MVCCResolveIntentTxnTimestampMismatch", TestMVCCResolveIntentTxnTimestampMismatch},
+	{"MVCCConditionalPutOldTimestamp", TestMVCCConditionalPutOldTimestamp},
+	{"MVCCAbortTxn", TestMVCCAbortTxn},
+	{"MVCCAbortTxnWithPreviousVersion", TestMVCCAbortTxnWithPreviousVersion},
+	{"MVCCWriteWithDiffTimestampsAndEpochs", TestMVCCWriteWithDiffTimestampsAndEpochs},
+	{"MVCCReadWithDiffEpochs", TestMVCCReadWithDiffEpochs},
+	{"MVCCReadWithOldEpoch", TestMVCCReadWithOldEpoch},
+	{"MVCCWriteWithSequenceAndBatchIndex", TestMVCCWriteWithSequenceAndBatchIndex},
+	{"MVCCReadWithPushedTimestamp", TestMVCCReadWithPushedTimestamp},
+	{"MVCCResolveWithDiffEpochs", TestMVCCResolveWithDiffEpochs},
+	{"MVCCResolveWithUpdatedTimestamp", TestMVCCResolveWithUpdatedTimestamp},
+	{"MVCCResolveWithPushedTimestamp", TestMVCCResolveWithPushedTimestamp},
+	{"MVCCResolveTxnNoOps", TestMVCCResolveTxnNoOps},
+	{"MVCCResolveTxnRange", TestMVCCResolveTxnRange},
+	{"FindSplitKey", TestFindSplitKey},
+	{"FindValidSplitKeys", TestFindValidSplitKeys},
+	{"FindBalancedSplitKeys", TestFindBalancedSplitKeys},
+	{"MVCCStatsBasic", TestMVCCStatsBasic},
+	{"MVCCStatsWithRandomRuns", TestMVCCStatsWithRandomRuns},
+	{"MVCCGarbageCollect", TestMVCCGarbageCollect},
+	{"MVCCComputeStatsError", TestMVCCComputeStatsError},
+	{"MVCCGarbageCollectNonDeleted", TestMVCCGarbageCollectNonDeleted},
+	{"MVCCGarbageCollectIntent", TestMVCCGarbageCollectIntent},
+	{"ResolveIntentWithLowerEpoch", TestResolveIntentWithLowerEpoch},
+}
+
+// TestMVCCGoInMem runs mvccEngineTests against a GoInMem engine; the tests
+// themselves run against RocksDB.
+func TestMVCCGoInMem(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer func(f func() Engine) { createTestEngine = f }(createTestEngine)
+	createTestEngine = func() Engine {
+		return NewGoInMem(roachpb.Attributes{}, 1<<20)
+	}
+	for _, test := range mvccEngineTests {
+		t.Run(test.name, test.test)
+	}
+}
+
 // makeTxn creates a new transaction using the specified base
 // txn and timestamp.
 func makeTxn(baseTxn roachpb.Transaction, ts hlc.Timestamp) *roachpb.Transaction {
@@ -270,6 +270,7 @@ type RocksDB struct {
 	cache        RocksDBCache       // Shared cache.
 	maxSize      int64              // Used for calculating rebalancing and free space.
 	maxOpenFiles int                // The maximum number of open files this instance will use.
+	encryption   *EncryptionKeys    // The store keys, if the store is encrypted.
 	deallocated  chan struct{}      // Closed when the underlying handle is deallocated.
 }
 
@@ -283,6 +284,21 @@ var _ Engine = &RocksDB{}
 // needed.
 func NewRocksDB(
 	attrs roachpb.Attributes, dir string, cache RocksDBCache, maxSize int64, maxOpenFiles int,
+) (*RocksDB, error) {
+	return NewEncryptedRocksDB(attrs, dir, cache, maxSize, maxOpenFiles, nil)
+}
+
+// NewEncryptedRocksDB is like NewRocksDB, but encrypts the files of the
+// database with the active store key and decrypts existing files with any
+// of the provided keys. Files written before encryption was enabled remain
+// readable.
+func NewEncryptedRocksDB(
+	attrs roachpb.Attributes,
+	dir string,
+	cache RocksDBCache,
+	maxSize int64,
+	maxOpenFiles int,
+	encryption *EncryptionKeys,
 ) (*RocksDB, error) {
 	if dir == "" {
 		panic("dir must be non-empty")
@@ -293,6 +309,7 @@ func NewRocksDB(
 		cache:        cache.ref(),
 		maxSize:      maxSize,
 		maxOpenFiles: maxOpenFiles,
+		encryption:   encryption,
 		deallocated:  make(chan struct{}),
 	}
 	if err := r.open(); err != nil {
@@ -346,6 +363,10 @@ func (r *RocksDB) open() error {
 	blockSize := envutil.EnvOrDefaultBytes("COCKROACH_ROCKSDB_BLOCK_SIZE", defaultBlockSize)
 	walTTL := envutil.EnvOrDefaultDuration("COCKROACH_ROCKSDB_WAL_TTL", 0).Seconds()
 
+	if r.encryption != nil && r.encryption.Active != nil {
+		log.Infof(context.TODO(), "encrypting new files with store key %s", r.encryption.Active.IDString())
+	}
+
 	status := C.DBOpen(&r.rdb, goToCSlice([]byte(r.dir)),
 		C.DBOptions{
 			cache:           r.cache.cache,
@@ -355,6 +376,7 @@ func (r *RocksDB) open() error {
 			logging_enabled: C.bool(log.V(3)),
 			num_cpu:         C.int(runtime.NumCPU()),
 			max_open_files:  C.int(r.maxOpenFiles),
+			encryption_keys: goToCSlice(r.encryption.encode()),
 		})
 	if err := statusToError(status); err != nil {
 		return errors.Errorf("could not open rocksdb instance: %s", err)
@@ -633,6 +655,11 @@ func (r *RocksDB) GetStats() (*Stats, error) {
 		Flushes:                  int64(s.flushes),
 		Compactions:              int64(s.compactions),
 		TableReadersMemEstimate:  int64(s.table_readers_mem_estimate),
+
+		L0FileCount:                    int64(s.l0_file_count),
+		ImmutableMemtableCount:         int64(s.immutable_memtable_count),
+		PendingCompactionBytesEstimate: int64(s.pending_compaction_bytes_estimate),
+		WriteStallMicros:               int64(s.write_stall_micros),
 	}, nil
 }
 
@@ -1521,6 +1548,70 @@ func MakeRocksDBSstFileWriter() RocksDBSstFileWriter {
 	return RocksDBSstFileWriter{C.DBSstFileWriterNew(), 0}
 }
 
+// SSTIngester is implemented by engines which can atomically add sstables
+// that were written outside of the engine.
+type SSTIngester interface {
+	// AuxiliaryDir returns a directory on the engine's filesystem in which
+	// files to be ingested can be written, or the empty string if the
+	// engine doesn't support ingestion.
+	AuxiliaryDir() string
+	// NewSstFileWriter returns a writer for sstables which can be ingested
+	// by the engine.
+	NewSstFileWriter() RocksDBSstFileWriter
+	// IngestExternalFiles atomically adds the sstables at the given paths
+	// to the engine. The key ranges of the files must not overlap with each
+	// other. They may overlap existing data, including deleted keys, which
+	// the keys of the files shadow. If move is true, the files are hard
+	// linked into the engine's directory instead of being copied.
+	IngestExternalFiles(paths []string, move bool) error
+}
+
+var _ SSTIngester = &RocksDB{}
+
+// auxiliaryDirName is the name of the directory within the engine's data
+// directory returned by AuxiliaryDir.
+const auxiliaryDirName = "auxiliary"
+
+// AuxiliaryDir implements the SSTIngester interface. In-memory engines
+// don't have an auxiliary directory.
+func (r *RocksDB) AuxiliaryDir() string {
+	if r.dir == "" {
+		return ""
+	}
+	return filepath.Join(r.dir, auxiliaryDirName)
+}
+
+// Encrypted returns whether the engine was opened with store keys. Data
+// kept outside of the engine's files isn't covered by the encryption.
+func (r *RocksDB) Encrypted() bool {
+	return r.encryption != nil
+}
+
+// NewSstFileWriter implements the SSTIngester interface. Unlike the writers
+// returned by MakeRocksDBSstFileWriter, the sstables are written with the
+// engine's configuration and are encrypted if the engine is.
+func (r *RocksDB) NewSstFileWriter() RocksDBSstFileWriter {
+	return RocksDBSstFileWriter{C.DBEngineNewSstFileWriter(r.rdb), 0}
+}
+
+// IngestExternalFiles implements the SSTIngester interface.
+func (r *RocksDB) IngestExternalFiles(paths []string, move bool) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	cPaths := make([]*C.char, len(paths))
+	for i := range paths {
+		cPaths[i] = C.CString(paths[i])
+	}
+	defer func() {
+		for _, p := range cPaths {
+			C.free(unsafe.Pointer(p))
+		}
+	}()
+	return statusToError(C.DBEngineIngestExternalFiles(
+		r.rdb, &cPaths[0], C.int(len(cPaths)), C.bool(move)))
+}
+
 // Open creates a file at the given path for output of an sstable.
 func (fw *RocksDBSstFileWriter) Open(path string) error {
 	if fw == nil {
@@ -17,6 +17,7 @@
 
 #include <algorithm>
 #include <atomic>
+#include <map>
 #include <stdarg.h>
 #include <google/protobuf/repeated_field.h>
 #include <google/protobuf/stubs/stringprintf.h>
@@ -72,7 +73,7 @@ struct DBEngine {
 };
 
 struct DBImpl : public DBEngine {
-  std::unique_ptr<rocksdb::Env> memenv;
+  std::unique_ptr<rocksdb::Env> env;
   std::unique_ptr<rocksdb::DB> rep_deleter;
   rocksdb::ReadOptions const read_opts;
   std::shared_ptr<rocksdb::Cache> block_cache;
@@ -84,7 +85,7 @@ struct DBImpl : public DBEngine {
   DBImpl(rocksdb::DB* r, rocksdb::Env* m, std::shared_ptr<rocksdb::Cache> bc,
     std::shared_ptr<DBEventListener> event_listener)
       : DBEngine(r),
-        memenv(m),
+        env(m),
         rep_deleter(r),
         block_cache(bc),
         event_listener(event_listener) {
@@ -897,6 +898,503 @@ class DBLogger : public rocksdb::Logger {
   const bool enabled_;
 };
 
+// Encryption at rest.
+//
+// DBEncryptedEnv wraps the Env used for on-disk stores. Every file it
+// writes while an active store key is configured (SSTs, WAL, MANIFEST,
+// etc.) starts with a header of the following layout, which must be kept
+// in sync with storage/engine/encryption.go:
+//
+//   magic (8 bytes) | store key ID (8 bytes) | IV (16 bytes) |
+//   data key wrapped with the store key (32 bytes)
+//
+// The remainder of the file is encrypted with AES-256 in CTR mode using a
+// per-file random data key, which is itself encrypted ("wrapped") with
+// the store key. Files without the header are read as plaintext, which
+// allows encryption to be enabled on an existing store. Rotating the
+// store key only affects newly written files; the old key must be
+// provided for as long as files encrypted with it remain (compactions
+// eventually rewrite all of them).
+//
+// The AES and random number primitives are provided by Go (see
+// rocksdb/encryption.go).
+const char kEncryptionMagic[] = "crdbenc1";
+const size_t kEncryptionMagicSize = 8;
+const size_t kEncryptionKeyIDSize = 8;
+const size_t kEncryptionIVSize = 16;
+const size_t kEncryptionDataKeySize = 32;
+const size_t kEncryptionHeaderSize =
+    kEncryptionMagicSize + kEncryptionKeyIDSize + kEncryptionIVSize + kEncryptionDataKeySize;
+
+std::string ToHex(const std::string& s) {
+  static const char kHex[] = "0123456789abcdef";
+  std::string result;
+  for (size_t i = 0; i < s.size(); i++) {
+    result.push_back(kHex[(s[i] >> 4) & 0xf]);
+    result.push_back(kHex[s[i] & 0xf]);
+  }
+  return result;
+}
+
+// Cipher owns an AES cipher created by Go (see rocksDBNewCipher), which
+// allows the key schedule to be computed once per key rather than on
+// every call to CryptCTR.
+class Cipher {
+ public:
+  Cipher()
+      : handle_(0) {
+  }
+
+  ~Cipher() {
+    if (handle_ != 0) {
+      rocksDBFreeCipher(handle_);
+    }
+  }
+
+  rocksdb::Status Init(const std::string& key) {
+    handle_ = rocksDBNewCipher(const_cast<char*>(key.data()), key.size());
+    if (handle_ == 0) {
+      return rocksdb::Status::InvalidArgument("invalid encryption key");
+    }
+    return rocksdb::Status::OK();
+  }
+
+  // CryptCTR encrypts or decrypts the n bytes at data in place, which
+  // start at the specified offset into the stream with the specified IV.
+  rocksdb::Status CryptCTR(const std::string& iv, uint64_t offset, char* data, size_t n) const {
+    if (n == 0) {
+      return rocksdb::Status::OK();
+    }
+    if (rocksDBCryptCTR(handle_, const_cast<char*>(iv.data()), offset, data, n) != 0) {
+      return rocksdb::Status::Corruption("invalid encryption key");
+    }
+    return rocksdb::Status::OK();
+  }
+
+ private:
+  Cipher(const Cipher&) = delete;
+  Cipher& operator=(const Cipher&) = delete;
+
+  uint64_t handle_;
+};
+
+// NewCipher creates a Cipher for the specified key.
+rocksdb::Status NewCipher(const std::string& key, std::shared_ptr<const Cipher>* result) {
+  std::shared_ptr<Cipher> cipher(new Cipher);
+  rocksdb::Status status = cipher->Init(key);
+  if (status.ok()) {
+    *result = cipher;
+  }
+  return status;
+}
+
+rocksdb::Status RandomBytes(std::string* s, size_t n) {
+  s->resize(n);
+  if (rocksDBRandomBytes(&(*s)[0], n) != 0) {
+    return rocksdb::Status::IOError("unable to generate random bytes");
+  }
+  return rocksdb::Status::OK();
+}
+
+// FileCipher holds the cipher for the data key and the IV of an encrypted
+// file. The cipher is shared by all of the open handles to the file.
+struct FileCipher {
+  std::shared_ptr<const Cipher> cipher;
+  std::string iv;
+
+  rocksdb::Status Crypt(uint64_t offset, char* data, size_t n) const {
+    return cipher->CryptCTR(iv, offset, data, n);
+  }
+};
+
+class EncryptedSequentialFile : public rocksdb::SequentialFile {
+ public:
+  EncryptedSequentialFile(std::unique_ptr<rocksdb::SequentialFile> file,
+                          const FileCipher& cipher)
+      : file_(std::move(file)),
+        cipher_(cipher),
+        offset_(0) {
+  }
+
+  virtual rocksdb::Status Read(size_t n, rocksdb::Slice* result, char* scratch) {
+    rocksdb::Status status = file_->Read(n, result, scratch);
+    if (!status.ok()) {
+      return status;
+    }
+    if (result->data() != scratch) {
+      memmove(scratch, result->data(), result->size());
+      *result = rocksdb::Slice(scratch, result->size());
+    }
+    status = cipher_.Crypt(offset_, scratch, result->size());
+    offset_ += result->size();
+    return status;
+  }
+
+  virtual rocksdb::Status Skip(uint64_t n) {
+    rocksdb::Status status = file_->Skip(n);
+    if (status.ok()) {
+      offset_ += n;
+    }
+    return status;
+  }
+
+  virtual rocksdb::Status InvalidateCache(size_t offset, size_t length) {
+    return file_->InvalidateCache(offset + kEncryptionHeaderSize, length);
+  }
+
+ private:
+  std::unique_ptr<rocksdb::SequentialFile> file_;
+  const FileCipher cipher_;
+  uint64_t offset_;
+};
+
+class EncryptedRandomAccessFile : public rocksdb::RandomAccessFile {
+ public:
+  EncryptedRandomAccessFile(std::unique_ptr<rocksdb::RandomAccessFile> file,
+                            const FileCipher& cipher)
+      : file_(std::move(file)),
+        cipher_(cipher) {
+  }
+
+  virtual rocksdb::Status Read(uint64_t offset, size_t n, rocksdb::Slice* result,
+                               char* scratch) const {
+    rocksdb::Status status = file_->Read(offset + kEncryptionHeaderSize, n, result, scratch);
+    if (!status.ok()) {
+      return status;
+    }
+    if (result->data() != scratch) {
+      memmove(scratch, result->data(), result->size());
+      *result = rocksdb::Slice(scratch, result->size());
+    }
+    return cipher_.Crypt(offset, scratch, result->size());
+  }
+
+  virtual size_t GetUniqueId(char* id, size_t max_size) const {
+    return file_->GetUniqueId(id, max_size);
+  }
+
+  virtual void Hint(AccessPattern pattern) {
+    file_->Hint(pattern);
+  }
+
+  virtual rocksdb::Status InvalidateCache(size_t offset, size_t length) {
+    return file_->InvalidateCache(offset + kEncryptionHeaderSize, length);
+  }
+
+ private:
+  std::unique_ptr<rocksdb::RandomAccessFile> file_;
+  const FileCipher cipher_;
+};
+
+class EncryptedWritableFile : public rocksdb::WritableFile {
+ public:
+  EncryptedWritableFile(std::unique_ptr<rocksdb::WritableFile> file,
+                        const FileCipher& cipher)
+      : file_(std::move(file)),
+        cipher_(cipher),
+        offset_(0) {
+  }
+
+  virtual rocksdb::Status Append(const rocksdb::Slice& data) {
+    std::string buf(data.data(), data.size());
+    rocksdb::Status status = cipher_.Crypt(offset_, &buf[0], buf.size());
+    if (!status.ok()) {
+      return status;
+    }
+    status = file_->Append(buf);
+    if (status.ok()) {
+      offset_ += buf.size();
+    }
+    return status;
+  }
+
+  virtual rocksdb::Status PositionedAppend(const rocksdb::Slice& data, uint64_t offset) {
+    std::string buf(data.data(), data.size());
+    rocksdb::Status status = cipher_.Crypt(offset, &buf[0], buf.size());
+    if (!status.ok()) {
+      return status;
+    }
+    status = file_->PositionedAppend(buf, offset + kEncryptionHeaderSize);
+    if (status.ok()) {
+      offset_ = offset + buf.size();
+    }
+    return status;
+  }
+
+  virtual rocksdb::Status Truncate(uint64_t size) {
+    rocksdb::Status status = file_->Truncate(size + kEncryptionHeaderSize);
+    if (status.ok()) {
+      offset_ = size;
+    }
+    return status;
+  }
+
+  virtual rocksdb::Status Close() { return file_->Close(); }
+  virtual rocksdb::Status Flush() { return file_->Flush(); }
+  virtual rocksdb::Status Sync() { return file_->Sync(); }
+  virtual rocksdb::Status Fsync() { return file_->Fsync(); }
+  virtual bool IsSyncThreadSafe() const { return file_->IsSyncThreadSafe(); }
+
+  virtual uint64_t GetFileSize() {
+    const uint64_t size = file_->GetFileSize();
+    return size < kEncryptionHeaderSize ? 0 : size - kEncryptionHeaderSize;
+  }
+
+ private:
+  std::unique_ptr<rocksdb::WritableFile> file_;
+  const FileCipher cipher_;
+  uint64_t offset_;
+};
+
+class DBEncryptedEnv : public rocksdb::EnvWrapper {
+ public:
+  DBEncryptedEnv(rocksdb::Env* base)
+      : rocksdb::EnvWrapper(base) {
+  }
+
+  // Init parses the store keys passed to DBOpen. Each key is encoded as a
+  // length prefixed ID followed by the length prefixed key, starting with
+  // the active key. An empty active key disables encryption of new files.
+  rocksdb::Status Init(DBSlice encoded) {
+    rocksdb::Slice s = ToSlice(encoded);
+    for (bool active = true; !s.empty(); active = false) {
+      std::string id, key;
+      if (!DecodeLengthPrefixed(&s, &id) || !DecodeLengthPrefixed(&s, &key)) {
+        return rocksdb::Status::InvalidArgument("malformed encryption keys");
+      }
+      if (active) {
+        active_id_ = id;
+      }
+      if (!id.empty()) {
+        rocksdb::Status status = NewCipher(key, &keys_[id]);
+        if (!status.ok()) {
+          return status;
+        }
+      }
+    }
+    return rocksdb::Status::OK();
+  }
+
+  virtual rocksdb::Status NewSequentialFile(const std::string& fname,
+                                            std::unique_ptr<rocksdb::SequentialFile>* result,
+                                            const rocksdb::EnvOptions& options) {
+    bool encrypted;
+    FileCipher cipher;
+    rocksdb::Status status = ReadCipher(fname, &encrypted, &cipher);
+    if (!status.ok()) {
+      return status;
+    }
+    status = target()->NewSequentialFile(fname, result, options);
+    if (!status.ok() || !encrypted) {
+      return status;
+    }
+    status = (*result)->Skip(kEncryptionHeaderSize);
+    if (!status.ok()) {
+      result->reset();
+      return status;
+    }
+    result->reset(new EncryptedSequentialFile(std::move(*result), cipher));
+    return status;
+  }
+
+  virtual rocksdb::Status NewRandomAccessFile(const std::string& fname,
+                                              std::unique_ptr<rocksdb::RandomAccessFile>* result,
+                                              const rocksdb::EnvOptions& options) {
+    bool encrypted;
+    FileCipher cipher;
+    rocksdb::Status status = ReadCipher(fname, &encrypted, &cipher);
+    if (!status.ok()) {
+      return status;
+    }
+    status = target()->NewRandomAccessFile(fname, result, options);
+    if (!status.ok() || !encrypted) {
+      return status;
+    }
+    result->reset(new EncryptedRandomAccessFile(std::move(*result), cipher));
+    return status;
+  }
+
+  virtual rocksdb::Status NewWritableFile(const std::string& fname,
+                                          std::unique_ptr<rocksdb::WritableFile>* result,
+                                          const rocksdb::EnvOptions& options) {
+    rocksdb::Status status = target()->NewWritableFile(fname, result, options);
+    if (!status.ok() || active_id_.empty()) {
+      return status;
+    }
+    std::string header;
+    FileCipher cipher;
+    status = NewHeader(&header, &cipher);
+    if (status.ok()) {
+      status = (*result)->Append(header);
+    }
+    if (!status.ok()) {
+      result->reset();
+      return status;
+    }
+    result->reset(new EncryptedWritableFile(std::move(*result), cipher));
+    return status;
+  }
+
+  virtual rocksdb::Status ReuseWritableFile(const std::string& fname,
+                                            const std::string& old_fname,
+                                            std::unique_ptr<rocksdb::WritableFile>* result,
+                                            const rocksdb::EnvOptions& options) {
+    // Reusing a file in place would reuse its data key and IV for new
+    // contents, so the file is given a fresh header instead.
+    rocksdb::Status status = target()->RenameFile(old_fname, fname);
+    if (!status.ok()) {
+      return status;
+    }
+    return NewWritableFile(fname, result, options);
+  }
+
+  virtual rocksdb::Status GetFileSize(const std::string& fname, uint64_t* size) {
+    rocksdb::Status status = target()->GetFileSize(fname, size);
+    if (!status.ok() || *size < kEncryptionHeaderSize) {
+      return status;
+    }
+    std::string header;
+    status = ReadHeader(fname, &header);
+    if (!status.ok()) {
+      return status;
+    }
+    if (IsEncrypted(header)) {
+      *size -= kEncryptionHeaderSize;
+    }
+    return status;
+  }
+
+  virtual rocksdb::Status GetChildrenFileAttributes(
+      const std::string& dir, std::vector<rocksdb::Env::FileAttributes>* result) {
+    std::vector<std::string> children;
+    rocksdb::Status status = GetChildren(dir, &children);
+    if (!status.ok()) {
+      return status;
+    }
+    result->clear();
+    for (const auto& child : children) {
+      rocksdb::Env::FileAttributes attrs;
+      attrs.name = child;
+      status = GetFileSize(dir + "/" + child, &attrs.size_bytes);
+      if (status.IsNotFound()) {
+        // The file was deleted concurrently.
+        continue;
+      }
+      if (!status.ok()) {
+        return status;
+      }
+      result->push_back(attrs);
+    }
+    return rocksdb::Status::OK();
+  }
+
+ private:
+  static bool DecodeLengthPrefixed(rocksdb::Slice* s, std::string* value) {
+    if (s->empty()) {
+      return false;
+    }
+    const size_t len = static_cast<unsigned char>((*s)[0]);
+    s->remove_prefix(1);
+    if (s->size() < len) {
+      return false;
+    }
+    value->assign(s->data(), len);
+    s->remove_prefix(len);
+    return true;
+  }
+
+  static bool IsEncrypted(const std::string& header) {
+    return header.size() == kEncryptionHeaderSize &&
+        memcmp(header.data(), kEncryptionMagic, kEncryptionMagicSize) == 0;
+  }
+
+  // ReadHeader reads the header of the file, which is shorter than
+  // kEncryptionHeaderSize if the file is.
+  rocksdb::Status ReadHeader(const std::string& fname, std::string* header) {
+    std::unique_ptr<rocksdb::SequentialFile> file;
+    rocksdb::Status status = target()->NewSequentialFile(fname, &file, rocksdb::EnvOptions());
+    if (!status.ok()) {
+      return status;
+    }
+    char scratch[kEncryptionHeaderSize];
+    header->clear();
+    while (header->size() < kEncryptionHeaderSize) {
+      rocksdb::Slice result;
+      status = file->Read(kEncryptionHeaderSize - header->size(), &result, scratch);
+      if (!status.ok()) {
+        return status;
+      }
+      if (result.empty()) {
+        break;
+      }
+      header->append(result.data(), result.size());
+    }
+    return rocksdb::Status::OK();
+  }
+
+  // ReadCipher reads the header of the file and unwraps its data key.
+  rocksdb::Status ReadCipher(const std::string& fname, bool* encrypted, FileCipher* cipher) {
+    std::string header;
+    rocksdb::Status status = ReadHeader(fname, &header);
+    if (!status.ok()) {
+      return status;
+    }
+    *encrypted = IsEncrypted(header);
+    if (!*encrypted) {
+      return status;
+    }
+    size_t pos = kEncryptionMagicSize;
+    const std::string id = header.substr(pos, kEncryptionKeyIDSize);
+    pos += kEncryptionKeyIDSize;
+    cipher->iv = header.substr(pos, kEncryptionIVSize);
+    pos += kEncryptionIVSize;
+    std::string key = header.substr(pos, kEncryptionDataKeySize);
+
+    auto it = keys_.find(id);
+    if (it == keys_.end()) {
+      return rocksdb::Status::InvalidArgument(
+          fname + " is encrypted with unknown store key " + ToHex(id));
+    }
+    status = it->second->CryptCTR(cipher->iv, 0, &key[0], key.size());
+    if (!status.ok()) {
+      return status;
+    }
+    return NewCipher(key, &cipher->cipher);
+  }
+
+  // NewHeader generates a data key and IV for a new file and returns the
+  // header recording them, wrapped with the active store key.
+  rocksdb::Status NewHeader(std::string* header, FileCipher* cipher) {
+    std::string key;
+    rocksdb::Status status = RandomBytes(&key, kEncryptionDataKeySize);
+    if (status.ok()) {
+      status = RandomBytes(&cipher->iv, kEncryptionIVSize);
+    }
+    if (status.ok()) {
+      status = NewCipher(key, &cipher->cipher);
+    }
+    if (!status.ok()) {
+      return status;
+    }
+    std::string wrapped_key = key;
+    status = keys_[active_id_]->CryptCTR(cipher->iv, 0, &wrapped_key[0], wrapped_key.size());
+    if (!status.ok()) {
+      return status;
+    }
+    header->assign(kEncryptionMagic, kEncryptionMagicSize);
+    header->append(active_id_);
+    header->append(cipher->iv);
+    header->append(wrapped_key);
+    assert(header->size() == kEncryptionHeaderSize);
+    return status;
+  }
+
+  // The active store key's ID, or empty if new files are not encrypted.
+  std::string active_id_;
+  // The ciphers for all of the store keys, indexed by ID.
+  std::map<std::string, std::shared_ptr<const Cipher> > keys_;
+};
+
 // Getter defines an interface for retrieving a value from either an
 // iterator or an engine. It is used by ProcessDeltaKey to abstract
 // whether the "base" layer is an iterator or an engine.
@@ -1606,10 +2104,21 @@ DBStatus DBOpen(DBEngine **db, DBSlice dir, DBOptions db_opts) {
   std::shared_ptr<DBEventListener> event_listener(new DBEventListener);
   options.listeners.emplace_back(event_listener);
 
-  std::unique_ptr<rocksdb::Env> memenv;
+  std::unique_ptr<rocksdb::Env> env;
   if (dir.len == 0) {
-    memenv.reset(rocksdb::NewMemEnv(rocksdb::Env::Default()));
-    options.env = memenv.get();
+    env.reset(rocksdb::NewMemEnv(rocksdb::Env::Default()));
+    options.env = env.get();
+  } else {
+    // On-disk stores are always accessed through DBEncryptedEnv, even
+    // without encryption keys, so that opening an encrypted store without
+    // its keys fails cleanly instead of reading garbage.
+    DBEncryptedEnv* encrypted_env = new DBEncryptedEnv(rocksdb::Env::Default());
+    env.reset(encrypted_env);
+    rocksdb::Status status = encrypted_env->Init(db_opts.encryption_keys);
+    if (!status.ok()) {
+      return ToDBStatus(status);
+    }
+    options.env = env.get();
   }
 
   rocksdb::DB *db_ptr;
@@ -1617,7 +2126,7 @@ DBStatus DBOpen(DBEngine **db, DBSlice dir, DBOptions db_opts) {
   if (!status.ok()) {
     return ToDBStatus(status);
   }
-  *db = new DBImpl(db_ptr, memenv.release(), table_options.block_cache, event_listener);
+  *db = new DBImpl(db_ptr, env.release(), table_options.block_cache, event_listener);
   return kSuccess;
 }
 
@@ -1837,6 +2346,15 @@ DBStatus DBImpl::GetStats(DBStatsResult* stats) {
   std::string table_readers_mem_estimate;
   rep->GetProperty("rocksdb.estimate-table-readers-mem", &table_readers_mem_estimate);
 
+  std::string l0_file_count;
+  rep->GetProperty("rocksdb.num-files-at-level0", &l0_file_count);
+
+  std::string immutable_memtable_count;
+  rep->GetProperty("rocksdb.num-immutable-mem-table", &immutable_memtable_count);
+
+  std::string pending_compaction_bytes_estimate;
+  rep->GetProperty("rocksdb.estimate-pending-compaction-bytes", &pending_compaction_bytes_estimate);
+
   stats->block_cache_hits = (int64_t)s->getTickerCount(rocksdb::BLOCK_CACHE_HIT);
   stats->block_cache_misses = (int64_t)s->getTickerCount(rocksdb::BLOCK_CACHE_MISS);
   stats->block_cache_usage = (int64_t)block_cache->GetUsage();
@@ -1851,6 +2369,10 @@ DBStatus DBImpl::GetStats(DBStatsResult* stats) {
   stats->flushes = (int64_t)event_listener->GetFlushes();
   stats->compactions = (int64_t)event_listener->GetCompactions();
   stats->table_readers_mem_estimate = std::stoll(table_readers_mem_estimate);
+  stats->l0_file_count = std::stoll(l0_file_count);
+  stats->immutable_memtable_count = std::stoll(immutable_memtable_count);
+  stats->pending_compaction_bytes_estimate = std::stoll(pending_compaction_bytes_estimate);
+  stats->write_stall_micros = (int64_t)s->getTickerCount(rocksdb::STALL_MICROS);
   return kSuccess;
 }
 
@@ -2136,6 +2658,24 @@ DBStatus DBEngineAddFile(DBEngine* db, DBSlice path) {
   return kSuccess;
 }
 
+DBStatus DBEngineIngestExternalFiles(DBEngine* db, char** paths, int len, bool move_files) {
+  std::vector<std::string> paths_vec;
+  for (int i = 0; i < len; i++) {
+    paths_vec.push_back(paths[i]);
+  }
+  rocksdb::IngestExternalFileOptions options;
+  options.move_files = move_files;
+  // Unlike AddFile, assign the files a sequence number above the existing
+  // data they overlap, e.g. the deletions of the data they replace.
+  options.allow_global_seqno = true;
+  options.snapshot_consistency = true;
+  rocksdb::Status status = db->rep->IngestExternalFile(paths_vec, options);
+  if (!status.ok()) {
+    return ToDBStatus(status);
+  }
+  return kSuccess;
+}
+
 struct DBSstFileWriter {
   std::unique_ptr<rocksdb::Options> options;
   rocksdb::ImmutableCFOptions ioptions;
@@ -2155,6 +2695,14 @@ DBSstFileWriter* DBSstFileWriterNew() {
   return new DBSstFileWriter(options);
 }
 
+DBSstFileWriter* DBEngineNewSstFileWriter(DBEngine* db) {
+  // Use the engine's options so that the sstable is written through the
+  // engine's Env (and is thus encrypted if the engine is) and with the
+  // engine's table configuration.
+  rocksdb::Options* options = new rocksdb::Options(db->rep->GetOptions());
+  return new DBSstFileWriter(options);
+}
+
 DBStatus DBSstFileWriterOpen(DBSstFileWriter* fw, DBSlice path) {
   rocksdb::Status status = fw->rep.Open(ToString(path));
   if (!status.ok()) {
@@ -67,6 +67,9 @@ typedef struct {
   bool logging_enabled;
   int num_cpu;
   int max_open_files;
+  // The store keys used to encrypt the files of the database, encoded as
+  // described in DBEncryptedEnv. May be empty.
+  DBSlice encryption_keys;
 } DBOptions;
 
 // Create a new cache with the specified size.
@@ -211,6 +214,10 @@ typedef struct {
   int64_t flushes;
   int64_t compactions;
   int64_t table_readers_mem_estimate;
+  int64_t l0_file_count;
+  int64_t immutable_memtable_count;
+  int64_t pending_compaction_bytes_estimate;
+  int64_t write_stall_micros;
 } DBStatsResult;
 
 DBStatus DBGetStats(DBEngine* db, DBStatsResult* stats);
@@ -236,11 +243,22 @@ DBString DBGetUserProperties(DBEngine* db);
 // documentation on `AddFile` for the various restrictions on what can be added.
 DBStatus DBEngineAddFile(DBEngine* db, DBSlice path);
 
+// Atomically adds the files at the given paths to a database. The key
+// ranges of the files must not overlap with each other. They may overlap
+// existing data, including deleted keys, which the files' keys shadow. If
+// move_files is true, the files are hard linked into the database
+// directory instead of being copied.
+DBStatus DBEngineIngestExternalFiles(DBEngine* db, char** paths, int len, bool move_files);
+
 typedef struct DBSstFileWriter DBSstFileWriter;
 
 // Creates a new SstFileWriter with the default configuration.
 DBSstFileWriter* DBSstFileWriterNew();
 
+// Creates a new SstFileWriter which writes sstables suitable for ingestion
+// by the given database, using its Env and table configuration.
+DBSstFileWriter* DBEngineNewSstFileWriter(DBEngine* db);
+
 // Opens a file at the given path for output of an sstable.
 DBStatus DBSstFileWriterOpen(DBSstFileWriter* fw, DBSlice path);
 
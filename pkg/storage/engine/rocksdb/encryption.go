@@ -0,0 +1,129 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rocksdb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"unsafe"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// #include <stdint.h>
+import "C"
+
+// The encrypted RocksDB Env (see DBEncryptedEnv in db.cc) calls back into
+// Go for its cryptography rather than linking against a C crypto library.
+
+// maxBufLen is the maximum length of a C buffer converted to a Go slice.
+const maxBufLen = 1<<31 - 1
+
+func cBytes(data *C.char, n C.int) []byte {
+	if n == 0 {
+		return nil
+	}
+	return (*[maxBufLen]byte)(unsafe.Pointer(data))[:n:n]
+}
+
+// ciphers holds the AES ciphers created by rocksDBNewCipher, indexed by
+// the handle returned to C. The key schedule is computed once per key
+// instead of once per call to rocksDBCryptCTR.
+var ciphers struct {
+	syncutil.Mutex
+	lastHandle uint64
+	m          map[uint64]cipher.Block
+}
+
+// rocksDBNewCipher creates an AES cipher for the specified key and returns
+// a handle for use with rocksDBCryptCTR, or 0 if the key is invalid. The
+// handle must be released with rocksDBFreeCipher.
+//
+//export rocksDBNewCipher
+func rocksDBNewCipher(key *C.char, keyLen C.int) C.uint64_t {
+	block, err := aes.NewCipher(cBytes(key, keyLen))
+	if err != nil {
+		return 0
+	}
+	ciphers.Lock()
+	defer ciphers.Unlock()
+	if ciphers.m == nil {
+		ciphers.m = make(map[uint64]cipher.Block)
+	}
+	ciphers.lastHandle++
+	ciphers.m[ciphers.lastHandle] = block
+	return C.uint64_t(ciphers.lastHandle)
+}
+
+// rocksDBFreeCipher releases a cipher created by rocksDBNewCipher.
+//
+//export rocksDBFreeCipher
+func rocksDBFreeCipher(handle C.uint64_t) {
+	ciphers.Lock()
+	delete(ciphers.m, uint64(handle))
+	ciphers.Unlock()
+}
+
+// rocksDBCryptCTR encrypts (or, equivalently, decrypts) the n bytes at
+// data in place using AES in CTR mode with the cipher created by
+// rocksDBNewCipher and the specified 16 byte initial counter block. The
+// data is assumed to start at the specified offset into the stream, which
+// allows files to be read and written at arbitrary positions. The whole
+// buffer is processed in a single call. Returns 0 on success.
+//
+//export rocksDBCryptCTR
+func rocksDBCryptCTR(
+	handle C.uint64_t, iv *C.char, offset C.uint64_t, data *C.char, n C.int,
+) C.int {
+	ciphers.Lock()
+	block, ok := ciphers.m[uint64(handle)]
+	ciphers.Unlock()
+	if !ok {
+		return -1
+	}
+	var counter [aes.BlockSize]byte
+	copy(counter[:], cBytes(iv, aes.BlockSize))
+	addToCounter(&counter, uint64(offset)/aes.BlockSize)
+	stream := cipher.NewCTR(block, counter[:])
+	if skip := uint64(offset) % aes.BlockSize; skip > 0 {
+		var discard [aes.BlockSize]byte
+		stream.XORKeyStream(discard[:skip], discard[:skip])
+	}
+	buf := cBytes(data, n)
+	stream.XORKeyStream(buf, buf)
+	return 0
+}
+
+// addToCounter adds n to the big-endian 128-bit counter block.
+func addToCounter(counter *[aes.BlockSize]byte, n uint64) {
+	carry := n
+	for i := len(counter) - 1; i >= 0 && carry > 0; i-- {
+		sum := uint64(counter[i]) + carry&0xff
+		counter[i] = byte(sum)
+		carry = carry>>8 + sum>>8
+	}
+}
+
+// rocksDBRandomBytes fills the n bytes at buf with cryptographically
+// secure random bytes. Returns 0 on success.
+//
+//export rocksDBRandomBytes
+func rocksDBRandomBytes(buf *C.char, n C.int) C.int {
+	if _, err := rand.Read(cBytes(buf, n)); err != nil {
+		return -1
+	}
+	return 0
+}
@@ -78,6 +78,53 @@ const (
 	gcTaskLimit = 25
 )
 
+// ProtectedTimestampReader is an interface defined in the storage package
+// that can be implemented by the SQL layer to expose the records of the
+// system.protected_ts table. The GC queue doesn't garbage collect the MVCC
+// versions which are visible at a protected timestamp.
+type ProtectedTimestampReader interface {
+	// MinProtectedTimestamp returns the minimum timestamp protected by an
+	// unexpired record overlapping the span, or hlc.ZeroTimestamp if there is
+	// no such record. It's called for every GC of a range, and is expected
+	// to answer from a cache which it refuses to use (returning an error)
+	// once it's too stale.
+	MinProtectedTimestamp(ctx context.Context, span roachpb.Span) (hlc.Timestamp, error)
+}
+
+// checkProtectedTimestamps rejects the GC requests of the batch which would
+// raise the GC threshold of the range to or above its minimum protected
+// timestamp. The GC queue clamps the threshold it computes, but a record may
+// have been written after it looked up the protected timestamp; checking
+// again once the request holds the command queue, which it shares with any
+// read of the range, lets the writer of the record verify the protection by
+// reading the range at the protected timestamp.
+func (r *Replica) checkProtectedTimestamps(
+	ctx context.Context, ba roachpb.BatchRequest,
+) *roachpb.Error {
+	reader := r.store.cfg.ProtectedTimestamps
+	if reader == nil {
+		return nil
+	}
+	for _, union := range ba.Requests {
+		gc, ok := union.GetInner().(*roachpb.GCRequest)
+		if !ok || gc.Threshold == hlc.ZeroTimestamp {
+			continue
+		}
+		desc := r.Desc()
+		span := roachpb.Span{Key: desc.StartKey.AsRawKey(), EndKey: desc.EndKey.AsRawKey()}
+		protected, err := reader.MinProtectedTimestamp(ctx, span)
+		if err != nil {
+			return roachpb.NewError(errors.Wrapf(err,
+				"could not look up protected timestamps for range %s", r))
+		}
+		if protected != hlc.ZeroTimestamp && !gc.Threshold.Less(protected) {
+			return roachpb.NewErrorf("GC threshold %s is not below protected timestamp %s",
+				gc.Threshold, protected)
+		}
+	}
+	return nil
+}
+
 // gcQueue manages a queue of replicas slated to be scanned in their
 // entirety using the MVCC versions iterator. The gc queue manages the
 // following tasks:
@@ -306,7 +353,17 @@ func (gcq *gcQueue) process(
 		return errors.Errorf("could not find zone config for range %s: %s", repl, err)
 	}
 
-	gcKeys, info, err := RunGC(ctx, desc, snap, now, zone.GC,
+	// Look up the minimum protected timestamp of the range. Failing to do
+	// so must not let the GC remove versions which may be protected.
+	var protected hlc.Timestamp
+	if reader := repl.store.cfg.ProtectedTimestamps; reader != nil {
+		span := roachpb.Span{Key: desc.StartKey.AsRawKey(), EndKey: desc.EndKey.AsRawKey()}
+		if protected, err = reader.MinProtectedTimestamp(ctx, span); err != nil {
+			return errors.Wrapf(err, "could not look up protected timestamps for range %s", repl)
+		}
+	}
+
+	gcKeys, info, err := RunGC(ctx, desc, snap, now, zone.GC, protected,
 		func(now hlc.Timestamp, txn *roachpb.Transaction, typ roachpb.PushTxnType) {
 			pushTxn(ctx, gcq.store.DB(), now, txn, typ)
 		},
@@ -379,8 +436,12 @@ type GCInfo struct {
 	ResolveTotal int
 	// ResolveErrors is the number of successful intent resolutions.
 	ResolveSuccess int
-	// Threshold is the computed expiration timestamp. Equal to `Now - Policy`.
+	// Threshold is the computed expiration timestamp. Equal to `Now - Policy`,
+	// unless that would remove versions visible at ProtectedTimestamp.
 	Threshold hlc.Timestamp
+	// ProtectedTimestamp is the minimum protected timestamp of the range, if
+	// any.
+	ProtectedTimestamp hlc.Timestamp
 }
 
 func (info *GCInfo) updateMetrics(metrics *StoreMetrics) {
@@ -408,13 +469,15 @@ type lockableGCInfo struct {
 // Engine (which is not mutated). It uses the provided functions pushTxnFn and
 // resolveIntentsFn to clarify the true status of and clean up after encountered
 // transactions. It returns a slice of gc'able keys from the data, transaction,
-// and abort spans.
+// and abort spans. Versions visible at the protected timestamp, if non-zero,
+// are not garbage collected regardless of the policy.
 func RunGC(
 	ctx context.Context,
 	desc *roachpb.RangeDescriptor,
 	snap engine.Reader,
 	now hlc.Timestamp,
 	policy config.GCPolicy,
+	protected hlc.Timestamp,
 	pushTxnFn pushFunc,
 	resolveIntentsFn resolveFunc,
 ) ([]roachpb.GCRequest_GCKey, GCInfo, error) {
@@ -456,7 +519,13 @@ func RunGC(
 	abortSpanGCThreshold := now.Add(-int64(abortCacheAgeThreshold), 0)
 
 	gc := engine.MakeGarbageCollector(now, policy)
+	// Reads are only served at timestamps above the threshold, so it must
+	// stay below the protected timestamp.
+	if protected != hlc.ZeroTimestamp && !gc.Threshold.Less(protected) {
+		gc.Threshold = protected.Prev()
+	}
 	infoMu.Threshold = gc.Threshold
+	infoMu.ProtectedTimestamp = protected
 	infoMu.TxnSpanGCThreshold = txnExp
 
 	var gcKeys []roachpb.GCRequest_GCKey
@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -331,6 +332,108 @@ func TestGCQueueProcess(t *testing.T) {
 	}
 }
 
+// testProtectedTimestamps is a ProtectedTimestampReader which protects a
+// single span at a fixed timestamp.
+type testProtectedTimestamps struct {
+	span roachpb.Span
+	ts   hlc.Timestamp
+}
+
+func (p testProtectedTimestamps) MinProtectedTimestamp(
+	_ context.Context, span roachpb.Span,
+) (hlc.Timestamp, error) {
+	if span.Key.Compare(p.span.EndKey) < 0 && p.span.Key.Compare(span.EndKey) < 0 {
+		return p.ts, nil
+	}
+	return hlc.ZeroTimestamp, nil
+}
+
+// TestGCQueueProtectedTimestamp verifies that the GC queue keeps the
+// versions visible at the minimum protected timestamp of a range, and that
+// the range's GC threshold stays below it.
+func TestGCQueueProtectedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tc := testContext{}
+	tc.manualClock = hlc.NewManualClock(123)
+	cfg := TestStoreConfig(hlc.NewClock(tc.manualClock.UnixNano, time.Nanosecond))
+	protected := &testProtectedTimestamps{
+		span: roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")},
+	}
+	cfg.ProtectedTimestamps = protected
+	tc.StartWithStoreConfig(t, cfg)
+	defer tc.Stop()
+
+	tc.manualClock.Increment(48 * 60 * 60 * 1E9) // 2d past the epoch
+	now := tc.Clock().Now().WallTime
+
+	ts1 := makeTS(now-2*24*60*60*1E9+1, 0) // 2d old
+	ts2 := makeTS(now-25*60*60*1E9, 0)     // 25h old
+	ts3 := makeTS(now-1E9, 0)              // 1s old
+	key := roachpb.Key("a")
+	for _, ts := range []hlc.Timestamp{ts1, ts2, ts3} {
+		pArgs := putArgs(key, []byte("value"))
+		if _, err := tc.SendWrappedWith(roachpb.Header{Timestamp: ts}, &pArgs); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sysCfg, ok := tc.gossip.GetSystemConfig()
+	if !ok {
+		t.Fatal("config not set")
+	}
+	gcQ := newGCQueue(tc.store, tc.gossip)
+
+	testCases := []struct {
+		protected hlc.Timestamp
+		expTS     []hlc.Timestamp
+	}{
+		// While ts1 is protected, the version written at ts1 remains visible.
+		{ts1, []hlc.Timestamp{ts3, ts2, ts1}},
+		// Without protection, the version written at ts1 is garbage collected.
+		{hlc.ZeroTimestamp, []hlc.Timestamp{ts3, ts2}},
+	}
+	for i, c := range testCases {
+		protected.ts = c.protected
+		if err := gcQ.process(context.Background(), tc.Clock().Now(), tc.repl, sysCfg); err != nil {
+			t.Fatal(err)
+		}
+		kvs, err := engine.Scan(tc.store.Engine(), engine.MakeMVCCMetadataKey(key),
+			engine.MakeMVCCMetadataKey(key.Next()), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actTS []hlc.Timestamp
+		for _, kv := range kvs {
+			actTS = append(actTS, kv.Key.Timestamp)
+		}
+		if !reflect.DeepEqual(actTS, c.expTS) {
+			t.Errorf("%d: expected versions at %s, got %s", i, c.expTS, actTS)
+		}
+
+		tc.repl.mu.Lock()
+		threshold := tc.repl.mu.state.GCThreshold
+		tc.repl.mu.Unlock()
+		if c.protected != hlc.ZeroTimestamp && !threshold.Less(c.protected) {
+			t.Errorf("%d: expected GC threshold %s below protected timestamp %s",
+				i, threshold, c.protected)
+		}
+	}
+
+	// A GC request which was computed before a timestamp was protected is
+	// rejected once it would raise the GC threshold to or above it.
+	protected.ts = ts3
+	desc := tc.repl.Desc()
+	gcr := gcArgs(desc.StartKey, desc.EndKey)
+	gcr.Threshold = ts3
+	if _, pErr := tc.SendWrapped(&gcr); !testutils.IsPError(pErr, "is not below protected timestamp") {
+		t.Fatalf("expected GC request to be rejected, got %v", pErr)
+	}
+	gcr.Threshold = ts3.Prev()
+	if _, pErr := tc.SendWrapped(&gcr); pErr != nil {
+		t.Fatal(pErr)
+	}
+}
+
 func TestGCQueueTransactionTable(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -29,6 +29,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
@@ -93,6 +94,12 @@ func (s *Store) ForceRaftLogScanAndProcess() {
 	forceScanAndProcess(s, s.raftLogQueue.baseQueue)
 }
 
+// ForceSplitScanAndProcess iterates over all ranges and enqueues any that
+// need to be split, then processes the split queue.
+func (s *Store) ForceSplitScanAndProcess() {
+	forceScanAndProcess(s, s.splitQueue.baseQueue)
+}
+
 // ForceTimeSeriesMaintenanceQueueProcess iterates over all ranges, enqueuing
 // any that need time series maintenance, then processes the time series
 // maintenance queue.
@@ -228,3 +235,35 @@ func (nl *NodeLiveness) StopHeartbeat() {
 func ProposerEvaluatedKVEnabled() bool {
 	return propEvalKV
 }
+
+// TestingSetRangeFeedPollInterval sets the interval at which RangeFeeds
+// poll, returning a function which restores it.
+func TestingSetRangeFeedPollInterval(d time.Duration) func() {
+	return settings.TestingSetDuration(&rangeFeedPollInterval, d)
+}
+
+// TestingSetProposalQuota sets the size of the proposal quota pool created
+// by replicas which become the Raft leader, returning a function which
+// restores it.
+func TestingSetProposalQuota(n int64) func() {
+	return settings.TestingSetByteSize(&proposalQuotaBytes, n)
+}
+
+// ProposalQuota returns the proposal quota available on the replica, and
+// whether it has a quota pool at all, which it only does while it is the
+// Raft leader.
+func (r *Replica) ProposalQuota() (int64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mu.proposalQuota == nil {
+		return 0, false
+	}
+	return r.mu.proposalQuota.approximateQuota(), true
+}
+
+// TestingSetBackpressureRangeSizeMultiplier sets the multiple of a range's
+// maximum size above which writes to it are backpressured, returning a
+// function which restores it.
+func TestingSetBackpressureRangeSizeMultiplier(v float64) func() {
+	return settings.TestingSetFloat(&backpressureRangeSizeMultiplier, v)
+}
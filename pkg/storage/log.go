@@ -19,6 +19,7 @@ package storage
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -190,6 +191,59 @@ func (s *Store) logChange(
 	})
 }
 
+// eventLogRemoveInconsistentReplica is the system.eventlog event type of
+// sql.EventLogRemoveInconsistentReplica, which can't be used here since the
+// sql package depends on this one.
+const eventLogRemoveInconsistentReplica = "remove_inconsistent_replica"
+
+// logRemoveInconsistentReplica records the removal of a replica which failed
+// a consistency check into the event table. Unlike the other range events,
+// it is recorded in system.eventlog rather than system.rangelog, since it
+// calls for the attention of operators.
+func (s *Store) logRemoveInconsistentReplica(
+	txn *client.Txn,
+	replica roachpb.ReplicaDescriptor,
+	checksum, expectedChecksum []byte,
+	desc roachpb.RangeDescriptor,
+) error {
+	if !s.cfg.LogRangeEvents {
+		return nil
+	}
+	info := struct {
+		RemovedReplica   roachpb.ReplicaDescriptor
+		Checksum         string
+		ExpectedChecksum string
+		UpdatedDesc      roachpb.RangeDescriptor
+	}{replica, fmt.Sprintf("%x", checksum), fmt.Sprintf("%x", expectedChecksum), desc}
+	infoBytes, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	const insertEventTableStmt = `
+INSERT INTO system.eventlog (
+  timestamp, eventType, targetID, reportingID, info
+)
+VALUES(
+  $1, $2, $3, $4, $5
+)
+`
+	rows, err := s.cfg.SQLExecutor.ExecuteStatementInTransaction("log-event", txn, insertEventTableStmt,
+		selectEventTimestamp(s, txn.Proto.Timestamp),
+		eventLogRemoveInconsistentReplica,
+		int32(desc.RangeID),
+		int32(s.Ident.NodeID),
+		string(infoBytes),
+	)
+	if err != nil {
+		return err
+	}
+	if rows != 1 {
+		return errors.Errorf("%d rows affected by log insertion; expected exactly one row affected.", rows)
+	}
+	return nil
+}
+
 // selectEventTimestamp selects a timestamp for this log message. If the
 // transaction this event is being written in has a non-zero timestamp, then that
 // timestamp should be used; otherwise, the store's physical clock is used.
@@ -106,6 +106,36 @@ var (
 		Name: "rocksdb.num-sstables",
 		Help: "Number of rocksdb SSTables",
 	}
+	metaRdbL0FileCount = metric.Metadata{
+		Name: "rocksdb.l0-sstables",
+		Help: "Number of rocksdb SSTables in level 0",
+	}
+	metaRdbImmutableMemtableCount = metric.Metadata{
+		Name: "rocksdb.immutable-memtables",
+		Help: "Number of rocksdb memtables waiting to be flushed",
+	}
+	metaRdbPendingCompactionBytes = metric.Metadata{
+		Name: "rocksdb.estimated-pending-compaction",
+		Help: "Estimated number of bytes rocksdb needs to compact",
+	}
+	metaRdbWriteStallMicros = metric.Metadata{
+		Name: "rocksdb.write-stall-micros",
+		Help: "Microseconds rocksdb stalled or slowed down writes",
+	}
+
+	// Admission control metrics.
+	metaAdmissionOverloadLevel = metric.Metadata{
+		Name: "admission.overload-level",
+		Help: "Overload level of the store's engine (0: none, 1: low priority work is throttled, 2: normal priority work is throttled)",
+	}
+	metaAdmissionQueue = metric.Metadata{
+		Name: "admission.queue",
+		Help: "Number of requests throttled while the store's engine is overloaded",
+	}
+	metaAdmissionQueueNanos = metric.Metadata{
+		Name: "admission.queue-nanos",
+		Help: "Nanoseconds requests spent throttled while the store's engine was overloaded",
+	}
 
 	// Range event metrics.
 	metaRangeSplits                     = metric.Metadata{Name: "range.splits"}
@@ -114,6 +144,34 @@ var (
 	metaRangeSnapshotsGenerated         = metric.Metadata{Name: "range.snapshots.generated"}
 	metaRangeSnapshotsNormalApplied     = metric.Metadata{Name: "range.snapshots.normal-applied"}
 	metaRangeSnapshotsPreemptiveApplied = metric.Metadata{Name: "range.snapshots.preemptive-applied"}
+	metaRangeSnapshotsIngested          = metric.Metadata{
+		Name: "range.snapshots.ingested",
+		Help: "Number of snapshots applied by ingesting sstables",
+	}
+	metaRangeSnapshotSentBytes = metric.Metadata{
+		Name: "range.snapshots.sent-bytes",
+		Help: "Number of snapshot bytes sent",
+	}
+	metaRangeSnapshotRcvdBytes = metric.Metadata{
+		Name: "range.snapshots.rcvd-bytes",
+		Help: "Number of snapshot bytes received",
+	}
+	metaRangeSnapshotSendQueue = metric.Metadata{
+		Name: "range.snapshots.send-queue",
+		Help: "Number of snapshots waiting for the send rate limit",
+	}
+	metaRangeSnapshotRcvdQueue = metric.Metadata{
+		Name: "range.snapshots.rcvd-queue",
+		Help: "Number of snapshots waiting for the receive rate limit",
+	}
+	metaRangeSnapshotSendQueueNanos = metric.Metadata{
+		Name: "range.snapshots.send-queue-nanos",
+		Help: "Nanoseconds spent waiting for the snapshot send rate limit",
+	}
+	metaRangeSnapshotRcvdQueueNanos = metric.Metadata{
+		Name: "range.snapshots.rcvd-queue-nanos",
+		Help: "Nanoseconds spent waiting for the snapshot receive rate limit",
+	}
 
 	// Raft processing metrics.
 	metaRaftTicks = metric.Metadata{
@@ -360,6 +418,15 @@ type StoreMetrics struct {
 	RdbTableReadersMemEstimate  *metric.Gauge
 	RdbReadAmplification        *metric.Gauge
 	RdbNumSSTables              *metric.Gauge
+	RdbL0FileCount              *metric.Gauge
+	RdbImmutableMemtableCount   *metric.Gauge
+	RdbPendingCompactionBytes   *metric.Gauge
+	RdbWriteStallMicros         *metric.Gauge
+
+	// Admission control metrics.
+	AdmissionOverloadLevel *metric.Gauge
+	AdmissionQueue         *metric.Gauge
+	AdmissionQueueNanos    *metric.Counter
 
 	// TODO(mrtracy): This should be removed as part of #4465. This is only
 	// maintained to keep the current structure of StatusSummaries; it would be
@@ -373,6 +440,15 @@ type StoreMetrics struct {
 	RangeSnapshotsGenerated         *metric.Counter
 	RangeSnapshotsNormalApplied     *metric.Counter
 	RangeSnapshotsPreemptiveApplied *metric.Counter
+	RangeSnapshotsIngested          *metric.Counter
+
+	// Snapshot transfer metrics.
+	RangeSnapshotSentBytes      *metric.Counter
+	RangeSnapshotRcvdBytes      *metric.Counter
+	RangeSnapshotSendQueue      *metric.Gauge
+	RangeSnapshotRcvdQueue      *metric.Gauge
+	RangeSnapshotSendQueueNanos *metric.Counter
+	RangeSnapshotRcvdQueueNanos *metric.Counter
 
 	// Raft processing metrics.
 	RaftTicks                *metric.Counter
@@ -532,6 +608,15 @@ func newStoreMetrics(sampleInterval time.Duration) *StoreMetrics {
 		RdbTableReadersMemEstimate:  metric.NewGauge(metaRdbTableReadersMemEstimate),
 		RdbReadAmplification:        metric.NewGauge(metaRdbReadAmplification),
 		RdbNumSSTables:              metric.NewGauge(metaRdbNumSSTables),
+		RdbL0FileCount:              metric.NewGauge(metaRdbL0FileCount),
+		RdbImmutableMemtableCount:   metric.NewGauge(metaRdbImmutableMemtableCount),
+		RdbPendingCompactionBytes:   metric.NewGauge(metaRdbPendingCompactionBytes),
+		RdbWriteStallMicros:         metric.NewGauge(metaRdbWriteStallMicros),
+
+		// Admission control metrics.
+		AdmissionOverloadLevel: metric.NewGauge(metaAdmissionOverloadLevel),
+		AdmissionQueue:         metric.NewGauge(metaAdmissionQueue),
+		AdmissionQueueNanos:    metric.NewCounter(metaAdmissionQueueNanos),
 
 		// Range event metrics.
 		RangeSplits:                     metric.NewCounter(metaRangeSplits),
@@ -540,6 +625,15 @@ func newStoreMetrics(sampleInterval time.Duration) *StoreMetrics {
 		RangeSnapshotsGenerated:         metric.NewCounter(metaRangeSnapshotsGenerated),
 		RangeSnapshotsNormalApplied:     metric.NewCounter(metaRangeSnapshotsNormalApplied),
 		RangeSnapshotsPreemptiveApplied: metric.NewCounter(metaRangeSnapshotsPreemptiveApplied),
+		RangeSnapshotsIngested:          metric.NewCounter(metaRangeSnapshotsIngested),
+
+		// Snapshot transfer metrics.
+		RangeSnapshotSentBytes:      metric.NewCounter(metaRangeSnapshotSentBytes),
+		RangeSnapshotRcvdBytes:      metric.NewCounter(metaRangeSnapshotRcvdBytes),
+		RangeSnapshotSendQueue:      metric.NewGauge(metaRangeSnapshotSendQueue),
+		RangeSnapshotRcvdQueue:      metric.NewGauge(metaRangeSnapshotRcvdQueue),
+		RangeSnapshotSendQueueNanos: metric.NewCounter(metaRangeSnapshotSendQueueNanos),
+		RangeSnapshotRcvdQueueNanos: metric.NewCounter(metaRangeSnapshotRcvdQueueNanos),
 
 		// Raft processing metrics.
 		RaftTicks:                metric.NewCounter(metaRaftTicks),
@@ -713,6 +807,10 @@ func (sm *StoreMetrics) updateRocksDBStats(stats engine.Stats) {
 	sm.RdbFlushes.Update(stats.Flushes)
 	sm.RdbCompactions.Update(stats.Compactions)
 	sm.RdbTableReadersMemEstimate.Update(stats.TableReadersMemEstimate)
+	sm.RdbL0FileCount.Update(stats.L0FileCount)
+	sm.RdbImmutableMemtableCount.Update(stats.ImmutableMemtableCount)
+	sm.RdbPendingCompactionBytes.Update(stats.PendingCompactionBytesEstimate)
+	sm.RdbWriteStallMicros.Update(stats.WriteStallMicros)
 }
 
 func (sm *StoreMetrics) leaseRequestComplete(success bool) {
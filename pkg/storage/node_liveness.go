@@ -262,6 +262,69 @@ func (nl *NodeLiveness) IncrementEpoch(ctx context.Context, nodeID roachpb.NodeI
 	return nil
 }
 
+// SetDecommissioning runs a conditional put on the liveness record of
+// the specified node, setting its decommissioning flag to the supplied
+// value. Replicas are moved off of decommissioning nodes by the
+// replicate queue, and decommissioning nodes are not considered as
+// targets for new replicas.
+func (nl *NodeLiveness) SetDecommissioning(
+	ctx context.Context, nodeID roachpb.NodeID, decommission bool,
+) error {
+	liveness, err := nl.GetLiveness(nodeID)
+	if err != nil {
+		return err
+	}
+	oldLiveness := liveness
+	newLiveness := liveness
+
+	// Retry in the event the conditional put fails, which happens if the
+	// node heartbeats its liveness record concurrently.
+	for r := retry.StartWithCtx(ctx, base.DefaultRetryOptions()); r.Next(); {
+		newLiveness.Decommissioning = decommission
+		tryAgain := false
+		if err := nl.updateLiveness(ctx, nodeID, &newLiveness, &oldLiveness, func(actual Liveness) {
+			oldLiveness = actual
+			newLiveness = actual
+			tryAgain = true
+		}); err != nil {
+			return err
+		}
+		if !tryAgain {
+			break
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	log.Infof(ctx, "set node %d liveness decommissioning to %t", nodeID, decommission)
+	nl.mu.Lock()
+	defer nl.mu.Unlock()
+	if nodeID == nl.mu.self.NodeID {
+		nl.mu.self = newLiveness
+	} else {
+		nl.mu.nodes[nodeID] = newLiveness
+	}
+	return nil
+}
+
+// GetLivenesses returns a slice containing the liveness records of all
+// nodes known to this node, including its own.
+func (nl *NodeLiveness) GetLivenesses() []Liveness {
+	nl.mu.Lock()
+	defer nl.mu.Unlock()
+	livenesses := make([]Liveness, 0, len(nl.mu.nodes)+1)
+	if nl.mu.self.NodeID != 0 {
+		livenesses = append(livenesses, nl.mu.self)
+	}
+	for nodeID, l := range nl.mu.nodes {
+		if nodeID != nl.mu.self.NodeID {
+			livenesses = append(livenesses, l)
+		}
+	}
+	return livenesses
+}
+
 // Metrics returns a struct which contains metrics related to node
 // liveness activity.
 func (nl *NodeLiveness) Metrics() LivenessMetrics {
@@ -330,11 +393,15 @@ func (nl *NodeLiveness) livenessGossipUpdate(key string, content roachpb.Value)
 
 	// If there's an existing liveness record, only update the received
 	// timestamp if this is our first receipt of this node's liveness
-	// or if the expiration or epoch was advanced.
+	// or if the expiration or epoch was advanced. A change of the
+	// decommissioning flag is accepted as long as the update isn't
+	// older than the existing record.
 	nl.mu.Lock()
 	defer nl.mu.Unlock()
 	exLiveness, ok := nl.mu.nodes[liveness.NodeID]
-	if !ok || exLiveness.Expiration.Less(liveness.Expiration) || exLiveness.Epoch < liveness.Epoch {
+	if !ok || exLiveness.Expiration.Less(liveness.Expiration) || exLiveness.Epoch < liveness.Epoch ||
+		(exLiveness.Decommissioning != liveness.Decommissioning &&
+			exLiveness.Epoch == liveness.Epoch && exLiveness.Expiration == liveness.Expiration) {
 		nl.mu.nodes[liveness.NodeID] = liveness
 	}
 }
@@ -151,6 +151,40 @@ func TestNodeLivenessEpochIncrement(t *testing.T) {
 	}
 }
 
+// TestNodeLivenessDecommission verifies that the decommissioning flag can
+// be set on a remote node's liveness record and survives its heartbeats.
+func TestNodeLivenessDecommission(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	mtc := startMultiTestContext(t, 2)
+	defer mtc.Stop()
+
+	verifyLiveness(t, mtc)
+
+	nodeID := mtc.gossips[1].NodeID.Get()
+	for _, decommission := range []bool{true, false} {
+		if err := mtc.nodeLivenesses[0].SetDecommissioning(
+			context.Background(), nodeID, decommission); err != nil {
+			t.Fatal(err)
+		}
+		// Heartbeat the decommissioned node, which must not reset the flag.
+		if err := mtc.nodeLivenesses[1].ManualHeartbeat(); err != nil {
+			t.Fatal(err)
+		}
+		for i, nl := range mtc.nodeLivenesses {
+			util.SucceedsSoon(t, func() error {
+				liveness, err := nl.GetLiveness(nodeID)
+				if err != nil {
+					return err
+				}
+				if liveness.Decommissioning != decommission {
+					return errors.Errorf("node %d: expected decommissioning=%t, got %+v", i, decommission, liveness)
+				}
+				return nil
+			})
+		}
+	}
+}
+
 // TestNodeLivenessRestart verifies that if nodes are shutdown and
 // restarted, the node liveness records are re-gossiped immediately.
 func TestNodeLivenessRestart(t *testing.T) {
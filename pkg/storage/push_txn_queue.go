@@ -0,0 +1,314 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// EnablePushTxnQueue controls whether pushes which fail to push their
+// pushee wait in the push txn queue instead of returning an error to
+// the pusher. Exported for testing.
+//
+// The queue is opt-in for now, for two reasons. Nodes running an older
+// version don't report the transactions waiting on a pushee in response
+// to a PUSH_QUERY, so in a mixed-version cluster a dependency cycle
+// involving them isn't detected and the pushers wait until a heartbeat
+// expires instead of backing off. And while enabled, a push which can't
+// win against a live pushee no longer fails with a TransactionPushError
+// but blocks until the pushee finishes or expires, which existing callers
+// of PushTxn don't expect yet (see TestPushTxnQueueDisabledByDefault).
+var EnablePushTxnQueue = settings.RegisterBoolSetting(
+	"kv.transaction.push_txn_queue.enabled",
+	"set to true to queue failed pushes until the pushee's transaction changes and break "+
+		"deadlocks through cycle detection; only enable once all nodes run a version "+
+		"which reports waiting transactions",
+	false,
+)
+
+// pushTxnQueueQueryInterval is the interval at which a waiting pusher
+// checks whether its pushee has expired and, if transactional, queries
+// its own transaction record to learn whether it has been aborted and
+// which transactions are waiting on it.
+var pushTxnQueueQueryInterval = 250 * time.Millisecond
+
+// waitingPush is a PushTxn request which failed to push its pushee and
+// is waiting in the push txn queue for the pushee's transaction record
+// to change.
+type waitingPush struct {
+	req *roachpb.PushTxnRequest
+	// pending receives the updated pushee transaction when its record is
+	// updated, or nil if the queue is cleared.
+	pending chan *roachpb.Transaction
+	mu      struct {
+		syncutil.Mutex
+		// dependents is the set of transactions which are waiting,
+		// directly or transitively, on the pusher.
+		dependents map[uuid.UUID]struct{}
+	}
+}
+
+// pushTxnQueue enqueues PushTxn requests which are waiting on extant
+// transactions with conflicting intents to abort or commit. The queue
+// is maintained by the replica which holds the range lease for the
+// range containing the pushee's transaction record.
+//
+// Waiting pushes are notified immediately when the pushee's
+// transaction record is updated on this replica, at which point the
+// push is retried. While waiting, transactional pushers periodically
+// query their own transaction record, which returns the set of
+// transactions waiting on them. These dependents are accumulated
+// transitively: a query for a transaction returns the pushers waiting
+// on it along with each of their dependents. If the pushee turns up in
+// the pusher's dependents, the transactions form a dependency cycle,
+// which is broken by forcing the push of the transaction which would
+// lose a conflict on priority.
+type pushTxnQueue struct {
+	store *Store
+	mu    struct {
+		syncutil.Mutex
+		txns map[uuid.UUID][]*waitingPush
+	}
+}
+
+func newPushTxnQueue(store *Store) *pushTxnQueue {
+	ptq := &pushTxnQueue{store: store}
+	ptq.mu.txns = map[uuid.UUID][]*waitingPush{}
+	return ptq
+}
+
+// Clear removes all waiting pushes from the queue, signaling each to
+// retry its push. This is called when the replica loses the range
+// lease; the retried pushes are redirected to the new lease holder.
+func (ptq *pushTxnQueue) Clear() {
+	ptq.mu.Lock()
+	txns := ptq.mu.txns
+	ptq.mu.txns = map[uuid.UUID][]*waitingPush{}
+	ptq.mu.Unlock()
+
+	for _, waiting := range txns {
+		for _, w := range waiting {
+			w.signal(nil)
+		}
+	}
+}
+
+// UpdateTxn is invoked when the transaction record of the supplied
+// transaction is updated on this replica. All pushes waiting on the
+// transaction are signaled to retry.
+func (ptq *pushTxnQueue) UpdateTxn(txn *roachpb.Transaction) {
+	if txn == nil || txn.ID == nil {
+		return
+	}
+	ptq.mu.Lock()
+	waiting := ptq.mu.txns[*txn.ID]
+	delete(ptq.mu.txns, *txn.ID)
+	ptq.mu.Unlock()
+
+	for _, w := range waiting {
+		w.signal(txn)
+	}
+}
+
+// GetDependents returns the IDs of the transactions which are waiting,
+// directly or transitively, on the transaction with the supplied ID.
+func (ptq *pushTxnQueue) GetDependents(txnID uuid.UUID) []uuid.UUID {
+	ptq.mu.Lock()
+	defer ptq.mu.Unlock()
+
+	set := map[uuid.UUID]struct{}{}
+	for _, w := range ptq.mu.txns[txnID] {
+		if w.req.PusherTxn.ID != nil {
+			set[*w.req.PusherTxn.ID] = struct{}{}
+		}
+		w.mu.Lock()
+		for id := range w.mu.dependents {
+			set[id] = struct{}{}
+		}
+		w.mu.Unlock()
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	dependents := make([]uuid.UUID, 0, len(set))
+	for id := range set {
+		dependents = append(dependents, id)
+	}
+	return dependents
+}
+
+// MaybeWait is called after the supplied push failed with the supplied
+// TransactionPushError. The push is enqueued and waits until the
+// pushee's transaction record is updated, the pushee's heartbeat
+// expires or a dependency cycle involving the pusher and pushee is
+// detected. On return with a nil error the push should be retried; if
+// force is true, the retried push should be forced to break a
+// dependency cycle. A non-nil error should be returned to the pusher.
+func (ptq *pushTxnQueue) MaybeWait(
+	ctx context.Context, req *roachpb.PushTxnRequest, pErr *roachpb.Error,
+) (force bool, _ *roachpb.Error) {
+	pushErr, ok := pErr.GetDetail().(*roachpb.TransactionPushError)
+	if !ok || req.PusheeTxn.ID == nil {
+		return false, pErr
+	}
+	pushee := &pushErr.PusheeTxn
+
+	w := &waitingPush{
+		req:     req,
+		pending: make(chan *roachpb.Transaction, 1),
+	}
+	ptq.mu.Lock()
+	ptq.mu.txns[*req.PusheeTxn.ID] = append(ptq.mu.txns[*req.PusheeTxn.ID], w)
+	ptq.mu.Unlock()
+	defer ptq.remove(w)
+
+	log.VEventf(ctx, 2, "%s waiting on pushee %s", req.PusherTxn.Short(), pushee.Short())
+
+	// The pushee is considered abandoned once it has failed to heartbeat
+	// for two heartbeat intervals, at which point the push succeeds. The
+	// expiration is checked against the store's clock, which is the one
+	// PushTxn evaluates it against, on every tick of the query interval;
+	// the ticker only sets the pace at which the waiting push polls.
+	expiration := pushee.LastActive().Add(2*base.DefaultHeartbeatInterval.Nanoseconds(), 0)
+	ticker := time.NewTicker(pushTxnQueueQueryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false, roachpb.NewError(ctx.Err())
+
+		case <-ptq.store.Stopper().ShouldQuiesce():
+			return false, pErr
+
+		case txn := <-w.pending:
+			if txn != nil {
+				log.VEventf(ctx, 2, "pushee %s was updated", txn.Short())
+			}
+			return false, nil
+
+		case <-ticker.C:
+			if expiration.Less(ptq.store.Clock().Now()) {
+				log.VEventf(ctx, 2, "pushee %s has expired", pushee.Short())
+				return false, nil
+			}
+			// Only transactional pushers can take part in a dependency cycle.
+			if req.PusherTxn.ID == nil {
+				continue
+			}
+			pusher, dependents, qErr := ptq.queryPusher(ctx, req)
+			if qErr != nil {
+				log.VEventf(ctx, 2, "failed to query pusher %s: %s", req.PusherTxn.Short(), qErr)
+				continue
+			}
+			if pusher.ID != nil && pusher.Status != roachpb.PENDING {
+				// The pusher has been aborted or committed in the meantime; it
+				// will find out on its next request.
+				return false, pErr
+			}
+			w.mu.Lock()
+			w.mu.dependents = dependents
+			w.mu.Unlock()
+
+			if _, ok := dependents[*req.PusheeTxn.ID]; ok {
+				priority := req.PusherTxn.Priority
+				if pusher.ID != nil {
+					priority = pusher.Priority
+				}
+				if pusherWinsCycle(req.PusherTxn.ID, priority, req.PusheeTxn.ID, pushee.Priority) {
+					log.VEventf(ctx, 1, "%s breaking dependency cycle with pushee %s",
+						req.PusherTxn.Short(), pushee.Short())
+					return true, nil
+				}
+			}
+		}
+	}
+}
+
+// queryPusher queries the transaction record of the pusher, returning
+// its current state along with the set of transactions waiting on it.
+func (ptq *pushTxnQueue) queryPusher(
+	ctx context.Context, req *roachpb.PushTxnRequest,
+) (*roachpb.Transaction, map[uuid.UUID]struct{}, error) {
+	b := &client.Batch{}
+	b.AddRawRequest(&roachpb.PushTxnRequest{
+		Span: roachpb.Span{
+			Key: req.PusherTxn.Key,
+		},
+		Now:       ptq.store.Clock().Now(),
+		PusheeTxn: req.PusherTxn.TxnMeta,
+		PushType:  roachpb.PUSH_QUERY,
+	})
+	if err := ptq.store.DB().Run(ctx, b); err != nil {
+		return nil, nil, err
+	}
+	resp := b.RawResponse().Responses[0].GetInner().(*roachpb.PushTxnResponse)
+	dependents := make(map[uuid.UUID]struct{}, len(resp.WaitingTxns))
+	for _, id := range resp.WaitingTxns {
+		dependents[id] = struct{}{}
+	}
+	return &resp.PusheeTxn, dependents, nil
+}
+
+// remove removes the supplied waiting push from the queue, if present.
+func (ptq *pushTxnQueue) remove(w *waitingPush) {
+	ptq.mu.Lock()
+	defer ptq.mu.Unlock()
+	pusheeID := *w.req.PusheeTxn.ID
+	waiting := ptq.mu.txns[pusheeID]
+	for i := range waiting {
+		if waiting[i] == w {
+			waiting = append(waiting[:i], waiting[i+1:]...)
+			break
+		}
+	}
+	if len(waiting) == 0 {
+		delete(ptq.mu.txns, pusheeID)
+	} else {
+		ptq.mu.txns[pusheeID] = waiting
+	}
+}
+
+// signal notifies the waiting push without blocking.
+func (w *waitingPush) signal(txn *roachpb.Transaction) {
+	select {
+	case w.pending <- txn:
+	default:
+	}
+}
+
+// pusherWinsCycle returns whether a pusher should force its push of the
+// pushee to break a dependency cycle. The ordering matches the one
+// PushTxn uses to decide conflicts, so that in a cycle of two
+// transactions exactly one side forces its push.
+func pusherWinsCycle(
+	pusherID *uuid.UUID, pusherPriority int32, pusheeID *uuid.UUID, pusheePriority int32,
+) bool {
+	if pusheePriority != pusherPriority {
+		return pusheePriority < pusherPriority
+	}
+	return bytes.Compare(pusheeID.GetBytes(), pusherID.GetBytes()) < 0
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func enablePushTxnQueue() func() {
+	resetEnabled := settings.TestingSetBool(&EnablePushTxnQueue, true)
+	prevInterval := pushTxnQueueQueryInterval
+	pushTxnQueueQueryInterval = 10 * time.Millisecond
+	return func() {
+		resetEnabled()
+		pushTxnQueueQueryInterval = prevInterval
+	}
+}
+
+// writeIntent lays down an intent at the supplied transaction's key.
+func writeIntent(t *testing.T, store *Store, txn *roachpb.Transaction) {
+	pArgs := putArgs(txn.Key, []byte("value"))
+	txn.Sequence++
+	if _, pErr := maybeWrapWithBeginTransaction(
+		context.Background(), store.testSender(), roachpb.Header{Txn: txn}, &pArgs,
+	); pErr != nil {
+		t.Fatal(pErr)
+	}
+	txn.Writing = true
+}
+
+// waitForPushes waits until the given number of pushes are waiting in
+// the push txn queue of the supplied replica.
+func waitForPushes(t *testing.T, repl *Replica, count int) {
+	util.SucceedsSoon(t, func() error {
+		repl.pushTxnQueue.mu.Lock()
+		defer repl.pushTxnQueue.mu.Unlock()
+		var n int
+		for _, waiting := range repl.pushTxnQueue.mu.txns {
+			n += len(waiting)
+		}
+		if n != count {
+			return errors.Errorf("expected %d waiting pushes; got %d", count, n)
+		}
+		return nil
+	})
+}
+
+// TestPushTxnQueueUpdateTxn verifies that a push which failed to push
+// its pushee waits in the push txn queue and succeeds as soon as the
+// pushee commits.
+func TestPushTxnQueueUpdateTxn(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer enablePushTxnQueue()()
+	store, _, stopper := createTestStore(t)
+	defer stopper.Stop()
+
+	pushee := newTransaction("pushee", roachpb.Key("a"), 1, enginepb.SERIALIZABLE, store.cfg.Clock)
+	pusher := newTransaction("pusher", roachpb.Key("b"), 1, enginepb.SERIALIZABLE, store.cfg.Clock)
+	pushee.Priority = 2
+	pusher.Priority = 1 // pusher will lose
+	writeIntent(t, store, pushee)
+
+	errCh := make(chan error, 1)
+	go func() {
+		args := pushTxnArgs(pusher, pushee, roachpb.PUSH_ABORT)
+		resp, pErr := client.SendWrapped(context.Background(), store.testSender(), &args)
+		if pErr != nil {
+			errCh <- pErr.GoError()
+			return
+		}
+		if status := resp.(*roachpb.PushTxnResponse).PusheeTxn.Status; status != roachpb.COMMITTED {
+			errCh <- errors.Errorf("expected pushee to be committed; got %s", status)
+			return
+		}
+		errCh <- nil
+	}()
+
+	repl := store.LookupReplica(roachpb.RKey(pushee.Key), nil)
+	waitForPushes(t, repl, 1)
+
+	eArgs, h := endTxnArgs(pushee, true /* commit */)
+	pushee.Sequence++
+	if _, pErr := client.SendWrappedWith(context.Background(), store.testSender(), h, &eArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	waitForPushes(t, repl, 0)
+}
+
+// TestPushTxnQueueDependencyCycle verifies that two transactions whose
+// pushes wait on each other are detected as a dependency cycle, which
+// is broken by aborting exactly one of them.
+func TestPushTxnQueueDependencyCycle(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer enablePushTxnQueue()()
+	store, _, stopper := createTestStore(t)
+	defer stopper.Stop()
+
+	txnA := newTransaction("a", roachpb.Key("a"), 1, enginepb.SERIALIZABLE, store.cfg.Clock)
+	txnB := newTransaction("b", roachpb.Key("b"), 1, enginepb.SERIALIZABLE, store.cfg.Clock)
+	txnA.Priority = 2
+	txnB.Priority = 2
+	writeIntent(t, store, txnA)
+	writeIntent(t, store, txnB)
+
+	// Each transaction pushes the other with a stale, lower priority so
+	// that neither push succeeds on priority.
+	type result struct {
+		resp *roachpb.PushTxnResponse
+		pErr *roachpb.Error
+	}
+	resultCh := make(chan result, 2)
+	for _, txns := range [][2]*roachpb.Transaction{{txnA, txnB}, {txnB, txnA}} {
+		pusher := txns[0].Clone()
+		pusher.Priority = 1
+		args := pushTxnArgs(&pusher, txns[1], roachpb.PUSH_ABORT)
+		go func() {
+			resp, pErr := client.SendWrapped(context.Background(), store.testSender(), &args)
+			if pErr != nil {
+				resultCh <- result{pErr: pErr}
+				return
+			}
+			resultCh <- result{resp: resp.(*roachpb.PushTxnResponse)}
+		}()
+	}
+
+	var succeeded, failed int
+	for i := 0; i < 2; i++ {
+		res := <-resultCh
+		if res.pErr != nil {
+			if _, ok := res.pErr.GetDetail().(*roachpb.TransactionPushError); !ok {
+				t.Fatalf("expected TransactionPushError; got %s", res.pErr)
+			}
+			failed++
+			continue
+		}
+		if status := res.resp.PusheeTxn.Status; status != roachpb.ABORTED {
+			t.Fatalf("expected pushee to be aborted; got %s", status)
+		}
+		succeeded++
+	}
+	if succeeded != 1 || failed != 1 {
+		t.Fatalf("expected exactly one push to succeed; got %d succeeded, %d failed", succeeded, failed)
+	}
+}
+
+// TestPushTxnQueueDisabledByDefault backs the queue's opt-in default: a
+// push which loses against a live pushee returns a TransactionPushError
+// right away while the queue is disabled, which callers of PushTxn rely
+// on to back off, but blocks until the pushee expires according to the
+// store's clock once it is enabled.
+func TestPushTxnQueueDisabledByDefault(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	if EnablePushTxnQueue.Get() {
+		t.Fatal("expected the push txn queue to be disabled by default")
+	}
+	store, manual, stopper := createTestStore(t)
+	defer stopper.Stop()
+
+	pushee := newTransaction("pushee", roachpb.Key("a"), 1, enginepb.SERIALIZABLE, store.cfg.Clock)
+	pusher := newTransaction("pusher", roachpb.Key("b"), 1, enginepb.SERIALIZABLE, store.cfg.Clock)
+	pushee.Priority = 2
+	pusher.Priority = 1 // pusher will lose
+	writeIntent(t, store, pushee)
+
+	args := pushTxnArgs(pusher, pushee, roachpb.PUSH_ABORT)
+	if _, pErr := client.SendWrapped(context.Background(), store.testSender(), &args); pErr == nil {
+		t.Fatal("expected the push to fail")
+	} else if _, ok := pErr.GetDetail().(*roachpb.TransactionPushError); !ok {
+		t.Fatalf("expected TransactionPushError; got %s", pErr)
+	}
+
+	defer enablePushTxnQueue()()
+	errCh := make(chan error, 1)
+	go func() {
+		args := pushTxnArgs(pusher, pushee, roachpb.PUSH_ABORT)
+		resp, pErr := client.SendWrapped(context.Background(), store.testSender(), &args)
+		if pErr != nil {
+			errCh <- pErr.GoError()
+			return
+		}
+		if status := resp.(*roachpb.PushTxnResponse).PusheeTxn.Status; status != roachpb.ABORTED {
+			errCh <- errors.Errorf("expected pushee to be aborted; got %s", status)
+			return
+		}
+		errCh <- nil
+	}()
+
+	repl := store.LookupReplica(roachpb.RKey(pushee.Key), nil)
+	waitForPushes(t, repl, 1)
+	// The pushee is live on the store's manual clock, however long the
+	// push waits in real time.
+	select {
+	case err := <-errCh:
+		t.Fatalf("expected the push to wait for the pushee; got %v", err)
+	case <-time.After(10 * pushTxnQueueQueryInterval):
+	}
+
+	manual.Increment(2*base.DefaultHeartbeatInterval.Nanoseconds() + 1)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	waitForPushes(t, repl, 0)
+}
+
+func TestPusherWinsCycle(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	txnA := newTransaction("a", roachpb.Key("a"), 1, enginepb.SERIALIZABLE, nil)
+	txnB := newTransaction("b", roachpb.Key("b"), 1, enginepb.SERIALIZABLE, nil)
+
+	testCases := []struct {
+		priorityA, priorityB int32
+	}{
+		{1, 2},
+		{2, 1},
+		{1, 1},
+	}
+	for i, c := range testCases {
+		aWins := pusherWinsCycle(txnA.ID, c.priorityA, txnB.ID, c.priorityB)
+		bWins := pusherWinsCycle(txnB.ID, c.priorityB, txnA.ID, c.priorityA)
+		if aWins == bWins {
+			t.Errorf("%d: expected exactly one pusher to win; got %t, %t", i, aWins, bWins)
+		}
+		if c.priorityA != c.priorityB && aWins != (c.priorityA > c.priorityB) {
+			t.Errorf("%d: expected higher priority pusher to win", i)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// quotaPool is a semaphore denominated in bytes. Acquisitions block
+// until enough quota has been returned to the pool, or until the pool
+// is closed, after which acquisitions succeed immediately without
+// taking any quota.
+type quotaPool struct {
+	capacity int64
+
+	mu struct {
+		syncutil.Mutex
+		quota  int64
+		closed bool
+		// notify is closed and replaced whenever quota is returned to the
+		// pool or the pool is closed, waking up all blocked acquisitions.
+		notify chan struct{}
+	}
+}
+
+func newQuotaPool(capacity int64) *quotaPool {
+	qp := &quotaPool{capacity: capacity}
+	qp.mu.quota = capacity
+	qp.mu.notify = make(chan struct{})
+	return qp
+}
+
+// acquire blocks until n bytes of quota are available and takes them
+// from the pool. Acquisitions larger than the pool's capacity take the
+// full capacity, so they proceed once the pool is otherwise idle. It
+// returns the amount of quota taken, which must be returned to the pool
+// with add, and fails if the context is canceled or stopCh is closed
+// first.
+func (qp *quotaPool) acquire(ctx context.Context, n int64, stopCh <-chan struct{}) (int64, error) {
+	if n > qp.capacity {
+		n = qp.capacity
+	}
+	for {
+		qp.mu.Lock()
+		if qp.mu.closed {
+			qp.mu.Unlock()
+			return 0, nil
+		}
+		if qp.mu.quota >= n {
+			qp.mu.quota -= n
+			qp.mu.Unlock()
+			return n, nil
+		}
+		notify := qp.mu.notify
+		qp.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-stopCh:
+			return 0, &roachpb.NodeUnavailableError{}
+		}
+	}
+}
+
+// add returns n bytes of quota to the pool. The pool never holds more
+// than its capacity.
+func (qp *quotaPool) add(n int64) {
+	if n <= 0 {
+		return
+	}
+	qp.mu.Lock()
+	defer qp.mu.Unlock()
+	qp.mu.quota += n
+	if qp.mu.quota > qp.capacity {
+		qp.mu.quota = qp.capacity
+	}
+	close(qp.mu.notify)
+	qp.mu.notify = make(chan struct{})
+}
+
+// close unblocks all current and future acquisitions.
+func (qp *quotaPool) close() {
+	qp.mu.Lock()
+	defer qp.mu.Unlock()
+	if !qp.mu.closed {
+		qp.mu.closed = true
+		close(qp.mu.notify)
+	}
+}
+
+// approximateQuota returns the quota currently available in the pool.
+func (qp *quotaPool) approximateQuota() int64 {
+	qp.mu.Lock()
+	defer qp.mu.Unlock()
+	return qp.mu.quota
+}
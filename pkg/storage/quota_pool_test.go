@@ -0,0 +1,124 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestQuotaPoolBlocksUntilQuotaReturned verifies that acquisitions block
+// while the pool is exhausted and proceed once enough quota is returned.
+func TestQuotaPoolBlocksUntilQuotaReturned(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	qp := newQuotaPool(100)
+
+	if n, err := qp.acquire(context.Background(), 80, nil); err != nil || n != 80 {
+		t.Fatalf("expected to acquire 80, got %d, %v", n, err)
+	}
+
+	acquired := make(chan int64, 1)
+	go func() {
+		n, err := qp.acquire(context.Background(), 50, nil)
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- n
+	}()
+
+	select {
+	case n := <-acquired:
+		t.Fatalf("acquired %d bytes from an exhausted pool", n)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Returning too little quota doesn't unblock the acquisition.
+	qp.add(20)
+	select {
+	case n := <-acquired:
+		t.Fatalf("acquired %d bytes with only %d available", n, 40)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	qp.add(60)
+	if n := <-acquired; n != 50 {
+		t.Fatalf("expected to acquire 50, got %d", n)
+	}
+	if q := qp.approximateQuota(); q != 50 {
+		t.Fatalf("expected 50 bytes of quota left, got %d", q)
+	}
+
+	// Quota returned beyond the pool's capacity is discarded.
+	qp.add(1000)
+	if q := qp.approximateQuota(); q != 100 {
+		t.Fatalf("expected pool to be capped at 100, got %d", q)
+	}
+}
+
+// TestQuotaPoolLargeAcquisition verifies that acquisitions larger than
+// the pool's capacity take the whole pool instead of blocking forever.
+func TestQuotaPoolLargeAcquisition(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	qp := newQuotaPool(100)
+
+	if n, err := qp.acquire(context.Background(), 1000, nil); err != nil || n != 100 {
+		t.Fatalf("expected to acquire 100, got %d, %v", n, err)
+	}
+	if q := qp.approximateQuota(); q != 0 {
+		t.Fatalf("expected empty pool, got %d", q)
+	}
+}
+
+// TestQuotaPoolCancellation verifies that blocked acquisitions return when
+// their context is canceled, the stopper closes, or the pool is closed.
+func TestQuotaPoolCancellation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	qp := newQuotaPool(100)
+	if _, err := qp.acquire(context.Background(), 100, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := qp.acquire(ctx, 10, nil); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+
+	stopCh := make(chan struct{})
+	close(stopCh)
+	if _, err := qp.acquire(context.Background(), 10, stopCh); err == nil {
+		t.Fatal("expected error when stopping")
+	} else if _, ok := err.(*roachpb.NodeUnavailableError); !ok {
+		t.Fatalf("expected NodeUnavailableError, got %T: %v", err, err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		n, err := qp.acquire(context.Background(), 10, nil)
+		if err == nil && n != 0 {
+			t.Errorf("expected no quota from a closed pool, got %d", n)
+		}
+		errCh <- err
+	}()
+	qp.close()
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+}
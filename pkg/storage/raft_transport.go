@@ -616,11 +616,13 @@ func (c snapshotClientWithBreaker) Recv() (*SnapshotResponse, error) {
 	return m, err
 }
 
-// SendSnapshot streams the given outgoing snapshot. The caller is responsible
-// for closing the OutgoingSnapshot.
+// SendSnapshot streams the given outgoing snapshot at the rate permitted by
+// the throttle, if it is non-nil. The caller is responsible for closing the
+// OutgoingSnapshot.
 func (t *RaftTransport) SendSnapshot(
 	ctx context.Context,
 	storePool *StorePool,
+	throttle *snapshotThrottle,
 	header SnapshotRequest_Header,
 	snap *OutgoingSnapshot,
 	newBatch func() engine.Batch,
@@ -653,5 +655,5 @@ func (t *RaftTransport) SendSnapshot(
 		snapshotClientWithBreaker{
 			MultiRaft_RaftSnapshotClient: stream,
 			breaker: breaker,
-		}, storePool, header, snap, newBatch)
+		}, storePool, throttle, header, snap, newBatch)
 }
@@ -0,0 +1,713 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package raftlog implements a store for the Raft log entries of the
+// ranges of a store, which keeps them outside of the store's engine.
+//
+// The entries of all the ranges are appended to a sequence of segment files.
+// Each entry is written as a record of the following layout:
+//
+//	checksum (4 bytes) | length of the payload (4 bytes) |
+//	payload: range ID (uvarint) | encoded raftpb.Entry
+//
+// The checksum is a CRC-32 (Castagnoli) of the payload. An index of the
+// location of the live entries of each range is kept in memory and rebuilt
+// from the segments when the log is opened, with later records of an entry
+// replacing earlier ones. Entries are removed from the index when they're
+// truncated, and a segment is deleted once it's the oldest segment and it
+// doesn't contain any live entries anymore. Once the live entries of the
+// oldest segment only take up a small part of it, they are copied to the
+// last segment so that the oldest segment can be deleted; otherwise, a few
+// long-lived entries would keep all the later segments around. Since only
+// a prefix of the segments is ever deleted, a removed entry can't reappear
+// when the log is reopened unless the record which replaced it was also
+// written to one of the remaining segments.
+//
+// The log doesn't know which entries of a range are committed. Its user is
+// expected to store the truncated state and the last index of the log of
+// each range elsewhere, to append entries and sync them before persisting
+// a last index which includes them, and to remove the entries outside of
+// these bounds after they're persisted, as well as when the log is opened
+// after a crash.
+package raftlog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// DirName is the name of the directory within a store's auxiliary directory
+// in which the store's log is kept.
+const DirName = "raftlog"
+
+// DefaultSegmentSize is the size after which the segment entries are
+// appended to is closed and a new segment is started.
+const DefaultSegmentSize = 64 << 20 // 64 MB
+
+const (
+	segmentSuffix    = ".log"
+	recordHeaderSize = 8
+)
+
+// compactionRatio is the ratio of the size of the oldest segment to the size
+// of its live entries at or above which the live entries are copied to the
+// last segment, so that the oldest segment can be deleted.
+const compactionRatio = 4
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// entryLoc is the location of an entry's record.
+type entryLoc struct {
+	seq    uint64 // the segment containing the record
+	offset int64  // the offset of the record within the segment
+	size   int64  // the size of the record, including its header
+}
+
+// segment is a file entries are appended to.
+type segment struct {
+	seq  uint64
+	file *os.File
+	// size is the number of bytes written to the segment. Guarded by Log.mu.
+	size int64
+	// live is the number of live entries in the segment, and liveBytes the
+	// size of their records. Guarded by Log.mu.
+	live      int
+	liveBytes int64
+	// synced is the number of bytes of the segment known to be synced.
+	// Guarded by Log.syncMu.
+	synced int64
+}
+
+// Log stores the Raft log entries of the ranges of a store in a sequence of
+// segment files in a directory. It is safe for concurrent use, though the
+// entries of a single range must not be modified concurrently.
+type Log struct {
+	dir         string
+	segmentSize int64
+
+	// syncMu serializes the syncs of segments, which allows concurrent
+	// appends to share a sync, as well as the compaction of segments with
+	// them. syncMu must be acquired before mu.
+	syncMu sync.Mutex
+
+	mu struct {
+		sync.RWMutex
+		// segments are the segments of the log, ordered by sequence number.
+		// Entries are appended to the last one.
+		segments []*segment
+		ranges   map[roachpb.RangeID]map[uint64]entryLoc
+		buf      []byte
+	}
+}
+
+// Open opens the log in the given directory, creating the directory if it
+// doesn't exist. The records which were being written when the log was last
+// closed uncleanly are discarded, but other corruption results in an error.
+func Open(dir string, segmentSize int64) (*Log, error) {
+	if segmentSize <= 0 {
+		segmentSize = DefaultSegmentSize
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	l := &Log{dir: dir, segmentSize: segmentSize}
+	l.mu.ranges = make(map[roachpb.RangeID]map[uint64]entryLoc)
+
+	seqs, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i, seq := range seqs {
+		if err := l.replaySegment(seq, i == len(seqs)-1); err != nil {
+			l.closeSegments()
+			return nil, err
+		}
+	}
+	if len(l.mu.segments) == 0 {
+		if err := l.addSegmentLocked(1); err != nil {
+			return nil, err
+		}
+	}
+	if err := l.compactLocked(); err != nil {
+		l.closeSegments()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Exists returns whether the directory contains the segments of a log.
+func Exists(dir string) (bool, error) {
+	seqs, err := listSegments(dir)
+	if os.IsNotExist(errors.Cause(err)) {
+		return false, nil
+	}
+	return len(seqs) > 0, err
+}
+
+func listSegments(dir string) ([]uint64, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var seqs []uint64
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("unexpected file %s in raft log directory %s", name, dir)
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Sort(uint64Slice(seqs))
+	return seqs, nil
+}
+
+type uint64Slice []uint64
+
+func (s uint64Slice) Len() int           { return len(s) }
+func (s uint64Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func (l *Log) segmentPath(seq uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%016d%s", seq, segmentSuffix))
+}
+
+// replaySegment adds the records of the segment to the index. A truncated or
+// corrupted record at the end of the last segment is the result of a crash
+// while it was being written, so the segment is truncated before it.
+func (l *Log) replaySegment(seq uint64, last bool) error {
+	path := l.segmentPath(seq)
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	seg := &segment{seq: seq, file: file}
+	l.mu.segments = append(l.mu.segments, seg)
+
+	r := bufio.NewReader(file)
+	var header [recordHeaderSize]byte
+	var payload []byte
+	for {
+		_, err := io.ReadFull(r, header[:])
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			size := binary.LittleEndian.Uint32(header[4:])
+			if cap(payload) < int(size) {
+				payload = make([]byte, size)
+			}
+			payload = payload[:size]
+			if _, err = io.ReadFull(r, payload); err == nil &&
+				crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(header[:4]) {
+				err = errors.New("checksum mismatch")
+			}
+		}
+		var rangeID roachpb.RangeID
+		var ent raftpb.Entry
+		if err == nil {
+			rangeID, err = decodePayload(payload, &ent)
+		}
+		if err != nil {
+			if !last {
+				return errors.Wrapf(err, "corrupted record at offset %d of %s", seg.size, path)
+			}
+			log.Warningf(context.TODO(), "discarding incomplete record at offset %d of %s: %s",
+				seg.size, path, err)
+			if err := file.Truncate(seg.size); err != nil {
+				return err
+			}
+			break
+		}
+		size := int64(recordHeaderSize + len(payload))
+		l.setLocked(rangeID, ent.Index, entryLoc{seq: seq, offset: seg.size, size: size})
+		seg.size += size
+	}
+	seg.synced = seg.size
+	return nil
+}
+
+func decodePayload(payload []byte, ent *raftpb.Entry) (roachpb.RangeID, error) {
+	rangeID, n := binary.Uvarint(payload)
+	if n <= 0 {
+		return 0, errors.New("invalid range ID")
+	}
+	if err := ent.Unmarshal(payload[n:]); err != nil {
+		return 0, err
+	}
+	return roachpb.RangeID(rangeID), nil
+}
+
+// addSegmentLocked creates a new segment which entries are appended to.
+func (l *Log) addSegmentLocked(seq uint64) error {
+	file, err := os.OpenFile(l.segmentPath(seq), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	l.mu.segments = append(l.mu.segments, &segment{seq: seq, file: file})
+	return syncDir(l.dir)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		_ = d.Close()
+		return err
+	}
+	return d.Close()
+}
+
+func (l *Log) segmentLocked(seq uint64) *segment {
+	i := sort.Search(len(l.mu.segments), func(i int) bool {
+		return l.mu.segments[i].seq >= seq
+	})
+	return l.mu.segments[i]
+}
+
+// setLocked sets the location of the entry, returning the change of the
+// size of the range's live entries.
+func (l *Log) setLocked(rangeID roachpb.RangeID, index uint64, loc entryLoc) int64 {
+	locs, ok := l.mu.ranges[rangeID]
+	if !ok {
+		locs = make(map[uint64]entryLoc)
+		l.mu.ranges[rangeID] = locs
+	}
+	delta := loc.size
+	if old, ok := locs[index]; ok {
+		oldSeg := l.segmentLocked(old.seq)
+		oldSeg.live--
+		oldSeg.liveBytes -= old.size
+		delta -= old.size
+	}
+	locs[index] = loc
+	seg := l.segmentLocked(loc.seq)
+	seg.live++
+	seg.liveBytes += loc.size
+	return delta
+}
+
+// removeLocked removes the entries of the range for which the filter
+// returns true from the index. Both syncMu and mu must be held.
+func (l *Log) removeLocked(rangeID roachpb.RangeID, filter func(index uint64) bool) error {
+	locs := l.mu.ranges[rangeID]
+	for index, loc := range locs {
+		if filter(index) {
+			seg := l.segmentLocked(loc.seq)
+			seg.live--
+			seg.liveBytes -= loc.size
+			delete(locs, index)
+		}
+	}
+	if len(locs) == 0 {
+		delete(l.mu.ranges, rangeID)
+	}
+	return l.compactLocked()
+}
+
+// compactLocked deletes the oldest segments which don't contain any live
+// entries, first copying the live entries of the oldest segment to the last
+// segment once they take up at most 1/compactionRatio of it. The copies are
+// appended after all the existing records, so they replace the originals
+// when the log is reopened. Both syncMu and mu must be held, unless the log
+// is being opened.
+func (l *Log) compactLocked() error {
+	for {
+		if err := l.removeDeadSegmentsLocked(); err != nil {
+			return err
+		}
+		if len(l.mu.segments) <= 1 {
+			return nil
+		}
+		seg := l.mu.segments[0]
+		if seg.liveBytes*compactionRatio > seg.size {
+			return nil
+		}
+		if err := l.copyLiveEntriesLocked(seg); err != nil {
+			return err
+		}
+	}
+}
+
+// liveEntry is a live entry of a segment being compacted.
+type liveEntry struct {
+	rangeID roachpb.RangeID
+	index   uint64
+	loc     entryLoc
+}
+
+type liveEntriesByOffset []liveEntry
+
+func (s liveEntriesByOffset) Len() int           { return len(s) }
+func (s liveEntriesByOffset) Less(i, j int) bool { return s[i].loc.offset < s[j].loc.offset }
+func (s liveEntriesByOffset) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// copyLiveEntriesLocked appends the records of the live entries of the
+// segment to the last segment and syncs them, which leaves the segment
+// without live entries. Both syncMu and mu must be held.
+func (l *Log) copyLiveEntriesLocked(seg *segment) error {
+	var ents []liveEntry
+	for rangeID, locs := range l.mu.ranges {
+		for index, loc := range locs {
+			if loc.seq == seg.seq {
+				ents = append(ents, liveEntry{rangeID: rangeID, index: index, loc: loc})
+			}
+		}
+	}
+	sort.Sort(liveEntriesByOffset(ents))
+
+	// Records are self-contained, so they are copied verbatim.
+	buf := l.mu.buf[:0]
+	offsets := make([]int64, len(ents))
+	for i, e := range ents {
+		offsets[i] = int64(len(buf))
+		start := len(buf)
+		end := start + int(e.loc.size)
+		if cap(buf) < end {
+			newBuf := make([]byte, len(buf), 2*end)
+			copy(newBuf, buf)
+			buf = newBuf
+		}
+		buf = buf[:end]
+		record := buf[start:end]
+		if _, err := seg.file.ReadAt(record, e.loc.offset); err != nil {
+			return errors.Wrapf(err, "reading entry %d of r%d", e.index, e.rangeID)
+		}
+		if crc32.Checksum(record[recordHeaderSize:], crcTable) != binary.LittleEndian.Uint32(record[:4]) {
+			return errors.Errorf("checksum mismatch in entry %d of r%d at offset %d of %s",
+				e.index, e.rangeID, e.loc.offset, l.segmentPath(seg.seq))
+		}
+	}
+	l.mu.buf = buf
+
+	last := l.mu.segments[len(l.mu.segments)-1]
+	if last.size >= l.segmentSize {
+		if err := last.file.Sync(); err != nil {
+			return err
+		}
+		last.synced = last.size
+		if err := l.addSegmentLocked(last.seq + 1); err != nil {
+			return err
+		}
+		last = l.mu.segments[len(l.mu.segments)-1]
+	}
+	if _, err := last.file.WriteAt(buf, last.size); err != nil {
+		return err
+	}
+	for i, e := range ents {
+		l.setLocked(e.rangeID, e.index, entryLoc{
+			seq:    last.seq,
+			offset: last.size + offsets[i],
+			size:   e.loc.size,
+		})
+	}
+	last.size += int64(len(buf))
+	if err := last.file.Sync(); err != nil {
+		return err
+	}
+	last.synced = last.size
+	// The records appended to the segment which were waiting for a sync
+	// were copied and synced, so the segment doesn't need to be synced
+	// anymore when it's deleted.
+	seg.synced = seg.size
+	log.Infof(context.TODO(), "copied %d live entries of raft log segment %s",
+		len(ents), l.segmentPath(seg.seq))
+	return nil
+}
+
+// removeDeadSegmentsLocked deletes the oldest segments while they don't
+// contain any live entries. The segment entries are appended to is kept.
+func (l *Log) removeDeadSegmentsLocked() error {
+	removed := false
+	for len(l.mu.segments) > 1 && l.mu.segments[0].live == 0 {
+		seg := l.mu.segments[0]
+		if err := seg.file.Close(); err != nil {
+			return err
+		}
+		if err := os.Remove(l.segmentPath(seg.seq)); err != nil {
+			return err
+		}
+		l.mu.segments = l.mu.segments[1:]
+		removed = true
+	}
+	if removed {
+		return syncDir(l.dir)
+	}
+	return nil
+}
+
+// Append writes the entries of the range to the log and syncs them. Entries
+// replace any existing entries with the same index. It returns the change of
+// the size of the range's live entries.
+func (l *Log) Append(rangeID roachpb.RangeID, ents []raftpb.Entry) (int64, error) {
+	if len(ents) == 0 {
+		return 0, nil
+	}
+	l.mu.Lock()
+	seg := l.mu.segments[len(l.mu.segments)-1]
+	if seg.size >= l.segmentSize {
+		// Sync the full segment before starting a new one, so that syncing
+		// the new segment is enough to sync all the appended entries.
+		if err := seg.file.Sync(); err != nil {
+			l.mu.Unlock()
+			return 0, err
+		}
+		if err := l.addSegmentLocked(seg.seq + 1); err != nil {
+			l.mu.Unlock()
+			return 0, err
+		}
+		seg = l.mu.segments[len(l.mu.segments)-1]
+	}
+
+	buf := l.mu.buf[:0]
+	offsets := make([]int64, len(ents)+1)
+	for i := range ents {
+		offsets[i] = int64(len(buf))
+		var err error
+		if buf, err = appendRecord(buf, rangeID, &ents[i]); err != nil {
+			l.mu.Unlock()
+			return 0, err
+		}
+	}
+	offsets[len(ents)] = int64(len(buf))
+	l.mu.buf = buf
+	if _, err := seg.file.WriteAt(buf, seg.size); err != nil {
+		l.mu.Unlock()
+		return 0, err
+	}
+	var delta int64
+	for i := range ents {
+		delta += l.setLocked(rangeID, ents[i].Index, entryLoc{
+			seq:    seg.seq,
+			offset: seg.size + offsets[i],
+			size:   offsets[i+1] - offsets[i],
+		})
+	}
+	seg.size += int64(len(buf))
+	end := seg.size
+	l.mu.Unlock()
+
+	return delta, l.sync(seg, end)
+}
+
+func appendRecord(buf []byte, rangeID roachpb.RangeID, ent *raftpb.Entry) ([]byte, error) {
+	var varint [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(varint[:], uint64(rangeID))
+	size := n + ent.Size()
+
+	start := len(buf)
+	end := start + recordHeaderSize + size
+	if cap(buf) < end {
+		newBuf := make([]byte, len(buf), 2*end)
+		copy(newBuf, buf)
+		buf = newBuf
+	}
+	buf = buf[:end]
+	payload := buf[start+recordHeaderSize:]
+	copy(payload, varint[:n])
+	if _, err := ent.MarshalTo(payload[n:]); err != nil {
+		return nil, err
+	}
+	binary.LittleEndian.PutUint32(buf[start:], crc32.Checksum(payload, crcTable))
+	binary.LittleEndian.PutUint32(buf[start+4:], uint32(size))
+	return buf, nil
+}
+
+// sync syncs the segment if the first end bytes of it haven't been synced
+// yet. The segment contains live entries which were just appended, so it
+// can't be deleted concurrently.
+func (l *Log) sync(seg *segment, end int64) error {
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+	if seg.synced >= end {
+		return nil
+	}
+	l.mu.RLock()
+	size := seg.size
+	l.mu.RUnlock()
+	if err := seg.file.Sync(); err != nil {
+		return err
+	}
+	seg.synced = size
+	return nil
+}
+
+// Iterate calls f with the encoded entries of the range with consecutive
+// indexes starting at lo, until the entry at hi or a missing entry is
+// reached, or f returns true or an error.
+func (l *Log) Iterate(
+	rangeID roachpb.RangeID, lo, hi uint64, f func(index uint64, data []byte) (bool, error),
+) error {
+	for index := lo; index < hi; index++ {
+		data, ok, err := l.read(rangeID, index)
+		if err != nil || !ok {
+			return err
+		}
+		if done, err := f(index, data); done || err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read returns the encoded entry of the range at the given index.
+func (l *Log) read(rangeID roachpb.RangeID, index uint64) ([]byte, bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	loc, ok := l.mu.ranges[rangeID][index]
+	if !ok {
+		return nil, false, nil
+	}
+	seg := l.segmentLocked(loc.seq)
+	record := make([]byte, loc.size)
+	if _, err := seg.file.ReadAt(record, loc.offset); err != nil {
+		return nil, false, errors.Wrapf(err, "reading entry %d of r%d", index, rangeID)
+	}
+	payload := record[recordHeaderSize:]
+	if crc32.Checksum(payload, crcTable) != binary.LittleEndian.Uint32(record[:4]) {
+		return nil, false, errors.Errorf("checksum mismatch in entry %d of r%d at offset %d of %s",
+			index, rangeID, loc.offset, l.segmentPath(loc.seq))
+	}
+	_, n := binary.Uvarint(payload)
+	return payload[n:], true, nil
+}
+
+// Size returns the size of the live entries of the range with indexes in
+// [lo, hi).
+func (l *Log) Size(rangeID roachpb.RangeID, lo, hi uint64) int64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var size int64
+	for index, loc := range l.mu.ranges[rangeID] {
+		if index >= lo && index < hi {
+			size += loc.size
+		}
+	}
+	return size
+}
+
+// Contains returns whether the log contains all the entries of the range
+// with indexes in [lo, hi).
+func (l *Log) Contains(rangeID roachpb.RangeID, lo, hi uint64) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	locs := l.mu.ranges[rangeID]
+	for index := lo; index < hi; index++ {
+		if _, ok := locs[index]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Bounds returns the lowest and highest index of the entries of the range,
+// and whether the log contains any entries of the range.
+func (l *Log) Bounds(rangeID roachpb.RangeID) (uint64, uint64, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var lo, hi uint64
+	for index := range l.mu.ranges[rangeID] {
+		if lo == 0 || index < lo {
+			lo = index
+		}
+		if index > hi {
+			hi = index
+		}
+	}
+	return lo, hi, hi > 0
+}
+
+// RangeIDs returns the IDs of the ranges the log contains entries of.
+func (l *Log) RangeIDs() []roachpb.RangeID {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	rangeIDs := make([]roachpb.RangeID, 0, len(l.mu.ranges))
+	for rangeID := range l.mu.ranges {
+		rangeIDs = append(rangeIDs, rangeID)
+	}
+	return rangeIDs
+}
+
+// Truncate removes the entries of the range with indexes lower than
+// firstIndex.
+func (l *Log) Truncate(rangeID roachpb.RangeID, firstIndex uint64) error {
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.removeLocked(rangeID, func(index uint64) bool { return index < firstIndex })
+}
+
+// TruncateAfter removes the entries of the range with indexes higher than
+// lastIndex.
+func (l *Log) TruncateAfter(rangeID roachpb.RangeID, lastIndex uint64) error {
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.removeLocked(rangeID, func(index uint64) bool { return index > lastIndex })
+}
+
+// Destroy removes all the entries of the range.
+func (l *Log) Destroy(rangeID roachpb.RangeID) error {
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.removeLocked(rangeID, func(uint64) bool { return true })
+}
+
+// Close syncs and closes the segments of the log.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := l.mu.segments[len(l.mu.segments)-1].file.Sync()
+	if closeErr := l.closeSegments(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (l *Log) closeSegments() error {
+	var err error
+	for _, seg := range l.mu.segments {
+		if closeErr := seg.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	l.mu.segments = nil
+	return err
+}
@@ -0,0 +1,330 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package raftlog
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/coreos/etcd/raft/raftpb"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func makeEntries(lo, hi, term uint64) []raftpb.Entry {
+	var ents []raftpb.Entry
+	for i := lo; i < hi; i++ {
+		ents = append(ents, raftpb.Entry{
+			Index: i,
+			Term:  term,
+			Data:  []byte(fmt.Sprintf("entry %d of term %d", i, term)),
+		})
+	}
+	return ents
+}
+
+// readEntries returns the entries of the range in [lo, hi).
+func readEntries(t *testing.T, l *Log, rangeID roachpb.RangeID, lo, hi uint64) []raftpb.Entry {
+	var ents []raftpb.Entry
+	if err := l.Iterate(rangeID, lo, hi, func(index uint64, data []byte) (bool, error) {
+		var ent raftpb.Entry
+		if err := ent.Unmarshal(data); err != nil {
+			return false, err
+		}
+		if ent.Index != index {
+			return false, fmt.Errorf("expected entry %d, but got %d", index, ent.Index)
+		}
+		ents = append(ents, ent)
+		return false, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return ents
+}
+
+func checkEntries(t *testing.T, l *Log, rangeID roachpb.RangeID, lo, hi uint64, expected []raftpb.Entry) {
+	ents := readEntries(t, l, rangeID, lo, hi)
+	if len(ents) != len(expected) {
+		t.Fatalf("r%d: expected %d entries, but got %d", rangeID, len(expected), len(ents))
+	}
+	for i := range ents {
+		if ents[i].Index != expected[i].Index || ents[i].Term != expected[i].Term ||
+			string(ents[i].Data) != string(expected[i].Data) {
+			t.Fatalf("r%d: expected entry %+v, but got %+v", rangeID, expected[i], ents[i])
+		}
+	}
+}
+
+func segmentCount(t *testing.T, dir string) int {
+	seqs, err := listSegments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(seqs)
+}
+
+// TestLogAppendTruncate verifies that entries of several ranges can be
+// appended, overwritten and truncated, and that this is reflected after the
+// log is reopened.
+func TestLogAppendTruncate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanup := testutils.TempDir(t, 0)
+	defer cleanup()
+
+	l, err := Open(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Append(1, makeEntries(1, 11, 5)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Append(2, makeEntries(5, 8, 1)); err != nil {
+		t.Fatal(err)
+	}
+	// Overwrite a suffix of the entries of r1, as Raft does after a leader
+	// change.
+	delta, err := l.Append(1, makeEntries(8, 10, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The overwritten entries have the same size as the new ones.
+	if delta != 0 {
+		t.Fatalf("expected a size delta of 0, but got %d", delta)
+	}
+	if err := l.TruncateAfter(1, 9); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Truncate(1, 3); err != nil {
+		t.Fatal(err)
+	}
+	expected := append(makeEntries(3, 8, 5), makeEntries(8, 10, 6)...)
+	checkEntries(t, l, 1, 3, 11, expected)
+	checkEntries(t, l, 2, 5, 8, makeEntries(5, 8, 1))
+	if l.Contains(1, 2, 10) || !l.Contains(1, 3, 10) || l.Contains(1, 3, 11) {
+		t.Fatal("unexpected entries of r1")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The truncated entries of r1 reappear when the log is reopened, since they
+	// are part of the same segment as the live entries. It's the responsibility
+	// of the user of the log to remove them again.
+	l, err = Open(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	if rangeIDs := l.RangeIDs(); len(rangeIDs) != 2 {
+		t.Fatalf("expected entries of 2 ranges, but got %v", rangeIDs)
+	}
+	checkEntries(t, l, 1, 1, 3, makeEntries(1, 3, 5))
+	checkEntries(t, l, 1, 3, 10, expected)
+	checkEntries(t, l, 2, 5, 8, makeEntries(5, 8, 1))
+	if err := l.Destroy(2); err != nil {
+		t.Fatal(err)
+	}
+	if rangeIDs := l.RangeIDs(); len(rangeIDs) != 1 || rangeIDs[0] != 1 {
+		t.Fatalf("expected entries of r1 only, but got %v", rangeIDs)
+	}
+}
+
+// TestLogSegmentRemoval verifies that segments are deleted once all of
+// their entries have been truncated.
+func TestLogSegmentRemoval(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanup := testutils.TempDir(t, 0)
+	defer cleanup()
+
+	// Use tiny segments so that every append starts a new segment.
+	l, err := Open(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	for i := uint64(1); i <= 10; i++ {
+		if _, err := l.Append(1, makeEntries(i, i+1, 1)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := l.Append(2, makeEntries(i, i+1, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := segmentCount(t, dir); n != 20 {
+		t.Fatalf("expected 20 segments, but found %d", n)
+	}
+	// Truncating r1 alone only allows the first segment to be removed, since
+	// the second one contains a live entry of r2.
+	if err := l.Truncate(1, 6); err != nil {
+		t.Fatal(err)
+	}
+	if n := segmentCount(t, dir); n != 19 {
+		t.Fatalf("expected 19 segments, but found %d", n)
+	}
+	if err := l.Truncate(2, 6); err != nil {
+		t.Fatal(err)
+	}
+	if n := segmentCount(t, dir); n != 10 {
+		t.Fatalf("expected 10 segments, but found %d", n)
+	}
+	// The segment entries are appended to is never removed.
+	if err := l.Destroy(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Destroy(2); err != nil {
+		t.Fatal(err)
+	}
+	if n := segmentCount(t, dir); n != 1 {
+		t.Fatalf("expected 1 segment, but found %d", n)
+	}
+	if _, err := l.Append(1, makeEntries(11, 12, 2)); err != nil {
+		t.Fatal(err)
+	}
+	checkEntries(t, l, 1, 11, 12, makeEntries(11, 12, 2))
+}
+
+// TestLogSegmentCompaction verifies that the live entries of the oldest
+// segment are copied forward once they take up a small part of it, so that
+// long-lived entries don't keep the later segments from being deleted.
+func TestLogSegmentCompaction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanup := testutils.TempDir(t, 0)
+	defer cleanup()
+
+	const segmentSize = 200
+	l, err := Open(dir, segmentSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first segment contains a single entry of r1, which is never
+	// truncated, and many entries of r2.
+	if _, err := l.Append(1, makeEntries(1, 2, 1)); err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(1); i <= 30; i += 10 {
+		if _, err := l.Append(2, makeEntries(i, i+10, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := segmentCount(t, dir); n != 3 {
+		t.Fatalf("expected 3 segments, but found %d", n)
+	}
+	// Once the entries of r2 are truncated, the entry of r1 is copied to a
+	// new segment and all the others are deleted.
+	if err := l.Truncate(2, 31); err != nil {
+		t.Fatal(err)
+	}
+	if n := segmentCount(t, dir); n != 1 {
+		t.Fatalf("expected 1 segment, but found %d", n)
+	}
+	checkEntries(t, l, 1, 1, 2, makeEntries(1, 2, 1))
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The truncated entries of r2 don't reappear when the log is reopened.
+	l, err = Open(dir, segmentSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := l.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	if rangeIDs := l.RangeIDs(); len(rangeIDs) != 1 || rangeIDs[0] != 1 {
+		t.Fatalf("expected entries of r1 only, but got %v", rangeIDs)
+	}
+	checkEntries(t, l, 1, 1, 2, makeEntries(1, 2, 1))
+}
+
+// TestLogRecovery verifies that a partially written record at the end of
+// the log is discarded when the log is opened, while a corrupted record in
+// an earlier segment results in an error.
+func TestLogRecovery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanup := testutils.TempDir(t, 0)
+	defer cleanup()
+
+	l, err := Open(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Append(1, makeEntries(1, 4, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Append(1, makeEntries(4, 6, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tear the last record of the last segment.
+	lastPath := l.segmentPath(2)
+	info, err := os.Stat(lastPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(lastPath, info.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+	l, err = Open(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEntries(t, l, 1, 1, 6, makeEntries(1, 5, 1))
+	// The log can be appended to after the torn record was discarded.
+	if _, err := l.Append(1, makeEntries(5, 6, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l, err = Open(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkEntries(t, l, 1, 1, 6, append(makeEntries(1, 5, 1), makeEntries(5, 6, 2)...))
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Corrupt the first segment.
+	f, err := os.OpenFile(l.segmentPath(1), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte("garbage"), recordHeaderSize+2); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir, 1); !testutils.IsError(err, "corrupted record at offset 0") {
+		t.Fatalf("expected a corruption error, but got %v", err)
+	}
+}
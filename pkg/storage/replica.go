@@ -238,6 +238,10 @@ type Replica struct {
 	systemDBHash []byte
 	abortCache   *AbortCache // Avoids anomalous reads after abort
 
+	// pushTxnQueue queues failed PushTxn requests which are waiting on
+	// transactions whose records are held by this range.
+	pushTxnQueue *pushTxnQueue
+
 	// creatingReplica is set when a replica is created as uninitialized
 	// via a raft message.
 	creatingReplica *roachpb.ReplicaDescriptor
@@ -271,6 +275,10 @@ type Replica struct {
 		global, local *CommandQueue
 	}
 
+	// rangeFeeds tracks the RangeFeeds served by the replica, which are
+	// notified of the keys written by each applied command.
+	rangeFeeds rangeFeedRegistry
+
 	mu struct {
 		// Protects all fields in the mu struct.
 		//
@@ -384,6 +392,21 @@ type Replica struct {
 
 		// Most recent timestamps for keys / key ranges.
 		tsCache *timestampCache
+		// closedTimestampTracker determines the closed timestamp attached to
+		// commands proposed while this replica holds the lease.
+		closedTimestampTracker closedTimestampTracker
+		// closedTimestamp is the highest closed timestamp carried by a
+		// command applied on this replica. Consistent reads at or below it
+		// may be served by this replica whether or not it holds the lease.
+		closedTimestamp hlc.Timestamp
+		// proposalQuota is the quota pool limiting the size of the commands
+		// in flight to followers. It is only non-nil while the replica is the
+		// Raft leader.
+		proposalQuota *quotaPool
+		// quotaReleaseQueue holds the quota of applied commands which are
+		// released once all live followers have appended them, in index
+		// order.
+		quotaReleaseQueue []quotaRelease
 		// submitProposalFn can be set to mock out the propose operation.
 		submitProposalFn func(*ProposalData) error
 		// Computed checksum at a snapshot UUID.
@@ -540,6 +563,7 @@ func newReplica(rangeID roachpb.RangeID, store *Store) *Replica {
 		RangeID:        rangeID,
 		store:          store,
 		abortCache:     NewAbortCache(rangeID),
+		pushTxnQueue:   newPushTxnQueue(store),
 	}
 
 	// Init rangeStr with the range ID.
@@ -711,7 +735,13 @@ func (r *Replica) destroyDataRaftMuLocked() error {
 		return err
 	}
 
-	return batch.Commit()
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+	if r.store.raftLog != nil {
+		return r.store.raftLog.Destroy(desc.RangeID)
+	}
+	return nil
 }
 
 func (r *Replica) setReplicaID(replicaID roachpb.ReplicaID) error {
@@ -1139,7 +1169,20 @@ func (r *Replica) Send(
 	var pErr *roachpb.Error
 	if ba.IsWrite() {
 		log.Event(ctx, "read-write path")
+		if err := r.maybeBackpressureWriteBatch(ctx, ba); err != nil {
+			return nil, roachpb.NewError(err)
+		}
+		if err := r.store.admission.admit(
+			ctx, r.writeAdmissionPriority(ba), r.store.Stopper().ShouldQuiesce(),
+		); err != nil {
+			return nil, roachpb.NewError(err)
+		}
 		br, pErr = r.addWriteCmd(ctx, ba)
+		if pErr == nil {
+			r.updatePushTxnQueue(ba, br)
+		} else {
+			br, pErr = r.maybeWaitForPushee(ctx, ba, pErr)
+		}
 	} else if ba.IsReadOnly() {
 		log.Event(ctx, "read-only path")
 		br, pErr = r.addReadOnlyCmd(ctx, ba)
@@ -1169,6 +1212,67 @@ func (r *Replica) Send(
 	return br, pErr
 }
 
+// maybeWaitForPushee is called when a write batch fails. If the batch
+// consists of a single PushTxn request which failed to push the pushee,
+// the push waits in the push txn queue instead of returning the error
+// to the pusher, which would back off and retry. See pushTxnQueue for
+// details.
+func (r *Replica) maybeWaitForPushee(
+	ctx context.Context, ba roachpb.BatchRequest, pErr *roachpb.Error,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	if !EnablePushTxnQueue.Get() {
+		return nil, pErr
+	}
+	for {
+		if _, ok := pErr.GetDetail().(*roachpb.TransactionPushError); !ok || !ba.IsSingleRequest() {
+			return nil, pErr
+		}
+		req, ok := ba.Requests[0].GetInner().(*roachpb.PushTxnRequest)
+		if !ok || (req.PushType != roachpb.PUSH_ABORT && req.PushType != roachpb.PUSH_TIMESTAMP) {
+			return nil, pErr
+		}
+		force, waitErr := r.pushTxnQueue.MaybeWait(ctx, req, pErr)
+		if waitErr != nil {
+			return nil, waitErr
+		}
+
+		// Retry the push. Now is updated so that an expired pushee is
+		// recognized as such.
+		retryReq := *req
+		retryReq.Now = r.store.Clock().Now()
+		retryReq.Force = force
+		ba.Requests = nil
+		ba.Add(&retryReq)
+
+		var br *roachpb.BatchResponse
+		if br, pErr = r.addWriteCmd(ctx, ba); pErr == nil {
+			r.updatePushTxnQueue(ba, br)
+			return br, nil
+		}
+	}
+}
+
+// updatePushTxnQueue informs the push txn queue about transaction
+// records updated by the supplied successful write batch, and attaches
+// the waiting transactions to the responses of push queries.
+func (r *Replica) updatePushTxnQueue(ba roachpb.BatchRequest, br *roachpb.BatchResponse) {
+	for i, union := range ba.Requests {
+		switch t := union.GetInner().(type) {
+		case *roachpb.EndTransactionRequest:
+			r.pushTxnQueue.UpdateTxn(br.Txn)
+		case *roachpb.PushTxnRequest:
+			resp := br.Responses[i].GetInner().(*roachpb.PushTxnResponse)
+			if t.PushType == roachpb.PUSH_QUERY {
+				if t.PusheeTxn.ID != nil {
+					resp.WaitingTxns = r.pushTxnQueue.GetDependents(*t.PusheeTxn.ID)
+				}
+			} else {
+				r.pushTxnQueue.UpdateTxn(&resp.PusheeTxn)
+			}
+		}
+	}
+}
+
 func (r *Replica) checkBatchRange(ba roachpb.BatchRequest) error {
 	rspan, err := keys.Range(ba)
 	if err != nil {
@@ -1420,7 +1524,13 @@ func (r *Replica) endCmds(
 // timestamp cache. When the write returns, the updated timestamp
 // will inform the batch response timestamp or batch response txn
 // timestamp.
-func (r *Replica) applyTimestampCache(ba *roachpb.BatchRequest) (bumped bool, _ *roachpb.Error) {
+//
+// The batch timestamp is also moved above minTS, which is the
+// timestamp the lease holder may have closed (see
+// trackClosedTimestamp).
+func (r *Replica) applyTimestampCache(
+	ba *roachpb.BatchRequest, minTS hlc.Timestamp,
+) (bumped bool, _ *roachpb.Error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -1441,6 +1551,20 @@ func (r *Replica) applyTimestampCache(ba *roachpb.BatchRequest) (bumped bool, _
 		}
 	}()
 
+	// Forward the timestamp above the closed timestamp. Like a more
+	// recent read, this does not make the write too old.
+	if minTS != hlc.ZeroTimestamp {
+		if ba.Txn != nil {
+			if !minTS.Less(ba.Txn.Timestamp) {
+				txn := ba.Txn.Clone()
+				txn.Timestamp.Forward(minTS.Next())
+				ba.Txn = &txn
+			}
+		} else {
+			ba.Timestamp.Forward(minTS.Next())
+		}
+	}
+
 	for _, union := range ba.Requests {
 		args := union.GetInner()
 		if consultsTimestampCache(args) {
@@ -1567,8 +1691,9 @@ func (r *Replica) addAdminCmd(
 func (r *Replica) addReadOnlyCmd(
 	ctx context.Context, ba roachpb.BatchRequest,
 ) (br *roachpb.BatchResponse, pErr *roachpb.Error) {
-	// If the read is consistent, the read requires the range lease.
-	if ba.ReadConsistency != roachpb.INCONSISTENT {
+	// If the read is consistent, the read requires the range lease unless
+	// its timestamp has been closed.
+	if ba.ReadConsistency != roachpb.INCONSISTENT && !r.canServeFollowerRead(ctx, ba) {
 		if pErr = r.redirectOnOrAcquireLease(ctx); pErr != nil {
 			return nil, pErr
 		}
@@ -1740,12 +1865,24 @@ func (r *Replica) tryAddWriteCmd(
 		}
 	}
 
+	if pErr := r.checkProtectedTimestamps(ctx, ba); pErr != nil {
+		return nil, pErr, false
+	}
+
+	untrackClosedTimestamp := func() {}
 	if !isNonKV {
+		// Register the write with the closed timestamp tracker until it has
+		// been proposed, so that the lease holder does not close a timestamp
+		// above it in the meantime.
+		var minTS hlc.Timestamp
+		minTS, untrackClosedTimestamp = r.trackClosedTimestamp()
+		defer untrackClosedTimestamp()
+
 		// Examine the read and write timestamp caches for preceding
 		// commands which require this command to move its timestamp
 		// forward. Or, in the case of a transactional write, the txn
 		// timestamp and possible write-too-old bool.
-		if bumped, pErr := r.applyTimestampCache(&ba); pErr != nil {
+		if bumped, pErr := r.applyTimestampCache(&ba, minTS); pErr != nil {
 			return nil, pErr, false
 		} else if bumped {
 			// There is brittleness built into this system. If we bump the
@@ -1780,6 +1917,7 @@ func (r *Replica) tryAddWriteCmd(
 	log.Event(ctx, "raft")
 
 	ch, tryAbandon, err := r.propose(ctx, ba)
+	untrackClosedTimestamp()
 	if err != nil {
 		return nil, roachpb.NewError(err), false
 	}
@@ -1915,6 +2053,9 @@ func (r *Replica) insertProposalLocked(pd *ProposalData, originReplica roachpb.R
 	}
 	pd.MaxLeaseIndex = r.mu.lastAssignedLeaseIndex
 	pd.OriginReplica = originReplica
+	if !pd.Cmd.IsLeaseRequest() {
+		pd.ClosedTimestamp = r.closeTimestampLocked()
+	}
 	if log.V(4) {
 		log.Infof(pd.ctx, "submitting proposal %x: maxLeaseIndex=%d",
 			pd.idKey, pd.MaxLeaseIndex)
@@ -1959,6 +2100,15 @@ func (r *Replica) propose(
 	}
 	r.mu.Unlock()
 
+	// Acquire proposal quota before locking raftMu, which is required to
+	// release the quota held by other commands.
+	var quotaSize int64
+	if !ba.IsNonKV() {
+		if quotaSize, err = r.maybeAcquireProposalQuota(ctx, int64(ba.Size())); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// submitProposalLocked calls withRaftGroupLocked which requires that
 	// raftMu is held. In order to maintain our lock ordering we need to lock
 	// Replica.raftMu here before locking Replica.mu.
@@ -1980,6 +2130,7 @@ func (r *Replica) propose(
 	// An error here corresponds to a failfast-proposal: The command resulted
 	// in an error and did not need to commit a batch (the common error case).
 	if pErr != nil {
+		r.releaseProposalQuota(quotaSize)
 		r.handleProposalData(
 			ctx, repDesc, pCmd.LocalProposalData, pCmd.ReplicatedProposalData,
 		)
@@ -1991,10 +2142,12 @@ func (r *Replica) propose(
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	pCmd.quotaSize = quotaSize
 	r.insertProposalLocked(pCmd, repDesc)
 
 	if err := r.submitProposalLocked(pCmd); err != nil {
 		delete(r.mu.proposals, pCmd.idKey)
+		r.releaseProposalQuotaLocked(quotaSize)
 		return nil, nil, err
 	}
 	// Must not use `pCmd` in the closure below as a proposal which is not
@@ -2003,6 +2156,9 @@ func (r *Replica) propose(
 		r.mu.Lock()
 		_, ok := r.mu.proposals[idKey]
 		delete(r.mu.proposals, idKey)
+		if ok {
+			r.releaseProposalQuotaLocked(quotaSize)
+		}
 		r.mu.Unlock()
 		return ok
 	}
@@ -2029,9 +2185,10 @@ func defaultSubmitProposalLocked(r *Replica, p *ProposalData) error {
 	ctx := r.AnnotateCtx(context.TODO())
 
 	raftCmd := storagebase.RaftCommand{
-		Cmd:           p.Cmd,
-		OriginReplica: p.OriginReplica,
-		MaxLeaseIndex: p.MaxLeaseIndex,
+		Cmd:             p.Cmd,
+		OriginReplica:   p.OriginReplica,
+		MaxLeaseIndex:   p.MaxLeaseIndex,
+		ClosedTimestamp: p.ClosedTimestamp,
 	}
 	if p.ReplicatedProposalData != (storagebase.ReplicatedProposalData{}) {
 		raftCmd.ReplicatedProposalData = &p.ReplicatedProposalData
@@ -2190,6 +2347,7 @@ func (r *Replica) handleRaftReadyRaftMuLocked(inSnap IncomingSnapshot) error {
 	lastIndex := r.mu.lastIndex // used for append below
 	raftLogSize := r.mu.raftLogSize
 	leaderID := r.mu.leaderID
+	lastLeaderID := leaderID
 	err := r.withRaftGroupLocked(false, func(raftGroup *raft.RawNode) (bool, error) {
 		if hasReady = raftGroup.HasReady(); hasReady {
 			rd = raftGroup.Ready()
@@ -2294,6 +2452,13 @@ func (r *Replica) handleRaftReadyRaftMuLocked(inSnap IncomingSnapshot) error {
 	if err := batch.Commit(); err != nil {
 		return err
 	}
+	if r.store.raftLog != nil && len(rd.Entries) > 0 {
+		// Remove the previously appended entries which never committed now
+		// that the new last index is persisted.
+		if err := r.store.raftLog.TruncateAfter(r.RangeID, lastIndex); err != nil {
+			return err
+		}
+	}
 
 	// Update protected state (last index, raft log size and raft leader
 	// ID) and set raft log entry cache. We clear any older, uncommitted
@@ -2379,6 +2544,7 @@ func (r *Replica) handleRaftReadyRaftMuLocked(inSnap IncomingSnapshot) error {
 			log.Fatalf(ctx, "unexpected Raft entry: %v", e)
 		}
 	}
+	r.updateProposalQuotaRaftMuLocked(ctx, lastLeaderID)
 	if refreshReason != noReason {
 		r.mu.Lock()
 		r.refreshProposalsLocked(0, refreshReason)
@@ -2399,7 +2565,16 @@ func (r *Replica) handleRaftReadyRaftMuLocked(inSnap IncomingSnapshot) error {
 func (r *Replica) tick() (bool, error) {
 	r.raftMu.Lock()
 	defer r.raftMu.Unlock()
-	return r.tickRaftMuLocked()
+	exists, err := r.tickRaftMuLocked()
+	if exists && err == nil {
+		// Followers acknowledging appends don't necessarily produce a Raft
+		// Ready, so proposal quota is also released periodically.
+		r.mu.Lock()
+		leaderID := r.mu.leaderID
+		r.mu.Unlock()
+		r.updateProposalQuotaRaftMuLocked(r.AnnotateCtx(context.TODO()), leaderID)
+	}
+	return exists, err
 }
 
 // tickRaftMuLocked requires that raftMu is held, but not replicaMu.
@@ -2693,6 +2868,7 @@ func (r *Replica) refreshProposalsLocked(refreshAtDelta int, reason refreshRaftR
 			continue
 		}
 		delete(r.mu.proposals, idKey)
+		r.releaseProposalQuotaLocked(p.quotaSize)
 		// The command's designated lease index range was filled up, so send it
 		// back to the proposer for a retry.
 		log.Eventf(p.ctx, "retry proposal %x: %s", p.idKey, reason)
@@ -2720,6 +2896,7 @@ func (r *Replica) refreshProposalsLocked(refreshAtDelta int, reason refreshRaftR
 		log.Eventf(p.ctx, "re-submitting command %x to Raft: %s", p.idKey, reason)
 		if err := r.submitProposalLocked(p); err != nil {
 			delete(r.mu.proposals, p.idKey)
+			r.releaseProposalQuotaLocked(p.quotaSize)
 			p.done <- proposalResult{Err: roachpb.NewError(err)}
 			close(p.done)
 		}
@@ -2828,6 +3005,7 @@ func (r *Replica) sendRaftMessage(ctx context.Context, msg raftpb.Message) {
 				if err := r.store.cfg.Transport.SendSnapshot(
 					ctx,
 					r.store.allocator.storePool,
+					r.store.snapshotSendThrottle,
 					SnapshotRequest_Header{
 						RangeDescriptor: *r.Desc(),
 						RaftMessageRequest: RaftMessageRequest{
@@ -2999,6 +3177,12 @@ func (r *Replica) processRaftCommand(
 		ctx = cmd.ctx
 		cmd.ctx = nil // avoid confusion
 		delete(r.mu.proposals, idKey)
+		if cmd.quotaSize > 0 && r.mu.proposalQuota != nil {
+			r.mu.quotaReleaseQueue = append(r.mu.quotaReleaseQueue, quotaRelease{
+				index: index,
+				size:  cmd.quotaSize,
+			})
+		}
 	}
 	leaseIndex := r.mu.state.LeaseAppliedIndex
 
@@ -3163,6 +3347,14 @@ func (r *Replica) processRaftCommand(
 			pErr = forcedErr
 		}
 
+		// All writes at or below the command's closed timestamp have been
+		// applied, so reads below it may now be served by this replica.
+		if pErr == nil && raftCmd.ClosedTimestamp != hlc.ZeroTimestamp {
+			r.mu.Lock()
+			r.mu.closedTimestamp.Forward(raftCmd.ClosedTimestamp)
+			r.mu.Unlock()
+		}
+
 		var lpd LocalProposalData
 		if cmdProposedLocally {
 			if pErr != nil {
@@ -3360,6 +3552,7 @@ func (r *Replica) applyRaftCommand(
 		return enginepb.MVCCStats{}, roachpb.NewError(NewReplicaCorruptionError(
 			errors.Wrap(err, "could not commit batch")))
 	}
+	r.rangeFeeds.notify(ctx, writeBatch)
 	return rpd.Delta, nil
 }
 
@@ -4143,9 +4336,13 @@ func (r *Replica) needsSplitBySizeLocked() bool {
 }
 
 func (r *Replica) exceedsDoubleSplitSizeLocked() bool {
+	return r.exceedsMultipleOfSplitSizeLocked(2)
+}
+
+func (r *Replica) exceedsMultipleOfSplitSizeLocked(mult float64) bool {
 	maxBytes := r.mu.maxBytes
 	size := r.mu.state.Stats.Total()
-	return maxBytes > 0 && size > maxBytes*2
+	return maxBytes > 0 && float64(size) > float64(maxBytes)*mult
 }
 
 func (r *Replica) setPendingSnapshotIndex(index uint64) error {
@@ -0,0 +1,114 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+)
+
+// backpressureRangeSizeMultiplier is the multiple of the zone's
+// RangeMaxBytes above which writes to a range are blocked until the range
+// has been split. This keeps a range which is written to faster than it
+// can be split from growing without bound. Zero disables backpressure.
+var backpressureRangeSizeMultiplier = settings.RegisterValidatedFloatSetting(
+	"kv.range.backpressure_range_size_multiplier",
+	"multiple of range_max_bytes that a range can grow to without splitting before writes to it are blocked, or 0 to disable",
+	2,
+	func(v float64) error {
+		// A multiplier below 1 would block writes to ranges which aren't
+		// due for a split yet.
+		if v != 0 && v < 1 {
+			return errors.Errorf("must be 0 or at least 1, got %g", v)
+		}
+		return nil
+	},
+)
+
+// backpressureRetryOptions control how often a backpressured write checks
+// whether the range has been split.
+var backpressureRetryOptions = retry.Options{
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     time.Second,
+	Multiplier:     2,
+}
+
+// shouldBackpressureWrites returns whether writes in the batch should be
+// blocked while the range is too large. Only requests which add data are
+// backpressured; deletions are allowed through as they can only help.
+// Ranges holding system data are exempt, since writes to them (node
+// liveness heartbeats, for example) may be needed for the split itself to
+// make progress.
+func (r *Replica) shouldBackpressureWrites(ba roachpb.BatchRequest) bool {
+	if backpressureRangeSizeMultiplier.Get() <= 0 {
+		return false
+	}
+	if sq := r.store.splitQueue; sq == nil || sq.Disabled() {
+		// Nothing is going to split the range.
+		return false
+	}
+	if r.Desc().StartKey.Less(roachpb.RKey(keys.UserTableDataMin)) {
+		return false
+	}
+	for _, union := range ba.Requests {
+		switch union.GetInner().(type) {
+		case *roachpb.PutRequest, *roachpb.ConditionalPutRequest, *roachpb.InitPutRequest,
+			*roachpb.IncrementRequest, *roachpb.MergeRequest:
+			return true
+		}
+	}
+	return false
+}
+
+// maybeBackpressureWriteBatch blocks the batch while the range exceeds
+// backpressureRangeSizeMultiplier times its maximum size, until the split
+// queue has split the range, the context is canceled or the store is
+// stopping.
+func (r *Replica) maybeBackpressureWriteBatch(ctx context.Context, ba roachpb.BatchRequest) error {
+	if !r.shouldBackpressureWrites(ba) {
+		return nil
+	}
+	if !r.exceedsBackpressureSize() {
+		return nil
+	}
+
+	log.Event(ctx, "backpressuring write until range is split")
+	r.store.splitQueue.MaybeAdd(r, r.store.Clock().Now())
+	opts := backpressureRetryOptions
+	opts.Closer = r.store.Stopper().ShouldQuiesce()
+	for retry := retry.StartWithCtx(ctx, opts); retry.Next(); {
+		if !r.exceedsBackpressureSize() {
+			return nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return &roachpb.NodeUnavailableError{}
+}
+
+func (r *Replica) exceedsBackpressureSize() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.exceedsMultipleOfSplitSizeLocked(backpressureRangeSizeMultiplier.Get())
+}
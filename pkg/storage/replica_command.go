@@ -1268,6 +1268,10 @@ func (r *Replica) GC(
 // pusher, return TransactionPushError. Transaction will be retried
 // with priority one less than the pushee's higher priority.
 //
+// Forced Push: If args.Force is set, the pusher wins regardless of
+// priority. This is used by the push txn queue to break a dependency
+// cycle between waiting transactions.
+//
 // If the pusher is non-transactional, args.PusherTxn is an empty
 // proto with only the priority set.
 //
@@ -1391,6 +1395,9 @@ func (r *Replica) PushTxn(
 		// If just attempting to cleanup old or already-committed txns,
 		// pusher always fails.
 		pusherWins = false
+	case args.Force:
+		reason = "forced push to break dependency cycle"
+		pusherWins = true
 	case args.PushType == roachpb.PUSH_TIMESTAMP &&
 		reply.PusheeTxn.Isolation == enginepb.SNAPSHOT:
 		// Can always push a SNAPSHOT txn's timestamp.
@@ -1594,6 +1601,11 @@ func (r *Replica) TruncateLog(
 		hlc.ZeroTimestamp, nil /* txn */, false /* returnKeys */); err != nil {
 		return reply, ProposalData{}, err
 	}
+	if raftLog := r.store.raftLog; raftLog != nil {
+		// The entries are removed from the raft log files once the new
+		// TruncatedState is applied.
+		diff.SysBytes -= raftLog.Size(r.RangeID, 0, args.Index)
+	}
 	r.mu.Lock()
 	raftLogSize := r.mu.raftLogSize + diff.SysBytes
 	r.mu.Unlock()
@@ -1839,12 +1851,17 @@ func (r *Replica) CheckConsistency(
 	var inconsistencyCount uint32
 	var wg sync.WaitGroup
 	sp := r.store.cfg.StorePool
-	for _, replica := range r.Desc().Replicas {
+	replicas := r.Desc().Replicas
+	// The checksums of the replicas, in the order of replicas. The checksums
+	// of the replicas which couldn't be reached are nil.
+	checksums := make([][]byte, len(replicas))
+	for i, replica := range replicas {
 		if replica == localReplica {
+			checksums[i] = c.checksum
 			continue
 		}
 		wg.Add(1)
-		replica := replica // per-iteration copy
+		i, replica := i, replica // per-iteration copy
 		if err := r.store.Stopper().RunAsyncTask(ctx, func(ctx context.Context) {
 			defer wg.Done()
 			addr, err := sp.resolver(replica.NodeID)
@@ -1870,6 +1887,7 @@ func (r *Replica) CheckConsistency(
 				log.Error(ctx, errors.Wrapf(err, "could not CollectChecksum from replica %s", replica))
 				return
 			}
+			checksums[i] = resp.Checksum
 			if bytes.Equal(c.checksum, resp.Checksum) {
 				return
 			}
@@ -1894,6 +1912,13 @@ func (r *Replica) CheckConsistency(
 
 	if inconsistencyCount == 0 {
 	} else if args.WithDiff {
+		if ConsistencyCheckRepair.Get() {
+			if repaired, err := r.repairInconsistentReplicas(ctx, replicas, checksums); err != nil {
+				log.Error(ctx, errors.Wrap(err, "could not repair inconsistent replicas"))
+			} else if repaired {
+				return roachpb.CheckConsistencyResponse{}, nil
+			}
+		}
 		logFunc := log.Errorf
 		if p := r.store.TestingKnobs().BadChecksumPanic; p != nil {
 			p(r.store.Ident)
@@ -3207,7 +3232,8 @@ func (r *Replica) ChangeReplicas(
 				CanDecline: true,
 			}
 			if err := r.store.cfg.Transport.SendSnapshot(
-				ctx, r.store.allocator.storePool, req, snap, r.store.Engine().NewBatch); err != nil {
+				ctx, r.store.allocator.storePool, r.store.snapshotSendThrottle, req, snap,
+				r.store.Engine().NewBatch); err != nil {
 				return errors.Wrapf(err, "%s: change replicas aborted due to failed preemptive snapshot", r)
 			}
 			return nil
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
@@ -32,6 +33,15 @@ const (
 	replicaConsistencyQueueSize = 100
 )
 
+// consistencyCheckInterval is the target duration of a complete pass of
+// consistency checks over a store's replicas, for the stores whose
+// StoreConfig.ConsistencyCheckInterval isn't set.
+var consistencyCheckInterval = settings.RegisterNonNegativeDurationSetting(
+	"server.consistency_check.interval",
+	"the time between range consistency checks, or 0 to disable them",
+	24*time.Hour,
+)
+
 type replicaConsistencyQueue struct {
 	*baseQueue
 }
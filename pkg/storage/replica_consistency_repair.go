@@ -0,0 +1,121 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// ConsistencyCheckRepair causes the lease holder to remove the replicas which
+// disagree with the majority of their range when it detects a replication
+// consistency check failure. It takes precedence over
+// StoreConfig.ConsistencyCheckPanicOnFailure when a majority can be
+// determined. Exported for testing.
+var ConsistencyCheckRepair = settings.RegisterBoolSetting(
+	"kv.consistency_check.repair.enabled",
+	"set to true to remove the replicas which disagree with the majority of their range in a consistency check",
+	false,
+)
+
+// findInconsistentReplicas returns the checksum computed by a majority of
+// the replicas, and the indexes of the replicas which computed a different
+// one. checksums holds the checksum of each replica, or nil if it is
+// unknown. It returns false if no checksum was computed by a majority of the
+// replicas, in which case the inconsistent replicas can't be identified.
+func findInconsistentReplicas(checksums [][]byte) ([]byte, []int, bool) {
+	var majority []byte
+	for _, checksum := range checksums {
+		if checksum == nil {
+			continue
+		}
+		var count int
+		for _, other := range checksums {
+			if bytes.Equal(checksum, other) {
+				count++
+			}
+		}
+		if count > len(checksums)/2 {
+			majority = checksum
+			break
+		}
+	}
+	if majority == nil {
+		return nil, nil, false
+	}
+	var inconsistent []int
+	for i, checksum := range checksums {
+		if checksum != nil && !bytes.Equal(checksum, majority) {
+			inconsistent = append(inconsistent, i)
+		}
+	}
+	return majority, inconsistent, true
+}
+
+// repairInconsistentReplicas repairs the range after a consistency check
+// found some of its replicas to be inconsistent, by removing the replicas
+// which disagree with the majority of the range's replicas. The replicate
+// queue then adds new replicas, which are initialized from a snapshot of a
+// consistent replica. If the local replica is inconsistent, the lease is
+// transferred to a consistent replica instead, whose consistency checks will
+// remove the local replica. It returns false if the inconsistent replicas
+// couldn't be identified.
+func (r *Replica) repairInconsistentReplicas(
+	ctx context.Context, replicas []roachpb.ReplicaDescriptor, checksums [][]byte,
+) (bool, error) {
+	majority, inconsistent, ok := findInconsistentReplicas(checksums)
+	if !ok {
+		log.Errorf(ctx, "unable to repair inconsistent replicas: no majority of replicas agree on a checksum")
+		return false, nil
+	}
+	localReplica, err := r.GetReplicaDescriptor()
+	if err != nil {
+		return false, err
+	}
+	for _, i := range inconsistent {
+		if replicas[i] != localReplica {
+			continue
+		}
+		for j, checksum := range checksums {
+			if bytes.Equal(checksum, majority) {
+				log.Errorf(ctx, "local replica %s is inconsistent; transferring lease to %s",
+					localReplica, replicas[j])
+				return true, r.AdminTransferLease(replicas[j].StoreID)
+			}
+		}
+	}
+
+	for _, i := range inconsistent {
+		replica := replicas[i]
+		log.Errorf(ctx, "removing inconsistent replica %s", replica)
+		if err := r.ChangeReplicas(ctx, roachpb.REMOVE_REPLICA, replica, r.Desc()); err != nil {
+			return false, errors.Wrapf(err, "could not remove inconsistent replica %s", replica)
+		}
+		desc := *r.Desc()
+		if err := r.store.DB().Txn(ctx, func(txn *client.Txn) error {
+			return r.store.logRemoveInconsistentReplica(txn, replica, checksums[i], majority, desc)
+		}); err != nil {
+			log.Warningf(ctx, "unable to log removal of inconsistent replica %s: %s", replica, err)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestFindInconsistentReplicas(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+	testCases := []struct {
+		checksums    [][]byte
+		majority     []byte
+		inconsistent []int
+		ok           bool
+	}{
+		{[][]byte{a, a, a}, a, nil, true},
+		{[][]byte{a, b, a}, a, []int{1}, true},
+		{[][]byte{b, a, a}, a, []int{0}, true},
+		// Unknown checksums count against the majority, but the replicas they
+		// belong to aren't considered inconsistent.
+		{[][]byte{a, nil, a}, a, nil, true},
+		{[][]byte{a, nil, b}, nil, nil, false},
+		{[][]byte{a, b, c}, nil, nil, false},
+		{[][]byte{a, b}, nil, nil, false},
+		{[][]byte{a, b, a, c, a}, a, []int{1, 3}, true},
+		{[][]byte{a, b, b, a}, nil, nil, false},
+	}
+	for i, c := range testCases {
+		majority, inconsistent, ok := findInconsistentReplicas(c.checksums)
+		if ok != c.ok || !bytes.Equal(majority, c.majority) ||
+			!reflect.DeepEqual(inconsistent, c.inconsistent) {
+			t.Errorf("%d: expected (%q, %v, %t), but got (%q, %v, %t)",
+				i, c.majority, c.inconsistent, c.ok, majority, inconsistent, ok)
+		}
+	}
+}
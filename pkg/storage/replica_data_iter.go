@@ -17,6 +17,8 @@
 package storage
 
 import (
+	"bytes"
+
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
@@ -28,6 +30,11 @@ type keyRange struct {
 	start, end engine.MVCCKey
 }
 
+// contains returns whether the key falls within the key range.
+func (kr keyRange) contains(key engine.MVCCKey) bool {
+	return bytes.Compare(key.Key, kr.start.Key) >= 0 && bytes.Compare(key.Key, kr.end.Key) < 0
+}
+
 // ReplicaDataIterator provides a complete iteration over all key / value
 // rows in a range, including all system-local metadata and user data.
 // The ranges keyRange slice specifies the key ranges which comprise
@@ -48,6 +48,10 @@ type LocalProposalData struct {
 	idKey           storagebase.CmdIDKey
 	proposedAtTicks int
 	ctx             context.Context
+	// quotaSize is the amount of proposal quota acquired for this command,
+	// which is returned to the pool once all live followers have caught up
+	// to the command (or the command is abandoned).
+	quotaSize int64
 
 	// The error resulting from the proposal. Most failing proposals will
 	// fail-fast, i.e. will return an error to the client above Raft. However,
@@ -104,9 +108,10 @@ type LocalProposalData struct {
 //    it must run when the command has applied (such as resolving intents).
 type ProposalData struct {
 	LocalProposalData
-	MaxLeaseIndex uint64
-	OriginReplica roachpb.ReplicaDescriptor
-	Cmd           *roachpb.BatchRequest
+	MaxLeaseIndex   uint64
+	OriginReplica   roachpb.ReplicaDescriptor
+	ClosedTimestamp hlc.Timestamp
+	Cmd             *roachpb.BatchRequest
 	storagebase.ReplicatedProposalData
 	WriteBatch *storagebase.WriteBatch
 }
@@ -349,6 +354,10 @@ func (r *Replica) leasePostApply(
 		r.mu.Lock()
 		r.mu.tsCache.Clear(r.store.Clock().Now())
 		r.mu.Unlock()
+
+		// Pushes waiting on transactions whose records are held by this
+		// range are retried and will be redirected to the new lease holder.
+		r.pushTxnQueue.Clear()
 	}
 
 	if !iAmTheLeaseHolder && newLease.Covers(r.store.Clock().Now()) {
@@ -482,6 +491,12 @@ func (r *Replica) handleReplicatedProposalData(
 			// Our in-memory state has diverged from the on-disk state.
 			log.Fatalf(ctx, "failed to update store after merging range: %s", err)
 		}
+		if raftLog := r.store.raftLog; raftLog != nil {
+			// The merge removed the raft log of the right hand range.
+			if err := raftLog.Destroy(rpd.Merge.RightDesc.RangeID); err != nil {
+				log.Warningf(ctx, "unable to destroy raft log files of merged range: %s", err)
+			}
+		}
 		rpd.Merge = nil
 	}
 
@@ -542,6 +557,12 @@ func (r *Replica) handleReplicatedProposalData(
 		// Clear any entries in the Raft log entry cache for this range up
 		// to and including the most recently truncated index.
 		r.store.raftEntryCache.clearTo(r.RangeID, newTruncState.Index+1)
+		if raftLog := r.store.raftLog; raftLog != nil {
+			if err := raftLog.Truncate(r.RangeID, newTruncState.Index+1); err != nil {
+				// The entries are removed when the store is restarted.
+				log.Warningf(ctx, "unable to truncate raft log files: %s", err)
+			}
+		}
 	}
 
 	if newThresh := rpd.State.GCThreshold; newThresh != hlc.ZeroTimestamp {
@@ -582,6 +603,7 @@ func (r *Replica) handleLocalProposalData(
 		lpd.ctx = nil
 		lpd.Err = nil
 		lpd.proposedAtTicks = 0
+		lpd.quotaSize = 0
 		lpd.Reply = nil
 	}
 
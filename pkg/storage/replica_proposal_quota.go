@@ -0,0 +1,161 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"math"
+
+	"github.com/coreos/etcd/raft"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// proposalQuotaBytes is the size of the proposal quota pool maintained by
+// the Raft leader of each range. Proposals acquire quota proportional to
+// their size, and the quota is only returned once every live follower has
+// appended the proposal to its log. This bounds how far a slow follower
+// can fall behind the leader before writes to the range are blocked,
+// instead of letting it fall so far behind that it needs a snapshot.
+// Changes apply to each range the next time its leadership changes.
+var proposalQuotaBytes = settings.RegisterPositiveByteSizeSetting(
+	"kv.raft.proposal_quota",
+	"the size of the pool of proposal quota maintained by the raft leader of each range",
+	1<<20, /* 1 MB */
+)
+
+// quotaRelease records the proposal quota held by a locally proposed
+// command which has been applied but may not yet be replicated to all
+// followers.
+type quotaRelease struct {
+	index uint64
+	size  int64
+}
+
+// maybeAcquireProposalQuota blocks until size bytes of proposal quota are
+// available, if the replica is the Raft leader. It returns the amount of
+// quota acquired, which is returned to the pool once the proposal has been
+// replicated to all live followers.
+func (r *Replica) maybeAcquireProposalQuota(ctx context.Context, size int64) (int64, error) {
+	r.mu.Lock()
+	quotaPool := r.mu.proposalQuota
+	r.mu.Unlock()
+
+	// Only the Raft leader tracks the progress of followers, so the pool is
+	// nil on all other replicas.
+	if quotaPool == nil {
+		return 0, nil
+	}
+	if quotaPool.approximateQuota() < size {
+		log.Event(ctx, "waiting for proposal quota")
+	}
+	return quotaPool.acquire(ctx, size, r.store.Stopper().ShouldQuiesce())
+}
+
+// releaseProposalQuota returns quota acquired for a proposal which was not
+// successfully proposed to Raft.
+func (r *Replica) releaseProposalQuota(size int64) {
+	r.mu.Lock()
+	r.releaseProposalQuotaLocked(size)
+	r.mu.Unlock()
+}
+
+func (r *Replica) releaseProposalQuotaLocked(size int64) {
+	if r.mu.proposalQuota != nil {
+		r.mu.proposalQuota.add(size)
+	}
+}
+
+// updateProposalQuotaRaftMuLocked creates or closes the proposal quota
+// pool when the replica gains or loses Raft leadership and, while it is
+// the leader, returns the quota held by applied commands which all live
+// followers have appended to their logs.
+func (r *Replica) updateProposalQuotaRaftMuLocked(
+	ctx context.Context, lastLeaderID roachpb.ReplicaID,
+) {
+	r.mu.Lock()
+	if r.mu.leaderID != lastLeaderID {
+		if r.mu.leaderID != 0 && r.mu.replicaID == r.mu.leaderID {
+			// We became the leader. Any quota acquired under a previous
+			// leadership is forgotten.
+			r.mu.proposalQuota = newQuotaPool(proposalQuotaBytes.Get())
+		} else if r.mu.proposalQuota != nil {
+			// We lost leadership. Unblock any waiting proposals, which will
+			// be redirected to the new leader's lease holder as usual.
+			r.mu.proposalQuota.close()
+			r.mu.proposalQuota = nil
+		}
+		r.mu.quotaReleaseQueue = nil
+		r.mu.Unlock()
+		return
+	}
+	if r.mu.proposalQuota == nil || len(r.mu.quotaReleaseQueue) == 0 {
+		r.mu.Unlock()
+		return
+	}
+	status := r.raftStatusLocked()
+	replicas := r.mu.state.Desc.Replicas
+	replicaID := r.mu.replicaID
+	r.mu.Unlock()
+
+	if status == nil {
+		return
+	}
+
+	// Find the highest index which has been appended by every follower we
+	// are actively replicating to. Followers which are receiving a snapshot
+	// or are being probed will be caught up by other means, and followers on
+	// dead nodes would otherwise block writes to the range indefinitely.
+	minIndex := uint64(math.MaxUint64)
+	for _, rep := range replicas {
+		if rep.ReplicaID == replicaID {
+			continue
+		}
+		progress, ok := status.Progress[uint64(rep.ReplicaID)]
+		if !ok || progress.State != raft.ProgressStateReplicate {
+			continue
+		}
+		if nl := r.store.cfg.NodeLiveness; nl != nil {
+			if live, err := nl.IsLive(rep.NodeID); err != nil || !live {
+				continue
+			}
+		}
+		if progress.Match < minIndex {
+			minIndex = progress.Match
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mu.proposalQuota == nil {
+		return
+	}
+	var released int64
+	var n int
+	for ; n < len(r.mu.quotaReleaseQueue); n++ {
+		release := r.mu.quotaReleaseQueue[n]
+		if release.index > minIndex {
+			break
+		}
+		released += release.size
+	}
+	r.mu.quotaReleaseQueue = r.mu.quotaReleaseQueue[n:]
+	if released > 0 {
+		log.VEventf(ctx, 3, "releasing %d bytes of proposal quota (index %d)", released, minIndex)
+		r.mu.proposalQuota.add(released)
+	}
+}
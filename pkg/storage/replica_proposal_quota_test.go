@@ -0,0 +1,117 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// setLeaderForTesting makes the replica believe that leaderID is the Raft
+// leader and applies the resulting proposal quota changes.
+func setLeaderForTesting(r *Replica, leaderID roachpb.ReplicaID) {
+	r.raftMu.Lock()
+	defer r.raftMu.Unlock()
+	r.mu.Lock()
+	lastLeaderID := r.mu.leaderID
+	r.mu.leaderID = leaderID
+	r.mu.Unlock()
+	r.updateProposalQuotaRaftMuLocked(context.Background(), lastLeaderID)
+}
+
+// TestReplicaProposalQuota verifies that the Raft leader acquires proposal
+// quota for its proposals and gets it back once they're replicated, and
+// that the quota pool is dropped when the replica loses leadership and
+// recreated in full when it regains it.
+func TestReplicaProposalQuota(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	const quota = 1000
+	defer TestingSetProposalQuota(quota)()
+
+	tc := testContext{}
+	tc.Start(t)
+	defer tc.Stop()
+	repl := tc.repl
+
+	pArgs := putArgs(roachpb.Key("a"), []byte("value"))
+	if _, pErr := tc.SendWrapped(&pArgs); pErr != nil {
+		t.Fatal(pErr)
+	}
+	// The replica is the only one in the range, so the put's quota is
+	// released as soon as it's applied.
+	util.SucceedsSoon(t, func() error {
+		if n, ok := repl.ProposalQuota(); !ok || n != quota {
+			return errors.Errorf("expected %d bytes of quota, got %d (pool: %t)", quota, n, ok)
+		}
+		return nil
+	})
+
+	// Take most of the quota, so that a further acquisition blocks.
+	ctx := context.Background()
+	if n, err := repl.maybeAcquireProposalQuota(ctx, 800); err != nil || n != 800 {
+		t.Fatalf("expected to acquire 800 bytes, got %d, %v", n, err)
+	}
+	acquired := make(chan error, 1)
+	go func() {
+		n, err := repl.maybeAcquireProposalQuota(ctx, 500)
+		if err == nil && n != 0 {
+			err = errors.Errorf("expected to acquire no quota after losing leadership, got %d", n)
+		}
+		acquired <- err
+	}()
+	select {
+	case err := <-acquired:
+		t.Fatalf("acquired quota from an exhausted pool: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// Losing leadership unblocks the acquisition without handing out any
+	// quota, and followers don't acquire quota at all.
+	repl.mu.Lock()
+	replicaID := repl.mu.replicaID
+	repl.mu.quotaReleaseQueue = append(repl.mu.quotaReleaseQueue, quotaRelease{index: 1, size: 800})
+	repl.mu.Unlock()
+	setLeaderForTesting(repl, replicaID+1)
+	if err := <-acquired; err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := repl.ProposalQuota(); ok {
+		t.Fatalf("expected no quota pool on a follower, got one with %d bytes", n)
+	}
+	repl.mu.Lock()
+	queued := len(repl.mu.quotaReleaseQueue)
+	repl.mu.Unlock()
+	if queued != 0 {
+		t.Fatalf("expected the quota release queue to be cleared, got %d entries", queued)
+	}
+	if n, err := repl.maybeAcquireProposalQuota(ctx, 500); err != nil || n != 0 {
+		t.Fatalf("expected a follower to acquire no quota, got %d, %v", n, err)
+	}
+
+	// Regaining leadership starts over with a full pool; quota acquired
+	// under the previous leadership doesn't overfill it when released.
+	setLeaderForTesting(repl, replicaID)
+	repl.releaseProposalQuota(800)
+	if n, ok := repl.ProposalQuota(); !ok || n != quota {
+		t.Fatalf("expected %d bytes of quota, got %d (pool: %t)", quota, n, ok)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/raftlog"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -75,12 +76,13 @@ func (r *Replica) Entries(lo, hi, maxBytes uint64) ([]raftpb.Entry, error) {
 	snap := r.store.NewSnapshot()
 	defer snap.Close()
 	ctx := r.AnnotateCtx(context.TODO())
-	return entries(ctx, snap, r.RangeID, r.store.raftEntryCache, lo, hi, maxBytes)
+	return entries(ctx, snap, r.store.raftLog, r.RangeID, r.store.raftEntryCache, lo, hi, maxBytes)
 }
 
 func entries(
 	ctx context.Context,
 	e engine.Reader,
+	raftLog *raftlog.Log,
 	rangeID roachpb.RangeID,
 	eCache *raftEntryCache,
 	lo, hi, maxBytes uint64,
@@ -121,7 +123,7 @@ func entries(
 		return exceededMaxBytes, nil
 	}
 
-	if err := iterateEntries(ctx, e, rangeID, expectedIndex, hi, scanFunc); err != nil {
+	if err := iterateEntries(ctx, e, raftLog, rangeID, expectedIndex, hi, scanFunc); err != nil {
 		return nil, err
 	}
 	// Cache the fetched entries.
@@ -170,14 +172,26 @@ func entries(
 	return nil, raft.ErrUnavailable
 }
 
+// iterateEntries calls scanFunc with the Raft log entries of the range in
+// [lo, hi), which are read from the store's Raft log files if raftLog is
+// set and from the engine otherwise.
 func iterateEntries(
 	ctx context.Context,
 	e engine.Reader,
+	raftLog *raftlog.Log,
 	rangeID roachpb.RangeID,
 	lo,
 	hi uint64,
 	scanFunc func(roachpb.KeyValue) (bool, error),
 ) error {
+	if raftLog != nil {
+		return raftLog.Iterate(rangeID, lo, hi, func(index uint64, data []byte) (bool, error) {
+			return scanFunc(roachpb.KeyValue{
+				Key:   keys.RaftLogKey(rangeID, index),
+				Value: roachpb.MakeValueFromBytes(data),
+			})
+		})
+	}
 	_, err := engine.MVCCIterate(
 		ctx, e,
 		keys.RaftLogKey(rangeID, lo),
@@ -197,13 +211,18 @@ func (r *Replica) Term(i uint64) (uint64, error) {
 	snap := r.store.NewSnapshot()
 	defer snap.Close()
 	ctx := r.AnnotateCtx(context.TODO())
-	return term(ctx, snap, r.RangeID, r.store.raftEntryCache, i)
+	return term(ctx, snap, r.store.raftLog, r.RangeID, r.store.raftEntryCache, i)
 }
 
 func term(
-	ctx context.Context, eng engine.Reader, rangeID roachpb.RangeID, eCache *raftEntryCache, i uint64,
+	ctx context.Context,
+	eng engine.Reader,
+	raftLog *raftlog.Log,
+	rangeID roachpb.RangeID,
+	eCache *raftEntryCache,
+	i uint64,
 ) (uint64, error) {
-	ents, err := entries(ctx, eng, rangeID, eCache, i, i+1, 0)
+	ents, err := entries(ctx, eng, raftLog, rangeID, eCache, i, i+1, 0)
 	if err == raft.ErrCompacted {
 		ts, err := loadTruncatedState(ctx, eng, rangeID)
 		if err != nil {
@@ -312,7 +331,7 @@ func (r *Replica) SnapshotWithContext(ctx context.Context) (*OutgoingSnapshot, e
 	// Delegate to a static function to make sure that we do not depend
 	// on any indirect calls to r.store.Engine() (or other in-memory
 	// state of the Replica). Everything must come from the snapshot.
-	snapData, err := snapshot(ctx, snap, rangeID, r.store.raftEntryCache, startKey)
+	snapData, err := snapshot(ctx, snap, r.store.raftLog, rangeID, r.store.raftEntryCache, startKey)
 	if err != nil {
 		log.Errorf(ctx, "error generating snapshot: %s", err)
 		return nil, err
@@ -361,6 +380,9 @@ type OutgoingSnapshot struct {
 	RaftSnap raftpb.Snapshot
 	// The RocksDB snapshot that will be streamed from.
 	EngineSnap engine.Reader
+	// The store's raft log files, if it doesn't keep the raft log in the
+	// engine. Unlike EngineSnap, they aren't a consistent snapshot.
+	RaftLog *raftlog.Log
 	// The complete range iterator for the snapshot to stream.
 	Iter *ReplicaDataIterator
 	// True if a goroutine has scheduled a call to CloseOutSnap for this snap.
@@ -376,6 +398,9 @@ type IncomingSnapshot struct {
 	Batches [][]byte
 	// The Raft log entries for this snapshot.
 	LogEntries [][]byte
+	// If set, the snapshot's data was written into sstables as it was
+	// received instead of being held in Batches.
+	ssts *snapshotSSTWriter
 }
 
 // CloseOutSnap closes the Replica's outgoing snapshot, freeing its resources
@@ -395,6 +420,7 @@ func (r *Replica) CloseOutSnap() {
 func snapshot(
 	ctx context.Context,
 	snap engine.Reader,
+	raftLog *raftlog.Log,
 	rangeID roachpb.RangeID,
 	eCache *raftEntryCache,
 	startKey roachpb.RKey,
@@ -429,7 +455,7 @@ func snapshot(
 		cs.Nodes = append(cs.Nodes, uint64(rep.ReplicaID))
 	}
 
-	term, err := term(ctx, snap, rangeID, eCache, appliedIndex)
+	term, err := term(ctx, snap, raftLog, rangeID, eCache, appliedIndex)
 	if err != nil {
 		return OutgoingSnapshot{}, errors.Errorf("failed to fetch term of %d: %s", appliedIndex, err)
 	}
@@ -443,6 +469,7 @@ func snapshot(
 		snapUUID.Short(), appliedIndex)
 	return OutgoingSnapshot{
 		EngineSnap: snap,
+		RaftLog:    raftLog,
 		Iter:       iter,
 		SnapUUID:   snapUUID,
 		RaftSnap: raftpb.Snapshot{
@@ -460,6 +487,11 @@ func snapshot(
 // r.mu.lastIndex and r.mu.raftLogSize, and returns new values. We do this
 // rather than modifying them directly because these modifications need to be
 // atomic with the commit of the batch.
+//
+// If the store keeps the raft log in files, the entries are synced to them
+// right away, and only the new last index is written to the batch. The
+// previously appended entries which never committed are left in the files
+// until the caller removes them after committing the batch.
 func (r *Replica) append(
 	ctx context.Context,
 	batch engine.ReadWriter,
@@ -470,6 +502,18 @@ func (r *Replica) append(
 	if len(entries) == 0 {
 		return prevLastIndex, prevRaftLogSize, nil
 	}
+	if raftLog := r.store.raftLog; raftLog != nil {
+		delta, err := raftLog.Append(r.RangeID, entries)
+		if err != nil {
+			return 0, 0, err
+		}
+		lastIndex := entries[len(entries)-1].Index
+		if err := setLastIndex(ctx, batch, r.RangeID, lastIndex); err != nil {
+			return 0, 0, err
+		}
+		delta -= raftLog.Size(r.RangeID, lastIndex+1, prevLastIndex+1)
+		return lastIndex, prevRaftLogSize + delta, nil
+	}
 	var diff enginepb.MVCCStats
 	var value roachpb.Value
 	for i := range entries {
@@ -581,15 +625,90 @@ func (r *Replica) applySnapshot(
 		snapType = "Raft"
 	}
 
+	var numSSTs int
+	if inSnap.ssts != nil {
+		numSSTs = inSnap.ssts.numFiles()
+	}
 	log.Infof(ctx, "applying %s snapshot at index %d "+
-		"(id=%s, encoded size=%d, %d rocksdb batches, %d log entries)",
+		"(id=%s, encoded size=%d, %d rocksdb batches, %d sstables, %d log entries)",
 		snapType, snap.Metadata.Index, inSnap.SnapUUID.Short(),
-		len(snap.Data), len(inSnap.Batches), len(inSnap.LogEntries))
+		len(snap.Data), len(inSnap.Batches), numSSTs, len(inSnap.LogEntries))
 	defer func(start time.Time) {
 		log.Infof(ctx, "applied %s snapshot in %.3fs",
 			snapType, timeutil.Since(start).Seconds())
 	}(timeutil.Now())
 
+	var s storagebase.ReplicaState
+	var err error
+	if inSnap.ssts != nil {
+		s, raftLogSize, err = r.applySnapshotSSTs(ctx, inSnap, snap, hs, raftLogSize)
+	} else {
+		s, raftLogSize, err = r.applySnapshotBatches(ctx, inSnap, snap, hs, raftLogSize)
+	}
+	if err != nil {
+		return err
+	}
+	if raftLog := r.store.raftLog; raftLog != nil {
+		// Remove the entries which were replaced by the snapshot now that the
+		// new raft log bounds are persisted.
+		if err := raftLog.Truncate(r.RangeID, s.TruncatedState.Index+1); err != nil {
+			return err
+		}
+		if err := raftLog.TruncateAfter(r.RangeID, s.RaftAppliedIndex); err != nil {
+			return err
+		}
+		raftLogSize = raftLog.Size(r.RangeID, 0, s.RaftAppliedIndex+1)
+	}
+
+	r.mu.Lock()
+	// We set the persisted last index to the last applied index. This is
+	// not a correctness issue, but means that we may have just transferred
+	// some entries we're about to re-request from the leader and overwrite.
+	// However, raft.MultiNode currently expects this behaviour, and the
+	// performance implications are not likely to be drastic. If our
+	// feelings about this ever change, we can add a LastIndex field to
+	// raftpb.SnapshotMetadata.
+	r.mu.lastIndex = s.RaftAppliedIndex
+	r.mu.raftLogSize = raftLogSize
+	// Update the range and store stats.
+	r.store.metrics.subtractMVCCStats(r.mu.state.Stats)
+	r.store.metrics.addMVCCStats(s.Stats)
+	r.mu.state = s
+	r.assertStateLocked(r.store.Engine())
+	r.mu.Unlock()
+
+	// The snapshot replaced the data of the range wholesale, without telling
+	// the RangeFeeds which keys changed. They must be re-established.
+	r.rangeFeeds.disconnect(roachpb.NewError(roachpb.NewRangeNotFoundError(r.RangeID)))
+
+	// As the last deferred action after committing the batch, update other
+	// fields which are uninitialized or need updating. This may not happen
+	// if the system config has not yet been loaded. While config update
+	// will correctly set the fields, there is no order guarantee in
+	// ApplySnapshot.
+	// TODO: should go through the standard store lock when adding a replica.
+	if err := r.updateRangeInfo(&desc); err != nil {
+		panic(err)
+	}
+
+	r.setDescWithoutProcessUpdate(&desc)
+
+	appliedSuccessfully = true
+	return nil
+}
+
+// applySnapshotBatches clears the range's existing data and writes the
+// snapshot's data, Raft log and HardState through a single batch. Returns
+// the new ReplicaState and Raft log size.
+func (r *Replica) applySnapshotBatches(
+	ctx context.Context,
+	inSnap IncomingSnapshot,
+	snap raftpb.Snapshot,
+	hs raftpb.HardState,
+	raftLogSize int64,
+) (storagebase.ReplicaState, int64, error) {
+	desc := inSnap.RangeDescriptor
+
 	batch := r.store.Engine().NewBatch()
 	defer batch.Close()
 
@@ -604,7 +723,7 @@ func (r *Replica) applySnapshot(
 	defer iter.Close()
 	for ; iter.Valid(); iter.Next() {
 		if err := distinctBatch.Clear(iter.Key()); err != nil {
-			return err
+			return storagebase.ReplicaState{}, 0, err
 		}
 	}
 
@@ -613,7 +732,7 @@ func (r *Replica) applySnapshot(
 	// Write the snapshot into the range.
 	for _, batchRepr := range inSnap.Batches {
 		if err := batch.ApplyBatchRepr(batchRepr); err != nil {
-			return err
+			return storagebase.ReplicaState{}, 0, err
 		}
 	}
 
@@ -621,21 +740,19 @@ func (r *Replica) applySnapshot(
 	// distinct batch.
 	distinctBatch = batch.Distinct()
 
-	logEntries := make([]raftpb.Entry, len(inSnap.LogEntries))
-	for i, bytes := range inSnap.LogEntries {
-		if err := logEntries[i].Unmarshal(bytes); err != nil {
-			return err
-		}
+	logEntries, err := decodeSnapshotLogEntries(inSnap.LogEntries)
+	if err != nil {
+		return storagebase.ReplicaState{}, 0, err
 	}
 	// Write the snapshot's Raft log into the range.
-	_, raftLogSize, err := r.append(ctx, distinctBatch, 0, raftLogSize, logEntries)
+	_, raftLogSize, err = r.append(ctx, distinctBatch, 0, raftLogSize, logEntries)
 	if err != nil {
-		return err
+		return storagebase.ReplicaState{}, 0, err
 	}
 
 	if !raft.IsEmptyHardState(hs) {
 		if err := setHardState(ctx, distinctBatch, r.RangeID, hs); err != nil {
-			return errors.Wrapf(err, "unable to persist HardState %+v", &hs)
+			return storagebase.ReplicaState{}, 0, errors.Wrapf(err, "unable to persist HardState %+v", &hs)
 		}
 	} else {
 		// Note that we don't require that Raft supply us with a nonempty
@@ -652,55 +769,42 @@ func (r *Replica) applySnapshot(
 
 	s, err := loadState(ctx, batch, &desc)
 	if err != nil {
-		return err
+		return storagebase.ReplicaState{}, 0, err
+	}
+
+	r.verifySnapshotState(ctx, s, snap)
+
+	if err := batch.Commit(); err != nil {
+		return storagebase.ReplicaState{}, 0, err
 	}
+	return s, raftLogSize, nil
+}
 
+// verifySnapshotState checks the ReplicaState resulting from applying the
+// snapshot.
+func (r *Replica) verifySnapshotState(
+	ctx context.Context, s storagebase.ReplicaState, snap raftpb.Snapshot,
+) {
 	if s.Desc.RangeID != r.RangeID {
 		log.Fatalf(ctx, "unexpected range ID %d", s.Desc.RangeID)
 	}
 
-	// As outlined above, last and applied index are the same after applying
-	// the snapshot (i.e. the snapshot has no uncommitted tail).
+	// The last and applied index are the same after applying the snapshot
+	// (i.e. the snapshot has no uncommitted tail).
 	if s.RaftAppliedIndex != snap.Metadata.Index {
 		log.Fatalf(ctx, "snapshot RaftAppliedIndex %d doesn't match its metadata index %d",
 			s.RaftAppliedIndex, snap.Metadata.Index)
 	}
+}
 
-	if err := batch.Commit(); err != nil {
-		return err
-	}
-
-	r.mu.Lock()
-	// We set the persisted last index to the last applied index. This is
-	// not a correctness issue, but means that we may have just transferred
-	// some entries we're about to re-request from the leader and overwrite.
-	// However, raft.MultiNode currently expects this behaviour, and the
-	// performance implications are not likely to be drastic. If our
-	// feelings about this ever change, we can add a LastIndex field to
-	// raftpb.SnapshotMetadata.
-	r.mu.lastIndex = s.RaftAppliedIndex
-	r.mu.raftLogSize = raftLogSize
-	// Update the range and store stats.
-	r.store.metrics.subtractMVCCStats(r.mu.state.Stats)
-	r.store.metrics.addMVCCStats(s.Stats)
-	r.mu.state = s
-	r.assertStateLocked(r.store.Engine())
-	r.mu.Unlock()
-
-	// As the last deferred action after committing the batch, update other
-	// fields which are uninitialized or need updating. This may not happen
-	// if the system config has not yet been loaded. While config update
-	// will correctly set the fields, there is no order guarantee in
-	// ApplySnapshot.
-	// TODO: should go through the standard store lock when adding a replica.
-	if err := r.updateRangeInfo(&desc); err != nil {
-		panic(err)
+func decodeSnapshotLogEntries(encoded [][]byte) ([]raftpb.Entry, error) {
+	logEntries := make([]raftpb.Entry, len(encoded))
+	for i, bytes := range encoded {
+		if err := logEntries[i].Unmarshal(bytes); err != nil {
+			return nil, err
+		}
 	}
-
-	r.setDescWithoutProcessUpdate(&desc)
-
-	appliedSuccessfully = true
-	return nil
+	return logEntries, nil
 }
 
 // Raft commands are encoded with a 1-byte version (currently 0), an 8-byte ID,
@@ -0,0 +1,421 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// rangeFeedPollInterval is the interval at which a RangeFeed looks at the
+// keys it couldn't resolve yet, and checks whether its span is due to be
+// resolved in the absence of writes.
+var rangeFeedPollInterval = settings.RegisterPositiveDurationSetting(
+	"kv.rangefeed.poll_interval",
+	"the interval at which rangefeeds look at the keys they couldn't resolve yet",
+	time.Second,
+)
+
+// rangeFeedResolveLag is how far behind the current time a RangeFeed
+// resolves its span. Resolving a timestamp forces writes at or below it
+// to be pushed, so the lag keeps feeds from interfering with all but
+// long-running transactions. Transactions with intents in the span are
+// never pushed, since the span isn't resolved past them.
+var rangeFeedResolveLag = settings.RegisterNonNegativeDurationSetting(
+	"kv.rangefeed.resolve_lag",
+	"how far behind the current time rangefeeds resolve their spans",
+	time.Second,
+)
+
+// rangeFeedIdleResolveInterval is how far the resolved timestamp of a
+// RangeFeed whose span isn't written to may fall behind before it is
+// resolved again. A span which is written to is resolved as soon as the
+// writes have committed, since the feed's consumer needs the resolved
+// timestamp to make use of them; an idle span is only resolved so that
+// consumers of feeds spanning several ranges make progress.
+var rangeFeedIdleResolveInterval = settings.RegisterPositiveDurationSetting(
+	"kv.rangefeed.idle_resolve_interval",
+	"how far behind rangefeeds let the resolved timestamp of spans without writes fall "+
+		"before resolving them again",
+	10*time.Second,
+)
+
+// RangeFeedEventSink is the destination of the events emitted by a
+// RangeFeed. It is implemented by roachpb.Internal_RangeFeedServer.
+type RangeFeedEventSink interface {
+	Context() context.Context
+	Send(*roachpb.RangeFeedEvent) error
+}
+
+// rangeFeedRegistration is a RangeFeed registered with a replica.
+type rangeFeedRegistration struct {
+	span roachpb.Span
+	// notifyC receives a value when keys are marked as dirty.
+	notifyC chan struct{}
+
+	// The fields below are protected by rangeFeedRegistry.mu.
+	//
+	// dirty holds the keys of the span which were written by the commands
+	// applied since the feed last took them.
+	dirty map[string]struct{}
+	// err, once set, terminates the feed.
+	err *roachpb.Error
+}
+
+// rangeFeedRegistry tracks the RangeFeeds served by a replica. Every
+// command applied to the replica marks the keys it wrote as dirty in the
+// feeds whose span contains them, so that a feed only has to look at the
+// keys written since it last caught up instead of scanning its span.
+type rangeFeedRegistry struct {
+	// count is the number of registered feeds. It is read atomically when
+	// commands are applied, so that their write batches are only decoded
+	// while feeds are registered.
+	count int32
+
+	mu struct {
+		syncutil.Mutex
+		feeds map[*rangeFeedRegistration]struct{}
+	}
+}
+
+func (reg *rangeFeedRegistry) register(span roachpb.Span) *rangeFeedRegistration {
+	feed := &rangeFeedRegistration{
+		span:    span,
+		notifyC: make(chan struct{}, 1),
+		dirty:   make(map[string]struct{}),
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if reg.mu.feeds == nil {
+		reg.mu.feeds = make(map[*rangeFeedRegistration]struct{})
+	}
+	reg.mu.feeds[feed] = struct{}{}
+	atomic.AddInt32(&reg.count, 1)
+	return feed
+}
+
+func (reg *rangeFeedRegistry) unregister(feed *rangeFeedRegistration) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	delete(reg.mu.feeds, feed)
+	atomic.AddInt32(&reg.count, -1)
+}
+
+// hasDirty returns whether keys of the feed were marked as dirty since the
+// last call to takeDirty, or the feed was terminated by an error.
+func (reg *rangeFeedRegistry) hasDirty(feed *rangeFeedRegistration) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return len(feed.dirty) > 0 || feed.err != nil
+}
+
+// takeDirty returns the keys of the feed marked as dirty since the last
+// call, or the error which terminated the feed.
+func (reg *rangeFeedRegistry) takeDirty(
+	feed *rangeFeedRegistration,
+) (map[string]struct{}, *roachpb.Error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if feed.err != nil {
+		return nil, feed.err
+	}
+	dirty := feed.dirty
+	feed.dirty = make(map[string]struct{})
+	return dirty, nil
+}
+
+// notify marks the keys written by a command's write batch as dirty in the
+// feeds whose span contains them.
+func (reg *rangeFeedRegistry) notify(ctx context.Context, writeBatch *storagebase.WriteBatch) {
+	if atomic.LoadInt32(&reg.count) == 0 || writeBatch == nil {
+		return
+	}
+	reader, err := engine.NewRocksDBBatchReader(writeBatch.Data)
+	if err != nil {
+		reg.disconnect(roachpb.NewError(err))
+		return
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for reader.Next() {
+		key := reader.Key().Key
+		for feed := range reg.mu.feeds {
+			if key.Compare(feed.span.Key) < 0 || key.Compare(feed.span.EndKey) >= 0 {
+				continue
+			}
+			feed.dirty[string(key)] = struct{}{}
+			select {
+			case feed.notifyC <- struct{}{}:
+			default:
+			}
+		}
+	}
+	if err := reader.Error(); err != nil {
+		log.Warningf(ctx, "unable to decode write batch for rangefeeds: %s", err)
+		reg.disconnectLocked(roachpb.NewError(err))
+	}
+}
+
+// disconnect terminates all the registered feeds with the given error.
+func (reg *rangeFeedRegistry) disconnect(pErr *roachpb.Error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.disconnectLocked(pErr)
+}
+
+func (reg *rangeFeedRegistry) disconnectLocked(pErr *roachpb.Error) {
+	for feed := range reg.mu.feeds {
+		if feed.err == nil {
+			feed.err = pErr
+		}
+		select {
+		case feed.notifyC <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// RangeFeed streams the writes committed to the span of the request at
+// timestamps above the request's timestamp to the supplied sink, along
+// with checkpoints which resolve the span up to a timestamp. The feed
+// is served by the lease holder and runs until the sink's context is
+// canceled or an error occurs, which is returned. Callers are expected
+// to handle NotLeaseHolderError, RangeKeyMismatchError and
+// RangeNotFoundError by re-establishing the feed where appropriate,
+// starting at the last resolved timestamp.
+//
+// The span is scanned once when the feed starts. After that, the feed
+// only looks at the keys written by the commands applied to the replica
+// since, and at the keys which it couldn't resolve yet. Since resolving
+// the span pushes writers, it is only resolved when there are such keys,
+// or when its resolved timestamp has fallen behind by the idle resolve
+// interval.
+func (r *Replica) RangeFeed(
+	args *roachpb.RangeFeedRequest, stream RangeFeedEventSink,
+) *roachpb.Error {
+	ctx := r.AnnotateCtx(stream.Context())
+	from := args.Timestamp
+
+	feed := r.rangeFeeds.register(args.Span)
+	defer r.rangeFeeds.unregister(feed)
+
+	var timer timeutil.Timer
+	defer timer.Stop()
+	// pending are the keys which must be looked at again, or nil until the
+	// span has been scanned.
+	var pending map[string]struct{}
+	for {
+		readTS := r.store.Clock().Now().Add(-rangeFeedResolveLag.Get().Nanoseconds(), 0)
+		needsResolve := pending == nil || len(pending) > 0 || r.rangeFeeds.hasDirty(feed) ||
+			from.Add(rangeFeedIdleResolveInterval.Get().Nanoseconds(), 0).Less(readTS)
+		if needsResolve && from.Less(readTS) {
+			vals, resolved, newPending, pErr := r.rangeFeedPoll(ctx, feed, from, readTS, pending)
+			if pErr != nil {
+				return pErr
+			}
+			pending = newPending
+			for i := range vals {
+				if err := stream.Send(&roachpb.RangeFeedEvent{Val: &vals[i]}); err != nil {
+					return roachpb.NewError(err)
+				}
+			}
+			if from.Less(resolved) {
+				from = resolved
+				if err := stream.Send(&roachpb.RangeFeedEvent{
+					Checkpoint: &roachpb.RangeFeedCheckpoint{Span: args.Span, ResolvedTS: resolved},
+				}); err != nil {
+					return roachpb.NewError(err)
+				}
+			}
+		}
+
+		timer.Reset(rangeFeedPollInterval.Get())
+		select {
+		case <-timer.C:
+			timer.Read = true
+		case <-feed.notifyC:
+		case <-ctx.Done():
+			return roachpb.NewError(ctx.Err())
+		case <-r.store.Stopper().ShouldQuiesce():
+			return roachpb.NewError(&roachpb.NodeUnavailableError{})
+		}
+	}
+}
+
+// rangeFeedPoll returns the values committed to the span of the feed at
+// timestamps in (from, resolved], where resolved is the timestamp up to
+// which the span has been resolved, sorted by timestamp. It only looks at
+// the pending keys and the keys marked as dirty since the last poll,
+// unless pending is nil, in which case it scans the whole span. It also
+// returns the keys which must be looked at by the next poll: those with
+// intents or values above the resolved timestamp.
+//
+// The span is read like a consistent scan at readTS: the poll waits for
+// overlapping commands in flight, whose keys are then marked as dirty.
+// Writes at or below readTS which haven't been resolved yet are still
+// present as intents, so the span is only resolved up to just below the
+// oldest intent. The read is recorded in the timestamp cache at the
+// resolved timestamp, which forces all future writes to the span above
+// it, but leaves the transactions which wrote the intents alone.
+func (r *Replica) rangeFeedPoll(
+	ctx context.Context,
+	feed *rangeFeedRegistration,
+	from, readTS hlc.Timestamp,
+	pending map[string]struct{},
+) (
+	vals []roachpb.RangeFeedValue,
+	resolved hlc.Timestamp,
+	newPending map[string]struct{},
+	pErr *roachpb.Error,
+) {
+	span := feed.span
+	var ba roachpb.BatchRequest
+	ba.RangeID = r.RangeID
+	ba.Timestamp = readTS
+	ba.Add(&roachpb.ScanRequest{Span: span})
+
+	if pErr := r.redirectOnOrAcquireLease(ctx); pErr != nil {
+		return nil, hlc.ZeroTimestamp, nil, pErr
+	}
+	endCmdsFunc, err := r.beginCmds(ctx, &ba)
+	if err != nil {
+		return nil, hlc.ZeroTimestamp, nil, roachpb.NewError(err)
+	}
+
+	r.readOnlyCmdMu.RLock()
+	defer r.readOnlyCmdMu.RUnlock()
+	defer func() {
+		pErr = endCmdsFunc(nil, pErr, false)
+	}()
+
+	r.mu.Lock()
+	err = r.mu.destroyed
+	r.mu.Unlock()
+	if err != nil {
+		return nil, hlc.ZeroTimestamp, nil, roachpb.NewError(err)
+	}
+	if !r.ContainsKeyRange(span.Key, span.EndKey) {
+		return nil, hlc.ZeroTimestamp, nil, roachpb.NewError(
+			roachpb.NewRangeKeyMismatchError(span.Key, span.EndKey, r.Desc()))
+	}
+
+	// The commands which were in flight have applied by now, so the keys
+	// they wrote are dirty.
+	dirty, pErr := r.rangeFeeds.takeDirty(feed)
+	if pErr != nil {
+		return nil, hlc.ZeroTimestamp, nil, pErr
+	}
+
+	resolved = readTS
+	newPending = make(map[string]struct{})
+	iter := r.store.Engine().NewIterator(false)
+	defer iter.Close()
+	// visit reads the versions of the keys in [start, end).
+	visit := func(start, end roachpb.Key) error {
+		endKey := engine.MakeMVCCMetadataKey(end)
+		for iter.Seek(engine.MakeMVCCMetadataKey(start)); iter.Valid(); iter.Next() {
+			key := iter.Key()
+			if !key.Less(endKey) {
+				break
+			}
+			if !key.IsValue() {
+				var meta enginepb.MVCCMetadata
+				if err := iter.ValueProto(&meta); err != nil {
+					return err
+				}
+				if meta.Txn != nil {
+					newPending[string(key.Key)] = struct{}{}
+					if !resolved.Less(meta.Timestamp) {
+						resolved = meta.Timestamp.Prev()
+					}
+				}
+				continue
+			}
+			if readTS.Less(key.Timestamp) {
+				newPending[string(key.Key)] = struct{}{}
+				continue
+			}
+			if !from.Less(key.Timestamp) {
+				continue
+			}
+			vals = append(vals, roachpb.RangeFeedValue{
+				Key: append(roachpb.Key(nil), key.Key...),
+				Value: roachpb.Value{
+					RawBytes:  append([]byte(nil), iter.Value()...),
+					Timestamp: key.Timestamp,
+				},
+			})
+		}
+		return iter.Error()
+	}
+	if pending == nil {
+		err = visit(span.Key, span.EndKey)
+	} else {
+		for key := range dirty {
+			pending[key] = struct{}{}
+		}
+		for key := range pending {
+			k := roachpb.Key(key)
+			if err = visit(k, k.Next()); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, hlc.ZeroTimestamp, nil, roachpb.NewError(err)
+	}
+
+	// Only promise not to accept writes at or below the resolved timestamp.
+	ba.Timestamp = resolved
+
+	// Intents are only discovered while iterating, so values of keys
+	// visited before the oldest intent may still be above the resolved
+	// timestamp. They are emitted by a later poll.
+	filtered := vals[:0]
+	for _, val := range vals {
+		if resolved.Less(val.Value.Timestamp) {
+			newPending[string(val.Key)] = struct{}{}
+			continue
+		}
+		filtered = append(filtered, val)
+	}
+	vals = filtered
+	sort.Stable(rangeFeedValuesByTimestamp(vals))
+	if len(vals) > 0 {
+		log.VEventf(ctx, 2, "rangefeed emitting %d values, resolved to %s", len(vals), resolved)
+	}
+	return vals, resolved, newPending, nil
+}
+
+type rangeFeedValuesByTimestamp []roachpb.RangeFeedValue
+
+func (v rangeFeedValuesByTimestamp) Len() int      { return len(v) }
+func (v rangeFeedValuesByTimestamp) Swap(i, j int) { v[i], v[j] = v[j], v[i] }
+func (v rangeFeedValuesByTimestamp) Less(i, j int) bool {
+	return v[i].Value.Timestamp.Less(v[j].Value.Timestamp)
+}
@@ -0,0 +1,352 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// enableSnapshotSSTIngestion controls whether incoming snapshots are
+// written into sstables as they are received and ingested into the store's
+// engine, instead of being buffered in memory and applied through a batch.
+// Ingestion avoids stalling the store's write path while the snapshot's
+// data is written. It is read when a snapshot is received.
+var enableSnapshotSSTIngestion = settings.RegisterBoolSetting(
+	"kv.snapshot_sst.ingestion.enabled",
+	"set to false to apply incoming snapshots through a batch instead of ingesting them as sstables",
+	true,
+)
+
+// snapshotSSTDirName is the directory within the engine's auxiliary
+// directory in which the sstables of incoming snapshots are written.
+const snapshotSSTDirName = "snapshots"
+
+// snapshotSSTDir returns the directory in which the sstables of incoming
+// snapshots are written, or the empty string if the store's engine doesn't
+// support ingesting them.
+func (s *Store) snapshotSSTDir() string {
+	ingester, ok := s.engine.(engine.SSTIngester)
+	if !ok || ingester.AuxiliaryDir() == "" {
+		return ""
+	}
+	return filepath.Join(ingester.AuxiliaryDir(), snapshotSSTDirName)
+}
+
+// removeSnapshotSSTs removes the sstables of all incoming snapshots. It
+// must only be called when no snapshots are being received.
+func (s *Store) removeSnapshotSSTs() error {
+	if dir := s.snapshotSSTDir(); dir != "" {
+		return os.RemoveAll(dir)
+	}
+	return nil
+}
+
+// shouldIngestSnapshot returns whether the data of incoming snapshots
+// should be written into sstables and ingested. Both preemptive and Raft
+// snapshots qualify; the existing data of the range, if any, is removed
+// before the sstables are ingested (see applySnapshotSSTs).
+func (s *Store) shouldIngestSnapshot() bool {
+	return enableSnapshotSSTIngestion.Get() && s.snapshotSSTDir() != ""
+}
+
+// isRangeDataEmpty returns whether the reader holds no data, including
+// unreplicated data, for the range.
+func isRangeDataEmpty(desc *roachpb.RangeDescriptor, reader engine.Reader) bool {
+	iter := NewReplicaDataIterator(desc, reader, false /* !replicatedOnly */)
+	defer iter.Close()
+	return !iter.Valid()
+}
+
+// clearRangeData removes all the data, including unreplicated data, of
+// the range before the data of a snapshot is ingested, except for the term
+// and vote of its HardState, which Raft must not forget even if the node
+// crashes before the snapshot has been applied. The commit index is reset,
+// as the entries it refers to are removed. The caller must hold the
+// replica's raftMu, which serializes all writes to the range's keys.
+func clearRangeData(ctx context.Context, desc *roachpb.RangeDescriptor, eng engine.Engine) error {
+	hs, err := loadHardState(ctx, eng, desc.RangeID)
+	if err != nil {
+		return err
+	}
+	batch := eng.NewBatch()
+	defer batch.Close()
+	iter := NewReplicaDataIterator(desc, eng, false /* !replicatedOnly */)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		if err := batch.Clear(iter.Key()); err != nil {
+			return err
+		}
+	}
+	if !raft.IsEmptyHardState(hs) {
+		if err := setHardState(ctx, batch, desc.RangeID, raftpb.HardState{
+			Term: hs.Term,
+			Vote: hs.Vote,
+		}); err != nil {
+			return err
+		}
+	}
+	return batch.Commit()
+}
+
+// snapshotSSTWriter writes the replicated data of an incoming snapshot into
+// sstables which are ingested when the snapshot is applied. The data is
+// split into one sstable per replicated key range of the range (see
+// makeReplicatedKeyRanges), so that the sstables overlap neither each other
+// nor the data of other ranges.
+type snapshotSSTWriter struct {
+	eng    engine.SSTIngester
+	dir    string
+	ranges []keyRange
+
+	// paths holds the path of the sstable for each of the key ranges, or
+	// the empty string if the snapshot has no data in the key range.
+	paths []string
+	// cur is the writer for the sstable of the key range at index curIndex
+	// which is being written, if any.
+	cur      *engine.RocksDBSstFileWriter
+	curIndex int
+}
+
+// newSnapshotSSTWriter creates a snapshotSSTWriter which writes sstables
+// for the given engine into a new directory within parent.
+func newSnapshotSSTWriter(
+	eng engine.SSTIngester, parent string, desc *roachpb.RangeDescriptor,
+) (*snapshotSSTWriter, error) {
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return nil, err
+	}
+	dir, err := ioutil.TempDir(parent, fmt.Sprintf("r%d-", desc.RangeID))
+	if err != nil {
+		return nil, err
+	}
+	ranges := makeReplicatedKeyRanges(desc)
+	return &snapshotSSTWriter{
+		eng:    eng,
+		dir:    dir,
+		ranges: ranges,
+		paths:  make([]string, len(ranges)),
+	}, nil
+}
+
+// addBatch writes the contents of a BatchRepr received as part of the
+// snapshot. The batches of a snapshot consist of puts of the range's
+// replicated data in key order.
+func (w *snapshotSSTWriter) addBatch(repr []byte) error {
+	r, err := engine.NewRocksDBBatchReader(repr)
+	if err != nil {
+		return err
+	}
+	for r.Next() {
+		if r.BatchType() != engine.BatchTypeValue {
+			return errors.Errorf("unexpected batch entry type %d in snapshot", r.BatchType())
+		}
+		if err := w.add(engine.MVCCKeyValue{Key: r.Key(), Value: r.Value()}); err != nil {
+			return err
+		}
+	}
+	return r.Error()
+}
+
+func (w *snapshotSSTWriter) add(kv engine.MVCCKeyValue) error {
+	if w.cur == nil || !w.ranges[w.curIndex].contains(kv.Key) {
+		if err := w.finishFile(); err != nil {
+			return err
+		}
+		w.curIndex = -1
+		for i := range w.ranges {
+			if w.ranges[i].contains(kv.Key) {
+				w.curIndex = i
+				break
+			}
+		}
+		if w.curIndex == -1 {
+			return errors.Errorf("snapshot contains key %s outside of the range", kv.Key)
+		}
+		if w.paths[w.curIndex] != "" {
+			return errors.Errorf("snapshot contains out of order key %s", kv.Key)
+		}
+		path := filepath.Join(w.dir, fmt.Sprintf("%d.sst", w.curIndex))
+		if err := w.openFile(path); err != nil {
+			return err
+		}
+		w.paths[w.curIndex] = path
+	}
+	return w.cur.Add(kv)
+}
+
+func (w *snapshotSSTWriter) openFile(path string) error {
+	fw := w.eng.NewSstFileWriter()
+	if err := fw.Open(path); err != nil {
+		_ = fw.Close()
+		return err
+	}
+	w.cur = &fw
+	return nil
+}
+
+func (w *snapshotSSTWriter) finishFile() error {
+	if w.cur == nil {
+		return nil
+	}
+	err := w.cur.Close()
+	w.cur = nil
+	return err
+}
+
+// ingest atomically ingests the snapshot's sstables, along with an
+// sstable holding the range's unreplicated keys as read from the given
+// reader. Writing the Raft log and HardState together with the data
+// ensures that a crash can't leave the store with the data of the snapshot
+// but without the Raft state required to apply it.
+func (w *snapshotSSTWriter) ingest(rangeID roachpb.RangeID, unreplicated engine.Reader) error {
+	if err := w.finishFile(); err != nil {
+		return err
+	}
+
+	prefix := keys.MakeRangeIDUnreplicatedPrefix(rangeID)
+	unreplicatedPath := filepath.Join(w.dir, "unreplicated.sst")
+	if err := unreplicated.Iterate(
+		engine.MakeMVCCMetadataKey(prefix),
+		engine.MakeMVCCMetadataKey(prefix.PrefixEnd()),
+		func(kv engine.MVCCKeyValue) (bool, error) {
+			if w.cur == nil {
+				if err := w.openFile(unreplicatedPath); err != nil {
+					return false, err
+				}
+			}
+			return false, w.cur.Add(kv)
+		},
+	); err != nil {
+		return err
+	}
+	if w.cur == nil {
+		unreplicatedPath = ""
+	} else if err := w.finishFile(); err != nil {
+		return err
+	}
+
+	// The unreplicated keys sort after the replicated range ID keys and
+	// before all other keys of the range.
+	var paths []string
+	for i, path := range w.paths {
+		if i == 1 && unreplicatedPath != "" {
+			paths = append(paths, unreplicatedPath)
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return w.eng.IngestExternalFiles(paths, true /* move */)
+}
+
+// numFiles returns the number of sstables written so far.
+func (w *snapshotSSTWriter) numFiles() int {
+	var n int
+	for _, path := range w.paths {
+		if path != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// close removes the snapshot's sstables. Ingested sstables are unaffected,
+// as they've been linked into the engine's directory.
+func (w *snapshotSSTWriter) close() {
+	if w.cur != nil {
+		_ = w.cur.Close()
+		w.cur = nil
+	}
+	if err := os.RemoveAll(w.dir); err != nil {
+		log.Warningf(context.TODO(), "unable to remove snapshot sstables in %s: %s", w.dir, err)
+	}
+}
+
+// applySnapshotSSTs writes the snapshot's Raft log and HardState and
+// ingests them together with the sstables the snapshot's data was written
+// into as it was received. Returns the new ReplicaState and Raft log size.
+//
+// Unlike applySnapshotBatches, the snapshot isn't applied atomically: the
+// existing data of the range, if any, is removed first (see
+// clearRangeData). A node which crashes in between restarts with an
+// uninitialized replica, which will receive another snapshot.
+func (r *Replica) applySnapshotSSTs(
+	ctx context.Context,
+	inSnap IncomingSnapshot,
+	snap raftpb.Snapshot,
+	hs raftpb.HardState,
+	raftLogSize int64,
+) (storagebase.ReplicaState, int64, error) {
+	desc := inSnap.RangeDescriptor
+	eng := r.store.Engine()
+
+	// Read-only commands don't hold raftMu; they must not observe the range
+	// while its data is partially replaced.
+	r.readOnlyCmdMu.Lock()
+	defer r.readOnlyCmdMu.Unlock()
+
+	// The ingested sstables shadow the existing keys they overlap, but not
+	// the others, so the range's existing data is removed first.
+	if !isRangeDataEmpty(&desc, eng) {
+		if err := clearRangeData(ctx, &desc, eng); err != nil {
+			return storagebase.ReplicaState{}, 0, errors.Wrap(err, "unable to clear range data")
+		}
+	}
+
+	// Write the Raft log and HardState to a batch which is never committed,
+	// but from which the sstable holding them is built.
+	batch := eng.NewBatch()
+	defer batch.Close()
+
+	logEntries, err := decodeSnapshotLogEntries(inSnap.LogEntries)
+	if err != nil {
+		return storagebase.ReplicaState{}, 0, err
+	}
+	if _, raftLogSize, err = r.append(ctx, batch, 0, raftLogSize, logEntries); err != nil {
+		return storagebase.ReplicaState{}, 0, err
+	}
+	if !raft.IsEmptyHardState(hs) {
+		if err := setHardState(ctx, batch, r.RangeID, hs); err != nil {
+			return storagebase.ReplicaState{}, 0, errors.Wrapf(err, "unable to persist HardState %+v", &hs)
+		}
+	}
+
+	if err := inSnap.ssts.ingest(r.RangeID, batch); err != nil {
+		return storagebase.ReplicaState{}, 0, errors.Wrap(err, "unable to ingest snapshot")
+	}
+	r.store.metrics.RangeSnapshotsIngested.Inc(1)
+
+	s, err := loadState(ctx, eng, &desc)
+	if err != nil {
+		return storagebase.ReplicaState{}, 0, err
+	}
+	r.verifySnapshotState(ctx, s, snap)
+	return s, raftLogSize, nil
+}
@@ -0,0 +1,273 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestSnapshotSSTWriterIngest verifies that the data of a snapshot written
+// by a snapshotSSTWriter is ingested along with its unreplicated keys,
+// alongside the existing data of neighboring ranges.
+func TestSnapshotSSTWriterIngest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanup := testutils.TempDir(t, 0)
+	defer cleanup()
+
+	eng, err := engine.NewRocksDB(
+		roachpb.Attributes{}, dir, engine.RocksDBCache{}, 0, engine.DefaultMaxOpenFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eng.Close()
+
+	desc := &roachpb.RangeDescriptor{
+		RangeID:  2,
+		StartKey: roachpb.RKey("b"),
+		EndKey:   roachpb.RKey("c"),
+	}
+	value := []byte("value")
+
+	// Write data for the neighboring ranges.
+	neighbors := []engine.MVCCKey{
+		engine.MakeMVCCMetadataKey(keys.RaftAppliedIndexKey(1)),
+		engine.MakeMVCCMetadataKey(keys.RaftAppliedIndexKey(3)),
+		engine.MakeMVCCMetadataKey(keys.RangeDescriptorKey(roachpb.RKey("a"))),
+		engine.MakeMVCCMetadataKey(keys.RangeDescriptorKey(roachpb.RKey("c"))),
+		engine.MakeMVCCMetadataKey(roachpb.Key("a")),
+		engine.MakeMVCCMetadataKey(roachpb.Key("c")),
+	}
+	for _, key := range neighbors {
+		if err := eng.Put(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := eng.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if !isRangeDataEmpty(desc, eng) {
+		t.Fatal("expected the range to be empty")
+	}
+
+	// The snapshot's data, in key order.
+	data := []engine.MVCCKey{
+		engine.MakeMVCCMetadataKey(keys.RaftAppliedIndexKey(desc.RangeID)),
+		engine.MakeMVCCMetadataKey(keys.RangeDescriptorKey(desc.StartKey)),
+		engine.MakeMVCCMetadataKey(roachpb.Key("b1")),
+		{Key: roachpb.Key("b1"), Timestamp: hlc.Timestamp{WallTime: 2}},
+		{Key: roachpb.Key("b1"), Timestamp: hlc.Timestamp{WallTime: 1}},
+		engine.MakeMVCCMetadataKey(roachpb.Key("b2")),
+	}
+	batch := eng.NewBatch()
+	defer batch.Close()
+	for _, key := range data {
+		if err := batch.Put(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	parent := filepath.Join(eng.AuxiliaryDir(), snapshotSSTDirName)
+	w, err := newSnapshotSSTWriter(eng, parent, desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.close()
+	if err := w.addBatch(batch.Repr()); err != nil {
+		t.Fatal(err)
+	}
+	if n := w.numFiles(); n != 3 {
+		t.Fatalf("expected one sstable per replicated key range, found %d", n)
+	}
+
+	unreplicated := eng.NewBatch()
+	defer unreplicated.Close()
+	hardStateKey := engine.MakeMVCCMetadataKey(keys.RaftHardStateKey(desc.RangeID))
+	if err := unreplicated.Put(hardStateKey, value); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.ingest(desc.RangeID, unreplicated); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range append(append(data, hardStateKey), neighbors...) {
+		if v, err := eng.Get(key); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(v, value) {
+			t.Fatalf("expected %q for %s, found %q", value, key, v)
+		}
+	}
+
+	// The sstables are removed when the writer is closed.
+	w.close()
+	if _, err := os.Stat(w.dir); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, got %v", w.dir, err)
+	}
+}
+
+// TestSnapshotSSTWriterRejectsForeignKeys verifies that snapshots containing
+// keys outside of the range or out of order are rejected.
+func TestSnapshotSSTWriterRejectsForeignKeys(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanup := testutils.TempDir(t, 0)
+	defer cleanup()
+
+	eng, err := engine.NewRocksDB(
+		roachpb.Attributes{}, dir, engine.RocksDBCache{}, 0, engine.DefaultMaxOpenFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eng.Close()
+
+	desc := &roachpb.RangeDescriptor{
+		RangeID:  2,
+		StartKey: roachpb.RKey("b"),
+		EndKey:   roachpb.RKey("c"),
+	}
+	testCases := []struct {
+		puts   []roachpb.Key
+		expErr string
+	}{
+		{[]roachpb.Key{roachpb.Key("d")}, "outside of the range"},
+		{[]roachpb.Key{keys.RaftHardStateKey(desc.RangeID)}, "outside of the range"},
+		{[]roachpb.Key{
+			roachpb.Key("b"),
+			keys.RangeDescriptorKey(desc.StartKey),
+			roachpb.Key("b1"),
+		}, "out of order"},
+	}
+	for i, c := range testCases {
+		batch := eng.NewBatch()
+		for _, key := range c.puts {
+			if err := batch.Put(engine.MakeMVCCMetadataKey(key), []byte("value")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w, err := newSnapshotSSTWriter(eng, filepath.Join(eng.AuxiliaryDir(), snapshotSSTDirName), desc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.addBatch(batch.Repr()); !testutils.IsError(err, c.expErr) {
+			t.Errorf("%d: expected error %q, got %v", i, c.expErr, err)
+		}
+		w.close()
+		batch.Close()
+	}
+}
+
+// TestSnapshotSSTIngestReplacesData verifies that the data of a snapshot
+// can be ingested into a range which has data once it's been cleared, and
+// that clearing the range keeps the term and vote of its HardState.
+func TestSnapshotSSTIngestReplacesData(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanup := testutils.TempDir(t, 0)
+	defer cleanup()
+
+	eng, err := engine.NewRocksDB(
+		roachpb.Attributes{}, dir, engine.RocksDBCache{}, 0, engine.DefaultMaxOpenFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eng.Close()
+	ctx := context.Background()
+
+	desc := &roachpb.RangeDescriptor{
+		RangeID:  2,
+		StartKey: roachpb.RKey("b"),
+		EndKey:   roachpb.RKey("c"),
+	}
+	oldKey := engine.MakeMVCCMetadataKey(roachpb.Key("b1"))
+	newKey := engine.MakeMVCCMetadataKey(roachpb.Key("b2"))
+	for _, key := range []engine.MVCCKey{
+		oldKey,
+		newKey,
+		engine.MakeMVCCMetadataKey(keys.RaftLogKey(desc.RangeID, 10)),
+	} {
+		if err := eng.Put(key, []byte("old")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := setHardState(ctx, eng, desc.RangeID, raftpb.HardState{
+		Term: 5, Vote: 3, Commit: 10,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// The existing data is also kept alive by an engine snapshot, as when
+	// another range's snapshot is being sent.
+	snap := eng.NewSnapshot()
+	defer snap.Close()
+
+	if err := clearRangeData(ctx, desc, eng); err != nil {
+		t.Fatal(err)
+	}
+	if hs, err := loadHardState(ctx, eng, desc.RangeID); err != nil {
+		t.Fatal(err)
+	} else if expected := (raftpb.HardState{Term: 5, Vote: 3}); !reflect.DeepEqual(hs, expected) {
+		t.Fatalf("expected HardState %+v, found %+v", expected, hs)
+	}
+
+	batch := eng.NewBatch()
+	defer batch.Close()
+	if err := batch.Put(newKey, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	w, err := newSnapshotSSTWriter(eng, filepath.Join(eng.AuxiliaryDir(), snapshotSSTDirName), desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.close()
+	if err := w.addBatch(batch.Repr()); err != nil {
+		t.Fatal(err)
+	}
+	unreplicated := eng.NewBatch()
+	defer unreplicated.Close()
+	if err := setHardState(ctx, unreplicated, desc.RangeID, raftpb.HardState{
+		Term: 6, Commit: 20,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.ingest(desc.RangeID, unreplicated); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := eng.Get(oldKey); err != nil {
+		t.Fatal(err)
+	} else if v != nil {
+		t.Fatalf("expected %s to be removed, found %q", oldKey, v)
+	}
+	if v, err := eng.Get(newKey); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(v, []byte("new")) {
+		t.Fatalf("expected %q for %s, found %q", "new", newKey, v)
+	}
+	if hs, err := loadHardState(ctx, eng, desc.RangeID); err != nil {
+		t.Fatal(err)
+	} else if expected := (raftpb.HardState{Term: 6, Commit: 20}); !reflect.DeepEqual(hs, expected) {
+		t.Fatalf("expected HardState %+v, found %+v", expected, hs)
+	}
+}
@@ -73,12 +73,16 @@ func newReplicateQueue(
 	if g != nil { // gossip is nil for some unittests
 		// Register a gossip callback to signal queue that replicas in
 		// purgatory might be retried due to new store gossip.
-		g.RegisterCallback(gossip.MakePrefixPattern(gossip.KeyStorePrefix), func(_ string, _ roachpb.Value) {
+		updateFn := func(_ string, _ roachpb.Value) {
 			select {
 			case rq.updateChan <- struct{}{}:
 			default:
 			}
-		})
+		}
+		g.RegisterCallback(gossip.MakePrefixPattern(gossip.KeyStorePrefix), updateFn)
+		// Node liveness updates may change the set of decommissioning nodes,
+		// which affects the set of candidate stores.
+		g.RegisterCallback(gossip.MakePrefixPattern(gossip.KeyNodeLivenessPrefix), updateFn)
 	}
 
 	return rq
@@ -248,6 +252,41 @@ func (rq *replicateQueue) process(
 		if err = repl.ChangeReplicas(ctx, roachpb.REMOVE_REPLICA, deadReplica, desc); err != nil {
 			return err
 		}
+	case AllocatorRemoveDecommissioning:
+		log.Event(ctx, "removing a decommissioning replica")
+		decommissioningReplicas := rq.allocator.storePool.decommissioningReplicas(desc.Replicas)
+		if len(decommissioningReplicas) == 0 {
+			if log.V(1) {
+				log.Warningf(ctx, "Range of replica %s was identified as having decommissioning replicas, but no decommissioning replicas were found.", repl)
+			}
+			break
+		}
+		decommissioningReplica := decommissioningReplicas[0]
+		if decommissioningReplica.StoreID == repl.store.StoreID() {
+			// The local replica is the leaseholder and is being decommissioned.
+			// Transfer the lease away so that the new leaseholder can remove it.
+			target := rq.allocator.TransferLeaseTarget(
+				zone.Constraints, desc.Replicas, repl.store.StoreID(), desc.RangeID,
+				false /* checkTransferLeaseSource */)
+			if target == (roachpb.ReplicaDescriptor{}) {
+				// Lease balance is secondary to getting the lease off of a
+				// decommissioning node, so fall back to any eligible replica.
+				target = leaseTargetOffDecommissioning(desc.Replicas, decommissioningReplicas)
+			}
+			if target == (roachpb.ReplicaDescriptor{}) {
+				return errors.Errorf("%s: no lease transfer target for decommissioning replica", repl)
+			}
+			log.VEventf(ctx, 1, "transferring lease to s%d", target.StoreID)
+			if err := repl.AdminTransferLease(target.StoreID); err != nil {
+				return errors.Wrapf(err, "%s: unable to transfer lease to s%d", repl, target.StoreID)
+			}
+			// Do not requeue as we transferred our lease away.
+			return nil
+		}
+		log.VEventf(ctx, 1, "removing decommissioning replica %+v from store", decommissioningReplica)
+		if err = repl.ChangeReplicas(ctx, roachpb.REMOVE_REPLICA, decommissioningReplica, desc); err != nil {
+			return err
+		}
 	case AllocatorNoop:
 		// The Noop case will result if this replica was queued in order to
 		// rebalance. Attempt to find a rebalancing target.
@@ -298,6 +337,24 @@ func (rq *replicateQueue) process(
 	return nil
 }
 
+// leaseTargetOffDecommissioning returns the first of the supplied replicas
+// which is not contained in the decommissioning replicas, or an empty
+// descriptor if there is no such replica.
+func leaseTargetOffDecommissioning(
+	replicas, decommissioningReplicas []roachpb.ReplicaDescriptor,
+) roachpb.ReplicaDescriptor {
+outer:
+	for _, repl := range replicas {
+		for _, decommissioningRepl := range decommissioningReplicas {
+			if repl.NodeID == decommissioningRepl.NodeID {
+				continue outer
+			}
+		}
+		return repl
+	}
+	return roachpb.ReplicaDescriptor{}
+}
+
 func (*replicateQueue) timer() time.Duration {
 	return replicateQueueTimerDuration
 }
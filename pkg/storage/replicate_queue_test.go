@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/testcluster"
@@ -50,10 +51,7 @@ func TestReplicateQueueRebalance(t *testing.T) {
 	}()
 
 	// TODO(peter): Remove when lease rebalancing is the default.
-	defer func(v bool) {
-		storage.EnableLeaseRebalancing = v
-	}(storage.EnableLeaseRebalancing)
-	storage.EnableLeaseRebalancing = true
+	defer settings.TestingSetBool(&storage.EnableLeaseRebalancing, true)()
 
 	const numNodes = 5
 	tc := testcluster.StartTestCluster(t, numNodes,
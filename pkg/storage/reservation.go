@@ -18,17 +18,24 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
-	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 )
 
-const (
-	// defaultMaxReservations is the number of concurrent reservations allowed.
-	defaultMaxReservations = 1
-	// defaultMaxReservedBytes is the total number of bytes that can be
-	// reserved, by all active reservations, at any time.
-	defaultMaxReservedBytes = 250 << 20 // 250 MiB
+// maxReservations is the number of concurrent reservations allowed.
+var maxReservations = settings.RegisterPositiveIntSetting(
+	"server.reservations.max_count",
+	"the maximum number of concurrent replica reservations per store",
+	1,
+)
+
+// maxReservedBytes is the total number of bytes that can be reserved, by all
+// active reservations, at any time.
+var maxReservedBytes = settings.RegisterPositiveByteSizeSetting(
+	"server.reservations.max_bytes",
+	"the maximum number of bytes reserved by all of a store's concurrent replica reservations",
+	250<<20, /* 250 MiB */
 )
 
 // reservationRequest represents a request for a replica reservation.
@@ -45,9 +52,12 @@ type reservationResponse struct {
 
 // bookie contains a store's replica reservations.
 type bookie struct {
-	metrics          *StoreMetrics
-	maxReservations  int   // Maximum number of allowed reservations.
-	maxReservedBytes int64 // Maximum bytes allowed for all reservations combined.
+	metrics *StoreMetrics
+	// maxReservations and maxReservedBytes, if non-zero, override the
+	// maximum number of reservations and the maximum bytes allowed for all
+	// reservations combined given by the cluster settings.
+	maxReservations  int
+	maxReservedBytes int64
 	mu               struct {
 		syncutil.Mutex                                               // Protects all values within the mu struct.
 		reservationsByRangeID map[roachpb.RangeID]reservationRequest // All active reservations
@@ -58,14 +68,26 @@ type bookie struct {
 // newBookie creates a reservations system.
 func newBookie(metrics *StoreMetrics) *bookie {
 	b := &bookie{
-		metrics:          metrics,
-		maxReservations:  envutil.EnvOrDefaultInt("COCKROACH_MAX_RESERVATIONS", defaultMaxReservations),
-		maxReservedBytes: envutil.EnvOrDefaultBytes("COCKROACH_MAX_RESERVED_BYTES", defaultMaxReservedBytes),
+		metrics: metrics,
 	}
 	b.mu.reservationsByRangeID = make(map[roachpb.RangeID]reservationRequest)
 	return b
 }
 
+func (b *bookie) getMaxReservations() int {
+	if b.maxReservations != 0 {
+		return b.maxReservations
+	}
+	return int(maxReservations.Get())
+}
+
+func (b *bookie) getMaxReservedBytes() int64 {
+	if b.maxReservedBytes != 0 {
+		return b.maxReservedBytes
+	}
+	return maxReservedBytes.Get()
+}
+
 // Reserve a new replica. Reservations can be rejected due to having too many
 // outstanding reservations already or not having enough free disk space.
 // Accepted reservations return a ReservationResponse with Reserved set to true.
@@ -98,10 +120,10 @@ func (b *bookie) Reserve(
 	}
 
 	// Do we have too many current reservations?
-	if len(b.mu.reservationsByRangeID) >= b.maxReservations {
+	if max := b.getMaxReservations(); len(b.mu.reservationsByRangeID) >= max {
 		if log.V(1) {
 			log.Infof(ctx, "[r%d] unable to book reservation, too many reservations (current:%d, max:%d)",
-				req.RangeID, len(b.mu.reservationsByRangeID), b.maxReservations)
+				req.RangeID, len(b.mu.reservationsByRangeID), max)
 		}
 		return resp
 	}
@@ -120,10 +142,10 @@ func (b *bookie) Reserve(
 	}
 
 	// Do we have enough reserved space free for the reservation?
-	if b.mu.size+req.RangeSize > b.maxReservedBytes {
+	if max := b.getMaxReservedBytes(); b.mu.size+req.RangeSize > max {
 		if log.V(1) {
 			log.Infof(ctx, "[r%d] unable to book reservation, not enough available reservation space (requested:%d, reserved:%d, maxReserved:%d)",
-				req.RangeID, req.RangeSize, b.mu.size, b.maxReservedBytes)
+				req.RangeID, req.RangeSize, b.mu.size, max)
 		}
 		return resp
 	}
@@ -30,7 +30,7 @@ func createTestBookie(maxReservations int, maxReservedBytes int64) *bookie {
 	b.maxReservations = maxReservations
 	b.maxReservedBytes = maxReservedBytes
 	// Set a high number for a mocked total available space.
-	b.metrics.Available.Update(defaultMaxReservedBytes * 10)
+	b.metrics.Available.Update(maxReservedBytes.Get() * 10)
 	return b
 }
 
@@ -50,7 +50,7 @@ func verifyBookie(t *testing.T, b *bookie, reservations int, reservedBytes int64
 // correctly.
 func TestBookieReserve(t *testing.T) {
 	defer leaktest.AfterTest(t)()
-	b := createTestBookie(5, defaultMaxReservedBytes)
+	b := createTestBookie(5, maxReservedBytes.Get())
 
 	testCases := []struct {
 		rangeID      int
@@ -156,7 +156,7 @@ func TestBookieReserveMaxRanges(t *testing.T) {
 
 	previousReserved := 10
 
-	b := createTestBookie(previousReserved, defaultMaxReservedBytes)
+	b := createTestBookie(previousReserved, maxReservedBytes.Get())
 
 	// Load up reservations.
 	for i := 1; i <= previousReserved; i++ {
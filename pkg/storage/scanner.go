@@ -22,6 +22,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -29,6 +30,33 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
+// defaultScanInterval is the default target duration of a complete scan of
+// a store's replicas.
+const defaultScanInterval = 10 * time.Minute
+
+// scanInterval is the target duration of a complete scan of a store's
+// replicas, for the stores whose StoreConfig.ScanInterval isn't set.
+var scanInterval = settings.RegisterPositiveDurationSetting(
+	"server.scanner.interval",
+	"the target duration of a complete scan of a store's replicas by its queues",
+	defaultScanInterval,
+)
+
+// scanMaxIdleTime is the maximum time the scanner of a store whose
+// StoreConfig.ScanInterval and ScanMaxIdleTime aren't set waits between
+// replicas, which makes small stores complete their scans faster than the
+// scan interval.
+var scanMaxIdleTime = settings.RegisterNonNegativeDurationSetting(
+	"server.scanner.max_idle_time",
+	"the maximum time a store's scanner waits between replicas, or 0 for no maximum",
+	200*time.Millisecond,
+)
+
+// intervalSettingRecheckInterval is how often a scanner whose interval
+// setting is zero checks whether it has been changed. It's a variable for
+// testing.
+var intervalSettingRecheckInterval = 10 * time.Second
+
 // A replicaQueue is a prioritized queue of replicas for which work is
 // scheduled. For example, there's a GC queue for replicas which are due
 // for garbage collection, a rebalance queue to move replicas from full
@@ -66,12 +94,12 @@ type replicaSet interface {
 type replicaScanner struct {
 	log.AmbientContext
 
-	targetInterval time.Duration  // Target duration interval for scan loop
-	maxIdleTime    time.Duration  // Max idle time for scan loop
-	waitTimer      timeutil.Timer // Shared timer to avoid allocations.
-	replicas       replicaSet     // Replicas to be scanned
-	queues         []replicaQueue // Replica queues managed by this scanner
-	removed        chan *Replica  // Replicas to remove from queues
+	targetInterval func() time.Duration // Target duration interval for scan loop
+	maxIdleTime    func() time.Duration // Max idle time for scan loop
+	waitTimer      timeutil.Timer       // Shared timer to avoid allocations.
+	replicas       replicaSet           // Replicas to be scanned
+	queues         []replicaQueue       // Replica queues managed by this scanner
+	removed        chan *Replica        // Replicas to remove from queues
 	// Count of times and total duration through the scanning loop.
 	mu struct {
 		syncutil.Mutex
@@ -97,8 +125,8 @@ func newReplicaScanner(
 	}
 	rs := &replicaScanner{
 		AmbientContext: ambient,
-		targetInterval: targetInterval,
-		maxIdleTime:    maxIdleTime,
+		targetInterval: func() time.Duration { return targetInterval },
+		maxIdleTime:    func() time.Duration { return maxIdleTime },
 		replicas:       replicas,
 		removed:        make(chan *Replica, 10),
 		setDisabledCh:  make(chan struct{}, 1),
@@ -109,6 +137,19 @@ func newReplicaScanner(
 	return rs
 }
 
+// followIntervalSettings makes the scanner pace itself according to the
+// values returned by the given functions, which read cluster settings,
+// instead of fixed durations, and enables it. A nil maxIdleTime keeps the
+// scanner's fixed maximum idle time. While targetInterval returns zero, the
+// scanner is paused. This method may only be called before Start().
+func (rs *replicaScanner) followIntervalSettings(targetInterval, maxIdleTime func() time.Duration) {
+	rs.targetInterval = targetInterval
+	if maxIdleTime != nil {
+		rs.maxIdleTime = maxIdleTime
+	}
+	rs.SetDisabled(false)
+}
+
 // AddQueues adds a variable arg list of queues to the replica scanner.
 // This method may only be called before Start().
 func (rs *replicaScanner) AddQueues(queues ...replicaQueue) {
@@ -176,7 +217,7 @@ func (rs *replicaScanner) RemoveReplica(repl *Replica) {
 // the scan.
 func (rs *replicaScanner) paceInterval(start, now time.Time) time.Duration {
 	elapsed := now.Sub(start)
-	remainingNanos := rs.targetInterval.Nanoseconds() - elapsed.Nanoseconds()
+	remainingNanos := rs.targetInterval().Nanoseconds() - elapsed.Nanoseconds()
 	if remainingNanos < 0 {
 		remainingNanos = 0
 	}
@@ -185,8 +226,8 @@ func (rs *replicaScanner) paceInterval(start, now time.Time) time.Duration {
 		count = 1
 	}
 	interval := time.Duration(remainingNanos / int64(count))
-	if rs.maxIdleTime > 0 && interval > rs.maxIdleTime {
-		interval = rs.maxIdleTime
+	if maxIdleTime := rs.maxIdleTime(); maxIdleTime > 0 && interval > maxIdleTime {
+		interval = maxIdleTime
 	}
 	return interval
 }
@@ -262,12 +303,20 @@ func (rs *replicaScanner) scanLoop(clock *hlc.Clock, stopper *stop.Stopper) {
 				}
 				continue
 			}
+			if rs.targetInterval() == 0 {
+				if done := rs.waitIntervalSet(stopper); done {
+					return
+				}
+				start = timeutil.Now()
+				continue
+			}
 			var shouldStop bool
 			count := 0
 			rs.replicas.Visit(func(repl *Replica) bool {
 				count++
 				shouldStop = rs.waitAndProcess(ctx, start, clock, stopper, repl)
-				return !shouldStop
+				// Stop early if the interval setting was zeroed mid-scan.
+				return !shouldStop && rs.targetInterval() != 0
 			})
 			if count == 0 {
 				// No replicas processed, just wait.
@@ -316,3 +365,23 @@ func (rs *replicaScanner) waitEnabled(stopper *stop.Stopper) bool {
 		}
 	}
 }
+
+// waitIntervalSet loops, removing replicas from the scanner's queues,
+// until the scanner's interval setting is non-zero or the stopper signals
+// shutdown.
+func (rs *replicaScanner) waitIntervalSet(stopper *stop.Stopper) bool {
+	for rs.targetInterval() == 0 {
+		rs.waitTimer.Reset(intervalSettingRecheckInterval)
+		select {
+		case <-rs.waitTimer.C:
+			rs.waitTimer.Read = true
+
+		case repl := <-rs.removed:
+			rs.removeReplica(repl)
+
+		case <-stopper.ShouldStop():
+			return true
+		}
+	}
+	return false
+}
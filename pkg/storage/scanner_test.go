@@ -18,6 +18,7 @@ package storage
 
 import (
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -368,6 +369,46 @@ func TestScannerDisabledWithZeroInterval(t *testing.T) {
 	}
 }
 
+// TestScannerPausedByZeroIntervalSetting verifies that a scanner following
+// an interval setting doesn't scan while the setting is zero, and resumes
+// once it's changed.
+func TestScannerPausedByZeroIntervalSetting(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer func(d time.Duration) { intervalSettingRecheckInterval = d }(intervalSettingRecheckInterval)
+	intervalSettingRecheckInterval = time.Millisecond
+
+	const count = 3
+	ranges := newTestRangeSet(count, t)
+	q := &testQueue{}
+	var interval int64
+	s := newReplicaScanner(log.AmbientContext{}, 0, 0, ranges)
+	s.followIntervalSettings(func() time.Duration {
+		return time.Duration(atomic.LoadInt64(&interval))
+	}, nil)
+	s.AddQueues(q)
+	mc := hlc.NewManualClock(123)
+	clock := hlc.NewClock(mc.UnixNano, time.Nanosecond)
+	stopper := stop.NewStopper()
+	defer stopper.Stop()
+	s.Start(clock, stopper)
+
+	time.Sleep(10 * time.Millisecond)
+	if sc, qc := s.scanCount(), q.count(); sc != 0 || qc != 0 {
+		t.Fatalf("expected no scans with a zero interval, got %d scans and %d queued replicas", sc, qc)
+	}
+
+	atomic.StoreInt64(&interval, int64(time.Millisecond))
+	util.SucceedsSoon(t, func() error {
+		if qc := q.count(); qc != count {
+			return errors.Errorf("expected %d replicas; have %d", count, qc)
+		}
+		if s.scanCount() == 0 {
+			return errors.Errorf("expected scanner count to increment")
+		}
+		return nil
+	})
+}
+
 // TestScannerEmptyRangeSet verifies that an empty range set doesn't busy loop.
 func TestScannerEmptyRangeSet(t *testing.T) {
 	defer leaktest.AfterTest(t)()
@@ -0,0 +1,209 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// The rates, in bytes per second, at which a store sends and receives
+// snapshots, unless set in its StoreConfig. Rebalancing snapshots are
+// preemptive snapshots sent when adding a replica to a range; they're
+// limited more aggressively than the Raft snapshots which recover replicas
+// which have fallen behind. Sending and receiving are limited separately,
+// e.g. so that a store on a node with a slow disk can receive snapshots
+// more slowly than it sends them. A zero rate disables the limit.
+var (
+	rebalanceSnapshotSendRate = settings.RegisterNonNegativeByteSizeSetting(
+		"kv.snapshot_rebalance.max_send_rate",
+		"the rate limit (bytes/sec) at which a store sends rebalancing snapshots, or 0 to disable the limit",
+		2<<20,
+	)
+	rebalanceSnapshotRecvRate = settings.RegisterNonNegativeByteSizeSetting(
+		"kv.snapshot_rebalance.max_recv_rate",
+		"the rate limit (bytes/sec) at which a store receives rebalancing snapshots, or 0 to disable the limit",
+		2<<20,
+	)
+	recoverySnapshotSendRate = settings.RegisterNonNegativeByteSizeSetting(
+		"kv.snapshot_recovery.max_send_rate",
+		"the rate limit (bytes/sec) at which a store sends recovery snapshots, or 0 to disable the limit",
+		8<<20,
+	)
+	recoverySnapshotRecvRate = settings.RegisterNonNegativeByteSizeSetting(
+		"kv.snapshot_recovery.max_recv_rate",
+		"the rate limit (bytes/sec) at which a store receives recovery snapshots, or 0 to disable the limit",
+		8<<20,
+	)
+)
+
+// snapshotRate returns a function returning the given rate, or the current
+// value of the given setting if the rate is zero.
+func snapshotRate(rate int64, setting func() int64) func() int64 {
+	if rate == 0 {
+		return setting
+	}
+	return func() int64 { return rate }
+}
+
+// snapshotRateLimiter is a token bucket limiting the rate at which
+// snapshot data is transferred. It allows bursts of up to one second's
+// worth of data.
+type snapshotRateLimiter struct {
+	// rate returns the limit in bytes per second. A non-positive rate
+	// disables the limiter.
+	rate func() int64
+
+	mu struct {
+		syncutil.Mutex
+		// tokens is the number of bytes available for transfer. It becomes
+		// negative when waiters have reserved more than is available.
+		tokens float64
+		last   time.Time
+	}
+}
+
+func newSnapshotRateLimiter(rate func() int64) *snapshotRateLimiter {
+	l := &snapshotRateLimiter{rate: rate}
+	l.mu.tokens = float64(rate())
+	l.mu.last = timeutil.Now()
+	return l
+}
+
+// wait blocks until n bytes may be transferred or the context is canceled,
+// returning the time spent waiting. Waiters are served in the order in
+// which they call wait.
+func (l *snapshotRateLimiter) wait(ctx context.Context, n int64) (time.Duration, error) {
+	rate := l.rate()
+	if rate <= 0 {
+		return 0, nil
+	}
+	l.mu.Lock()
+	now := timeutil.Now()
+	l.mu.tokens += now.Sub(l.mu.last).Seconds() * float64(rate)
+	if burst := float64(rate); l.mu.tokens > burst {
+		l.mu.tokens = burst
+	}
+	l.mu.last = now
+	l.mu.tokens -= float64(n)
+	var delay time.Duration
+	if l.mu.tokens < 0 {
+		delay = time.Duration(-l.mu.tokens / float64(rate) * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return delay, nil
+	case <-ctx.Done():
+		// Return the reservation, as the bytes won't be transferred.
+		l.mu.Lock()
+		l.mu.tokens += float64(n)
+		l.mu.Unlock()
+		return timeutil.Since(now), ctx.Err()
+	}
+}
+
+// snapshotThrottle limits the rate at which a store sends or receives
+// snapshots, with separate limits for rebalancing and recovery snapshots,
+// and records the corresponding metrics. A nil throttle doesn't limit
+// anything.
+type snapshotThrottle struct {
+	// waiters is the number of transfers waiting for the rate limit. It is
+	// accessed atomically and must be the first field for alignment.
+	waiters int64
+
+	rebalance *snapshotRateLimiter
+	recovery  *snapshotRateLimiter
+
+	bytes      *metric.Counter
+	queue      *metric.Gauge
+	queueNanos *metric.Counter
+}
+
+func newSnapshotThrottle(
+	rebalanceRate, recoveryRate func() int64,
+	bytes *metric.Counter,
+	queue *metric.Gauge,
+	queueNanos *metric.Counter,
+) *snapshotThrottle {
+	return &snapshotThrottle{
+		rebalance:  newSnapshotRateLimiter(rebalanceRate),
+		recovery:   newSnapshotRateLimiter(recoveryRate),
+		bytes:      bytes,
+		queue:      queue,
+		queueNanos: queueNanos,
+	}
+}
+
+// newSnapshotSendThrottle creates the throttle for the snapshots sent by a
+// store. A zero rate follows the corresponding cluster setting.
+func newSnapshotSendThrottle(
+	rebalanceRate, recoveryRate int64, metrics *StoreMetrics,
+) *snapshotThrottle {
+	return newSnapshotThrottle(
+		snapshotRate(rebalanceRate, func() int64 { return rebalanceSnapshotSendRate.Get() }),
+		snapshotRate(recoveryRate, func() int64 { return recoverySnapshotSendRate.Get() }),
+		metrics.RangeSnapshotSentBytes, metrics.RangeSnapshotSendQueue,
+		metrics.RangeSnapshotSendQueueNanos)
+}
+
+// newSnapshotRecvThrottle creates the throttle for the snapshots received
+// by a store. A zero rate follows the corresponding cluster setting.
+func newSnapshotRecvThrottle(
+	rebalanceRate, recoveryRate int64, metrics *StoreMetrics,
+) *snapshotThrottle {
+	return newSnapshotThrottle(
+		snapshotRate(rebalanceRate, func() int64 { return rebalanceSnapshotRecvRate.Get() }),
+		snapshotRate(recoveryRate, func() int64 { return recoverySnapshotRecvRate.Get() }),
+		metrics.RangeSnapshotRcvdBytes, metrics.RangeSnapshotRcvdQueue,
+		metrics.RangeSnapshotRcvdQueueNanos)
+}
+
+// wait blocks until n bytes of the snapshot with the given header may be
+// transferred. Snapshots which the recipient can decline are preemptive
+// snapshots sent to rebalance a range; all others are Raft snapshots.
+func (t *snapshotThrottle) wait(
+	ctx context.Context, header *SnapshotRequest_Header, n int64,
+) error {
+	if t == nil {
+		return nil
+	}
+	limiter := t.recovery
+	if header.CanDecline {
+		limiter = t.rebalance
+	}
+	t.queue.Update(atomic.AddInt64(&t.waiters, 1))
+	waited, err := limiter.wait(ctx, n)
+	t.queue.Update(atomic.AddInt64(&t.waiters, -1))
+	t.queueNanos.Inc(waited.Nanoseconds())
+	if err != nil {
+		return err
+	}
+	t.bytes.Inc(n)
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+)
+
+// TestSnapshotRateLimiter verifies that the rate limiter allows a burst of
+// one second's worth of data and delays transfers beyond it.
+func TestSnapshotRateLimiter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	const rate = 10000
+	l := newSnapshotRateLimiter(func() int64 { return rate })
+
+	if waited, err := l.wait(context.Background(), rate); err != nil || waited != 0 {
+		t.Fatalf("expected burst to proceed immediately, waited %s, %v", waited, err)
+	}
+	// The bucket is now empty, so transferring another 10% of the rate
+	// takes roughly 100ms.
+	waited, err := l.wait(context.Background(), rate/10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if waited <= 50*time.Millisecond || waited > 100*time.Millisecond {
+		t.Fatalf("expected to wait about 100ms, waited %s", waited)
+	}
+
+	// A disabled limiter never waits.
+	l = newSnapshotRateLimiter(func() int64 { return 0 })
+	for i := 0; i < 10; i++ {
+		if waited, err := l.wait(context.Background(), 1<<30); err != nil || waited != 0 {
+			t.Fatalf("expected disabled limiter not to wait, waited %s, %v", waited, err)
+		}
+	}
+}
+
+// TestSnapshotRateLimiterCancellation verifies that waiting transfers return
+// when their context is canceled and give up their reservation.
+func TestSnapshotRateLimiterCancellation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	const rate = 1000
+	l := newSnapshotRateLimiter(func() int64 { return rate })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.wait(ctx, 100*rate); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+	// The canceled transfer's reservation was returned, so the burst is
+	// still available.
+	if waited, err := l.wait(context.Background(), rate/2); err != nil || waited != 0 {
+		t.Fatalf("expected burst to proceed immediately, waited %s, %v", waited, err)
+	}
+}
+
+// TestSnapshotThrottle verifies that rebalancing and recovery snapshots are
+// limited separately and that the throttle's metrics are updated.
+func TestSnapshotThrottle(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	bytes := metric.NewCounter(metric.Metadata{Name: "bytes"})
+	queue := metric.NewGauge(metric.Metadata{Name: "queue"})
+	queueNanos := metric.NewCounter(metric.Metadata{Name: "queue-nanos"})
+	const rate = 10000
+	limit := func() int64 { return rate }
+	throttle := newSnapshotThrottle(limit, limit, bytes, queue, queueNanos)
+
+	rebalance := &SnapshotRequest_Header{CanDecline: true}
+	recovery := &SnapshotRequest_Header{CanDecline: false}
+
+	// Exhaust the rebalance limit. Recovery snapshots are unaffected.
+	if err := throttle.wait(context.Background(), rebalance, rate); err != nil {
+		t.Fatal(err)
+	}
+	if err := throttle.wait(context.Background(), recovery, rate); err != nil {
+		t.Fatal(err)
+	}
+	if nanos := queueNanos.Count(); nanos != 0 {
+		t.Fatalf("expected no queueing, found %dns", nanos)
+	}
+
+	if err := throttle.wait(context.Background(), rebalance, rate/10); err != nil {
+		t.Fatal(err)
+	}
+	if nanos := queueNanos.Count(); nanos == 0 {
+		t.Fatal("expected queueing after exhausting the rebalance limit")
+	}
+	if n := bytes.Count(); n != 2*rate+rate/10 {
+		t.Fatalf("expected %d bytes, found %d", 2*rate+rate/10, n)
+	}
+	if n := queue.Value(); n != 0 {
+		t.Fatalf("expected empty queue, found %d", n)
+	}
+
+	// A nil throttle doesn't limit anything.
+	var nilThrottle *snapshotThrottle
+	if err := nilThrottle.wait(context.Background(), rebalance, 1<<30); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSnapshotSendRecvThrottleSettings verifies that the snapshots a store
+// sends and receives follow separate cluster settings, unless the rate is
+// set in the store's config.
+func TestSnapshotSendRecvThrottleSettings(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	const rate = 10000
+	defer settings.TestingSetByteSize(&rebalanceSnapshotSendRate, rate)()
+	defer settings.TestingSetByteSize(&rebalanceSnapshotRecvRate, 0)()
+
+	metrics := newStoreMetrics(time.Hour)
+	send := newSnapshotSendThrottle(0, 0, metrics)
+	recv := newSnapshotRecvThrottle(0, 0, metrics)
+	rebalance := &SnapshotRequest_Header{CanDecline: true}
+
+	// Exhaust the send limit. Receiving isn't limited.
+	for _, throttle := range []*snapshotThrottle{send, recv} {
+		if err := throttle.wait(context.Background(), rebalance, rate+rate/10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if nanos := metrics.RangeSnapshotSendQueueNanos.Count(); nanos == 0 {
+		t.Fatal("expected sending to queue after exhausting the send limit")
+	}
+	if nanos := metrics.RangeSnapshotRcvdQueueNanos.Count(); nanos != 0 {
+		t.Fatalf("expected no queueing to receive, found %dns", nanos)
+	}
+
+	// A rate set in the store's config overrides the settings.
+	recv = newSnapshotRecvThrottle(rate, 0, metrics)
+	if r := recv.rebalance.rate(); r != rate {
+		t.Fatalf("expected a receive rate of %d, found %d", rate, r)
+	}
+}
@@ -43,6 +43,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/raftlog"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/bufalloc"
@@ -348,6 +349,10 @@ type Store struct {
 	metrics                 *StoreMetrics
 	intentResolver          *intentResolver
 	raftEntryCache          *raftEntryCache
+	raftLog                 *raftlog.Log         // Raft log files; nil if the raft log is kept in engine
+	snapshotSendThrottle    *snapshotThrottle    // Limits outgoing snapshot bandwidth
+	snapshotRecvThrottle    *snapshotThrottle    // Limits incoming snapshot bandwidth
+	admission               *admissionController // Throttles work while the engine is overloaded
 
 	coalescedMu struct {
 		syncutil.Mutex
@@ -525,6 +530,11 @@ type StoreConfig struct {
 	// maintenance queue to dispatch individual maintenance tasks.
 	TimeSeriesDataStore TimeSeriesDataStore
 
+	// ProtectedTimestamps is consulted by the store's GC queue for the
+	// timestamps at which data must remain readable. If nil, data is garbage
+	// collected according to the zone configs only.
+	ProtectedTimestamps ProtectedTimestampReader
+
 	// RangeRetryOptions are the retry options when retryable errors are
 	// encountered sending commands to ranges.
 	RangeRetryOptions retry.Options
@@ -562,16 +572,19 @@ type StoreConfig struct {
 	// for local networks.
 	RaftElectionTimeoutTicks int
 
-	// ScanInterval is the default value for the scan interval
+	// ScanInterval is the target duration of a complete scan of the store's
+	// replicas. If zero, the server.scanner.interval cluster setting is used.
 	ScanInterval time.Duration
 
 	// ScanMaxIdleTime is the maximum time the scanner will be idle between ranges.
 	// If enabled (> 0), the scanner may complete in less than ScanInterval for small
-	// stores.
+	// stores. If both it and ScanInterval are zero, the
+	// server.scanner.max_idle_time cluster setting is used.
 	ScanMaxIdleTime time.Duration
 
 	// ConsistencyCheckInterval is the default time period in between consecutive
-	// consistency checks on a range.
+	// consistency checks on a range. If zero, the
+	// server.consistency_check.interval cluster setting is used.
 	ConsistencyCheckInterval time.Duration
 
 	// ConsistencyCheckPanicOnFailure causes the node to panic when it detects a
@@ -595,6 +608,17 @@ type StoreConfig struct {
 	// shared by all Raft groups managed by the store.
 	RaftEntryCacheSize uint64
 
+	// RebalanceSnapshotRate and RecoverySnapshotRate are the rates in bytes
+	// per second at which the store sends and receives the preemptive
+	// snapshots used to rebalance ranges and the Raft snapshots used to
+	// recover replicas which have fallen behind, respectively. Sends and
+	// receives are limited separately. A negative rate disables the limit,
+	// and a zero rate follows the kv.snapshot_rebalance.max_send_rate,
+	// kv.snapshot_rebalance.max_recv_rate, kv.snapshot_recovery.max_send_rate
+	// and kv.snapshot_recovery.max_recv_rate cluster settings.
+	RebalanceSnapshotRate int64
+	RecoverySnapshotRate  int64
+
 	TestingKnobs StoreTestingKnobs
 
 	// RangeLeaseActiveDuration is the duration of the active period of leader
@@ -776,6 +800,12 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 
 	s.intentResolver = newIntentResolver(s)
 	s.raftEntryCache = newRaftEntryCache(cfg.RaftEntryCacheSize)
+	s.snapshotSendThrottle = newSnapshotSendThrottle(
+		cfg.RebalanceSnapshotRate, cfg.RecoverySnapshotRate, s.metrics)
+	s.snapshotRecvThrottle = newSnapshotRecvThrottle(
+		cfg.RebalanceSnapshotRate, cfg.RecoverySnapshotRate, s.metrics)
+	s.admission = newAdmissionController(s.metrics.AdmissionOverloadLevel,
+		s.metrics.AdmissionQueue, s.metrics.AdmissionQueueNanos)
 	s.drainLeases.Store(false)
 	s.scheduler = newRaftScheduler(s.cfg.AmbientCtx, s.metrics, s, storeSchedulerConcurrency)
 
@@ -809,6 +839,15 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 		s.scanner = newReplicaScanner(
 			s.cfg.AmbientCtx, cfg.ScanInterval, cfg.ScanMaxIdleTime, newStoreReplicaVisitor(s),
 		)
+		if cfg.ScanInterval == 0 {
+			var maxIdleTime func() time.Duration
+			if cfg.ScanMaxIdleTime == 0 {
+				maxIdleTime = func() time.Duration { return scanMaxIdleTime.Get() }
+			}
+			s.scanner.followIntervalSettings(
+				func() time.Duration { return scanInterval.Get() }, maxIdleTime,
+			)
+		}
 		s.gcQueue = newGCQueue(s, s.cfg.Gossip)
 		s.splitQueue = newSplitQueue(s, s.db, s.cfg.Gossip)
 		s.replicateQueue = newReplicateQueue(
@@ -822,6 +861,11 @@ func NewStore(cfg StoreConfig, eng engine.Engine, nodeDesc *roachpb.NodeDescript
 		s.consistencyScanner = newReplicaScanner(
 			s.cfg.AmbientCtx, cfg.ConsistencyCheckInterval, 0, newStoreReplicaVisitor(s),
 		)
+		if cfg.ConsistencyCheckInterval == 0 {
+			s.consistencyScanner.followIntervalSettings(
+				func() time.Duration { return consistencyCheckInterval.Get() }, nil,
+			)
+		}
 		s.replicaConsistencyQueue = newReplicaConsistencyQueue(s, s.cfg.Gossip)
 		s.consistencyScanner.AddQueues(s.replicaConsistencyQueue)
 
@@ -1012,6 +1056,12 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 	}
 	s.rangeIDAlloc = idAlloc
 
+	// Remove the sstables of any snapshots which were being received when
+	// the store was last stopped.
+	if err := s.removeSnapshotSSTs(); err != nil {
+		return err
+	}
+
 	now := s.cfg.Clock.Now()
 	s.startedAt = now.WallTime
 
@@ -1069,6 +1119,12 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 		return err
 	}
 
+	// Open the raft log files, which must happen after the migrations above
+	// and before any raft log entries are read or written.
+	if err := s.openRaftLog(ctx); err != nil {
+		return err
+	}
+
 	// Start Raft processing goroutines.
 	s.cfg.Transport.Listen(s.StoreID(), s)
 	s.processRaft()
@@ -1190,6 +1246,8 @@ func (s *Store) Start(ctx context.Context, stopper *stop.Stopper) error {
 		log.Event(ctx, "computed initial metrics")
 	}
 
+	s.startAdmissionSampler()
+
 	// Set the started flag (for unittests).
 	atomic.StoreInt32(&s.started, 1)
 
@@ -2455,6 +2513,19 @@ func (s *Store) Send(
 	return nil, pErr
 }
 
+// RangeFeed registers a RangeFeed over the specified span with the
+// replica of the request's range on this store. It sends events to the
+// provided sink and returns with an error when the feed terminates.
+func (s *Store) RangeFeed(
+	args *roachpb.RangeFeedRequest, stream RangeFeedEventSink,
+) *roachpb.Error {
+	repl, err := s.GetReplica(args.RangeID)
+	if err != nil {
+		return roachpb.NewError(err)
+	}
+	return repl.RangeFeed(args, stream)
+}
+
 // maybeUpdateTransaction does a "query" push on the specified
 // transaction to glean possible changes, such as a higher timestamp
 // and/or priority. It turns out this is necessary while a request
@@ -2540,6 +2611,21 @@ func (s *Store) HandleSnapshot(header *SnapshotRequest_Header, stream SnapshotRe
 
 	ctx := s.AnnotateCtx(stream.Context())
 
+	// Applying a snapshot adds to the engine's compaction debt, so
+	// rebalancing snapshots are declined while the engine is overloaded and
+	// Raft snapshots wait for it to catch up.
+	if header.CanDecline {
+		if s.admission.throttled(admissionLow) {
+			log.Event(ctx, "declining snapshot while engine is overloaded")
+			return stream.Send(&SnapshotResponse{
+				Status:        SnapshotResponse_DECLINED,
+				StoreCapacity: capacity,
+			})
+		}
+	} else if err := s.admission.admit(ctx, admissionLow, s.stopper.ShouldQuiesce()); err != nil {
+		return sendSnapError(err)
+	}
+
 	if header.CanDecline {
 		// Check the bookie to see if we can apply the snapshot.
 		resp := s.reserve(ctx, reservationRequest{
@@ -2575,6 +2661,18 @@ func (s *Store) HandleSnapshot(header *SnapshotRequest_Header, stream SnapshotRe
 		return err
 	}
 
+	// Write the snapshot's data into sstables as it is received, if
+	// possible, rather than holding it in memory until it is applied.
+	var ssts *snapshotSSTWriter
+	if s.shouldIngestSnapshot() {
+		if ssts, err = newSnapshotSSTWriter(
+			s.engine.(engine.SSTIngester), s.snapshotSSTDir(), &header.RangeDescriptor,
+		); err != nil {
+			return sendSnapError(errors.Wrap(err, "unable to write snapshot sstables"))
+		}
+		defer ssts.close()
+	}
+
 	var batches [][]byte
 	var logEntries [][]byte
 	for {
@@ -2586,8 +2684,22 @@ func (s *Store) HandleSnapshot(header *SnapshotRequest_Header, stream SnapshotRe
 			return sendSnapError(errors.New("client error: provided a header mid-stream"))
 		}
 
+		size := int64(len(req.KVBatch))
+		for _, entry := range req.LogEntries {
+			size += int64(len(entry))
+		}
+		if err := s.snapshotRecvThrottle.wait(ctx, header, size); err != nil {
+			return sendSnapError(err)
+		}
+
 		if req.KVBatch != nil {
-			batches = append(batches, req.KVBatch)
+			if ssts != nil {
+				if err := ssts.addBatch(req.KVBatch); err != nil {
+					return sendSnapError(errors.Wrap(err, "unable to write snapshot sstables"))
+				}
+			} else {
+				batches = append(batches, req.KVBatch)
+			}
 		}
 		if req.LogEntries != nil {
 			logEntries = append(logEntries, req.LogEntries...)
@@ -2603,6 +2715,7 @@ func (s *Store) HandleSnapshot(header *SnapshotRequest_Header, stream SnapshotRe
 				RangeDescriptor: header.RangeDescriptor,
 				Batches:         batches,
 				LogEntries:      logEntries,
+				ssts:            ssts,
 			}
 
 			if err := s.processRaftRequest(ctx, &header.RaftMessageRequest, inSnap); err != nil {
@@ -3013,11 +3126,14 @@ type SnapshotStorePool interface {
 	updateRemoteCapacityEstimate(toStoreID roachpb.StoreID, capacity roachpb.StoreCapacity)
 }
 
-// sendSnapshot sends an outgoing snapshot via a pre-opened GRPC stream.
+// sendSnapshot sends an outgoing snapshot via a pre-opened GRPC stream. The
+// rate at which the snapshot is sent is limited by the throttle, if it is
+// non-nil.
 func sendSnapshot(
 	ctx context.Context,
 	stream OutgoingSnapshotStream,
 	storePool SnapshotStorePool,
+	throttle *snapshotThrottle,
 	header SnapshotRequest_Header,
 	snap *OutgoingSnapshot,
 	newBatch func() engine.Batch,
@@ -3090,7 +3206,7 @@ func sendSnapshot(
 		}
 
 		if len(b.Repr()) >= batchSize {
-			if err := sendBatch(stream, b); err != nil {
+			if err := sendBatch(ctx, stream, throttle, &header, b); err != nil {
 				return err
 			}
 			b = nil
@@ -3100,7 +3216,7 @@ func sendSnapshot(
 		}
 	}
 	if b != nil {
-		if err := sendBatch(stream, b); err != nil {
+		if err := sendBatch(ctx, stream, throttle, &header, b); err != nil {
 			return err
 		}
 	}
@@ -3124,7 +3240,20 @@ func sendSnapshot(
 		return false, err
 	}
 
-	if err := iterateEntries(ctx, snap.EngineSnap, rangeID, firstIndex, endIndex, scanFunc); err != nil {
+	if err := iterateEntries(ctx, snap.EngineSnap, snap.RaftLog, rangeID, firstIndex, endIndex, scanFunc); err != nil {
+		return err
+	}
+	// The raft log files aren't part of the engine snapshot, so entries may
+	// have been truncated since it was taken. The snapshot is retried.
+	if snap.RaftLog != nil && uint64(len(logEntries)) != endIndex-firstIndex {
+		return errors.Errorf("range=%s: raft log entries in [%d, %d) were truncated while sending snapshot",
+			rangeID, firstIndex, endIndex)
+	}
+	var logEntriesSize int64
+	for _, entry := range logEntries {
+		logEntriesSize += int64(len(entry))
+	}
+	if err := throttle.wait(ctx, &header, logEntriesSize); err != nil {
 		return err
 	}
 	if err := stream.Send(&SnapshotRequest{LogEntries: logEntries, Final: true}); err != nil {
@@ -3156,9 +3285,18 @@ func sendSnapshot(
 	}
 }
 
-func sendBatch(stream OutgoingSnapshotStream, batch engine.Batch) error {
+func sendBatch(
+	ctx context.Context,
+	stream OutgoingSnapshotStream,
+	throttle *snapshotThrottle,
+	header *SnapshotRequest_Header,
+	batch engine.Batch,
+) error {
 	repr := batch.Repr()
 	batch.Close()
+	if err := throttle.wait(ctx, header, int64(len(repr))); err != nil {
+		return err
+	}
 	return stream.Send(&SnapshotRequest{KVBatch: repr})
 }
 
@@ -0,0 +1,279 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+// The thresholds above which a store's engine is considered overloaded and
+// low priority work is throttled. Normal priority work is throttled once
+// the engine is severely overloaded, at severeOverloadMultiplier times the
+// thresholds, or when RocksDB has started stalling writes. The L0 file
+// threshold is well below the point at which RocksDB slows down and then
+// stops all writes, including node liveness heartbeats. Zero disables a
+// threshold.
+var (
+	admissionL0FileThreshold = settings.RegisterNonNegativeIntSetting(
+		"kv.admission.l0_file_threshold",
+		"the number of L0 files above which a store is considered overloaded, or 0 to disable",
+		8,
+	)
+	admissionPendingCompactionThreshold = settings.RegisterNonNegativeByteSizeSetting(
+		"kv.admission.pending_compaction_threshold",
+		"the estimated pending compaction bytes above which a store is considered overloaded, or 0 to disable",
+		8<<30,
+	)
+	admissionImmutableMemtableThreshold = settings.RegisterNonNegativeIntSetting(
+		"kv.admission.immutable_memtable_threshold",
+		"the number of immutable memtables above which a store is considered overloaded, or 0 to disable",
+		4,
+	)
+)
+
+const (
+	severeOverloadMultiplier = 1.5
+
+	// admissionSampleInterval is the interval at which the engine's stats
+	// are sampled to update the overload level.
+	admissionSampleInterval = time.Second
+)
+
+// admissionPriority is the priority with which work is admitted to a
+// store while its engine is overloaded.
+type admissionPriority int
+
+const (
+	// admissionLow is background work which can be delayed without
+	// affecting foreground traffic: bulk writes such as restores, schema
+	// change backfills, GC and snapshots.
+	admissionLow admissionPriority = iota
+	// admissionNormal is foreground traffic.
+	admissionNormal
+	// admissionSystem is traffic to the system ranges, such as node
+	// liveness heartbeats and meta range updates, which is never throttled.
+	admissionSystem
+)
+
+// overloadLevel describes how far an engine has fallen behind on flushes
+// and compactions.
+type overloadLevel int
+
+const (
+	notOverloaded overloadLevel = iota
+	overloaded
+	severelyOverloaded
+)
+
+// throttles returns whether work of the given priority is throttled at the
+// overload level.
+func (l overloadLevel) throttles(pri admissionPriority) bool {
+	switch pri {
+	case admissionLow:
+		return l >= overloaded
+	case admissionNormal:
+		return l >= severelyOverloaded
+	default:
+		return false
+	}
+}
+
+// admissionController throttles the work submitted to a store while the
+// store's engine falls behind on flushes and compactions, delaying low
+// priority work first so that foreground and system traffic keeps flowing
+// and RocksDB doesn't start stalling every writer. The overload level is
+// computed periodically from the engine's stats.
+type admissionController struct {
+	level      *metric.Gauge
+	queue      *metric.Gauge
+	queueNanos *metric.Counter
+
+	mu struct {
+		syncutil.Mutex
+		level overloadLevel
+		// levelChanged is closed and replaced whenever the level changes,
+		// waking up the throttled waiters.
+		levelChanged chan struct{}
+		// waiters is the number of throttled waiters.
+		waiters int64
+		// stallMicros is the engine's cumulative write stall time at the
+		// last update, used to detect new stalls.
+		stallMicros int64
+		sampled     bool
+	}
+}
+
+func newAdmissionController(
+	level *metric.Gauge, queue *metric.Gauge, queueNanos *metric.Counter,
+) *admissionController {
+	ac := &admissionController{
+		level:      level,
+		queue:      queue,
+		queueNanos: queueNanos,
+	}
+	ac.mu.levelChanged = make(chan struct{})
+	return ac
+}
+
+// exceeds returns whether the value is at or above the threshold, or the
+// given multiple of it. A non-positive threshold is never exceeded.
+func exceeds(value, threshold int64, multiplier float64) bool {
+	return threshold > 0 && float64(value) >= float64(threshold)*multiplier
+}
+
+// computeOverloadLevel returns the overload level of an engine with the
+// given stats, which stalled writes for stallMicros since the previous
+// stats were taken.
+func computeOverloadLevel(stats engine.Stats, stallMicros int64) overloadLevel {
+	l0 := admissionL0FileThreshold.Get()
+	pending := admissionPendingCompactionThreshold.Get()
+	switch {
+	case stallMicros > 0,
+		exceeds(stats.L0FileCount, l0, severeOverloadMultiplier),
+		exceeds(stats.PendingCompactionBytesEstimate, pending, severeOverloadMultiplier):
+		return severelyOverloaded
+	case exceeds(stats.L0FileCount, l0, 1),
+		exceeds(stats.PendingCompactionBytesEstimate, pending, 1),
+		exceeds(stats.ImmutableMemtableCount, admissionImmutableMemtableThreshold.Get(), 1):
+		return overloaded
+	}
+	return notOverloaded
+}
+
+// update recomputes the overload level from the engine's stats, returning
+// the previous and the new level.
+func (ac *admissionController) update(stats engine.Stats) (overloadLevel, overloadLevel) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	var stallMicros int64
+	// Stalls which predate the first update may be arbitrarily old.
+	if ac.mu.sampled {
+		stallMicros = stats.WriteStallMicros - ac.mu.stallMicros
+	}
+	ac.mu.stallMicros = stats.WriteStallMicros
+	ac.mu.sampled = true
+	prev := ac.mu.level
+	ac.mu.level = computeOverloadLevel(stats, stallMicros)
+	if ac.mu.level != prev {
+		close(ac.mu.levelChanged)
+		ac.mu.levelChanged = make(chan struct{})
+	}
+	ac.level.Update(int64(ac.mu.level))
+	return prev, ac.mu.level
+}
+
+// throttled returns whether work of the given priority is currently
+// throttled.
+func (ac *admissionController) throttled(pri admissionPriority) bool {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	return ac.mu.level.throttles(pri)
+}
+
+// admit blocks while work of the given priority is throttled, until the
+// engine has caught up, the context is canceled or the quiesce channel is
+// closed.
+func (ac *admissionController) admit(
+	ctx context.Context, pri admissionPriority, quiesce <-chan struct{},
+) error {
+	var start time.Time
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	for ac.mu.level.throttles(pri) {
+		if start.IsZero() {
+			start = timeutil.Now()
+			log.Event(ctx, "throttling request while engine is overloaded")
+			ac.mu.waiters++
+			ac.queue.Update(ac.mu.waiters)
+			defer func() {
+				ac.mu.waiters--
+				ac.queue.Update(ac.mu.waiters)
+				ac.queueNanos.Inc(timeutil.Since(start).Nanoseconds())
+			}()
+		}
+		levelChanged := ac.mu.levelChanged
+		ac.mu.Unlock()
+		var err error
+		select {
+		case <-levelChanged:
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-quiesce:
+			err = &roachpb.NodeUnavailableError{}
+		}
+		ac.mu.Lock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAdmissionPriority returns the priority with which a write batch is
+// admitted to the replica's store. Writes to ranges holding system data
+// are never throttled.
+func (r *Replica) writeAdmissionPriority(ba roachpb.BatchRequest) admissionPriority {
+	if r.Desc().StartKey.Less(roachpb.RKey(keys.UserTableDataMin)) {
+		return admissionSystem
+	}
+	if ba.Background {
+		return admissionLow
+	}
+	for _, union := range ba.Requests {
+		if _, ok := union.GetInner().(*roachpb.GCRequest); ok {
+			return admissionLow
+		}
+	}
+	return admissionNormal
+}
+
+// startAdmissionSampler starts a worker which periodically updates the
+// store's overload level from its engine's stats.
+func (s *Store) startAdmissionSampler() {
+	s.stopper.RunWorker(func() {
+		ctx := s.AnnotateCtx(context.Background())
+		ticker := time.NewTicker(admissionSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				stats, err := s.engine.GetStats()
+				if err != nil {
+					log.Warningf(ctx, "unable to update overload level: %s", err)
+					continue
+				}
+				if prev, level := s.admission.update(*stats); level != prev {
+					log.Infof(ctx, "overload level changed from %d to %d "+
+						"(L0 files: %d, pending compaction bytes: %d, immutable memtables: %d)",
+						prev, level, stats.L0FileCount, stats.PendingCompactionBytesEstimate,
+						stats.ImmutableMemtableCount)
+				}
+			case <-s.stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
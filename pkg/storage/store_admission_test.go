@@ -0,0 +1,120 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+)
+
+func newTestAdmissionController() *admissionController {
+	return newAdmissionController(metric.NewGauge(metaAdmissionOverloadLevel),
+		metric.NewGauge(metaAdmissionQueue), metric.NewCounter(metaAdmissionQueueNanos))
+}
+
+func TestAdmissionControllerOverloadLevel(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	l0 := admissionL0FileThreshold.Get()
+	pending := admissionPendingCompactionThreshold.Get()
+	testCases := []struct {
+		stats    engine.Stats
+		expected overloadLevel
+	}{
+		{engine.Stats{}, notOverloaded},
+		{engine.Stats{L0FileCount: l0 - 1}, notOverloaded},
+		{engine.Stats{L0FileCount: l0}, overloaded},
+		{engine.Stats{L0FileCount: l0 * 2}, severelyOverloaded},
+		{engine.Stats{PendingCompactionBytesEstimate: pending}, overloaded},
+		{engine.Stats{PendingCompactionBytesEstimate: pending * 2}, severelyOverloaded},
+		{engine.Stats{ImmutableMemtableCount: admissionImmutableMemtableThreshold.Get()}, overloaded},
+		// Write stalls count from the first update, and only new stalls
+		// severely overload the engine.
+		{engine.Stats{WriteStallMicros: 100}, severelyOverloaded},
+		{engine.Stats{WriteStallMicros: 100}, notOverloaded},
+	}
+	ac := newTestAdmissionController()
+	ac.update(engine.Stats{})
+	for i, c := range testCases {
+		if _, level := ac.update(c.stats); level != c.expected {
+			t.Errorf("%d: expected level %d, but got %d", i, c.expected, level)
+		}
+		for _, pri := range []admissionPriority{admissionLow, admissionNormal, admissionSystem} {
+			if throttled := ac.throttled(pri); throttled != c.expected.throttles(pri) {
+				t.Errorf("%d: expected priority %d throttled=%t, but got %t",
+					i, pri, c.expected.throttles(pri), throttled)
+			}
+		}
+	}
+	if ac.throttled(admissionSystem) || severelyOverloaded.throttles(admissionSystem) {
+		t.Error("expected system traffic to never be throttled")
+	}
+}
+
+func TestAdmissionControllerAdmit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ac := newTestAdmissionController()
+	ac.update(engine.Stats{L0FileCount: admissionL0FileThreshold.Get()})
+	ctx := context.Background()
+	quiesce := make(chan struct{})
+
+	// Normal priority work is admitted while low priority work waits.
+	if err := ac.admit(ctx, admissionNormal, quiesce); err != nil {
+		t.Fatal(err)
+	}
+	admitted := make(chan error, 1)
+	go func() {
+		admitted <- ac.admit(ctx, admissionLow, quiesce)
+	}()
+	util.SucceedsSoon(t, func() error {
+		if n := ac.queue.Value(); n != 1 {
+			return errors.Errorf("expected 1 throttled request, but got %d", n)
+		}
+		return nil
+	})
+	select {
+	case err := <-admitted:
+		t.Fatalf("expected low priority work to be throttled, but it was admitted: %v", err)
+	default:
+	}
+	ac.update(engine.Stats{})
+	if err := <-admitted; err != nil {
+		t.Fatal(err)
+	}
+	if ac.queue.Value() != 0 {
+		t.Fatalf("expected no throttled requests, but got %d", ac.queue.Value())
+	}
+
+	// Throttled work gives up when its context is canceled or the store is
+	// quiescing.
+	ac.update(engine.Stats{L0FileCount: admissionL0FileThreshold.Get()})
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := ac.admit(cancelCtx, admissionLow, quiesce); err != context.Canceled {
+		t.Fatalf("expected %s, but got %v", context.Canceled, err)
+	}
+	close(quiesce)
+	if err := ac.admit(ctx, admissionLow, quiesce); err == nil {
+		t.Fatal("expected an error while quiescing")
+	}
+}
@@ -23,13 +23,14 @@ import (
 	"sort"
 	"time"
 
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
-	"github.com/cockroachdb/cockroach/pkg/util/envutil"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/shuffle"
@@ -46,14 +47,43 @@ const (
 	// TestTimeUntilStoreDeadOff is the test value for TimeUntilStoreDead that
 	// prevents the store pool from marking stores as dead.
 	TestTimeUntilStoreDeadOff = 24 * time.Hour
+)
+
+// failedReservationsTimeout is the amount of time to consider the store
+// throttled for up-replication after a failed reservation call.
+var failedReservationsTimeout = settings.RegisterNonNegativeDurationSetting(
+	"server.failed_reservation_timeout",
+	"the amount of time to consider the store throttled for up-replication after a failed reservation call",
+	5*time.Second,
+)
 
-	// defaultFailedReservationsTimeout is the amount of time to consider the
-	// store throttled for up-replication after a failed reservation call.
-	defaultFailedReservationsTimeout = 5 * time.Second
+// declinedReservationsTimeout is the amount of time to consider the store
+// throttled for up-replication after a reservation was declined.
+var declinedReservationsTimeout = settings.RegisterNonNegativeDurationSetting(
+	"server.declined_reservation_timeout",
+	"the amount of time to consider the store throttled for up-replication after a reservation was declined",
+	0,
+)
 
-	// defaultDeclinedReservationsTimeout is the amount of time to consider the
-	// store throttled for up-replication after a reservation was declined.
-	defaultDeclinedReservationsTimeout = 0 * time.Second
+// timeUntilStoreDeadMinimum is the lowest value of TimeUntilStoreDead. Store
+// descriptors are gossiped every gossip.DefaultGossipStoresInterval; a store
+// must be able to miss several of those updates, e.g. while its node
+// restarts, without having all its replicas moved away.
+const timeUntilStoreDeadMinimum = time.Minute
+
+// TimeUntilStoreDead is the time after which, if there is no new gossiped
+// information about a store, it is considered dead. A StorePool created with
+// a non-zero timeUntilStoreDead ignores it.
+var TimeUntilStoreDead = settings.RegisterValidatedDurationSetting(
+	"server.time_until_store_dead",
+	"the time after which if there is no new gossiped information about a store, it is considered dead",
+	5*time.Minute,
+	func(v time.Duration) error {
+		if v < timeUntilStoreDeadMinimum {
+			return errors.Errorf("cannot be set to less than %s: %s", timeUntilStoreDeadMinimum, v)
+		}
+		return nil
+	},
 )
 
 type storeDetail struct {
@@ -203,24 +233,26 @@ func (pq *storePoolPQ) dequeue() *storeDetail {
 type StorePool struct {
 	log.AmbientContext
 
-	clock                       *hlc.Clock
-	timeUntilStoreDead          time.Duration
-	rpcContext                  *rpc.Context
-	failedReservationsTimeout   time.Duration
-	declinedReservationsTimeout time.Duration
-	resolver                    NodeAddressResolver
-	deterministic               bool
-	mu                          struct {
+	clock              *hlc.Clock
+	timeUntilStoreDead time.Duration
+	rpcContext         *rpc.Context
+	resolver           NodeAddressResolver
+	deterministic      bool
+	mu                 struct {
 		syncutil.RWMutex
 		// Each storeDetail is contained in both a map and a priorityQueue;
 		// pointers are used so that data can be kept in sync.
 		storeDetails map[roachpb.StoreID]*storeDetail
 		queue        storePoolPQ
+		// decommissioningNodes is the set of nodes whose liveness record
+		// is marked as decommissioning.
+		decommissioningNodes map[roachpb.NodeID]struct{}
 	}
 }
 
 // NewStorePool creates a StorePool and registers the store updating callback
-// with gossip.
+// with gossip. If timeUntilStoreDead is zero, the pool follows the
+// TimeUntilStoreDead cluster setting.
 func NewStorePool(
 	ambient log.AmbientContext,
 	g *gossip.Gossip,
@@ -235,19 +267,18 @@ func NewStorePool(
 		clock:              clock,
 		timeUntilStoreDead: timeUntilStoreDead,
 		rpcContext:         rpcContext,
-		failedReservationsTimeout: envutil.EnvOrDefaultDuration("COCKROACH_FAILED_RESERVATION_TIMEOUT",
-			defaultFailedReservationsTimeout),
-		declinedReservationsTimeout: envutil.EnvOrDefaultDuration("COCKROACH_DECLINED_RESERVATION_TIMEOUT",
-			defaultDeclinedReservationsTimeout),
-		resolver:      GossipAddressResolver(g),
-		deterministic: deterministic,
+		resolver:           GossipAddressResolver(g),
+		deterministic:      deterministic,
 	}
 	sp.mu.storeDetails = make(map[roachpb.StoreID]*storeDetail)
+	sp.mu.decommissioningNodes = make(map[roachpb.NodeID]struct{})
 	heap.Init(&sp.mu.queue)
 	storeRegex := gossip.MakePrefixPattern(gossip.KeyStorePrefix)
 	g.RegisterCallback(storeRegex, sp.storeGossipUpdate)
 	deadReplicasRegex := gossip.MakePrefixPattern(gossip.KeyDeadReplicasPrefix)
 	g.RegisterCallback(deadReplicasRegex, sp.deadReplicasGossipUpdate)
+	livenessRegex := gossip.MakePrefixPattern(gossip.KeyNodeLivenessPrefix)
+	g.RegisterCallback(livenessRegex, sp.livenessGossipUpdate)
 	sp.start(stopper)
 
 	return sp
@@ -319,6 +350,34 @@ func (sp *StorePool) deadReplicasGossipUpdate(_ string, content roachpb.Value) {
 	detail.deadReplicas = deadReplicas
 }
 
+// livenessGossipUpdate is the gossip callback used to keep track of the
+// nodes which are being decommissioned.
+func (sp *StorePool) livenessGossipUpdate(_ string, content roachpb.Value) {
+	var liveness Liveness
+	if err := content.GetProto(&liveness); err != nil {
+		ctx := sp.AnnotateCtx(context.TODO())
+		log.Error(ctx, err)
+		return
+	}
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	if liveness.Decommissioning {
+		sp.mu.decommissioningNodes[liveness.NodeID] = struct{}{}
+	} else {
+		delete(sp.mu.decommissioningNodes, liveness.NodeID)
+	}
+}
+
+// getTimeUntilStoreDead returns the time after which a store which hasn't
+// been heard from is considered dead.
+func (sp *StorePool) getTimeUntilStoreDead() time.Duration {
+	if sp.timeUntilStoreDead != 0 {
+		return sp.timeUntilStoreDead
+	}
+	return TimeUntilStoreDead.Get()
+}
+
 // start will run continuously and mark stores as offline if they haven't been
 // heard from in longer than timeUntilStoreDead.
 func (sp *StorePool) start(stopper *stop.Stopper) {
@@ -331,10 +390,10 @@ func (sp *StorePool) start(stopper *stop.Stopper) {
 			detail := sp.mu.queue.peek()
 			if detail == nil {
 				// No stores yet, wait the full timeout.
-				timeout = sp.timeUntilStoreDead
+				timeout = sp.getTimeUntilStoreDead()
 			} else {
 				// Check to see if the store should be marked as dead.
-				deadAsOf := detail.lastUpdatedTime.GoTime().Add(sp.timeUntilStoreDead)
+				deadAsOf := detail.lastUpdatedTime.GoTime().Add(sp.getTimeUntilStoreDead())
 				now := sp.clock.Now()
 				if now.GoTime().After(deadAsOf) {
 					deadDetail := sp.mu.queue.dequeue()
@@ -431,6 +490,23 @@ outer:
 	return deadReplicas
 }
 
+// decommissioningReplicas returns any replicas from the supplied slice that
+// are located on decommissioning nodes.
+func (sp *StorePool) decommissioningReplicas(
+	repls []roachpb.ReplicaDescriptor,
+) []roachpb.ReplicaDescriptor {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+
+	var decommissioningReplicas []roachpb.ReplicaDescriptor
+	for _, repl := range repls {
+		if _, ok := sp.mu.decommissioningNodes[repl.NodeID]; ok {
+			decommissioningReplicas = append(decommissioningReplicas, repl)
+		}
+	}
+	return decommissioningReplicas
+}
+
 // stat provides a running sample size and running stats.
 type stat struct {
 	n, mean, s float64
@@ -512,8 +588,9 @@ storeLoop:
 }
 
 // getStoreList returns a storeList that contains all active stores that
-// contain the required attributes and their associated stats. It also returns
-// the total number of alive and throttled stores.
+// contain the required attributes and their associated stats. Stores on
+// decommissioning nodes are excluded. It also returns the total number of
+// alive and throttled stores.
 func (sp *StorePool) getStoreList(rangeID roachpb.RangeID) (StoreList, int, int) {
 	sp.mu.RLock()
 	defer sp.mu.RUnlock()
@@ -536,6 +613,11 @@ func (sp *StorePool) getStoreList(rangeID roachpb.RangeID) (StoreList, int, int)
 	now := sp.clock.PhysicalTime()
 	for _, storeID := range storeIDs {
 		detail := sp.mu.storeDetails[storeID]
+		if detail.desc != nil {
+			if _, ok := sp.mu.decommissioningNodes[detail.desc.Node.NodeID]; ok {
+				continue
+			}
+		}
 		switch detail.status(now, rangeID) {
 		case storeStatusThrottled:
 			aliveStoreCount++
@@ -576,16 +658,18 @@ func (sp *StorePool) throttle(reason throttleReason, toStoreID roachpb.StoreID)
 	// timeout period has passed.
 	switch reason {
 	case throttleDeclined:
-		detail.throttledUntil = sp.clock.Now().GoTime().Add(sp.declinedReservationsTimeout)
+		timeout := declinedReservationsTimeout.Get()
+		detail.throttledUntil = sp.clock.Now().GoTime().Add(timeout)
 		if log.V(2) {
 			log.Infof(ctx, "snapshot declined, store:%s will be throttled for %s until %s",
-				toStoreID, sp.declinedReservationsTimeout, detail.throttledUntil)
+				toStoreID, timeout, detail.throttledUntil)
 		}
 	case throttleFailed:
-		detail.throttledUntil = sp.clock.Now().GoTime().Add(sp.failedReservationsTimeout)
+		timeout := failedReservationsTimeout.Get()
+		detail.throttledUntil = sp.clock.Now().GoTime().Add(timeout)
 		if log.V(2) {
 			log.Infof(ctx, "snapshot failed, store:%s will be throttled for %s until %s",
-				toStoreID, sp.failedReservationsTimeout, detail.throttledUntil)
+				toStoreID, timeout, detail.throttledUntil)
 		}
 	}
 }
@@ -501,7 +501,7 @@ func TestStorePoolThrottle(t *testing.T) {
 	sg.GossipStores(uniqueStore, t)
 
 	{
-		expected := sp.clock.Now().GoTime().Add(sp.declinedReservationsTimeout)
+		expected := sp.clock.Now().GoTime().Add(declinedReservationsTimeout.Get())
 		sp.throttle(throttleDeclined, 1)
 
 		sp.mu.Lock()
@@ -514,7 +514,7 @@ func TestStorePoolThrottle(t *testing.T) {
 	}
 
 	{
-		expected := sp.clock.Now().GoTime().Add(sp.failedReservationsTimeout)
+		expected := sp.clock.Now().GoTime().Add(failedReservationsTimeout.Get())
 		sp.throttle(throttleFailed, 1)
 
 		sp.mu.Lock()
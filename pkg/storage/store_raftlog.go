@@ -0,0 +1,298 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/coreos/etcd/raft/raftpb"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/raftlog"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+// enableRaftLogFiles controls whether the Raft log entries of the store's
+// replicas are kept in segment files next to the store's engine instead of
+// in the engine itself. This avoids writing every entry to the engine's
+// write-ahead log and memtables and compacting it into sstables, most of
+// which is wasted work since entries are usually truncated soon after
+// they're applied. Entries are moved between the engine and the files when
+// the store is started with a different setting.
+//
+// The cluster settings aren't known yet when a store starts, so the store
+// records the value it last saw next to its engine (see
+// RecordClusterSettings) and uses that one instead.
+var enableRaftLogFiles = settings.RegisterBoolSetting(
+	"kv.raft_log.files.enabled",
+	"set to true to keep the raft log entries of replicas in files next to their store's engine instead of in the engine (takes effect when stores are restarted)",
+	false,
+)
+
+// raftLogFilesMarker is the name of the file, in the store's auxiliary
+// directory, whose presence records that the Raft log files are enabled.
+const raftLogFilesMarker = "raftlog-enabled"
+
+// RecordClusterSettings records the values of the cluster settings which
+// only take effect when the store is started. It is called every time the
+// cluster settings are updated.
+func (s *Store) RecordClusterSettings() error {
+	rocksdb, ok := s.engine.(*engine.RocksDB)
+	if !ok || rocksdb.AuxiliaryDir() == "" {
+		return nil
+	}
+	marker := filepath.Join(rocksdb.AuxiliaryDir(), raftLogFilesMarker)
+	if !enableRaftLogFiles.Get() {
+		if err := os.Remove(marker); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		return err
+	}
+	f, err := os.Create(marker)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// raftLogFilesEnabled returns whether the Raft log files were enabled the
+// last time the store recorded the cluster settings.
+func (s *Store) raftLogFilesEnabled() (bool, error) {
+	rocksdb := s.engine.(*engine.RocksDB)
+	_, err := os.Stat(filepath.Join(rocksdb.AuxiliaryDir(), raftLogFilesMarker))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// raftLogDir returns the directory of the store's Raft log segment files, or
+// the empty string if the store's engine doesn't have a directory for them.
+func (s *Store) raftLogDir() string {
+	rocksdb, ok := s.engine.(*engine.RocksDB)
+	if !ok || rocksdb.AuxiliaryDir() == "" {
+		return ""
+	}
+	return filepath.Join(rocksdb.AuxiliaryDir(), raftlog.DirName)
+}
+
+// openRaftLog opens the store's Raft log files if they're enabled, moving
+// the entries kept in the engine into them, or moves the entries kept in the
+// files back into the engine if they're disabled. The files of encrypted
+// stores aren't used, since they wouldn't be encrypted. It must be called
+// before the store starts processing Raft messages.
+func (s *Store) openRaftLog(ctx context.Context) error {
+	dir := s.raftLogDir()
+	if dir == "" {
+		return nil
+	}
+	enabled, err := s.raftLogFilesEnabled()
+	if err != nil {
+		return err
+	}
+	if enabled && s.engine.(*engine.RocksDB).Encrypted() {
+		log.Warningf(ctx, "keeping the raft log in the engine since the store is encrypted")
+		enabled = false
+	}
+	if !enabled {
+		exists, err := raftlog.Exists(dir)
+		if err != nil || !exists {
+			return err
+		}
+		raftLog, err := raftlog.Open(dir, raftlog.DefaultSegmentSize)
+		if err != nil {
+			return err
+		}
+		if err := migrateRaftLogFromFiles(ctx, s.engine, raftLog); err != nil {
+			_ = raftLog.Close()
+			return err
+		}
+		if err := raftLog.Close(); err != nil {
+			return err
+		}
+		return os.RemoveAll(dir)
+	}
+
+	raftLog, err := raftlog.Open(dir, raftlog.DefaultSegmentSize)
+	if err != nil {
+		return err
+	}
+	s.stopper.AddCloser(stop.CloserFn(func() {
+		if err := raftLog.Close(); err != nil {
+			log.Warningf(ctx, "error closing raft log: %s", err)
+		}
+	}))
+	if err := migrateRaftLogToFiles(ctx, s.engine, raftLog); err != nil {
+		return err
+	}
+	if err := reconcileRaftLogFiles(ctx, s.engine, raftLog); err != nil {
+		return err
+	}
+	s.raftLog = raftLog
+	return nil
+}
+
+// raftLogBounds returns the first and last index of the range's Raft log,
+// as persisted in the engine.
+func raftLogBounds(
+	ctx context.Context, eng engine.Reader, rangeID roachpb.RangeID,
+) (uint64, uint64, error) {
+	truncState, err := loadTruncatedState(ctx, eng, rangeID)
+	if err != nil {
+		return 0, 0, err
+	}
+	lastIndex, err := loadLastIndex(ctx, eng, rangeID)
+	if err != nil {
+		return 0, 0, err
+	}
+	return truncState.Index + 1, lastIndex, nil
+}
+
+// migrateRaftLogToFiles moves the Raft log entries kept in the engine into
+// the segment files. The entries are synced to the files before they're
+// removed from the engine, so the migration can be resumed if it's
+// interrupted.
+func migrateRaftLogToFiles(ctx context.Context, eng engine.Engine, raftLog *raftlog.Log) error {
+	var migrated int
+	err := IterateRangeDescriptors(ctx, eng, func(desc roachpb.RangeDescriptor) (bool, error) {
+		firstIndex, lastIndex, err := raftLogBounds(ctx, eng, desc.RangeID)
+		if err != nil {
+			return false, err
+		}
+		var ents []raftpb.Entry
+		if err := iterateEntries(ctx, eng, nil /* raftLog */, desc.RangeID, firstIndex, lastIndex+1,
+			func(kv roachpb.KeyValue) (bool, error) {
+				var ent raftpb.Entry
+				if err := kv.Value.GetProto(&ent); err != nil {
+					return false, err
+				}
+				ents = append(ents, ent)
+				return false, nil
+			}); err != nil {
+			return false, err
+		}
+		if len(ents) == 0 {
+			return false, nil
+		}
+		if _, err := raftLog.Append(desc.RangeID, ents); err != nil {
+			return false, err
+		}
+		batch := eng.NewBatch()
+		defer batch.Close()
+		prefix := keys.RaftLogPrefix(desc.RangeID)
+		if _, err := engine.ClearRange(batch, engine.MakeMVCCMetadataKey(prefix),
+			engine.MakeMVCCMetadataKey(prefix.PrefixEnd())); err != nil {
+			return false, err
+		}
+		migrated++
+		return false, batch.Commit()
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to move raft log into files")
+	}
+	if migrated > 0 {
+		log.Infof(ctx, "moved the raft logs of %d ranges into files", migrated)
+	}
+	return nil
+}
+
+// migrateRaftLogFromFiles moves the Raft log entries kept in the segment
+// files back into the engine. The caller removes the files once it returns.
+func migrateRaftLogFromFiles(ctx context.Context, eng engine.Engine, raftLog *raftlog.Log) error {
+	var migrated int
+	err := IterateRangeDescriptors(ctx, eng, func(desc roachpb.RangeDescriptor) (bool, error) {
+		firstIndex, lastIndex, err := raftLogBounds(ctx, eng, desc.RangeID)
+		if err != nil {
+			return false, err
+		}
+		if !raftLog.Contains(desc.RangeID, firstIndex, lastIndex+1) {
+			return false, errors.Errorf("raft log files are missing entries in [%d, %d] of r%d",
+				firstIndex, lastIndex, desc.RangeID)
+		}
+		if firstIndex > lastIndex {
+			return false, nil
+		}
+		batch := eng.NewBatch()
+		defer batch.Close()
+		var value roachpb.Value
+		if err := raftLog.Iterate(desc.RangeID, firstIndex, lastIndex+1,
+			func(index uint64, data []byte) (bool, error) {
+				// This is the encoding used by Value.SetProto for the entry.
+				key := keys.RaftLogKey(desc.RangeID, index)
+				value.SetBytes(data)
+				value.InitChecksum(key)
+				return false, engine.MVCCPut(ctx, batch, nil /* ms */, key, hlc.ZeroTimestamp,
+					value, nil /* txn */)
+			}); err != nil {
+			return false, err
+		}
+		migrated++
+		return false, batch.Commit()
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to move raft log out of files")
+	}
+	log.Infof(ctx, "moved the raft logs of %d ranges out of files", migrated)
+	return nil
+}
+
+// reconcileRaftLogFiles removes the entries of the segment files which
+// aren't part of the Raft logs persisted in the engine. These are entries
+// which were appended, truncated or destroyed by operations interrupted
+// before they removed them from the files, as well as the entries which
+// reappeared since the segments they were written to weren't deleted.
+func reconcileRaftLogFiles(ctx context.Context, eng engine.Engine, raftLog *raftlog.Log) error {
+	rangeIDs := make(map[roachpb.RangeID]struct{})
+	err := IterateRangeDescriptors(ctx, eng, func(desc roachpb.RangeDescriptor) (bool, error) {
+		rangeIDs[desc.RangeID] = struct{}{}
+		firstIndex, lastIndex, err := raftLogBounds(ctx, eng, desc.RangeID)
+		if err != nil {
+			return false, err
+		}
+		if err := raftLog.Truncate(desc.RangeID, firstIndex); err != nil {
+			return false, err
+		}
+		if err := raftLog.TruncateAfter(desc.RangeID, lastIndex); err != nil {
+			return false, err
+		}
+		if !raftLog.Contains(desc.RangeID, firstIndex, lastIndex+1) {
+			return false, errors.Errorf("raft log files are missing entries in [%d, %d] of r%d",
+				firstIndex, lastIndex, desc.RangeID)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, rangeID := range raftLog.RangeIDs() {
+		if _, ok := rangeIDs[rangeID]; !ok {
+			if err := raftLog.Destroy(rangeID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/coreos/etcd/raft"
+	"github.com/coreos/etcd/raft/raftpb"
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/raftlog"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestRaftLogFilesMigration verifies that the raft log of a range is moved
+// from the engine into raft log files and back without losing entries, and
+// that the entries of the files outside of the raft log bounds persisted in
+// the engine are removed when the store is started.
+func TestRaftLogFilesMigration(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanup := testutils.TempDir(t, 0)
+	defer cleanup()
+
+	eng, err := engine.NewRocksDB(
+		roachpb.Attributes{}, dir, engine.RocksDBCache{}, 0, engine.DefaultMaxOpenFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eng.Close()
+
+	ctx := context.Background()
+	const rangeID = roachpb.RangeID(1)
+	desc := roachpb.RangeDescriptor{
+		RangeID:  rangeID,
+		StartKey: roachpb.RKeyMin,
+		EndKey:   roachpb.RKeyMax,
+	}
+	if err := engine.MVCCPutProto(ctx, eng, nil, keys.RangeDescriptorKey(desc.StartKey),
+		hlc.ZeroTimestamp, nil, &desc); err != nil {
+		t.Fatal(err)
+	}
+	// The log contains the entries in [11, 20].
+	if err := engine.MVCCPutProto(ctx, eng, nil, keys.RaftTruncatedStateKey(rangeID),
+		hlc.ZeroTimestamp, nil, &roachpb.RaftTruncatedState{Index: 10, Term: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := setLastIndex(ctx, eng, rangeID, 20); err != nil {
+		t.Fatal(err)
+	}
+	var expected []raftpb.Entry
+	for i := uint64(11); i <= 20; i++ {
+		ent := raftpb.Entry{Index: i, Term: 5, Data: []byte(fmt.Sprintf("entry %d", i))}
+		if err := engine.MVCCPutProto(ctx, eng, nil, keys.RaftLogKey(rangeID, i),
+			hlc.ZeroTimestamp, nil, &ent); err != nil {
+			t.Fatal(err)
+		}
+		expected = append(expected, ent)
+	}
+
+	checkEntries := func(raftLog *raftlog.Log) {
+		ents, err := entries(ctx, eng, raftLog, rangeID, newRaftEntryCache(100), 11, 21, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkRaftEntries(t, ents, expected)
+	}
+
+	logDir := filepath.Join(eng.AuxiliaryDir(), raftlog.DirName)
+	raftLog, err := raftlog.Open(logDir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateRaftLogToFiles(ctx, eng, raftLog); err != nil {
+		t.Fatal(err)
+	}
+	checkEntries(raftLog)
+	kvs, err := engine.Scan(eng, engine.MakeMVCCMetadataKey(keys.RaftLogPrefix(rangeID)),
+		engine.MakeMVCCMetadataKey(keys.RaftLogPrefix(rangeID).PrefixEnd()), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 0 {
+		t.Fatalf("expected the raft log to be removed from the engine, but found %d entries", len(kvs))
+	}
+
+	// Entries which were appended but never committed, those of a range
+	// which was destroyed, and those which were truncated are removed.
+	if _, err := raftLog.Append(rangeID, []raftpb.Entry{{Index: 21, Term: 6}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := raftLog.Append(rangeID+1, []raftpb.Entry{{Index: 11, Term: 5}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := raftLog.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.MVCCPutProto(ctx, eng, nil, keys.RaftTruncatedStateKey(rangeID),
+		hlc.ZeroTimestamp, nil, &roachpb.RaftTruncatedState{Index: 12, Term: 5}); err != nil {
+		t.Fatal(err)
+	}
+	expected = expected[2:]
+	if raftLog, err = raftlog.Open(logDir, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileRaftLogFiles(ctx, eng, raftLog); err != nil {
+		t.Fatal(err)
+	}
+	if rangeIDs := raftLog.RangeIDs(); len(rangeIDs) != 1 || rangeIDs[0] != rangeID {
+		t.Fatalf("expected the raft log of r%d only, but got %v", rangeID, rangeIDs)
+	}
+	if raftLog.Contains(rangeID, 12, 13) || raftLog.Contains(rangeID, 21, 22) {
+		t.Fatal("expected the entries outside of the raft log to be removed")
+	}
+	if _, err := entries(ctx, eng, raftLog, rangeID, newRaftEntryCache(100), 11, 21, 0); err != raft.ErrCompacted {
+		t.Fatalf("expected %s, but got %v", raft.ErrCompacted, err)
+	}
+
+	if err := migrateRaftLogFromFiles(ctx, eng, raftLog); err != nil {
+		t.Fatal(err)
+	}
+	if err := raftLog.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ents, err := entries(ctx, eng, nil, rangeID, newRaftEntryCache(100), 13, 21, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRaftEntries(t, ents, expected)
+}
+
+func checkRaftEntries(t *testing.T, ents, expected []raftpb.Entry) {
+	if len(ents) != len(expected) {
+		t.Fatalf("expected %d entries, but got %d", len(expected), len(ents))
+	}
+	for i := range ents {
+		if ents[i].Index != expected[i].Index || ents[i].Term != expected[i].Term ||
+			!bytes.Equal(ents[i].Data, expected[i].Data) {
+			t.Fatalf("expected entry %+v, but got %+v", expected[i], ents[i])
+		}
+	}
+}
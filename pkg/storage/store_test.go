@@ -2566,7 +2566,7 @@ func TestSendSnapshotThrottling(t *testing.T) {
 		sp := &fakeStorePool{}
 		expectedErr := errors.New("")
 		c := fakeSnapshotStream{nil, expectedErr}
-		err := sendSnapshot(ctx, c, sp, header, snap, newBatch)
+		err := sendSnapshot(ctx, c, sp, nil, header, snap, newBatch)
 		if sp.failedThrottles != 1 {
 			t.Fatalf("expected 1 failed throttle, but found %d", sp.failedThrottles)
 		}
@@ -2584,7 +2584,7 @@ func TestSendSnapshotThrottling(t *testing.T) {
 			Status:        SnapshotResponse_DECLINED,
 		}
 		c := fakeSnapshotStream{resp, nil}
-		err := sendSnapshot(ctx, c, sp, header, snap, newBatch)
+		err := sendSnapshot(ctx, c, sp, nil, header, snap, newBatch)
 		if sp.declinedThrottles != 1 {
 			t.Fatalf("expected 1 declined throttle, but found %d", sp.declinedThrottles)
 		}
@@ -2607,7 +2607,7 @@ func TestSendSnapshotThrottling(t *testing.T) {
 			Status:        SnapshotResponse_DECLINED,
 		}
 		c := fakeSnapshotStream{resp, nil}
-		err := sendSnapshot(ctx, c, sp, header, snap, newBatch)
+		err := sendSnapshot(ctx, c, sp, nil, header, snap, newBatch)
 		if sp.failedThrottles != 1 {
 			t.Fatalf("expected 1 failed throttle, but found %d", sp.failedThrottles)
 		}
@@ -2627,7 +2627,7 @@ func TestSendSnapshotThrottling(t *testing.T) {
 			Status:        SnapshotResponse_ERROR,
 		}
 		c := fakeSnapshotStream{resp, nil}
-		err := sendSnapshot(ctx, c, sp, header, snap, newBatch)
+		err := sendSnapshot(ctx, c, sp, nil, header, snap, newBatch)
 		if sp.failedThrottles != 1 {
 			t.Fatalf("expected 1 failed throttle, but found %d", sp.failedThrottles)
 		}
@@ -191,6 +191,22 @@ func (ls *Stores) Send(
 	return br, pErr
 }
 
+// RangeFeed registers a RangeFeed over the specified span with the store
+// addressed by the request. It sends events to the provided sink and
+// returns with an error when the feed terminates.
+func (ls *Stores) RangeFeed(
+	args *roachpb.RangeFeedRequest, stream RangeFeedEventSink,
+) *roachpb.Error {
+	if args.RangeID == 0 || args.Replica.StoreID == 0 {
+		return roachpb.NewErrorf("rangefeed request must specify a range and replica: %s", args)
+	}
+	store, err := ls.GetStore(args.Replica.StoreID)
+	if err != nil {
+		return roachpb.NewError(err)
+	}
+	return store.RangeFeed(args, stream)
+}
+
 // LookupReplica looks up replica by key [range]. Lookups are done
 // by consulting each store in turn via Store.LookupReplica(key).
 // Returns RangeID and replica on success; RangeKeyMismatch error
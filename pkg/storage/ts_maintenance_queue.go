@@ -40,15 +40,15 @@ const (
 // maintenance can then be informed by data from the local store.
 type TimeSeriesDataStore interface {
 	ContainsTimeSeries(roachpb.RKey, roachpb.RKey) bool
-	PruneTimeSeries(
+	MaintainTimeSeries(
 		context.Context, engine.Reader, roachpb.RKey, roachpb.RKey, *client.DB, hlc.Timestamp,
 	) error
 }
 
 // timeSeriesMaintenanceQueue identifies replicas that contain time series
 // data and performs necessary data maintenance on the time series located in
-// the replica. Currently, maintenance involves pruning time series data older
-// than a certain threshold.
+// the replica. Currently, maintenance involves rolling up time series data
+// older than a certain threshold into a lower resolution, and then pruning it.
 //
 // Logic for time series maintenance is implemented in a higher level time
 // series package; this queue uses the TimeSeriesDataStore interface to call
@@ -66,10 +66,10 @@ type TimeSeriesDataStore interface {
 // effectively prune time series without expensive distributed scans.
 //
 // Data changes executed by this queue are idempotent; it is explicitly safe
-// for multiple nodes to attempt to prune the same time series concurrently.
-// In this situation, each node would compute the same delete range based on
-// the current timestamp; the first will succeed, all others will become
-// a no-op.
+// for multiple nodes to attempt to maintain the same time series concurrently.
+// In this situation, each node would compute the same rollups and the same
+// delete range based on the current timestamp; the first will succeed, all
+// others will become a no-op.
 type timeSeriesMaintenanceQueue struct {
 	*baseQueue
 	tsData TimeSeriesDataStore
@@ -114,7 +114,7 @@ func (tsmq *timeSeriesMaintenanceQueue) process(
 	desc := repl.Desc()
 	snap := repl.store.Engine().NewSnapshot()
 	defer snap.Close()
-	return tsmq.tsData.PruneTimeSeries(ctx, snap, desc.StartKey, desc.EndKey, tsmq.db, now)
+	return tsmq.tsData.MaintainTimeSeries(ctx, snap, desc.StartKey, desc.EndKey, tsmq.db, now)
 }
 
 func (tsmq *timeSeriesMaintenanceQueue) timer() time.Duration {
@@ -41,11 +41,11 @@ import (
 
 type modelTimeSeriesDataStore struct {
 	syncutil.Mutex
-	t                  testing.TB
-	containsCalled     int
-	pruneCalled        int
-	pruneSeenStartKeys map[string]struct{}
-	pruneSeenEndKeys   map[string]struct{}
+	t                     testing.TB
+	containsCalled        int
+	maintainCalled        int
+	maintainSeenStartKeys map[string]struct{}
+	maintainSeenEndKeys   map[string]struct{}
 }
 
 func (m *modelTimeSeriesDataStore) ContainsTimeSeries(start, end roachpb.RKey) bool {
@@ -58,7 +58,7 @@ func (m *modelTimeSeriesDataStore) ContainsTimeSeries(start, end roachpb.RKey) b
 	return true
 }
 
-func (m *modelTimeSeriesDataStore) PruneTimeSeries(
+func (m *modelTimeSeriesDataStore) MaintainTimeSeries(
 	ctx context.Context,
 	snapshot engine.Reader,
 	start, end roachpb.RKey,
@@ -66,20 +66,20 @@ func (m *modelTimeSeriesDataStore) PruneTimeSeries(
 	now hlc.Timestamp,
 ) error {
 	if snapshot == nil {
-		m.t.Fatal("PruneTimeSeries was passed a nil snapshot")
+		m.t.Fatal("MaintainTimeSeries was passed a nil snapshot")
 	}
 	if db == nil {
-		m.t.Fatal("PruneTimeSeries was passed a nil client.DB")
+		m.t.Fatal("MaintainTimeSeries was passed a nil client.DB")
 	}
 	if !start.Less(end) {
-		m.t.Fatalf("PruneTimeSeries passed start key %v which is not less than end key %v", start, end)
+		m.t.Fatalf("MaintainTimeSeries passed start key %v which is not less than end key %v", start, end)
 	}
 
 	m.Lock()
 	defer m.Unlock()
-	m.pruneCalled++
-	m.pruneSeenStartKeys[start.String()] = struct{}{}
-	m.pruneSeenEndKeys[end.String()] = struct{}{}
+	m.maintainCalled++
+	m.maintainSeenStartKeys[start.String()] = struct{}{}
+	m.maintainSeenEndKeys[end.String()] = struct{}{}
 	return nil
 }
 
@@ -89,9 +89,9 @@ func TestTimeSeriesMaintenanceQueue(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
 	model := &modelTimeSeriesDataStore{
-		t:                  t,
-		pruneSeenStartKeys: make(map[string]struct{}),
-		pruneSeenEndKeys:   make(map[string]struct{}),
+		t:                     t,
+		maintainSeenStartKeys: make(map[string]struct{}),
+		maintainSeenEndKeys:   make(map[string]struct{}),
 	}
 
 	cfg := storage.TestStoreConfig(nil)
@@ -147,19 +147,19 @@ func TestTimeSeriesMaintenanceQueue(t *testing.T) {
 		if a, e := model.containsCalled, len(expectedStartKeys); a != e {
 			return fmt.Errorf("ContainsTimeSeries called %d times; expected %d", a, e)
 		}
-		if a, e := model.pruneCalled, len(expectedStartKeys); a != e {
-			return fmt.Errorf("PruneTimeSeries called %d times; expected %d", a, e)
+		if a, e := model.maintainCalled, len(expectedStartKeys); a != e {
+			return fmt.Errorf("MaintainTimeSeries called %d times; expected %d", a, e)
 		}
 		return nil
 	})
 
 	model.Lock()
 	defer model.Unlock()
-	if a, e := model.pruneSeenStartKeys, expectedStartKeys; !reflect.DeepEqual(a, e) {
-		t.Errorf("start keys seen by PruneTimeSeries did not match expectation: %s", pretty.Diff(a, e))
+	if a, e := model.maintainSeenStartKeys, expectedStartKeys; !reflect.DeepEqual(a, e) {
+		t.Errorf("start keys seen by MaintainTimeSeries did not match expectation: %s", pretty.Diff(a, e))
 	}
-	if a, e := model.pruneSeenEndKeys, expectedEndKeys; !reflect.DeepEqual(a, e) {
-		t.Errorf("end keys seen by PruneTimeSeries did not match expectation: %s", pretty.Diff(a, e))
+	if a, e := model.maintainSeenEndKeys, expectedEndKeys; !reflect.DeepEqual(a, e) {
+		t.Errorf("end keys seen by MaintainTimeSeries did not match expectation: %s", pretty.Diff(a, e))
 	}
 }
 
@@ -259,4 +259,30 @@ func TestTimeSeriesMaintenanceQueueServer(t *testing.T) {
 		}
 		return nil
 	})
+
+	// Verify the pruned datapoints have been rolled up to the lower resolution.
+	rollupDuration := ts.Resolution30m.SampleDuration()
+	rollupDatapoint := func(dp tspb.TimeSeriesDatapoint) tspb.TimeSeriesDatapoint {
+		return tspb.TimeSeriesDatapoint{
+			TimestampNanos: dp.TimestampNanos - dp.TimestampNanos%rollupDuration + rollupDuration/2,
+			Value:          dp.Value,
+		}
+	}
+	rollups, _, err := tsdb.Query(
+		context.TODO(),
+		tspb.Query{Name: seriesName},
+		ts.Resolution30m,
+		rollupDuration,
+		0,
+		now+ts.Resolution30m.SlabDuration(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRollups := []tspb.TimeSeriesDatapoint{
+		rollupDatapoint(datapoints[0]), rollupDatapoint(datapoints[1]),
+	}
+	if a, e := rollups, expectedRollups; !reflect.DeepEqual(a, e) {
+		t.Fatalf("got rollups %v, expected %v, diff: %s", a, e, pretty.Diff(a, e))
+	}
 }
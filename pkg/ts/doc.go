@@ -90,11 +90,10 @@ different nodes, will be stored contiguously in the key space. Data from all
 sources in a series can thus be queried in a single scan.
 
 
-Unused Feature: Multiple resolutions
+Multiple resolutions
 
-CockroachDB time series database has rudimentary support for a planned feature:
-recording the same series at multiple sample durations, commonly known as a
-"rollup".
+CockroachDB time series database supports recording the same series at multiple
+sample durations, commonly known as a "rollup".
 
 For example, a single series may be recorded with a sample size of 10 seconds,
 but also record the same data with a sample size of 1 hour. The 1 hour data will
@@ -107,10 +106,34 @@ supports a fixed set of Resolutions; each Resolution has a fixed sample duration
 and a slab duration. For example, the resolution "Resolution10s" has a sample
 duration of 10 seconds and a slab duration of 1 hour.
 
-This feature was planned and slightly informs our key structure (resolution
-information is encoded in every time series key); however, all time series in
-CockroachDB are currently recorded at a downsample duration of 10 seconds, and a
-slab duration of 1 hour.
+All time series in CockroachDB are recorded at a sample duration of 10 seconds,
+and a slab duration of 1 hour; this data is retained for 30 days. Before it is
+pruned, 10 second data is rolled up into the resolution "Resolution30m", which
+has a sample duration of 30 minutes and a slab duration of 1 day, and is
+retained for a year. Each rolled up sample keeps the count, sum, minimum and
+maximum of the samples it summarizes, so that all downsamplers can be applied
+to rolled up data. Rollups are computed by the time series maintenance queue,
+which also prunes old data.
+
+Queries over a time span longer than the retention period of 10 second data
+are served from the rolled up data.
+
+
+Queries
+
+A query names a single series and describes how it is reduced to one value per
+sample period: a downsampler combines the samples of each source within a
+period, and a source aggregator combines the downsampled values of all sources
+(e.g. their sum, maximum, count or a percentile). A query may instead ask for
+the downsampled values of each source separately.
+
+Queries can also be expressed textually, in which case they may be combined
+with arithmetic; for example, the following computes the fraction of
+connections used on each node:
+
+	by_source(cr.node.sql.conns) / 100
+
+See textquery.go for the full syntax.
 
 
 Example
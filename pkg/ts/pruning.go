@@ -38,19 +38,20 @@ func (tsdb *DB) ContainsTimeSeries(start, end roachpb.RKey) bool {
 	return !lastTSRKey.Less(start) && !end.Less(firstTSRKey)
 }
 
-// PruneTimeSeries prunes old data for any time series found in the supplied
-// key range.
+// MaintainTimeSeries rolls up and then prunes old data for any time series
+// found in the supplied key range.
 //
 // The snapshot should be supplied by a local store, and is used only to
 // discover the names of time series which are store in that snapshot. The KV
-// client is then used to prune old data from the discovered series.
+// client is then used to roll up and prune old data from the discovered
+// series.
 //
 // The snapshot is used for key discovery (as opposed to the KV client) because
 // the task of pruning time series is distributed across the cluster to the
 // individual ranges which contain that time series data. Because replicas of
 // those ranges are guaranteed to have time series data locally, we can use the
 // snapshot to quickly obtain a set of keys to be pruned with no network calls.
-func (tsdb *DB) PruneTimeSeries(
+func (tsdb *DB) MaintainTimeSeries(
 	ctx context.Context,
 	snapshot engine.Reader,
 	start, end roachpb.RKey,
@@ -61,6 +62,9 @@ func (tsdb *DB) PruneTimeSeries(
 	if err != nil {
 		return err
 	}
+	if err := rollupTimeSeries(ctx, db, series, timestamp); err != nil {
+		return err
+	}
 	return pruneTimeSeries(ctx, db, series, timestamp)
 }
 
@@ -132,7 +136,8 @@ func findTimeSeries(
 // For each time series supplied, the pruning operation will delete all data
 // older than a constant threshold. The threshold is different depending on the
 // resolution; typically, lower-resolution time series data will be retained for
-// a longer period.
+// a longer period. Data at a resolution which is rolled up must be rolled up
+// by rollupTimeSeries before it is pruned.
 //
 // If data is stored at a resolution which is not known to the system, it is
 // assumed that the resolution has been deprecated and all data for that time
@@ -19,6 +19,7 @@ package ts
 import (
 	"container/heap"
 	"fmt"
+	"math"
 	"sort"
 	"time"
 
@@ -448,6 +449,15 @@ func (ii *interpolatingIterator) isValid() bool {
 	return ii.nextReal.isValid()
 }
 
+// hasValue returns true if this interpolatingIterator has either a real or an
+// interpolated value at its current offset.
+func (ii *interpolatingIterator) hasValue() bool {
+	if !ii.isValid() {
+		return false
+	}
+	return ii.nextReal.offset() == ii.offset || ii.prevReal.isValid()
+}
+
 // midTimestamp returns a timestamp at the middle of the current offset's sample
 // period. The middle of the sample period has been chosen in order to minimize
 // the possible distance from the returned timestamp and the timestamp of the
@@ -656,6 +666,41 @@ func (ai aggregatingIterator) min() float64 {
 	return min
 }
 
+// count returns the number of interpolatingIterators being aggregated which
+// have a value at the current offset.
+func (ai aggregatingIterator) count() float64 {
+	var count float64
+	for i := range ai {
+		if ai[i].hasValue() {
+			count++
+		}
+	}
+	return count
+}
+
+// percentile returns the given percentile of the current values of the
+// interpolatingIterators being aggregated, computed with the nearest-rank
+// method. Iterators without a value at the current offset are ignored.
+func (ai aggregatingIterator) percentile(p float64) float64 {
+	values := make([]float64, 0, len(ai))
+	for i := range ai {
+		if ai[i].hasValue() {
+			values = append(values, ai[i].value())
+		}
+	}
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	rank := int(math.Ceil(p / 100 * float64(len(values))))
+	if rank < 1 {
+		rank = 1
+	} else if rank > len(values) {
+		rank = len(values)
+	}
+	return values[rank-1]
+}
+
 // Query returns datapoints for the named time series during the supplied time
 // span.  Data is returned as a series of consecutive data points.
 //
@@ -672,26 +717,105 @@ func (ai aggregatingIterator) min() float64 {
 // timestamp falls in the middle of the sample period it represents.
 //
 // If data for the named time series was collected from multiple sources, each
-// returned datapoint will represent the sum of datapoints from all sources at
-// the same time. The returned string slices contains a list of all sources for
-// the metric which were aggregated to produce the result.
+// returned datapoint will represent the combination of datapoints from all
+// sources at the same time, computed with the source aggregator of the query.
+// The returned string slices contains a list of all sources for the metric
+// which were aggregated to produce the result.
 func (db *DB) Query(
 	ctx context.Context,
 	query tspb.Query,
 	queryResolution Resolution,
 	sampleDuration, startNanos, endNanos int64,
 ) ([]tspb.TimeSeriesDatapoint, []string, error) {
+	sourceSpans, err := db.readDataSpans(
+		ctx, query, queryResolution, sampleDuration, startNanos, endNanos,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Compute a list of all sources with data present in the query.
+	sources := make([]string, 0, len(sourceSpans))
+	spans := make([]dataSpan, 0, len(sourceSpans))
+	for name, span := range sourceSpans {
+		sources = append(sources, name)
+		spans = append(spans, *span)
+	}
+
+	datapoints, err := aggregateDataSpans(query, spans, sampleDuration, endNanos)
+	if err != nil {
+		return nil, nil, err
+	}
+	return datapoints, sources, nil
+}
+
+// QueryBySource returns datapoints for the named time series during the
+// supplied time span, like Query, but does not combine the datapoints of
+// different sources; instead, a separate series is returned for each source
+// with data present in the query, ordered by source. The source aggregator of
+// the query is ignored.
+func (db *DB) QueryBySource(
+	ctx context.Context,
+	query tspb.Query,
+	queryResolution Resolution,
+	sampleDuration, startNanos, endNanos int64,
+) ([]tspb.TimeSeriesData, error) {
+	if _, err := getDownsampleFunction(query.GetDownsampler()); err != nil {
+		return nil, err
+	}
+	sourceSpans, err := db.readDataSpans(
+		ctx, query, queryResolution, sampleDuration, startNanos, endNanos,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]string, 0, len(sourceSpans))
+	for name := range sourceSpans {
+		sources = append(sources, name)
+	}
+	sort.Strings(sources)
+
+	// Each series is computed by aggregating the data of a single source,
+	// for which the sum of values is simply the value of the source.
+	query.SourceAggregator = tspb.TimeSeriesQueryAggregator_SUM.Enum()
+	result := make([]tspb.TimeSeriesData, 0, len(sources))
+	for _, source := range sources {
+		datapoints, err := aggregateDataSpans(
+			query, []dataSpan{*sourceSpans[source]}, sampleDuration, endNanos,
+		)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, tspb.TimeSeriesData{
+			Name:       query.Name,
+			Source:     source,
+			Datapoints: datapoints,
+		})
+	}
+	return result, nil
+}
+
+// readDataSpans reads the data of the supplied query from the given
+// resolution, returning a dataSpan for each source with data present in the
+// query. The start of each dataSpan is normalized to a sampleDuration boundary.
+func (db *DB) readDataSpans(
+	ctx context.Context,
+	query tspb.Query,
+	queryResolution Resolution,
+	sampleDuration, startNanos, endNanos int64,
+) (map[string]*dataSpan, error) {
 	// Verify that sampleDuration is a multiple of
 	// queryResolution.SampleDuration().
 	if sampleDuration < queryResolution.SampleDuration() {
-		return nil, nil, fmt.Errorf(
+		return nil, fmt.Errorf(
 			"sampleDuration %d was not less that queryResolution.SampleDuration %d",
 			sampleDuration,
 			queryResolution.SampleDuration(),
 		)
 	}
 	if sampleDuration%queryResolution.SampleDuration() != 0 {
-		return nil, nil, fmt.Errorf(
+		return nil, fmt.Errorf(
 			"sampleDuration %d is not a multiple of queryResolution.SampleDuration %d",
 			sampleDuration,
 			queryResolution.SampleDuration(),
@@ -712,7 +836,7 @@ func (db *DB) Query(
 		b.Scan(startKey, endKey)
 
 		if err := db.db.Run(ctx, b); err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		rows = b.Results[0].Rows
 	} else {
@@ -730,7 +854,7 @@ func (db *DB) Query(
 		}
 		err := db.db.Run(ctx, b)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		for _, result := range b.Results {
 			row := result.Rows[0]
@@ -743,22 +867,28 @@ func (db *DB) Query(
 
 	// Convert the queried source data into a set of data spans, one for each
 	// source.
-	sourceSpans, err := makeDataSpans(rows, startNanos)
-	if err != nil {
-		return nil, nil, err
-	}
+	return makeDataSpans(rows, startNanos)
+}
 
+// aggregateDataSpans computes the datapoints of the supplied query from the
+// given dataSpans, which must all begin at the same time. Datapoints are
+// downsampled into intervals of sampleDuration, and datapoints of different
+// dataSpans at the same time are combined using the source aggregator of the
+// query.
+func aggregateDataSpans(
+	query tspb.Query, spans []dataSpan, sampleDuration, endNanos int64,
+) ([]tspb.TimeSeriesDatapoint, error) {
 	// Choose an extractor function which will be used to return values from
 	// each source for each sample period.
 	extractor, err := getExtractionFunction(query.GetDownsampler())
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Choose downsampler function.
 	downsampler, err := getDownsampleFunction(query.GetDownsampler())
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	// If we are returning a derivative, iteration needs to start at offset -1
@@ -770,29 +900,19 @@ func (db *DB) Query(
 	}
 
 	// Create an interpolatingIterator for each dataSpan, adding each iterator
-	// into a aggregatingIterator collection. This is also where we compute a
-	// list of all sources with data present in the query.
-	sources := make([]string, 0, len(sourceSpans))
-	iters := make(aggregatingIterator, 0, len(sourceSpans))
-	for name, span := range sourceSpans {
-		sources = append(sources, name)
+	// into a aggregatingIterator collection.
+	iters := make(aggregatingIterator, 0, len(spans))
+	for _, span := range spans {
 		iters = append(iters, newInterpolatingIterator(
-			*span, startOffset, sampleDuration, extractor, downsampler,
+			span, startOffset, sampleDuration, extractor, downsampler,
 		))
 	}
 
 	// Choose an aggregation function to use when taking values from the
 	// aggregatingIterator.
-	var valueFn func() float64
-	switch query.GetSourceAggregator() {
-	case tspb.TimeSeriesQueryAggregator_SUM:
-		valueFn = iters.sum
-	case tspb.TimeSeriesQueryAggregator_AVG:
-		valueFn = iters.avg
-	case tspb.TimeSeriesQueryAggregator_MAX:
-		valueFn = iters.max
-	case tspb.TimeSeriesQueryAggregator_MIN:
-		valueFn = iters.min
+	valueFn, err := getSourceAggregationFunction(query, iters)
+	if err != nil {
+		return nil, err
 	}
 
 	// Iterate over all requested offsets, recording a value from the
@@ -802,7 +922,7 @@ func (db *DB) Query(
 	iters.init()
 	if !iters.isValid() {
 		// We have no data to return.
-		return nil, sources, nil
+		return nil, nil
 	}
 
 	var last tspb.TimeSeriesDatapoint
@@ -844,7 +964,7 @@ func (db *DB) Query(
 		iters.advance()
 	}
 
-	return responseData, sources, nil
+	return responseData, nil
 }
 
 // makeDataSpans constructs a new dataSpan for each distinct source encountered
@@ -886,10 +1006,20 @@ func getExtractionFunction(agg tspb.TimeSeriesQueryAggregator) (extractFn, error
 		return (roachpb.InternalTimeSeriesSample).Maximum, nil
 	case tspb.TimeSeriesQueryAggregator_MIN:
 		return (roachpb.InternalTimeSeriesSample).Minimum, nil
+	case tspb.TimeSeriesQueryAggregator_FIRST, tspb.TimeSeriesQueryAggregator_LAST:
+		return (roachpb.InternalTimeSeriesSample).Average, nil
+	case tspb.TimeSeriesQueryAggregator_COUNT:
+		return extractCount, nil
+	case tspb.TimeSeriesQueryAggregator_PERCENTILE:
+		return nil, errors.Errorf("time series aggregator %s cannot be used as a downsampler", agg)
 	}
 	return nil, errors.Errorf("query specified unknown time series aggregator %s", agg.String())
 }
 
+func extractCount(point roachpb.InternalTimeSeriesSample) float64 {
+	return float64(point.Count)
+}
+
 func downsampleSum(points ...roachpb.InternalTimeSeriesSample) float64 {
 	result := 0.0
 	for _, p := range points {
@@ -928,6 +1058,22 @@ func downsampleAvg(points ...roachpb.InternalTimeSeriesSample) float64 {
 	return total / float64(count)
 }
 
+func downsampleFirst(points ...roachpb.InternalTimeSeriesSample) float64 {
+	return points[0].Average()
+}
+
+func downsampleLast(points ...roachpb.InternalTimeSeriesSample) float64 {
+	return points[len(points)-1].Average()
+}
+
+func downsampleCount(points ...roachpb.InternalTimeSeriesSample) float64 {
+	var count uint32
+	for _, p := range points {
+		count += p.Count
+	}
+	return float64(count)
+}
+
 // getDownsampleFunction returns
 func getDownsampleFunction(agg tspb.TimeSeriesQueryAggregator) (downsampleFn, error) {
 	switch agg {
@@ -939,6 +1085,45 @@ func getDownsampleFunction(agg tspb.TimeSeriesQueryAggregator) (downsampleFn, er
 		return downsampleMax, nil
 	case tspb.TimeSeriesQueryAggregator_MIN:
 		return downsampleMin, nil
+	case tspb.TimeSeriesQueryAggregator_FIRST:
+		return downsampleFirst, nil
+	case tspb.TimeSeriesQueryAggregator_LAST:
+		return downsampleLast, nil
+	case tspb.TimeSeriesQueryAggregator_COUNT:
+		return downsampleCount, nil
+	case tspb.TimeSeriesQueryAggregator_PERCENTILE:
+		return nil, errors.Errorf("time series aggregator %s cannot be used as a downsampler", agg)
 	}
 	return nil, errors.Errorf("query specified unknown time series aggregator %s", agg.String())
 }
+
+// getSourceAggregationFunction returns a function which combines the current
+// values of the supplied aggregatingIterator into a single value, according to
+// the source aggregator of the query.
+func getSourceAggregationFunction(
+	query tspb.Query, iters aggregatingIterator,
+) (func() float64, error) {
+	switch agg := query.GetSourceAggregator(); agg {
+	case tspb.TimeSeriesQueryAggregator_SUM:
+		return iters.sum, nil
+	case tspb.TimeSeriesQueryAggregator_AVG:
+		return iters.avg, nil
+	case tspb.TimeSeriesQueryAggregator_MAX:
+		return iters.max, nil
+	case tspb.TimeSeriesQueryAggregator_MIN:
+		return iters.min, nil
+	case tspb.TimeSeriesQueryAggregator_COUNT:
+		return iters.count, nil
+	case tspb.TimeSeriesQueryAggregator_PERCENTILE:
+		p := query.GetPercentile()
+		if p <= 0 || p > 100 {
+			return nil, errors.Errorf("percentile %g is not in the range (0, 100]", p)
+		}
+		return func() float64 {
+			return iters.percentile(p)
+		}, nil
+	case tspb.TimeSeriesQueryAggregator_FIRST, tspb.TimeSeriesQueryAggregator_LAST:
+		return nil, errors.Errorf("time series aggregator %s cannot be used as a source aggregator", agg)
+	}
+	return nil, errors.Errorf("query specified unknown time series aggregator %s", query.GetSourceAggregator())
+}
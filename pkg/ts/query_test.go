@@ -602,6 +602,24 @@ func TestAggregation(t *testing.T) {
 				return ui.avg()
 			},
 		},
+		{
+			[]float64{2, 2, 2, 2, 2, 2, 1},
+			func(ui aggregatingIterator) float64 {
+				return ui.count()
+			},
+		},
+		{
+			[]float64{1, 5, 7.5, 10, 20, 40, 56},
+			func(ui aggregatingIterator) float64 {
+				return ui.percentile(50)
+			},
+		},
+		{
+			[]float64{3.4, 7, 10, 25, 20, 40, 56},
+			func(ui aggregatingIterator) float64 {
+				return ui.percentile(99)
+			},
+		},
 	}
 
 	extractFn := func(s roachpb.InternalTimeSeriesSample) float64 {
@@ -747,6 +765,8 @@ func (tm *testModel) assertQuery(
 			value = iters.max()
 		case tspb.TimeSeriesQueryAggregator_MIN:
 			value = iters.min()
+		case tspb.TimeSeriesQueryAggregator_COUNT:
+			value = iters.count()
 		default:
 			tm.t.Fatalf("unknown query aggregator %s", q.GetSourceAggregator())
 		}
@@ -855,6 +875,14 @@ func TestQuery(t *testing.T) {
 	// Test with everything specified.
 	tm.assertQuery("test.multimetric", nil, tspb.TimeSeriesQueryAggregator_MIN.Enum(), tspb.TimeSeriesQueryAggregator_MAX.Enum(),
 		tspb.TimeSeriesQueryDerivative_NON_NEGATIVE_DERIVATIVE.Enum(), resolution1ns, 1, 0, 90, 8, 2)
+	// Test with count aggregator and downsampler.
+	tm.assertQuery("test.multimetric", nil, tspb.TimeSeriesQueryAggregator_COUNT.Enum(), tspb.TimeSeriesQueryAggregator_COUNT.Enum(), nil,
+		resolution1ns, 1, 0, 90, 8, 2)
+	// Test with first and last downsamplers.
+	tm.assertQuery("test.multimetric", nil, tspb.TimeSeriesQueryAggregator_FIRST.Enum(), nil, nil,
+		resolution1ns, 1, 0, 90, 8, 2)
+	tm.assertQuery("test.multimetric", nil, tspb.TimeSeriesQueryAggregator_LAST.Enum(), nil, nil,
+		resolution1ns, 1, 0, 90, 8, 2)
 
 	// Test queries that return no data. Check with every
 	// aggregator/downsampler/derivative combination. This situation is
@@ -863,6 +891,7 @@ func TestQuery(t *testing.T) {
 	aggs := []tspb.TimeSeriesQueryAggregator{
 		tspb.TimeSeriesQueryAggregator_MIN, tspb.TimeSeriesQueryAggregator_MAX,
 		tspb.TimeSeriesQueryAggregator_AVG, tspb.TimeSeriesQueryAggregator_SUM,
+		tspb.TimeSeriesQueryAggregator_COUNT,
 	}
 	derivs := []tspb.TimeSeriesQueryDerivative{
 		tspb.TimeSeriesQueryDerivative_NONE, tspb.TimeSeriesQueryDerivative_DERIVATIVE,
@@ -1023,4 +1052,183 @@ func TestQueryDownsampling(t *testing.T) {
 	tm.assertQuery("test.metric", nil, nil, nil, nil, resolution1ns, 10, 0, 60, 6, 2)
 	tm.assertQuery("test.metric", []string{"source1"}, nil, nil, nil, resolution1ns, 10, 0, 60, 5, 1)
 	tm.assertQuery("test.metric", []string{"source2"}, nil, nil, nil, resolution1ns, 10, 0, 60, 4, 1)
+	for _, downsampler := range []tspb.TimeSeriesQueryAggregator{
+		tspb.TimeSeriesQueryAggregator_FIRST,
+		tspb.TimeSeriesQueryAggregator_LAST,
+		tspb.TimeSeriesQueryAggregator_COUNT,
+	} {
+		tm.assertQuery("test.metric", nil, downsampler.Enum(), nil, nil, resolution1ns, 10, 0, 60, 6, 2)
+	}
+}
+
+// TestQueryAggregators verifies the results of the aggregators which are not
+// covered by the test model, and the rejection of aggregators in positions
+// where they cannot be used.
+func TestQueryAggregators(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tm := newTestModel(t)
+	tm.Start()
+	defer tm.Stop()
+
+	tm.storeTimeSeriesData(resolution1ns, []tspb.TimeSeriesData{
+		{
+			Name:       "test.metric",
+			Source:     "source1",
+			Datapoints: []tspb.TimeSeriesDatapoint{datapoint(1, 10), datapoint(2, 10)},
+		},
+		{
+			Name:       "test.metric",
+			Source:     "source2",
+			Datapoints: []tspb.TimeSeriesDatapoint{datapoint(1, 20), datapoint(2, 40)},
+		},
+		{
+			Name:       "test.metric",
+			Source:     "source3",
+			Datapoints: []tspb.TimeSeriesDatapoint{datapoint(1, 30)},
+		},
+	})
+	tm.assertModelCorrect()
+
+	for _, tc := range []struct {
+		agg        tspb.TimeSeriesQueryAggregator
+		percentile float64
+		expected   []tspb.TimeSeriesDatapoint
+	}{
+		{tspb.TimeSeriesQueryAggregator_COUNT, 0,
+			[]tspb.TimeSeriesDatapoint{datapoint(1, 3), datapoint(2, 2)}},
+		{tspb.TimeSeriesQueryAggregator_PERCENTILE, 10,
+			[]tspb.TimeSeriesDatapoint{datapoint(1, 10), datapoint(2, 10)}},
+		{tspb.TimeSeriesQueryAggregator_PERCENTILE, 50,
+			[]tspb.TimeSeriesDatapoint{datapoint(1, 20), datapoint(2, 10)}},
+		{tspb.TimeSeriesQueryAggregator_PERCENTILE, 100,
+			[]tspb.TimeSeriesDatapoint{datapoint(1, 30), datapoint(2, 40)}},
+	} {
+		query := tspb.Query{
+			Name:             "test.metric",
+			SourceAggregator: tc.agg.Enum(),
+			Percentile:       tc.percentile,
+		}
+		actual, _, err := tm.DB.Query(context.TODO(), query, resolution1ns, 1, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%s(%g): got %v, expected %v", tc.agg, tc.percentile, actual, tc.expected)
+		}
+	}
+
+	for _, tc := range []struct {
+		query    tspb.Query
+		expected string
+	}{
+		{
+			tspb.Query{
+				Downsampler: tspb.TimeSeriesQueryAggregator_PERCENTILE.Enum(),
+			},
+			"cannot be used as a downsampler",
+		},
+		{
+			tspb.Query{
+				SourceAggregator: tspb.TimeSeriesQueryAggregator_FIRST.Enum(),
+			},
+			"cannot be used as a source aggregator",
+		},
+		{
+			tspb.Query{
+				SourceAggregator: tspb.TimeSeriesQueryAggregator_PERCENTILE.Enum(),
+			},
+			"not in the range",
+		},
+		{
+			tspb.Query{
+				SourceAggregator: tspb.TimeSeriesQueryAggregator_PERCENTILE.Enum(),
+				Percentile:       101,
+			},
+			"not in the range",
+		},
+	} {
+		tc.query.Name = "test.metric"
+		if _, _, err := tm.DB.Query(context.TODO(), tc.query, resolution1ns, 1, 0, 10); !testutils.IsError(err, tc.expected) {
+			t.Errorf("%v: expected error %q, got %v", tc.query, tc.expected, err)
+		}
+	}
+}
+
+// TestQueryBySource verifies that queries grouped by source return a separate
+// series for each source.
+func TestQueryBySource(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tm := newTestModel(t)
+	tm.Start()
+	defer tm.Stop()
+
+	tm.storeTimeSeriesData(resolution1ns, []tspb.TimeSeriesData{
+		{
+			Name:       "test.metric",
+			Source:     "source2",
+			Datapoints: []tspb.TimeSeriesDatapoint{datapoint(1, 20), datapoint(3, 40)},
+		},
+		{
+			Name:       "test.metric",
+			Source:     "source1",
+			Datapoints: []tspb.TimeSeriesDatapoint{datapoint(1, 10), datapoint(2, 10)},
+		},
+		{
+			Name:       "test.metric",
+			Source:     "source3",
+			Datapoints: []tspb.TimeSeriesDatapoint{datapoint(1, 30)},
+		},
+	})
+	tm.assertModelCorrect()
+
+	for _, tc := range []struct {
+		sources  []string
+		expected []tspb.TimeSeriesData
+	}{
+		{
+			nil,
+			[]tspb.TimeSeriesData{
+				{
+					Name:       "test.metric",
+					Source:     "source1",
+					Datapoints: []tspb.TimeSeriesDatapoint{datapoint(1, 10), datapoint(2, 10)},
+				},
+				{
+					Name:       "test.metric",
+					Source:     "source2",
+					Datapoints: []tspb.TimeSeriesDatapoint{datapoint(1, 20), datapoint(3, 40)},
+				},
+				{
+					Name:       "test.metric",
+					Source:     "source3",
+					Datapoints: []tspb.TimeSeriesDatapoint{datapoint(1, 30)},
+				},
+			},
+		},
+		{
+			[]string{"source3", "source4"},
+			[]tspb.TimeSeriesData{
+				{
+					Name:       "test.metric",
+					Source:     "source3",
+					Datapoints: []tspb.TimeSeriesDatapoint{datapoint(1, 30)},
+				},
+			},
+		},
+	} {
+		query := tspb.Query{
+			Name:    "test.metric",
+			Sources: tc.sources,
+			// The source aggregator is ignored when grouping by source.
+			SourceAggregator: tspb.TimeSeriesQueryAggregator_AVG.Enum(),
+			GroupBySource:    true,
+		}
+		actual, err := tm.DB.QueryBySource(context.TODO(), query, resolution1ns, 1, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%v: got %v, expected %v", tc.sources, actual, tc.expected)
+		}
+	}
 }
@@ -29,8 +29,12 @@ func (r Resolution) String() string {
 	switch r {
 	case Resolution10s:
 		return "10s"
+	case Resolution30m:
+		return "30m"
 	case resolution1ns:
 		return "1ns"
+	case resolution10ns:
+		return "10ns"
 	}
 	return fmt.Sprintf("%d", r)
 }
@@ -40,17 +44,26 @@ func (r Resolution) String() string {
 const (
 	// Resolution10s stores data with a sample resolution of 10 seconds.
 	Resolution10s Resolution = 1
+	// Resolution30m stores data with a sample resolution of 30 minutes. Data
+	// at this resolution is not recorded directly, but is rolled up from
+	// Resolution10s data before it is pruned.
+	Resolution30m Resolution = 2
 	// resolution1ns stores data with a sample resolution of 1 nanosecond. Used
 	// only for testing.
 	resolution1ns Resolution = 999
+	// resolution10ns stores data with a sample resolution of 10 nanoseconds,
+	// rolled up from resolution1ns data. Used only for testing.
+	resolution10ns Resolution = 998
 )
 
 // sampleDurationByResolution is a map used to retrieve the sample duration
 // corresponding to a Resolution value. Sample durations are expressed in
 // nanoseconds.
 var sampleDurationByResolution = map[Resolution]int64{
-	Resolution10s: int64(time.Second * 10),
-	resolution1ns: 1, // 1ns resolution only for tests.
+	Resolution10s:  int64(time.Second * 10),
+	Resolution30m:  int64(time.Minute * 30),
+	resolution1ns:  1,  // 1ns resolution only for tests.
+	resolution10ns: 10, // 10ns resolution only for tests.
 }
 
 // slabDurationByResolution is a map used to retrieve the slab duration
@@ -58,18 +71,39 @@ var sampleDurationByResolution = map[Resolution]int64{
 // samples are stored at a single Cockroach key/value. Slab durations are
 // expressed in nanoseconds.
 var slabDurationByResolution = map[Resolution]int64{
-	Resolution10s: int64(time.Hour),
-	resolution1ns: 10, // 1ns resolution only for tests.
+	Resolution10s:  int64(time.Hour),
+	Resolution30m:  int64(time.Hour * 24),
+	resolution1ns:  10,  // 1ns resolution only for tests.
+	resolution10ns: 100, // 10ns resolution only for tests.
 }
 
 // pruneAgeByResolution maintains a suggested maximum age per resolution; data
 // which is older than the given threshold for a resolution is considered
 // eligible for deletion. Thresholds are specified in nanoseconds.
 var pruneThresholdByResolution = map[Resolution]int64{
-	Resolution10s: (30 * 24 * time.Hour).Nanoseconds(),
-	resolution1ns: time.Second.Nanoseconds(),
+	Resolution10s:  (30 * 24 * time.Hour).Nanoseconds(),
+	Resolution30m:  (365 * 24 * time.Hour).Nanoseconds(),
+	resolution1ns:  time.Second.Nanoseconds(),
+	resolution10ns: (10 * time.Second).Nanoseconds(),
 }
 
+// rollupResolutionByResolution maps a resolution to the lower resolution into
+// which its data is rolled up before being pruned. Data at a resolution which
+// is not present in this map is pruned without being rolled up.
+//
+// The sample duration of the rollup resolution must be a multiple of the
+// sample duration of the rolled up resolution, and must divide its slab
+// duration: this guarantees that each rollup sample is computed from a single
+// slab, which is rolled up in its entirety before it is pruned.
+var rollupResolutionByResolution = map[Resolution]Resolution{
+	Resolution10s: Resolution30m,
+	resolution1ns: resolution10ns,
+}
+
+// queryResolutions lists the resolutions at which time series can be queried,
+// from the highest to the lowest.
+var queryResolutions = []Resolution{Resolution10s, Resolution30m}
+
 // SampleDuration returns the sample duration corresponding to this resolution
 // value, expressed in nanoseconds.
 func (r Resolution) SampleDuration() int64 {
@@ -101,3 +135,11 @@ func (r Resolution) PruneThreshold() int64 {
 	}
 	return threshold
 }
+
+// RollupResolution returns the resolution into which data at this resolution
+// is rolled up before being pruned. The second return value is false if data
+// at this resolution is not rolled up.
+func (r Resolution) RollupResolution() (Resolution, bool) {
+	target, ok := rollupResolutionByResolution[r]
+	return target, ok
+}
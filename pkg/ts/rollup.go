@@ -0,0 +1,205 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ts
+
+import (
+	"sort"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+)
+
+// rollupTimeSeries computes rollups of the data of the supplied time series
+// which is old enough to be pruned, and stores them at the rollup resolution
+// of the time series. It must be called before the same data is pruned by
+// pruneTimeSeries; time series at a resolution which is not rolled up are
+// ignored.
+//
+// Each rollup sample summarizes the samples of a single source which fall in
+// its sample period, keeping their count, sum, minimum and maximum. Rollups
+// are written with merge requests; since a merged sample replaces any sample
+// previously stored at the same offset, computing the rollup of the same data
+// multiple times is idempotent.
+func rollupTimeSeries(
+	ctx context.Context, db *client.DB, timeSeriesList []timeSeriesResolutionInfo, now hlc.Timestamp,
+) error {
+	thresholds := computeThresholds(now.WallTime)
+
+	for _, timeSeries := range timeSeriesList {
+		target, ok := timeSeries.Resolution.RollupResolution()
+		if !ok {
+			continue
+		}
+
+		// Scan the data which will be pruned, using the same bounds as
+		// pruneTimeSeries.
+		start := makeDataKeySeriesPrefix(timeSeries.Name, timeSeries.Resolution)
+		end := MakeDataKey(timeSeries.Name, "", timeSeries.Resolution,
+			thresholds[timeSeries.Resolution])
+		b := &client.Batch{}
+		b.Scan(start, end)
+		if err := db.Run(ctx, b); err != nil {
+			return err
+		}
+
+		rollups, err := computeRollups(b.Results[0].Rows, target)
+		if err != nil {
+			return err
+		}
+		if len(rollups) == 0 {
+			continue
+		}
+
+		b = &client.Batch{}
+		for _, rollup := range rollups {
+			var value roachpb.Value
+			if err := value.SetProto(&rollup.data); err != nil {
+				return err
+			}
+			b.AddRawRequest(&roachpb.MergeRequest{
+				Span: roachpb.Span{
+					Key: MakeDataKey(
+						timeSeries.Name, rollup.source, target, rollup.data.StartTimestampNanos,
+					),
+				},
+				Value: value,
+			})
+		}
+		if err := db.Run(ctx, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollupData is a slab of rolled up data for a single source.
+type rollupData struct {
+	source string
+	data   roachpb.InternalTimeSeriesData
+}
+
+// rollupSlabKey identifies a slab of rolled up data.
+type rollupSlabKey struct {
+	source string
+	start  int64
+}
+
+// rollupSlabKeys sorts slabs of rolled up data by source and timestamp.
+type rollupSlabKeys []rollupSlabKey
+
+func (k rollupSlabKeys) Len() int      { return len(k) }
+func (k rollupSlabKeys) Swap(i, j int) { k[i], k[j] = k[j], k[i] }
+func (k rollupSlabKeys) Less(i, j int) bool {
+	if k[i].source != k[j].source {
+		return k[i].source < k[j].source
+	}
+	return k[i].start < k[j].start
+}
+
+// computeRollups rolls up the supplied time series data into slabs of the
+// target resolution. The returned slabs are ordered by source and timestamp,
+// and their samples are ordered by offset.
+func computeRollups(rows []client.KeyValue, target Resolution) ([]rollupData, error) {
+	slabs := make(map[rollupSlabKey]map[int32]*roachpb.InternalTimeSeriesSample)
+	var keys rollupSlabKeys
+
+	targetSlab := target.SlabDuration()
+	targetSample := target.SampleDuration()
+	for _, row := range rows {
+		var data roachpb.InternalTimeSeriesData
+		if err := row.ValueProto(&data); err != nil {
+			return nil, err
+		}
+		_, source, _, _, err := DecodeDataKey(row.Key)
+		if err != nil {
+			return nil, err
+		}
+		for _, sample := range data.Samples {
+			if sample.Count == 0 {
+				continue
+			}
+			timestamp := data.StartTimestampNanos + int64(sample.Offset)*data.SampleDurationNanos
+			key := rollupSlabKey{source: source, start: timestamp - timestamp%targetSlab}
+			samples, ok := slabs[key]
+			if !ok {
+				samples = make(map[int32]*roachpb.InternalTimeSeriesSample)
+				slabs[key] = samples
+				keys = append(keys, key)
+			}
+			offset := int32((timestamp - key.start) / targetSample)
+			accumulateRollupSample(samples, offset, sample)
+		}
+	}
+
+	sort.Sort(keys)
+
+	rollups := make([]rollupData, 0, len(keys))
+	for _, key := range keys {
+		samples := slabs[key]
+		rollup := rollupData{
+			source: key.source,
+			data: roachpb.InternalTimeSeriesData{
+				StartTimestampNanos: key.start,
+				SampleDurationNanos: targetSample,
+				Samples:             make([]roachpb.InternalTimeSeriesSample, 0, len(samples)),
+			},
+		}
+		for _, sample := range samples {
+			rollup.data.Samples = append(rollup.data.Samples, *sample)
+		}
+		sort.Sort(samplesByOffset(rollup.data.Samples))
+		rollups = append(rollups, rollup)
+	}
+	return rollups, nil
+}
+
+// accumulateRollupSample adds the supplied sample to the rollup sample at the
+// given offset.
+func accumulateRollupSample(
+	samples map[int32]*roachpb.InternalTimeSeriesSample,
+	offset int32,
+	sample roachpb.InternalTimeSeriesSample,
+) {
+	max, min := sample.Maximum(), sample.Minimum()
+	rollup, ok := samples[offset]
+	if !ok {
+		samples[offset] = &roachpb.InternalTimeSeriesSample{
+			Offset: offset,
+			Count:  sample.Count,
+			Sum:    sample.Sum,
+			Max:    &max,
+			Min:    &min,
+		}
+		return
+	}
+	rollup.Count += sample.Count
+	rollup.Sum += sample.Sum
+	if max > *rollup.Max {
+		*rollup.Max = max
+	}
+	if min < *rollup.Min {
+		*rollup.Min = min
+	}
+}
+
+// samplesByOffset sorts time series samples by offset.
+type samplesByOffset []roachpb.InternalTimeSeriesSample
+
+func (s samplesByOffset) Len() int           { return len(s) }
+func (s samplesByOffset) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s samplesByOffset) Less(i, j int) bool { return s[i].Offset < s[j].Offset }
@@ -0,0 +1,212 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ts
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/kr/pretty"
+)
+
+func TestRollupResolutions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	for r, target := range rollupResolutionByResolution {
+		if target.SampleDuration()%r.SampleDuration() != 0 {
+			t.Errorf("%s: sample duration of rollup resolution %s is not a multiple of %d",
+				r, target, r.SampleDuration())
+		}
+		if r.SlabDuration()%target.SampleDuration() != 0 {
+			t.Errorf("%s: slab duration %d is not a multiple of the sample duration of "+
+				"rollup resolution %s", r, r.SlabDuration(), target)
+		}
+		if target.PruneThreshold() <= r.PruneThreshold() {
+			t.Errorf("%s: rollup resolution %s is not retained longer", r, target)
+		}
+	}
+}
+
+func TestRollupTimeSeries(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	tm := newTestModel(t)
+	tm.Start()
+	defer tm.Stop()
+
+	tm.storeTimeSeriesData(resolution1ns, []tspb.TimeSeriesData{
+		{
+			Name:   "test.metric",
+			Source: "source1",
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				datapoint(1, 1),
+				datapoint(2, 5),
+				datapoint(5, 3),
+				datapoint(12, 4),
+				datapoint(13, 6),
+				// Newer than the pruning threshold, and thus not rolled up.
+				datapoint(int64(1500*time.Millisecond), 100),
+			},
+		},
+		{
+			Name:   "test.metric",
+			Source: "source2",
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				datapoint(105, 7),
+			},
+		},
+	})
+	tm.assertModelCorrect()
+	tm.assertKeyCount(4)
+
+	series := timeSeriesResolutionInfo{Name: "test.metric", Resolution: resolution1ns}
+	now := hlc.Timestamp{WallTime: int64(2 * time.Second)}
+
+	assertRollups := func() {
+		for _, tc := range []struct {
+			sources     []string
+			downsampler tspb.TimeSeriesQueryAggregator
+			expected    []tspb.TimeSeriesDatapoint
+		}{
+			{
+				[]string{"source1"},
+				tspb.TimeSeriesQueryAggregator_AVG,
+				[]tspb.TimeSeriesDatapoint{datapoint(5, 3), datapoint(15, 5)},
+			},
+			{
+				[]string{"source1"},
+				tspb.TimeSeriesQueryAggregator_SUM,
+				[]tspb.TimeSeriesDatapoint{datapoint(5, 9), datapoint(15, 10)},
+			},
+			{
+				[]string{"source1"},
+				tspb.TimeSeriesQueryAggregator_MAX,
+				[]tspb.TimeSeriesDatapoint{datapoint(5, 5), datapoint(15, 6)},
+			},
+			{
+				[]string{"source1"},
+				tspb.TimeSeriesQueryAggregator_MIN,
+				[]tspb.TimeSeriesDatapoint{datapoint(5, 1), datapoint(15, 4)},
+			},
+			{
+				[]string{"source2"},
+				tspb.TimeSeriesQueryAggregator_AVG,
+				[]tspb.TimeSeriesDatapoint{datapoint(105, 7)},
+			},
+		} {
+			query := tspb.Query{
+				Name:        "test.metric",
+				Sources:     tc.sources,
+				Downsampler: tc.downsampler.Enum(),
+			}
+			actual, _, err := tm.DB.Query(context.TODO(), query, resolution10ns, 10, 0, 1000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("%v %s: got %v, expected %v, diff: %s", tc.sources, tc.downsampler,
+					actual, tc.expected, pretty.Diff(actual, tc.expected))
+			}
+		}
+	}
+
+	if err := rollupTimeSeries(
+		context.TODO(), tm.LocalTestCluster.DB, []timeSeriesResolutionInfo{series}, now,
+	); err != nil {
+		t.Fatal(err)
+	}
+	// One slab of rolled up data for each source; the most recent datapoint of
+	// source1 is not rolled up.
+	if a, e := len(tm.getActualData()), 6; a != e {
+		t.Fatalf("expected %d time series keys, found %d", e, a)
+	}
+	assertRollups()
+
+	// Rolling up the same data again does not change the rollups.
+	if err := rollupTimeSeries(
+		context.TODO(), tm.LocalTestCluster.DB, []timeSeriesResolutionInfo{series}, now,
+	); err != nil {
+		t.Fatal(err)
+	}
+	assertRollups()
+
+	// Rollups remain after the data they summarize is pruned.
+	if err := pruneTimeSeries(
+		context.TODO(), tm.LocalTestCluster.DB, []timeSeriesResolutionInfo{series}, now,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if a, e := len(tm.getActualData()), 3; a != e {
+		t.Fatalf("expected %d time series keys, found %d", e, a)
+	}
+	assertRollups()
+}
+
+func TestQueryResolution(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	day := int64(24 * time.Hour)
+	now := int64(1475700000 * time.Second)
+	for i, tc := range []struct {
+		age, sampleNanos   int64
+		expectedResolution Resolution
+		expectedSample     int64
+	}{
+		{day, 0, Resolution10s, int64(10 * time.Second)},
+		{day, int64(time.Minute), Resolution10s, int64(time.Minute)},
+		{day, int64(15 * time.Second), Resolution10s, int64(20 * time.Second)},
+		{30 * day, 0, Resolution10s, int64(10 * time.Second)},
+		{31 * day, 0, Resolution30m, int64(30 * time.Minute)},
+		{31 * day, int64(10 * time.Second), Resolution30m, int64(30 * time.Minute)},
+		{60 * day, 0, Resolution30m, int64(30 * time.Minute)},
+		{365 * day, int64(45 * time.Minute), Resolution30m, int64(time.Hour)},
+		{2 * 365 * day, int64(24 * time.Hour), Resolution30m, int64(24 * time.Hour)},
+	} {
+		r, sample := queryResolution(now-tc.age, now, tc.sampleNanos)
+		if r != tc.expectedResolution || sample != tc.expectedSample {
+			t.Errorf("%d: expected resolution %s and sample period %d, got %s and %d",
+				i, tc.expectedResolution, tc.expectedSample, r, sample)
+		}
+	}
+}
+
+func TestStitchDatapoints(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	low := []tspb.TimeSeriesDatapoint{datapoint(10, 1), datapoint(20, 2), datapoint(30, 3)}
+	for i, tc := range []struct {
+		high, expected []tspb.TimeSeriesDatapoint
+	}{
+		{nil, low},
+		{
+			[]tspb.TimeSeriesDatapoint{datapoint(30, 30), datapoint(40, 40)},
+			[]tspb.TimeSeriesDatapoint{datapoint(10, 1), datapoint(20, 2), datapoint(30, 30), datapoint(40, 40)},
+		},
+		{
+			[]tspb.TimeSeriesDatapoint{datapoint(50, 50)},
+			[]tspb.TimeSeriesDatapoint{datapoint(10, 1), datapoint(20, 2), datapoint(30, 3), datapoint(50, 50)},
+		},
+		{
+			[]tspb.TimeSeriesDatapoint{datapoint(0, 0)},
+			[]tspb.TimeSeriesDatapoint{datapoint(0, 0)},
+		},
+	} {
+		if actual := stitchDatapoints(low, tc.high); !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%d: expected %v, got %v", i, tc.expected, actual)
+		}
+	}
+}
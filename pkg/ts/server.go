@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	gwruntime "github.com/grpc-ecosystem/grpc-gateway/runtime"
 )
 
@@ -82,6 +83,112 @@ func (s *Server) RegisterGateway(
 	return tspb.RegisterTimeSeriesHandler(ctx, mux, conn)
 }
 
+// queryResolution returns the resolution at which data is read for a query
+// starting at startNanos, along with the sample period of the returned data.
+// The highest resolution which still retains data as old as startNanos is
+// chosen, so that queries reaching far into the past are served from
+// rollups.
+//
+// The requested sample period defaults to the sample duration of the chosen
+// resolution, and is otherwise rounded up to a multiple of it.
+func queryResolution(startNanos, nowNanos, sampleNanos int64) (Resolution, int64) {
+	resolution := queryResolutions[len(queryResolutions)-1]
+	for _, r := range queryResolutions {
+		if nowNanos-startNanos <= r.PruneThreshold() {
+			resolution = r
+			break
+		}
+	}
+
+	sampleDuration := resolution.SampleDuration()
+	if sampleNanos < sampleDuration {
+		return resolution, sampleDuration
+	}
+	if rem := sampleNanos % sampleDuration; rem != 0 {
+		sampleNanos += sampleDuration - rem
+	}
+	return resolution, sampleNanos
+}
+
+// stitchDatapoints returns the datapoints of a query at a lower resolution
+// which precede the first datapoint of the same query at a higher
+// resolution, followed by the datapoints at the higher resolution. Data is
+// only rolled up when it is pruned from the higher resolution, so the most
+// recent data is only available at the higher resolution.
+func stitchDatapoints(low, high []tspb.TimeSeriesDatapoint) []tspb.TimeSeriesDatapoint {
+	if len(high) == 0 {
+		return low
+	}
+	i := 0
+	for i < len(low) && low[i].TimestampNanos < high[0].TimestampNanos {
+		i++
+	}
+	return append(low[:i:i], high...)
+}
+
+// query runs a query which is not grouped by source. Queries at a resolution
+// other than the highest one are also run at the highest one, and their
+// results are stitched together.
+func (s *Server) query(
+	ctx context.Context,
+	query tspb.Query,
+	resolution Resolution,
+	sampleNanos, startNanos, endNanos int64,
+) ([]tspb.TimeSeriesDatapoint, []string, error) {
+	datapoints, sources, err := s.db.Query(ctx, query, resolution, sampleNanos, startNanos, endNanos)
+	if err != nil || resolution == queryResolutions[0] {
+		return datapoints, sources, err
+	}
+	recent, recentSources, err := s.db.Query(
+		ctx, query, queryResolutions[0], sampleNanos, startNanos, endNanos,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, source := range recentSources {
+		found := false
+		for _, existing := range sources {
+			found = found || existing == source
+		}
+		if !found {
+			sources = append(sources, source)
+		}
+	}
+	return stitchDatapoints(datapoints, recent), sources, nil
+}
+
+// queryBySource is like query, for queries grouped by source.
+func (s *Server) queryBySource(
+	ctx context.Context,
+	query tspb.Query,
+	resolution Resolution,
+	sampleNanos, startNanos, endNanos int64,
+) ([]tspb.TimeSeriesData, error) {
+	series, err := s.db.QueryBySource(ctx, query, resolution, sampleNanos, startNanos, endNanos)
+	if err != nil || resolution == queryResolutions[0] {
+		return series, err
+	}
+	recent, err := s.db.QueryBySource(
+		ctx, query, queryResolutions[0], sampleNanos, startNanos, endNanos,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range recent {
+		found := false
+		for i := range series {
+			if series[i].Source == r.Source {
+				series[i].Datapoints = stitchDatapoints(series[i].Datapoints, r.Datapoints)
+				found = true
+			}
+		}
+		if !found {
+			series = append(series, r)
+		}
+	}
+	return series, nil
+}
+
 // Query is an endpoint that returns data for one or more metrics over a
 // specific time span.
 func (s *Server) Query(
@@ -92,16 +199,129 @@ func (s *Server) Query(
 		return nil, grpc.Errorf(codes.InvalidArgument, "Queries cannot be empty")
 	}
 
-	// If not set, sampleNanos should default to ten second resolution.
-	sampleNanos := request.SampleNanos
-	if sampleNanos == 0 {
-		sampleNanos = Resolution10s.SampleDuration()
-	}
+	resolution, sampleNanos := queryResolution(
+		request.StartNanos, timeutil.Now().UnixNano(), request.SampleNanos,
+	)
 
 	response := tspb.TimeSeriesQueryResponse{
 		Results: make([]tspb.TimeSeriesQueryResponse_Result, len(request.Queries)),
 	}
 
+	if err := s.runQueryWorkers(ctx, len(request.Queries), func(ctx context.Context, queryIdx int) error {
+		query := request.Queries[queryIdx]
+		result := tspb.TimeSeriesQueryResponse_Result{
+			Query: query,
+		}
+		if query.GroupBySource {
+			bySource, err := s.queryBySource(
+				ctx,
+				query,
+				resolution,
+				sampleNanos,
+				request.StartNanos,
+				request.EndNanos,
+			)
+			if err != nil {
+				return err
+			}
+			result.BySource = bySource
+			result.Sources = make([]string, len(bySource))
+			for i, series := range bySource {
+				result.Sources[i] = series.Source
+			}
+		} else {
+			datapoints, sources, err := s.query(
+				ctx,
+				query,
+				resolution,
+				sampleNanos,
+				request.StartNanos,
+				request.EndNanos,
+			)
+			if err != nil {
+				return err
+			}
+			result.Datapoints = datapoints
+			result.Sources = sources
+		}
+		response.Results[queryIdx] = result
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// TextQuery is an endpoint that returns data for one or more queries written
+// in the textual query language over a specific time span.
+func (s *Server) TextQuery(
+	ctx context.Context, request *tspb.TimeSeriesTextQueryRequest,
+) (*tspb.TimeSeriesTextQueryResponse, error) {
+	ctx = s.AnnotateCtx(ctx)
+	if len(request.Queries) == 0 {
+		return nil, grpc.Errorf(codes.InvalidArgument, "Queries cannot be empty")
+	}
+
+	// Parse all queries before running any of them.
+	exprs := make([]textQueryExpr, len(request.Queries))
+	for i, query := range request.Queries {
+		expr, err := parseTextQuery(query)
+		if err != nil {
+			return nil, grpc.Errorf(codes.InvalidArgument, "%s", err)
+		}
+		exprs[i] = expr
+	}
+
+	resolution, sampleNanos := queryResolution(
+		request.StartNanos, timeutil.Now().UnixNano(), request.SampleNanos,
+	)
+
+	response := tspb.TimeSeriesTextQueryResponse{
+		Results: make([]tspb.TimeSeriesTextQueryResponse_Result, len(request.Queries)),
+	}
+
+	if err := s.runQueryWorkers(ctx, len(exprs), func(ctx context.Context, queryIdx int) error {
+		value, err := evalTextQuery(exprs[queryIdx], func(query tspb.Query) ([]tspb.TimeSeriesData, error) {
+			if query.GroupBySource {
+				return s.queryBySource(
+					ctx, query, resolution, sampleNanos, request.StartNanos, request.EndNanos,
+				)
+			}
+			datapoints, _, err := s.query(
+				ctx, query, resolution, sampleNanos, request.StartNanos, request.EndNanos,
+			)
+			if err != nil {
+				return nil, err
+			}
+			return []tspb.TimeSeriesData{{Name: query.Name, Datapoints: datapoints}}, nil
+		})
+		if err != nil {
+			return err
+		}
+		if value.isScalar {
+			return grpc.Errorf(codes.InvalidArgument,
+				"query %q does not reference any time series", request.Queries[queryIdx])
+		}
+		response.Results[queryIdx] = tspb.TimeSeriesTextQueryResponse_Result{
+			Query:  request.Queries[queryIdx],
+			Series: value.series,
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// runQueryWorkers runs the supplied function for each of count queries, using
+// a worker task for each query. The number of concurrently running workers is
+// limited by the worker semaphore of the server. The first error returned by
+// any worker is returned.
+func (s *Server) runQueryWorkers(
+	ctx context.Context, count int, work func(ctx context.Context, queryIdx int) error,
+) error {
 	// Defer cancellation of context passed to worker tasks; if main task
 	// returns early, worker tasks should be torn down quickly.
 	ctx, cancel := context.WithCancel(ctx)
@@ -118,29 +338,14 @@ func (s *Server) Query(
 	// they have written their result to the "output" channel, which is
 	// processed later in the main function.
 	if err := s.stopper.RunAsyncTask(ctx, func(ctx context.Context) {
-		for queryIdx, query := range request.Queries {
+		for queryIdx := 0; queryIdx < count; queryIdx++ {
 			queryIdx := queryIdx
-			query := query
 			if err := s.stopper.RunLimitedAsyncTask(
 				ctx,
 				s.workerSem,
 				true, /* wait */
 				func(ctx context.Context) {
-					datapoints, sources, err := s.db.Query(
-						ctx,
-						query,
-						Resolution10s,
-						sampleNanos,
-						request.StartNanos,
-						request.EndNanos,
-					)
-					if err == nil {
-						response.Results[queryIdx] = tspb.TimeSeriesQueryResponse_Result{
-							Query:      query,
-							Datapoints: datapoints,
-						}
-						response.Results[queryIdx].Sources = sources
-					}
+					err := work(ctx, queryIdx)
 					select {
 					case workerOutput <- err:
 					case <-ctx.Done():
@@ -157,22 +362,21 @@ func (s *Server) Query(
 			}
 		}
 	}); err != nil {
-		return nil, err
+		return err
 	}
 
-	for range request.Queries {
+	for i := 0; i < count; i++ {
 		select {
 		case err := <-workerOutput:
 			if err != nil {
 				// Return the first error encountered. This will cancel the
 				// worker context and cause all other in-progress workers to
 				// exit.
-				return nil, err
+				return err
 			}
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return ctx.Err()
 		}
 	}
-
-	return &response, nil
+	return nil
 }
@@ -19,27 +19,43 @@ package ts_test
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/ts"
 	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
+// recentNanos returns a timestamp from the day before the current time,
+// aligned to the sample periods used by the tests.
+func recentNanos() int64 {
+	const alignNanos = int64(5 * time.Hour)
+	nowNanos := timeutil.Now().Add(-24 * time.Hour).UnixNano()
+	return nowNanos - nowNanos%alignNanos
+}
+
 func TestServerQuery(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
 	defer s.Stopper().Stop()
 	tsrv := s.(*server.TestServer)
 
-	// Populate data directly.
+	// Populate data directly. The data is recent, so that it is queried at
+	// the highest resolution.
+	baseNanos := recentNanos()
 	tsdb := tsrv.TsDB()
 	if err := tsdb.StoreData(context.TODO(), ts.Resolution10s, []tspb.TimeSeriesData{
 		{
@@ -47,15 +63,15 @@ func TestServerQuery(t *testing.T) {
 			Source: "source1",
 			Datapoints: []tspb.TimeSeriesDatapoint{
 				{
-					TimestampNanos: 400 * 1e9,
+					TimestampNanos: baseNanos + 400*1e9,
 					Value:          100.0,
 				},
 				{
-					TimestampNanos: 500 * 1e9,
+					TimestampNanos: baseNanos + 500*1e9,
 					Value:          200.0,
 				},
 				{
-					TimestampNanos: 520 * 1e9,
+					TimestampNanos: baseNanos + 520*1e9,
 					Value:          300.0,
 				},
 			},
@@ -65,19 +81,19 @@ func TestServerQuery(t *testing.T) {
 			Source: "source2",
 			Datapoints: []tspb.TimeSeriesDatapoint{
 				{
-					TimestampNanos: 400 * 1e9,
+					TimestampNanos: baseNanos + 400*1e9,
 					Value:          100.0,
 				},
 				{
-					TimestampNanos: 500 * 1e9,
+					TimestampNanos: baseNanos + 500*1e9,
 					Value:          200.0,
 				},
 				{
-					TimestampNanos: 510 * 1e9,
+					TimestampNanos: baseNanos + 510*1e9,
 					Value:          250.0,
 				},
 				{
-					TimestampNanos: 530 * 1e9,
+					TimestampNanos: baseNanos + 530*1e9,
 					Value:          350.0,
 				},
 			},
@@ -86,15 +102,15 @@ func TestServerQuery(t *testing.T) {
 			Name: "other.metric",
 			Datapoints: []tspb.TimeSeriesDatapoint{
 				{
-					TimestampNanos: 400 * 1e9,
+					TimestampNanos: baseNanos + 400*1e9,
 					Value:          100.0,
 				},
 				{
-					TimestampNanos: 500 * 1e9,
+					TimestampNanos: baseNanos + 500*1e9,
 					Value:          200.0,
 				},
 				{
-					TimestampNanos: 510 * 1e9,
+					TimestampNanos: baseNanos + 510*1e9,
 					Value:          250.0,
 				},
 			},
@@ -112,15 +128,15 @@ func TestServerQuery(t *testing.T) {
 				},
 				Datapoints: []tspb.TimeSeriesDatapoint{
 					{
-						TimestampNanos: 505 * 1e9,
+						TimestampNanos: baseNanos + 505*1e9,
 						Value:          400.0,
 					},
 					{
-						TimestampNanos: 515 * 1e9,
+						TimestampNanos: baseNanos + 515*1e9,
 						Value:          500.0,
 					},
 					{
-						TimestampNanos: 525 * 1e9,
+						TimestampNanos: baseNanos + 525*1e9,
 						Value:          600.0,
 					},
 				},
@@ -132,11 +148,11 @@ func TestServerQuery(t *testing.T) {
 				},
 				Datapoints: []tspb.TimeSeriesDatapoint{
 					{
-						TimestampNanos: 505 * 1e9,
+						TimestampNanos: baseNanos + 505*1e9,
 						Value:          200.0,
 					},
 					{
-						TimestampNanos: 515 * 1e9,
+						TimestampNanos: baseNanos + 515*1e9,
 						Value:          250.0,
 					},
 				},
@@ -151,15 +167,15 @@ func TestServerQuery(t *testing.T) {
 				},
 				Datapoints: []tspb.TimeSeriesDatapoint{
 					{
-						TimestampNanos: 505 * 1e9,
+						TimestampNanos: baseNanos + 505*1e9,
 						Value:          1.0,
 					},
 					{
-						TimestampNanos: 515 * 1e9,
+						TimestampNanos: baseNanos + 515*1e9,
 						Value:          5.0,
 					},
 					{
-						TimestampNanos: 525 * 1e9,
+						TimestampNanos: baseNanos + 525*1e9,
 						Value:          5.0,
 					},
 				},
@@ -173,8 +189,8 @@ func TestServerQuery(t *testing.T) {
 	}
 	client := tspb.NewTimeSeriesClient(conn)
 	response, err := client.Query(context.Background(), &tspb.TimeSeriesQueryRequest{
-		StartNanos: 500 * 1e9,
-		EndNanos:   526 * 1e9,
+		StartNanos: baseNanos + 500*1e9,
+		EndNanos:   baseNanos + 526*1e9,
 		Queries: []tspb.Query{
 			{
 				Name: "test.metric",
@@ -212,11 +228,11 @@ func TestServerQuery(t *testing.T) {
 				},
 				Datapoints: []tspb.TimeSeriesDatapoint{
 					{
-						TimestampNanos: 250 * 1e9,
+						TimestampNanos: baseNanos + 250*1e9,
 						Value:          200.0,
 					},
 					{
-						TimestampNanos: 750 * 1e9,
+						TimestampNanos: baseNanos + 750*1e9,
 						Value:          650.0,
 					},
 				},
@@ -224,8 +240,8 @@ func TestServerQuery(t *testing.T) {
 		},
 	}
 	response, err = client.Query(context.Background(), &tspb.TimeSeriesQueryRequest{
-		StartNanos:  0,
-		EndNanos:    1000 * 1e9,
+		StartNanos:  baseNanos,
+		EndNanos:    baseNanos + 1000*1e9,
 		SampleNanos: 500 * 1e9,
 		Queries: []tspb.Query{
 			{
@@ -246,6 +262,313 @@ func TestServerQuery(t *testing.T) {
 	}
 }
 
+func TestServerTextQuery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop()
+	tsrv := s.(*server.TestServer)
+
+	// Populate data directly. The data is recent, so that it is queried at
+	// the highest resolution.
+	baseNanos := recentNanos()
+	tsdb := tsrv.TsDB()
+	if err := tsdb.StoreData(context.TODO(), ts.Resolution10s, []tspb.TimeSeriesData{
+		{
+			Name:   "test.metric",
+			Source: "source1",
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				{
+					TimestampNanos: baseNanos + 500*1e9,
+					Value:          200.0,
+				},
+				{
+					TimestampNanos: baseNanos + 510*1e9,
+					Value:          300.0,
+				},
+			},
+		},
+		{
+			Name:   "test.metric",
+			Source: "source2",
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				{
+					TimestampNanos: baseNanos + 500*1e9,
+					Value:          100.0,
+				},
+				{
+					TimestampNanos: baseNanos + 510*1e9,
+					Value:          150.0,
+				},
+			},
+		},
+		{
+			Name: "other.metric",
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				{
+					TimestampNanos: baseNanos + 500*1e9,
+					Value:          100.0,
+				},
+				{
+					TimestampNanos: baseNanos + 510*1e9,
+					Value:          200.0,
+				},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedResult := &tspb.TimeSeriesTextQueryResponse{
+		Results: []tspb.TimeSeriesTextQueryResponse_Result{
+			{
+				Query: "test.metric / 2",
+				Series: []tspb.TimeSeriesData{
+					{
+						Name: "(test.metric / 2)",
+						Datapoints: []tspb.TimeSeriesDatapoint{
+							{
+								TimestampNanos: baseNanos + 505*1e9,
+								Value:          150.0,
+							},
+							{
+								TimestampNanos: baseNanos + 515*1e9,
+								Value:          225.0,
+							},
+						},
+					},
+				},
+			},
+			{
+				Query: "by_source(test.metric) - other.metric",
+				Series: []tspb.TimeSeriesData{
+					{
+						Name:   "(by_source(test.metric) - other.metric)",
+						Source: "source1",
+						Datapoints: []tspb.TimeSeriesDatapoint{
+							{
+								TimestampNanos: baseNanos + 505*1e9,
+								Value:          100.0,
+							},
+							{
+								TimestampNanos: baseNanos + 515*1e9,
+								Value:          100.0,
+							},
+						},
+					},
+					{
+						Name:   "(by_source(test.metric) - other.metric)",
+						Source: "source2",
+						Datapoints: []tspb.TimeSeriesDatapoint{
+							{
+								TimestampNanos: baseNanos + 505*1e9,
+								Value:          0.0,
+							},
+							{
+								TimestampNanos: baseNanos + 515*1e9,
+								Value:          -50.0,
+							},
+						},
+					},
+				},
+			},
+			{
+				Query: "percentile(100, test.metric{source=\"source2\"})",
+				Series: []tspb.TimeSeriesData{
+					{
+						Name: "test.metric",
+						Datapoints: []tspb.TimeSeriesDatapoint{
+							{
+								TimestampNanos: baseNanos + 505*1e9,
+								Value:          100.0,
+							},
+							{
+								TimestampNanos: baseNanos + 515*1e9,
+								Value:          150.0,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	conn, err := tsrv.RPCContext().GRPCDial(tsrv.Cfg.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := tspb.NewTimeSeriesClient(conn)
+	request := &tspb.TimeSeriesTextQueryRequest{
+		StartNanos: baseNanos + 500*1e9,
+		EndNanos:   baseNanos + 526*1e9,
+	}
+	for _, r := range expectedResult.Results {
+		request.Queries = append(request.Queries, r.Query)
+	}
+	response, err := client.TextQuery(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(response, expectedResult) {
+		t.Fatalf("actual response \n%v\n did not match expected response \n%v",
+			response, expectedResult)
+	}
+
+	// Invalid queries are rejected.
+	for _, tc := range []struct {
+		queries  []string
+		expected string
+	}{
+		{nil, "Queries cannot be empty"},
+		{[]string{"test.metric", "sum(test.metric"}, "expected \"\\)\", found end of query"},
+		{[]string{"1 + 2"}, "does not reference any time series"},
+		{[]string{strings.Repeat("sum(", 100) + "test.metric" + strings.Repeat(")", 100)},
+			"query is nested more than 64 levels deep"},
+		{[]string{strings.Repeat("test.metric + ", 1000) + "test.metric"},
+			"exceeds the maximum length"},
+	} {
+		request.Queries = tc.queries
+		_, err := client.TextQuery(context.Background(), request)
+		if !testutils.IsError(err, tc.expected) {
+			t.Errorf("%v: expected error %q, got %v", tc.queries, tc.expected, err)
+		} else if code := grpc.Code(err); code != codes.InvalidArgument {
+			t.Errorf("%v: expected error code %s, got %s", tc.queries, codes.InvalidArgument, code)
+		}
+	}
+}
+
+// TestServerQueryRollups verifies that queries starting before the data at
+// the highest resolution is pruned are served from rollups, stitched together
+// with the recent data which has not been rolled up yet.
+func TestServerQueryRollups(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop()
+	tsrv := s.(*server.TestServer)
+
+	const halfHour = int64(30 * time.Minute)
+	const day = int64(24 * time.Hour)
+	baseNanos := recentNanos()
+	tsdb := tsrv.TsDB()
+
+	// Populate rollups of old data directly, along with recent data which has
+	// not been rolled up.
+	if err := tsdb.StoreData(context.TODO(), ts.Resolution30m, []tspb.TimeSeriesData{
+		{
+			Name:   "test.metric",
+			Source: "source1",
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				{
+					TimestampNanos: baseNanos - 60*day,
+					Value:          100.0,
+				},
+				{
+					TimestampNanos: baseNanos - 60*day + halfHour,
+					Value:          200.0,
+				},
+				{
+					TimestampNanos: baseNanos - 35*day,
+					Value:          300.0,
+				},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tsdb.StoreData(context.TODO(), ts.Resolution10s, []tspb.TimeSeriesData{
+		{
+			Name:   "test.metric",
+			Source: "source1",
+			Datapoints: []tspb.TimeSeriesDatapoint{
+				{
+					TimestampNanos: baseNanos,
+					Value:          400.0,
+				},
+				{
+					TimestampNanos: baseNanos + 10*1e9,
+					Value:          600.0,
+				},
+			},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := tsrv.RPCContext().GRPCDial(tsrv.Cfg.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := tspb.NewTimeSeriesClient(conn)
+
+	for _, tc := range []struct {
+		name       string
+		startNanos int64
+		endNanos   int64
+		expected   []tspb.TimeSeriesDatapoint
+	}{
+		{
+			// A short query over data which has been pruned at the highest
+			// resolution.
+			name:       "seven days starting sixty days ago",
+			startNanos: baseNanos - 60*day,
+			endNanos:   baseNanos - 53*day,
+			expected: []tspb.TimeSeriesDatapoint{
+				{
+					TimestampNanos: baseNanos - 60*day + halfHour/2,
+					Value:          100.0,
+				},
+				{
+					TimestampNanos: baseNanos - 60*day + halfHour + halfHour/2,
+					Value:          200.0,
+				},
+			},
+		},
+		{
+			// A query spanning both rollups and recent data.
+			name:       "forty days up to now",
+			startNanos: baseNanos - 40*day,
+			endNanos:   baseNanos + day,
+			expected: []tspb.TimeSeriesDatapoint{
+				{
+					TimestampNanos: baseNanos - 35*day + halfHour/2,
+					Value:          300.0,
+				},
+				{
+					TimestampNanos: baseNanos + halfHour/2,
+					Value:          500.0,
+				},
+			},
+		},
+	} {
+		expectedResult := &tspb.TimeSeriesQueryResponse{
+			Results: []tspb.TimeSeriesQueryResponse_Result{
+				{
+					Query: tspb.Query{
+						Name:    "test.metric",
+						Sources: []string{"source1"},
+					},
+					Datapoints: tc.expected,
+				},
+			},
+		}
+		response, err := client.Query(context.Background(), &tspb.TimeSeriesQueryRequest{
+			StartNanos: tc.startNanos,
+			EndNanos:   tc.endNanos,
+			Queries: []tspb.Query{
+				{
+					Name: "test.metric",
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("%s: %s", tc.name, err)
+		}
+		if !proto.Equal(response, expectedResult) {
+			t.Errorf("%s: actual response \n%v\n did not match expected response \n%v",
+				tc.name, response, expectedResult)
+		}
+	}
+}
+
 // TestServerQueryStarvation tests a very specific scenario, wherein a single
 // query request has more queries than the server's MaxWorkers count.
 func TestServerQueryStarvation(t *testing.T) {
@@ -0,0 +1,669 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ts
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/pkg/errors"
+)
+
+// This file implements the textual time series query language accepted by the
+// TextQuery endpoint. The grammar of a textual query is:
+//
+//	expr     := term (('+' | '-') term)*
+//	term     := factor (('*' | '/') factor)*
+//	factor   := number | '(' expr ')' | function | series
+//	function := ident '(' [arg (',' arg)*] ')'
+//	series   := (ident | string) ['{' 'source' '=' string '}']
+//
+// A series selects the time series with the given name; names containing
+// characters other than letters, digits, '_' and '.' (such as the '-p99'
+// suffix of histogram-derived series) must be quoted. The optional source
+// filter is a list of sources separated by '|'.
+//
+// Functions modify the query of the series they are applied to:
+//
+//	sum(x), avg(x), max(x), min(x), count(x)  aggregate the sources of x
+//	percentile(p, x)  computes the p-th percentile of the sources of x
+//	by_source(x)      returns a separate series for each source of x
+//	downsample(a, x)  downsamples x using aggregator a (avg, sum, max, min,
+//	                  first, last or count)
+//	rate(x)           the non-negative derivative of x
+//	derivative(x)     the derivative of x
+//
+// Arithmetic between series is evaluated point-wise, using the datapoints of
+// both operands with the same timestamp; see evalTextQueryBinary.
+
+// textQueryExpr is a node of a parsed textual query.
+type textQueryExpr interface {
+	fmt.Stringer
+}
+
+// textQueryNumber is a numeric constant.
+type textQueryNumber float64
+
+func (n textQueryNumber) String() string {
+	return strconv.FormatFloat(float64(n), 'g', -1, 64)
+}
+
+// textQuerySeries is a time series, along with the query options set by the
+// functions applied to it.
+type textQuerySeries struct {
+	query tspb.Query
+}
+
+func (s *textQuerySeries) String() string {
+	var buf bytes.Buffer
+	if isTextQueryIdent(s.query.Name) {
+		buf.WriteString(s.query.Name)
+	} else {
+		buf.WriteString(strconv.Quote(s.query.Name))
+	}
+	if len(s.query.Sources) > 0 {
+		fmt.Fprintf(&buf, "{source=%s}", strconv.Quote(strings.Join(s.query.Sources, "|")))
+	}
+	str := buf.String()
+	if s.query.Downsampler != nil {
+		str = fmt.Sprintf("downsample(%s, %s)", strings.ToLower(s.query.Downsampler.String()), str)
+	}
+	if s.query.SourceAggregator != nil {
+		agg := *s.query.SourceAggregator
+		if agg == tspb.TimeSeriesQueryAggregator_PERCENTILE {
+			str = fmt.Sprintf("percentile(%s, %s)", textQueryNumber(s.query.Percentile), str)
+		} else {
+			str = fmt.Sprintf("%s(%s)", strings.ToLower(agg.String()), str)
+		}
+	}
+	if s.query.Derivative != nil {
+		switch *s.query.Derivative {
+		case tspb.TimeSeriesQueryDerivative_DERIVATIVE:
+			str = fmt.Sprintf("derivative(%s)", str)
+		case tspb.TimeSeriesQueryDerivative_NON_NEGATIVE_DERIVATIVE:
+			str = fmt.Sprintf("rate(%s)", str)
+		}
+	}
+	if s.query.GroupBySource {
+		str = fmt.Sprintf("by_source(%s)", str)
+	}
+	return str
+}
+
+// textQueryBinary is an arithmetic operation between two expressions.
+type textQueryBinary struct {
+	op          byte
+	left, right textQueryExpr
+}
+
+func (b *textQueryBinary) String() string {
+	return fmt.Sprintf("(%s %c %s)", b.left, b.op, b.right)
+}
+
+// textQueryTokenKind is the kind of a textQueryToken.
+type textQueryTokenKind int
+
+const (
+	textQueryEOF textQueryTokenKind = iota
+	textQueryIdent
+	textQueryString
+	textQueryNum
+	textQueryPunct
+)
+
+// textQueryToken is a lexical token of a textual query.
+type textQueryToken struct {
+	kind textQueryTokenKind
+	text string
+	pos  int
+}
+
+func (t textQueryToken) String() string {
+	if t.kind == textQueryEOF {
+		return "end of query"
+	}
+	return strconv.Quote(t.text)
+}
+
+const (
+	// maxTextQueryLength is the maximum length of a textual query, which
+	// bounds the size of the expression trees built by the parser.
+	maxTextQueryLength = 4096
+	// maxTextQueryDepth is the maximum nesting depth of the parentheses and
+	// function calls of a textual query, which bounds the recursion of the
+	// parser.
+	maxTextQueryDepth = 64
+)
+
+// textQueryParser is a recursive descent parser for textual queries.
+type textQueryParser struct {
+	input string
+	pos   int
+	tok   textQueryToken
+	// depth is the number of factors currently being parsed.
+	depth int
+}
+
+// parseTextQuery parses the supplied textual query.
+func parseTextQuery(input string) (textQueryExpr, error) {
+	if len(input) > maxTextQueryLength {
+		return nil, errors.Errorf("query of length %d exceeds the maximum length of %d",
+			len(input), maxTextQueryLength)
+	}
+	p := textQueryParser{input: input}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != textQueryEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return expr, nil
+}
+
+func isTextQueryIdentChar(c byte, first bool) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		return true
+	case c >= '0' && c <= '9', c == '.':
+		return !first
+	}
+	return false
+}
+
+// isTextQueryIdent returns true if the supplied series name can be written in
+// a textual query without quotes.
+func isTextQueryIdent(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isTextQueryIdentChar(s[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *textQueryParser) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("at position %d of query %q: %s",
+		p.tok.pos, p.input, fmt.Sprintf(format, args...))
+}
+
+// next advances the parser to the next token of the input.
+func (p *textQueryParser) next() error {
+	for p.pos < len(p.input) && strings.IndexByte(" \t\r\n", p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+	start := p.pos
+	p.tok = textQueryToken{pos: start}
+	if p.pos == len(p.input) {
+		p.tok.kind = textQueryEOF
+		return nil
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case isTextQueryIdentChar(c, true):
+		for p.pos < len(p.input) && isTextQueryIdentChar(p.input[p.pos], false) {
+			p.pos++
+		}
+		p.tok.kind = textQueryIdent
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.input) &&
+			(p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		p.tok.kind = textQueryNum
+	case c == '"':
+		p.pos++
+		for p.pos < len(p.input) && p.input[p.pos] != '"' {
+			if p.input[p.pos] == '\\' {
+				p.pos++
+			}
+			p.pos++
+		}
+		if p.pos >= len(p.input) {
+			return p.errorf("unterminated string")
+		}
+		p.pos++
+		s, err := strconv.Unquote(p.input[start:p.pos])
+		if err != nil {
+			return p.errorf("invalid string %s", p.input[start:p.pos])
+		}
+		p.tok.kind = textQueryString
+		p.tok.text = s
+		return nil
+	case strings.IndexByte("(){},=+-*/", c) >= 0:
+		p.pos++
+		p.tok.kind = textQueryPunct
+	default:
+		return p.errorf("unexpected character %q", c)
+	}
+	p.tok.text = p.input[start:p.pos]
+	return nil
+}
+
+// isPunct returns true if the current token is the given punctuation.
+func (p *textQueryParser) isPunct(punct string) bool {
+	return p.tok.kind == textQueryPunct && p.tok.text == punct
+}
+
+// expect consumes the given punctuation, returning an error if the current
+// token is anything else.
+func (p *textQueryParser) expect(punct string) error {
+	if !p.isPunct(punct) {
+		return p.errorf("expected %q, found %s", punct, p.tok)
+	}
+	return p.next()
+}
+
+func (p *textQueryParser) parseExpr() (textQueryExpr, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("+") || p.isPunct("-") {
+		op := p.tok.text[0]
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &textQueryBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *textQueryParser) parseTerm() (textQueryExpr, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("*") || p.isPunct("/") {
+		op := p.tok.text[0]
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &textQueryBinary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *textQueryParser) parseFactor() (textQueryExpr, error) {
+	// Parenthesized expressions and the arguments of functions are parsed
+	// recursively as factors.
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxTextQueryDepth {
+		return nil, p.errorf("query is nested more than %d levels deep", maxTextQueryDepth)
+	}
+
+	tok := p.tok
+	switch tok.kind {
+	case textQueryNum:
+		n, err := p.parseNumber()
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	case textQueryPunct:
+		if tok.text != "(" {
+			return nil, p.errorf("unexpected %s", tok)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	case textQueryIdent, textQueryString:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if tok.kind == textQueryIdent && p.isPunct("(") {
+			return p.parseFunction(tok)
+		}
+		return p.parseSeries(tok.text)
+	}
+	return nil, p.errorf("unexpected %s", tok)
+}
+
+func (p *textQueryParser) parseNumber() (textQueryNumber, error) {
+	if p.tok.kind != textQueryNum {
+		return 0, p.errorf("expected a number, found %s", p.tok)
+	}
+	n, err := strconv.ParseFloat(p.tok.text, 64)
+	if err != nil {
+		return 0, p.errorf("invalid number %s", p.tok)
+	}
+	return textQueryNumber(n), p.next()
+}
+
+// parseSeries parses the optional source filter of the series with the
+// supplied name.
+func (p *textQueryParser) parseSeries(name string) (textQueryExpr, error) {
+	series := &textQuerySeries{query: tspb.Query{Name: name}}
+	if !p.isPunct("{") {
+		return series, nil
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != textQueryIdent || p.tok.text != "source" {
+		return nil, p.errorf("expected \"source\", found %s", p.tok)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != textQueryString {
+		return nil, p.errorf("expected a string, found %s", p.tok)
+	}
+	for _, source := range strings.Split(p.tok.text, "|") {
+		if source == "" {
+			return nil, p.errorf("empty source in %s", p.tok)
+		}
+		series.query.Sources = append(series.query.Sources, source)
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("}"); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+// textQuerySourceAggregators are the source aggregators which can be applied
+// to a series by calling a function of the same name.
+var textQuerySourceAggregators = map[string]tspb.TimeSeriesQueryAggregator{
+	"sum":   tspb.TimeSeriesQueryAggregator_SUM,
+	"avg":   tspb.TimeSeriesQueryAggregator_AVG,
+	"max":   tspb.TimeSeriesQueryAggregator_MAX,
+	"min":   tspb.TimeSeriesQueryAggregator_MIN,
+	"count": tspb.TimeSeriesQueryAggregator_COUNT,
+}
+
+// parseFunction parses the arguments of a call to the function named by the
+// supplied token, and applies the function to its series argument.
+func (p *textQueryParser) parseFunction(fn textQueryToken) (textQueryExpr, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	// The leading arguments of percentile and downsample are parameters of
+	// the function, rather than expressions.
+	var percentile textQueryNumber
+	var downsampler tspb.TimeSeriesQueryAggregator
+	switch fn.text {
+	case "percentile":
+		var err error
+		if percentile, err = p.parseNumber(); err != nil {
+			return nil, err
+		}
+		if percentile <= 0 || percentile > 100 {
+			return nil, p.errorf("percentile %s is not in the range (0, 100]", percentile)
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	case "downsample":
+		if p.tok.kind != textQueryIdent {
+			return nil, p.errorf("expected an aggregator, found %s", p.tok)
+		}
+		agg, ok := tspb.TimeSeriesQueryAggregator_value[strings.ToUpper(p.tok.text)]
+		downsampler = tspb.TimeSeriesQueryAggregator(agg)
+		if !ok || downsampler == tspb.TimeSeriesQueryAggregator_PERCENTILE {
+			return nil, p.errorf("unknown downsampler %s", p.tok)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+
+	arg, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	series, ok := arg.(*textQuerySeries)
+	if !ok {
+		return nil, errors.Errorf("in query %q: function %s must be applied to a time series, not %s",
+			p.input, fn.text, arg)
+	}
+
+	query := &series.query
+	conflict := func(set bool) error {
+		if set {
+			return errors.Errorf("in query %q: function %s conflicts with another function applied to %s",
+				p.input, fn.text, series)
+		}
+		return nil
+	}
+	switch fn.text {
+	case "percentile":
+		if err := conflict(query.SourceAggregator != nil || query.GroupBySource); err != nil {
+			return nil, err
+		}
+		query.SourceAggregator = tspb.TimeSeriesQueryAggregator_PERCENTILE.Enum()
+		query.Percentile = float64(percentile)
+	case "downsample":
+		if err := conflict(query.Downsampler != nil); err != nil {
+			return nil, err
+		}
+		query.Downsampler = downsampler.Enum()
+	case "by_source":
+		if err := conflict(query.SourceAggregator != nil || query.GroupBySource); err != nil {
+			return nil, err
+		}
+		query.GroupBySource = true
+	case "rate", "derivative":
+		if err := conflict(query.Derivative != nil); err != nil {
+			return nil, err
+		}
+		if fn.text == "rate" {
+			query.Derivative = tspb.TimeSeriesQueryDerivative_NON_NEGATIVE_DERIVATIVE.Enum()
+		} else {
+			query.Derivative = tspb.TimeSeriesQueryDerivative_DERIVATIVE.Enum()
+		}
+	default:
+		agg, ok := textQuerySourceAggregators[fn.text]
+		if !ok {
+			return nil, errors.Errorf("in query %q: unknown function %s", p.input, fn.text)
+		}
+		if err := conflict(query.SourceAggregator != nil || query.GroupBySource); err != nil {
+			return nil, err
+		}
+		query.SourceAggregator = agg.Enum()
+	}
+	return series, nil
+}
+
+// textQueryValue is the value of an evaluated textual query expression: either
+// a scalar, or a set of time series. Series which are grouped by source have
+// a source; an ungrouped series has no source.
+type textQueryValue struct {
+	isScalar bool
+	scalar   float64
+	grouped  bool
+	series   []tspb.TimeSeriesData
+}
+
+// textQueryFn runs the supplied query, returning a single series without a
+// source if the query is not grouped by source, and a series for each source
+// otherwise.
+type textQueryFn func(tspb.Query) ([]tspb.TimeSeriesData, error)
+
+// evalTextQuery evaluates the supplied textual query expression, using
+// queryFn to run the queries of the time series it references.
+func evalTextQuery(expr textQueryExpr, queryFn textQueryFn) (textQueryValue, error) {
+	switch e := expr.(type) {
+	case textQueryNumber:
+		return textQueryValue{isScalar: true, scalar: float64(e)}, nil
+	case *textQuerySeries:
+		series, err := queryFn(e.query)
+		if err != nil {
+			return textQueryValue{}, err
+		}
+		return textQueryValue{grouped: e.query.GroupBySource, series: series}, nil
+	case *textQueryBinary:
+		left, err := evalTextQuery(e.left, queryFn)
+		if err != nil {
+			return textQueryValue{}, err
+		}
+		right, err := evalTextQuery(e.right, queryFn)
+		if err != nil {
+			return textQueryValue{}, err
+		}
+		return evalTextQueryBinary(e, left, right)
+	}
+	panic(fmt.Sprintf("unknown textual query expression %T", expr))
+}
+
+// evalTextQueryBinary evaluates an arithmetic operation between two values.
+//
+// A scalar is combined with every datapoint of the other operand. Two series
+// are combined point-wise: the result has a datapoint at each timestamp for
+// which both series have a datapoint. Series grouped by source are matched by
+// source, keeping only the sources present in both operands; an ungrouped
+// series is combined with every series of a grouped operand. Datapoints at
+// which a division by zero would occur are omitted; a division of a constant
+// by zero is an error.
+func evalTextQueryBinary(
+	b *textQueryBinary, left, right textQueryValue,
+) (textQueryValue, error) {
+	apply := func(l, r float64) (float64, bool) {
+		switch b.op {
+		case '+':
+			return l + r, true
+		case '-':
+			return l - r, true
+		case '*':
+			return l * r, true
+		case '/':
+			return l / r, r != 0
+		}
+		panic(fmt.Sprintf("unknown textual query operator %c", b.op))
+	}
+
+	if left.isScalar && right.isScalar {
+		v, ok := apply(left.scalar, right.scalar)
+		if !ok {
+			return textQueryValue{}, errors.Errorf("division by zero in %s", b)
+		}
+		return textQueryValue{isScalar: true, scalar: v}, nil
+	}
+
+	name := b.String()
+	result := textQueryValue{grouped: left.grouped || right.grouped}
+	switch {
+	case left.isScalar || right.isScalar:
+		operand := left
+		if left.isScalar {
+			operand = right
+		}
+		for _, s := range operand.series {
+			datapoints := make([]tspb.TimeSeriesDatapoint, 0, len(s.Datapoints))
+			for _, dp := range s.Datapoints {
+				l, r := left.scalar, right.scalar
+				if left.isScalar {
+					r = dp.Value
+				} else {
+					l = dp.Value
+				}
+				if v, ok := apply(l, r); ok {
+					datapoints = append(datapoints, tspb.TimeSeriesDatapoint{
+						TimestampNanos: dp.TimestampNanos,
+						Value:          v,
+					})
+				}
+			}
+			result.series = append(result.series, tspb.TimeSeriesData{
+				Name:       name,
+				Source:     s.Source,
+				Datapoints: datapoints,
+			})
+		}
+	default:
+		for _, l := range left.series {
+			for _, r := range right.series {
+				if left.grouped && right.grouped && l.Source != r.Source {
+					continue
+				}
+				source := l.Source
+				if !left.grouped {
+					source = r.Source
+				}
+				result.series = append(result.series, tspb.TimeSeriesData{
+					Name:       name,
+					Source:     source,
+					Datapoints: combineDatapoints(l.Datapoints, r.Datapoints, apply),
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+// combineDatapoints combines the datapoints of two series, ordered by
+// timestamp, which have the same timestamp using the supplied function.
+func combineDatapoints(
+	left, right []tspb.TimeSeriesDatapoint, apply func(l, r float64) (float64, bool),
+) []tspb.TimeSeriesDatapoint {
+	var result []tspb.TimeSeriesDatapoint
+	for i, j := 0, 0; i < len(left) && j < len(right); {
+		switch l, r := left[i], right[j]; {
+		case l.TimestampNanos < r.TimestampNanos:
+			i++
+		case l.TimestampNanos > r.TimestampNanos:
+			j++
+		default:
+			if v, ok := apply(l.Value, r.Value); ok {
+				result = append(result, tspb.TimeSeriesDatapoint{
+					TimestampNanos: l.TimestampNanos,
+					Value:          v,
+				})
+			}
+			i++
+			j++
+		}
+	}
+	return result
+}
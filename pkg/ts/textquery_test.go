@@ -0,0 +1,220 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ts
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/ts/tspb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/kr/pretty"
+)
+
+func TestParseTextQuery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	for _, tc := range []struct {
+		input, expected string
+	}{
+		{`cr.node.sql.conns`, `cr.node.sql.conns`},
+		{` "cr.node.sql.service.latency-p99" `, `"cr.node.sql.service.latency-p99"`},
+		{`sum(a) + 2 * b`, `(sum(a) + (2 * b))`},
+		{`(a - b) / c - 1.5`, `(((a - b) / c) - 1.5)`},
+		{`a{source="1|2"}`, `a{source="1|2"}`},
+		{`a { source = "1" }`, `a{source="1"}`},
+		{`percentile(99.9, "x-p99")`, `percentile(99.9, "x-p99")`},
+		{`count(a)`, `count(a)`},
+		{`by_source(rate(downsample(max, a{source="3"})))`,
+			`by_source(rate(downsample(max, a{source="3"})))`},
+		{`max(rate(a))`, `rate(max(a))`},
+		{`derivative(downsample(last, a))`, `derivative(downsample(last, a))`},
+		{strings.Repeat("(", maxTextQueryDepth-1) + "a" + strings.Repeat(")", maxTextQueryDepth-1),
+			`a`},
+	} {
+		expr, err := parseTextQuery(tc.input)
+		if err != nil {
+			t.Errorf("%s: %s", tc.input, err)
+			continue
+		}
+		if s := expr.String(); s != tc.expected {
+			t.Errorf("%s: parsed as %s, expected %s", tc.input, s, tc.expected)
+		}
+	}
+}
+
+func TestParseTextQueryErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	for _, tc := range []struct {
+		input, expected string
+	}{
+		{``, `unexpected end of query`},
+		{`a +`, `unexpected end of query`},
+		{`a b`, `at position 2 of query "a b": unexpected "b"`},
+		{`(a`, `expected ")", found end of query`},
+		{`a ^ b`, `unexpected character '^'`},
+		{`"abc`, `unterminated string`},
+		{`sum(a, b)`, `expected ")", found ","`},
+		{`sum(1)`, `function sum must be applied to a time series, not 1`},
+		{`sum(a + b)`, `function sum must be applied to a time series, not (a + b)`},
+		{`sum(rate(by_source(a)) / 2)`, `must be applied to a time series`},
+		{`sum(max(a))`, `function sum conflicts with another function applied to max(a)`},
+		{`sum(by_source(a))`, `function sum conflicts`},
+		{`by_source(percentile(50, a))`, `function by_source conflicts`},
+		{`rate(derivative(a))`, `function rate conflicts`},
+		{`downsample(min, downsample(max, a))`, `function downsample conflicts`},
+		{`foo(a)`, `unknown function foo`},
+		{`percentile(0, a)`, `percentile 0 is not in the range (0, 100]`},
+		{`percentile(a, b)`, `expected a number, found "a"`},
+		{`downsample(percentile, a)`, `unknown downsampler "percentile"`},
+		{`downsample(median, a)`, `unknown downsampler "median"`},
+		{`a{node="1"}`, `expected "source", found "node"`},
+		{`a{source=1}`, `expected a string, found "1"`},
+		{`a{source="1||2"}`, `empty source`},
+		{`1.2.3`, `invalid number "1.2.3"`},
+		{strings.Repeat("(", maxTextQueryDepth) + "a" + strings.Repeat(")", maxTextQueryDepth),
+			`query is nested more than 64 levels deep`},
+		{strings.Repeat("rate(", maxTextQueryDepth) + "a" + strings.Repeat(")", maxTextQueryDepth),
+			`query is nested more than 64 levels deep`},
+		{strings.Repeat("a+", maxTextQueryLength/2) + "a",
+			`query of length 4097 exceeds the maximum length of 4096`},
+	} {
+		if _, err := parseTextQuery(tc.input); !testutils.IsError(err, regexp.QuoteMeta(tc.expected)) {
+			t.Errorf("%s: expected error %q, got %v", tc.input, tc.expected, err)
+		}
+	}
+}
+
+func TestEvalTextQuery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	data := map[string][]tspb.TimeSeriesData{
+		"a": {
+			{Name: "a", Datapoints: []tspb.TimeSeriesDatapoint{
+				datapoint(1, 10), datapoint(2, 20), datapoint(3, 30),
+			}},
+		},
+		"b": {
+			{Name: "b", Datapoints: []tspb.TimeSeriesDatapoint{
+				datapoint(2, 4), datapoint(3, 0), datapoint(4, 1),
+			}},
+		},
+		"c": {
+			{Name: "c", Source: "1", Datapoints: []tspb.TimeSeriesDatapoint{
+				datapoint(1, 1), datapoint(2, 2),
+			}},
+			{Name: "c", Source: "2", Datapoints: []tspb.TimeSeriesDatapoint{
+				datapoint(1, 3),
+			}},
+		},
+		"d": {
+			{Name: "d", Source: "1", Datapoints: []tspb.TimeSeriesDatapoint{
+				datapoint(1, 10),
+			}},
+			{Name: "d", Source: "3", Datapoints: []tspb.TimeSeriesDatapoint{
+				datapoint(1, 5),
+			}},
+		},
+	}
+	queryFn := func(query tspb.Query) ([]tspb.TimeSeriesData, error) {
+		if series := data[query.Name]; query.GroupBySource == (series[0].Source != "") {
+			return series, nil
+		}
+		t.Fatalf("unexpected query %v", query)
+		return nil, nil
+	}
+
+	for _, tc := range []struct {
+		input    string
+		expected textQueryValue
+	}{
+		{`2 * 3`, textQueryValue{isScalar: true, scalar: 6}},
+		{`a`, textQueryValue{series: data["a"]}},
+		{`by_source(c)`, textQueryValue{grouped: true, series: data["c"]}},
+		{
+			`a + b`,
+			textQueryValue{series: []tspb.TimeSeriesData{
+				{Name: "(a + b)", Datapoints: []tspb.TimeSeriesDatapoint{
+					datapoint(2, 24), datapoint(3, 30),
+				}},
+			}},
+		},
+		{
+			`a / b`,
+			textQueryValue{series: []tspb.TimeSeriesData{
+				{Name: "(a / b)", Datapoints: []tspb.TimeSeriesDatapoint{
+					datapoint(2, 5),
+				}},
+			}},
+		},
+		{
+			`2 - a`,
+			textQueryValue{series: []tspb.TimeSeriesData{
+				{Name: "(2 - a)", Datapoints: []tspb.TimeSeriesDatapoint{
+					datapoint(1, -8), datapoint(2, -18), datapoint(3, -28),
+				}},
+			}},
+		},
+		{
+			`1 / b`,
+			textQueryValue{series: []tspb.TimeSeriesData{
+				{Name: "(1 / b)", Datapoints: []tspb.TimeSeriesDatapoint{
+					datapoint(2, 0.25), datapoint(4, 1),
+				}},
+			}},
+		},
+		{
+			`by_source(c) + a`,
+			textQueryValue{grouped: true, series: []tspb.TimeSeriesData{
+				{Name: "(by_source(c) + a)", Source: "1", Datapoints: []tspb.TimeSeriesDatapoint{
+					datapoint(1, 11), datapoint(2, 22),
+				}},
+				{Name: "(by_source(c) + a)", Source: "2", Datapoints: []tspb.TimeSeriesDatapoint{
+					datapoint(1, 13),
+				}},
+			}},
+		},
+		{
+			`by_source(c) * by_source(d) * 2`,
+			textQueryValue{grouped: true, series: []tspb.TimeSeriesData{
+				{Name: "((by_source(c) * by_source(d)) * 2)", Source: "1", Datapoints: []tspb.TimeSeriesDatapoint{
+					datapoint(1, 20),
+				}},
+			}},
+		},
+	} {
+		expr, err := parseTextQuery(tc.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := evalTextQuery(expr, queryFn)
+		if err != nil {
+			t.Fatalf("%s: %s", tc.input, err)
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("%s: got %v, expected %v, diff: %s", tc.input, actual, tc.expected,
+				pretty.Diff(actual, tc.expected))
+		}
+	}
+
+	expr, err := parseTextQuery(`a + 1 / (2 - 2)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := evalTextQuery(expr, queryFn); !testutils.IsError(err, "division by zero") {
+		t.Errorf("expected division by zero error, got %v", err)
+	}
+}
@@ -37,12 +37,18 @@ export function getEventInfo(e: Event) {
     case eventTypes.DROP_TABLE:
       content = <span>User {info.User} <strong>dropped table</strong> {info.TableName}</span>;
       break;
+    case eventTypes.UNDROP_TABLE:
+      content = <span>User {info.User} <strong>restored table</strong> {info.TableName}</span>;
+      break;
     case eventTypes.NODE_JOIN:
       content = <span>Node {targetId} <strong>joined the cluster</strong></span>;
       break;
     case eventTypes.NODE_RESTART:
       content = <span>Node {targetId} <strong>rejoined the cluster</strong></span>;
       break;
+    case eventTypes.REMOVE_INCONSISTENT_REPLICA:
+      content = <span>Range {targetId} <strong>removed inconsistent replica</strong> on store {info.RemovedReplica.store_id}</span>;
+      break;
     default:
       content = <span>Unknown event type: {e.event_type}</span>;
   }
@@ -12,6 +12,8 @@ export const DROP_DATABASE = "drop_database";
 export const CREATE_TABLE = "create_table";
 // Recorded when a table is dropped.
 export const DROP_TABLE = "drop_table";
+// Recorded when a dropped table is restored.
+export const UNDROP_TABLE = "undrop_table";
 // Recorded when a table is altered.
 export const ALTER_TABLE = "alter_table";
 // Recorded when an index is created.
@@ -31,12 +33,16 @@ export const FINISH_SCHEMA_CHANGE = "finish_schema_change";
 export const NODE_JOIN = "node_join";
 // Recorded when an existing node rejoins the cluster after being offline.
 export const NODE_RESTART = "node_restart";
+// Recorded when a replica which failed a consistency check is removed from its
+// range.
+export const REMOVE_INCONSISTENT_REPLICA = "remove_inconsistent_replica";
 
 // Node Event Types
 export const nodeEvents = [NODE_JOIN, NODE_RESTART];
+export const rangeEvents = [REMOVE_INCONSISTENT_REPLICA];
 export const databaseEvents = [CREATE_DATABASE, DROP_DATABASE];
-export const tableEvents = [CREATE_TABLE, DROP_TABLE, ALTER_TABLE, CREATE_INDEX,
-  DROP_INDEX, CREATE_VIEW, DROP_VIEW, REVERSE_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE];
+export const tableEvents = [CREATE_TABLE, DROP_TABLE, UNDROP_TABLE, ALTER_TABLE,
+  CREATE_INDEX, DROP_INDEX, CREATE_VIEW, DROP_VIEW, REVERSE_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE];
 
 interface EventSet {
   [key: string]: number;
@@ -45,6 +51,7 @@ interface EventSet {
 const nodeEventSet = _.invert<EventSet>(nodeEvents);
 const databaseEventSet = _.invert<EventSet>(databaseEvents);
 const tableEventSet = _.invert<EventSet>(tableEvents);
+const rangeEventSet = _.invert<EventSet>(rangeEvents);
 
 export function isNodeEvent(e: Event): boolean {
   return !_.isUndefined(nodeEventSet[e.event_type]);
@@ -57,3 +64,7 @@ export function isDatabaseEvent(e: Event): boolean {
 export function isTableEvent(e: Event): boolean {
   return !_.isUndefined(tableEventSet[e.event_type]);
 }
+
+export function isRangeEvent(e: Event): boolean {
+  return !_.isUndefined(rangeEventSet[e.event_type]);
+}
@@ -359,6 +359,7 @@ func NewEntryDecoder(in io.Reader) *EntryDecoder {
 }
 
 // Decode decodes the next log entry into the provided protobuf message.
+// Entries in both the text and the JSON formats are supported.
 func (d *EntryDecoder) Decode(entry *Entry) error {
 	for {
 		if !d.scanner.Scan() {
@@ -368,6 +369,9 @@ func (d *EntryDecoder) Decode(entry *Entry) error {
 			return io.EOF
 		}
 		b := d.scanner.Bytes()
+		if len(b) > 0 && b[0] == '{' {
+			return decodeJSONEntry(b, entry)
+		}
 		m := entryRE.FindSubmatch(b)
 		if m == nil {
 			continue
@@ -400,6 +404,17 @@ func (d *EntryDecoder) split(data []byte, atEOF bool) (advance int, token []byte
 	if atEOF && len(data) == 0 {
 		return 0, nil, nil
 	}
+	if data[0] == '{' {
+		// Entries in the JSON format span a single line.
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		// Request more data.
+		return 0, nil, nil
+	}
 	// We assume we're currently positioned at a log entry. We want to find the
 	// next one so we start our search at data[1].
 	i := entryRE.FindIndex(data[1:])
@@ -556,6 +571,15 @@ func (buf *buffer) someDigits(i, d int) int {
 	return copy(buf.tmp[i:], buf.tmp[j:])
 }
 
+// FormatEntry writes the log entry to the specified writer in the text
+// format.
+func FormatEntry(entry Entry, w io.Writer) error {
+	buf := formatLogEntry(entry, nil, nil)
+	defer logging.putBuffer(buf)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
 func formatLogEntry(entry Entry, stacks []byte, colors *colorProfile) *buffer {
 	buf := formatHeader(entry.Severity, time.Unix(0, entry.Time),
 		int(entry.Goroutine), entry.File, int(entry.Line), colors)
@@ -575,6 +599,7 @@ func init() {
 
 	logging.setVState(0, nil, false)
 	logging.exitFunc = os.Exit
+	logging.prefix = program
 
 	go logging.flushDaemon()
 }
@@ -582,6 +607,7 @@ func init() {
 // Flush flushes all pending log I/O.
 func Flush() {
 	logging.lockAndFlushAll()
+	flushSecondaryLoggers()
 }
 
 // loggingT collects all the global state of the logging setup.
@@ -625,6 +651,17 @@ type loggingT struct {
 	vmodule   moduleSpec // The state of the --vmodule flag.
 	verbosity level      // V logging level, the value of the --verbosity flag/
 	exitFunc  func(int)  // func that will be called on fatal errors
+
+	// prefix is the first component of the names of the log files. It is the
+	// program name for the main logger.
+	prefix string
+	// channel is the channel of the entries of a secondary logger. It is
+	// empty for the main logger, whose entries are assigned a channel based
+	// on the file that logged them.
+	channel Channel
+	// syncWrites makes the entries be flushed and synced to the log files
+	// before the logging call returns.
+	syncWrites bool
 }
 
 // buffer holds a byte Buffer for reuse. The zero value is ready for use.
@@ -686,9 +723,10 @@ func (l *loggingT) putBuffer(b *buffer) {
 }
 
 // outputLogEntry marshals a log entry proto into bytes, and writes
-// the data to the log files. If a trace location is set, stack traces
-// are added to the entry before marshaling.
-func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string) {
+// the data to the log files and to the sinks. If a trace location is set,
+// stack traces are added to the entry before marshaling. The message
+// includes the formatted tags, if any.
+func (l *loggingT) outputLogEntry(s Severity, file string, line int, tags []logTag, msg string) {
 	// TODO(tschottdorf): this is a pretty horrible critical section.
 	l.mu.Lock()
 
@@ -718,23 +756,28 @@ func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string)
 		}
 	}
 
+	channel := l.channel
+	if channel == "" {
+		channel = channelForFile(file)
+	}
+
 	if l.toStderr || !logDir.isSet() {
-		l.outputToStderr(entry, stacks)
+		l.outputToStderr(entry, channel, tags, stacks)
 	} else {
 		if s >= l.stderrThreshold.get() {
-			l.outputToStderr(entry, stacks)
+			l.outputToStderr(entry, channel, tags, stacks)
 		}
 		if l.file[s] == nil {
 			if err := l.createFiles(s); err != nil {
 				// Make sure the message appears somewhere.
-				l.outputToStderr(entry, stacks)
+				l.outputToStderr(entry, channel, tags, stacks)
 				l.mu.Unlock()
 				l.exit(err)
 				return
 			}
 		}
 
-		buf := l.processForFile(entry, stacks)
+		buf := l.processForFile(entry, channel, tags, stacks)
 		data := buf.Bytes()
 
 		switch s {
@@ -758,6 +801,16 @@ func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string)
 				panic(err)
 			}
 		}
+		if l.syncWrites {
+			for sev := s; sev >= Severity_INFO; sev-- {
+				if err := l.file[sev].Flush(); err != nil {
+					panic(err)
+				}
+				if err := l.file[sev].Sync(); err != nil {
+					panic(err)
+				}
+			}
+		}
 
 		l.putBuffer(buf)
 
@@ -768,6 +821,7 @@ func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string)
 	}
 	exitFunc := l.exitFunc
 	l.mu.Unlock()
+	outputToSinks(entry, channel, tags, stacks)
 	// Flush and exit on fatal logging.
 	if s == Severity_FATAL {
 		// If we got here via Exit rather than Fatal, print no stacks.
@@ -780,8 +834,8 @@ func (l *loggingT) outputLogEntry(s Severity, file string, line int, msg string)
 	}
 }
 
-func (l *loggingT) outputToStderr(entry Entry, stacks []byte) {
-	buf := l.processForStderr(entry, stacks)
+func (l *loggingT) outputToStderr(entry Entry, channel Channel, tags []logTag, stacks []byte) {
+	buf := l.processForStderr(entry, channel, tags, stacks)
 	if _, err := os.Stderr.Write(buf.Bytes()); err != nil {
 		panic(err)
 	}
@@ -789,12 +843,22 @@ func (l *loggingT) outputToStderr(entry Entry, stacks []byte) {
 }
 
 // processForStderr formats a log entry for output to standard error.
-func (l *loggingT) processForStderr(entry Entry, stacks []byte) *buffer {
+func (l *loggingT) processForStderr(
+	entry Entry, channel Channel, tags []logTag, stacks []byte,
+) *buffer {
+	if logFormat.get() == formatJSON {
+		return formatJSONEntry(entry, channel, tags, stacks)
+	}
 	return formatLogEntry(entry, stacks, l.getTermColorProfile())
 }
 
 // processForFile formats a log entry for output to a file.
-func (l *loggingT) processForFile(entry Entry, stacks []byte) *buffer {
+func (l *loggingT) processForFile(
+	entry Entry, channel Channel, tags []logTag, stacks []byte,
+) *buffer {
+	if logFormat.get() == formatJSON {
+		return formatJSONEntry(entry, channel, tags, stacks)
+	}
 	return formatLogEntry(entry, stacks, nil)
 }
 
@@ -919,7 +983,7 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 		}
 	}
 	var err error
-	sb.file, _, err = create(sb.sev, now)
+	sb.file, _, err = create(sb.logger.prefix, sb.sev, now)
 	sb.nbytes = 0
 	if err != nil {
 		return err
@@ -935,14 +999,14 @@ func (sb *syncBuffer) rotateFile(now time.Time) error {
 		fmt.Sprintf("[config] arguments: %s\n", os.Args),
 		fmt.Sprintf("line format: [IWEF]yymmdd hh:mm:ss.uuuuuu goid file:line msg\n"),
 	} {
-		buf := formatLogEntry(Entry{
+		buf := sb.logger.processForFile(Entry{
 			Severity:  sb.sev,
 			Time:      now.UnixNano(),
 			Goroutine: goid.Get(),
 			File:      f,
 			Line:      int64(l),
 			Message:   msg,
-		}, nil, nil)
+		}, sb.logger.channel, nil, nil)
 		var n int
 		n, err = sb.file.Write(buf.Bytes())
 		sb.nbytes += uint64(n)
@@ -1014,6 +1078,7 @@ func (l *loggingT) flushDaemon() {
 	// doesn't need to be Stop()'d as the loop never escapes
 	for range time.Tick(flushInterval) {
 		l.lockAndFlushAll()
+		flushSecondaryLoggers()
 	}
 }
 
@@ -1079,7 +1144,7 @@ func (lb logBridge) Write(b []byte) (n int, err error) {
 			line = 1
 		}
 	}
-	logging.outputLogEntry(Severity(lb), file, line, text)
+	logging.outputLogEntry(Severity(lb), file, line, nil, text)
 	return len(b), nil
 }
 
@@ -148,21 +148,21 @@ func removePeriods(s string) string {
 	return strings.Replace(s, ".", "", -1)
 }
 
-// logName returns a new log file name containing the severity, with start time
-// t, and the name for the symlink for the severity.
-func logName(severity Severity, t time.Time) (name, link string) {
+// logName returns a new log file name starting with prefix and containing the
+// severity, with start time t, and the name for the symlink for the severity.
+func logName(prefix string, severity Severity, t time.Time) (name, link string) {
 	// Replace the ':'s in the time format with '_'s to allow for log files in
 	// Windows.
 	tFormatted := strings.Replace(t.Format(time.RFC3339), ":", "_", -1)
 
 	name = fmt.Sprintf("%s.%s.%s.log.%s.%s.%d",
-		removePeriods(program),
+		removePeriods(prefix),
 		removePeriods(host),
 		removePeriods(userName),
 		severity.Name(),
 		tFormatted,
 		pid)
-	return name, removePeriods(program) + "." + severity.Name()
+	return name, removePeriods(prefix) + "." + severity.Name()
 }
 
 var errMalformedName = errors.New("malformed log filename")
@@ -204,15 +204,17 @@ func parseLogFilename(filename string) (FileDetails, error) {
 var errDirectoryNotSet = errors.New("log: log directory not set")
 
 // create creates a new log file and returns the file and its filename, which
-// contains severity ("INFO", "FATAL", etc.) and t. If the file is created
-// successfully, create also attempts to update the symlink for that tag, ignoring
-// errors.
-func create(severity Severity, t time.Time) (f *os.File, filename string, err error) {
+// contains prefix, severity ("INFO", "FATAL", etc.) and t. If the file is
+// created successfully, create also attempts to update the symlink for that
+// tag, ignoring errors.
+func create(
+	prefix string, severity Severity, t time.Time,
+) (f *os.File, filename string, err error) {
 	dir, err := logDir.get()
 	if err != nil {
 		return nil, "", err
 	}
-	name, link := logName(severity, t)
+	name, link := logName(prefix, severity, t)
 	fname := filepath.Join(dir, name)
 
 	// Open the file os.O_APPEND|os.O_CREATE rather than use os.Create.
@@ -352,7 +354,14 @@ func FetchEntriesFromFiles(
 		return nil, err
 	}
 
-	selectedFiles := selectFiles(logFiles, severity, endTimestamp)
+	// Skip the files of secondary loggers.
+	mainFiles := logFiles[:0]
+	for _, logFile := range logFiles {
+		if logFile.Details.Program == removePeriods(program) {
+			mainFiles = append(mainFiles, logFile)
+		}
+	}
+	selectedFiles := selectFiles(mainFiles, severity, endTimestamp)
 
 	entries := []Entry{}
 	for _, file := range selectedFiles {
@@ -36,7 +36,7 @@ func TestLogFilenameParsing(t *testing.T) {
 	}
 
 	for i, testCase := range testCases {
-		filename, _ := logName(testCase.Severity, testCase.Time)
+		filename, _ := logName(program, testCase.Severity, testCase.Time)
 		details, err := parseLogFilename(filename)
 		if err != nil {
 			t.Fatal(err)
@@ -60,7 +60,7 @@ func TestSelectFiles(t *testing.T) {
 	for i := 0; i < 100; i++ {
 		sev := Severity_INFO + Severity(i)%(Severity_FATAL-Severity_INFO)
 		fileTime := year2000.AddDate(i, 0, 0)
-		name, _ := logName(sev, fileTime)
+		name, _ := logName(program, sev, fileTime)
 		testfile := FileInfo{
 			Name: name,
 			Details: FileDetails{
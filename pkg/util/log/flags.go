@@ -30,4 +30,9 @@ func init() {
 	// which we can't pass to logflags without creating an import cycle.
 	flag.Var(&logging.stderrThreshold,
 		logflags.AlsoLogToStderrName, "logs at or above this threshold go to stderr")
+	flag.Var(&logFormat, logflags.LogFormatName, "format of the log files and stderr: text or json")
+	flag.Var(&logSinks, logflags.LogSinkName,
+		"additional destination of log entries, which can be repeated: "+
+			"stderr, file:///<path>, syslog, syslog:///<socket>, tcp://<host:port> or udp://<host:port>, "+
+			"optionally followed by ?format=text|json&channels=<channel>,...&threshold=<severity>")
 }
@@ -0,0 +1,168 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// format is a log output format.
+type format int32
+
+const (
+	// formatText is the glog-style format documented in formatHeader.
+	formatText format = iota
+	// formatJSON formats each entry as a JSON object on a single line.
+	formatJSON
+)
+
+// logFormat is the format of the log files and of the standard error
+// output. It is set by the --log-format flag.
+var logFormat format
+
+func parseFormat(value string) (format, error) {
+	switch value {
+	case "text":
+		return formatText, nil
+	case "json":
+		return formatJSON, nil
+	}
+	return 0, errors.Errorf("unknown log format %q, expected text or json", value)
+}
+
+// get returns the value of the format.
+func (f *format) get() format {
+	return format(atomic.LoadInt32((*int32)(f)))
+}
+
+// String is part of the flag.Value interface.
+func (f *format) String() string {
+	if f.get() == formatJSON {
+		return "json"
+	}
+	return "text"
+}
+
+// Set is part of the flag.Value interface.
+func (f *format) Set(value string) error {
+	v, err := parseFormat(value)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32((*int32)(f), int32(v))
+	return nil
+}
+
+// jsonEntry is the JSON representation of a log entry. The tags of the entry
+// are kept separate from its message.
+type jsonEntry struct {
+	Severity  string    `json:"severity"`
+	Time      string    `json:"time"`
+	Goroutine int64     `json:"goroutine,omitempty"`
+	File      string    `json:"file"`
+	Line      int64     `json:"line"`
+	Channel   Channel   `json:"channel,omitempty"`
+	Tags      []jsonTag `json:"tags,omitempty"`
+	Message   string    `json:"message"`
+	Stacks    string    `json:"stacks,omitempty"`
+}
+
+// jsonTag is the JSON representation of a log tag. The tags of an entry are
+// a list rather than an object to preserve their order. The value is empty
+// for tags without a value.
+type jsonTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+// formatJSONEntry formats a log entry as a single line of JSON. The message
+// of the entry is expected to start with the formatted tags.
+func formatJSONEntry(entry Entry, channel Channel, tags []logTag, stacks []byte) *buffer {
+	je := jsonEntry{
+		Severity:  entry.Severity.String(),
+		Time:      time.Unix(0, entry.Time).UTC().Format(time.RFC3339Nano),
+		Goroutine: entry.Goroutine,
+		File:      entry.File,
+		Line:      entry.Line,
+		Channel:   channel,
+		Message:   entry.Message,
+		Stacks:    string(stacks),
+	}
+	if len(tags) > 0 {
+		var prefix msgBuf
+		formatTagList(tags, &prefix)
+		je.Message = strings.TrimPrefix(je.Message, prefix.String())
+		je.Tags = make([]jsonTag, len(tags))
+		for i, t := range tags {
+			je.Tags[i].Key = t.Key()
+			if v := t.Value(); v != nil {
+				je.Tags[i].Value = fmt.Sprint(v)
+			}
+		}
+	}
+
+	buf := logging.getBuffer()
+	// Encoding cannot fail: all the fields are strings or integers.
+	data, _ := json.Marshal(je)
+	_, _ = buf.Write(data)
+	_ = buf.WriteByte('\n')
+	return buf
+}
+
+// decodeJSONEntry decodes an entry formatted by formatJSONEntry. The tags are
+// prepended to the message, in their original order.
+func decodeJSONEntry(data []byte, entry *Entry) error {
+	var je jsonEntry
+	if err := json.Unmarshal(data, &je); err != nil {
+		return err
+	}
+	sev, ok := SeverityByName(je.Severity)
+	if !ok {
+		return errMalformedSev
+	}
+	t, err := time.Parse(time.RFC3339Nano, je.Time)
+	if err != nil {
+		return err
+	}
+
+	var msg msgBuf
+	if len(je.Tags) > 0 {
+		msg.WriteString("[")
+		for i, t := range je.Tags {
+			if i > 0 {
+				msg.WriteString(",")
+			}
+			msg.EmitString(t.Key, t.Value)
+		}
+		msg.WriteString("] ")
+	}
+	msg.WriteString(je.Message)
+
+	*entry = Entry{
+		Severity:  sev,
+		Time:      t.UnixNano(),
+		Goroutine: je.Goroutine,
+		File:      je.File,
+		Line:      je.Line,
+		Message:   msg.String(),
+	}
+	return nil
+}
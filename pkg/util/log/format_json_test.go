@@ -0,0 +1,127 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kr/pretty"
+	"golang.org/x/net/context"
+)
+
+func TestFormatJSONEntry(t *testing.T) {
+	ctx := WithLogTagInt(context.Background(), "n", 1)
+	ctx = WithLogTagStr(ctx, "client", "127.0.0.1")
+	ctx = WithLogTag(ctx, "bare", nil)
+	tags := contextLogTags(ctx)
+
+	now := time.Date(2016, 12, 1, 10, 11, 12, 123456000, time.UTC)
+	entry := Entry{
+		Severity:  Severity_WARNING,
+		Time:      now.UnixNano(),
+		Goroutine: 7,
+		File:      "sql/executor.go",
+		Line:      42,
+		Message:   makeMessage(ctx, "hello %s", []interface{}{"world"}),
+	}
+	buf := formatJSONEntry(entry, ChannelSQL, tags, nil)
+	data := buf.String()
+	logging.putBuffer(buf)
+
+	var je jsonEntry
+	if err := json.Unmarshal([]byte(data), &je); err != nil {
+		t.Fatalf("%s: %s", data, err)
+	}
+	expected := jsonEntry{
+		Severity:  "WARNING",
+		Time:      "2016-12-01T10:11:12.123456Z",
+		Goroutine: 7,
+		File:      "sql/executor.go",
+		Line:      42,
+		Channel:   ChannelSQL,
+		Tags:      []jsonTag{{"n", "1"}, {"client", "127.0.0.1"}, {"bare", ""}},
+		Message:   "hello world",
+	}
+	if !reflect.DeepEqual(expected, je) {
+		t.Fatalf("%s", strings.Join(pretty.Diff(expected, je), "\n"))
+	}
+
+	// Decoding the entry restores the tags in the message, in order.
+	var decoded Entry
+	if err := NewEntryDecoder(strings.NewReader(data)).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	entry.Message = "[n1,client=127.0.0.1,bare] hello world"
+	if !reflect.DeepEqual(entry, decoded) {
+		t.Fatalf("%s", strings.Join(pretty.Diff(entry, decoded), "\n"))
+	}
+}
+
+func TestJSONLogFiles(t *testing.T) {
+	s := logScope(t)
+	defer s.close(t)
+
+	setFlags()
+	defer func() {
+		if err := logFormat.Set("text"); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	if err := logFormat.Set("json"); err != nil {
+		t.Fatal(err)
+	}
+
+	Info(WithLogTagInt(context.Background(), "n", 2), "json entry")
+	Flush()
+
+	entries, err := FetchEntriesFromFiles(Severity_INFO, 0, time.Now().UnixNano(), 100,
+		regexp.MustCompile("json entry"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Message != "[n2] json entry" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		expected format
+		err      string
+	}{
+		{"text", formatText, ""},
+		{"json", formatJSON, ""},
+		{"xml", 0, `unknown log format "xml"`},
+	} {
+		f, err := parseFormat(tc.value)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s: expected error %q, got %v", tc.value, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.value, err)
+		} else if f != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.value, &tc.expected, &f)
+		}
+	}
+}
@@ -67,6 +67,7 @@ func DisableLogFileOutput() {
 	if err := logging.removeFilesLocked(); err != nil {
 		logging.exit(err)
 	}
+	removeSecondaryLogFiles()
 	logDir.clear()
 	logging.toStderr = true
 	logging.stderrThreshold = Severity_NONE
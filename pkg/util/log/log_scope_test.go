@@ -91,8 +91,21 @@ func dirTestOverride(dir string) error {
 	logging.mu.Lock()
 	err := logging.closeFilesLocked()
 	logging.mu.Unlock()
+	if err != nil {
+		return err
+	}
 
-	return err
+	secondaryLogRegistry.Lock()
+	defer secondaryLogRegistry.Unlock()
+	for _, l := range secondaryLogRegistry.loggers {
+		l.logger.mu.Lock()
+		err := l.logger.closeFilesLocked()
+		l.logger.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func isDirEmpty(dirname string) (bool, error) {
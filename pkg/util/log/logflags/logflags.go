@@ -66,6 +66,8 @@ const (
 	VModuleName         = "vmodule"
 	LogBacktraceAtName  = "log-backtrace-at"
 	LogDirName          = "log-dir"
+	LogFormatName       = "log-format"
+	LogSinkName         = "log-sink"
 )
 
 // InitFlags creates logging flags which update the given variables. The passed mutex is
@@ -0,0 +1,85 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"os"
+
+	"golang.org/x/net/context"
+
+	"github.com/cockroachdb/cockroach/pkg/util/caller"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// SecondaryLogger writes log entries to its own files in the log directory.
+// The files are named like those of the main logger, with the program name
+// followed by the name of the secondary logger, and are rotated independently
+// of the main log files. When no log directory is configured, the entries are
+// written to standard error.
+type SecondaryLogger struct {
+	logger loggingT
+}
+
+var secondaryLogRegistry struct {
+	syncutil.Mutex
+	loggers []*SecondaryLogger
+}
+
+// NewSecondaryLogger creates a secondary logger whose files are named
+// "<program>-<name>.<host>.<user>.log.INFO.<time>.<pid>" and whose entries
+// are routed to the sinks of the given channel. Secondary loggers are flushed
+// together with the main logger, unless forceSyncWrites is set, in which case
+// each entry is flushed and synced to disk before Logf returns.
+func NewSecondaryLogger(name string, channel Channel, forceSyncWrites bool) *SecondaryLogger {
+	l := &SecondaryLogger{}
+	l.logger.prefix = program + "-" + name
+	l.logger.channel = channel
+	l.logger.syncWrites = forceSyncWrites
+	l.logger.stderrThreshold = Severity_NONE
+	l.logger.exitFunc = os.Exit
+
+	secondaryLogRegistry.Lock()
+	secondaryLogRegistry.loggers = append(secondaryLogRegistry.loggers, l)
+	secondaryLogRegistry.Unlock()
+	return l
+}
+
+// Logf writes an entry to the secondary logger, prefixed by the log tags of
+// ctx. Arguments are handled in the manner of fmt.Printf.
+func (l *SecondaryLogger) Logf(ctx context.Context, format string, args ...interface{}) {
+	file, line, _ := caller.Lookup(1)
+	msg := makeMessage(ctx, format, args)
+	l.logger.outputLogEntry(Severity_INFO, file, line, contextLogTags(ctx), msg)
+}
+
+func flushSecondaryLoggers() {
+	secondaryLogRegistry.Lock()
+	defer secondaryLogRegistry.Unlock()
+	for _, l := range secondaryLogRegistry.loggers {
+		l.logger.lockAndFlushAll()
+	}
+}
+
+func removeSecondaryLogFiles() {
+	secondaryLogRegistry.Lock()
+	defer secondaryLogRegistry.Unlock()
+	for _, l := range secondaryLogRegistry.loggers {
+		l.logger.mu.Lock()
+		if err := l.logger.removeFilesLocked(); err != nil {
+			l.logger.exit(err)
+		}
+		l.logger.mu.Unlock()
+	}
+}
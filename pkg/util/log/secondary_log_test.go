@@ -0,0 +1,124 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestSecondaryLog(t *testing.T) {
+	s := logScope(t)
+	defer s.close(t)
+
+	setFlags()
+	l := NewSecondaryLogger("testing", ChannelDefault, false /* forceSyncWrites */)
+	ctx := WithLogTagStr(context.Background(), "user", "root")
+	l.Logf(ctx, "secondary %d", 1)
+	Info(context.Background(), "primary")
+	Flush()
+
+	results, err := ListLogFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var secondaryFiles []FileInfo
+	for _, result := range results {
+		if result.Details.Program == removePeriods(program)+"-testing" {
+			secondaryFiles = append(secondaryFiles, result)
+		}
+	}
+	if len(secondaryFiles) != 1 {
+		t.Fatalf("expected one secondary log file, found %+v", results)
+	}
+
+	reader, err := GetLogReader(secondaryFiles[0].Name, true /* restricted */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	var messages []string
+	decoder := NewEntryDecoder(reader)
+	for {
+		var entry Entry
+		if err := decoder.Decode(&entry); err != nil {
+			if err == io.EOF {
+				break
+			}
+			t.Fatal(err)
+		}
+		messages = append(messages, entry.Message)
+	}
+	if last := messages[len(messages)-1]; last != "[user=root] secondary 1" {
+		t.Errorf("unexpected last secondary log entry %q", last)
+	}
+	for _, msg := range messages {
+		if strings.Contains(msg, "primary") {
+			t.Errorf("found main log entry %q in the secondary log", msg)
+		}
+	}
+
+	// Entries of secondary loggers are not returned with the main log entries.
+	entries, err := FetchEntriesFromFiles(Severity_INFO, 0, time.Now().UnixNano(), 100,
+		regexp.MustCompile("secondary|primary"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Message != "primary" {
+		t.Errorf("expected only the main log entry, got %+v", entries)
+	}
+}
+
+func TestSecondaryLogSyncWrites(t *testing.T) {
+	s := logScope(t)
+	defer s.close(t)
+
+	setFlags()
+	l := NewSecondaryLogger("testing-sync", ChannelDefault, true /* forceSyncWrites */)
+	l.Logf(context.Background(), "synced")
+
+	// The entry is in the file without the logger being flushed.
+	results, err := ListLogFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if result.Details.Program != removePeriods(program)+"-testing-sync" {
+			continue
+		}
+		reader, err := GetLogReader(result.Name, true /* restricted */)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := reader.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(contents), "synced") {
+			t.Fatalf("expected the entry to be written, got %q", contents)
+		}
+		return
+	}
+	t.Fatalf("expected a secondary log file, found %+v", results)
+}
@@ -0,0 +1,429 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// Channel is a category of log entries. Sinks can be restricted to the
+// entries of some channels.
+type Channel string
+
+const (
+	// ChannelDefault is the channel of the entries that don't belong to any
+	// other channel.
+	ChannelDefault Channel = "default"
+	// ChannelStorage is the channel of the entries logged by the storage
+	// layer.
+	ChannelStorage Channel = "storage"
+	// ChannelSQL is the channel of the entries logged by the SQL layer.
+	ChannelSQL Channel = "sql"
+	// ChannelAudit is the channel of the SQL audit log.
+	ChannelAudit Channel = "audit"
+)
+
+var channels = []Channel{ChannelDefault, ChannelStorage, ChannelSQL, ChannelAudit}
+
+// channelForFile returns the channel of the entries logged by the given
+// file, as returned by caller.Lookup.
+func channelForFile(file string) Channel {
+	switch {
+	case strings.HasPrefix(file, "storage/"):
+		return ChannelStorage
+	case strings.HasPrefix(file, "sql/"):
+		return ChannelSQL
+	default:
+		return ChannelDefault
+	}
+}
+
+// sinkWriter writes formatted log entries to a destination.
+type sinkWriter interface {
+	write(s Severity, data []byte) error
+	close() error
+}
+
+// sink is a destination for log entries, in addition to the log files.
+type sink struct {
+	spec      string
+	writer    sinkWriter
+	format    format
+	threshold Severity
+	// channels is the set of channels routed to the sink.
+	channels map[Channel]struct{}
+	// failing is set after a write error, to avoid reporting further errors
+	// until a write succeeds.
+	failing bool
+}
+
+var sinks struct {
+	syncutil.Mutex
+	sinks []*sink
+}
+
+// AddSink adds a destination for log entries, in addition to the log files.
+// The sink is specified as a URL of the form <type>[://<address>][?<options>]
+// where type is one of:
+//
+//   stderr                      standard error
+//   file:///path/to/file        a file, which is appended to
+//   syslog                      the local syslog daemon
+//   syslog:///path/to/socket    a syslog daemon listening on a local socket
+//   tcp://host:port             a network service, one entry per line
+//   udp://host:port             a network service, one entry per datagram
+//
+// and the options are:
+//
+//   format=text|json            the format of the entries (default text for
+//                               stderr and syslog, json otherwise)
+//   channels=<channel>,...      the channels routed to the sink (default
+//                               all but audit): default, storage, sql or
+//                               audit
+//   threshold=<severity>        the minimum severity of the entries routed to
+//                               the sink (default INFO)
+//
+// Entries are written to syslog and network sinks asynchronously, and are
+// dropped when those can't keep up.
+func AddSink(spec string) error {
+	s, err := newSink(spec)
+	if err != nil {
+		return errors.Wrapf(err, "invalid log sink %q", spec)
+	}
+	sinks.Lock()
+	sinks.sinks = append(sinks.sinks, s)
+	sinks.Unlock()
+	return nil
+}
+
+func newSink(spec string) (*sink, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	typ := u.Scheme
+	if typ == "" {
+		typ = u.Path
+	}
+
+	s := &sink{spec: spec, format: formatJSON, threshold: Severity_INFO}
+	switch typ {
+	case "stderr":
+		s.format = formatText
+		s.writer = stderrSink{}
+	case "syslog":
+		s.format = formatText
+		if u.Host != "" {
+			return nil, errors.New("only local syslog sockets are supported")
+		}
+		// An empty network and address select the socket of the local syslog
+		// daemon.
+		var network, addr string
+		if u.Scheme != "" && u.Path != "" {
+			network, addr = "unixgram", u.Path
+		}
+		w, err := newSyslogSink(network, addr)
+		if err != nil {
+			return nil, err
+		}
+		s.writer = newAsyncSink(spec, w)
+	case "file":
+		if u.Path == "" {
+			return nil, errors.New("no file specified")
+		}
+		f, err := os.OpenFile(u.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0664)
+		if err != nil {
+			return nil, err
+		}
+		s.writer = fileSink{f}
+	case "tcp", "udp":
+		if u.Host == "" {
+			return nil, errors.New("no address specified")
+		}
+		s.writer = newAsyncSink(spec, &netSink{network: typ, addr: u.Host})
+	default:
+		return nil, errors.Errorf("unknown sink type %q", typ)
+	}
+
+	opts := u.Query()
+	if v := opts.Get("format"); v != "" {
+		if s.format, err = parseFormat(v); err != nil {
+			return nil, err
+		}
+	}
+	if v := opts.Get("threshold"); v != "" {
+		var ok bool
+		if s.threshold, ok = SeverityByName(v); !ok {
+			return nil, errors.Errorf("unknown severity %q", v)
+		}
+	}
+	s.channels = make(map[Channel]struct{})
+	if v := opts.Get("channels"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			channel, err := channelByName(name)
+			if err != nil {
+				return nil, err
+			}
+			s.channels[channel] = struct{}{}
+		}
+	} else {
+		// The audit log is only routed to the sinks that ask for it, as it
+		// can be voluminous and contains the text of the SQL statements.
+		for _, c := range channels {
+			if c != ChannelAudit {
+				s.channels[c] = struct{}{}
+			}
+		}
+	}
+	return s, nil
+}
+
+func channelByName(name string) (Channel, error) {
+	for _, c := range channels {
+		if string(c) == name {
+			return c, nil
+		}
+	}
+	return "", errors.Errorf("unknown channel %q", name)
+}
+
+// removeAllSinks closes and removes all the sinks. For testing only.
+func removeAllSinks() {
+	sinks.Lock()
+	defer sinks.Unlock()
+	for _, s := range sinks.sinks {
+		_ = s.writer.close() // ignore error
+	}
+	sinks.sinks = nil
+}
+
+// outputToSinks writes a log entry to the sinks its channel is routed to.
+// Remote sinks only buffer the entry (see asyncSink).
+func outputToSinks(entry Entry, channel Channel, tags []logTag, stacks []byte) {
+	sinks.Lock()
+	defer sinks.Unlock()
+	for _, s := range sinks.sinks {
+		if entry.Severity < s.threshold {
+			continue
+		}
+		if _, ok := s.channels[channel]; !ok {
+			continue
+		}
+		var buf *buffer
+		if s.format == formatJSON {
+			buf = formatJSONEntry(entry, channel, tags, stacks)
+		} else {
+			buf = formatLogEntry(entry, stacks, nil)
+		}
+		err := s.writer.write(entry.Severity, buf.Bytes())
+		logging.putBuffer(buf)
+		if err != nil {
+			if !s.failing {
+				fmt.Fprintf(os.Stderr, "log: unable to write to sink %s: %s\n", s.spec, err)
+			}
+			s.failing = true
+		} else {
+			s.failing = false
+		}
+	}
+}
+
+type stderrSink struct{}
+
+func (stderrSink) write(_ Severity, data []byte) error {
+	_, err := os.Stderr.Write(data)
+	return err
+}
+
+func (stderrSink) close() error {
+	return nil
+}
+
+type fileSink struct {
+	file *os.File
+}
+
+func (s fileSink) write(_ Severity, data []byte) error {
+	_, err := s.file.Write(data)
+	return err
+}
+
+func (s fileSink) close() error {
+	return s.file.Close()
+}
+
+const (
+	// asyncSinkBufferSize is the number of entries buffered for a remote
+	// sink. Entries are dropped while the buffer is full.
+	asyncSinkBufferSize = 1000
+	// asyncSinkMinBackoff and asyncSinkMaxBackoff bound the delay before
+	// writing to a remote sink again after an error.
+	asyncSinkMinBackoff = 100 * time.Millisecond
+	asyncSinkMaxBackoff = 10 * time.Second
+)
+
+type asyncEntry struct {
+	sev  Severity
+	data []byte
+}
+
+// asyncSink writes entries to a remote sink from its own goroutine, so that
+// a slow or unavailable destination doesn't block logging. The entries are
+// buffered, and dropped when the buffer is full. After a write error, which
+// drops the entry, the sink backs off exponentially before trying the next
+// one, so that an unavailable destination isn't reconnected to for every
+// entry. The entries still buffered when the sink is closed are lost.
+type asyncSink struct {
+	spec    string
+	writer  sinkWriter
+	entries chan asyncEntry
+	stop    chan struct{}
+	done    chan struct{}
+	// dropped counts the entries dropped since the last report. It is
+	// accessed atomically.
+	dropped int64
+}
+
+func newAsyncSink(spec string, writer sinkWriter) *asyncSink {
+	s := &asyncSink{
+		spec:    spec,
+		writer:  writer,
+		entries: make(chan asyncEntry, asyncSinkBufferSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *asyncSink) write(sev Severity, data []byte) error {
+	select {
+	case s.entries <- asyncEntry{sev: sev, data: append([]byte(nil), data...)}:
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+	}
+	return nil
+}
+
+func (s *asyncSink) run() {
+	defer close(s.done)
+	var backoff time.Duration
+	for {
+		var e asyncEntry
+		select {
+		case <-s.stop:
+			return
+		case e = <-s.entries:
+		}
+		if dropped := atomic.SwapInt64(&s.dropped, 0); dropped > 0 {
+			fmt.Fprintf(os.Stderr, "log: dropped %d entries for sink %s\n", dropped, s.spec)
+		}
+		err := s.writer.write(e.sev, e.data)
+		if err == nil {
+			backoff = 0
+			continue
+		}
+		if backoff == 0 {
+			// Only report the first of a series of errors.
+			fmt.Fprintf(os.Stderr, "log: unable to write to sink %s: %s\n", s.spec, err)
+			backoff = asyncSinkMinBackoff
+		} else {
+			backoff *= 2
+			if backoff > asyncSinkMaxBackoff {
+				backoff = asyncSinkMaxBackoff
+			}
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *asyncSink) close() error {
+	close(s.stop)
+	<-s.done
+	return s.writer.close()
+}
+
+// netSinkTimeout bounds the time spent connecting to and writing to a
+// network sink.
+const netSinkTimeout = time.Second
+
+// netSink writes entries to a network service. The connection is
+// established lazily, and reestablished after an error. It is wrapped in an
+// asyncSink, which backs off after errors.
+type netSink struct {
+	network, addr string
+	conn          net.Conn
+}
+
+func (s *netSink) write(_ Severity, data []byte) error {
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.addr, netSinkTimeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	if err := s.conn.SetWriteDeadline(time.Now().Add(netSinkTimeout)); err != nil {
+		return err
+	}
+	if _, err := s.conn.Write(data); err != nil {
+		_ = s.conn.Close() // ignore error
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (s *netSink) close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// sinkSpecs is the value of the --log-sink flag, which can be repeated.
+type sinkSpecs []string
+
+var logSinks sinkSpecs
+
+// String is part of the flag.Value interface.
+func (s *sinkSpecs) String() string {
+	return strings.Join(*s, " ")
+}
+
+// Set is part of the flag.Value interface.
+func (s *sinkSpecs) Set(spec string) error {
+	if err := AddSink(spec); err != nil {
+		return err
+	}
+	*s = append(*s, spec)
+	return nil
+}
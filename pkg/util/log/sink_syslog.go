@@ -0,0 +1,54 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !windows,!plan9,!nacl
+
+package log
+
+import (
+	"log/syslog"
+	"strings"
+)
+
+// syslogSink writes entries to a syslog daemon, with a priority matching
+// their severity.
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+func newSyslogSink(network, addr string) (sinkWriter, error) {
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_DAEMON, program)
+	if err != nil {
+		return nil, err
+	}
+	return syslogSink{w}, nil
+}
+
+func (s syslogSink) write(sev Severity, data []byte) error {
+	msg := strings.TrimSuffix(string(data), "\n")
+	switch sev {
+	case Severity_WARNING:
+		return s.w.Warning(msg)
+	case Severity_ERROR:
+		return s.w.Err(msg)
+	case Severity_FATAL:
+		return s.w.Crit(msg)
+	default:
+		return s.w.Info(msg)
+	}
+}
+
+func (s syslogSink) close() error {
+	return s.w.Close()
+}
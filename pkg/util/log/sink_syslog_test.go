@@ -0,0 +1,72 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !windows,!plan9,!nacl
+
+package log
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestSyslogSink(t *testing.T) {
+	s := logScope(t)
+	defer s.close(t)
+	defer removeAllSinks()
+
+	setFlags()
+	dir, err := ioutil.TempDir("", "TestSyslogSink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Error(err)
+		}
+	}()
+	// The listener stands in for the socket of the syslog daemon.
+	path := filepath.Join(dir, "syslog.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := AddSink("syslog://" + path); err != nil {
+		t.Fatal(err)
+	}
+
+	Warning(context.Background(), "syslog entry")
+
+	if err := conn.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 4096)
+	n, err := conn.Read(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(data[:n])
+	// The priority is LOG_DAEMON|LOG_WARNING.
+	if !strings.HasPrefix(msg, "<28>") || !strings.Contains(msg, "syslog entry") {
+		t.Fatalf("unexpected syslog message %q", msg)
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2026 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestAddSinkErrors(t *testing.T) {
+	defer removeAllSinks()
+
+	for _, tc := range []struct {
+		spec string
+		err  string
+	}{
+		{"bogus", `unknown sink type "bogus"`},
+		{"file://", "no file specified"},
+		{"tcp://", "no address specified"},
+		{"syslog://localhost:514", "only local syslog sockets are supported"},
+		{"stderr?format=xml", `unknown log format "xml"`},
+		{"stderr?threshold=LOUD", `unknown severity "LOUD"`},
+		{"stderr?channels=sql,kv", `unknown channel "kv"`},
+	} {
+		if err := AddSink(tc.spec); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: expected error %q, got %v", tc.spec, tc.err, err)
+		}
+	}
+	if len(sinks.sinks) != 0 {
+		t.Errorf("expected no sink to be added, found %d", len(sinks.sinks))
+	}
+}
+
+func TestSinkChannels(t *testing.T) {
+	for _, tc := range []struct {
+		spec     string
+		expected []Channel
+	}{
+		{"stderr", []Channel{ChannelDefault, ChannelStorage, ChannelSQL}},
+		{"stderr?channels=audit", []Channel{ChannelAudit}},
+		{"stderr?channels=sql,audit", []Channel{ChannelSQL, ChannelAudit}},
+	} {
+		s, err := newSink(tc.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range channels {
+			_, routed := s.channels[c]
+			expected := false
+			for _, e := range tc.expected {
+				expected = expected || e == c
+			}
+			if routed != expected {
+				t.Errorf("%s: expected channel %s to be routed: %t, got %t", tc.spec, c, expected, routed)
+			}
+		}
+	}
+}
+
+func TestChannelForFile(t *testing.T) {
+	for file, expected := range map[string]Channel{
+		"storage/replica.go": ChannelStorage,
+		"sql/executor.go":    ChannelSQL,
+		"server/server.go":   ChannelDefault,
+		"util/log/clog.go":   ChannelDefault,
+	} {
+		if c := channelForFile(file); c != expected {
+			t.Errorf("%s: expected channel %s, got %s", file, expected, c)
+		}
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	s := logScope(t)
+	defer s.close(t)
+	defer removeAllSinks()
+
+	setFlags()
+	dir, err := ioutil.TempDir("", "TestFileSink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Error(err)
+		}
+	}()
+	path := filepath.Join(dir, "sink.log")
+	if err := AddSink("file://" + path + "?threshold=WARNING"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithLogTagInt(context.Background(), "n", 3)
+	Info(ctx, "info entry")
+	Warning(ctx, "warning entry")
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []Entry
+	decoder := NewEntryDecoder(f)
+	for {
+		var entry Entry
+		if err := decoder.Decode(&entry); err != nil {
+			if err == io.EOF {
+				break
+			}
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 1 || entries[0].Severity != Severity_WARNING ||
+		entries[0].Message != "[n3] warning entry" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestTCPSinkChannels(t *testing.T) {
+	s := logScope(t)
+	defer s.close(t)
+	defer removeAllSinks()
+
+	setFlags()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if err := AddSink("tcp://" + ln.Addr().String() + "?channels=audit"); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewSecondaryLogger("sink-testing", ChannelAudit, false /* forceSyncWrites */)
+	ctx := WithLogTagStr(context.Background(), "user", "root")
+	l.Logf(ctx, "audited")
+	Info(context.Background(), "not routed")
+	l.Logf(ctx, "done")
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var je jsonEntry
+		if err := json.Unmarshal(scanner.Bytes(), &je); err != nil {
+			t.Fatalf("%s: %s", scanner.Text(), err)
+		}
+		if je.Channel != ChannelAudit {
+			t.Errorf("unexpected channel %q", je.Channel)
+		}
+		if len(je.Tags) != 1 || je.Tags[0] != (jsonTag{"user", "root"}) {
+			t.Errorf("unexpected tags %v", je.Tags)
+		}
+		if je.Message == "done" {
+			break
+		}
+		messages = append(messages, je.Message)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 1 || messages[0] != "audited" {
+		t.Fatalf("unexpected messages: %q", messages)
+	}
+}
+
+func TestUDPSink(t *testing.T) {
+	s := logScope(t)
+	defer s.close(t)
+	defer removeAllSinks()
+
+	setFlags()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := AddSink("udp://" + conn.LocalAddr().String() + "?format=text&threshold=ERROR"); err != nil {
+		t.Fatal(err)
+	}
+
+	Warning(context.Background(), "warning entry")
+	Error(context.Background(), "error entry")
+
+	if err := conn.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry Entry
+	if err := NewEntryDecoder(strings.NewReader(string(data[:n]))).Decode(&entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Severity != Severity_ERROR || entry.Message != "error entry" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+}
+
+// blockingSinkWriter is a sinkWriter which blocks until unblock is closed.
+type blockingSinkWriter struct {
+	unblock chan struct{}
+	written chan []byte
+}
+
+func (w *blockingSinkWriter) write(_ Severity, data []byte) error {
+	<-w.unblock
+	w.written <- data
+	return nil
+}
+
+func (w *blockingSinkWriter) close() error {
+	return nil
+}
+
+func TestAsyncSinkOverflow(t *testing.T) {
+	w := &blockingSinkWriter{
+		unblock: make(chan struct{}),
+		written: make(chan []byte, 2*asyncSinkBufferSize),
+	}
+	s := newAsyncSink("blocking", w)
+
+	// Writes don't block while the destination does; the entries beyond
+	// the buffer are dropped.
+	for i := 0; i < 2*asyncSinkBufferSize; i++ {
+		if err := s.write(Severity_INFO, []byte("entry")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(w.unblock)
+	for i := 0; i < asyncSinkBufferSize; i++ {
+		select {
+		case <-w.written:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("only %d buffered entries were written", i)
+		}
+	}
+	if err := s.close(); err != nil {
+		t.Fatal(err)
+	}
+	// One more entry may have been taken from the buffer before it filled.
+	if n := len(w.written); n > 1 {
+		t.Fatalf("expected the entries beyond the buffer to be dropped, %d more were written", n)
+	}
+}
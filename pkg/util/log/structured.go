@@ -102,7 +102,11 @@ func (b *msgBuf) EmitLazyLogger(value otlog.LazyLogger) {
 // formatTags appends the tags to a bytes.Buffer. If there are no tags,
 // returns false.
 func formatTags(ctx context.Context, buf *msgBuf) bool {
-	tags := contextLogTags(ctx)
+	return formatTagList(contextLogTags(ctx), buf)
+}
+
+// formatTagList is like formatTags for a list of tags.
+func formatTagList(tags []logTag, buf *msgBuf) bool {
 	if len(tags) > 0 {
 		buf.WriteString("[")
 		for i, t := range tags {
@@ -137,5 +141,5 @@ func addStructured(ctx context.Context, s Severity, depth int, format string, ar
 	// makeMessage already added the tags when forming msg, we don't want
 	// eventInternal to prepend them again.
 	eventInternal(ctx, (s >= Severity_ERROR), false /*withTags*/, "%s:%d %s", file, line, msg)
-	logging.outputLogEntry(s, file, line, msg)
+	logging.outputLogEntry(s, file, line, contextLogTags(ctx), msg)
 }